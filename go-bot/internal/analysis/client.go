@@ -38,6 +38,14 @@ type AnalysisResult struct {
 	ADX           *ADXResult
 	Stochastic    *StochasticResult
 	Regime        *RegimeResult
+	VWAP          *VWAPResult
+	Ichimoku      *IchimokuResult
+	OBV           *OBVResult
+	Supertrend    *SupertrendResult
+	Donchian      *DonchianResult
+	Pivots        *PivotResult
+	EMAs          []EMALevel // ascending by period, only periods the window covers
+	EMAAlignment  string     // BULLISH, BEARISH, MIXED
 	OverallSignal string
 	BullishCount  int32
 	BearishCount  int32
@@ -130,6 +138,76 @@ type RegimeResult struct {
 	Description string
 }
 
+// VWAPResult holds session and anchored vwap output
+type VWAPResult struct {
+	Session         float64
+	SessionUpper    float64
+	SessionLower    float64
+	Anchored        float64
+	AnchorTimestamp int64
+	Signal          string // ABOVE, BELOW
+	SessionSeries   []float64
+}
+
+// IchimokuResult holds ichimoku cloud output
+type IchimokuResult struct {
+	Tenkan      float64
+	Kijun       float64
+	SenkouA     float64
+	SenkouB     float64
+	Chikou      float64
+	Signal      string // ABOVE_CLOUD, IN_CLOUD, BELOW_CLOUD
+	TKCross     string // BULLISH, BEARISH, NONE
+	FutureCloud string // BULLISH, BEARISH
+}
+
+// OBVResult holds on-balance volume output
+type OBVResult struct {
+	Value      float64
+	Slope      float64
+	Signal     string // RISING, FALLING, FLAT
+	Divergence string // BULLISH, BEARISH, NONE
+	Series     []float64
+}
+
+// SupertrendResult holds supertrend output
+type SupertrendResult struct {
+	Value     float64
+	Direction string // UP, DOWN
+	Flipped   bool
+	Series    []float64
+}
+
+// DonchianResult holds donchian channel output
+type DonchianResult struct {
+	Upper        float64
+	Middle       float64
+	Lower        float64
+	WidthPercent float64
+	Signal       string // BREAKOUT_UP, BREAKOUT_DOWN, INSIDE
+	Period       int    // bars in the channel
+}
+
+// PivotLevels is one set of pivot, resistance and support levels
+type PivotLevels struct {
+	Pivot, R1, R2, R3, S1, S2, S3 float64
+}
+
+// PivotResult holds classic and fibonacci pivots from the previous utc day
+type PivotResult struct {
+	Classic          PivotLevels
+	Fibonacci        PivotLevels
+	SessionTimestamp int64
+	Position         string // ABOVE_PIVOT, BELOW_PIVOT
+}
+
+// EMALevel is one ema in a multi-period set
+type EMALevel struct {
+	Period int32
+	Value  float64
+	Trend  string // ABOVE, BELOW
+}
+
 // NewClient creates a new analysis client connected to the rust engine
 func NewClient(addr string) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -354,22 +432,31 @@ func (c *Client) AnalyzeAll(ctx context.Context, candles []Candle, opts *Analyze
 	}
 
 	resp, err := c.client.AnalyzeAll(ctx, &pb.AnalyzeAllRequest{
-		Candles:          toProtoCandles(candles),
-		RsiPeriod:        opts.RSIPeriod,
-		MacdFast:         opts.MACDFast,
-		MacdSlow:         opts.MACDSlow,
-		MacdSignal:       opts.MACDSignal,
-		BbPeriod:         opts.BBPeriod,
-		BbStdDev:         opts.BBStdDev,
-		EmaPeriod:        opts.EMAPeriod,
-		VolumeLookback:   opts.VolumeLookback,
-		VolumeThreshold:  opts.VolumeThreshold,
+		Candles:              toProtoCandles(candles),
+		RsiPeriod:            opts.RSIPeriod,
+		MacdFast:             opts.MACDFast,
+		MacdSlow:             opts.MACDSlow,
+		MacdSignal:           opts.MACDSignal,
+		BbPeriod:             opts.BBPeriod,
+		BbStdDev:             opts.BBStdDev,
+		EmaPeriod:            opts.EMAPeriod,
+		VolumeLookback:       opts.VolumeLookback,
+		VolumeThreshold:      opts.VolumeThreshold,
+		VwapAnchor:           opts.VWAPAnchor,
+		IchimokuTenkan:       opts.IchimokuTenkan,
+		IchimokuKijun:        opts.IchimokuKijun,
+		IchimokuSenkou:       opts.IchimokuSenkou,
+		SupertrendPeriod:     opts.SupertrendPeriod,
+		SupertrendMultiplier: opts.SupertrendMultiplier,
+		DonchianPeriod:       opts.DonchianPeriod,
+		EmaPeriods:           opts.EMAPeriods,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("full analysis failed: %w", err)
 	}
 
 	result := &AnalysisResult{
+		EMAAlignment:  resp.EmaAlignment,
 		OverallSignal: resp.OverallSignal,
 		BullishCount:  resp.BullishCount,
 		BearishCount:  resp.BearishCount,
@@ -462,10 +549,79 @@ func (c *Client) AnalyzeAll(ctx context.Context, candles []Candle, opts *Analyze
 			Description: resp.Regime.Description,
 		}
 	}
+	if resp.Vwap != nil {
+		result.VWAP = &VWAPResult{
+			Session:         resp.Vwap.Session,
+			SessionUpper:    resp.Vwap.SessionUpper,
+			SessionLower:    resp.Vwap.SessionLower,
+			Anchored:        resp.Vwap.Anchored,
+			AnchorTimestamp: resp.Vwap.AnchorTimestamp,
+			Signal:          resp.Vwap.Signal,
+			SessionSeries:   resp.Vwap.SessionSeries,
+		}
+	}
+	if resp.Ichimoku != nil {
+		result.Ichimoku = &IchimokuResult{
+			Tenkan:      resp.Ichimoku.Tenkan,
+			Kijun:       resp.Ichimoku.Kijun,
+			SenkouA:     resp.Ichimoku.SenkouA,
+			SenkouB:     resp.Ichimoku.SenkouB,
+			Chikou:      resp.Ichimoku.Chikou,
+			Signal:      resp.Ichimoku.Signal,
+			TKCross:     resp.Ichimoku.TkCross,
+			FutureCloud: resp.Ichimoku.FutureCloud,
+		}
+	}
+	if resp.Obv != nil {
+		result.OBV = &OBVResult{
+			Value:      resp.Obv.Value,
+			Slope:      resp.Obv.Slope,
+			Signal:     resp.Obv.Signal,
+			Divergence: resp.Obv.Divergence,
+			Series:     resp.Obv.Series,
+		}
+	}
+	if resp.Supertrend != nil {
+		result.Supertrend = &SupertrendResult{
+			Value:     resp.Supertrend.Value,
+			Direction: resp.Supertrend.Direction,
+			Flipped:   resp.Supertrend.Flipped,
+			Series:    resp.Supertrend.Series,
+		}
+	}
+	if resp.Donchian != nil {
+		result.Donchian = &DonchianResult{
+			Upper:        resp.Donchian.Upper,
+			Middle:       resp.Donchian.Middle,
+			Lower:        resp.Donchian.Lower,
+			WidthPercent: resp.Donchian.WidthPercent,
+			Signal:       resp.Donchian.Signal,
+			Period:       int(withDefaults(opts).DonchianPeriod),
+		}
+	}
+	if resp.Pivots != nil {
+		result.Pivots = &PivotResult{
+			Classic:          fromProtoPivotLevels(resp.Pivots.Classic),
+			Fibonacci:        fromProtoPivotLevels(resp.Pivots.Fibonacci),
+			SessionTimestamp: resp.Pivots.SessionTimestamp,
+			Position:         resp.Pivots.Position,
+		}
+	}
+	for _, e := range resp.Emas {
+		result.EMAs = append(result.EMAs, EMALevel{Period: e.Period, Value: e.Value, Trend: e.Trend})
+	}
 
 	return result, nil
 }
 
+// converts proto pivot levels, treating a missing message as zero levels
+func fromProtoPivotLevels(l *pb.PivotLevels) PivotLevels {
+	if l == nil {
+		return PivotLevels{}
+	}
+	return PivotLevels{Pivot: l.Pivot, R1: l.R1, R2: l.R2, R3: l.R3, S1: l.S1, S2: l.S2, S3: l.S3}
+}
+
 // AnalyzeOptions holds configuration for the full analysis
 type AnalyzeOptions struct {
 	RSIPeriod       int32
@@ -477,6 +633,15 @@ type AnalyzeOptions struct {
 	EMAPeriod       int32
 	VolumeLookback  int32
 	VolumeThreshold float64
+
+	VWAPAnchor           int64 // unix seconds, 0 = first candle
+	IchimokuTenkan       int32
+	IchimokuKijun        int32
+	IchimokuSenkou       int32
+	SupertrendPeriod     int32
+	SupertrendMultiplier float64
+	DonchianPeriod       int32
+	EMAPeriods           []int32
 }
 
 // DefaultAnalyzeOptions returns standard indicator parameters
@@ -491,5 +656,13 @@ func DefaultAnalyzeOptions() *AnalyzeOptions {
 		EMAPeriod:       21,
 		VolumeLookback:  20,
 		VolumeThreshold: 2.0,
+
+		IchimokuTenkan:       9,
+		IchimokuKijun:        26,
+		IchimokuSenkou:       52,
+		SupertrendPeriod:     10,
+		SupertrendMultiplier: 3.0,
+		DonchianPeriod:       20,
+		EMAPeriods:           []int32{12, 26, 50, 200},
 	}
 }
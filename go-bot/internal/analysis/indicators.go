@@ -0,0 +1,877 @@
+// native go ports of the rust engine indicators. each function mirrors its
+// counterpart in rust-engine/src/indicators (same seeding, smoothing, zero
+// padding and classification thresholds) so either backend produces the same
+// AnalysisResult. a nil result means there isn't enough data.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// computeRSI uses wilder's smoothing; the series is aligned to price changes
+// (len(closes)-1) with zeros before the first full period
+func computeRSI(closes []float64, period int) *RSIResult {
+	if period <= 0 || len(closes) < period+1 {
+		return nil
+	}
+
+	gains := make([]float64, 0, len(closes)-1)
+	losses := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			gains = append(gains, change)
+			losses = append(losses, 0)
+		} else {
+			gains = append(gains, 0)
+			losses = append(losses, math.Abs(change))
+		}
+	}
+
+	series := make([]float64, period-1, len(gains))
+	avgGain := sum(gains[:period]) / float64(period)
+	avgLoss := sum(losses[:period]) / float64(period)
+	series = append(series, rsiValue(avgGain, avgLoss))
+	for i := period; i < len(gains); i++ {
+		avgGain = (avgGain*float64(period-1) + gains[i]) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + losses[i]) / float64(period)
+		series = append(series, rsiValue(avgGain, avgLoss))
+	}
+
+	value := series[len(series)-1]
+	return &RSIResult{Value: value, Signal: classifyRSI(value), Series: series}
+}
+
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+func classifyRSI(value float64) string {
+	switch {
+	case value <= 30:
+		return "OVERSOLD"
+	case value >= 70:
+		return "OVERBOUGHT"
+	default:
+		return "NEUTRAL"
+	}
+}
+
+// computeEMA returns the full ema series seeded with the sma of the first
+// period values; leading entries are zero
+func computeEMA(values []float64, period int) []float64 {
+	if period <= 0 || len(values) == 0 || period > len(values) {
+		return nil
+	}
+
+	result := make([]float64, period-1, len(values))
+	multiplier := 2 / (float64(period) + 1)
+	result = append(result, sum(values[:period])/float64(period))
+	for i := period; i < len(values); i++ {
+		prev := result[i-1]
+		result = append(result, (values[i]-prev)*multiplier+prev)
+	}
+	return result
+}
+
+func emaTrend(price, ema float64) string {
+	if price > ema {
+		return "ABOVE"
+	}
+	return "BELOW"
+}
+
+func computeMACD(closes []float64, fast, slow, signal int) *MACDResult {
+	if fast <= 0 || signal <= 0 || fast >= slow || len(closes) < slow+signal {
+		return nil
+	}
+
+	fastEMA := computeEMA(closes, fast)
+	slowEMA := computeEMA(closes, slow)
+
+	macd := make([]float64, len(closes))
+	for i := slow - 1; i < len(closes); i++ {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+
+	// signal line is the ema of the valid part of the macd line
+	signalSeries := make([]float64, slow-1, len(closes))
+	signalSeries = append(signalSeries, computeEMA(macd[slow-1:], signal)...)
+
+	histogram := make([]float64, len(closes))
+	for i := slow + signal - 2; i < len(closes); i++ {
+		histogram[i] = macd[i] - signalSeries[i]
+	}
+
+	last := len(closes) - 1
+	res := &MACDResult{
+		MACDLine:        macd[last],
+		SignalLine:      signalSeries[last],
+		Histogram:       histogram[last],
+		MACDSeries:      macd,
+		SignalSeries:    signalSeries,
+		HistogramSeries: histogram,
+	}
+	if last > 0 {
+		prev := macd[last-1] - signalSeries[last-1]
+		curr := res.MACDLine - res.SignalLine
+		res.Crossover = (prev <= 0 && curr > 0) || (prev >= 0 && curr < 0)
+	}
+	res.Signal = classifyMACD(res.MACDLine, res.SignalLine, res.Histogram)
+	return res
+}
+
+func classifyMACD(line, signal, histogram float64) string {
+	switch {
+	case line > signal && histogram > 0:
+		return "BULLISH"
+	case line < signal && histogram < 0:
+		return "BEARISH"
+	default:
+		return "NEUTRAL"
+	}
+}
+
+// computeBollinger uses the population standard deviation over each window
+func computeBollinger(closes []float64, period int, numStdDev float64) *BollingerResult {
+	if period <= 0 || len(closes) < period {
+		return nil
+	}
+
+	n := len(closes)
+	upper := make([]float64, n)
+	middle := make([]float64, n)
+	lower := make([]float64, n)
+	for i := period - 1; i < n; i++ {
+		window := closes[i+1-period : i+1]
+		sma := sum(window) / float64(period)
+		var variance float64
+		for _, v := range window {
+			variance += (v - sma) * (v - sma)
+		}
+		sd := math.Sqrt(variance / float64(period))
+		upper[i] = sma + numStdDev*sd
+		middle[i] = sma
+		lower[i] = sma - numStdDev*sd
+	}
+
+	last := n - 1
+	res := &BollingerResult{
+		Upper:        upper[last],
+		Middle:       middle[last],
+		Lower:        lower[last],
+		PercentB:     0.5,
+		UpperSeries:  upper,
+		MiddleSeries: middle,
+		LowerSeries:  lower,
+	}
+	if math.Abs(res.Middle) > 1e-10 {
+		res.Bandwidth = (res.Upper - res.Lower) / res.Middle
+	}
+	if math.Abs(res.Upper-res.Lower) > 1e-10 {
+		res.PercentB = (closes[last] - res.Lower) / (res.Upper - res.Lower)
+	}
+	res.Signal = classifyBollinger(closes[last], res.Upper, res.Lower, res.Bandwidth)
+	return res
+}
+
+func classifyBollinger(price, upper, lower, bandwidth float64) string {
+	switch {
+	case bandwidth < 0.02:
+		return "SQUEEZE"
+	case price >= upper:
+		return "UPPER_BAND"
+	case price <= lower:
+		return "LOWER_BAND"
+	default:
+		return "MIDDLE"
+	}
+}
+
+// detectVolumeSpike compares the last volume to the mean of the lookback
+// bars before it
+func detectVolumeSpike(volumes []float64, lookback int, threshold float64) *VolumeResult {
+	if lookback <= 0 || len(volumes) < lookback+1 {
+		return nil
+	}
+
+	current := volumes[len(volumes)-1]
+	avg := sum(volumes[len(volumes)-1-lookback:len(volumes)-1]) / float64(lookback)
+	var ratio float64
+	if math.Abs(avg) > 1e-10 {
+		ratio = current / avg
+	}
+
+	signal := "NORMAL"
+	switch {
+	case ratio >= threshold:
+		signal = "SPIKE"
+	case ratio < 0.5:
+		signal = "LOW"
+	}
+	return &VolumeResult{
+		IsSpike:       ratio >= threshold,
+		CurrentVolume: current,
+		AverageVolume: avg,
+		Ratio:         ratio,
+		Signal:        signal,
+	}
+}
+
+func trueRanges(highs, lows, closes []float64) []float64 {
+	tr := make([]float64, 0, len(highs)-1)
+	for i := 1; i < len(highs); i++ {
+		hl := highs[i] - lows[i]
+		hc := math.Abs(highs[i] - closes[i-1])
+		lc := math.Abs(lows[i] - closes[i-1])
+		tr = append(tr, math.Max(hl, math.Max(hc, lc)))
+	}
+	return tr
+}
+
+// computeATR is wilder-smoothed true range, aligned like the rsi series
+func computeATR(highs, lows, closes []float64, period int) *ATRResult {
+	n := len(highs)
+	if period <= 0 || n < period+1 || n != len(lows) || n != len(closes) {
+		return nil
+	}
+
+	tr := trueRanges(highs, lows, closes)
+	series := make([]float64, period-1, len(tr))
+	atr := sum(tr[:period]) / float64(period)
+	series = append(series, atr)
+	for i := period; i < len(tr); i++ {
+		atr = (atr*float64(period-1) + tr[i]) / float64(period)
+		series = append(series, atr)
+	}
+
+	var pct float64
+	if last := closes[n-1]; math.Abs(last) > 1e-10 {
+		pct = atr / last * 100
+	}
+	return &ATRResult{Value: atr, Percent: pct, Signal: classifyVolatility(pct), Series: series}
+}
+
+func classifyVolatility(atrPct float64) string {
+	switch {
+	case atrPct >= 3:
+		return "HIGH"
+	case atrPct <= 1:
+		return "LOW"
+	default:
+		return "NORMAL"
+	}
+}
+
+func computeADX(highs, lows, closes []float64, period int) *ADXResult {
+	n := len(highs)
+	if period <= 0 || n < period*2+1 || n != len(lows) || n != len(closes) {
+		return nil
+	}
+
+	plusDM := make([]float64, 0, n-1)
+	minusDM := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		up := highs[i] - highs[i-1]
+		down := lows[i-1] - lows[i]
+		if up > down && up > 0 {
+			plusDM = append(plusDM, up)
+		} else {
+			plusDM = append(plusDM, 0)
+		}
+		if down > up && down > 0 {
+			minusDM = append(minusDM, down)
+		} else {
+			minusDM = append(minusDM, 0)
+		}
+	}
+
+	sPlus := wilderSmooth(plusDM, period)
+	sMinus := wilderSmooth(minusDM, period)
+	sTR := wilderSmooth(trueRanges(highs, lows, closes), period)
+
+	plusDI := make([]float64, len(sTR))
+	minusDI := make([]float64, len(sTR))
+	dx := make([]float64, len(sTR))
+	for i, atr := range sTR {
+		if atr > 1e-10 {
+			plusDI[i] = sPlus[i] / atr * 100
+			minusDI[i] = sMinus[i] / atr * 100
+		}
+		if s := plusDI[i] + minusDI[i]; s > 1e-10 {
+			dx[i] = math.Abs(plusDI[i]-minusDI[i]) / s * 100
+		}
+	}
+
+	adx := wilderSmooth(dx, period)
+	if len(adx) == 0 {
+		return nil
+	}
+
+	// aligned to true ranges; the first value needs two wilder windows
+	series := make([]float64, period*2-2, n-1)
+	series = append(series, adx...)
+	for len(series) < n-1 {
+		series = append(series, 0)
+	}
+	series = series[:n-1]
+
+	res := &ADXResult{
+		Value:   adx[len(adx)-1],
+		PlusDI:  plusDI[len(plusDI)-1],
+		MinusDI: minusDI[len(minusDI)-1],
+		Series:  series,
+	}
+	res.Signal = classifyADX(res.Value)
+	res.TrendDir = trendDirection(res.PlusDI, res.MinusDI)
+	return res
+}
+
+// wilderSmooth seeds with the simple mean of the first period values
+func wilderSmooth(data []float64, period int) []float64 {
+	if period <= 0 || len(data) < period {
+		return nil
+	}
+	result := make([]float64, 0, len(data)-period+1)
+	result = append(result, sum(data[:period])/float64(period))
+	for i := period; i < len(data); i++ {
+		prev := result[len(result)-1]
+		result = append(result, (prev*float64(period-1)+data[i])/float64(period))
+	}
+	return result
+}
+
+func classifyADX(adx float64) string {
+	switch {
+	case adx >= 50:
+		return "STRONG_TREND"
+	case adx >= 25:
+		return "TRENDING"
+	case adx >= 15:
+		return "WEAK"
+	default:
+		return "NO_TREND"
+	}
+}
+
+func trendDirection(plusDI, minusDI float64) string {
+	switch {
+	case plusDI > minusDI+2:
+		return "UP"
+	case minusDI > plusDI+2:
+		return "DOWN"
+	default:
+		return "NEUTRAL"
+	}
+}
+
+// computeStochastic returns smoothed %K and %D = sma(%K, dPeriod)
+func computeStochastic(highs, lows, closes []float64, kPeriod, dPeriod, smooth int) *StochasticResult {
+	n := len(highs)
+	if kPeriod <= 0 || dPeriod <= 0 || smooth <= 0 || n < kPeriod+dPeriod+smooth || n != len(lows) || n != len(closes) {
+		return nil
+	}
+
+	rawK := make([]float64, n)
+	for i := kPeriod - 1; i < n; i++ {
+		highest, lowest := math.Inf(-1), math.Inf(1)
+		for j := i + 1 - kPeriod; j <= i; j++ {
+			highest = math.Max(highest, highs[j])
+			lowest = math.Min(lowest, lows[j])
+		}
+		if r := highest - lowest; r > 1e-10 {
+			rawK[i] = (closes[i] - lowest) / r * 100
+		} else {
+			rawK[i] = 50 // flat market
+		}
+	}
+
+	validStart := kPeriod - 1 + smooth - 1
+	k := make([]float64, n)
+	for i := validStart; i < n; i++ {
+		k[i] = sum(rawK[i+1-smooth:i+1]) / float64(smooth)
+	}
+	d := make([]float64, n)
+	for i := validStart + dPeriod - 1; i < n; i++ {
+		d[i] = sum(k[i+1-dPeriod:i+1]) / float64(dPeriod)
+	}
+
+	res := &StochasticResult{K: k[n-1], D: d[n-1], KSeries: k, DSeries: d}
+	res.Signal = classifyStochastic(res.K, res.D, k[n-2], d[n-2])
+	return res
+}
+
+func classifyStochastic(k, d, prevK, prevD float64) string {
+	switch {
+	case prevK <= prevD && k > d && k < 30:
+		return "BULLISH_CROSS"
+	case prevK >= prevD && k < d && k > 70:
+		return "BEARISH_CROSS"
+	case k <= 20:
+		return "OVERSOLD"
+	case k >= 80:
+		return "OVERBOUGHT"
+	default:
+		return "NEUTRAL"
+	}
+}
+
+// classifyRegime combines adx (trend strength) and atr% (volatility)
+func classifyRegime(highs, lows, closes []float64, period int) *RegimeResult {
+	return regimeFrom(computeADX(highs, lows, closes, period), computeATR(highs, lows, closes, period))
+}
+
+// regimeFrom classifies already-computed adx and atr; nil if either is missing
+func regimeFrom(adx *ADXResult, atr *ATRResult) *RegimeResult {
+	if adx == nil || atr == nil {
+		return nil
+	}
+
+	var regime string
+	var conf float64
+	switch {
+	case atr.Percent >= 3:
+		regime = "volatile"
+		conf = 60 + math.Min(atr.Percent-3, 5)*8
+	case adx.Value >= 25:
+		regime = "trending"
+		conf = 50 + math.Min(adx.Value-25, 25)*2
+	case atr.Percent <= 1 && adx.Value < 20:
+		regime = "quiet"
+		conf = 50 + math.Min(20-adx.Value, 10)*3 + math.Min(1-atr.Percent, 0.5)*20
+	default:
+		regime = "ranging"
+		conf = 40 + math.Min(25-adx.Value, 15)*2
+	}
+
+	return &RegimeResult{
+		Regime:      regime,
+		ADX:         adx.Value,
+		ATRPercent:  atr.Percent,
+		PlusDI:      adx.PlusDI,
+		MinusDI:     adx.MinusDI,
+		TrendDir:    adx.TrendDir,
+		Confidence:  math.Min(conf, 100),
+		Description: regimeDescription(regime, adx.Value, atr.Percent, adx.TrendDir),
+	}
+}
+
+func regimeDescription(regime string, adx, atrPct float64, trendDir string) string {
+	switch regime {
+	case "trending":
+		return fmt.Sprintf("Strong %s trend detected (ADX=%.1f). Favor trend-following entries with wider stops.", strings.ToLower(trendDir), adx)
+	case "volatile":
+		return fmt.Sprintf("High volatility detected (ATR=%.2f%%). Reduce position size and use wider stops.", atrPct)
+	case "quiet":
+		return fmt.Sprintf("Quiet market (ADX=%.1f, ATR=%.2f%%). Watch for breakout setups, wait for confirmation.", adx, atrPct)
+	default:
+		return fmt.Sprintf("Range-bound market (ADX=%.1f, ATR=%.2f%%). Favor mean-reversion at support/resistance.", adx, atrPct)
+	}
+}
+
+const secondsPerDay = 86400
+
+// utcDay buckets a unix-second timestamp into its utc day (floor division)
+func utcDay(ts int64) int64 {
+	d := ts / secondsPerDay
+	if ts%secondsPerDay < 0 {
+		d--
+	}
+	return d
+}
+
+// computeVWAP returns the session vwap (reset each utc day) with ±1
+// volume-weighted std dev bands, and the vwap anchored at the first candle
+// at or after anchor. nil when either range has no volume.
+func computeVWAP(highs, lows, closes, volumes []float64, timestamps []int64, anchor int64) *VWAPResult {
+	n := len(closes)
+	if n == 0 || len(highs) != n || len(lows) != n || len(volumes) != n || len(timestamps) != n {
+		return nil
+	}
+
+	typical := make([]float64, n)
+	for i := range typical {
+		typical[i] = (highs[i] + lows[i] + closes[i]) / 3
+	}
+
+	series := make([]float64, 0, n)
+	sessionStart := 0
+	var pv, vol float64
+	for i := 0; i < n; i++ {
+		if i > 0 && utcDay(timestamps[i]) != utcDay(timestamps[i-1]) {
+			sessionStart = i
+			pv, vol = 0, 0
+		}
+		pv += typical[i] * volumes[i]
+		vol += volumes[i]
+		if vol > 0 {
+			series = append(series, pv/vol)
+		} else {
+			series = append(series, 0)
+		}
+	}
+	if vol <= 0 {
+		return nil
+	}
+	session := pv / vol
+
+	var variance float64
+	for i := sessionStart; i < n; i++ {
+		variance += volumes[i] * math.Pow(typical[i]-session, 2)
+	}
+	stdDev := math.Sqrt(variance / vol)
+
+	anchorIdx := -1
+	for i, ts := range timestamps {
+		if ts >= anchor {
+			anchorIdx = i
+			break
+		}
+	}
+	if anchorIdx < 0 {
+		return nil
+	}
+	var apv, avol float64
+	for i := anchorIdx; i < n; i++ {
+		apv += typical[i] * volumes[i]
+		avol += volumes[i]
+	}
+	if avol <= 0 {
+		return nil
+	}
+
+	signal := "BELOW"
+	if closes[n-1] > session {
+		signal = "ABOVE"
+	}
+	return &VWAPResult{
+		Session:         session,
+		SessionUpper:    session + stdDev,
+		SessionLower:    session - stdDev,
+		Anchored:        apv / avol,
+		AnchorTimestamp: timestamps[anchorIdx],
+		Signal:          signal,
+		SessionSeries:   series,
+	}
+}
+
+// computeIchimoku needs senkou+kijun candles so the cloud under the current
+// bar (plotted kijun bars ago) exists
+func computeIchimoku(highs, lows, closes []float64, tenkanPeriod, kijunPeriod, senkouPeriod int) *IchimokuResult {
+	n := len(closes)
+	if tenkanPeriod <= 0 || kijunPeriod <= 0 || senkouPeriod <= 0 ||
+		len(highs) != n || len(lows) != n || n < senkouPeriod+kijunPeriod {
+		return nil
+	}
+
+	last := n - 1
+	tenkan := midpoint(highs, lows, last, tenkanPeriod)
+	kijun := midpoint(highs, lows, last, kijunPeriod)
+
+	origin := last - kijunPeriod
+	senkouA := (midpoint(highs, lows, origin, tenkanPeriod) + midpoint(highs, lows, origin, kijunPeriod)) / 2
+	senkouB := midpoint(highs, lows, origin, senkouPeriod)
+
+	price := closes[last]
+	signal := "IN_CLOUD"
+	switch {
+	case price > math.Max(senkouA, senkouB):
+		signal = "ABOVE_CLOUD"
+	case price < math.Min(senkouA, senkouB):
+		signal = "BELOW_CLOUD"
+	}
+
+	prevTenkan := midpoint(highs, lows, last-1, tenkanPeriod)
+	prevKijun := midpoint(highs, lows, last-1, kijunPeriod)
+	tkCross := "NONE"
+	switch {
+	case prevTenkan <= prevKijun && tenkan > kijun:
+		tkCross = "BULLISH"
+	case prevTenkan >= prevKijun && tenkan < kijun:
+		tkCross = "BEARISH"
+	}
+
+	futureCloud := "BEARISH"
+	if (tenkan+kijun)/2 >= midpoint(highs, lows, last, senkouPeriod) {
+		futureCloud = "BULLISH"
+	}
+
+	return &IchimokuResult{
+		Tenkan:      tenkan,
+		Kijun:       kijun,
+		SenkouA:     senkouA,
+		SenkouB:     senkouB,
+		Chikou:      price,
+		Signal:      signal,
+		TKCross:     tkCross,
+		FutureCloud: futureCloud,
+	}
+}
+
+// midpoint is (highest high + lowest low) / 2 over the period bars ending at end
+func midpoint(highs, lows []float64, end, period int) float64 {
+	highest, lowest := highLow(highs[end+1-period:end+1], lows[end+1-period:end+1])
+	return (highest + lowest) / 2
+}
+
+func highLow(highs, lows []float64) (float64, float64) {
+	highest, lowest := math.Inf(-1), math.Inf(1)
+	for i := range highs {
+		highest = math.Max(highest, highs[i])
+		lowest = math.Min(lowest, lows[i])
+	}
+	return highest, lowest
+}
+
+// computeOBV measures the obv change over the lookback against the volume
+// traded in it, and flags when that disagrees with price
+func computeOBV(closes, volumes []float64, lookback int) *OBVResult {
+	n := len(closes)
+	if lookback <= 0 || n < lookback+1 || len(volumes) != n {
+		return nil
+	}
+
+	series := make([]float64, n)
+	for i := 1; i < n; i++ {
+		switch {
+		case closes[i] > closes[i-1]:
+			series[i] = series[i-1] + volumes[i]
+		case closes[i] < closes[i-1]:
+			series[i] = series[i-1] - volumes[i]
+		default:
+			series[i] = series[i-1]
+		}
+	}
+
+	last := n - 1
+	var slope float64
+	if traded := sum(volumes[n-lookback:]); traded > 1e-10 {
+		slope = (series[last] - series[last-lookback]) / traded
+	}
+
+	signal := "FLAT"
+	switch {
+	case slope > 0.2:
+		signal = "RISING"
+	case slope < -0.2:
+		signal = "FALLING"
+	}
+
+	priceChange := closes[last] - closes[last-lookback]
+	divergence := "NONE"
+	switch {
+	case priceChange > 0 && slope < -0.2:
+		divergence = "BEARISH"
+	case priceChange < 0 && slope > 0.2:
+		divergence = "BULLISH"
+	}
+
+	return &OBVResult{Value: series[last], Slope: slope, Signal: signal, Divergence: divergence, Series: series}
+}
+
+// computeSupertrend ratchets atr bands around hl2 and flips on a close
+// through the active band; the trend starts UP on the first bar with an atr
+func computeSupertrend(highs, lows, closes []float64, period int, multiplier float64) *SupertrendResult {
+	n := len(closes)
+	if period <= 0 || n < period+2 {
+		return nil
+	}
+	atr := computeATR(highs, lows, closes, period)
+	if atr == nil {
+		return nil
+	}
+
+	series := make([]float64, n)
+	up, prevUp := true, true
+	var finalUpper, finalLower float64
+	for i := period; i < n; i++ {
+		hl2 := (highs[i] + lows[i]) / 2
+		band := multiplier * atr.Series[i-1]
+		basicUpper, basicLower := hl2+band, hl2-band
+
+		if i == period {
+			finalUpper, finalLower = basicUpper, basicLower
+		} else {
+			prevClose := closes[i-1]
+			if basicUpper < finalUpper || prevClose > finalUpper {
+				finalUpper = basicUpper
+			}
+			if basicLower > finalLower || prevClose < finalLower {
+				finalLower = basicLower
+			}
+		}
+
+		prevUp = up
+		if up && closes[i] < finalLower {
+			up = false
+		} else if !up && closes[i] > finalUpper {
+			up = true
+		}
+		if up {
+			series[i] = finalLower
+		} else {
+			series[i] = finalUpper
+		}
+	}
+
+	direction := "DOWN"
+	if up {
+		direction = "UP"
+	}
+	return &SupertrendResult{Value: series[n-1], Direction: direction, Flipped: up != prevUp, Series: series}
+}
+
+// computeDonchian judges breakouts against the channel of the period bars
+// before the latest one
+func computeDonchian(highs, lows, closes []float64, period int) *DonchianResult {
+	n := len(closes)
+	if period <= 0 || n < period+1 || len(highs) != n || len(lows) != n {
+		return nil
+	}
+
+	upper, lower := highLow(highs[n-period:], lows[n-period:])
+	prevUpper, prevLower := highLow(highs[n-1-period:n-1], lows[n-1-period:n-1])
+	middle := (upper + lower) / 2
+	var width float64
+	if math.Abs(middle) > 1e-10 {
+		width = (upper - lower) / middle * 100
+	}
+
+	signal := "INSIDE"
+	switch price := closes[n-1]; {
+	case price > prevUpper:
+		signal = "BREAKOUT_UP"
+	case price < prevLower:
+		signal = "BREAKOUT_DOWN"
+	}
+	return &DonchianResult{Upper: upper, Middle: middle, Lower: lower, WidthPercent: width, Signal: signal, Period: period}
+}
+
+// computePivots derives classic and fibonacci levels from the utc day before
+// the last candle's day. nil when the window doesn't reach a previous day.
+func computePivots(highs, lows, closes []float64, timestamps []int64) *PivotResult {
+	n := len(closes)
+	if n < 2 || len(highs) != n || len(lows) != n || len(timestamps) != n {
+		return nil
+	}
+
+	currentDay := utcDay(timestamps[n-1])
+	end := -1
+	for i := n - 1; i >= 0; i-- {
+		if utcDay(timestamps[i]) != currentDay {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil
+	}
+	prevDay := utcDay(timestamps[end])
+	start := end
+	for start > 0 && utcDay(timestamps[start-1]) == prevDay {
+		start--
+	}
+
+	high, low := highLow(highs[start:end+1], lows[start:end+1])
+	pivot := (high + low + closes[end]) / 3
+	rng := high - low
+
+	position := "BELOW_PIVOT"
+	if closes[n-1] > pivot {
+		position = "ABOVE_PIVOT"
+	}
+	return &PivotResult{
+		Classic: PivotLevels{
+			Pivot: pivot,
+			R1:    2*pivot - low,
+			R2:    pivot + rng,
+			R3:    high + 2*(pivot-low),
+			S1:    2*pivot - high,
+			S2:    pivot - rng,
+			S3:    low - 2*(high-pivot),
+		},
+		Fibonacci: PivotLevels{
+			Pivot: pivot,
+			R1:    pivot + 0.382*rng,
+			R2:    pivot + 0.618*rng,
+			R3:    pivot + rng,
+			S1:    pivot - 0.382*rng,
+			S2:    pivot - 0.618*rng,
+			S3:    pivot - rng,
+		},
+		SessionTimestamp: prevDay * secondsPerDay,
+		Position:         position,
+	}
+}
+
+// computeEMALevels returns the latest ema for each period the window covers
+// (sorted, deduplicated) and how they're stacked
+func computeEMALevels(closes []float64, periods []int32) ([]EMALevel, string) {
+	sorted := append([]int32(nil), periods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var levels []EMALevel
+	for i, p := range sorted {
+		if p <= 0 || (i > 0 && p == sorted[i-1]) {
+			continue
+		}
+		series := computeEMA(closes, int(p))
+		if series == nil {
+			continue
+		}
+		value := series[len(series)-1]
+		levels = append(levels, EMALevel{Period: p, Value: value, Trend: emaTrend(closes[len(closes)-1], value)})
+	}
+	return levels, emaAlignment(levels)
+}
+
+// emaAlignment is BULLISH when every shorter ema sits above the longer one,
+// BEARISH when every one sits below, MIXED otherwise ("" for fewer than two)
+func emaAlignment(levels []EMALevel) string {
+	if len(levels) < 2 {
+		return ""
+	}
+	bullish, bearish := true, true
+	for i := 1; i < len(levels); i++ {
+		bullish = bullish && levels[i-1].Value > levels[i].Value
+		bearish = bearish && levels[i-1].Value < levels[i].Value
+	}
+	switch {
+	case bullish:
+		return "BULLISH"
+	case bearish:
+		return "BEARISH"
+	default:
+		return "MIXED"
+	}
+}
+
+// overallSignal maps the net bullish/bearish vote to the engine's labels
+func overallSignal(bullish, bearish int32) string {
+	switch net := bullish - bearish; {
+	case net >= 3:
+		return "STRONG_BUY"
+	case net >= 1:
+		return "BUY"
+	case net == 0:
+		return "NEUTRAL"
+	case net >= -2:
+		return "SELL"
+	default:
+		return "STRONG_SELL"
+	}
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
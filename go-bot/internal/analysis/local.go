@@ -0,0 +1,203 @@
+// in-process indicator engine, used when the rust engine isn't configured or
+// can't be reached.
+package analysis
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Analyzer is anything that can produce a full AnalysisResult
+// (the grpc Client and the native Local engine both qualify)
+type Analyzer interface {
+	AnalyzeAll(ctx context.Context, candles []Candle, opts *AnalyzeOptions) (*AnalysisResult, error)
+}
+
+// Local computes every indicator natively in go, matching the rust engine
+type Local struct{}
+
+// NewLocal creates the native go indicator engine
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// AnalyzeAll runs all indicators. Zero option fields fall back to the
+// defaults, and indicators without enough data are left nil, as the rust
+// engine does.
+func (l *Local) AnalyzeAll(ctx context.Context, candles []Candle, opts *AnalyzeOptions) (*AnalysisResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	o := withDefaults(opts)
+
+	n := len(candles)
+	highs := make([]float64, n)
+	lows := make([]float64, n)
+	closes := make([]float64, n)
+	volumes := make([]float64, n)
+	timestamps := make([]int64, n)
+	for i, c := range candles {
+		highs[i], lows[i], closes[i], volumes[i] = c.High, c.Low, c.Close, c.Volume
+		timestamps[i] = c.Timestamp
+	}
+
+	rsiPeriod := int(o.RSIPeriod)
+	result := &AnalysisResult{
+		RSI:        computeRSI(closes, rsiPeriod),
+		MACD:       computeMACD(closes, int(o.MACDFast), int(o.MACDSlow), int(o.MACDSignal)),
+		Bollinger:  computeBollinger(closes, int(o.BBPeriod), o.BBStdDev),
+		Volume:     detectVolumeSpike(volumes, int(o.VolumeLookback), o.VolumeThreshold),
+		ATR:        computeATR(highs, lows, closes, rsiPeriod),
+		ADX:        computeADX(highs, lows, closes, rsiPeriod),
+		Stochastic: computeStochastic(highs, lows, closes, rsiPeriod, 3, 3),
+		Regime:     classifyRegime(highs, lows, closes, rsiPeriod),
+		VWAP:       computeVWAP(highs, lows, closes, volumes, timestamps, o.VWAPAnchor),
+		Ichimoku:   computeIchimoku(highs, lows, closes, int(o.IchimokuTenkan), int(o.IchimokuKijun), int(o.IchimokuSenkou)),
+		OBV:        computeOBV(closes, volumes, int(o.VolumeLookback)),
+		Supertrend: computeSupertrend(highs, lows, closes, int(o.SupertrendPeriod), o.SupertrendMultiplier),
+		Donchian:   computeDonchian(highs, lows, closes, int(o.DonchianPeriod)),
+		Pivots:     computePivots(highs, lows, closes, timestamps),
+	}
+	result.EMAs, result.EMAAlignment = computeEMALevels(closes, o.EMAPeriods)
+	if series := computeEMA(closes, int(o.EMAPeriod)); series != nil {
+		value := series[len(series)-1]
+		result.EMA = &EMAResult{Value: value, Trend: emaTrend(closes[n-1], value), Series: series}
+	}
+
+	vote(result)
+
+	return result, nil
+}
+
+// vote fills the overall signal the same way the engine does. volume, atr,
+// adx, regime and the structure indicators (vwap, ichimoku, obv, supertrend,
+// donchian, pivots) don't count.
+func vote(result *AnalysisResult) {
+	var bullish, bearish int32
+	if result.RSI != nil {
+		switch result.RSI.Signal {
+		case "OVERSOLD":
+			bullish++
+		case "OVERBOUGHT":
+			bearish++
+		}
+	}
+	if result.MACD != nil {
+		switch result.MACD.Signal {
+		case "BULLISH":
+			bullish++
+		case "BEARISH":
+			bearish++
+		}
+	}
+	if result.Bollinger != nil {
+		switch result.Bollinger.Signal {
+		case "LOWER_BAND":
+			bullish++
+		case "UPPER_BAND":
+			bearish++
+		}
+	}
+	if result.EMA != nil {
+		switch result.EMA.Trend {
+		case "ABOVE":
+			bullish++
+		case "BELOW":
+			bearish++
+		}
+	}
+	if result.Stochastic != nil {
+		switch result.Stochastic.Signal {
+		case "OVERSOLD", "BULLISH_CROSS":
+			bullish++
+		case "OVERBOUGHT", "BEARISH_CROSS":
+			bearish++
+		}
+	}
+	result.BullishCount = bullish
+	result.BearishCount = bearish
+	result.OverallSignal = overallSignal(bullish, bearish)
+}
+
+// withDefaults fills zero fields the same way the engine's request handler does
+func withDefaults(opts *AnalyzeOptions) AnalyzeOptions {
+	d := *DefaultAnalyzeOptions()
+	if opts == nil {
+		return d
+	}
+	o := *opts
+	if o.RSIPeriod <= 0 {
+		o.RSIPeriod = d.RSIPeriod
+	}
+	if o.MACDFast <= 0 {
+		o.MACDFast = d.MACDFast
+	}
+	if o.MACDSlow <= 0 {
+		o.MACDSlow = d.MACDSlow
+	}
+	if o.MACDSignal <= 0 {
+		o.MACDSignal = d.MACDSignal
+	}
+	if o.BBPeriod <= 0 {
+		o.BBPeriod = d.BBPeriod
+	}
+	if o.BBStdDev <= 0 {
+		o.BBStdDev = d.BBStdDev
+	}
+	if o.EMAPeriod <= 0 {
+		o.EMAPeriod = d.EMAPeriod
+	}
+	if o.VolumeLookback <= 0 {
+		o.VolumeLookback = d.VolumeLookback
+	}
+	if o.VolumeThreshold <= 0 {
+		o.VolumeThreshold = d.VolumeThreshold
+	}
+	if o.IchimokuTenkan <= 0 {
+		o.IchimokuTenkan = d.IchimokuTenkan
+	}
+	if o.IchimokuKijun <= 0 {
+		o.IchimokuKijun = d.IchimokuKijun
+	}
+	if o.IchimokuSenkou <= 0 {
+		o.IchimokuSenkou = d.IchimokuSenkou
+	}
+	if o.SupertrendPeriod <= 0 {
+		o.SupertrendPeriod = d.SupertrendPeriod
+	}
+	if o.SupertrendMultiplier <= 0 {
+		o.SupertrendMultiplier = d.SupertrendMultiplier
+	}
+	if o.DonchianPeriod <= 0 {
+		o.DonchianPeriod = d.DonchianPeriod
+	}
+	if len(o.EMAPeriods) == 0 {
+		o.EMAPeriods = d.EMAPeriods
+	}
+	return o
+}
+
+// Fallback tries the primary analyzer and switches to the secondary when
+// the primary call fails (e.g. the rust engine went away after startup)
+type Fallback struct {
+	primary   Analyzer
+	secondary Analyzer
+}
+
+// NewFallback wraps primary with a secondary analyzer
+func NewFallback(primary, secondary Analyzer) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+// AnalyzeAll runs the primary analyzer, falling back to the secondary on error
+func (f *Fallback) AnalyzeAll(ctx context.Context, candles []Candle, opts *AnalyzeOptions) (*AnalysisResult, error) {
+	result, err := f.primary.AnalyzeAll(ctx, candles, opts)
+	if err == nil {
+		return result, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	slog.Warn("primary indicator engine failed, using fallback", "error", err)
+	return f.secondary.AnalyzeAll(ctx, candles, opts)
+}
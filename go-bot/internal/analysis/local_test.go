@@ -0,0 +1,306 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// goldenCandles is the dataset the reference values below were produced
+// from, by running rust-engine/src/indicators over the same 120 bars with
+// the default AnalyzeAll parameters. Bars are 4h apart starting at the epoch,
+// so the window spans 20 utc days. The last bar carries a volume spike.
+// rust-engine/src/indicators/golden.rs rebuilds the same candles and checks
+// the same values; its ignored print_golden_values test regenerates them.
+func goldenCandles() []Candle {
+	const n = 120
+	candles := make([]Candle, n)
+	for i := range candles {
+		x := float64(i)
+		base := 100 + 10*math.Sin(x*0.15) + x*0.3
+		vol := 1000 + 300*math.Sin(x*0.9)
+		if i == n-1 {
+			vol += 2500
+		}
+		candles[i] = Candle{
+			High:      base + 1 + 0.5*math.Abs(math.Cos(x*0.7)),
+			Low:       base - 1 - 0.5*math.Abs(math.Sin(x*0.4)),
+			Close:     base + 0.3*math.Sin(x*1.3),
+			Volume:    vol,
+			Timestamp: int64(i) * 4 * 3600,
+		}
+	}
+	return candles
+}
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+		t.Errorf("%s = %.15g, rust engine = %.15g", name, got, want)
+	}
+}
+
+func TestLocal_MatchesRustEngine(t *testing.T) {
+	res, err := NewLocal().AnalyzeAll(context.Background(), goldenCandles(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "rsi", res.RSI.Value, 46.12416852922325)
+	assertClose(t, "rsi[13]", res.RSI.Series[13], 93.68702441508734)
+	assertClose(t, "rsi[50]", res.RSI.Series[50], 90.04127463268296)
+
+	assertClose(t, "macd", res.MACD.MACDLine, -1.209678612250201)
+	assertClose(t, "macd signal", res.MACD.SignalLine, -0.8388896912117019)
+	assertClose(t, "macd hist", res.MACD.Histogram, -0.37078892103849914)
+	assertClose(t, "macd hist[33]", res.MACD.HistogramSeries[33], -0.20031591965045914)
+	assertClose(t, "macd hist[80]", res.MACD.HistogramSeries[80], 0.41917364087126385)
+
+	assertClose(t, "bb upper", res.Bollinger.Upper, 136.3354659714733)
+	assertClose(t, "bb middle", res.Bollinger.Middle, 128.467209160638)
+	assertClose(t, "bb lower", res.Bollinger.Lower, 120.59895234980267)
+	assertClose(t, "bb bandwidth", res.Bollinger.Bandwidth, 0.12249439934507622)
+	assertClose(t, "bb %b", res.Bollinger.PercentB, 0.4118851176009358)
+
+	assertClose(t, "ema", res.EMA.Value, 127.67794019190865)
+
+	assertClose(t, "volume avg", res.Volume.AverageVolume, 996.3377224353628)
+	assertClose(t, "volume ratio", res.Volume.Ratio, 3.5977678792844143)
+
+	assertClose(t, "atr", res.ATR.Value, 2.6254080740447385)
+	assertClose(t, "atr %", res.ATR.Percent, 2.065939505804016)
+	assertClose(t, "atr[13]", res.ATR.Series[13], 2.767641184187712)
+	assertClose(t, "atr[60]", res.ATR.Series[60], 2.742078794815643)
+
+	assertClose(t, "adx", res.ADX.Value, 35.09756860156656)
+	assertClose(t, "+di", res.ADX.PlusDI, 13.043903375213823)
+	assertClose(t, "-di", res.ADX.MinusDI, 15.535713524830305)
+	assertClose(t, "adx[26]", res.ADX.Series[26], 38.54139063450936)
+	assertClose(t, "adx[90]", res.ADX.Series[90], 49.938711761159276)
+
+	assertClose(t, "stoch k", res.Stochastic.K, 38.70541121873335)
+	assertClose(t, "stoch d", res.Stochastic.D, 27.91967514290663)
+	assertClose(t, "stoch d[17]", res.Stochastic.DSeries[17], 79.1172808941217)
+	assertClose(t, "stoch d[70]", res.Stochastic.DSeries[70], 9.630606808830734)
+
+	assertClose(t, "regime confidence", res.Regime.Confidence, 70.19513720313311)
+
+	// series lengths follow the engine's alignment
+	if len(res.RSI.Series) != 119 || len(res.ATR.Series) != 119 || len(res.ADX.Series) != 119 {
+		t.Errorf("wilder series lengths = %d/%d/%d, want 119", len(res.RSI.Series), len(res.ATR.Series), len(res.ADX.Series))
+	}
+	if len(res.MACD.HistogramSeries) != 120 || len(res.Stochastic.DSeries) != 120 || len(res.EMA.Series) != 120 {
+		t.Error("close-aligned series should have one entry per candle")
+	}
+
+	labels := map[string][2]string{
+		"rsi":        {res.RSI.Signal, "NEUTRAL"},
+		"macd":       {res.MACD.Signal, "BEARISH"},
+		"bollinger":  {res.Bollinger.Signal, "MIDDLE"},
+		"ema":        {res.EMA.Trend, "BELOW"},
+		"volume":     {res.Volume.Signal, "SPIKE"},
+		"atr":        {res.ATR.Signal, "NORMAL"},
+		"adx":        {res.ADX.Signal, "TRENDING"},
+		"adx dir":    {res.ADX.TrendDir, "DOWN"},
+		"stochastic": {res.Stochastic.Signal, "NEUTRAL"},
+		"regime":     {res.Regime.Regime, "trending"},
+		"overall":    {res.OverallSignal, "SELL"},
+	}
+	for name, l := range labels {
+		if l[0] != l[1] {
+			t.Errorf("%s signal = %s, want %s", name, l[0], l[1])
+		}
+	}
+	if res.MACD.Crossover || !res.Volume.IsSpike {
+		t.Errorf("crossover=%v spike=%v, want false/true", res.MACD.Crossover, res.Volume.IsSpike)
+	}
+	if res.BullishCount != 0 || res.BearishCount != 2 {
+		t.Errorf("votes = %d/%d, want 0/2", res.BullishCount, res.BearishCount)
+	}
+	want := "Strong down trend detected (ADX=35.1). Favor trend-following entries with wider stops."
+	if res.Regime.Description != want {
+		t.Errorf("description = %q", res.Regime.Description)
+	}
+}
+
+func TestLocal_StructureMatchesRustEngine(t *testing.T) {
+	res, err := NewLocal().AnalyzeAll(context.Background(), goldenCandles(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "vwap", res.VWAP.Session, 125.91658240533076)
+	assertClose(t, "vwap upper", res.VWAP.SessionUpper, 127.17100322736798)
+	assertClose(t, "vwap lower", res.VWAP.SessionLower, 124.66216158329354)
+	assertClose(t, "vwap anchored", res.VWAP.Anchored, 118.19275311495367)
+
+	assertClose(t, "tenkan", res.Ichimoku.Tenkan, 125.58573924713352)
+	assertClose(t, "kijun", res.Ichimoku.Kijun, 131.36429142608824)
+	assertClose(t, "senkou a", res.Ichimoku.SenkouA, 128.66630460982432)
+	assertClose(t, "senkou b", res.Ichimoku.SenkouB, 124.7786602444445)
+
+	assertClose(t, "obv", res.OBV.Value, 8541.801499325611)
+	assertClose(t, "obv slope", res.OBV.Slope, -0.373702844202912)
+	assertClose(t, "obv[60]", res.OBV.Series[60], 8499.013936691346)
+
+	assertClose(t, "supertrend", res.Supertrend.Value, 132.17728785653404)
+	assertClose(t, "supertrend[10]", res.Supertrend.Series[10], 104.65212658721724)
+	assertClose(t, "supertrend[70]", res.Supertrend.Series[70], 120.25537746754452)
+
+	assertClose(t, "donchian upper", res.Donchian.Upper, 137.8195380031143)
+	assertClose(t, "donchian lower", res.Donchian.Lower, 122.85989990381107)
+	assertClose(t, "donchian width", res.Donchian.WidthPercent, 11.477420865580136)
+	if res.Donchian.Period != 20 {
+		t.Errorf("donchian period = %d, want 20", res.Donchian.Period)
+	}
+
+	assertClose(t, "pivot", res.Pivots.Classic.Pivot, 125.59105537223964)
+	assertClose(t, "r1", res.Pivots.Classic.R1, 128.21570008422816)
+	assertClose(t, "r3", res.Pivots.Classic.R3, 134.41492562636577)
+	assertClose(t, "s2", res.Pivots.Classic.S2, 119.39182983010203)
+	assertClose(t, "s3", res.Pivots.Classic.S3, 115.81724899995294)
+	assertClose(t, "fib r2", res.Pivots.Fibonacci.R2, 129.42217675728068)
+	assertClose(t, "fib s1", res.Pivots.Fibonacci.S1, 123.22295121514307)
+
+	// 200 is longer than the window and is skipped
+	if len(res.EMAs) != 3 || res.EMAs[0].Period != 12 || res.EMAs[2].Period != 50 {
+		t.Fatalf("emas = %+v, want periods 12/26/50", res.EMAs)
+	}
+	assertClose(t, "ema12", res.EMAs[0].Value, 126.6329066173362)
+	assertClose(t, "ema26", res.EMAs[1].Value, 127.8425852295864)
+	assertClose(t, "ema50", res.EMAs[2].Value, 126.39438455229015)
+
+	labels := map[string][2]string{
+		"vwap":         {res.VWAP.Signal, "ABOVE"},
+		"cloud":        {res.Ichimoku.Signal, "IN_CLOUD"},
+		"tk cross":     {res.Ichimoku.TKCross, "NONE"},
+		"future cloud": {res.Ichimoku.FutureCloud, "BULLISH"},
+		"obv":          {res.OBV.Signal, "FALLING"},
+		"obv div":      {res.OBV.Divergence, "NONE"},
+		"supertrend":   {res.Supertrend.Direction, "DOWN"},
+		"donchian":     {res.Donchian.Signal, "INSIDE"},
+		"pivots":       {res.Pivots.Position, "ABOVE_PIVOT"},
+		"ema26 trend":  {res.EMAs[1].Trend, "BELOW"},
+		"alignment":    {res.EMAAlignment, "MIXED"},
+	}
+	for name, l := range labels {
+		if l[0] != l[1] {
+			t.Errorf("%s = %s, want %s", name, l[0], l[1])
+		}
+	}
+	if res.VWAP.AnchorTimestamp != 0 || res.Pivots.SessionTimestamp != 1555200 || res.Supertrend.Flipped {
+		t.Errorf("anchor=%d session=%d flipped=%v", res.VWAP.AnchorTimestamp, res.Pivots.SessionTimestamp, res.Supertrend.Flipped)
+	}
+
+	// anchoring between candles starts at the next one
+	anchored, _ := NewLocal().AnalyzeAll(context.Background(), goldenCandles(), &AnalyzeOptions{VWAPAnchor: 100*4*3600 + 1})
+	assertClose(t, "vwap anchored@101", anchored.VWAP.Anchored, 127.8620238445712)
+	if anchored.VWAP.AnchorTimestamp != 1454400 {
+		t.Errorf("anchor timestamp = %d, want 1454400", anchored.VWAP.AnchorTimestamp)
+	}
+}
+
+func TestEMAAlignment(t *testing.T) {
+	rising := make([]float64, 60)
+	for i := range rising {
+		rising[i] = 100 + float64(i)
+	}
+	levels, align := computeEMALevels(rising, []int32{50, 12, 26, 12})
+	if len(levels) != 3 || levels[0].Period != 12 || align != "BULLISH" {
+		t.Errorf("rising: levels=%+v align=%s", levels, align)
+	}
+	for i := range rising {
+		rising[i] = 200 - float64(i)
+	}
+	if _, align := computeEMALevels(rising, []int32{12, 26}); align != "BEARISH" {
+		t.Errorf("falling alignment = %s", align)
+	}
+	if _, align := computeEMALevels(rising, []int32{12}); align != "" {
+		t.Errorf("single ema alignment = %q", align)
+	}
+}
+
+func TestLocal_InsufficientData(t *testing.T) {
+	res, err := NewLocal().AnalyzeAll(context.Background(), goldenCandles()[:20], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 bars: enough for rsi/atr/bollinger, not for macd, ema(21) or adx
+	if res.RSI == nil || res.ATR == nil || res.Bollinger == nil {
+		t.Error("expected rsi, atr and bollinger with 20 bars")
+	}
+	if res.MACD != nil || res.EMA != nil || res.ADX != nil || res.Regime != nil {
+		t.Error("expected macd, ema, adx and regime to be omitted")
+	}
+	if res.Ichimoku != nil || res.Donchian != nil || res.Pivots == nil || len(res.EMAs) != 1 {
+		t.Error("expected only pivots and ema12 among the structure indicators")
+	}
+
+	empty, err := NewLocal().AnalyzeAll(context.Background(), nil, nil)
+	if err != nil || empty.RSI != nil || empty.OverallSignal != "NEUTRAL" {
+		t.Errorf("empty input = %+v, %v", empty, err)
+	}
+}
+
+func TestLocal_ZeroOptionsUseDefaults(t *testing.T) {
+	candles := goldenCandles()
+	defaults, _ := NewLocal().AnalyzeAll(context.Background(), candles, nil)
+	zeroed, _ := NewLocal().AnalyzeAll(context.Background(), candles, &AnalyzeOptions{EMAPeriod: 9})
+
+	if zeroed.RSI.Value != defaults.RSI.Value || zeroed.MACD.MACDLine != defaults.MACD.MACDLine {
+		t.Error("zero option fields should fall back to the defaults")
+	}
+	if zeroed.EMA.Value == defaults.EMA.Value {
+		t.Error("explicit ema period should be honored")
+	}
+}
+
+func TestEMAAndRSIVectors(t *testing.T) {
+	// from the engine's ema unit tests
+	ema := computeEMA([]float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, 3)
+	if math.Abs(ema[2]-11) > 1e-10 || math.Abs(ema[3]-12) > 1e-10 {
+		t.Errorf("ema = %v", ema)
+	}
+	if computeEMA([]float64{1, 2, 3}, 5) != nil || computeEMA([]float64{1, 2, 3}, 0) != nil {
+		t.Error("expected nil ema for invalid period")
+	}
+
+	rising := make([]float64, 30)
+	for i := range rising {
+		rising[i] = 100 + float64(i)*2
+	}
+	if r := computeRSI(rising, 14); r.Value != 100 || r.Signal != "OVERBOUGHT" {
+		t.Errorf("all-gains rsi = %f %s", r.Value, r.Signal)
+	}
+	if computeRSI(rising[:3], 14) != nil {
+		t.Error("expected nil rsi with insufficient data")
+	}
+}
+
+type failingAnalyzer struct{ calls int }
+
+func (f *failingAnalyzer) AnalyzeAll(context.Context, []Candle, *AnalyzeOptions) (*AnalysisResult, error) {
+	f.calls++
+	return nil, errors.New("connection refused")
+}
+
+func TestFallback(t *testing.T) {
+	primary := &failingAnalyzer{}
+	fb := NewFallback(primary, NewLocal())
+
+	res, err := fb.AnalyzeAll(context.Background(), goldenCandles(), nil)
+	if err != nil || res.RSI == nil {
+		t.Fatalf("expected local result, got %v, %v", res, err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1", primary.calls)
+	}
+
+	// a cancelled request isn't retried on the fallback
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fb.AnalyzeAll(ctx, goldenCandles(), nil); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
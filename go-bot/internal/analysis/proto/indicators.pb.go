@@ -1354,25 +1354,665 @@ func (x *RegimeResponse) GetDescription() string {
 	return ""
 }
 
+type VWAPResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Session         float64                `protobuf:"fixed64,1,opt,name=session,proto3" json:"session,omitempty"`
+	SessionUpper    float64                `protobuf:"fixed64,2,opt,name=session_upper,json=sessionUpper,proto3" json:"session_upper,omitempty"` // session vwap + 1 volume-weighted std dev
+	SessionLower    float64                `protobuf:"fixed64,3,opt,name=session_lower,json=sessionLower,proto3" json:"session_lower,omitempty"` // session vwap - 1 volume-weighted std dev
+	Anchored        float64                `protobuf:"fixed64,4,opt,name=anchored,proto3" json:"anchored,omitempty"`
+	AnchorTimestamp int64                  `protobuf:"varint,5,opt,name=anchor_timestamp,json=anchorTimestamp,proto3" json:"anchor_timestamp,omitempty"` // first candle included in the anchored vwap
+	Signal          string                 `protobuf:"bytes,6,opt,name=signal,proto3" json:"signal,omitempty"`                                           // ABOVE, BELOW (close vs session vwap)
+	SessionSeries   []float64              `protobuf:"fixed64,7,rep,packed,name=session_series,json=sessionSeries,proto3" json:"session_series,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *VWAPResponse) Reset() {
+	*x = VWAPResponse{}
+	mi := &file_indicators_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VWAPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VWAPResponse) ProtoMessage() {}
+
+func (x *VWAPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VWAPResponse.ProtoReflect.Descriptor instead.
+func (*VWAPResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VWAPResponse) GetSession() float64 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *VWAPResponse) GetSessionUpper() float64 {
+	if x != nil {
+		return x.SessionUpper
+	}
+	return 0
+}
+
+func (x *VWAPResponse) GetSessionLower() float64 {
+	if x != nil {
+		return x.SessionLower
+	}
+	return 0
+}
+
+func (x *VWAPResponse) GetAnchored() float64 {
+	if x != nil {
+		return x.Anchored
+	}
+	return 0
+}
+
+func (x *VWAPResponse) GetAnchorTimestamp() int64 {
+	if x != nil {
+		return x.AnchorTimestamp
+	}
+	return 0
+}
+
+func (x *VWAPResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *VWAPResponse) GetSessionSeries() []float64 {
+	if x != nil {
+		return x.SessionSeries
+	}
+	return nil
+}
+
+type IchimokuResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tenkan        float64                `protobuf:"fixed64,1,opt,name=tenkan,proto3" json:"tenkan,omitempty"`
+	Kijun         float64                `protobuf:"fixed64,2,opt,name=kijun,proto3" json:"kijun,omitempty"`
+	SenkouA       float64                `protobuf:"fixed64,3,opt,name=senkou_a,json=senkouA,proto3" json:"senkou_a,omitempty"`           // span a under the current bar
+	SenkouB       float64                `protobuf:"fixed64,4,opt,name=senkou_b,json=senkouB,proto3" json:"senkou_b,omitempty"`           // span b under the current bar
+	Chikou        float64                `protobuf:"fixed64,5,opt,name=chikou,proto3" json:"chikou,omitempty"`                            // lagging span (current close)
+	Signal        string                 `protobuf:"bytes,6,opt,name=signal,proto3" json:"signal,omitempty"`                              // ABOVE_CLOUD, IN_CLOUD, BELOW_CLOUD
+	TkCross       string                 `protobuf:"bytes,7,opt,name=tk_cross,json=tkCross,proto3" json:"tk_cross,omitempty"`             // BULLISH, BEARISH, NONE
+	FutureCloud   string                 `protobuf:"bytes,8,opt,name=future_cloud,json=futureCloud,proto3" json:"future_cloud,omitempty"` // BULLISH, BEARISH
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IchimokuResponse) Reset() {
+	*x = IchimokuResponse{}
+	mi := &file_indicators_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IchimokuResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IchimokuResponse) ProtoMessage() {}
+
+func (x *IchimokuResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IchimokuResponse.ProtoReflect.Descriptor instead.
+func (*IchimokuResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *IchimokuResponse) GetTenkan() float64 {
+	if x != nil {
+		return x.Tenkan
+	}
+	return 0
+}
+
+func (x *IchimokuResponse) GetKijun() float64 {
+	if x != nil {
+		return x.Kijun
+	}
+	return 0
+}
+
+func (x *IchimokuResponse) GetSenkouA() float64 {
+	if x != nil {
+		return x.SenkouA
+	}
+	return 0
+}
+
+func (x *IchimokuResponse) GetSenkouB() float64 {
+	if x != nil {
+		return x.SenkouB
+	}
+	return 0
+}
+
+func (x *IchimokuResponse) GetChikou() float64 {
+	if x != nil {
+		return x.Chikou
+	}
+	return 0
+}
+
+func (x *IchimokuResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *IchimokuResponse) GetTkCross() string {
+	if x != nil {
+		return x.TkCross
+	}
+	return ""
+}
+
+func (x *IchimokuResponse) GetFutureCloud() string {
+	if x != nil {
+		return x.FutureCloud
+	}
+	return ""
+}
+
+type OBVResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Slope         float64                `protobuf:"fixed64,2,opt,name=slope,proto3" json:"slope,omitempty"`         // obv change over the lookback / volume traded in it
+	Signal        string                 `protobuf:"bytes,3,opt,name=signal,proto3" json:"signal,omitempty"`         // RISING, FALLING, FLAT
+	Divergence    string                 `protobuf:"bytes,4,opt,name=divergence,proto3" json:"divergence,omitempty"` // BULLISH, BEARISH, NONE
+	Series        []float64              `protobuf:"fixed64,5,rep,packed,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OBVResponse) Reset() {
+	*x = OBVResponse{}
+	mi := &file_indicators_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OBVResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OBVResponse) ProtoMessage() {}
+
+func (x *OBVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OBVResponse.ProtoReflect.Descriptor instead.
+func (*OBVResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *OBVResponse) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *OBVResponse) GetSlope() float64 {
+	if x != nil {
+		return x.Slope
+	}
+	return 0
+}
+
+func (x *OBVResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *OBVResponse) GetDivergence() string {
+	if x != nil {
+		return x.Divergence
+	}
+	return ""
+}
+
+func (x *OBVResponse) GetSeries() []float64 {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+type SupertrendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`       // support in an uptrend, resistance in a downtrend
+	Direction     string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"` // UP, DOWN
+	Flipped       bool                   `protobuf:"varint,3,opt,name=flipped,proto3" json:"flipped,omitempty"`    // direction changed on the last bar
+	Series        []float64              `protobuf:"fixed64,4,rep,packed,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SupertrendResponse) Reset() {
+	*x = SupertrendResponse{}
+	mi := &file_indicators_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupertrendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupertrendResponse) ProtoMessage() {}
+
+func (x *SupertrendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupertrendResponse.ProtoReflect.Descriptor instead.
+func (*SupertrendResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SupertrendResponse) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *SupertrendResponse) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *SupertrendResponse) GetFlipped() bool {
+	if x != nil {
+		return x.Flipped
+	}
+	return false
+}
+
+func (x *SupertrendResponse) GetSeries() []float64 {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+type DonchianResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Upper         float64                `protobuf:"fixed64,1,opt,name=upper,proto3" json:"upper,omitempty"`
+	Middle        float64                `protobuf:"fixed64,2,opt,name=middle,proto3" json:"middle,omitempty"`
+	Lower         float64                `protobuf:"fixed64,3,opt,name=lower,proto3" json:"lower,omitempty"`
+	WidthPercent  float64                `protobuf:"fixed64,4,opt,name=width_percent,json=widthPercent,proto3" json:"width_percent,omitempty"`
+	Signal        string                 `protobuf:"bytes,5,opt,name=signal,proto3" json:"signal,omitempty"` // BREAKOUT_UP, BREAKOUT_DOWN, INSIDE
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DonchianResponse) Reset() {
+	*x = DonchianResponse{}
+	mi := &file_indicators_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DonchianResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DonchianResponse) ProtoMessage() {}
+
+func (x *DonchianResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DonchianResponse.ProtoReflect.Descriptor instead.
+func (*DonchianResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DonchianResponse) GetUpper() float64 {
+	if x != nil {
+		return x.Upper
+	}
+	return 0
+}
+
+func (x *DonchianResponse) GetMiddle() float64 {
+	if x != nil {
+		return x.Middle
+	}
+	return 0
+}
+
+func (x *DonchianResponse) GetLower() float64 {
+	if x != nil {
+		return x.Lower
+	}
+	return 0
+}
+
+func (x *DonchianResponse) GetWidthPercent() float64 {
+	if x != nil {
+		return x.WidthPercent
+	}
+	return 0
+}
+
+func (x *DonchianResponse) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+type PivotLevels struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pivot         float64                `protobuf:"fixed64,1,opt,name=pivot,proto3" json:"pivot,omitempty"`
+	R1            float64                `protobuf:"fixed64,2,opt,name=r1,proto3" json:"r1,omitempty"`
+	R2            float64                `protobuf:"fixed64,3,opt,name=r2,proto3" json:"r2,omitempty"`
+	R3            float64                `protobuf:"fixed64,4,opt,name=r3,proto3" json:"r3,omitempty"`
+	S1            float64                `protobuf:"fixed64,5,opt,name=s1,proto3" json:"s1,omitempty"`
+	S2            float64                `protobuf:"fixed64,6,opt,name=s2,proto3" json:"s2,omitempty"`
+	S3            float64                `protobuf:"fixed64,7,opt,name=s3,proto3" json:"s3,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PivotLevels) Reset() {
+	*x = PivotLevels{}
+	mi := &file_indicators_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PivotLevels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PivotLevels) ProtoMessage() {}
+
+func (x *PivotLevels) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PivotLevels.ProtoReflect.Descriptor instead.
+func (*PivotLevels) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PivotLevels) GetPivot() float64 {
+	if x != nil {
+		return x.Pivot
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetR1() float64 {
+	if x != nil {
+		return x.R1
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetR2() float64 {
+	if x != nil {
+		return x.R2
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetR3() float64 {
+	if x != nil {
+		return x.R3
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetS1() float64 {
+	if x != nil {
+		return x.S1
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetS2() float64 {
+	if x != nil {
+		return x.S2
+	}
+	return 0
+}
+
+func (x *PivotLevels) GetS3() float64 {
+	if x != nil {
+		return x.S3
+	}
+	return 0
+}
+
+type PivotResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Classic          *PivotLevels           `protobuf:"bytes,1,opt,name=classic,proto3" json:"classic,omitempty"`
+	Fibonacci        *PivotLevels           `protobuf:"bytes,2,opt,name=fibonacci,proto3" json:"fibonacci,omitempty"`
+	SessionTimestamp int64                  `protobuf:"varint,3,opt,name=session_timestamp,json=sessionTimestamp,proto3" json:"session_timestamp,omitempty"` // start of the utc day the levels come from
+	Position         string                 `protobuf:"bytes,4,opt,name=position,proto3" json:"position,omitempty"`                                          // ABOVE_PIVOT, BELOW_PIVOT
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PivotResponse) Reset() {
+	*x = PivotResponse{}
+	mi := &file_indicators_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PivotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PivotResponse) ProtoMessage() {}
+
+func (x *PivotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PivotResponse.ProtoReflect.Descriptor instead.
+func (*PivotResponse) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PivotResponse) GetClassic() *PivotLevels {
+	if x != nil {
+		return x.Classic
+	}
+	return nil
+}
+
+func (x *PivotResponse) GetFibonacci() *PivotLevels {
+	if x != nil {
+		return x.Fibonacci
+	}
+	return nil
+}
+
+func (x *PivotResponse) GetSessionTimestamp() int64 {
+	if x != nil {
+		return x.SessionTimestamp
+	}
+	return 0
+}
+
+func (x *PivotResponse) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+type EMALevel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        int32                  `protobuf:"varint,1,opt,name=period,proto3" json:"period,omitempty"`
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Trend         string                 `protobuf:"bytes,3,opt,name=trend,proto3" json:"trend,omitempty"` // ABOVE, BELOW
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EMALevel) Reset() {
+	*x = EMALevel{}
+	mi := &file_indicators_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EMALevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EMALevel) ProtoMessage() {}
+
+func (x *EMALevel) ProtoReflect() protoreflect.Message {
+	mi := &file_indicators_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EMALevel.ProtoReflect.Descriptor instead.
+func (*EMALevel) Descriptor() ([]byte, []int) {
+	return file_indicators_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *EMALevel) GetPeriod() int32 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *EMALevel) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *EMALevel) GetTrend() string {
+	if x != nil {
+		return x.Trend
+	}
+	return ""
+}
+
 type AnalyzeAllRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Candles         []*Candle              `protobuf:"bytes,1,rep,name=candles,proto3" json:"candles,omitempty"`
-	RsiPeriod       int32                  `protobuf:"varint,2,opt,name=rsi_period,json=rsiPeriod,proto3" json:"rsi_period,omitempty"`
-	MacdFast        int32                  `protobuf:"varint,3,opt,name=macd_fast,json=macdFast,proto3" json:"macd_fast,omitempty"`
-	MacdSlow        int32                  `protobuf:"varint,4,opt,name=macd_slow,json=macdSlow,proto3" json:"macd_slow,omitempty"`
-	MacdSignal      int32                  `protobuf:"varint,5,opt,name=macd_signal,json=macdSignal,proto3" json:"macd_signal,omitempty"`
-	BbPeriod        int32                  `protobuf:"varint,6,opt,name=bb_period,json=bbPeriod,proto3" json:"bb_period,omitempty"`
-	BbStdDev        float64                `protobuf:"fixed64,7,opt,name=bb_std_dev,json=bbStdDev,proto3" json:"bb_std_dev,omitempty"`
-	EmaPeriod       int32                  `protobuf:"varint,8,opt,name=ema_period,json=emaPeriod,proto3" json:"ema_period,omitempty"`
-	VolumeLookback  int32                  `protobuf:"varint,9,opt,name=volume_lookback,json=volumeLookback,proto3" json:"volume_lookback,omitempty"`
-	VolumeThreshold float64                `protobuf:"fixed64,10,opt,name=volume_threshold,json=volumeThreshold,proto3" json:"volume_threshold,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Candles              []*Candle              `protobuf:"bytes,1,rep,name=candles,proto3" json:"candles,omitempty"`
+	RsiPeriod            int32                  `protobuf:"varint,2,opt,name=rsi_period,json=rsiPeriod,proto3" json:"rsi_period,omitempty"`
+	MacdFast             int32                  `protobuf:"varint,3,opt,name=macd_fast,json=macdFast,proto3" json:"macd_fast,omitempty"`
+	MacdSlow             int32                  `protobuf:"varint,4,opt,name=macd_slow,json=macdSlow,proto3" json:"macd_slow,omitempty"`
+	MacdSignal           int32                  `protobuf:"varint,5,opt,name=macd_signal,json=macdSignal,proto3" json:"macd_signal,omitempty"`
+	BbPeriod             int32                  `protobuf:"varint,6,opt,name=bb_period,json=bbPeriod,proto3" json:"bb_period,omitempty"`
+	BbStdDev             float64                `protobuf:"fixed64,7,opt,name=bb_std_dev,json=bbStdDev,proto3" json:"bb_std_dev,omitempty"`
+	EmaPeriod            int32                  `protobuf:"varint,8,opt,name=ema_period,json=emaPeriod,proto3" json:"ema_period,omitempty"`
+	VolumeLookback       int32                  `protobuf:"varint,9,opt,name=volume_lookback,json=volumeLookback,proto3" json:"volume_lookback,omitempty"`
+	VolumeThreshold      float64                `protobuf:"fixed64,10,opt,name=volume_threshold,json=volumeThreshold,proto3" json:"volume_threshold,omitempty"`
+	VwapAnchor           int64                  `protobuf:"varint,11,opt,name=vwap_anchor,json=vwapAnchor,proto3" json:"vwap_anchor,omitempty"`                                // unix seconds, 0 = first candle
+	IchimokuTenkan       int32                  `protobuf:"varint,12,opt,name=ichimoku_tenkan,json=ichimokuTenkan,proto3" json:"ichimoku_tenkan,omitempty"`                    // default 9
+	IchimokuKijun        int32                  `protobuf:"varint,13,opt,name=ichimoku_kijun,json=ichimokuKijun,proto3" json:"ichimoku_kijun,omitempty"`                       // default 26
+	IchimokuSenkou       int32                  `protobuf:"varint,14,opt,name=ichimoku_senkou,json=ichimokuSenkou,proto3" json:"ichimoku_senkou,omitempty"`                    // default 52
+	SupertrendPeriod     int32                  `protobuf:"varint,15,opt,name=supertrend_period,json=supertrendPeriod,proto3" json:"supertrend_period,omitempty"`              // default 10
+	SupertrendMultiplier float64                `protobuf:"fixed64,16,opt,name=supertrend_multiplier,json=supertrendMultiplier,proto3" json:"supertrend_multiplier,omitempty"` // default 3.0
+	DonchianPeriod       int32                  `protobuf:"varint,17,opt,name=donchian_period,json=donchianPeriod,proto3" json:"donchian_period,omitempty"`                    // default 20
+	EmaPeriods           []int32                `protobuf:"varint,18,rep,packed,name=ema_periods,json=emaPeriods,proto3" json:"ema_periods,omitempty"`                         // default 12, 26, 50, 200
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *AnalyzeAllRequest) Reset() {
 	*x = AnalyzeAllRequest{}
-	mi := &file_indicators_proto_msgTypes[19]
+	mi := &file_indicators_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1384,7 +2024,7 @@ func (x *AnalyzeAllRequest) String() string {
 func (*AnalyzeAllRequest) ProtoMessage() {}
 
 func (x *AnalyzeAllRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_indicators_proto_msgTypes[19]
+	mi := &file_indicators_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1397,7 +2037,7 @@ func (x *AnalyzeAllRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AnalyzeAllRequest.ProtoReflect.Descriptor instead.
 func (*AnalyzeAllRequest) Descriptor() ([]byte, []int) {
-	return file_indicators_proto_rawDescGZIP(), []int{19}
+	return file_indicators_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *AnalyzeAllRequest) GetCandles() []*Candle {
@@ -1470,6 +2110,62 @@ func (x *AnalyzeAllRequest) GetVolumeThreshold() float64 {
 	return 0
 }
 
+func (x *AnalyzeAllRequest) GetVwapAnchor() int64 {
+	if x != nil {
+		return x.VwapAnchor
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetIchimokuTenkan() int32 {
+	if x != nil {
+		return x.IchimokuTenkan
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetIchimokuKijun() int32 {
+	if x != nil {
+		return x.IchimokuKijun
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetIchimokuSenkou() int32 {
+	if x != nil {
+		return x.IchimokuSenkou
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetSupertrendPeriod() int32 {
+	if x != nil {
+		return x.SupertrendPeriod
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetSupertrendMultiplier() float64 {
+	if x != nil {
+		return x.SupertrendMultiplier
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetDonchianPeriod() int32 {
+	if x != nil {
+		return x.DonchianPeriod
+	}
+	return 0
+}
+
+func (x *AnalyzeAllRequest) GetEmaPeriods() []int32 {
+	if x != nil {
+		return x.EmaPeriods
+	}
+	return nil
+}
+
 type AnalyzeAllResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Rsi           *RSIResponse           `protobuf:"bytes,1,opt,name=rsi,proto3" json:"rsi,omitempty"`
@@ -1484,13 +2180,21 @@ type AnalyzeAllResponse struct {
 	Adx           *ADXResponse           `protobuf:"bytes,10,opt,name=adx,proto3" json:"adx,omitempty"`
 	Stochastic    *StochasticResponse    `protobuf:"bytes,11,opt,name=stochastic,proto3" json:"stochastic,omitempty"`
 	Regime        *RegimeResponse        `protobuf:"bytes,12,opt,name=regime,proto3" json:"regime,omitempty"`
+	Vwap          *VWAPResponse          `protobuf:"bytes,13,opt,name=vwap,proto3" json:"vwap,omitempty"`
+	Ichimoku      *IchimokuResponse      `protobuf:"bytes,14,opt,name=ichimoku,proto3" json:"ichimoku,omitempty"`
+	Obv           *OBVResponse           `protobuf:"bytes,15,opt,name=obv,proto3" json:"obv,omitempty"`
+	Supertrend    *SupertrendResponse    `protobuf:"bytes,16,opt,name=supertrend,proto3" json:"supertrend,omitempty"`
+	Donchian      *DonchianResponse      `protobuf:"bytes,17,opt,name=donchian,proto3" json:"donchian,omitempty"`
+	Pivots        *PivotResponse         `protobuf:"bytes,18,opt,name=pivots,proto3" json:"pivots,omitempty"`
+	Emas          []*EMALevel            `protobuf:"bytes,19,rep,name=emas,proto3" json:"emas,omitempty"`
+	EmaAlignment  string                 `protobuf:"bytes,20,opt,name=ema_alignment,json=emaAlignment,proto3" json:"ema_alignment,omitempty"` // BULLISH, BEARISH, MIXED
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AnalyzeAllResponse) Reset() {
 	*x = AnalyzeAllResponse{}
-	mi := &file_indicators_proto_msgTypes[20]
+	mi := &file_indicators_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1502,7 +2206,7 @@ func (x *AnalyzeAllResponse) String() string {
 func (*AnalyzeAllResponse) ProtoMessage() {}
 
 func (x *AnalyzeAllResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_indicators_proto_msgTypes[20]
+	mi := &file_indicators_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1515,7 +2219,7 @@ func (x *AnalyzeAllResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AnalyzeAllResponse.ProtoReflect.Descriptor instead.
 func (*AnalyzeAllResponse) Descriptor() ([]byte, []int) {
-	return file_indicators_proto_rawDescGZIP(), []int{20}
+	return file_indicators_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *AnalyzeAllResponse) GetRsi() *RSIResponse {
@@ -1602,6 +2306,62 @@ func (x *AnalyzeAllResponse) GetRegime() *RegimeResponse {
 	return nil
 }
 
+func (x *AnalyzeAllResponse) GetVwap() *VWAPResponse {
+	if x != nil {
+		return x.Vwap
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetIchimoku() *IchimokuResponse {
+	if x != nil {
+		return x.Ichimoku
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetObv() *OBVResponse {
+	if x != nil {
+		return x.Obv
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetSupertrend() *SupertrendResponse {
+	if x != nil {
+		return x.Supertrend
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetDonchian() *DonchianResponse {
+	if x != nil {
+		return x.Donchian
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetPivots() *PivotResponse {
+	if x != nil {
+		return x.Pivots
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetEmas() []*EMALevel {
+	if x != nil {
+		return x.Emas
+	}
+	return nil
+}
+
+func (x *AnalyzeAllResponse) GetEmaAlignment() string {
+	if x != nil {
+		return x.EmaAlignment
+	}
+	return ""
+}
+
 var File_indicators_proto protoreflect.FileDescriptor
 
 const file_indicators_proto_rawDesc = "" +
@@ -1718,7 +2478,60 @@ const file_indicators_proto_rawDesc = "" +
 	"\n" +
 	"confidence\x18\a \x01(\x01R\n" +
 	"confidence\x12 \n" +
-	"\vdescription\x18\b \x01(\tR\vdescription\"\xe9\x02\n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\"\xf8\x01\n" +
+	"\fVWAPResponse\x12\x18\n" +
+	"\asession\x18\x01 \x01(\x01R\asession\x12#\n" +
+	"\rsession_upper\x18\x02 \x01(\x01R\fsessionUpper\x12#\n" +
+	"\rsession_lower\x18\x03 \x01(\x01R\fsessionLower\x12\x1a\n" +
+	"\banchored\x18\x04 \x01(\x01R\banchored\x12)\n" +
+	"\x10anchor_timestamp\x18\x05 \x01(\x03R\x0fanchorTimestamp\x12\x16\n" +
+	"\x06signal\x18\x06 \x01(\tR\x06signal\x12%\n" +
+	"\x0esession_series\x18\a \x03(\x01R\rsessionSeries\"\xe4\x01\n" +
+	"\x10IchimokuResponse\x12\x16\n" +
+	"\x06tenkan\x18\x01 \x01(\x01R\x06tenkan\x12\x14\n" +
+	"\x05kijun\x18\x02 \x01(\x01R\x05kijun\x12\x19\n" +
+	"\bsenkou_a\x18\x03 \x01(\x01R\asenkouA\x12\x19\n" +
+	"\bsenkou_b\x18\x04 \x01(\x01R\asenkouB\x12\x16\n" +
+	"\x06chikou\x18\x05 \x01(\x01R\x06chikou\x12\x16\n" +
+	"\x06signal\x18\x06 \x01(\tR\x06signal\x12\x19\n" +
+	"\btk_cross\x18\a \x01(\tR\atkCross\x12!\n" +
+	"\ffuture_cloud\x18\b \x01(\tR\vfutureCloud\"\x89\x01\n" +
+	"\vOBVResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x14\n" +
+	"\x05slope\x18\x02 \x01(\x01R\x05slope\x12\x16\n" +
+	"\x06signal\x18\x03 \x01(\tR\x06signal\x12\x1e\n" +
+	"\n" +
+	"divergence\x18\x04 \x01(\tR\n" +
+	"divergence\x12\x16\n" +
+	"\x06series\x18\x05 \x03(\x01R\x06series\"z\n" +
+	"\x12SupertrendResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x18\n" +
+	"\aflipped\x18\x03 \x01(\bR\aflipped\x12\x16\n" +
+	"\x06series\x18\x04 \x03(\x01R\x06series\"\x93\x01\n" +
+	"\x10DonchianResponse\x12\x14\n" +
+	"\x05upper\x18\x01 \x01(\x01R\x05upper\x12\x16\n" +
+	"\x06middle\x18\x02 \x01(\x01R\x06middle\x12\x14\n" +
+	"\x05lower\x18\x03 \x01(\x01R\x05lower\x12#\n" +
+	"\rwidth_percent\x18\x04 \x01(\x01R\fwidthPercent\x12\x16\n" +
+	"\x06signal\x18\x05 \x01(\tR\x06signal\"\x83\x01\n" +
+	"\vPivotLevels\x12\x14\n" +
+	"\x05pivot\x18\x01 \x01(\x01R\x05pivot\x12\x0e\n" +
+	"\x02r1\x18\x02 \x01(\x01R\x02r1\x12\x0e\n" +
+	"\x02r2\x18\x03 \x01(\x01R\x02r2\x12\x0e\n" +
+	"\x02r3\x18\x04 \x01(\x01R\x02r3\x12\x0e\n" +
+	"\x02s1\x18\x05 \x01(\x01R\x02s1\x12\x0e\n" +
+	"\x02s2\x18\x06 \x01(\x01R\x02s2\x12\x0e\n" +
+	"\x02s3\x18\a \x01(\x01R\x02s3\"\xc2\x01\n" +
+	"\rPivotResponse\x121\n" +
+	"\aclassic\x18\x01 \x01(\v2\x17.indicators.PivotLevelsR\aclassic\x125\n" +
+	"\tfibonacci\x18\x02 \x01(\v2\x17.indicators.PivotLevelsR\tfibonacci\x12+\n" +
+	"\x11session_timestamp\x18\x03 \x01(\x03R\x10sessionTimestamp\x12\x1a\n" +
+	"\bposition\x18\x04 \x01(\tR\bposition\"N\n" +
+	"\bEMALevel\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\x05R\x06period\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x14\n" +
+	"\x05trend\x18\x03 \x01(\tR\x05trend\"\xaf\x05\n" +
 	"\x11AnalyzeAllRequest\x12,\n" +
 	"\acandles\x18\x01 \x03(\v2\x12.indicators.CandleR\acandles\x12\x1d\n" +
 	"\n" +
@@ -1734,7 +2547,17 @@ const file_indicators_proto_rawDesc = "" +
 	"ema_period\x18\b \x01(\x05R\temaPeriod\x12'\n" +
 	"\x0fvolume_lookback\x18\t \x01(\x05R\x0evolumeLookback\x12)\n" +
 	"\x10volume_threshold\x18\n" +
-	" \x01(\x01R\x0fvolumeThreshold\"\xc4\x04\n" +
+	" \x01(\x01R\x0fvolumeThreshold\x12\x1f\n" +
+	"\vvwap_anchor\x18\v \x01(\x03R\n" +
+	"vwapAnchor\x12'\n" +
+	"\x0fichimoku_tenkan\x18\f \x01(\x05R\x0eichimokuTenkan\x12%\n" +
+	"\x0eichimoku_kijun\x18\r \x01(\x05R\richimokuKijun\x12'\n" +
+	"\x0fichimoku_senkou\x18\x0e \x01(\x05R\x0eichimokuSenkou\x12+\n" +
+	"\x11supertrend_period\x18\x0f \x01(\x05R\x10supertrendPeriod\x123\n" +
+	"\x15supertrend_multiplier\x18\x10 \x01(\x01R\x14supertrendMultiplier\x12'\n" +
+	"\x0fdonchian_period\x18\x11 \x01(\x05R\x0edonchianPeriod\x12\x1f\n" +
+	"\vema_periods\x18\x12 \x03(\x05R\n" +
+	"emaPeriods\"\xd3\a\n" +
 	"\x12AnalyzeAllResponse\x12)\n" +
 	"\x03rsi\x18\x01 \x01(\v2\x17.indicators.RSIResponseR\x03rsi\x12,\n" +
 	"\x04macd\x18\x02 \x01(\v2\x18.indicators.MACDResponseR\x04macd\x12;\n" +
@@ -1750,7 +2573,17 @@ const file_indicators_proto_rawDesc = "" +
 	"\n" +
 	"stochastic\x18\v \x01(\v2\x1e.indicators.StochasticResponseR\n" +
 	"stochastic\x122\n" +
-	"\x06regime\x18\f \x01(\v2\x1a.indicators.RegimeResponseR\x06regime2\xed\x05\n" +
+	"\x06regime\x18\f \x01(\v2\x1a.indicators.RegimeResponseR\x06regime\x12,\n" +
+	"\x04vwap\x18\r \x01(\v2\x18.indicators.VWAPResponseR\x04vwap\x128\n" +
+	"\bichimoku\x18\x0e \x01(\v2\x1c.indicators.IchimokuResponseR\bichimoku\x12)\n" +
+	"\x03obv\x18\x0f \x01(\v2\x17.indicators.OBVResponseR\x03obv\x12>\n" +
+	"\n" +
+	"supertrend\x18\x10 \x01(\v2\x1e.indicators.SupertrendResponseR\n" +
+	"supertrend\x128\n" +
+	"\bdonchian\x18\x11 \x01(\v2\x1c.indicators.DonchianResponseR\bdonchian\x121\n" +
+	"\x06pivots\x18\x12 \x01(\v2\x19.indicators.PivotResponseR\x06pivots\x12(\n" +
+	"\x04emas\x18\x13 \x03(\v2\x14.indicators.EMALevelR\x04emas\x12#\n" +
+	"\rema_alignment\x18\x14 \x01(\tR\femaAlignment2\xed\x05\n" +
 	"\x13TechnicalIndicators\x12?\n" +
 	"\fCalculateRSI\x12\x16.indicators.RSIRequest\x1a\x17.indicators.RSIResponse\x12B\n" +
 	"\rCalculateMACD\x12\x17.indicators.MACDRequest\x1a\x18.indicators.MACDResponse\x12V\n" +
@@ -1776,7 +2609,7 @@ func file_indicators_proto_rawDescGZIP() []byte {
 	return file_indicators_proto_rawDescData
 }
 
-var file_indicators_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_indicators_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_indicators_proto_goTypes = []any{
 	(*Candle)(nil),             // 0: indicators.Candle
 	(*RSIRequest)(nil),         // 1: indicators.RSIRequest
@@ -1797,8 +2630,16 @@ var file_indicators_proto_goTypes = []any{
 	(*StochasticResponse)(nil), // 16: indicators.StochasticResponse
 	(*RegimeRequest)(nil),      // 17: indicators.RegimeRequest
 	(*RegimeResponse)(nil),     // 18: indicators.RegimeResponse
-	(*AnalyzeAllRequest)(nil),  // 19: indicators.AnalyzeAllRequest
-	(*AnalyzeAllResponse)(nil), // 20: indicators.AnalyzeAllResponse
+	(*VWAPResponse)(nil),       // 19: indicators.VWAPResponse
+	(*IchimokuResponse)(nil),   // 20: indicators.IchimokuResponse
+	(*OBVResponse)(nil),        // 21: indicators.OBVResponse
+	(*SupertrendResponse)(nil), // 22: indicators.SupertrendResponse
+	(*DonchianResponse)(nil),   // 23: indicators.DonchianResponse
+	(*PivotLevels)(nil),        // 24: indicators.PivotLevels
+	(*PivotResponse)(nil),      // 25: indicators.PivotResponse
+	(*EMALevel)(nil),           // 26: indicators.EMALevel
+	(*AnalyzeAllRequest)(nil),  // 27: indicators.AnalyzeAllRequest
+	(*AnalyzeAllResponse)(nil), // 28: indicators.AnalyzeAllResponse
 }
 var file_indicators_proto_depIdxs = []int32{
 	0,  // 0: indicators.RSIRequest.candles:type_name -> indicators.Candle
@@ -1810,41 +2651,50 @@ var file_indicators_proto_depIdxs = []int32{
 	0,  // 6: indicators.ADXRequest.candles:type_name -> indicators.Candle
 	0,  // 7: indicators.StochasticRequest.candles:type_name -> indicators.Candle
 	0,  // 8: indicators.RegimeRequest.candles:type_name -> indicators.Candle
-	0,  // 9: indicators.AnalyzeAllRequest.candles:type_name -> indicators.Candle
-	2,  // 10: indicators.AnalyzeAllResponse.rsi:type_name -> indicators.RSIResponse
-	4,  // 11: indicators.AnalyzeAllResponse.macd:type_name -> indicators.MACDResponse
-	6,  // 12: indicators.AnalyzeAllResponse.bollinger:type_name -> indicators.BollingerResponse
-	8,  // 13: indicators.AnalyzeAllResponse.ema:type_name -> indicators.EMAResponse
-	10, // 14: indicators.AnalyzeAllResponse.volume:type_name -> indicators.VolumeResponse
-	12, // 15: indicators.AnalyzeAllResponse.atr:type_name -> indicators.ATRResponse
-	14, // 16: indicators.AnalyzeAllResponse.adx:type_name -> indicators.ADXResponse
-	16, // 17: indicators.AnalyzeAllResponse.stochastic:type_name -> indicators.StochasticResponse
-	18, // 18: indicators.AnalyzeAllResponse.regime:type_name -> indicators.RegimeResponse
-	1,  // 19: indicators.TechnicalIndicators.CalculateRSI:input_type -> indicators.RSIRequest
-	3,  // 20: indicators.TechnicalIndicators.CalculateMACD:input_type -> indicators.MACDRequest
-	5,  // 21: indicators.TechnicalIndicators.CalculateBollingerBands:input_type -> indicators.BollingerRequest
-	7,  // 22: indicators.TechnicalIndicators.CalculateEMA:input_type -> indicators.EMARequest
-	9,  // 23: indicators.TechnicalIndicators.DetectVolumeSpike:input_type -> indicators.VolumeRequest
-	11, // 24: indicators.TechnicalIndicators.CalculateATR:input_type -> indicators.ATRRequest
-	13, // 25: indicators.TechnicalIndicators.CalculateADX:input_type -> indicators.ADXRequest
-	15, // 26: indicators.TechnicalIndicators.CalculateStochastic:input_type -> indicators.StochasticRequest
-	17, // 27: indicators.TechnicalIndicators.ClassifyRegime:input_type -> indicators.RegimeRequest
-	19, // 28: indicators.TechnicalIndicators.AnalyzeAll:input_type -> indicators.AnalyzeAllRequest
-	2,  // 29: indicators.TechnicalIndicators.CalculateRSI:output_type -> indicators.RSIResponse
-	4,  // 30: indicators.TechnicalIndicators.CalculateMACD:output_type -> indicators.MACDResponse
-	6,  // 31: indicators.TechnicalIndicators.CalculateBollingerBands:output_type -> indicators.BollingerResponse
-	8,  // 32: indicators.TechnicalIndicators.CalculateEMA:output_type -> indicators.EMAResponse
-	10, // 33: indicators.TechnicalIndicators.DetectVolumeSpike:output_type -> indicators.VolumeResponse
-	12, // 34: indicators.TechnicalIndicators.CalculateATR:output_type -> indicators.ATRResponse
-	14, // 35: indicators.TechnicalIndicators.CalculateADX:output_type -> indicators.ADXResponse
-	16, // 36: indicators.TechnicalIndicators.CalculateStochastic:output_type -> indicators.StochasticResponse
-	18, // 37: indicators.TechnicalIndicators.ClassifyRegime:output_type -> indicators.RegimeResponse
-	20, // 38: indicators.TechnicalIndicators.AnalyzeAll:output_type -> indicators.AnalyzeAllResponse
-	29, // [29:39] is the sub-list for method output_type
-	19, // [19:29] is the sub-list for method input_type
-	19, // [19:19] is the sub-list for extension type_name
-	19, // [19:19] is the sub-list for extension extendee
-	0,  // [0:19] is the sub-list for field type_name
+	24, // 9: indicators.PivotResponse.classic:type_name -> indicators.PivotLevels
+	24, // 10: indicators.PivotResponse.fibonacci:type_name -> indicators.PivotLevels
+	0,  // 11: indicators.AnalyzeAllRequest.candles:type_name -> indicators.Candle
+	2,  // 12: indicators.AnalyzeAllResponse.rsi:type_name -> indicators.RSIResponse
+	4,  // 13: indicators.AnalyzeAllResponse.macd:type_name -> indicators.MACDResponse
+	6,  // 14: indicators.AnalyzeAllResponse.bollinger:type_name -> indicators.BollingerResponse
+	8,  // 15: indicators.AnalyzeAllResponse.ema:type_name -> indicators.EMAResponse
+	10, // 16: indicators.AnalyzeAllResponse.volume:type_name -> indicators.VolumeResponse
+	12, // 17: indicators.AnalyzeAllResponse.atr:type_name -> indicators.ATRResponse
+	14, // 18: indicators.AnalyzeAllResponse.adx:type_name -> indicators.ADXResponse
+	16, // 19: indicators.AnalyzeAllResponse.stochastic:type_name -> indicators.StochasticResponse
+	18, // 20: indicators.AnalyzeAllResponse.regime:type_name -> indicators.RegimeResponse
+	19, // 21: indicators.AnalyzeAllResponse.vwap:type_name -> indicators.VWAPResponse
+	20, // 22: indicators.AnalyzeAllResponse.ichimoku:type_name -> indicators.IchimokuResponse
+	21, // 23: indicators.AnalyzeAllResponse.obv:type_name -> indicators.OBVResponse
+	22, // 24: indicators.AnalyzeAllResponse.supertrend:type_name -> indicators.SupertrendResponse
+	23, // 25: indicators.AnalyzeAllResponse.donchian:type_name -> indicators.DonchianResponse
+	25, // 26: indicators.AnalyzeAllResponse.pivots:type_name -> indicators.PivotResponse
+	26, // 27: indicators.AnalyzeAllResponse.emas:type_name -> indicators.EMALevel
+	1,  // 28: indicators.TechnicalIndicators.CalculateRSI:input_type -> indicators.RSIRequest
+	3,  // 29: indicators.TechnicalIndicators.CalculateMACD:input_type -> indicators.MACDRequest
+	5,  // 30: indicators.TechnicalIndicators.CalculateBollingerBands:input_type -> indicators.BollingerRequest
+	7,  // 31: indicators.TechnicalIndicators.CalculateEMA:input_type -> indicators.EMARequest
+	9,  // 32: indicators.TechnicalIndicators.DetectVolumeSpike:input_type -> indicators.VolumeRequest
+	11, // 33: indicators.TechnicalIndicators.CalculateATR:input_type -> indicators.ATRRequest
+	13, // 34: indicators.TechnicalIndicators.CalculateADX:input_type -> indicators.ADXRequest
+	15, // 35: indicators.TechnicalIndicators.CalculateStochastic:input_type -> indicators.StochasticRequest
+	17, // 36: indicators.TechnicalIndicators.ClassifyRegime:input_type -> indicators.RegimeRequest
+	27, // 37: indicators.TechnicalIndicators.AnalyzeAll:input_type -> indicators.AnalyzeAllRequest
+	2,  // 38: indicators.TechnicalIndicators.CalculateRSI:output_type -> indicators.RSIResponse
+	4,  // 39: indicators.TechnicalIndicators.CalculateMACD:output_type -> indicators.MACDResponse
+	6,  // 40: indicators.TechnicalIndicators.CalculateBollingerBands:output_type -> indicators.BollingerResponse
+	8,  // 41: indicators.TechnicalIndicators.CalculateEMA:output_type -> indicators.EMAResponse
+	10, // 42: indicators.TechnicalIndicators.DetectVolumeSpike:output_type -> indicators.VolumeResponse
+	12, // 43: indicators.TechnicalIndicators.CalculateATR:output_type -> indicators.ATRResponse
+	14, // 44: indicators.TechnicalIndicators.CalculateADX:output_type -> indicators.ADXResponse
+	16, // 45: indicators.TechnicalIndicators.CalculateStochastic:output_type -> indicators.StochasticResponse
+	18, // 46: indicators.TechnicalIndicators.ClassifyRegime:output_type -> indicators.RegimeResponse
+	28, // 47: indicators.TechnicalIndicators.AnalyzeAll:output_type -> indicators.AnalyzeAllResponse
+	38, // [38:48] is the sub-list for method output_type
+	28, // [28:38] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_indicators_proto_init() }
@@ -1858,7 +2708,7 @@ func file_indicators_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_indicators_proto_rawDesc), len(file_indicators_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   21,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
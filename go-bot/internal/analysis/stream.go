@@ -0,0 +1,595 @@
+// incremental indicator state. a Stream is fed one closed candle at a time and
+// keeps just enough state to produce the same AnalysisResult as AnalyzeAll
+// over the full history, without re-reading that history:
+//   - recursive indicators (ema, macd, rsi, atr, adx, supertrend, obv, vwap,
+//     pivots) carry their running values
+//   - window indicators (bollinger, volume, stochastic, ichimoku, donchian)
+//     keep a bounded ring of recent candles and reuse the batch functions
+//
+// the result is rebuilt once per candle and cached, so reads are O(1).
+// series fields are left nil. state round-trips through MarshalJSON /
+// UnmarshalJSON for snapshots.
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// smoother is an sma-seeded running average, shared by ema and wilder smoothing
+type smoother struct {
+	Period int     `json:"period"`
+	N      int     `json:"n"`
+	Sum    float64 `json:"sum"`
+	Value  float64 `json:"value"`
+}
+
+func (s *smoother) ready() bool { return s.Period > 0 && s.N >= s.Period }
+
+// pushEMA applies the 2/(period+1) multiplier once seeded
+func (s *smoother) pushEMA(x float64) {
+	s.N++
+	switch {
+	case s.N < s.Period:
+		s.Sum += x
+	case s.N == s.Period:
+		s.Value = (s.Sum + x) / float64(s.Period)
+	default:
+		s.Value = (x-s.Value)*(2/(float64(s.Period)+1)) + s.Value
+	}
+}
+
+// pushWilder applies (prev*(period-1)+x)/period once seeded
+func (s *smoother) pushWilder(x float64) {
+	s.N++
+	switch {
+	case s.N < s.Period:
+		s.Sum += x
+	case s.N == s.Period:
+		s.Value = (s.Sum + x) / float64(s.Period)
+	default:
+		s.Value = (s.Value*float64(s.Period-1) + x) / float64(s.Period)
+	}
+}
+
+// ring keeps the last Size values
+type ring struct {
+	Size   int       `json:"size"`
+	Values []float64 `json:"values"`
+}
+
+func (r *ring) push(x float64) {
+	r.Values = append(r.Values, x)
+	if len(r.Values) > r.Size {
+		r.Values = r.Values[len(r.Values)-r.Size:]
+	}
+}
+
+// last returns the final n values, or all of them when fewer are held
+func (r *ring) last(n int) []float64 {
+	if n >= len(r.Values) {
+		return r.Values
+	}
+	return r.Values[len(r.Values)-n:]
+}
+
+type emaState struct {
+	Period int32    `json:"period"`
+	EMA    smoother `json:"ema"`
+}
+
+// streamState is everything a Stream persists
+type streamState struct {
+	Options   AnalyzeOptions `json:"options"`
+	Count     int            `json:"count"`
+	LastTime  int64          `json:"last_time"`
+	PrevClose float64        `json:"prev_close"`
+	PrevHigh  float64        `json:"prev_high"`
+	PrevLow   float64        `json:"prev_low"`
+
+	// recent candles for the window indicators
+	Highs   ring `json:"highs"`
+	Lows    ring `json:"lows"`
+	Closes  ring `json:"closes"`
+	Volumes ring `json:"volumes"`
+
+	// rsi
+	Gain smoother `json:"gain"`
+	Loss smoother `json:"loss"`
+
+	// ema, multi-period emas and macd
+	EMA          smoother   `json:"ema"`
+	EMAs         []emaState `json:"emas"`
+	MACDFast     smoother   `json:"macd_fast"`
+	MACDSlow     smoother   `json:"macd_slow"`
+	MACDSignal   smoother   `json:"macd_signal"`
+	PrevMACDDiff float64    `json:"prev_macd_diff"`
+
+	// atr and adx share the true range smoothing
+	TR      smoother `json:"tr"`
+	PlusDM  smoother `json:"plus_dm"`
+	MinusDM smoother `json:"minus_dm"`
+	DX      smoother `json:"dx"`
+
+	// supertrend
+	STATR     smoother `json:"st_atr"`
+	STStarted bool     `json:"st_started"`
+	STUp      bool     `json:"st_up"`
+	STPrevUp  bool     `json:"st_prev_up"`
+	STUpper   float64  `json:"st_upper"`
+	STLower   float64  `json:"st_lower"`
+	STValue   float64  `json:"st_value"`
+
+	// obv: running total plus a window for the lookback slope
+	OBV    float64 `json:"obv"`
+	OBVWin ring    `json:"obv_window"`
+
+	// session vwap (current utc day) and anchored vwap
+	SessionDay  int64   `json:"session_day"`
+	SessionPV   float64 `json:"session_pv"`
+	SessionPV2  float64 `json:"session_pv2"`
+	SessionVol  float64 `json:"session_vol"`
+	AnchorTime  int64   `json:"anchor_time"`
+	AnchoredPV  float64 `json:"anchored_pv"`
+	AnchoredVol float64 `json:"anchored_vol"`
+	Anchored    bool    `json:"anchored"`
+
+	// pivots: the current utc day so far and the last completed one
+	DayHigh      float64 `json:"day_high"`
+	DayLow       float64 `json:"day_low"`
+	DayClose     float64 `json:"day_close"`
+	PrevDay      int64   `json:"prev_day"`
+	PrevDayHigh  float64 `json:"prev_day_high"`
+	PrevDayLow   float64 `json:"prev_day_low"`
+	PrevDayClose float64 `json:"prev_day_close"`
+	HasPrevDay   bool    `json:"has_prev_day"`
+}
+
+// Stream holds incremental indicator state for one symbol and interval.
+// It isn't safe for concurrent use; callers serialize Update and Result.
+type Stream struct {
+	st     streamState
+	result *AnalysisResult
+}
+
+// ErrOutOfOrder is returned when a candle isn't newer than the last one seen
+var ErrOutOfOrder = errors.New("candle is not newer than the stream's last candle")
+
+// NewStream creates an empty stream. Zero option fields use the defaults.
+func NewStream(opts *AnalyzeOptions) *Stream {
+	o := withDefaults(opts)
+	o.EMAPeriods = sortedPeriods(o.EMAPeriods)
+
+	rsiPeriod := int(o.RSIPeriod)
+	window := max(int(o.BBPeriod), int(o.VolumeLookback)+1, rsiPeriod+6,
+		int(o.IchimokuSenkou+o.IchimokuKijun), int(o.DonchianPeriod)+1)
+
+	st := streamState{
+		Options:    o,
+		Highs:      ring{Size: window},
+		Lows:       ring{Size: window},
+		Closes:     ring{Size: window},
+		Volumes:    ring{Size: window},
+		Gain:       smoother{Period: rsiPeriod},
+		Loss:       smoother{Period: rsiPeriod},
+		EMA:        smoother{Period: int(o.EMAPeriod)},
+		MACDFast:   smoother{Period: int(o.MACDFast)},
+		MACDSlow:   smoother{Period: int(o.MACDSlow)},
+		MACDSignal: smoother{Period: int(o.MACDSignal)},
+		TR:         smoother{Period: rsiPeriod},
+		PlusDM:     smoother{Period: rsiPeriod},
+		MinusDM:    smoother{Period: rsiPeriod},
+		DX:         smoother{Period: rsiPeriod},
+		STATR:      smoother{Period: int(o.SupertrendPeriod)},
+		STUp:       true,
+		STPrevUp:   true,
+		OBVWin:     ring{Size: int(o.VolumeLookback) + 1},
+	}
+	for _, p := range o.EMAPeriods {
+		st.EMAs = append(st.EMAs, emaState{Period: p, EMA: smoother{Period: int(p)}})
+	}
+	return &Stream{st: st}
+}
+
+// Count is the number of candles the stream has consumed
+func (s *Stream) Count() int { return s.st.Count }
+
+// LastTime is the timestamp of the last consumed candle (0 when empty)
+func (s *Stream) LastTime() int64 { return s.st.LastTime }
+
+// Options returns the parameters the stream was built with
+func (s *Stream) Options() AnalyzeOptions { return s.st.Options }
+
+// Result returns the cached analysis as of the last candle (nil when empty)
+func (s *Stream) Result() *AnalysisResult { return s.result }
+
+// Update consumes one closed candle. Candles must arrive in time order.
+func (s *Stream) Update(c Candle) error {
+	st := &s.st
+	if st.Count > 0 && c.Timestamp <= st.LastTime {
+		return ErrOutOfOrder
+	}
+	o := st.Options
+
+	if st.Count > 0 {
+		// rsi gains and losses
+		change := c.Close - st.PrevClose
+		st.Gain.pushWilder(math.Max(change, 0))
+		st.Loss.pushWilder(math.Max(-change, 0))
+
+		// true range and directional movement
+		tr := math.Max(c.High-c.Low, math.Max(math.Abs(c.High-st.PrevClose), math.Abs(c.Low-st.PrevClose)))
+		st.TR.pushWilder(tr)
+		st.STATR.pushWilder(tr)
+		up, down := c.High-st.PrevHigh, st.PrevLow-c.Low
+		plus, minus := 0.0, 0.0
+		if up > down && up > 0 {
+			plus = up
+		}
+		if down > up && down > 0 {
+			minus = down
+		}
+		st.PlusDM.pushWilder(plus)
+		st.MinusDM.pushWilder(minus)
+		if st.TR.ready() {
+			plusDI, minusDI := directional(st)
+			var dx float64
+			if sum := plusDI + minusDI; sum > 1e-10 {
+				dx = math.Abs(plusDI-minusDI) / sum * 100
+			}
+			st.DX.pushWilder(dx)
+		}
+
+		// obv
+		switch {
+		case c.Close > st.PrevClose:
+			st.OBV += c.Volume
+		case c.Close < st.PrevClose:
+			st.OBV -= c.Volume
+		}
+	}
+	st.OBVWin.push(st.OBV)
+
+	// emas and macd
+	st.EMA.pushEMA(c.Close)
+	for i := range st.EMAs {
+		st.EMAs[i].EMA.pushEMA(c.Close)
+	}
+	st.MACDFast.pushEMA(c.Close)
+	st.MACDSlow.pushEMA(c.Close)
+	var macdDiff float64
+	if st.MACDSlow.ready() {
+		line := st.MACDFast.Value - st.MACDSlow.Value
+		st.MACDSignal.pushEMA(line)
+		macdDiff = line
+		if st.MACDSignal.ready() {
+			macdDiff -= st.MACDSignal.Value
+		}
+	}
+
+	s.updateSupertrend(c)
+	s.updateVWAP(c)
+	s.updateDays(c)
+
+	st.Highs.push(c.High)
+	st.Lows.push(c.Low)
+	st.Closes.push(c.Close)
+	st.Volumes.push(c.Volume)
+
+	s.result = s.build(o, c)
+
+	st.PrevMACDDiff = macdDiff
+	st.PrevClose, st.PrevHigh, st.PrevLow = c.Close, c.High, c.Low
+	st.LastTime = c.Timestamp
+	st.Count++
+	return nil
+}
+
+func directional(st *streamState) (plusDI, minusDI float64) {
+	if st.TR.Value > 1e-10 {
+		plusDI = st.PlusDM.Value / st.TR.Value * 100
+		minusDI = st.MinusDM.Value / st.TR.Value * 100
+	}
+	return plusDI, minusDI
+}
+
+// updateSupertrend mirrors computeSupertrend's loop body for one candle
+func (s *Stream) updateSupertrend(c Candle) {
+	st := &s.st
+	if !st.STATR.ready() {
+		return
+	}
+	hl2 := (c.High + c.Low) / 2
+	band := st.Options.SupertrendMultiplier * st.STATR.Value
+	basicUpper, basicLower := hl2+band, hl2-band
+	if !st.STStarted {
+		st.STUpper, st.STLower = basicUpper, basicLower
+		st.STStarted = true
+	} else {
+		if basicUpper < st.STUpper || st.PrevClose > st.STUpper {
+			st.STUpper = basicUpper
+		}
+		if basicLower > st.STLower || st.PrevClose < st.STLower {
+			st.STLower = basicLower
+		}
+	}
+	st.STPrevUp = st.STUp
+	if st.STUp && c.Close < st.STLower {
+		st.STUp = false
+	} else if !st.STUp && c.Close > st.STUpper {
+		st.STUp = true
+	}
+	st.STValue = st.STLower
+	if !st.STUp {
+		st.STValue = st.STUpper
+	}
+}
+
+func (s *Stream) updateVWAP(c Candle) {
+	st := &s.st
+	tp := (c.High + c.Low + c.Close) / 3
+	if day := utcDay(c.Timestamp); st.Count == 0 || day != st.SessionDay {
+		st.SessionDay = day
+		st.SessionPV, st.SessionPV2, st.SessionVol = 0, 0, 0
+	}
+	st.SessionPV += tp * c.Volume
+	st.SessionPV2 += tp * tp * c.Volume
+	st.SessionVol += c.Volume
+
+	if !st.Anchored && c.Timestamp >= st.Options.VWAPAnchor {
+		st.Anchored = true
+		st.AnchorTime = c.Timestamp
+	}
+	if st.Anchored {
+		st.AnchoredPV += tp * c.Volume
+		st.AnchoredVol += c.Volume
+	}
+}
+
+func (s *Stream) updateDays(c Candle) {
+	st := &s.st
+	day := utcDay(c.Timestamp)
+	if st.Count > 0 && day != utcDay(st.LastTime) {
+		st.PrevDay = utcDay(st.LastTime)
+		st.PrevDayHigh, st.PrevDayLow, st.PrevDayClose = st.DayHigh, st.DayLow, st.DayClose
+		st.HasPrevDay = true
+		st.DayHigh, st.DayLow = c.High, c.Low
+	} else if st.Count == 0 {
+		st.DayHigh, st.DayLow = c.High, c.Low
+	}
+	st.DayHigh = math.Max(st.DayHigh, c.High)
+	st.DayLow = math.Min(st.DayLow, c.Low)
+	st.DayClose = c.Close
+}
+
+// build assembles the result for the candle just consumed. st.Count and the
+// prev* fields still describe the state before it.
+func (s *Stream) build(o AnalyzeOptions, c Candle) *AnalysisResult {
+	st := &s.st
+	n := st.Count + 1
+	rsiPeriod := int(o.RSIPeriod)
+	res := &AnalysisResult{}
+
+	if st.Gain.ready() {
+		value := rsiValue(st.Gain.Value, st.Loss.Value)
+		res.RSI = &RSIResult{Value: value, Signal: classifyRSI(value)}
+	}
+
+	if n >= int(o.MACDSlow+o.MACDSignal) && o.MACDFast > 0 && o.MACDFast < o.MACDSlow {
+		line := st.MACDFast.Value - st.MACDSlow.Value
+		m := &MACDResult{MACDLine: line, SignalLine: st.MACDSignal.Value, Histogram: line - st.MACDSignal.Value}
+		prev, curr := st.PrevMACDDiff, m.MACDLine-m.SignalLine
+		m.Crossover = (prev <= 0 && curr > 0) || (prev >= 0 && curr < 0)
+		m.Signal = classifyMACD(m.MACDLine, m.SignalLine, m.Histogram)
+		res.MACD = m
+	}
+
+	if b := computeBollinger(st.Closes.last(int(o.BBPeriod)), int(o.BBPeriod), o.BBStdDev); b != nil {
+		b.UpperSeries, b.MiddleSeries, b.LowerSeries = nil, nil, nil
+		res.Bollinger = b
+	}
+
+	if st.EMA.ready() {
+		res.EMA = &EMAResult{Value: st.EMA.Value, Trend: emaTrend(c.Close, st.EMA.Value)}
+	}
+	for _, e := range st.EMAs {
+		if e.EMA.ready() {
+			res.EMAs = append(res.EMAs, EMALevel{Period: e.Period, Value: e.EMA.Value, Trend: emaTrend(c.Close, e.EMA.Value)})
+		}
+	}
+	res.EMAAlignment = emaAlignment(res.EMAs)
+
+	res.Volume = detectVolumeSpike(st.Volumes.last(int(o.VolumeLookback)+1), int(o.VolumeLookback), o.VolumeThreshold)
+
+	if st.TR.ready() {
+		var pct float64
+		if math.Abs(c.Close) > 1e-10 {
+			pct = st.TR.Value / c.Close * 100
+		}
+		res.ATR = &ATRResult{Value: st.TR.Value, Percent: pct, Signal: classifyVolatility(pct)}
+	}
+
+	if n >= 2*rsiPeriod+1 && st.DX.ready() {
+		plusDI, minusDI := directional(st)
+		res.ADX = &ADXResult{
+			Value:    st.DX.Value,
+			PlusDI:   plusDI,
+			MinusDI:  minusDI,
+			Signal:   classifyADX(st.DX.Value),
+			TrendDir: trendDirection(plusDI, minusDI),
+		}
+	}
+	res.Regime = regimeFrom(res.ADX, res.ATR)
+
+	highs, lows, closes := st.Highs.Values, st.Lows.Values, st.Closes.Values
+	if k := computeStochastic(highs, lows, closes, rsiPeriod, 3, 3); k != nil {
+		k.KSeries, k.DSeries = nil, nil
+		res.Stochastic = k
+	}
+	res.Ichimoku = computeIchimoku(highs, lows, closes, int(o.IchimokuTenkan), int(o.IchimokuKijun), int(o.IchimokuSenkou))
+	res.Donchian = computeDonchian(highs, lows, closes, int(o.DonchianPeriod))
+
+	res.OBV = s.obvResult()
+	res.VWAP = s.vwapResult(c)
+
+	if st.STStarted && n >= int(o.SupertrendPeriod)+2 {
+		direction := "DOWN"
+		if st.STUp {
+			direction = "UP"
+		}
+		res.Supertrend = &SupertrendResult{Value: st.STValue, Direction: direction, Flipped: st.STUp != st.STPrevUp}
+	}
+
+	res.Pivots = s.pivotResult(c)
+
+	vote(res)
+	return res
+}
+
+// obvResult mirrors computeOBV over the lookback window. the slope only
+// depends on obv differences, so the running total's origin doesn't matter.
+func (s *Stream) obvResult() *OBVResult {
+	st := &s.st
+	lookback := int(st.Options.VolumeLookback)
+	obv := st.OBVWin.Values
+	if lookback <= 0 || len(obv) < lookback+1 {
+		return nil
+	}
+	closes := st.Closes.last(lookback + 1)
+	last := len(obv) - 1
+
+	var slope float64
+	if traded := sum(st.Volumes.last(lookback)); traded > 1e-10 {
+		slope = (obv[last] - obv[last-lookback]) / traded
+	}
+
+	signal := "FLAT"
+	switch {
+	case slope > 0.2:
+		signal = "RISING"
+	case slope < -0.2:
+		signal = "FALLING"
+	}
+
+	priceChange := closes[len(closes)-1] - closes[0]
+	divergence := "NONE"
+	switch {
+	case priceChange > 0 && slope < -0.2:
+		divergence = "BEARISH"
+	case priceChange < 0 && slope > 0.2:
+		divergence = "BULLISH"
+	}
+
+	return &OBVResult{Value: st.OBV, Slope: slope, Signal: signal, Divergence: divergence}
+}
+
+func (s *Stream) vwapResult(c Candle) *VWAPResult {
+	st := &s.st
+	if st.SessionVol <= 0 || !st.Anchored || st.AnchoredVol <= 0 {
+		return nil
+	}
+	session := st.SessionPV / st.SessionVol
+	variance := math.Max(st.SessionPV2/st.SessionVol-session*session, 0)
+	stdDev := math.Sqrt(variance)
+	signal := "BELOW"
+	if c.Close > session {
+		signal = "ABOVE"
+	}
+	return &VWAPResult{
+		Session:         session,
+		SessionUpper:    session + stdDev,
+		SessionLower:    session - stdDev,
+		Anchored:        st.AnchoredPV / st.AnchoredVol,
+		AnchorTimestamp: st.AnchorTime,
+		Signal:          signal,
+	}
+}
+
+func (s *Stream) pivotResult(c Candle) *PivotResult {
+	st := &s.st
+	if !st.HasPrevDay {
+		return nil
+	}
+	high, low := st.PrevDayHigh, st.PrevDayLow
+	pivot := (high + low + st.PrevDayClose) / 3
+	rng := high - low
+	position := "BELOW_PIVOT"
+	if c.Close > pivot {
+		position = "ABOVE_PIVOT"
+	}
+	return &PivotResult{
+		Classic: PivotLevels{
+			Pivot: pivot,
+			R1:    2*pivot - low,
+			R2:    pivot + rng,
+			R3:    high + 2*(pivot-low),
+			S1:    2*pivot - high,
+			S2:    pivot - rng,
+			S3:    low - 2*(high-pivot),
+		},
+		Fibonacci: PivotLevels{
+			Pivot: pivot,
+			R1:    pivot + 0.382*rng,
+			R2:    pivot + 0.618*rng,
+			R3:    pivot + rng,
+			S1:    pivot - 0.382*rng,
+			S2:    pivot - 0.618*rng,
+			S3:    pivot - rng,
+		},
+		SessionTimestamp: st.PrevDay * secondsPerDay,
+		Position:         position,
+	}
+}
+
+// streamSnapshot carries the cached result alongside the state so a
+// restored stream can answer before its next candle
+type streamSnapshot struct {
+	State  streamState     `json:"state"`
+	Result *AnalysisResult `json:"result,omitempty"`
+}
+
+// MarshalJSON snapshots the stream state
+func (s *Stream) MarshalJSON() ([]byte, error) {
+	return json.Marshal(streamSnapshot{State: s.st, Result: s.result})
+}
+
+// UnmarshalJSON restores a snapshot written by MarshalJSON
+func (s *Stream) UnmarshalJSON(data []byte) error {
+	var snap streamSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode stream state: %w", err)
+	}
+	s.st = snap.State
+	s.result = snap.Result
+	return nil
+}
+
+// Compatible reports whether a restored stream was built with the same
+// parameters as opts (after defaults), so its state can be reused
+func (s *Stream) Compatible(opts *AnalyzeOptions) bool {
+	o := withDefaults(opts)
+	o.EMAPeriods = sortedPeriods(o.EMAPeriods)
+	return reflect.DeepEqual(s.st.Options, o)
+}
+
+// sortedPeriods drops non-positive and duplicate periods and sorts the rest
+func sortedPeriods(periods []int32) []int32 {
+	out := make([]int32, 0, len(periods))
+	for _, p := range periods {
+		if p > 0 {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	uniq := out[:0]
+	for i, p := range out {
+		if i == 0 || p != out[i-1] {
+			uniq = append(uniq, p)
+		}
+	}
+	return uniq
+}
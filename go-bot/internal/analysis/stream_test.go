@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// assertSameResult compares two results field by field, skipping the series
+// the stream doesn't keep
+func assertSameResult(t *testing.T, path string, got, want reflect.Value) {
+	t.Helper()
+	switch want.Kind() {
+	case reflect.Ptr:
+		if got.IsNil() != want.IsNil() {
+			t.Errorf("%s: stream nil=%v, batch nil=%v", path, got.IsNil(), want.IsNil())
+			return
+		}
+		if !want.IsNil() {
+			assertSameResult(t, path, got.Elem(), want.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			name := want.Type().Field(i).Name
+			if strings.HasSuffix(name, "Series") {
+				continue
+			}
+			assertSameResult(t, path+"."+name, got.Field(i), want.Field(i))
+		}
+	case reflect.Slice:
+		if got.Len() != want.Len() {
+			t.Errorf("%s: stream len %d, batch len %d", path, got.Len(), want.Len())
+			return
+		}
+		for i := 0; i < want.Len(); i++ {
+			assertSameResult(t, path, got.Index(i), want.Index(i))
+		}
+	case reflect.Float64:
+		g, w := got.Float(), want.Float()
+		if math.Abs(g-w) > 1e-7*math.Max(1, math.Abs(w)) {
+			t.Errorf("%s = %.12g, batch = %.12g", path, g, w)
+		}
+	default:
+		if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+			t.Errorf("%s = %v, batch = %v", path, got.Interface(), want.Interface())
+		}
+	}
+}
+
+func TestStream_MatchesBatch(t *testing.T) {
+	candles := goldenCandles()
+	opts := &AnalyzeOptions{VWAPAnchor: 30 * 4 * 3600}
+	stream := NewStream(opts)
+	local := NewLocal()
+
+	for i, c := range candles {
+		if err := stream.Update(c); err != nil {
+			t.Fatalf("update %d: %v", i, err)
+		}
+		want, err := local.AnalyzeAll(context.Background(), candles[:i+1], opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := t.Failed()
+		assertSameResult(t, "result", reflect.ValueOf(stream.Result()), reflect.ValueOf(want))
+		if !before && t.Failed() {
+			t.Fatalf("stream diverged from batch after %d candles", i+1)
+		}
+	}
+	if stream.Count() != len(candles) || stream.LastTime() != candles[len(candles)-1].Timestamp {
+		t.Errorf("count %d last %d", stream.Count(), stream.LastTime())
+	}
+}
+
+func TestStream_SnapshotRoundTrip(t *testing.T) {
+	candles := goldenCandles()
+	stream := NewStream(nil)
+	for _, c := range candles[:80] {
+		stream.Update(c)
+	}
+
+	data, err := json.Marshal(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &Stream{}
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	assertSameResult(t, "restored", reflect.ValueOf(restored.Result()), reflect.ValueOf(stream.Result()))
+	if !restored.Compatible(nil) || restored.Compatible(&AnalyzeOptions{RSIPeriod: 7}) {
+		t.Error("compatibility check should follow the stream's options")
+	}
+
+	for _, c := range candles[80:] {
+		stream.Update(c)
+		restored.Update(c)
+	}
+	assertSameResult(t, "continued", reflect.ValueOf(restored.Result()), reflect.ValueOf(stream.Result()))
+}
+
+func TestStream_RejectsOutOfOrder(t *testing.T) {
+	candles := goldenCandles()
+	stream := NewStream(nil)
+	stream.Update(candles[1])
+	if err := stream.Update(candles[1]); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("duplicate: got %v", err)
+	}
+	if err := stream.Update(candles[0]); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("older: got %v", err)
+	}
+	if stream.Count() != 1 {
+		t.Errorf("count = %d, want 1", stream.Count())
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/backtest"
 	"github.com/trading-bot/go-bot/internal/database"
+	"github.com/trading-bot/go-bot/internal/usage"
 )
 
 // Server serves the analytics REST API.
@@ -20,6 +22,9 @@ type Server struct {
 	decisions *database.AIDecisionRepository
 	stats     *database.DailyStatsRepository
 	candles   *database.CandleRepository
+	backtests *database.BacktestRunRepository // optional
+	modelRuns *database.ModelRunRepository    // optional
+	usage     *usage.Reporter                 // optional
 	apiKey    string
 }
 
@@ -42,6 +47,21 @@ func NewServer(
 	}
 }
 
+// SetBacktestRuns enables the /api/backtests endpoints.
+func (s *Server) SetBacktestRuns(repo *database.BacktestRunRepository) {
+	s.backtests = repo
+}
+
+// SetModelRuns enables the /api/drift endpoints.
+func (s *Server) SetModelRuns(repo *database.ModelRunRepository) {
+	s.modelRuns = repo
+}
+
+// SetUsage enables the /api/usage endpoint.
+func (s *Server) SetUsage(reporter *usage.Reporter) {
+	s.usage = reporter
+}
+
 // RegisterRoutes adds all API routes to the given mux.
 func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/positions", s.auth(s.handlePositions))
@@ -51,6 +71,11 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/stats/daily", s.auth(s.handleDailyStats))
 	mux.HandleFunc("/api/stats/summary", s.auth(s.handleSummary))
 	mux.HandleFunc("/api/candles", s.auth(s.handleCandles))
+	mux.HandleFunc("/api/backtests", s.auth(s.handleBacktests))
+	mux.HandleFunc("/api/backtests/", s.auth(s.handleBacktestByID))
+	mux.HandleFunc("/api/drift", s.auth(s.handleDrift))
+	mux.HandleFunc("/api/drift/history", s.auth(s.handleDriftHistory))
+	mux.HandleFunc("/api/usage", s.auth(s.handleUsage))
 }
 
 // auth wraps a handler with API key authentication.
@@ -335,6 +360,219 @@ func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GET /api/backtests?symbol=BTC/USDT&limit=20
+func (s *Server) handleBacktests(w http.ResponseWriter, r *http.Request) {
+	if s.backtests == nil {
+		writeError(w, http.StatusServiceUnavailable, "backtest storage not configured")
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	limit := intParam(r, "limit", 20)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	runs, err := s.backtests.List(ctx, symbol, limit)
+	if err != nil {
+		slog.Error("api: list backtests", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list backtests")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"backtests": backtestRunsToAPI(runs),
+		"count":     len(runs),
+	})
+}
+
+// GET /api/backtests/123 (add ?format=html for the rendered report)
+func (s *Server) handleBacktestByID(w http.ResponseWriter, r *http.Request) {
+	if s.backtests == nil {
+		writeError(w, http.StatusServiceUnavailable, "backtest storage not configured")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/backtests/")
+	id, err := strconv.Atoi(path)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid backtest ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "html" {
+		writeError(w, http.StatusBadRequest, "format must be json or html")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	run, err := s.backtests.GetByID(ctx, id)
+	if err != nil {
+		slog.Error("api: get backtest", "error", err, "id", id)
+		writeError(w, http.StatusInternalServerError, "failed to get backtest")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "backtest not found")
+		return
+	}
+
+	if format == "html" {
+		var report backtest.Report
+		if err := json.Unmarshal(run.Report, &report); err != nil {
+			slog.Error("api: decode backtest report", "error", err, "id", id)
+			writeError(w, http.StatusInternalServerError, "failed to decode backtest report")
+			return
+		}
+		report.ID = run.ID
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := report.WriteHTML(w); err != nil {
+			slog.Error("api: render backtest report", "error", err, "id", id)
+		}
+		return
+	}
+
+	m := backtestRunToAPI(run)
+	m["report"] = run.Report
+	writeJSON(w, http.StatusOK, m)
+}
+
+// GET /api/drift?symbol=BTC/USDT — latest drift report per symbol and timeframe
+func (s *Server) handleDrift(w http.ResponseWriter, r *http.Request) {
+	if s.modelRuns == nil {
+		writeError(w, http.StatusServiceUnavailable, "drift monitoring not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	reports, err := s.modelRuns.LatestDriftReports(ctx, r.URL.Query().Get("symbol"))
+	if err != nil {
+		slog.Error("api: latest drift reports", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list drift reports")
+		return
+	}
+
+	drifted := 0
+	for _, rep := range reports {
+		if rep.DriftDetected {
+			drifted++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"reports": driftReportsToAPI(reports),
+		"count":   len(reports),
+		"drifted": drifted,
+	})
+}
+
+// GET /api/drift/history?symbol=BTC/USDT&timeframe=4h&limit=50
+func (s *Server) handleDriftHistory(w http.ResponseWriter, r *http.Request) {
+	if s.modelRuns == nil {
+		writeError(w, http.StatusServiceUnavailable, "drift monitoring not configured")
+		return
+	}
+
+	q := r.URL.Query()
+	limit := intParam(r, "limit", 50)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	reports, err := s.modelRuns.DriftHistory(ctx, q.Get("symbol"), q.Get("timeframe"), limit)
+	if err != nil {
+		slog.Error("api: drift history", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list drift history")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"reports": driftReportsToAPI(reports),
+		"count":   len(reports),
+	})
+}
+
+// GET /api/usage?user_id=1&days=7 — ai tokens, cost and budget; without
+// user_id, totals across all users
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if s.usage == nil {
+		writeError(w, http.StatusServiceUnavailable, "usage tracking not configured")
+		return
+	}
+
+	userID := 0
+	if r.URL.Query().Get("user_id") != "" {
+		id, err := requiredIntParam(r, "user_id")
+		if err != nil || id <= 0 {
+			writeError(w, http.StatusBadRequest, "user_id must be a positive integer")
+			return
+		}
+		userID = id
+	}
+	days := intParam(r, "days", 7)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	rep, err := s.usage.Report(ctx, userID)
+	if err != nil {
+		slog.Error("api: usage report", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load usage")
+		return
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+	daily, err := s.decisions.UsageByDay(ctx, userID, since)
+	if err != nil {
+		slog.Error("api: usage by day", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load usage")
+		return
+	}
+
+	bySymbol := make([]map[string]any, len(rep.BySymbol))
+	for i, st := range rep.BySymbol {
+		row := usageTotalsToAPI(st.Totals)
+		row["symbol"] = st.Symbol
+		bySymbol[i] = row
+	}
+	dailyRows := make([]map[string]any, len(daily))
+	for i, d := range daily {
+		dailyRows[i] = map[string]any{
+			"date":          d.Day.Format("2006-01-02"),
+			"calls":         d.Calls,
+			"input_tokens":  d.InputTokens,
+			"output_tokens": d.OutputTokens,
+			"cost_usd":      d.CostUSD,
+		}
+	}
+
+	resp := map[string]any{
+		"today":     usageTotalsToAPI(rep.Today),
+		"week":      usageTotalsToAPI(rep.Week),
+		"by_symbol": bySymbol,
+		"daily":     dailyRows,
+	}
+	if userID != 0 {
+		resp["user_id"] = userID
+	}
+	if b := rep.Budget; b != nil {
+		resp["budget"] = map[string]any{
+			"user_spent_usd":   b.UserSpent,
+			"user_limit_usd":   b.UserLimit,
+			"global_spent_usd": b.GlobalSpent,
+			"global_limit_usd": b.GlobalLimit,
+			"exhausted":        b.Exhausted,
+			"reason":           b.Reason,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // --- response helpers ---
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -524,9 +762,22 @@ func decisionsToAPI(decisions []*database.AIDecisionRecord) []map[string]any {
 		if d.CompletionTokens > 0 {
 			m["completion_tokens"] = d.CompletionTokens
 		}
+		if d.CostUSD > 0 {
+			m["cost_usd"] = d.CostUSD
+		}
 		if d.LatencyMs > 0 {
 			m["latency_ms"] = d.LatencyMs
 		}
+		if d.Provider != "" {
+			m["ai_provider"] = d.Provider
+			m["ai_model"] = d.Model
+		}
+		if d.PromptVersion != "" {
+			m["prompt_version"] = d.PromptVersion
+		}
+		if d.PromptExperiment != "" {
+			m["prompt_experiment"] = d.PromptExperiment
+		}
 		if d.WasApproved != nil {
 			m["was_approved"] = *d.WasApproved
 		}
@@ -544,6 +795,33 @@ func decisionsToAPI(decisions []*database.AIDecisionRecord) []map[string]any {
 	return result
 }
 
+func backtestRunsToAPI(runs []*database.BacktestRunRecord) []map[string]any {
+	result := make([]map[string]any, len(runs))
+	for i, run := range runs {
+		result[i] = backtestRunToAPI(run)
+	}
+	return result
+}
+
+func backtestRunToAPI(run *database.BacktestRunRecord) map[string]any {
+	return map[string]any{
+		"id":               run.ID,
+		"symbol":           run.Symbol,
+		"interval":         run.Interval,
+		"strategy":         run.Strategy,
+		"start_time":       run.StartTime.Format(time.RFC3339),
+		"end_time":         run.EndTime.Format(time.RFC3339),
+		"initial_capital":  run.InitialCapital,
+		"final_equity":     run.FinalEquity,
+		"total_return_pct": run.TotalReturnPct,
+		"sharpe_ratio":     run.SharpeRatio,
+		"max_drawdown_pct": run.MaxDrawdownPct,
+		"win_rate":         run.WinRate,
+		"total_trades":     run.TotalTrades,
+		"created_at":       run.CreatedAt.Format(time.RFC3339),
+	}
+}
+
 func dailyStatsToAPI(stats []*database.DailyStatsRecord) []map[string]any {
 	result := make([]map[string]any, len(stats))
 	for i, s := range stats {
@@ -559,7 +837,61 @@ func dailyStatsToAPI(stats []*database.DailyStatsRecord) []map[string]any {
 			"ai_decisions_made":   s.AIDecisionsMade,
 			"ai_decisions_approved": s.AIDecisionsApproved,
 			"notifications_sent":  s.NotificationsSent,
+			"ai_calls":            s.AICalls,
+			"ai_input_tokens":     s.AIInputTokens,
+			"ai_output_tokens":    s.AIOutputTokens,
+			"ai_cost_usd":         s.AICostUSD,
 		}
 	}
 	return result
 }
+
+func usageTotalsToAPI(t usage.Totals) map[string]any {
+	return map[string]any{
+		"calls":         t.Calls,
+		"input_tokens":  t.InputTokens,
+		"output_tokens": t.OutputTokens,
+		"cost_usd":      t.CostUSD,
+	}
+}
+
+func driftReportsToAPI(reports []*database.ModelRunRecord) []map[string]any {
+	result := make([]map[string]any, len(reports))
+	for i, rep := range reports {
+		result[i] = driftReportToAPI(rep)
+	}
+	return result
+}
+
+func driftReportToAPI(rep *database.ModelRunRecord) map[string]any {
+	m := map[string]any{
+		"id":             rep.DriftCheckID,
+		"symbol":         rep.Symbol,
+		"timeframe":      rep.Timeframe,
+		"drift_detected": rep.DriftDetected,
+		"reason":         rep.DriftReason,
+		"recommendation": rep.DriftRecommendation,
+		"checks":         rep.DriftChecks,
+		"checked_at":     rep.CreatedAt.Format(time.RFC3339),
+	}
+	if rep.Outcome != "" {
+		retrain := map[string]any{
+			"id":                 rep.ID,
+			"outcome":            rep.Outcome,
+			"candidate_accuracy": rep.CandidateAccuracy,
+			"deployed_accuracy":  rep.DeployedAccuracy,
+		}
+		if rep.WalkForwardFolds > 0 {
+			retrain["walk_forward_accuracy"] = rep.WalkForwardAccuracy
+			retrain["walk_forward_folds"] = rep.WalkForwardFolds
+		}
+		if rep.Reason != "" {
+			retrain["reason"] = rep.Reason
+		}
+		if rep.PromotedBy != "" {
+			retrain["promoted_by"] = rep.PromotedBy
+		}
+		m["retrain"] = retrain
+	}
+	return m
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/database"
+	"github.com/trading-bot/go-bot/internal/usage"
 )
 
 // newTestServer creates a Server with nil repos (sufficient for auth/validation tests).
@@ -455,7 +456,7 @@ func TestDecisionsToAPI(t *testing.T) {
 			Confidence: 85, Timeframe: "4h", EntryPrice: 50000,
 			StopLoss: 48000, TakeProfit: 55000, PositionSizeUSD: 5000,
 			RiskRewardRatio: 2.5, Reasoning: "strong bullish",
-			PromptTokens: 1000, CompletionTokens: 500, LatencyMs: 1200,
+			PromptTokens: 1000, CompletionTokens: 500, CostUSD: 0.0105, LatencyMs: 1200,
 			WasApproved: &approved, WasExecuted: true,
 			IndicatorsData: map[string]interface{}{"rsi": 65},
 			CreatedAt: time.Now(),
@@ -484,6 +485,9 @@ func TestDecisionsToAPI(t *testing.T) {
 	if result[0]["prompt_tokens"] != 1000 {
 		t.Errorf("prompt_tokens = %v", result[0]["prompt_tokens"])
 	}
+	if result[0]["cost_usd"] != 0.0105 {
+		t.Errorf("cost_usd = %v", result[0]["cost_usd"])
+	}
 
 	// second: sparse — should omit zero fields
 	for _, field := range []string{"timeframe", "entry_price", "stop_loss", "take_profit",
@@ -529,3 +533,123 @@ func TestDailyStatsToAPI(t *testing.T) {
 		t.Errorf("ai_decisions_made = %v", result[0]["ai_decisions_made"])
 	}
 }
+
+// ==================== backtests ====================
+
+func TestBacktests_NotConfigured(t *testing.T) {
+	for _, url := range []string{"/api/backtests", "/api/backtests/1"} {
+		rr := serve(newTestServer(""), http.MethodGet, url, nil)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: status = %d, want 503", url, rr.Code)
+		}
+	}
+}
+
+func TestBacktestByID_InvalidID(t *testing.T) {
+	srv := newTestServer("")
+	srv.SetBacktestRuns(database.NewBacktestRunRepository(nil))
+	for _, url := range []string{"/api/backtests/abc", "/api/backtests/0", "/api/backtests/-3"} {
+		rr := serve(srv, http.MethodGet, url, nil)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", url, rr.Code)
+		}
+	}
+}
+
+func TestBacktestByID_InvalidFormat(t *testing.T) {
+	srv := newTestServer("")
+	srv.SetBacktestRuns(database.NewBacktestRunRepository(nil))
+	rr := serve(srv, http.MethodGet, "/api/backtests/1?format=pdf", nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rr.Code)
+	}
+}
+
+func TestBacktestRunsToAPI(t *testing.T) {
+	runs := []*database.BacktestRunRecord{
+		{
+			ID: 7, Symbol: "BTC/USDT", Interval: "4h", Strategy: "sma_crossover",
+			StartTime:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			InitialCapital: 10000, FinalEquity: 11500, TotalReturnPct: 15,
+			SharpeRatio: 1.2, MaxDrawdownPct: 8.5, WinRate: 55, TotalTrades: 40,
+			CreatedAt: time.Now(),
+		},
+	}
+	result := backtestRunsToAPI(runs)
+	if len(result) != 1 {
+		t.Fatalf("len = %d, want 1", len(result))
+	}
+	if result[0]["strategy"] != "sma_crossover" {
+		t.Errorf("strategy = %v", result[0]["strategy"])
+	}
+	if result[0]["start_time"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("start_time = %v", result[0]["start_time"])
+	}
+	if _, ok := result[0]["report"]; ok {
+		t.Error("list entries should not include the full report")
+	}
+}
+
+// ==================== drift ====================
+
+func TestUsage_NotConfigured(t *testing.T) {
+	rr := serve(newTestServer(""), http.MethodGet, "/api/usage?user_id=1", nil)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rr.Code)
+	}
+}
+
+func TestUsage_InvalidUserID(t *testing.T) {
+	srv := newTestServer("")
+	srv.SetUsage(usage.NewReporter(nil, nil))
+	for _, url := range []string{"/api/usage?user_id=abc", "/api/usage?user_id=-1"} {
+		rr := serve(srv, http.MethodGet, url, nil)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", url, rr.Code)
+		}
+	}
+}
+
+func TestDrift_NotConfigured(t *testing.T) {
+	for _, url := range []string{"/api/drift", "/api/drift/history"} {
+		rr := serve(newTestServer(""), http.MethodGet, url, nil)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: status = %d, want 503", url, rr.Code)
+		}
+	}
+}
+
+func TestDriftReportsToAPI(t *testing.T) {
+	reports := []*database.ModelRunRecord{
+		{
+			DriftCheckID: 3, Symbol: "ETH/USDT", Timeframe: "1d",
+			DriftReason: "no drift detected", DriftRecommendation: "no_action",
+			CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 9, DriftCheckID: 4, Symbol: "BTC/USDT", Timeframe: "4h", DriftDetected: true,
+			DriftReason: "feature drift", DriftRecommendation: "retrain",
+			CandidateAccuracy: 0.57, DeployedAccuracy: 0.55, WalkForwardAccuracy: 0.49, WalkForwardFolds: 5,
+			Outcome: "rejected", Reason: "walk-forward accuracy 49.0% is below the 52.0% minimum",
+			CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	result := driftReportsToAPI(reports)
+	if len(result) != 2 {
+		t.Fatalf("len = %d, want 2", len(result))
+	}
+	if _, ok := result[0]["retrain"]; ok {
+		t.Error("a check without a retrain shouldn't have a retrain entry")
+	}
+	if result[0]["checked_at"] != "2024-03-01T12:00:00Z" {
+		t.Errorf("checked_at = %v", result[0]["checked_at"])
+	}
+	retrain, ok := result[1]["retrain"].(map[string]any)
+	if !ok {
+		t.Fatal("expected a retrain entry on the drifted scope")
+	}
+	if retrain["outcome"] != "rejected" || retrain["walk_forward_folds"] != 5 {
+		t.Errorf("unexpected retrain entry %v", retrain)
+	}
+}
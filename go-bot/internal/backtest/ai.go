@@ -0,0 +1,252 @@
+// ai pipeline strategy — replays the real claude decision flow over historical bars.
+// inputs are rebuilt point-in-time, responses are cached by prompt hash so reruns are
+// free and deterministic, and the uncached call count/cost is checked before the run.
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// ErrAIBudgetExceeded is returned by Prepare when the run would exceed the call or cost budget.
+var ErrAIBudgetExceeded = errors.New("ai backtest budget exceeded")
+
+// AIInputBuilder rebuilds the claude input as of the last candle (satisfied by *pipeline.Pipeline).
+type AIInputBuilder interface {
+	BuildInput(ctx context.Context, symbol string, candles []exchange.Candle) (*claude.AnalysisInput, error)
+}
+
+// AIProvider returns a trading decision for an input (satisfied by *claude.Client).
+type AIProvider interface {
+	Analyze(ctx context.Context, input *claude.AnalysisInput) (*claude.Decision, error)
+}
+
+// AIStrategyConfig controls the ai pipeline strategy.
+type AIStrategyConfig struct {
+	Symbol        string
+	Model         string  // part of the cache key
+	Lookback      int     // candles fed to the input builder per bar (default 100)
+	Warmup        int     // bars skipped before the first decision (default 50)
+	EveryN        int     // evaluate every N bars (default 1)
+	MinConfidence float64 // decisions below this are ignored (default 60)
+	PositionPct   float64 // fraction of capital per trade (default 0.2)
+
+	// budget, enforced by Prepare against uncached calls only
+	MaxCalls   int     // 0 = unlimited
+	MaxCostUSD float64 // 0 = unlimited
+
+	// pricing for the estimate, USD per million tokens
+	InputPricePerMTok  float64
+	OutputPricePerMTok float64
+	OutputTokens       int // assumed completion size per call (default 300)
+}
+
+// AICostEstimate is the pre-run plan for an ai backtest.
+type AICostEstimate struct {
+	Evaluations      int     `json:"evaluations"`  // bars that will be evaluated
+	CachedCalls      int     `json:"cached_calls"` // served from cache
+	UncachedCalls    int     `json:"uncached_calls"`
+	EstInputTokens   int     `json:"est_input_tokens"`
+	EstOutputTokens  int     `json:"est_output_tokens"`
+	EstCostUSD       float64 `json:"est_cost_usd"`
+	BuildErrors      int     `json:"build_errors"` // bars whose input couldn't be built
+	MaxCalls         int     `json:"max_calls,omitempty"`
+	MaxCostUSD       float64 `json:"max_cost_usd,omitempty"`
+	WithinBudget     bool    `json:"within_budget"`
+	BudgetViolations string  `json:"budget_violations,omitempty"`
+}
+
+// AIStats counts what happened during the run.
+type AIStats struct {
+	Calls     int `json:"calls"`      // live provider calls
+	CacheHits int `json:"cache_hits"` // decisions served from cache
+	Errors    int `json:"errors"`     // provider failures (treated as HOLD)
+	Signals   int `json:"signals"`    // BUY/SELL decisions above the confidence threshold
+}
+
+type plannedCall struct {
+	input *claude.AnalysisInput
+	hash  string
+}
+
+// AIStrategy adapts the claude decision pipeline to the backtest Strategy interface.
+type AIStrategy struct {
+	ctx     context.Context
+	cfg     AIStrategyConfig
+	builder AIInputBuilder
+	ai      AIProvider
+	cache   DecisionCache
+
+	plan     map[int64]plannedCall // keyed by bar open time (unix ms)
+	prepared bool
+	stats    AIStats
+}
+
+// NewAIStrategy creates the ai pipeline strategy. A nil cache uses an in-memory one.
+func NewAIStrategy(ctx context.Context, cfg AIStrategyConfig, builder AIInputBuilder, ai AIProvider, cache DecisionCache) *AIStrategy {
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = 100
+	}
+	if cfg.Warmup <= 0 {
+		cfg.Warmup = 50
+	}
+	if cfg.EveryN <= 0 {
+		cfg.EveryN = 1
+	}
+	if cfg.MinConfidence <= 0 {
+		cfg.MinConfidence = 60
+	}
+	if cfg.PositionPct <= 0 || cfg.PositionPct > 1 {
+		cfg.PositionPct = 0.2
+	}
+	if cfg.OutputTokens <= 0 {
+		cfg.OutputTokens = 300
+	}
+	if cache == nil {
+		cache = NewMemoryDecisionCache()
+	}
+	return &AIStrategy{ctx: ctx, cfg: cfg, builder: builder, ai: ai, cache: cache}
+}
+
+func (s *AIStrategy) Name() string {
+	return "ai-pipeline"
+}
+
+// Stats returns call and cache counters for the run so far.
+func (s *AIStrategy) Stats() AIStats {
+	return s.stats
+}
+
+// Prepare rebuilds the input for every bar that will be evaluated, checks the cache,
+// and estimates the cost of the remaining calls. It must be called before the run;
+// it returns ErrAIBudgetExceeded (with the estimate) if a budget would be exceeded.
+func (s *AIStrategy) Prepare(candles []exchange.Candle) (*AICostEstimate, error) {
+	est := &AICostEstimate{MaxCalls: s.cfg.MaxCalls, MaxCostUSD: s.cfg.MaxCostUSD}
+	s.plan = make(map[int64]plannedCall)
+
+	for idx := range candles {
+		if !s.evaluates(idx) {
+			continue
+		}
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		start := idx + 1 - s.cfg.Lookback
+		if start < 0 {
+			start = 0
+		}
+		input, err := s.builder.BuildInput(s.ctx, s.cfg.Symbol, candles[start:idx+1])
+		if err != nil {
+			est.BuildErrors++
+			continue
+		}
+
+		hash := claude.PromptHash(s.cfg.Model, input)
+		s.plan[candles[idx].OpenTime.UnixMilli()] = plannedCall{input: input, hash: hash}
+		est.Evaluations++
+
+		if _, ok := s.cache.Get(hash); ok {
+			est.CachedCalls++
+			continue
+		}
+		est.UncachedCalls++
+
+		system, user := claude.RenderPrompt(input)
+		est.EstInputTokens += estimateTokens(system) + estimateTokens(user)
+		est.EstOutputTokens += s.cfg.OutputTokens
+	}
+
+	est.EstCostUSD = float64(est.EstInputTokens)/1e6*s.cfg.InputPricePerMTok +
+		float64(est.EstOutputTokens)/1e6*s.cfg.OutputPricePerMTok
+
+	var violations []string
+	if s.cfg.MaxCalls > 0 && est.UncachedCalls > s.cfg.MaxCalls {
+		violations = append(violations, fmt.Sprintf("%d uncached calls > max %d", est.UncachedCalls, s.cfg.MaxCalls))
+	}
+	if s.cfg.MaxCostUSD > 0 && est.EstCostUSD > s.cfg.MaxCostUSD {
+		violations = append(violations, fmt.Sprintf("estimated $%.2f > max $%.2f", est.EstCostUSD, s.cfg.MaxCostUSD))
+	}
+	est.WithinBudget = len(violations) == 0
+	est.BudgetViolations = strings.Join(violations, "; ")
+	if !est.WithinBudget {
+		return est, fmt.Errorf("%w: %s", ErrAIBudgetExceeded, est.BudgetViolations)
+	}
+
+	s.prepared = true
+	return est, nil
+}
+
+// OnCandle returns the cached or freshly requested decision for a planned bar.
+func (s *AIStrategy) OnCandle(candles []exchange.Candle, idx int) *Signal {
+	if !s.prepared {
+		return nil
+	}
+	call, ok := s.plan[candles[idx].OpenTime.UnixMilli()]
+	if !ok {
+		return nil
+	}
+
+	decision, ok := s.cache.Get(call.hash)
+	if ok {
+		s.stats.CacheHits++
+	} else {
+		// the budget was checked in Prepare; this guards against cache misses
+		// that appeared since (e.g. a cache file edited mid-run)
+		if s.cfg.MaxCalls > 0 && s.stats.Calls >= s.cfg.MaxCalls {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, 2*time.Minute)
+		d, err := s.ai.Analyze(ctx, call.input)
+		cancel()
+		s.stats.Calls++
+		if err != nil {
+			s.stats.Errors++
+			return nil
+		}
+		decision = d
+		s.cache.Put(call.hash, decision)
+	}
+
+	return s.toSignal(decision)
+}
+
+func (s *AIStrategy) evaluates(idx int) bool {
+	return idx >= s.cfg.Warmup && (idx-s.cfg.Warmup)%s.cfg.EveryN == 0
+}
+
+func (s *AIStrategy) toSignal(d *claude.Decision) *Signal {
+	if d == nil || d.Confidence < s.cfg.MinConfidence {
+		return nil
+	}
+
+	var action Action
+	switch d.Action {
+	case claude.ActionBuy:
+		action = ActionBuy
+	case claude.ActionSell:
+		action = ActionSell
+	default:
+		return nil
+	}
+
+	s.stats.Signals++
+	return &Signal{
+		Action:     action,
+		StopLoss:   d.Plan.StopLoss,
+		TakeProfit: d.Plan.TakeProfit,
+		Size:       s.cfg.PositionPct,
+		Reason:     fmt.Sprintf("ai %.0f%%: %s", d.Confidence, d.Reasoning),
+	}
+}
+
+// estimateTokens approximates the token count of a prompt (~4 characters per token).
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
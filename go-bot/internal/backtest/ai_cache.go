@@ -0,0 +1,90 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+// DecisionCache stores AI decisions keyed by prompt hash.
+type DecisionCache interface {
+	Get(hash string) (*claude.Decision, bool)
+	Put(hash string, d *claude.Decision)
+}
+
+// MemoryDecisionCache is an in-process decision cache.
+type MemoryDecisionCache struct {
+	mu        sync.RWMutex
+	decisions map[string]*claude.Decision
+}
+
+func NewMemoryDecisionCache() *MemoryDecisionCache {
+	return &MemoryDecisionCache{decisions: make(map[string]*claude.Decision)}
+}
+
+func (c *MemoryDecisionCache) Get(hash string) (*claude.Decision, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	d, ok := c.decisions[hash]
+	return d, ok
+}
+
+func (c *MemoryDecisionCache) Put(hash string, d *claude.Decision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decisions[hash] = d
+}
+
+// Len returns the number of cached decisions.
+func (c *MemoryDecisionCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.decisions)
+}
+
+// FileDecisionCache is a memory cache persisted to a JSON file, so reruns of the
+// same backtest replay identical decisions without calling the provider.
+type FileDecisionCache struct {
+	*MemoryDecisionCache
+	path string
+}
+
+// LoadFileDecisionCache opens the cache at path; a missing file starts empty.
+func LoadFileDecisionCache(path string) (*FileDecisionCache, error) {
+	c := &FileDecisionCache{MemoryDecisionCache: NewMemoryDecisionCache(), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decision cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.decisions); err != nil {
+		return nil, fmt.Errorf("failed to parse decision cache %s: %w", path, err)
+	}
+	if c.decisions == nil {
+		c.decisions = make(map[string]*claude.Decision)
+	}
+	return c, nil
+}
+
+// Save writes the cache back to disk.
+func (c *FileDecisionCache) Save() error {
+	c.mu.RLock()
+	data, err := json.MarshalIndent(c.decisions, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode decision cache: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write decision cache: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
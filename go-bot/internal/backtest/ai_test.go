@@ -0,0 +1,220 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// priceInputBuilder builds a minimal input from the last close so each bar hashes differently.
+type priceInputBuilder struct{}
+
+func (priceInputBuilder) BuildInput(_ context.Context, symbol string, candles []exchange.Candle) (*claude.AnalysisInput, error) {
+	last := candles[len(candles)-1]
+	return &claude.AnalysisInput{Market: claude.MarketData{Symbol: symbol, Price: last.Close}}, nil
+}
+
+// countingAI buys with a fixed confidence and counts calls.
+type countingAI struct {
+	calls      int
+	confidence float64
+	err        error
+}
+
+func (a *countingAI) Analyze(_ context.Context, input *claude.AnalysisInput) (*claude.Decision, error) {
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	price := input.Market.Price
+	return &claude.Decision{
+		Action:     claude.ActionBuy,
+		Confidence: a.confidence,
+		Plan:       claude.TradePlan{Entry: price, StopLoss: price * 0.9, TakeProfit: price * 1.2},
+	}, nil
+}
+
+func aiCandles(n int) []exchange.Candle {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]exchange.Candle, n)
+	for i := range candles {
+		price := 100 + float64(i)
+		candles[i] = exchange.Candle{
+			OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Open:     price,
+			High:     price + 1,
+			Low:      price - 1,
+			Close:    price,
+			Volume:   1000,
+		}
+	}
+	return candles
+}
+
+func runAIBacktest(t *testing.T, strategy *AIStrategy, candles []exchange.Candle) *Result {
+	t.Helper()
+	engine := NewEngine(Config{Symbol: "BTC/USDT", Interval: "1h", InitialCapital: 10000, MaxOpenTrades: 1}, NewSliceLoader(candles), strategy)
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestAIStrategy_PrepareEstimate(t *testing.T) {
+	candles := aiCandles(40)
+	s := NewAIStrategy(context.Background(), AIStrategyConfig{
+		Symbol: "BTC/USDT", Model: "m", Warmup: 10, EveryN: 5,
+		InputPricePerMTok: 3, OutputPricePerMTok: 15,
+	}, priceInputBuilder{}, &countingAI{confidence: 80}, nil)
+
+	est, err := s.Prepare(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bars 10, 15, 20, 25, 30, 35
+	if est.Evaluations != 6 || est.UncachedCalls != 6 || est.CachedCalls != 0 {
+		t.Errorf("estimate = %+v, want 6 uncached evaluations", est)
+	}
+	if est.EstInputTokens <= 0 || est.EstOutputTokens != 6*300 {
+		t.Errorf("tokens in=%d out=%d", est.EstInputTokens, est.EstOutputTokens)
+	}
+	if est.EstCostUSD <= 0 || !est.WithinBudget {
+		t.Errorf("cost = %f within=%v", est.EstCostUSD, est.WithinBudget)
+	}
+}
+
+func TestAIStrategy_BudgetEnforcedBeforeRun(t *testing.T) {
+	ai := &countingAI{confidence: 80}
+	s := NewAIStrategy(context.Background(), AIStrategyConfig{Warmup: 10, MaxCalls: 5}, priceInputBuilder{}, ai, nil)
+
+	est, err := s.Prepare(aiCandles(40))
+	if !errors.Is(err, ErrAIBudgetExceeded) {
+		t.Fatalf("err = %v, want ErrAIBudgetExceeded", err)
+	}
+	if est == nil || est.WithinBudget || est.UncachedCalls != 30 {
+		t.Errorf("estimate = %+v", est)
+	}
+	if ai.calls != 0 {
+		t.Errorf("provider called %d times before budget check", ai.calls)
+	}
+
+	// an unprepared strategy never calls the provider
+	runAIBacktest(t, s, aiCandles(40))
+	if ai.calls != 0 {
+		t.Errorf("provider called %d times after failed prepare", ai.calls)
+	}
+}
+
+func TestAIStrategy_CostBudget(t *testing.T) {
+	s := NewAIStrategy(context.Background(), AIStrategyConfig{
+		Warmup: 10, MaxCostUSD: 0.0001, InputPricePerMTok: 3, OutputPricePerMTok: 15,
+	}, priceInputBuilder{}, &countingAI{confidence: 80}, nil)
+
+	if _, err := s.Prepare(aiCandles(40)); !errors.Is(err, ErrAIBudgetExceeded) {
+		t.Errorf("err = %v, want cost budget exceeded", err)
+	}
+
+	// both budgets blown are both reported
+	s = NewAIStrategy(context.Background(), AIStrategyConfig{
+		Warmup: 10, MaxCalls: 5, MaxCostUSD: 0.0001, InputPricePerMTok: 3, OutputPricePerMTok: 15,
+	}, priceInputBuilder{}, &countingAI{confidence: 80}, nil)
+	est, _ := s.Prepare(aiCandles(40))
+	if !strings.Contains(est.BudgetViolations, "uncached calls") || !strings.Contains(est.BudgetViolations, "estimated $") {
+		t.Errorf("violations = %q, want both the call and cost budget", est.BudgetViolations)
+	}
+}
+
+func TestAIStrategy_CachedRerunIsFreeAndDeterministic(t *testing.T) {
+	candles := aiCandles(60)
+	cache := NewMemoryDecisionCache()
+	cfg := AIStrategyConfig{Symbol: "BTC/USDT", Model: "m", Warmup: 20, EveryN: 10}
+
+	ai := &countingAI{confidence: 80}
+	first := NewAIStrategy(context.Background(), cfg, priceInputBuilder{}, ai, cache)
+	if _, err := first.Prepare(candles); err != nil {
+		t.Fatal(err)
+	}
+	r1 := runAIBacktest(t, first, candles)
+	if ai.calls == 0 || cache.Len() != ai.calls {
+		t.Fatalf("calls = %d, cached = %d", ai.calls, cache.Len())
+	}
+
+	// rerun with a provider that would fail: everything must come from cache
+	failing := &countingAI{err: errors.New("offline")}
+	second := NewAIStrategy(context.Background(), cfg, priceInputBuilder{}, failing, cache)
+	est, err := second.Prepare(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.UncachedCalls != 0 || est.EstCostUSD != 0 {
+		t.Errorf("rerun estimate = %+v, want all cached", est)
+	}
+	r2 := runAIBacktest(t, second, candles)
+	if failing.calls != 0 {
+		t.Errorf("rerun made %d provider calls", failing.calls)
+	}
+	if len(r1.Trades) != len(r2.Trades) || r1.FinalEquity != r2.FinalEquity {
+		t.Errorf("rerun differs: trades %d/%d equity %f/%f", len(r1.Trades), len(r2.Trades), r1.FinalEquity, r2.FinalEquity)
+	}
+}
+
+func TestAIStrategy_ModelChangesCacheKey(t *testing.T) {
+	candles := aiCandles(30)
+	cache := NewMemoryDecisionCache()
+
+	a := NewAIStrategy(context.Background(), AIStrategyConfig{Model: "a", Warmup: 20}, priceInputBuilder{}, &countingAI{confidence: 80}, cache)
+	a.Prepare(candles)
+	runAIBacktest(t, a, candles)
+
+	b := NewAIStrategy(context.Background(), AIStrategyConfig{Model: "b", Warmup: 20}, priceInputBuilder{}, &countingAI{confidence: 80}, cache)
+	est, _ := b.Prepare(candles)
+	if est.CachedCalls != 0 {
+		t.Errorf("cached = %d, a different model must not reuse decisions", est.CachedCalls)
+	}
+}
+
+func TestAIStrategy_MinConfidenceAndErrors(t *testing.T) {
+	candles := aiCandles(30)
+
+	low := NewAIStrategy(context.Background(), AIStrategyConfig{Warmup: 20, MinConfidence: 70}, priceInputBuilder{}, &countingAI{confidence: 50}, nil)
+	low.Prepare(candles)
+	if r := runAIBacktest(t, low, candles); len(r.Trades) != 0 {
+		t.Errorf("low confidence produced %d trades", len(r.Trades))
+	}
+
+	failing := NewAIStrategy(context.Background(), AIStrategyConfig{Warmup: 20}, priceInputBuilder{}, &countingAI{err: errors.New("boom")}, nil)
+	failing.Prepare(candles)
+	runAIBacktest(t, failing, candles)
+	if st := failing.Stats(); st.Errors == 0 || st.Signals != 0 {
+		t.Errorf("stats = %+v, want errors treated as hold", st)
+	}
+}
+
+func TestFileDecisionCache_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c, err := LoadFileDecisionCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("abc", &claude.Decision{Action: claude.ActionSell, Confidence: 77})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadFileDecisionCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := reloaded.Get("abc")
+	if !ok || d.Action != claude.ActionSell || d.Confidence != 77 {
+		t.Errorf("reloaded = %+v, %v", d, ok)
+	}
+}
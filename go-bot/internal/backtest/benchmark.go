@@ -0,0 +1,227 @@
+// benchmark-relative performance — buy-and-hold and external benchmark curves,
+// alpha/beta/information ratio/capture against them, exposure time, and
+// monthly/yearly return tables.
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// BenchmarkMetrics compares the strategy against one benchmark equity curve.
+// Alpha, beta and capture ratios are computed from per-bar returns.
+type BenchmarkMetrics struct {
+	Name             string  `json:"name"`              // "buy_and_hold" or the benchmark symbol
+	TotalReturnPct   float64 `json:"total_return_pct"`  // benchmark return over the period
+	AnnualizedReturn float64 `json:"annualized_return"` // benchmark CAGR
+	MaxDrawdown      float64 `json:"max_drawdown"`      // benchmark max drawdown, percent
+	ExcessReturnPct  float64 `json:"excess_return_pct"` // strategy minus benchmark total return
+
+	Alpha            float64 `json:"alpha"`             // annualized, percent
+	Beta             float64 `json:"beta"`              // sensitivity to benchmark returns
+	Correlation      float64 `json:"correlation"`       // of per-bar returns
+	TrackingError    float64 `json:"tracking_error"`    // annualized, percent
+	InformationRatio float64 `json:"information_ratio"` // annualized excess return / tracking error
+	UpCapture        float64 `json:"up_capture"`        // percent of benchmark up-bar returns captured
+	DownCapture      float64 `json:"down_capture"`      // percent of benchmark down-bar returns captured
+}
+
+// PeriodReturn is one row of a monthly or yearly return table.
+type PeriodReturn struct {
+	Period        string   `json:"period"` // "2024-03" or "2024"
+	ReturnPct     float64  `json:"return_pct"`
+	BuyAndHoldPct float64  `json:"buy_and_hold_pct"`
+	BenchmarkPct  *float64 `json:"benchmark_pct,omitempty"`
+}
+
+// buyAndHoldCurve values capital fully invested at the first close, net of the
+// entry fee and marked at liquidation value (exit fee deducted) on every bar.
+// timestamps match the engine's equity curve.
+func buyAndHoldCurve(candles []exchange.Candle, capital, feeRate float64) []EquityPoint {
+	if len(candles) == 0 || candles[0].Close <= 0 {
+		return nil
+	}
+	qty := capital * (1 - feeRate) / candles[0].Close
+
+	curve := make([]EquityPoint, 0, len(candles)+1)
+	curve = append(curve, EquityPoint{Time: candles[0].OpenTime, Equity: capital})
+	for _, c := range candles {
+		curve = append(curve, EquityPoint{Time: c.OpenTime, Equity: qty * c.Close * (1 - feeRate)})
+	}
+	return curve
+}
+
+// benchmarkCurve is a buy-and-hold curve of another instrument, sampled on the
+// strategy's bars. each bar uses the latest benchmark close at or before it.
+func benchmarkCurve(candles, bench []exchange.Candle, capital, feeRate float64) []EquityPoint {
+	if len(candles) == 0 || len(bench) == 0 {
+		return nil
+	}
+
+	aligned := make([]exchange.Candle, len(candles))
+	j := 0
+	for i, c := range candles {
+		for j+1 < len(bench) && !bench[j+1].OpenTime.After(c.OpenTime) {
+			j++
+		}
+		aligned[i] = exchange.Candle{OpenTime: c.OpenTime, Close: bench[j].Close}
+	}
+	return buyAndHoldCurve(aligned, capital, feeRate)
+}
+
+// computeBenchmarkMetrics compares a strategy curve to a benchmark curve of equal length.
+func computeBenchmarkMetrics(name string, strategy, bench []EquityPoint, years float64) *BenchmarkMetrics {
+	if len(bench) < 2 || len(bench) != len(strategy) || bench[0].Equity <= 0 {
+		return nil
+	}
+
+	bm := &BenchmarkMetrics{Name: name}
+	first, last := bench[0].Equity, bench[len(bench)-1].Equity
+	bm.TotalReturnPct = (last - first) / first * 100
+	if years > 0 && last > 0 {
+		bm.AnnualizedReturn = (math.Pow(last/first, 1/years) - 1) * 100
+	}
+	bm.MaxDrawdown, _ = maxDrawdown(bench)
+	if strategy[0].Equity > 0 {
+		bm.ExcessReturnPct = (strategy[len(strategy)-1].Equity-strategy[0].Equity)/strategy[0].Equity*100 - bm.TotalReturnPct
+	}
+
+	rs, rb := barReturns(strategy), barReturns(bench)
+	if len(rs) < 2 || years <= 0 {
+		return bm
+	}
+	periodsPerYear := float64(len(rs)) / years
+
+	meanS, meanB := avg(rs), avg(rb)
+	var cov, varS, varB float64
+	excess := make([]float64, len(rs))
+	for i := range rs {
+		ds, db := rs[i]-meanS, rb[i]-meanB
+		cov += ds * db
+		varS += ds * ds
+		varB += db * db
+		excess[i] = rs[i] - rb[i]
+	}
+	if varB > 0 {
+		bm.Beta = cov / varB
+	}
+	if varS > 0 && varB > 0 {
+		bm.Correlation = cov / math.Sqrt(varS*varB)
+	}
+	bm.Alpha = (meanS - bm.Beta*meanB) * periodsPerYear * 100
+
+	meanEx := avg(excess)
+	if te := stddev(excess, meanEx); te > 0 {
+		bm.TrackingError = te * math.Sqrt(periodsPerYear) * 100
+		bm.InformationRatio = meanEx / te * math.Sqrt(periodsPerYear)
+	}
+
+	// capture: average strategy return on benchmark up (down) bars relative to
+	// the benchmark's average on those bars
+	var upS, upB, downS, downB float64
+	for i := range rb {
+		switch {
+		case rb[i] > 0:
+			upS += rs[i]
+			upB += rb[i]
+		case rb[i] < 0:
+			downS += rs[i]
+			downB += rb[i]
+		}
+	}
+	if upB != 0 {
+		bm.UpCapture = upS / upB * 100
+	}
+	if downB != 0 {
+		bm.DownCapture = downS / downB * 100
+	}
+
+	return bm
+}
+
+// barReturns converts an equity curve into simple per-point returns.
+func barReturns(curve []EquityPoint) []float64 {
+	if len(curve) < 2 {
+		return nil
+	}
+	out := make([]float64, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		if prev := curve[i-1].Equity; prev > 0 {
+			out[i-1] = curve[i].Equity/prev - 1
+		}
+	}
+	return out
+}
+
+// exposurePct is the share of the backtest period with at least one open
+// position (overlapping trades are merged).
+func exposurePct(trades []Trade, start, end time.Time) float64 {
+	total := end.Sub(start)
+	if total <= 0 || len(trades) == 0 {
+		return 0
+	}
+
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].EntryTime.Before(sorted[j].EntryTime) })
+
+	var inMarket time.Duration
+	curStart, curEnd := sorted[0].EntryTime, sorted[0].ExitTime
+	for _, t := range sorted[1:] {
+		if t.EntryTime.After(curEnd) {
+			inMarket += curEnd.Sub(curStart)
+			curStart, curEnd = t.EntryTime, t.ExitTime
+		} else if t.ExitTime.After(curEnd) {
+			curEnd = t.ExitTime
+		}
+	}
+	inMarket += curEnd.Sub(curStart)
+
+	return math.Min(float64(inMarket)/float64(total)*100, 100)
+}
+
+// periodReturns builds a return table for the strategy, buy-and-hold, and
+// optional benchmark curves, bucketed by the layout (e.g. "2006-01" for months).
+// each period's return is measured from the previous period's closing equity.
+func periodReturns(strategy, hodl, bench []EquityPoint, layout string) []PeriodReturn {
+	if len(strategy) == 0 {
+		return nil
+	}
+
+	var rows []PeriodReturn
+	open := 0
+	for i := 1; i <= len(strategy); i++ {
+		if i < len(strategy) && strategy[i].Time.UTC().Format(layout) == strategy[open].Time.UTC().Format(layout) {
+			continue
+		}
+		// period spans [open, i-1]; its base is the close of the prior period
+		base := open - 1
+		if base < 0 {
+			base = 0
+		}
+		row := PeriodReturn{
+			Period:    strategy[open].Time.UTC().Format(layout),
+			ReturnPct: pctChange(strategy, base, i-1),
+		}
+		if len(hodl) == len(strategy) {
+			row.BuyAndHoldPct = pctChange(hodl, base, i-1)
+		}
+		if len(bench) == len(strategy) {
+			v := pctChange(bench, base, i-1)
+			row.BenchmarkPct = &v
+		}
+		rows = append(rows, row)
+		open = i
+	}
+	return rows
+}
+
+func pctChange(curve []EquityPoint, from, to int) float64 {
+	if curve[from].Equity <= 0 {
+		return 0
+	}
+	return (curve[to].Equity - curve[from].Equity) / curve[from].Equity * 100
+}
@@ -0,0 +1,224 @@
+package backtest
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+func dailyCurve(start time.Time, values ...float64) []EquityPoint {
+	curve := make([]EquityPoint, len(values))
+	for i, v := range values {
+		curve[i] = EquityPoint{Time: start.AddDate(0, 0, i), Equity: v}
+	}
+	return curve
+}
+
+func TestBuyAndHoldCurve_AfterFees(t *testing.T) {
+	candles := closes(100, 110, 120)
+	curve := buyAndHoldCurve(candles, 1000, 0.01)
+
+	if len(curve) != 4 {
+		t.Fatalf("len = %d, want 4 (initial + one per candle)", len(curve))
+	}
+	if curve[0].Equity != 1000 {
+		t.Errorf("initial = %f, want 1000", curve[0].Equity)
+	}
+	// 990 invested at 100 → 9.9 units, marked at 120 less 1% exit fee
+	want := 9.9 * 120 * 0.99
+	if math.Abs(curve[3].Equity-want) > 1e-9 {
+		t.Errorf("final = %f, want %f", curve[3].Equity, want)
+	}
+}
+
+func TestBenchmarkCurve_AlignsToStrategyBars(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]exchange.Candle, 4)
+	for i := range candles {
+		candles[i] = exchange.Candle{OpenTime: base.Add(time.Duration(i) * time.Hour), Close: 100}
+	}
+	// benchmark missing the 01:00 bar: it should carry the 00:00 close forward
+	bench := []exchange.Candle{
+		{OpenTime: base, Close: 10},
+		{OpenTime: base.Add(2 * time.Hour), Close: 20},
+		{OpenTime: base.Add(3 * time.Hour), Close: 30},
+	}
+
+	curve := benchmarkCurve(candles, bench, 1000, 0)
+	want := []float64{1000, 1000, 1000, 2000, 3000}
+	if len(curve) != len(want) {
+		t.Fatalf("len = %d, want %d", len(curve), len(want))
+	}
+	for i, w := range want {
+		if math.Abs(curve[i].Equity-w) > 1e-9 {
+			t.Errorf("curve[%d] = %f, want %f", i, curve[i].Equity, w)
+		}
+	}
+}
+
+func TestComputeBenchmarkMetrics_IdenticalCurves(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	curve := dailyCurve(base, 100, 102, 101, 105, 103, 108)
+
+	bm := computeBenchmarkMetrics("buy_and_hold", curve, curve, 1)
+	if math.Abs(bm.Beta-1) > 1e-9 || math.Abs(bm.Correlation-1) > 1e-9 {
+		t.Errorf("beta=%f corr=%f, want 1/1", bm.Beta, bm.Correlation)
+	}
+	if math.Abs(bm.Alpha) > 1e-9 || bm.ExcessReturnPct != 0 || bm.InformationRatio != 0 {
+		t.Errorf("alpha=%f excess=%f ir=%f, want 0", bm.Alpha, bm.ExcessReturnPct, bm.InformationRatio)
+	}
+	if math.Abs(bm.UpCapture-100) > 1e-9 || math.Abs(bm.DownCapture-100) > 1e-9 {
+		t.Errorf("capture up=%f down=%f, want 100/100", bm.UpCapture, bm.DownCapture)
+	}
+	if math.Abs(bm.TotalReturnPct-8) > 1e-9 {
+		t.Errorf("benchmark return = %f, want 8", bm.TotalReturnPct)
+	}
+}
+
+func TestComputeBenchmarkMetrics_LeveragedAndFlat(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bench := dailyCurve(base, 100, 110, 99, 108.9)
+
+	// strategy with exactly double the benchmark's per-bar returns
+	rb := barReturns(bench)
+	lev := []float64{100}
+	for _, r := range rb {
+		lev = append(lev, lev[len(lev)-1]*(1+2*r))
+	}
+	bm := computeBenchmarkMetrics("x", dailyCurve(base, lev...), bench, 1)
+	if math.Abs(bm.Beta-2) > 1e-9 {
+		t.Errorf("beta = %f, want 2", bm.Beta)
+	}
+	if math.Abs(bm.UpCapture-200) > 1e-9 || math.Abs(bm.DownCapture-200) > 1e-9 {
+		t.Errorf("capture up=%f down=%f, want 200/200", bm.UpCapture, bm.DownCapture)
+	}
+
+	// a strategy that stays in cash has no beta and no capture
+	flat := computeBenchmarkMetrics("x", dailyCurve(base, 100, 100, 100, 100), bench, 1)
+	if flat.Beta != 0 || flat.UpCapture != 0 || flat.DownCapture != 0 {
+		t.Errorf("flat strategy = %+v", flat)
+	}
+	if flat.ExcessReturnPct >= 0 {
+		t.Errorf("excess = %f, want negative vs a rising benchmark", flat.ExcessReturnPct)
+	}
+
+	if computeBenchmarkMetrics("x", bench[:2], bench, 1) != nil {
+		t.Error("expected nil for mismatched curve lengths")
+	}
+}
+
+func TestExposurePct_MergesOverlaps(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		{EntryTime: base.Add(10 * time.Hour), ExitTime: base.Add(30 * time.Hour)},
+		{EntryTime: base.Add(20 * time.Hour), ExitTime: base.Add(40 * time.Hour)}, // overlaps the first
+		{EntryTime: base.Add(70 * time.Hour), ExitTime: base.Add(80 * time.Hour)},
+	}
+	got := exposurePct(trades, base, base.Add(100*time.Hour))
+	if math.Abs(got-40) > 1e-9 {
+		t.Errorf("exposure = %f, want 40", got)
+	}
+	if exposurePct(nil, base, base.Add(time.Hour)) != 0 {
+		t.Error("expected zero exposure with no trades")
+	}
+}
+
+func TestPeriodReturns_Monthly(t *testing.T) {
+	start := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	// Jan 30, Jan 31, Feb 1, Feb 2
+	strategy := dailyCurve(start, 100, 110, 121, 99)
+	hodl := dailyCurve(start, 100, 100, 105, 105)
+
+	rows := periodReturns(strategy, hodl, nil, "2006-01")
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	if rows[0].Period != "2024-01" || math.Abs(rows[0].ReturnPct-10) > 1e-9 {
+		t.Errorf("jan = %+v, want 10%%", rows[0])
+	}
+	// feb is measured from jan's close (110 → 99)
+	if rows[1].Period != "2024-02" || math.Abs(rows[1].ReturnPct-(-10)) > 1e-9 {
+		t.Errorf("feb = %+v, want -10%%", rows[1])
+	}
+	if math.Abs(rows[1].BuyAndHoldPct-5) > 1e-9 || rows[1].BenchmarkPct != nil {
+		t.Errorf("feb hodl = %f bench = %v", rows[1].BuyAndHoldPct, rows[1].BenchmarkPct)
+	}
+
+	yearly := periodReturns(strategy, hodl, hodl, "2006")
+	if len(yearly) != 1 || math.Abs(yearly[0].ReturnPct-(-1)) > 1e-9 || yearly[0].BenchmarkPct == nil {
+		t.Errorf("yearly = %+v", yearly)
+	}
+}
+
+// symbolLoader serves candles per symbol.
+type symbolLoader map[string][]exchange.Candle
+
+func (l symbolLoader) LoadCandles(_ context.Context, symbol, _ string, _, _ time.Time) ([]exchange.Candle, error) {
+	return l[symbol], nil
+}
+
+func TestEngine_BenchmarkCurves(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	asset := make([]exchange.Candle, 60)
+	bench := make([]exchange.Candle, 60)
+	for i := range asset {
+		p := 100 + float64(i)
+		asset[i] = exchange.Candle{OpenTime: base.Add(time.Duration(i) * 24 * time.Hour), Open: p, High: p + 1, Low: p - 1, Close: p, Volume: 1000}
+		bench[i] = exchange.Candle{OpenTime: asset[i].OpenTime, Close: 50 + float64(i%5)}
+	}
+
+	engine := NewEngine(Config{
+		Symbol: "BTC/USDT", Interval: "1d", InitialCapital: 10000, FeeRate: 0.001, Benchmark: "ETH/USDT",
+	}, symbolLoader{"BTC/USDT": asset, "ETH/USDT": bench}, &fixedSignalStrategy{
+		signalAt: 5,
+		signal:   &Signal{Action: ActionBuy, StopLoss: 50, TakeProfit: 500, Size: 0.5},
+	})
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.BuyAndHold) != len(result.EquityCurve) || len(result.Benchmark) != len(result.EquityCurve) {
+		t.Fatalf("curve lengths equity=%d hodl=%d bench=%d", len(result.EquityCurve), len(result.BuyAndHold), len(result.Benchmark))
+	}
+
+	m := ComputeMetrics(result)
+	if m.BuyAndHold == nil || m.Benchmark == nil || m.Benchmark.Name != "ETH/USDT" {
+		t.Fatalf("benchmarks = %+v / %+v", m.BuyAndHold, m.Benchmark)
+	}
+	// half the capital in a steadily rising asset lags holding all of it
+	if m.BuyAndHold.ExcessReturnPct >= 0 {
+		t.Errorf("excess vs hodl = %f, want negative", m.BuyAndHold.ExcessReturnPct)
+	}
+	if m.BuyAndHold.Beta <= 0 || m.BuyAndHold.Beta >= 1 {
+		t.Errorf("beta = %f, want between 0 and 1", m.BuyAndHold.Beta)
+	}
+	if m.ExposurePct <= 80 || m.ExposurePct > 100 {
+		t.Errorf("exposure = %f", m.ExposurePct)
+	}
+	if len(m.Monthly) != 2 || len(m.Yearly) != 1 {
+		t.Errorf("monthly=%d yearly=%d, want 2/1", len(m.Monthly), len(m.Yearly))
+	}
+
+	var buf bytes.Buffer
+	if err := NewReport(result, m).WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Benchmarks", "ETH/USDT", "Returns by Month", "2024-02"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+}
+
+func TestEngine_BenchmarkMissing(t *testing.T) {
+	engine := NewEngine(Config{Symbol: "BTC/USDT", Interval: "1h", InitialCapital: 1000, Benchmark: "NOPE/USDT"},
+		symbolLoader{"BTC/USDT": closes(1, 2, 3)}, &fixedSignalStrategy{signalAt: -1})
+	if _, err := engine.Run(context.Background()); err == nil {
+		t.Error("expected error when the benchmark has no candles")
+	}
+}
@@ -14,38 +14,46 @@ import (
 
 // Config controls the backtesting parameters.
 type Config struct {
-	Symbol         string
-	Interval       string
-	StartTime      time.Time
-	EndTime        time.Time
-	InitialCapital float64
-	FeeRate        float64 // per-trade fee rate (e.g. 0.001 = 0.1%)
-	MaxOpenTrades  int     // max concurrent positions (0 = 1)
-	Slippage       float64 // simulated slippage as fraction (e.g. 0.0005 = 0.05%)
-	TrailingStop   *TrailingStopConfig
-	WindowSize     int // number of candles fed to strategy (0 = all available)
+	Symbol         string              `json:"symbol"`
+	Interval       string              `json:"interval"`
+	StartTime      time.Time           `json:"start_time"`
+	EndTime        time.Time           `json:"end_time"`
+	InitialCapital float64             `json:"initial_capital"`
+	FeeRate        float64             `json:"fee_rate"`        // per-trade fee rate (e.g. 0.001 = 0.1%)
+	MaxOpenTrades  int                 `json:"max_open_trades"` // max concurrent positions (0 = 1)
+	Slippage       float64             `json:"slippage"`        // simulated slippage as fraction (e.g. 0.0005 = 0.05%)
+	TrailingStop   *TrailingStopConfig `json:"trailing_stop,omitempty"`
+	WindowSize     int                 `json:"window_size,omitempty"` // number of candles fed to strategy (0 = all available)
+
+	// intrabar fill resolution: when a bar touches both stop and target, replay
+	// this lower timeframe (e.g. "1m") to see which was hit first ("" = assume stop)
+	IntrabarInterval string `json:"intrabar_interval,omitempty"`
+	// set when fills are priced by a SlippageEstimator instead of the flat Slippage fraction
+	SlippageModel bool `json:"slippage_model,omitempty"`
+	// optional benchmark symbol (e.g. "ETH/USDT") held buy-and-hold alongside the strategy
+	Benchmark string `json:"benchmark,omitempty"`
 }
 
 // TrailingStopConfig enables trailing stops on backtest positions.
 type TrailingStopConfig struct {
-	TrailPercent   float64 // trail distance as fraction
-	ActivationPct  float64 // activation threshold as fraction (0 = immediate)
+	TrailPercent  float64 `json:"trail_percent"`  // trail distance as fraction
+	ActivationPct float64 `json:"activation_pct"` // activation threshold as fraction (0 = immediate)
 }
 
 // Trade records a completed round-trip trade.
 type Trade struct {
-	EntryTime   time.Time
-	ExitTime    time.Time
-	Side        Action // BUY = long, SELL = short
-	EntryPrice  float64
-	ExitPrice   float64
-	Quantity    float64
-	EntryFee    float64
-	ExitFee     float64
-	PnL         float64
-	PnLPercent  float64
-	ExitReason  string
-	Bars        int // number of candles held
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	Side       Action    `json:"side"` // BUY = long, SELL = short
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	EntryFee   float64   `json:"entry_fee"`
+	ExitFee    float64   `json:"exit_fee"`
+	PnL        float64   `json:"pnl"`
+	PnLPercent float64   `json:"pnl_percent"`
+	ExitReason string    `json:"exit_reason"`
+	Bars       int       `json:"bars"` // number of candles held
 }
 
 // position tracks an open backtest position.
@@ -63,25 +71,54 @@ type position struct {
 
 // EquityPoint is a snapshot of equity at a given time.
 type EquityPoint struct {
-	Time   time.Time
-	Equity float64
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
 }
 
 // Result holds the complete backtest output.
 type Result struct {
 	Config       Config
+	Strategy     string
 	Trades       []Trade
 	EquityCurve  []EquityPoint
 	FinalEquity  float64
 	TotalCandles int
 	Duration     time.Duration
+
+	// intrabar stats: bars where stop and target were both inside the range,
+	// and how many of those were resolved from lower-timeframe candles
+	AmbiguousBars    int
+	IntrabarResolved int
+
+	// buy-and-hold of the traded symbol and of Config.Benchmark, after fees,
+	// on the same timestamps as EquityCurve
+	BuyAndHold []EquityPoint
+	Benchmark  []EquityPoint
+}
+
+// SlippageEstimator supplies per-symbol slippage estimates in basis points.
+// satisfied by *exchange.SlippageModel, so backtests can reuse live fill statistics.
+type SlippageEstimator interface {
+	EstimateBps(symbol string) float64
+	WorstCaseBps(symbol string) float64
 }
 
+// participation rate (order notional / bar quote volume) at which fills are
+// priced at the worst-case estimate
+const fullImpactParticipation = 0.01
+
 // Engine runs backtests.
 type Engine struct {
 	config   Config
 	loader   CandleLoader
 	strategy Strategy
+
+	intrabar  CandleLoader      // optional, defaults to loader
+	benchmark CandleLoader      // optional, defaults to loader
+	slippage  SlippageEstimator // optional, replaces the flat slippage fraction
+
+	ambiguous int
+	resolved  int
 }
 
 // NewEngine creates a backtesting engine.
@@ -92,6 +129,26 @@ func NewEngine(cfg Config, loader CandleLoader, strategy Strategy) *Engine {
 	return &Engine{config: cfg, loader: loader, strategy: strategy}
 }
 
+// SetIntrabarLoader sets the source for lower-timeframe candles used to resolve
+// ambiguous bars. Without it the main loader is used with Config.IntrabarInterval.
+func (e *Engine) SetIntrabarLoader(loader CandleLoader) {
+	e.intrabar = loader
+}
+
+// SetBenchmarkLoader sets the source for Config.Benchmark candles.
+// Without it the main loader is used.
+func (e *Engine) SetBenchmarkLoader(loader CandleLoader) {
+	e.benchmark = loader
+}
+
+// SetSlippageModel prices fills from live slippage statistics instead of Config.Slippage.
+// entries and targets use the expected slippage, stops the worst case, and large
+// orders relative to bar volume are pushed toward the worst case.
+func (e *Engine) SetSlippageModel(model SlippageEstimator) {
+	e.slippage = model
+	e.config.SlippageModel = model != nil
+}
+
 // Run executes the backtest and returns results.
 func (e *Engine) Run(ctx context.Context) (*Result, error) {
 	start := time.Now()
@@ -104,6 +161,7 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("insufficient candles: got %d, need at least 2", len(candles))
 	}
 
+	e.ambiguous, e.resolved = 0, 0
 	capital := e.config.InitialCapital
 	var positions []*position
 	var trades []Trade
@@ -113,7 +171,7 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 		candle := candles[i]
 
 		// check exits on existing positions
-		positions, capital, trades = e.checkExits(positions, capital, trades, candle, i)
+		positions, capital, trades = e.checkExits(ctx, positions, capital, trades, candle, i)
 
 		// get strategy signal
 		signal := e.getSignal(candles, i)
@@ -127,29 +185,52 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 			}
 		}
 
-		// record equity (capital + unrealized)
+		// record equity (capital + committed notional + unrealized)
 		unrealized := e.unrealizedPnL(positions, candle.Close)
 		equity = append(equity, EquityPoint{
 			Time:   candle.OpenTime,
-			Equity: capital + unrealized,
+			Equity: capital + positionValue(positions) + unrealized,
 		})
 	}
 
 	// force-close remaining positions at last candle
 	lastCandle := candles[len(candles)-1]
 	for _, pos := range positions {
-		trade := e.closePosition(pos, lastCandle, len(candles)-1, "end_of_data")
+		trade := e.closePosition(pos, lastCandle, len(candles)-1, "end_of_data", e.fillSlippage(pos.quantity, lastCandle, false))
 		capital += trade.Quantity*trade.ExitPrice - trade.ExitFee
 		trades = append(trades, trade)
 	}
 
+	var bench []EquityPoint
+	if e.config.Benchmark != "" {
+		loader := e.benchmark
+		if loader == nil {
+			loader = e.loader
+		}
+		benchCandles, err := loader.LoadCandles(ctx, e.config.Benchmark, e.config.Interval, e.config.StartTime, e.config.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("load benchmark candles: %w", err)
+		}
+		if len(benchCandles) == 0 {
+			return nil, fmt.Errorf("no benchmark candles for %s", e.config.Benchmark)
+		}
+		bench = benchmarkCurve(candles, benchCandles, e.config.InitialCapital, e.config.FeeRate)
+	}
+
 	return &Result{
 		Config:       e.config,
+		Strategy:     e.strategy.Name(),
 		Trades:       trades,
 		EquityCurve:  equity,
 		FinalEquity:  capital,
 		TotalCandles: len(candles),
 		Duration:     time.Since(start),
+
+		AmbiguousBars:    e.ambiguous,
+		IntrabarResolved: e.resolved,
+
+		BuyAndHold: buyAndHoldCurve(candles, e.config.InitialCapital, e.config.FeeRate),
+		Benchmark:  bench,
 	}, nil
 }
 
@@ -175,10 +256,11 @@ func (e *Engine) openPosition(signal *Signal, candle exchange.Candle, capital fl
 
 	price := candle.Close
 	// apply slippage
+	slip := e.fillSlippage(available/price, candle, false)
 	if signal.Action == ActionBuy {
-		price *= (1 + e.config.Slippage)
+		price *= (1 + slip)
 	} else {
-		price *= (1 - e.config.Slippage)
+		price *= (1 - slip)
 	}
 
 	fee := available * e.config.FeeRate
@@ -209,7 +291,7 @@ func (e *Engine) openPosition(signal *Signal, candle exchange.Candle, capital fl
 	return pos
 }
 
-func (e *Engine) checkExits(positions []*position, capital float64, trades []Trade, candle exchange.Candle, bar int) ([]*position, float64, []Trade) {
+func (e *Engine) checkExits(ctx context.Context, positions []*position, capital float64, trades []Trade, candle exchange.Candle, bar int) ([]*position, float64, []Trade) {
 	var remaining []*position
 
 	for _, pos := range positions {
@@ -218,43 +300,54 @@ func (e *Engine) checkExits(positions []*position, capital float64, trades []Tra
 
 		if pos.side == ActionBuy {
 			// long position
-			if pos.stopLoss > 0 && candle.Low <= pos.stopLoss {
+			stopHit := pos.stopLoss > 0 && candle.Low <= pos.stopLoss
+			targetHit := pos.takeProfit > 0 && candle.High >= pos.takeProfit
+			if stopHit && targetHit {
+				reason, exitPrice = e.resolveAmbiguous(ctx, pos, candle)
+			} else if stopHit {
 				reason = "stop_loss"
-				exitPrice = pos.stopLoss
-			} else if pos.takeProfit > 0 && candle.High >= pos.takeProfit {
+				exitPrice = gapFill(pos.side, pos.stopLoss, candle.Open)
+			} else if targetHit {
 				reason = "take_profit"
 				exitPrice = pos.takeProfit
 			}
 
 			// trailing stop
 			if pos.trailing != nil {
+				prevStop := pos.trailing.StopPrice
 				newStop, _ := pos.trailing.UpdateLong(pos.entryPrice, candle.High)
 				if newStop > 0 && pos.trailing.IsHitLong(candle.Low) && reason == "" {
 					reason = "trailing_stop"
-					exitPrice = pos.trailing.StopPrice
+					exitPrice = trailingFill(pos.side, prevStop, pos.trailing.StopPrice, candle.Open)
 				}
 			}
 		} else {
 			// short position
-			if pos.stopLoss > 0 && candle.High >= pos.stopLoss {
+			stopHit := pos.stopLoss > 0 && candle.High >= pos.stopLoss
+			targetHit := pos.takeProfit > 0 && candle.Low <= pos.takeProfit
+			if stopHit && targetHit {
+				reason, exitPrice = e.resolveAmbiguous(ctx, pos, candle)
+			} else if stopHit {
 				reason = "stop_loss"
-				exitPrice = pos.stopLoss
-			} else if pos.takeProfit > 0 && candle.Low <= pos.takeProfit {
+				exitPrice = gapFill(pos.side, pos.stopLoss, candle.Open)
+			} else if targetHit {
 				reason = "take_profit"
 				exitPrice = pos.takeProfit
 			}
 
 			if pos.trailing != nil {
+				prevStop := pos.trailing.StopPrice
 				newStop, _ := pos.trailing.UpdateShort(pos.entryPrice, candle.Low)
 				if newStop > 0 && pos.trailing.IsHitShort(candle.High) && reason == "" {
 					reason = "trailing_stop"
-					exitPrice = pos.trailing.StopPrice
+					exitPrice = trailingFill(pos.side, prevStop, pos.trailing.StopPrice, candle.Open)
 				}
 			}
 		}
 
 		if reason != "" {
-			trade := e.closePosition(pos, exchange.Candle{OpenTime: candle.OpenTime, Close: exitPrice}, bar, reason)
+			slip := e.fillSlippage(pos.quantity, candle, reason != "take_profit")
+			trade := e.closePosition(pos, exchange.Candle{OpenTime: candle.OpenTime, Close: exitPrice}, bar, reason, slip)
 			capital += trade.Quantity*trade.ExitPrice - trade.ExitFee
 			trades = append(trades, trade)
 		} else {
@@ -265,13 +358,150 @@ func (e *Engine) checkExits(positions []*position, capital float64, trades []Tra
 	return remaining, capital, trades
 }
 
-func (e *Engine) closePosition(pos *position, candle exchange.Candle, bar int, reason string) Trade {
+// resolveAmbiguous decides whether the stop or the target filled first on a bar
+// whose range covers both. It replays lower-timeframe candles when configured;
+// otherwise (or if they don't settle it) the stop is assumed, which is conservative.
+func (e *Engine) resolveAmbiguous(ctx context.Context, pos *position, candle exchange.Candle) (string, float64) {
+	e.ambiguous++
+
+	if e.config.IntrabarInterval != "" {
+		for _, sub := range e.loadIntrabar(ctx, candle) {
+			var stopHit, targetHit bool
+			if pos.side == ActionBuy {
+				stopHit = sub.Low <= pos.stopLoss
+				targetHit = sub.High >= pos.takeProfit
+			} else {
+				stopHit = sub.High >= pos.stopLoss
+				targetHit = sub.Low <= pos.takeProfit
+			}
+			if stopHit && targetHit {
+				break // still ambiguous at the lower timeframe
+			}
+			if stopHit {
+				e.resolved++
+				return "stop_loss", gapFill(pos.side, pos.stopLoss, sub.Open)
+			}
+			if targetHit {
+				e.resolved++
+				return "take_profit", pos.takeProfit
+			}
+		}
+	}
+
+	return "stop_loss", gapFill(pos.side, pos.stopLoss, candle.Open)
+}
+
+// loadIntrabar returns the lower-timeframe candles that make up one bar.
+// load errors are treated as "no data" so the run falls back to the conservative fill.
+func (e *Engine) loadIntrabar(ctx context.Context, candle exchange.Candle) []exchange.Candle {
+	loader := e.intrabar
+	if loader == nil {
+		loader = e.loader
+	}
+
+	end := candle.CloseTime
+	if end.IsZero() || !end.After(candle.OpenTime) {
+		end = candle.OpenTime.Add(intervalDuration(e.config.Interval) - time.Nanosecond)
+	}
+	if !end.After(candle.OpenTime) {
+		return nil
+	}
+
+	subs, err := loader.LoadCandles(ctx, e.config.Symbol, e.config.IntrabarInterval, candle.OpenTime, end)
+	if err != nil {
+		return nil
+	}
+
+	// loaders may return a wider range (e.g. most recent N candles), keep only this bar
+	var out []exchange.Candle
+	for _, c := range subs {
+		if !c.OpenTime.Before(candle.OpenTime) && !c.OpenTime.After(end) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// gapFill returns the realistic stop fill: if the bar opened through the stop,
+// a stop-market order fills at the open, not at the stop price.
+func gapFill(side Action, stop, open float64) float64 {
+	if open <= 0 {
+		return stop
+	}
+	if side == ActionBuy && open < stop {
+		return open
+	}
+	if side == ActionSell && open > stop {
+		return open
+	}
+	return stop
+}
+
+// trailingFill fills a trailing stop hit on this bar. The bar gapped only if
+// it opened through the stop as it stood before the bar; a stop the bar's own
+// extreme moved fills at its new level.
+func trailingFill(side Action, prevStop, stop, open float64) float64 {
+	if prevStop > 0 && gapFill(side, prevStop, open) != prevStop {
+		return open
+	}
+	return stop
+}
+
+// fillSlippage returns the slippage fraction applied to a fill of qty on this bar.
+// without a slippage model it is the flat Config.Slippage.
+func (e *Engine) fillSlippage(qty float64, candle exchange.Candle, adverse bool) float64 {
+	if e.slippage == nil {
+		return e.config.Slippage
+	}
+
+	est := e.slippage.EstimateBps(e.config.Symbol)
+	worst := e.slippage.WorstCaseBps(e.config.Symbol)
+	if worst < est {
+		worst = est
+	}
+	if adverse {
+		// stops and forced exits cross the spread into thin books
+		return worst / 10000
+	}
+
+	bps := est
+	if quoteVol := candle.Volume * candle.Close; quoteVol > 0 {
+		participation := qty * candle.Close / quoteVol
+		impact := math.Min(participation/fullImpactParticipation, 1)
+		bps = est + (worst-est)*impact
+	}
+	return bps / 10000
+}
+
+// intervalDuration converts a candle interval string to a time.Duration.
+func intervalDuration(interval string) time.Duration {
+	switch interval {
+	case "1m":
+		return time.Minute
+	case "5m":
+		return 5 * time.Minute
+	case "15m":
+		return 15 * time.Minute
+	case "30m":
+		return 30 * time.Minute
+	case "1h":
+		return time.Hour
+	case "4h":
+		return 4 * time.Hour
+	case "1d":
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+func (e *Engine) closePosition(pos *position, candle exchange.Candle, bar int, reason string, slippage float64) Trade {
 	exitPrice := candle.Close
 	// apply slippage on exit
 	if pos.side == ActionBuy {
-		exitPrice *= (1 - e.config.Slippage)
+		exitPrice *= (1 - slippage)
 	} else {
-		exitPrice *= (1 + e.config.Slippage)
+		exitPrice *= (1 + slippage)
 	}
 
 	exitFee := pos.quantity * exitPrice * e.config.FeeRate
@@ -305,10 +535,11 @@ func (e *Engine) unrealizedPnL(positions []*position, currentPrice float64) floa
 	for _, pos := range positions {
 		notional := pos.quantity * currentPrice
 		exitFee := notional * e.config.FeeRate
+		// entry fees were already deducted from capital when the position opened
 		if pos.side == ActionBuy {
-			total += (currentPrice-pos.entryPrice)*pos.quantity - pos.entryFee - exitFee
+			total += (currentPrice-pos.entryPrice)*pos.quantity - exitFee
 		} else {
-			total += (pos.entryPrice-currentPrice)*pos.quantity - pos.entryFee - exitFee
+			total += (pos.entryPrice-currentPrice)*pos.quantity - exitFee
 		}
 	}
 	return total
@@ -2,6 +2,7 @@ package backtest
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -301,6 +302,32 @@ func TestEngine_EquityCurve(t *testing.T) {
 	}
 }
 
+func TestEngine_EquityCurveMarksOpenPositions(t *testing.T) {
+	candles := make([]exchange.Candle, 10)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range candles {
+		candles[i] = exchange.Candle{OpenTime: base.Add(time.Duration(i) * time.Hour), Open: 100, High: 101, Low: 99, Close: 100, Volume: 1000}
+	}
+	strategy := &fixedSignalStrategy{
+		signalAt: 2,
+		signal:   &Signal{Action: ActionBuy, StopLoss: 50, TakeProfit: 300, Size: 0.5},
+	}
+
+	result, err := NewEngine(Config{
+		Symbol: "TEST/USDT", Interval: "1h", InitialCapital: 10000, FeeRate: 0.001,
+	}, NewSliceLoader(candles), strategy).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flat price: equity only moves by fees, never by the position notional
+	for _, ep := range result.EquityCurve {
+		if ep.Equity < 9980 || ep.Equity > 10000 {
+			t.Fatalf("equity %f at %v, want ~10000 while holding a flat position", ep.Equity, ep.Time)
+		}
+	}
+}
+
 func TestEngine_MaxOpenTrades(t *testing.T) {
 	candles := generateCandles(50, 100, time.Hour)
 
@@ -361,6 +388,211 @@ func TestEngine_ForceClosesAtEnd(t *testing.T) {
 	}
 }
 
+// --- intrabar fill tests ---
+
+// ambiguousCandles builds flat 1h candles where bar 5 spans both a 95 stop and a 105 target.
+func ambiguousCandles() []exchange.Candle {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]exchange.Candle, 10)
+	for i := range candles {
+		candles[i] = exchange.Candle{
+			OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Open:     100, High: 101, Low: 99, Close: 100, Volume: 1000,
+		}
+	}
+	candles[5].High = 106
+	candles[5].Low = 94
+	return candles
+}
+
+// minuteCandles builds 1m candles for one bar following the given path of closes.
+func minuteCandles(start time.Time, path ...float64) []exchange.Candle {
+	out := make([]exchange.Candle, len(path))
+	prev := 100.0
+	for i, p := range path {
+		out[i] = exchange.Candle{
+			OpenTime: start.Add(time.Duration(i) * time.Minute),
+			Open:     prev, High: math.Max(prev, p), Low: math.Min(prev, p), Close: p, Volume: 10,
+		}
+		prev = p
+	}
+	return out
+}
+
+func runAmbiguous(t *testing.T, intrabar []exchange.Candle, interval string) *Result {
+	t.Helper()
+	candles := ambiguousCandles()
+	engine := NewEngine(Config{
+		Symbol:           "TEST/USDT",
+		Interval:         "1h",
+		InitialCapital:   10000,
+		IntrabarInterval: interval,
+	}, NewSliceLoader(candles), &fixedSignalStrategy{
+		signalAt: 2,
+		signal:   &Signal{Action: ActionBuy, StopLoss: 95, TakeProfit: 105, Size: 0.5},
+	})
+	if intrabar != nil {
+		engine.SetIntrabarLoader(NewSliceLoader(intrabar))
+	}
+
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %d, want 1", len(result.Trades))
+	}
+	return result
+}
+
+func TestEngine_AmbiguousBar_DefaultsToStop(t *testing.T) {
+	result := runAmbiguous(t, nil, "")
+	if result.Trades[0].ExitReason != "stop_loss" {
+		t.Errorf("exit reason = %s, want stop_loss", result.Trades[0].ExitReason)
+	}
+	if result.AmbiguousBars != 1 || result.IntrabarResolved != 0 {
+		t.Errorf("ambiguous=%d resolved=%d, want 1/0", result.AmbiguousBars, result.IntrabarResolved)
+	}
+}
+
+func TestEngine_AmbiguousBar_IntrabarTargetFirst(t *testing.T) {
+	barStart := ambiguousCandles()[5].OpenTime
+	subs := minuteCandles(barStart, 102, 104, 106, 100, 96, 94)
+
+	result := runAmbiguous(t, subs, "1m")
+	if result.Trades[0].ExitReason != "take_profit" {
+		t.Errorf("exit reason = %s, want take_profit", result.Trades[0].ExitReason)
+	}
+	if result.IntrabarResolved != 1 {
+		t.Errorf("resolved = %d, want 1", result.IntrabarResolved)
+	}
+}
+
+func TestEngine_AmbiguousBar_IntrabarStopFirst(t *testing.T) {
+	barStart := ambiguousCandles()[5].OpenTime
+	subs := minuteCandles(barStart, 98, 94, 100, 106)
+
+	result := runAmbiguous(t, subs, "1m")
+	if result.Trades[0].ExitReason != "stop_loss" {
+		t.Errorf("exit reason = %s, want stop_loss", result.Trades[0].ExitReason)
+	}
+	if result.IntrabarResolved != 1 {
+		t.Errorf("resolved = %d, want 1", result.IntrabarResolved)
+	}
+}
+
+func TestEngine_AmbiguousBar_IntrabarMissingFallsBack(t *testing.T) {
+	// lower-timeframe data exists but not for the ambiguous bar
+	subs := minuteCandles(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 100, 101)
+
+	result := runAmbiguous(t, subs, "1m")
+	if result.Trades[0].ExitReason != "stop_loss" {
+		t.Errorf("exit reason = %s, want stop_loss", result.Trades[0].ExitReason)
+	}
+	if result.IntrabarResolved != 0 {
+		t.Errorf("resolved = %d, want 0", result.IntrabarResolved)
+	}
+}
+
+func TestEngine_StopLossGapDown(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := []exchange.Candle{
+		{OpenTime: base, Open: 100, High: 105, Low: 95, Close: 100, Volume: 100},
+		{OpenTime: base.Add(time.Hour), Open: 100, High: 105, Low: 95, Close: 102, Volume: 100},
+		{OpenTime: base.Add(2 * time.Hour), Open: 84, High: 86, Low: 80, Close: 82, Volume: 100}, // opens through the stop
+	}
+
+	engine := NewEngine(Config{
+		Symbol:         "TEST/USDT",
+		Interval:       "1h",
+		InitialCapital: 10000,
+	}, NewSliceLoader(candles), &fixedSignalStrategy{
+		signalAt: 1,
+		signal:   &Signal{Action: ActionBuy, StopLoss: 90, TakeProfit: 120, Size: 0.5},
+	})
+
+	result, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Trades) != 1 || result.Trades[0].ExitReason != "stop_loss" {
+		t.Fatalf("expected one stop_loss exit, got %+v", result.Trades)
+	}
+	if got := result.Trades[0].ExitPrice; got != 84 {
+		t.Errorf("exit price = %f, want the gap open 84, not the stop 90", got)
+	}
+}
+
+func TestGapFill(t *testing.T) {
+	if got := gapFill(ActionBuy, 95, 93); got != 93 {
+		t.Errorf("long gap through stop = %f, want 93", got)
+	}
+	if got := gapFill(ActionBuy, 95, 97); got != 95 {
+		t.Errorf("long no gap = %f, want 95", got)
+	}
+	if got := gapFill(ActionSell, 105, 107); got != 107 {
+		t.Errorf("short gap through stop = %f, want 107", got)
+	}
+}
+
+// --- slippage model tests ---
+
+type stubSlippage struct{ est, worst float64 }
+
+func (s stubSlippage) EstimateBps(string) float64  { return s.est }
+func (s stubSlippage) WorstCaseBps(string) float64 { return s.worst }
+
+func TestEngine_FillSlippage(t *testing.T) {
+	engine := NewEngine(Config{Symbol: "TEST/USDT", Slippage: 0.0005}, nil, nil)
+	candle := exchange.Candle{Close: 100, Volume: 1000} // $100k quote volume
+
+	if got := engine.fillSlippage(1, candle, true); got != 0.0005 {
+		t.Errorf("flat slippage = %f, want 0.0005", got)
+	}
+
+	engine.SetSlippageModel(stubSlippage{est: 5, worst: 20})
+	if !engine.config.SlippageModel {
+		t.Error("config should record that the slippage model is active")
+	}
+
+	if got := engine.fillSlippage(0.001, candle, false); math.Abs(got-0.0005) > 1e-6 {
+		t.Errorf("small order slippage = %f, want ~0.0005 (estimate)", got)
+	}
+	if got := engine.fillSlippage(10, candle, false); math.Abs(got-0.002) > 1e-9 {
+		t.Errorf("large order slippage = %f, want 0.002 (worst case)", got)
+	}
+	if got := engine.fillSlippage(0.001, candle, true); math.Abs(got-0.002) > 1e-9 {
+		t.Errorf("stop slippage = %f, want 0.002 (worst case)", got)
+	}
+}
+
+func TestEngine_SlippageModel_WorsensStops(t *testing.T) {
+	candles := ambiguousCandles()
+	candles[5].High = 101 // only the stop is hit
+	newEngine := func() *Engine {
+		return NewEngine(Config{Symbol: "TEST/USDT", Interval: "1h", InitialCapital: 10000}, NewSliceLoader(candles),
+			&fixedSignalStrategy{signalAt: 2, signal: &Signal{Action: ActionBuy, StopLoss: 95, TakeProfit: 105, Size: 0.5}})
+	}
+
+	flat, err := newEngine().Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	modelled := newEngine()
+	modelled.SetSlippageModel(stubSlippage{est: 5, worst: 50})
+	withModel, err := modelled.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if withModel.Trades[0].ExitPrice >= flat.Trades[0].ExitPrice {
+		t.Errorf("stop exit with model = %f, want below flat %f", withModel.Trades[0].ExitPrice, flat.Trades[0].ExitPrice)
+	}
+	if withModel.FinalEquity >= flat.FinalEquity {
+		t.Error("modelled slippage should reduce equity on a stopped-out trade")
+	}
+}
+
 // --- test strategy helpers ---
 
 type fixedSignalStrategy struct {
@@ -10,42 +10,49 @@ import (
 // Metrics holds computed performance statistics.
 type Metrics struct {
 	// returns
-	TotalReturn     float64 // total P&L in USD
-	TotalReturnPct  float64 // total return as percentage
-	AnnualizedReturn float64 // CAGR
+	TotalReturn      float64 `json:"total_return"`      // total P&L in USD
+	TotalReturnPct   float64 `json:"total_return_pct"`  // total return as percentage
+	AnnualizedReturn float64 `json:"annualized_return"` // CAGR
 
 	// trade stats
-	TotalTrades     int
-	WinningTrades   int
-	LosingTrades    int
-	WinRate         float64 // 0-100
-	ProfitFactor    float64 // gross profit / gross loss
-	AvgWin          float64
-	AvgLoss         float64
-	AvgWinPct       float64
-	AvgLossPct      float64
-	LargestWin      float64
-	LargestLoss     float64
-	AvgBarsHeld     float64
+	TotalTrades   int     `json:"total_trades"`
+	WinningTrades int     `json:"winning_trades"`
+	LosingTrades  int     `json:"losing_trades"`
+	WinRate       float64 `json:"win_rate"`      // 0-100
+	ProfitFactor  float64 `json:"profit_factor"` // gross profit / gross loss
+	AvgWin        float64 `json:"avg_win"`
+	AvgLoss       float64 `json:"avg_loss"`
+	AvgWinPct     float64 `json:"avg_win_pct"`
+	AvgLossPct    float64 `json:"avg_loss_pct"`
+	LargestWin    float64 `json:"largest_win"`
+	LargestLoss   float64 `json:"largest_loss"`
+	AvgBarsHeld   float64 `json:"avg_bars_held"`
 
 	// streaks
-	MaxConsecWins   int
-	MaxConsecLosses int
+	MaxConsecWins   int `json:"max_consec_wins"`
+	MaxConsecLosses int `json:"max_consec_losses"`
 
 	// risk
-	MaxDrawdown     float64 // as percentage
-	MaxDrawdownUSD  float64
-	SharpeRatio     float64 // annualized, assuming risk-free rate = 0
-	SortinoRatio    float64
-	CalmarRatio     float64 // annualized return / max drawdown
+	MaxDrawdown    float64 `json:"max_drawdown"` // as percentage
+	MaxDrawdownUSD float64 `json:"max_drawdown_usd"`
+	SharpeRatio    float64 `json:"sharpe_ratio"` // annualized, assuming risk-free rate = 0
+	SortinoRatio   float64 `json:"sortino_ratio"`
+	CalmarRatio    float64 `json:"calmar_ratio"` // annualized return / max drawdown
 
 	// fees
-	TotalFees float64
+	TotalFees float64 `json:"total_fees"`
+
+	// benchmark-relative
+	ExposurePct float64           `json:"exposure_pct"` // percent of the period with an open position
+	BuyAndHold  *BenchmarkMetrics `json:"buy_and_hold,omitempty"`
+	Benchmark   *BenchmarkMetrics `json:"benchmark,omitempty"`
+	Monthly     []PeriodReturn    `json:"monthly,omitempty"`
+	Yearly      []PeriodReturn    `json:"yearly,omitempty"`
 
 	// timing
-	StartDate time.Time
-	EndDate   time.Time
-	Duration  time.Duration
+	StartDate time.Time     `json:"start_date"`
+	EndDate   time.Time     `json:"end_date"`
+	Duration  time.Duration `json:"duration"`
 }
 
 // ComputeMetrics calculates all performance metrics from a backtest result.
@@ -71,6 +78,15 @@ func ComputeMetrics(result *Result) *Metrics {
 		m.AnnualizedReturn = (math.Pow(result.FinalEquity/result.Config.InitialCapital, 1/years) - 1) * 100
 	}
 
+	// benchmark comparison and return tables
+	m.BuyAndHold = computeBenchmarkMetrics("buy_and_hold", result.EquityCurve, result.BuyAndHold, years)
+	if result.Config.Benchmark != "" {
+		m.Benchmark = computeBenchmarkMetrics(result.Config.Benchmark, result.EquityCurve, result.Benchmark, years)
+	}
+	m.Monthly = periodReturns(result.EquityCurve, result.BuyAndHold, result.Benchmark, "2006-01")
+	m.Yearly = periodReturns(result.EquityCurve, result.BuyAndHold, result.Benchmark, "2006")
+	m.ExposurePct = exposurePct(result.Trades, m.StartDate, m.EndDate)
+
 	if len(result.Trades) == 0 {
 		return m
 	}
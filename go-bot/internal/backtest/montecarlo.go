@@ -0,0 +1,193 @@
+// monte carlo robustness analysis — resamples or shuffles a run's trade sequence
+// to estimate how much of the result is luck of ordering vs. edge.
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// resampling methods
+const (
+	MCBootstrap = "bootstrap" // draw trades with replacement
+	MCShuffle   = "shuffle"   // permute trade order (same trades, different path)
+)
+
+// MonteCarloConfig controls a robustness simulation.
+type MonteCarloConfig struct {
+	Iterations int     // number of simulated paths
+	Method     string  // MCBootstrap or MCShuffle
+	Capital    float64 // starting capital per path (0 = run's initial capital)
+	RuinPct    float64 // loss of starting capital (percent) counted as ruin, e.g. 50
+	Confidence float64 // interval width, e.g. 0.95
+	Seed       int64   // 0 = time-based
+}
+
+// Interval is a percentile summary of a simulated distribution.
+type Interval struct {
+	Lower  float64 `json:"lower"`
+	Median float64 `json:"median"`
+	Upper  float64 `json:"upper"`
+	Mean   float64 `json:"mean"`
+}
+
+// MonteCarloResult holds the distribution of outcomes across simulated paths.
+// Shuffling keeps the same trades, so the final return and trade-level Sharpe
+// are identical on every path; in that mode only the path metrics (drawdown,
+// ruin) are reported and the return fields are nil.
+type MonteCarloResult struct {
+	Method     string  `json:"method"`
+	Iterations int     `json:"iterations"`
+	Trades     int     `json:"trades"`
+	Capital    float64 `json:"capital"`
+	Confidence float64 `json:"confidence"`
+	RuinPct    float64 `json:"ruin_pct"`
+
+	ReturnPct      *Interval `json:"return_pct,omitempty"` // nil for shuffle
+	MaxDrawdownPct Interval  `json:"max_drawdown_pct"`
+	SharpeRatio    *Interval `json:"sharpe_ratio,omitempty"` // nil for shuffle
+
+	RiskOfRuin         float64  `json:"risk_of_ruin"`                   // 0-1, share of paths that hit the ruin level
+	ProbSharpePositive *float64 `json:"prob_sharpe_positive,omitempty"` // 0-1, nil for shuffle
+	ProbLoss           *float64 `json:"prob_loss,omitempty"`            // 0-1, share of paths ending below starting capital, nil for shuffle
+}
+
+// RunMonteCarlo simulates cfg.Iterations alternative trade sequences from a result.
+// Trades are replayed with their USD P&L, so drawdown and ruin depend on the capital.
+func RunMonteCarlo(result *Result, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if len(result.Trades) < 2 {
+		return nil, fmt.Errorf("monte carlo needs at least 2 trades, got %d", len(result.Trades))
+	}
+	if cfg.Iterations <= 0 {
+		return nil, fmt.Errorf("monte carlo iterations must be positive")
+	}
+	if cfg.Method == "" {
+		cfg.Method = MCBootstrap
+	}
+	if cfg.Method != MCBootstrap && cfg.Method != MCShuffle {
+		return nil, fmt.Errorf("unknown monte carlo method: %s (use bootstrap or shuffle)", cfg.Method)
+	}
+	if cfg.Capital <= 0 {
+		cfg.Capital = result.Config.InitialCapital
+	}
+	if cfg.Capital <= 0 {
+		return nil, fmt.Errorf("monte carlo needs positive starting capital")
+	}
+	if cfg.RuinPct <= 0 || cfg.RuinPct > 100 {
+		cfg.RuinPct = 50
+	}
+	if cfg.Confidence <= 0 || cfg.Confidence >= 1 {
+		cfg.Confidence = 0.95
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+
+	years := 0.0
+	if n := len(result.EquityCurve); n > 1 {
+		years = result.EquityCurve[n-1].Time.Sub(result.EquityCurve[0].Time).Hours() / (365.25 * 24)
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	n := len(result.Trades)
+	path := make([]Trade, n)
+	ruinLevel := cfg.Capital * (1 - cfg.RuinPct/100)
+
+	returns := make([]float64, cfg.Iterations)
+	drawdowns := make([]float64, cfg.Iterations)
+	sharpes := make([]float64, cfg.Iterations)
+	var ruined, sharpePos, losses int
+
+	for i := 0; i < cfg.Iterations; i++ {
+		if cfg.Method == MCShuffle {
+			copy(path, result.Trades)
+			rng.Shuffle(n, func(a, b int) { path[a], path[b] = path[b], path[a] })
+		} else {
+			for j := range path {
+				path[j] = result.Trades[rng.Intn(n)]
+			}
+		}
+
+		equity, peak, maxDD := cfg.Capital, cfg.Capital, 0.0
+		hitRuin := false
+		for _, t := range path {
+			equity += t.PnL
+			if equity > peak {
+				peak = equity
+			}
+			if peak > 0 {
+				if dd := (peak - equity) / peak * 100; dd > maxDD {
+					maxDD = dd
+				}
+			}
+			if equity <= ruinLevel {
+				hitRuin = true
+			}
+		}
+
+		returns[i] = (equity - cfg.Capital) / cfg.Capital * 100
+		drawdowns[i] = maxDD
+		sharpes[i] = sharpeRatio(path, years)
+
+		if hitRuin {
+			ruined++
+		}
+		if sharpes[i] > 0 {
+			sharpePos++
+		}
+		if equity < cfg.Capital {
+			losses++
+		}
+	}
+
+	iters := float64(cfg.Iterations)
+	mc := &MonteCarloResult{
+		Method:         cfg.Method,
+		Iterations:     cfg.Iterations,
+		Trades:         n,
+		Capital:        cfg.Capital,
+		Confidence:     cfg.Confidence,
+		RuinPct:        cfg.RuinPct,
+		MaxDrawdownPct: summarize(drawdowns, cfg.Confidence),
+		RiskOfRuin:     float64(ruined) / iters,
+	}
+	if cfg.Method == MCBootstrap {
+		ret, sharpe := summarize(returns, cfg.Confidence), summarize(sharpes, cfg.Confidence)
+		probSharpe, probLoss := float64(sharpePos)/iters, float64(losses)/iters
+		mc.ReturnPct = &ret
+		mc.SharpeRatio = &sharpe
+		mc.ProbSharpePositive = &probSharpe
+		mc.ProbLoss = &probLoss
+	}
+	return mc, nil
+}
+
+// summarize sorts vals in place and returns the two-sided confidence interval.
+func summarize(vals []float64, confidence float64) Interval {
+	sort.Float64s(vals)
+	tail := (1 - confidence) / 2
+	return Interval{
+		Lower:  percentile(vals, tail),
+		Median: percentile(vals, 0.5),
+		Upper:  percentile(vals, 1-tail),
+		Mean:   avg(vals),
+	}
+}
+
+// percentile returns the linearly interpolated q-quantile of sorted vals.
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*frac
+}
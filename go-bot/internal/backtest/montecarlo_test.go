@@ -0,0 +1,122 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func mcResult(pnls ...float64) *Result {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := make([]Trade, len(pnls))
+	for i, p := range pnls {
+		trades[i] = Trade{PnL: p, PnLPercent: p / 100}
+	}
+	return &Result{
+		Config: Config{InitialCapital: 10000},
+		Trades: trades,
+		EquityCurve: []EquityPoint{
+			{Time: base, Equity: 10000},
+			{Time: base.AddDate(1, 0, 0), Equity: 10000},
+		},
+	}
+}
+
+func TestRunMonteCarlo_ShuffleReportsPathMetricsOnly(t *testing.T) {
+	result := mcResult(500, -200, 300, -100, 400, -300)
+	mc, err := RunMonteCarlo(result, MonteCarloConfig{Iterations: 500, Method: MCShuffle, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// shuffling reorders the same trades, so return and sharpe are identical on every path
+	if mc.ReturnPct != nil || mc.SharpeRatio != nil || mc.ProbSharpePositive != nil || mc.ProbLoss != nil {
+		t.Errorf("shuffle should only report path metrics, got %+v", mc)
+	}
+	if mc.MaxDrawdownPct.Lower > mc.MaxDrawdownPct.Upper {
+		t.Error("drawdown interval inverted")
+	}
+	if mc.MaxDrawdownPct.Upper <= 0 {
+		t.Error("expected some paths with drawdown")
+	}
+}
+
+func TestRunMonteCarlo_BootstrapSpread(t *testing.T) {
+	result := mcResult(500, -200, 300, -100, 400, -300, 250, -150)
+	mc, err := RunMonteCarlo(result, MonteCarloConfig{Iterations: 2000, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mc.Method != MCBootstrap {
+		t.Errorf("method = %s, want bootstrap", mc.Method)
+	}
+	if mc.ReturnPct.Lower >= mc.ReturnPct.Upper {
+		t.Errorf("expected a return spread, got [%f, %f]", mc.ReturnPct.Lower, mc.ReturnPct.Upper)
+	}
+	if mc.ReturnPct.Lower > mc.ReturnPct.Median || mc.ReturnPct.Median > mc.ReturnPct.Upper {
+		t.Error("median should be inside the interval")
+	}
+	if p := *mc.ProbSharpePositive; p <= 0.5 || p > 1 {
+		t.Errorf("P(sharpe>0) = %f, expected > 0.5 for a profitable edge", p)
+	}
+	if mc.Capital != 10000 || mc.Confidence != 0.95 || mc.RuinPct != 50 {
+		t.Errorf("defaults not applied: capital=%f confidence=%f ruin=%f", mc.Capital, mc.Confidence, mc.RuinPct)
+	}
+}
+
+func TestRunMonteCarlo_RiskOfRuinDependsOnCapital(t *testing.T) {
+	result := mcResult(300, -400, 200, -500, 100, -300)
+
+	small, err := RunMonteCarlo(result, MonteCarloConfig{Iterations: 1000, Capital: 1000, RuinPct: 50, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := RunMonteCarlo(result, MonteCarloConfig{Iterations: 1000, Capital: 100000, RuinPct: 50, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if small.RiskOfRuin <= large.RiskOfRuin {
+		t.Errorf("risk of ruin small=%f large=%f, expected small capital to be riskier", small.RiskOfRuin, large.RiskOfRuin)
+	}
+	if large.RiskOfRuin != 0 {
+		t.Errorf("large capital risk of ruin = %f, want 0", large.RiskOfRuin)
+	}
+}
+
+func TestRunMonteCarlo_Deterministic(t *testing.T) {
+	result := mcResult(500, -200, 300, -100)
+	a, _ := RunMonteCarlo(result, MonteCarloConfig{Iterations: 200, Seed: 99})
+	b, _ := RunMonteCarlo(result, MonteCarloConfig{Iterations: 200, Seed: 99})
+	if *a.ReturnPct != *b.ReturnPct || a.MaxDrawdownPct != b.MaxDrawdownPct {
+		t.Error("same seed should produce the same distribution")
+	}
+}
+
+func TestRunMonteCarlo_Errors(t *testing.T) {
+	if _, err := RunMonteCarlo(mcResult(100), MonteCarloConfig{Iterations: 10}); err == nil {
+		t.Error("expected error with fewer than 2 trades")
+	}
+	if _, err := RunMonteCarlo(mcResult(100, -50), MonteCarloConfig{Iterations: 0}); err == nil {
+		t.Error("expected error with zero iterations")
+	}
+	if _, err := RunMonteCarlo(mcResult(100, -50), MonteCarloConfig{Iterations: 10, Method: "jackknife"}); err == nil {
+		t.Error("expected error for unknown method")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	vals := []float64{1, 2, 3, 4, 5}
+	cases := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 1}, {0.5, 3}, {1, 5}, {0.25, 2}, {0.1, 1.4},
+	}
+	for _, c := range cases {
+		if got := percentile(vals, c.q); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("percentile(%v) = %f, want %f", c.q, got, c.want)
+		}
+	}
+}
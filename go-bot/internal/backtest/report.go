@@ -0,0 +1,428 @@
+// backtest report export — serializes a run (config, metrics, trades, equity
+// curve) as JSON, CSV, or a self-contained HTML page with inline SVG charts.
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// supported report output formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
+)
+
+// maximum number of points drawn per chart (equity curves are downsampled above this)
+const maxChartPoints = 1000
+
+// Report is the persisted, self-describing record of a single backtest run.
+type Report struct {
+	ID          int           `json:"id,omitempty"`
+	Strategy    string        `json:"strategy"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Config      Config        `json:"config"`
+	Metrics     *Metrics      `json:"metrics"`
+	Trades      []Trade       `json:"trades"`
+	EquityCurve []EquityPoint `json:"equity_curve"`
+	FinalEquity float64       `json:"final_equity"`
+	Candles     int           `json:"candles"`
+
+	AmbiguousBars    int `json:"ambiguous_bars,omitempty"`
+	IntrabarResolved int `json:"intrabar_resolved,omitempty"`
+
+	MonteCarlo *MonteCarloResult `json:"monte_carlo,omitempty"`
+
+	BuyAndHold []EquityPoint `json:"buy_and_hold,omitempty"`
+	Benchmark  []EquityPoint `json:"benchmark,omitempty"`
+}
+
+// NewReport bundles a backtest result and its metrics into a report.
+func NewReport(result *Result, m *Metrics) *Report {
+	return &Report{
+		Strategy:    result.Strategy,
+		CreatedAt:   time.Now().UTC(),
+		Config:      result.Config,
+		Metrics:     m,
+		Trades:      result.Trades,
+		EquityCurve: result.EquityCurve,
+		FinalEquity: result.FinalEquity,
+		Candles:     result.TotalCandles,
+
+		AmbiguousBars:    result.AmbiguousBars,
+		IntrabarResolved: result.IntrabarResolved,
+
+		BuyAndHold: result.BuyAndHold,
+		Benchmark:  result.Benchmark,
+	}
+}
+
+// ValidFormat reports whether the given output format is supported.
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatJSON, FormatCSV, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// Write renders the report in the requested format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatCSV:
+		return r.WriteCSV(w)
+	case FormatHTML:
+		return r.WriteHTML(w)
+	default:
+		return fmt.Errorf("unknown report format: %s (use html, json, or csv)", format)
+	}
+}
+
+// WriteJSON writes the full report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes the trade list as CSV (one row per round-trip trade).
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"entry_time", "exit_time", "side", "entry_price", "exit_price", "quantity",
+		"entry_fee", "exit_fee", "pnl", "pnl_percent", "exit_reason", "bars",
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+	for _, t := range r.Trades {
+		row := []string{
+			t.EntryTime.UTC().Format(time.RFC3339),
+			t.ExitTime.UTC().Format(time.RFC3339),
+			string(t.Side),
+			formatFloat(t.EntryPrice),
+			formatFloat(t.ExitPrice),
+			formatFloat(t.Quantity),
+			formatFloat(t.EntryFee),
+			formatFloat(t.ExitFee),
+			formatFloat(t.PnL),
+			formatFloat(t.PnLPercent),
+			t.ExitReason,
+			strconv.Itoa(t.Bars),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("write csv row: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteHTML writes a self-contained HTML page with an equity curve,
+// a drawdown chart, summary metrics, and the full trade table.
+func (r *Report) WriteHTML(w io.Writer) error {
+	m := r.Metrics
+	if m == nil {
+		m = &Metrics{}
+	}
+
+	equity := downsample(r.EquityCurve, maxChartPoints)
+	values := make([]float64, len(equity))
+	drawdowns := make([]float64, len(equity))
+	peak := 0.0
+	for i, ep := range equity {
+		values[i] = ep.Equity
+		if ep.Equity > peak {
+			peak = ep.Equity
+		}
+		if peak > 0 {
+			drawdowns[i] = -(peak - ep.Equity) / peak * 100
+		}
+	}
+
+	data := htmlReportData{
+		Title:    fmt.Sprintf("%s %s — %s", r.Config.Symbol, r.Config.Interval, r.Strategy),
+		Report:   r,
+		Metrics:  m,
+		Equity:   buildChart(values, 900, 260),
+		Drawdown: buildChart(drawdowns, 900, 160),
+	}
+
+	// benchmark overlays share the equity chart's scale
+	hodl := equityValues(downsample(r.BuyAndHold, maxChartPoints))
+	bench := equityValues(downsample(r.Benchmark, maxChartPoints))
+	if len(hodl) != len(values) {
+		hodl = nil
+	}
+	if len(bench) != len(values) {
+		bench = nil
+	}
+	if hodl != nil || bench != nil {
+		lo, hi := seriesRange(values, hodl, bench)
+		data.Equity = scaleChart(values, lo, hi, 900, 260)
+		if hodl != nil {
+			data.BuyAndHold = scaleChart(hodl, lo, hi, 900, 260)
+		}
+		if bench != nil {
+			data.Benchmark = scaleChart(bench, lo, hi, 900, 260)
+		}
+	}
+	for _, bm := range []*BenchmarkMetrics{m.BuyAndHold, m.Benchmark} {
+		if bm != nil {
+			data.Benchmarks = append(data.Benchmarks, bm)
+		}
+	}
+	if len(equity) > 0 {
+		data.From = equity[0].Time.UTC().Format("2006-01-02")
+		data.To = equity[len(equity)-1].Time.UTC().Format("2006-01-02")
+	}
+
+	return htmlReportTmpl.Execute(w, data)
+}
+
+// chart holds precomputed SVG geometry for a single line series.
+type chart struct {
+	Width  int
+	Height int
+	Points string
+	Min    float64
+	Max    float64
+}
+
+type htmlReportData struct {
+	Title    string
+	From     string
+	To       string
+	Report   *Report
+	Metrics  *Metrics
+	Equity   chart
+	Drawdown chart
+
+	BuyAndHold chart // overlays on the equity chart, empty when absent
+	Benchmark  chart
+	Benchmarks []*BenchmarkMetrics
+}
+
+// buildChart scales a series into an SVG polyline points attribute.
+func buildChart(values []float64, width, height int) chart {
+	lo, hi := seriesRange(values)
+	return scaleChart(values, lo, hi, width, height)
+}
+
+// scaleChart maps a series onto a fixed [lo, hi] vertical range, so several
+// series can be drawn on the same axes.
+func scaleChart(values []float64, lo, hi float64, width, height int) chart {
+	c := chart{Width: width, Height: height, Min: lo, Max: hi}
+	if len(values) == 0 {
+		return c
+	}
+
+	span := hi - lo
+	if span == 0 {
+		span = 1
+	}
+
+	var b strings.Builder
+	step := 0.0
+	if len(values) > 1 {
+		step = float64(width) / float64(len(values)-1)
+	}
+	for i, v := range values {
+		x := float64(i) * step
+		y := float64(height) - (v-lo)/span*float64(height)
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", x, y)
+	}
+	c.Points = b.String()
+	return c
+}
+
+// seriesRange returns the min and max across all series.
+func seriesRange(series ...[]float64) (float64, float64) {
+	var lo, hi float64
+	first := true
+	for _, values := range series {
+		for _, v := range values {
+			if first || v < lo {
+				lo = v
+			}
+			if first || v > hi {
+				hi = v
+			}
+			first = false
+		}
+	}
+	return lo, hi
+}
+
+func equityValues(points []EquityPoint) []float64 {
+	out := make([]float64, len(points))
+	for i, p := range points {
+		out[i] = p.Equity
+	}
+	return out
+}
+
+// downsample keeps at most max points, always including the first and last.
+func downsample(points []EquityPoint, max int) []EquityPoint {
+	if len(points) <= max || max < 2 {
+		return points
+	}
+	out := make([]EquityPoint, 0, max)
+	stride := float64(len(points)-1) / float64(max-1)
+	for i := 0; i < max; i++ {
+		out = append(out, points[int(float64(i)*stride+0.5)])
+	}
+	return out
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+var htmlReportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":  func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"pct":    func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	"num":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"price":  func(v float64) string { return fmt.Sprintf("%.4f", v) },
+	"mul100": func(v float64) float64 { return v * 100 },
+	"sub":    func(a, b float64) float64 { return a - b },
+	"ts":     func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+	"optPct": func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f%%", *v)
+	},
+	"pnlClass": func(v float64) string {
+		if v > 0 {
+			return "win"
+		}
+		if v < 0 {
+			return "loss"
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Backtest: {{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 24px; color: #1f2937; background: #f9fafb; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+h2 { font-size: 16px; margin-top: 28px; }
+.sub { color: #6b7280; font-size: 13px; }
+.grid { display: grid; grid-template-columns: repeat(4, minmax(160px, 1fr)); gap: 8px; }
+.card { background: #fff; border: 1px solid #e5e7eb; border-radius: 6px; padding: 8px 12px; }
+.card .k { color: #6b7280; font-size: 12px; }
+.card .v { font-size: 16px; font-weight: 600; }
+svg { background: #fff; border: 1px solid #e5e7eb; border-radius: 6px; }
+table { border-collapse: collapse; width: 100%; background: #fff; font-size: 12px; }
+th, td { border: 1px solid #e5e7eb; padding: 4px 8px; text-align: right; }
+th { background: #f3f4f6; }
+td.l { text-align: left; }
+.win { color: #059669; }
+.loss { color: #dc2626; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="sub">{{.From}} → {{.To}} · {{.Report.Candles}} candles · generated {{ts .Report.CreatedAt}} UTC{{if .Report.ID}} · run #{{.Report.ID}}{{end}}</div>
+{{if .Report.AmbiguousBars}}<div class="sub">{{.Report.AmbiguousBars}} bars hit stop and target · {{.Report.IntrabarResolved}} resolved from {{.Report.Config.IntrabarInterval}} candles, rest assumed stop first</div>{{end}}
+
+<h2>Summary</h2>
+<div class="grid">
+<div class="card"><div class="k">Initial Capital</div><div class="v">{{money .Report.Config.InitialCapital}}</div></div>
+<div class="card"><div class="k">Final Equity</div><div class="v">{{money .Report.FinalEquity}}</div></div>
+<div class="card"><div class="k">Total Return</div><div class="v {{pnlClass .Metrics.TotalReturn}}">{{money .Metrics.TotalReturn}} ({{pct .Metrics.TotalReturnPct}})</div></div>
+<div class="card"><div class="k">Annualized</div><div class="v">{{pct .Metrics.AnnualizedReturn}}</div></div>
+<div class="card"><div class="k">Trades</div><div class="v">{{.Metrics.TotalTrades}} ({{.Metrics.WinningTrades}}W / {{.Metrics.LosingTrades}}L)</div></div>
+<div class="card"><div class="k">Win Rate</div><div class="v">{{pct .Metrics.WinRate}}</div></div>
+<div class="card"><div class="k">Profit Factor</div><div class="v">{{num .Metrics.ProfitFactor}}</div></div>
+<div class="card"><div class="k">Total Fees</div><div class="v">{{money .Metrics.TotalFees}}</div></div>
+<div class="card"><div class="k">Max Drawdown</div><div class="v loss">{{pct .Metrics.MaxDrawdown}} ({{money .Metrics.MaxDrawdownUSD}})</div></div>
+<div class="card"><div class="k">Sharpe</div><div class="v">{{num .Metrics.SharpeRatio}}</div></div>
+<div class="card"><div class="k">Sortino</div><div class="v">{{num .Metrics.SortinoRatio}}</div></div>
+<div class="card"><div class="k">Calmar</div><div class="v">{{num .Metrics.CalmarRatio}}</div></div>
+<div class="card"><div class="k">Exposure</div><div class="v">{{pct .Metrics.ExposurePct}}</div></div>
+{{with .Metrics.BuyAndHold}}<div class="card"><div class="k">vs Buy &amp; Hold</div><div class="v {{pnlClass .ExcessReturnPct}}">{{pct .ExcessReturnPct}}</div></div>{{end}}
+</div>
+
+{{if .Benchmarks}}
+<h2>Benchmarks</h2>
+<table>
+<tr><th class="l">Benchmark</th><th>Return</th><th>Annualized</th><th>Max DD</th><th>Excess</th><th>Alpha</th><th>Beta</th><th>Corr</th><th>Info Ratio</th><th>Up Capture</th><th>Down Capture</th></tr>
+{{range .Benchmarks}}<tr>
+<td class="l">{{.Name}}</td><td>{{pct .TotalReturnPct}}</td><td>{{pct .AnnualizedReturn}}</td><td>{{pct .MaxDrawdown}}</td>
+<td class="{{pnlClass .ExcessReturnPct}}">{{pct .ExcessReturnPct}}</td><td class="{{pnlClass .Alpha}}">{{pct .Alpha}}</td><td>{{num .Beta}}</td><td>{{num .Correlation}}</td>
+<td>{{num .InformationRatio}}</td><td>{{pct .UpCapture}}</td><td>{{pct .DownCapture}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+
+{{with .Report.MonteCarlo}}
+<h2>Monte Carlo ({{.Iterations}} × {{.Method}}, {{pct (mul100 .Confidence)}} CI)</h2>
+<table>
+<tr><th class="l">Metric</th><th>Lower</th><th>Median</th><th>Upper</th><th>Mean</th></tr>
+{{with .ReturnPct}}<tr><td class="l">Return</td><td>{{pct .Lower}}</td><td>{{pct .Median}}</td><td>{{pct .Upper}}</td><td>{{pct .Mean}}</td></tr>{{end}}
+<tr><td class="l">Max Drawdown</td><td>{{pct .MaxDrawdownPct.Lower}}</td><td>{{pct .MaxDrawdownPct.Median}}</td><td>{{pct .MaxDrawdownPct.Upper}}</td><td>{{pct .MaxDrawdownPct.Mean}}</td></tr>
+{{with .SharpeRatio}}<tr><td class="l">Sharpe</td><td>{{num .Lower}}</td><td>{{num .Median}}</td><td>{{num .Upper}}</td><td>{{num .Mean}}</td></tr>{{end}}
+</table>
+<div class="sub">{{with .ProbSharpePositive}}P(Sharpe &gt; 0): {{pct (mul100 .)}} · {{end}}{{with .ProbLoss}}P(loss): {{pct (mul100 .)}} · {{end}}risk of ruin ({{pct .RuinPct}} loss of {{money .Capital}}): {{pct (mul100 .RiskOfRuin)}}</div>
+{{end}}
+
+<h2>Equity Curve</h2>
+<div class="sub">min {{money .Equity.Min}} · max {{money .Equity.Max}}</div>
+<svg width="{{.Equity.Width}}" height="{{.Equity.Height}}" viewBox="0 0 {{.Equity.Width}} {{.Equity.Height}}" preserveAspectRatio="none">
+{{if .BuyAndHold.Points}}<polyline fill="none" stroke="#9ca3af" stroke-width="1" points="{{.BuyAndHold.Points}}"/>{{end}}
+{{if .Benchmark.Points}}<polyline fill="none" stroke="#d97706" stroke-width="1" points="{{.Benchmark.Points}}"/>{{end}}
+<polyline fill="none" stroke="#2563eb" stroke-width="1.5" points="{{.Equity.Points}}"/>
+</svg>
+{{if .BuyAndHold.Points}}<div class="sub"><span style="color:#2563eb">■</span> strategy · <span style="color:#9ca3af">■</span> buy &amp; hold{{if .Benchmark.Points}} · <span style="color:#d97706">■</span> {{.Report.Config.Benchmark}}{{end}}</div>{{end}}
+
+<h2>Drawdown</h2>
+<div class="sub">worst {{pct .Drawdown.Min}}</div>
+<svg width="{{.Drawdown.Width}}" height="{{.Drawdown.Height}}" viewBox="0 0 {{.Drawdown.Width}} {{.Drawdown.Height}}" preserveAspectRatio="none">
+<polyline fill="none" stroke="#dc2626" stroke-width="1.5" points="{{.Drawdown.Points}}"/>
+</svg>
+
+{{if .Metrics.Yearly}}
+<h2>Returns by Year</h2>
+{{template "periods" .Metrics.Yearly}}{{end}}
+{{if .Metrics.Monthly}}
+<h2>Returns by Month</h2>
+{{template "periods" .Metrics.Monthly}}{{end}}
+
+<h2>Trades</h2>
+<table>
+<tr><th>#</th><th>Side</th><th>Entry</th><th>Exit</th><th>Entry Price</th><th>Exit Price</th><th>Qty</th><th>P&amp;L</th><th>P&amp;L %</th><th>Bars</th><th>Exit Reason</th></tr>
+{{range $i, $t := .Report.Trades}}<tr>
+<td>{{$i}}</td><td class="l">{{$t.Side}}</td><td class="l">{{ts $t.EntryTime}}</td><td class="l">{{ts $t.ExitTime}}</td>
+<td>{{price $t.EntryPrice}}</td><td>{{price $t.ExitPrice}}</td><td>{{price $t.Quantity}}</td>
+<td class="{{pnlClass $t.PnL}}">{{money $t.PnL}}</td><td class="{{pnlClass $t.PnL}}">{{pct $t.PnLPercent}}</td>
+<td>{{$t.Bars}}</td><td class="l">{{$t.ExitReason}}</td>
+</tr>
+{{else}}<tr><td colspan="11" class="l">no trades</td></tr>
+{{end}}</table>
+</body>
+</html>
+{{define "periods"}}{{$bench := (index . 0).BenchmarkPct}}<table>
+<tr><th class="l">Period</th><th>Strategy</th><th>Buy &amp; Hold</th>{{if $bench}}<th>Benchmark</th>{{end}}<th>Difference</th></tr>
+{{range .}}<tr>
+<td class="l">{{.Period}}</td><td class="{{pnlClass .ReturnPct}}">{{pct .ReturnPct}}</td><td class="{{pnlClass .BuyAndHoldPct}}">{{pct .BuyAndHoldPct}}</td>
+{{if $bench}}<td>{{optPct .BenchmarkPct}}</td>{{end}}<td class="{{pnlClass (sub .ReturnPct .BuyAndHoldPct)}}">{{pct (sub .ReturnPct .BuyAndHoldPct)}}</td>
+</tr>
+{{end}}</table>{{end}}
+`))
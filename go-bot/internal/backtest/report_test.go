@@ -0,0 +1,164 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReport() *Report {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		{EntryTime: base, ExitTime: base.Add(4 * time.Hour), Side: ActionBuy, EntryPrice: 100, ExitPrice: 110, Quantity: 1, PnL: 9.8, PnLPercent: 9.8, ExitReason: "take_profit", Bars: 4},
+		{EntryTime: base.Add(5 * time.Hour), ExitTime: base.Add(8 * time.Hour), Side: ActionSell, EntryPrice: 110, ExitPrice: 115, Quantity: 1, PnL: -5.2, PnLPercent: -4.7, ExitReason: "stop_loss", Bars: 3},
+	}
+	var curve []EquityPoint
+	for i := 0; i < 10; i++ {
+		curve = append(curve, EquityPoint{Time: base.Add(time.Duration(i) * time.Hour), Equity: 10000 + float64(i%4)*10})
+	}
+	result := &Result{
+		Config:       Config{Symbol: "BTC/USDT", Interval: "1h", InitialCapital: 10000, FeeRate: 0.001, MaxOpenTrades: 1},
+		Strategy:     "sma_crossover",
+		Trades:       trades,
+		EquityCurve:  curve,
+		FinalEquity:  10004.6,
+		TotalCandles: 10,
+	}
+	return NewReport(result, ComputeMetrics(result))
+}
+
+func TestReport_WriteJSON_RoundTrip(t *testing.T) {
+	r := sampleReport()
+	var buf bytes.Buffer
+	if err := r.Write(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if decoded.Strategy != "sma_crossover" {
+		t.Errorf("strategy = %q, want sma_crossover", decoded.Strategy)
+	}
+	if decoded.Config.Symbol != "BTC/USDT" {
+		t.Errorf("symbol = %q, want BTC/USDT", decoded.Config.Symbol)
+	}
+	if len(decoded.Trades) != 2 {
+		t.Errorf("trades = %d, want 2", len(decoded.Trades))
+	}
+	if decoded.Metrics == nil || decoded.Metrics.TotalTrades != 2 {
+		t.Error("expected metrics with 2 trades")
+	}
+	if !strings.Contains(buf.String(), `"total_return_pct"`) {
+		t.Error("expected snake_case metric keys")
+	}
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	r := sampleReport()
+	var buf bytes.Buffer
+	if err := r.Write(&buf, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want 3 (header + 2 trades)", len(rows))
+	}
+	if rows[0][0] != "entry_time" {
+		t.Errorf("header[0] = %q, want entry_time", rows[0][0])
+	}
+	if rows[2][10] != "stop_loss" {
+		t.Errorf("exit reason = %q, want stop_loss", rows[2][10])
+	}
+}
+
+func TestReport_WriteHTML(t *testing.T) {
+	r := sampleReport()
+	var buf bytes.Buffer
+	if err := r.Write(&buf, FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "BTC/USDT", "sma_crossover", "<polyline", "take_profit"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+}
+
+func TestReport_WriteHTML_NoTrades(t *testing.T) {
+	r := NewReport(&Result{Config: Config{Symbol: "ETH/USDT"}}, nil)
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "no trades") {
+		t.Error("expected empty trade table placeholder")
+	}
+}
+
+func TestReport_UnknownFormat(t *testing.T) {
+	r := sampleReport()
+	if err := r.Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if ValidFormat("xml") {
+		t.Error("xml should not be a valid format")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	points := make([]EquityPoint, 5000)
+	for i := range points {
+		points[i].Equity = float64(i)
+	}
+
+	out := downsample(points, 1000)
+	if len(out) != 1000 {
+		t.Fatalf("len = %d, want 1000", len(out))
+	}
+	if out[0].Equity != 0 || out[len(out)-1].Equity != 4999 {
+		t.Errorf("expected first and last points preserved, got %v..%v", out[0].Equity, out[len(out)-1].Equity)
+	}
+
+	short := downsample(points[:10], 1000)
+	if len(short) != 10 {
+		t.Errorf("short series should be unchanged, got %d", len(short))
+	}
+}
+
+func TestReport_WriteHTML_MonteCarlo(t *testing.T) {
+	r := sampleReport()
+	probSharpe := 0.72
+	r.MonteCarlo = &MonteCarloResult{Method: MCBootstrap, Iterations: 1000, Confidence: 0.95, ProbSharpePositive: &probSharpe}
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Monte Carlo (1000") {
+		t.Error("expected monte carlo section")
+	}
+	if !strings.Contains(out, "72.00%") {
+		t.Error("expected P(Sharpe > 0) rendered as a percentage")
+	}
+
+	r.MonteCarlo = &MonteCarloResult{Method: MCShuffle, Iterations: 1000, Confidence: 0.95}
+	buf.Reset()
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "P(Sharpe") || strings.Contains(out, `<td class="l">Return</td>`) {
+		t.Error("shuffle should not render return or sharpe rows")
+	}
+}
@@ -0,0 +1,327 @@
+// additional built-in strategies: bollinger breakout, macd trend-follow,
+// donchian/turtle breakout with atr stops, and a regime-switching meta-strategy.
+package backtest
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/trading-bot/go-bot/internal/exchange"
+	"github.com/trading-bot/go-bot/internal/regime"
+)
+
+// BollingerBreakout enters when the close breaks outside the bands.
+type BollingerBreakout struct {
+	Period      int
+	StdDev      float64 // band width in standard deviations
+	StopPct     float64
+	TargetPct   float64
+	PositionPct float64
+}
+
+func NewBollingerBreakout(period int, stdDev, stopPct, targetPct, positionPct float64) (*BollingerBreakout, error) {
+	if period < 2 {
+		return nil, fmt.Errorf("bollinger period must be at least 2, got %d", period)
+	}
+	if stdDev <= 0 {
+		return nil, fmt.Errorf("bollinger band width must be positive, got %g", stdDev)
+	}
+	return &BollingerBreakout{
+		Period:      period,
+		StdDev:      stdDev,
+		StopPct:     stopPct,
+		TargetPct:   targetPct,
+		PositionPct: positionPct,
+	}, nil
+}
+
+func (b *BollingerBreakout) Name() string {
+	return "bollinger-breakout"
+}
+
+func (b *BollingerBreakout) OnCandle(candles []exchange.Candle, idx int) *Signal {
+	if idx < b.Period {
+		return nil
+	}
+
+	upper, lower := bollinger(candles, idx, b.Period, b.StdDev)
+	prevUpper, prevLower := bollinger(candles, idx-1, b.Period, b.StdDev)
+	price := candles[idx].Close
+	prev := candles[idx-1].Close
+
+	// only the bar that breaks out, not every bar outside the band
+	if prev <= prevUpper && price > upper {
+		return &Signal{
+			Action:     ActionBuy,
+			Entry:      price,
+			StopLoss:   price * (1 - b.StopPct),
+			TakeProfit: price * (1 + b.TargetPct),
+			Size:       b.PositionPct,
+			Reason:     "Bollinger upper band breakout",
+		}
+	}
+
+	if prev >= prevLower && price < lower {
+		return &Signal{
+			Action:     ActionSell,
+			Entry:      price,
+			StopLoss:   price * (1 + b.StopPct),
+			TakeProfit: price * (1 - b.TargetPct),
+			Size:       b.PositionPct,
+			Reason:     "Bollinger lower band breakdown",
+		}
+	}
+
+	return nil
+}
+
+// MACDTrend follows MACD/signal line crossovers.
+type MACDTrend struct {
+	FastPeriod   int
+	SlowPeriod   int
+	SignalPeriod int
+	StopPct      float64
+	TargetPct    float64
+	PositionPct  float64
+}
+
+func NewMACDTrend(fast, slow, signal int, stopPct, targetPct, positionPct float64) (*MACDTrend, error) {
+	if fast < 1 || signal < 1 {
+		return nil, fmt.Errorf("macd periods must be positive, got fast %d signal %d", fast, signal)
+	}
+	if fast >= slow {
+		return nil, fmt.Errorf("macd fast period %d must be below the slow period %d", fast, slow)
+	}
+	return &MACDTrend{
+		FastPeriod:   fast,
+		SlowPeriod:   slow,
+		SignalPeriod: signal,
+		StopPct:      stopPct,
+		TargetPct:    targetPct,
+		PositionPct:  positionPct,
+	}, nil
+}
+
+func (m *MACDTrend) Name() string {
+	return "macd-trend"
+}
+
+func (m *MACDTrend) OnCandle(candles []exchange.Candle, idx int) *Signal {
+	if idx < m.SlowPeriod+m.SignalPeriod {
+		return nil
+	}
+
+	hist, prevHist := macdHistogram(candles, idx, m.FastPeriod, m.SlowPeriod, m.SignalPeriod)
+	price := candles[idx].Close
+
+	// histogram crossing zero = macd line crossing the signal line
+	if prevHist <= 0 && hist > 0 {
+		return &Signal{
+			Action:     ActionBuy,
+			Entry:      price,
+			StopLoss:   price * (1 - m.StopPct),
+			TakeProfit: price * (1 + m.TargetPct),
+			Size:       m.PositionPct,
+			Reason:     "MACD bullish cross",
+		}
+	}
+
+	if prevHist >= 0 && hist < 0 {
+		return &Signal{
+			Action:     ActionSell,
+			Entry:      price,
+			StopLoss:   price * (1 + m.StopPct),
+			TakeProfit: price * (1 - m.TargetPct),
+			Size:       m.PositionPct,
+			Reason:     "MACD bearish cross",
+		}
+	}
+
+	return nil
+}
+
+// DonchianBreakout is a turtle-style channel breakout with ATR-based stops and targets.
+type DonchianBreakout struct {
+	Period      int     // channel lookback (e.g. 20)
+	ATRPeriod   int     // e.g. 20
+	StopATR     float64 // stop distance in ATRs (turtle: 2)
+	TargetATR   float64 // target distance in ATRs
+	PositionPct float64
+}
+
+func NewDonchianBreakout(period, atrPeriod int, stopATR, targetATR, positionPct float64) (*DonchianBreakout, error) {
+	if period < 1 || atrPeriod < 1 {
+		return nil, fmt.Errorf("donchian periods must be positive, got channel %d atr %d", period, atrPeriod)
+	}
+	if stopATR <= 0 || targetATR <= 0 {
+		return nil, fmt.Errorf("donchian stop and target must be positive ATR multiples, got %g and %g", stopATR, targetATR)
+	}
+	return &DonchianBreakout{
+		Period:      period,
+		ATRPeriod:   atrPeriod,
+		StopATR:     stopATR,
+		TargetATR:   targetATR,
+		PositionPct: positionPct,
+	}, nil
+}
+
+func (d *DonchianBreakout) Name() string {
+	return "donchian-breakout"
+}
+
+func (d *DonchianBreakout) OnCandle(candles []exchange.Candle, idx int) *Signal {
+	if idx < d.Period || idx < d.ATRPeriod {
+		return nil
+	}
+
+	// channel from the prior bars so the current bar can break it
+	high, low := donchian(candles, idx-1, d.Period)
+	n := atr(candles, idx, d.ATRPeriod)
+	if n == 0 {
+		return nil
+	}
+	price := candles[idx].Close
+
+	if price > high {
+		return &Signal{
+			Action:     ActionBuy,
+			Entry:      price,
+			StopLoss:   price - d.StopATR*n,
+			TakeProfit: price + d.TargetATR*n,
+			Size:       d.PositionPct,
+			Reason:     fmt.Sprintf("Donchian %d-bar high breakout", d.Period),
+		}
+	}
+
+	if price < low {
+		return &Signal{
+			Action:     ActionSell,
+			Entry:      price,
+			StopLoss:   price + d.StopATR*n,
+			TakeProfit: price - d.TargetATR*n,
+			Size:       d.PositionPct,
+			Reason:     fmt.Sprintf("Donchian %d-bar low breakdown", d.Period),
+		}
+	}
+
+	return nil
+}
+
+// RegimeSwitch delegates to a trend-following strategy in trending markets and a
+// mean-reversion strategy in ranging markets; volatile and quiet regimes stay flat.
+type RegimeSwitch struct {
+	Trend    Strategy
+	Range    Strategy
+	Lookback int // candles passed to regime detection
+}
+
+// regime detection needs this many candles
+const minRegimeLookback = 28
+
+func NewRegimeSwitch(trend, ranging Strategy, lookback int) (*RegimeSwitch, error) {
+	if trend == nil || ranging == nil {
+		return nil, fmt.Errorf("regime-switch needs a trend and a range strategy")
+	}
+	if lookback < minRegimeLookback {
+		return nil, fmt.Errorf("regime lookback must be at least %d, got %d", minRegimeLookback, lookback)
+	}
+	return &RegimeSwitch{Trend: trend, Range: ranging, Lookback: lookback}, nil
+}
+
+func (r *RegimeSwitch) Name() string {
+	return "regime-switch"
+}
+
+func (r *RegimeSwitch) OnCandle(candles []exchange.Candle, idx int) *Signal {
+	if idx+1 < r.Lookback {
+		return nil
+	}
+
+	window := candles[idx+1-r.Lookback : idx+1]
+	rc := make([]regime.Candle, len(window))
+	for i, c := range window {
+		rc[i] = regime.Candle{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume}
+	}
+	det := regime.Detect(rc, candles[idx].Close)
+
+	var sig *Signal
+	switch det.Regime {
+	case regime.RegimeTrending:
+		sig = r.Trend.OnCandle(candles, idx)
+	case regime.RegimeRanging:
+		sig = r.Range.OnCandle(candles, idx)
+	}
+	if sig != nil {
+		sig.Reason = fmt.Sprintf("[%s] %s", det.Regime, sig.Reason)
+	}
+	return sig
+}
+
+// --- indicator helpers ---
+
+// bollinger returns the upper and lower bands at endIdx.
+func bollinger(candles []exchange.Candle, endIdx, period int, width float64) (float64, float64) {
+	mid := sma(candles, endIdx, period)
+	var sq float64
+	for i := endIdx - period + 1; i <= endIdx; i++ {
+		d := candles[i].Close - mid
+		sq += d * d
+	}
+	sd := math.Sqrt(sq / float64(period))
+	return mid + width*sd, mid - width*sd
+}
+
+// macdHistogram returns the MACD histogram at endIdx and endIdx-1. EMAs are
+// seeded a few slow periods back rather than from the first candle, which keeps
+// each bar O(period) and is indistinguishable once the seed has decayed.
+func macdHistogram(candles []exchange.Candle, endIdx, fast, slow, signal int) (float64, float64) {
+	start := endIdx - 4*slow - signal
+	if start < 0 {
+		start = 0
+	}
+
+	fastK := 2 / float64(fast+1)
+	slowK := 2 / float64(slow+1)
+	sigK := 2 / float64(signal+1)
+
+	fastEMA := candles[start].Close
+	slowEMA := candles[start].Close
+	var sigEMA, hist, prevHist float64
+	for i := start; i <= endIdx; i++ {
+		c := candles[i].Close
+		fastEMA += fastK * (c - fastEMA)
+		slowEMA += slowK * (c - slowEMA)
+		line := fastEMA - slowEMA
+		if i == start {
+			sigEMA = line
+		}
+		sigEMA += sigK * (line - sigEMA)
+		prevHist = hist
+		hist = line - sigEMA
+	}
+	return hist, prevHist
+}
+
+// donchian returns the highest high and lowest low over the period ending at endIdx.
+func donchian(candles []exchange.Candle, endIdx, period int) (float64, float64) {
+	high, low := candles[endIdx].High, candles[endIdx].Low
+	for i := endIdx - period + 1; i <= endIdx; i++ {
+		high = math.Max(high, candles[i].High)
+		low = math.Min(low, candles[i].Low)
+	}
+	return high, low
+}
+
+// atr is the simple average true range over the period ending at endIdx.
+func atr(candles []exchange.Candle, endIdx, period int) float64 {
+	if endIdx < period {
+		return 0
+	}
+	var sum float64
+	for i := endIdx - period + 1; i <= endIdx; i++ {
+		c, prevClose := candles[i], candles[i-1].Close
+		tr := math.Max(c.High-c.Low, math.Max(math.Abs(c.High-prevClose), math.Abs(c.Low-prevClose)))
+		sum += tr
+	}
+	return sum / float64(period)
+}
@@ -0,0 +1,229 @@
+package backtest
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// closes builds candles with a ±1 high/low range around each close.
+func closes(prices ...float64) []exchange.Candle {
+	candles := make([]exchange.Candle, len(prices))
+	for i, p := range prices {
+		candles[i] = exchange.Candle{Open: p, High: p + 1, Low: p - 1, Close: p}
+	}
+	return candles
+}
+
+func flatThen(n int, base float64, tail ...float64) []exchange.Candle {
+	prices := make([]float64, 0, n+len(tail))
+	for i := 0; i < n; i++ {
+		// small alternation so the bands have non-zero width
+		prices = append(prices, base+float64(i%2))
+	}
+	return closes(append(prices, tail...)...)
+}
+
+// --- helpers ---
+
+func TestBollinger_Bands(t *testing.T) {
+	candles := closes(10, 10, 10, 10)
+	upper, lower := bollinger(candles, 3, 4, 2)
+	if upper != 10 || lower != 10 {
+		t.Errorf("flat bands = %f/%f, want 10/10", upper, lower)
+	}
+
+	candles = closes(8, 12, 8, 12)
+	upper, lower = bollinger(candles, 3, 4, 2)
+	if upper != 14 || lower != 6 {
+		t.Errorf("bands = %f/%f, want 14/6", upper, lower)
+	}
+}
+
+func TestATR_Basic(t *testing.T) {
+	candles := closes(100, 100, 100, 100)
+	if got := atr(candles, 3, 3); got != 2 {
+		t.Errorf("atr = %f, want 2", got)
+	}
+	if got := atr(candles, 1, 3); got != 0 {
+		t.Errorf("atr with insufficient data = %f, want 0", got)
+	}
+}
+
+func TestDonchian_Channel(t *testing.T) {
+	candles := closes(100, 105, 95, 102)
+	high, low := donchian(candles, 3, 3)
+	if high != 106 || low != 94 {
+		t.Errorf("channel = %f/%f, want 106/94", high, low)
+	}
+}
+
+func TestMACDHistogram_SignFollowsTrend(t *testing.T) {
+	var prices []float64
+	for i := 0; i < 60; i++ {
+		prices = append(prices, 100+float64(i))
+	}
+	hist, _ := macdHistogram(closes(prices...), 59, 12, 26, 9)
+	if hist <= 0 {
+		t.Errorf("uptrend histogram = %f, want > 0", hist)
+	}
+}
+
+// --- strategies ---
+
+func TestBollingerBreakout_Signals(t *testing.T) {
+	s, err := NewBollingerBreakout(20, 2, 0.02, 0.04, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name() != "bollinger-breakout" {
+		t.Errorf("name = %s", s.Name())
+	}
+
+	up := flatThen(25, 100, 110)
+	sig := s.OnCandle(up, len(up)-1)
+	if sig == nil || sig.Action != ActionBuy {
+		t.Fatalf("expected BUY on upper breakout, got %+v", sig)
+	}
+	if sig.StopLoss >= 110 || sig.TakeProfit <= 110 {
+		t.Errorf("long stop/target = %f/%f", sig.StopLoss, sig.TakeProfit)
+	}
+
+	down := flatThen(25, 100, 90)
+	if sig := s.OnCandle(down, len(down)-1); sig == nil || sig.Action != ActionSell {
+		t.Errorf("expected SELL on lower breakdown, got %+v", sig)
+	}
+
+	if sig := s.OnCandle(up, 10); sig != nil {
+		t.Error("expected nil with insufficient data")
+	}
+}
+
+func TestMACDTrend_CrossSignals(t *testing.T) {
+	s, err := NewMACDTrend(12, 26, 9, 0.02, 0.04, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name() != "macd-trend" {
+		t.Errorf("name = %s", s.Name())
+	}
+
+	// long decline then a sharp reversal: the histogram must cross up at some bar
+	var prices []float64
+	for i := 0; i < 60; i++ {
+		prices = append(prices, 200-float64(i))
+	}
+	for i := 0; i < 30; i++ {
+		prices = append(prices, 140+float64(i)*3)
+	}
+	candles := closes(prices...)
+
+	var buys int
+	for idx := range candles {
+		if sig := s.OnCandle(candles, idx); sig != nil && sig.Action == ActionBuy {
+			buys++
+		}
+	}
+	if buys == 0 {
+		t.Error("expected a bullish cross after the reversal")
+	}
+	if s.OnCandle(candles, 20) != nil {
+		t.Error("expected nil during warmup")
+	}
+}
+
+func TestDonchianBreakout_ATRStops(t *testing.T) {
+	s, err := NewDonchianBreakout(20, 20, 2, 4, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name() != "donchian-breakout" {
+		t.Errorf("name = %s", s.Name())
+	}
+
+	candles := flatThen(25, 100, 110)
+	sig := s.OnCandle(candles, len(candles)-1)
+	if sig == nil || sig.Action != ActionBuy {
+		t.Fatalf("expected BUY on channel breakout, got %+v", sig)
+	}
+	n := atr(candles, len(candles)-1, 20)
+	if math.Abs(sig.StopLoss-(110-2*n)) > 1e-9 || math.Abs(sig.TakeProfit-(110+4*n)) > 1e-9 {
+		t.Errorf("stop/target = %f/%f, want %f/%f", sig.StopLoss, sig.TakeProfit, 110-2*n, 110+4*n)
+	}
+
+	inside := flatThen(25, 100, 100.5)
+	if sig := s.OnCandle(inside, len(inside)-1); sig != nil {
+		t.Errorf("expected nil inside the channel, got %+v", sig)
+	}
+}
+
+// alwaysStrategy emits a fixed action on every bar.
+type alwaysStrategy struct{ action Action }
+
+func (a alwaysStrategy) Name() string { return "always-" + string(a.action) }
+func (a alwaysStrategy) OnCandle(_ []exchange.Candle, _ int) *Signal {
+	return &Signal{Action: a.action, Reason: "always"}
+}
+
+func TestRegimeSwitch_PicksByRegime(t *testing.T) {
+	s, err := NewRegimeSwitch(alwaysStrategy{ActionBuy}, alwaysStrategy{ActionSell}, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name() != "regime-switch" {
+		t.Errorf("name = %s", s.Name())
+	}
+
+	// steady trend with modest volatility → trending
+	var trend []exchange.Candle
+	for i := 0; i < 60; i++ {
+		p := 1000 + float64(i)*5
+		trend = append(trend, exchange.Candle{Open: p - 2, High: p + 3, Low: p - 4, Close: p})
+	}
+	sig := s.OnCandle(trend, len(trend)-1)
+	if sig == nil || sig.Action != ActionBuy {
+		t.Fatalf("trending market should use the trend strategy, got %+v", sig)
+	}
+	if !strings.HasPrefix(sig.Reason, "[trending]") {
+		t.Errorf("reason = %q, want regime prefix", sig.Reason)
+	}
+
+	// oscillation with ~1.5% range and no direction → ranging
+	var chop []exchange.Candle
+	for i := 0; i < 60; i++ {
+		p := 1000.0
+		if i%2 == 1 {
+			p = 1010
+		}
+		chop = append(chop, exchange.Candle{Open: p, High: p + 10, Low: p - 10, Close: p})
+	}
+	sig = s.OnCandle(chop, len(chop)-1)
+	if sig == nil || sig.Action != ActionSell {
+		t.Fatalf("ranging market should use the range strategy, got %+v", sig)
+	}
+
+	if s.OnCandle(chop, 10) != nil {
+		t.Error("expected nil before the lookback is filled")
+	}
+}
+
+func TestStrategyLibrary_RejectsBadPeriods(t *testing.T) {
+	errs := map[string]error{}
+	_, errs["bb period 0"] = NewBollingerBreakout(0, 2, 0.02, 0.04, 0.2)
+	_, errs["bb width -1"] = NewBollingerBreakout(20, -1, 0.02, 0.04, 0.2)
+	_, errs["macd fast -1"] = NewMACDTrend(-1, 26, 9, 0.02, 0.04, 0.2)
+	_, errs["macd fast >= slow"] = NewMACDTrend(26, 12, 9, 0.02, 0.04, 0.2)
+	_, errs["macd signal 0"] = NewMACDTrend(12, 26, 0, 0.02, 0.04, 0.2)
+	_, errs["donchian period 0"] = NewDonchianBreakout(0, 20, 2, 4, 0.2)
+	_, errs["donchian atr -5"] = NewDonchianBreakout(20, -5, 2, 4, 0.2)
+	_, errs["donchian stop 0"] = NewDonchianBreakout(20, 20, 0, 4, 0.2)
+	_, errs["regime lookback -1"] = NewRegimeSwitch(alwaysStrategy{ActionBuy}, alwaysStrategy{ActionSell}, -1)
+	_, errs["regime nil strategy"] = NewRegimeSwitch(nil, alwaysStrategy{ActionSell}, 50)
+	for name, err := range errs {
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
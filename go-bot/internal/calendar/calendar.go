@@ -0,0 +1,263 @@
+// event calendar — macro releases (CPI, FOMC) and token unlocks loaded from
+// local files and an optional http source. each event opens a blackout window
+// sized by its importance; during a blackout the scanner suppresses new
+// opportunities and executors refuse new entries. upcoming events are also
+// shown to claude.
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// Importance decides how wide an event's blackout window is.
+type Importance string
+
+const (
+	High   Importance = "high"
+	Medium Importance = "medium"
+	Low    Importance = "low"
+)
+
+// ParseImportance accepts high/medium/low in any case; anything else is medium.
+func ParseImportance(s string) Importance {
+	switch Importance(strings.ToLower(strings.TrimSpace(s))) {
+	case High:
+		return High
+	case Low:
+		return Low
+	default:
+		return Medium
+	}
+}
+
+// Event is a scheduled release or unlock.
+type Event struct {
+	Title      string
+	Time       time.Time
+	Importance Importance
+	Symbols    []string // assets or pairs it affects, e.g. ARB or ARB/USDT; empty = the whole market
+	Source     string   // name of the source it was loaded from
+}
+
+// Affects reports whether the event concerns the symbol.
+func (e Event) Affects(symbol string) bool {
+	if len(e.Symbols) == 0 {
+		return true
+	}
+	base := baseAsset(symbol)
+	for _, s := range e.Symbols {
+		if baseAsset(s) == base {
+			return true
+		}
+	}
+	return false
+}
+
+// baseAsset returns ARB for ARB, ARB/USDT or ARBUSDT
+func baseAsset(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if base, _, ok := strings.Cut(symbol, "/"); ok {
+		return base
+	}
+	for _, quote := range []string{"USDT", "USDC", "FDUSD", "BUSD"} {
+		if base, ok := strings.CutSuffix(symbol, quote); ok && base != "" {
+			return base
+		}
+	}
+	return symbol
+}
+
+// Window is how long before and after an event new entries are blocked.
+type Window struct {
+	Before time.Duration
+	After  time.Duration
+}
+
+// Config holds blackout windows per importance and the claude lookahead.
+type Config struct {
+	Windows   map[Importance]Window // importances without a window never block
+	Lookahead time.Duration         // how far ahead upcoming events are listed
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Windows: map[Importance]Window{
+			High:   {Before: 60 * time.Minute, After: 120 * time.Minute},
+			Medium: {Before: 30 * time.Minute, After: 60 * time.Minute},
+		},
+		Lookahead: 48 * time.Hour,
+	}
+}
+
+// Source loads events, e.g. from a file or url.
+type Source interface {
+	Name() string
+	Load(ctx context.Context) ([]Event, error)
+}
+
+// Calendar holds the events of all sources.
+type Calendar struct {
+	mu       sync.RWMutex
+	cfg      Config
+	sources  []Source
+	bySource map[string][]Event // last successful load per source
+	events   []Event            // all sources, by time
+	clock    clock.Clock
+}
+
+// New creates an empty calendar; add sources and call Refresh.
+func New(cfg Config) *Calendar {
+	return &Calendar{
+		cfg:      cfg,
+		bySource: make(map[string][]Event),
+		clock:    clock.Real(),
+	}
+}
+
+// SetClock replaces the time source blackouts are evaluated against.
+func (c *Calendar) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// AddSource registers a source. Call before Refresh.
+func (c *Calendar) AddSource(s Source) {
+	c.sources = append(c.sources, s)
+}
+
+// Refresh reloads every source. A source that fails keeps the events of its
+// last successful load; the errors are returned together.
+func (c *Calendar) Refresh(ctx context.Context) error {
+	var errs []error
+	loaded := make(map[string][]Event)
+	for _, s := range c.sources {
+		events, err := s.Load(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load events from %s: %w", s.Name(), err))
+			continue
+		}
+		for i := range events {
+			events[i].Source = s.Name()
+		}
+		loaded[s.Name()] = events
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, events := range loaded {
+		c.bySource[name] = events
+	}
+	c.events = c.events[:0]
+	for _, events := range c.bySource {
+		c.events = append(c.events, events...)
+	}
+	sort.SliceStable(c.events, func(i, j int) bool { return c.events[i].Time.Before(c.events[j].Time) })
+	return errors.Join(errs...)
+}
+
+// Run refreshes the calendar on an interval until ctx is cancelled.
+func (c *Calendar) Run(ctx context.Context, every time.Duration) {
+	ticker := c.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if err := c.Refresh(ctx); err != nil {
+				slog.Warn("event calendar: refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Events returns every loaded event, by time.
+func (c *Calendar) Events() []Event {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Event(nil), c.events...)
+}
+
+// Blackout returns the event whose window covers the current time for the
+// symbol, preferring the one whose window ends last.
+func (c *Calendar) Blackout(symbol string) (*Event, bool) {
+	now := c.clock.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var found *Event
+	var foundEnd time.Time
+	for i := range c.events {
+		e := c.events[i]
+		if !e.Affects(symbol) || !c.covers(e, now) {
+			continue
+		}
+		if end := c.BlackoutEnd(e); found == nil || end.After(foundEnd) {
+			found, foundEnd = &e, end
+		}
+	}
+	return found, found != nil
+}
+
+// InBlackout reports whether the event's window covers the current time.
+func (c *Calendar) InBlackout(e Event) bool {
+	return c.covers(e, c.clock.Now())
+}
+
+func (c *Calendar) covers(e Event, now time.Time) bool {
+	w, ok := c.cfg.Windows[e.Importance]
+	if !ok {
+		return false
+	}
+	return !now.Before(e.Time.Add(-w.Before)) && now.Before(e.Time.Add(w.After))
+}
+
+// Until is how long until the event, negative once it has passed.
+func (c *Calendar) Until(e Event) time.Duration {
+	return e.Time.Sub(c.clock.Now())
+}
+
+// BlackoutEnd is when the event's window closes.
+func (c *Calendar) BlackoutEnd(e Event) time.Time {
+	return e.Time.Add(c.cfg.Windows[e.Importance].After)
+}
+
+// AllowEntry reports whether a new position in the symbol may be opened,
+// with the reason when it may not.
+func (c *Calendar) AllowEntry(symbol string) (bool, string) {
+	e, ok := c.Blackout(symbol)
+	if !ok {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%s (%s) at %s, no new entries until %s",
+		e.Title, e.Importance, e.Time.UTC().Format("Jan 2 15:04 MST"), c.BlackoutEnd(*e).UTC().Format("15:04 MST"))
+}
+
+// Upcoming returns events affecting the symbol that are still in their
+// blackout window or due within the lookahead, by time.
+func (c *Calendar) Upcoming(symbol string) []Event {
+	now := c.clock.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var out []Event
+	for _, e := range c.events {
+		if !e.Affects(symbol) || e.Time.After(now.Add(c.cfg.Lookahead)) {
+			continue
+		}
+		if e.Time.Add(c.cfg.Windows[e.Importance].After).Before(now) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
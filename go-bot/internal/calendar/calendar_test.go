@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+type staticSource struct {
+	name   string
+	events []Event
+	err    error
+}
+
+func (s *staticSource) Name() string { return s.name }
+
+func (s *staticSource) Load(context.Context) ([]Event, error) {
+	return append([]Event(nil), s.events...), s.err
+}
+
+var cpiTime = time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC)
+
+func testCalendar(t *testing.T, now time.Time) (*Calendar, *clock.Simulated) {
+	t.Helper()
+	sim := clock.NewSimulated(now)
+	c := New(DefaultConfig())
+	c.SetClock(sim)
+	c.AddSource(&staticSource{name: "macro", events: []Event{
+		{Title: "US CPI", Time: cpiTime, Importance: High},
+		{Title: "Jobless claims", Time: cpiTime.Add(24 * time.Hour), Importance: Low},
+	}})
+	c.AddSource(&staticSource{name: "unlocks", events: []Event{
+		{Title: "ARB unlock", Time: cpiTime.Add(6 * time.Hour), Importance: Medium, Symbols: []string{"ARB"}},
+	}})
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return c, sim
+}
+
+func TestCalendarBlackout(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		symbol  string
+		blocked string // title of the blocking event, empty = allowed
+	}{
+		{"well before", cpiTime.Add(-2 * time.Hour), "BTC/USDT", ""},
+		{"inside the before window", cpiTime.Add(-30 * time.Minute), "BTC/USDT", "US CPI"},
+		{"after the release", cpiTime.Add(90 * time.Minute), "ETH/USDT", "US CPI"},
+		{"window closed", cpiTime.Add(2 * time.Hour), "ETH/USDT", ""},
+		{"unlock blocks its token", cpiTime.Add(6 * time.Hour), "ARB/USDT", "ARB unlock"},
+		{"unlock leaves other tokens", cpiTime.Add(6 * time.Hour), "OP/USDT", ""},
+		{"exchange symbol format", cpiTime.Add(6 * time.Hour), "ARBUSDT", "ARB unlock"},
+		{"low importance never blocks", cpiTime.Add(24 * time.Hour), "BTC/USDT", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testCalendar(t, tt.now)
+			ok, reason := c.AllowEntry(tt.symbol)
+			if tt.blocked == "" {
+				if !ok {
+					t.Errorf("expected entries to be allowed, got %q", reason)
+				}
+				return
+			}
+			if ok || !strings.HasPrefix(reason, tt.blocked) {
+				t.Errorf("expected a blackout for %q, got allowed=%v reason=%q", tt.blocked, ok, reason)
+			}
+		})
+	}
+}
+
+func TestCalendarUpcoming(t *testing.T) {
+	c, sim := testCalendar(t, cpiTime.Add(-3*time.Hour))
+
+	got := c.Upcoming("ARB/USDT")
+	if len(got) != 3 || got[0].Title != "US CPI" || got[1].Title != "ARB unlock" {
+		t.Fatalf("upcoming = %+v", got)
+	}
+	if len(c.Upcoming("BTC/USDT")) != 2 {
+		t.Error("the unlock shouldn't be listed for btc")
+	}
+	if c.Until(got[0]) != 3*time.Hour {
+		t.Errorf("cpi due in %v on the calendar's clock, want 3h", c.Until(got[0]))
+	}
+
+	// cpi stays listed while its blackout lasts
+	sim.Advance(4 * time.Hour)
+	if got := c.Upcoming("BTC/USDT"); len(got) != 2 || got[0].Title != "US CPI" {
+		t.Errorf("expected cpi to stay listed during its window, got %+v", got)
+	} else if !c.InBlackout(got[0]) || c.InBlackout(got[1]) {
+		t.Error("only cpi should be in its blackout window")
+	}
+	sim.Advance(2 * time.Hour)
+	if got := c.Upcoming("BTC/USDT"); len(got) != 1 || got[0].Title != "Jobless claims" {
+		t.Errorf("expected cpi to drop out after its window, got %+v", got)
+	}
+}
+
+func TestCalendarRefreshKeepsFailedSource(t *testing.T) {
+	src := &staticSource{name: "remote", events: []Event{{Title: "FOMC", Time: cpiTime, Importance: High}}}
+	c := New(DefaultConfig())
+	c.AddSource(src)
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	src.err = errors.New("timeout")
+	err := c.Refresh(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "remote") {
+		t.Errorf("expected the source error, got %v", err)
+	}
+	if events := c.Events(); len(events) != 1 || events[0].Source != "remote" {
+		t.Errorf("a failing source should keep its last events, got %+v", events)
+	}
+}
@@ -0,0 +1,241 @@
+// event sources — a local yaml or ics file, or the same formats over http.
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource loads events from a .yaml/.yml or .ics file.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Name() string {
+	return "file:" + filepath.Base(s.path)
+}
+
+func (s *FileSource) Load(_ context.Context) ([]Event, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(s.path), ".ics") {
+		return ParseICS(data)
+	}
+	return ParseYAML(data)
+}
+
+// HTTPSource loads events from a url serving ics (text/calendar or a .ics
+// path) or yaml/json.
+type HTTPSource struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{
+		url:        url,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *HTTPSource) Name() string {
+	return "http:" + s.url
+}
+
+func (s *HTTPSource) Load(ctx context.Context) ([]Event, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar source returned %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	path := strings.SplitN(s.url, "?", 2)[0]
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/calendar") || strings.HasSuffix(strings.ToLower(path), ".ics") {
+		return ParseICS(data)
+	}
+	return ParseYAML(data) // json is valid yaml
+}
+
+// yamlEvent is an event as written in a yaml file:
+//
+//	events:
+//	  - title: US CPI
+//	    time: 2026-11-12T13:30:00Z
+//	    importance: high
+//	  - title: ARB token unlock
+//	    time: 2026-11-16T12:00:00Z
+//	    symbols: [ARB]
+type yamlEvent struct {
+	Title      string    `yaml:"title"`
+	Time       time.Time `yaml:"time"`
+	Importance string    `yaml:"importance"`
+	Symbols    []string  `yaml:"symbols"`
+}
+
+// ParseYAML parses a yaml (or json) event list; importance defaults to medium.
+func ParseYAML(data []byte) ([]Event, error) {
+	var doc struct {
+		Events []yamlEvent `yaml:"events"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse events: %w", err)
+	}
+
+	events := make([]Event, 0, len(doc.Events))
+	for i, e := range doc.Events {
+		if e.Title == "" || e.Time.IsZero() {
+			return nil, fmt.Errorf("event %d needs a title and a time", i+1)
+		}
+		events = append(events, Event{
+			Title:      e.Title,
+			Time:       e.Time,
+			Importance: ParseImportance(e.Importance),
+			Symbols:    e.Symbols,
+		})
+	}
+	return events, nil
+}
+
+// ParseICS parses the VEVENTs of an iCalendar file. Importance comes from
+// X-IMPORTANCE, else PRIORITY (1-4 high, 5 medium, 6-9 low), else medium;
+// affected symbols from a comma-separated X-SYMBOLS.
+func ParseICS(data []byte) ([]Event, error) {
+	var events []Event
+	var cur *Event
+	var priority string
+
+	for _, line := range unfoldICS(data) {
+		name, params, value := splitICSLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			cur = &Event{}
+			priority = ""
+		case name == "END" && value == "VEVENT":
+			if cur == nil {
+				continue
+			}
+			if cur.Title == "" || cur.Time.IsZero() {
+				return nil, fmt.Errorf("event %d needs a SUMMARY and a DTSTART", len(events)+1)
+			}
+			if cur.Importance == "" {
+				cur.Importance = priorityImportance(priority)
+			}
+			events = append(events, *cur)
+			cur = nil
+		case cur == nil:
+			continue
+		case name == "SUMMARY":
+			cur.Title = unescapeICS(value)
+		case name == "DTSTART":
+			t, err := parseICSTime(value, params["TZID"])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse DTSTART %q: %w", value, err)
+			}
+			cur.Time = t
+		case name == "PRIORITY":
+			priority = value
+		case name == "X-IMPORTANCE":
+			cur.Importance = ParseImportance(value)
+		case name == "X-SYMBOLS":
+			for _, s := range strings.Split(value, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					cur.Symbols = append(cur.Symbols, s)
+				}
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfoldICS joins continuation lines (starting with a space or tab)
+func unfoldICS(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitICSLine splits NAME;PARAM=x:VALUE
+func splitICSLine(line string) (name string, params map[string]string, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func parseICSTime(value, tzid string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	loc := time.UTC
+	if tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func priorityImportance(priority string) Importance {
+	p, err := strconv.Atoi(strings.TrimSpace(priority))
+	switch {
+	case err != nil || p == 0:
+		return Medium
+	case p <= 4:
+		return High
+	case p == 5:
+		return Medium
+	default:
+		return Low
+	}
+}
+
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(s)
+}
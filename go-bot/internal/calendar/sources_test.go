@@ -0,0 +1,99 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testYAML = `
+events:
+  - title: US CPI
+    time: 2026-11-12T13:30:00Z
+    importance: HIGH
+  - title: ARB token unlock
+    time: 2026-11-16T12:00:00Z
+    symbols: [ARB]
+`
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:FOMC rate decision\\, press conference\r\n" +
+	"DTSTART:20261209T190000Z\r\n" +
+	"PRIORITY:1\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:SUI unlock\r\n" +
+	"DTSTART;TZID=America/New_York:20261201T080000\r\n" +
+	"X-IMPORTANCE:low\r\n" +
+	"X-SYMBOLS:SUI,\r\n" +
+	" SUI/USDT\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseYAML(t *testing.T) {
+	events, err := ParseYAML([]byte(testYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Importance != High || !events[0].Time.Equal(time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected cpi event %+v", events[0])
+	}
+	if events[1].Importance != Medium || len(events[1].Symbols) != 1 {
+		t.Errorf("importance should default to medium, got %+v", events[1])
+	}
+
+	if _, err := ParseYAML([]byte("events:\n  - title: no time\n")); err == nil {
+		t.Error("expected an error for an event without a time")
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	events, err := ParseICS([]byte(testICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	fomc := events[0]
+	if fomc.Title != "FOMC rate decision, press conference" || fomc.Importance != High || fomc.Time.Hour() != 19 {
+		t.Errorf("unexpected fomc event %+v", fomc)
+	}
+	sui := events[1]
+	if sui.Importance != Low || len(sui.Symbols) != 2 || sui.Symbols[1] != "SUI/USDT" {
+		t.Errorf("unexpected unlock event %+v", sui)
+	}
+	if sui.Time.UTC().Hour() != 13 {
+		t.Errorf("TZID should be applied, got %s", sui.Time.UTC())
+	}
+}
+
+func TestSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ics")
+	if err := os.WriteFile(path, []byte(testICS), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	events, err := NewFileSource(path).Load(context.Background())
+	if err != nil || len(events) != 2 {
+		t.Fatalf("file source: %d events, %v", len(events), err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte(testYAML))
+	}))
+	defer srv.Close()
+	events, err = NewHTTPSource(srv.URL + "/events").Load(context.Background())
+	if err != nil || len(events) != 2 || events[0].Title != "US CPI" {
+		t.Fatalf("http source: %+v, %v", events, err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // breaker states
@@ -62,7 +64,7 @@ type Breaker struct {
 	mu     sync.Mutex
 	config Config
 	users  map[int]*userState
-	now    func() time.Time // injectable clock for testing
+	clock  clock.Clock
 }
 
 // New creates a circuit breaker with the given config.
@@ -70,10 +72,21 @@ func New(config Config) *Breaker {
 	return &Breaker{
 		config: config,
 		users:  make(map[int]*userState),
-		now:    time.Now,
+		clock:  clock.Real(),
 	}
 }
 
+// SetClock replaces the time source (cooldowns and the daily reset follow it).
+func (b *Breaker) SetClock(c clock.Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = c
+}
+
+func (b *Breaker) now() time.Time {
+	return b.clock.Now()
+}
+
 // AllowTrade checks whether a user is allowed to open a new trade.
 // Returns true if trading is allowed, false with a reason if blocked.
 func (b *Breaker) AllowTrade(userID int) (bool, string) {
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -119,7 +121,8 @@ func TestCooldownTransition(t *testing.T) {
 	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := Config{MaxDailyLoss: 10, MaxConsecutiveLosses: 0, CooldownDuration: 30 * time.Minute}
 	b := New(cfg)
-	b.now = func() time.Time { return now }
+	clk := clock.NewSimulated(now)
+	b.SetClock(clk)
 
 	// trip the breaker
 	b.RecordTrade(1, -15)
@@ -128,7 +131,7 @@ func TestCooldownTransition(t *testing.T) {
 	}
 
 	// 29 minutes later — still open
-	b.now = func() time.Time { return now.Add(29 * time.Minute) }
+	clk.Set(now.Add(29 * time.Minute))
 	ok, reason := b.AllowTrade(1)
 	if ok {
 		t.Fatal("should still be blocked at 29min")
@@ -138,7 +141,7 @@ func TestCooldownTransition(t *testing.T) {
 	}
 
 	// 31 minutes later — transitions to half-open
-	b.now = func() time.Time { return now.Add(31 * time.Minute) }
+	clk.Set(now.Add(31 * time.Minute))
 	if b.State(1) != StateHalfOpen {
 		t.Fatalf("State = %v, want half_open after cooldown", b.State(1))
 	}
@@ -152,7 +155,8 @@ func TestHalfOpenProbeSuccess(t *testing.T) {
 	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := Config{MaxDailyLoss: 10, MaxConsecutiveLosses: 0, CooldownDuration: time.Minute}
 	b := New(cfg)
-	b.now = func() time.Time { return now }
+	clk := clock.NewSimulated(now)
+	b.SetClock(clk)
 
 	b.RecordTrade(1, -15)
 	if b.State(1) != StateOpen {
@@ -160,7 +164,7 @@ func TestHalfOpenProbeSuccess(t *testing.T) {
 	}
 
 	// advance past cooldown
-	b.now = func() time.Time { return now.Add(2 * time.Minute) }
+	clk.Set(now.Add(2 * time.Minute))
 	if b.State(1) != StateHalfOpen {
 		t.Fatal("should be half-open")
 	}
@@ -176,13 +180,14 @@ func TestHalfOpenProbeFailure(t *testing.T) {
 	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := Config{MaxDailyLoss: 10, MaxConsecutiveLosses: 0, CooldownDuration: time.Minute}
 	b := New(cfg)
-	b.now = func() time.Time { return now }
+	clk := clock.NewSimulated(now)
+	b.SetClock(clk)
 
 	b.RecordTrade(1, -15)
 
 	// advance past cooldown to half-open
 	now2 := now.Add(2 * time.Minute)
-	b.now = func() time.Time { return now2 }
+	clk.Set(now2)
 	if b.State(1) != StateHalfOpen {
 		t.Fatal("should be half-open")
 	}
@@ -199,7 +204,7 @@ func TestHalfOpenProbeFailure(t *testing.T) {
 		t.Fatal("should be blocked immediately after re-trip")
 	}
 
-	b.now = func() time.Time { return now2.Add(2 * time.Minute) }
+	clk.Set(now2.Add(2 * time.Minute))
 	ok, _ = b.AllowTrade(1)
 	if !ok {
 		t.Fatal("should allow after second cooldown")
@@ -259,7 +264,8 @@ func TestDailyReset(t *testing.T) {
 	now := time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)
 	cfg := Config{MaxDailyLoss: 50, MaxConsecutiveLosses: 0, CooldownDuration: 10 * time.Minute}
 	b := New(cfg)
-	b.now = func() time.Time { return now }
+	clk := clock.NewSimulated(now)
+	b.SetClock(clk)
 
 	// accumulate $30 loss today
 	b.RecordTrade(1, -30)
@@ -269,7 +275,7 @@ func TestDailyReset(t *testing.T) {
 	}
 
 	// next day — daily loss resets
-	b.now = func() time.Time { return now.Add(2 * time.Hour) } // crosses midnight
+	clk.Set(now.Add(2 * time.Hour)) // crosses midnight
 	loss, _ = b.Stats(1)
 	if loss != 0 {
 		t.Errorf("dailyLoss after day change = %f, want 0", loss)
@@ -280,7 +286,8 @@ func TestDailyResetDoesNotClearOpenState(t *testing.T) {
 	now := time.Date(2025, 1, 1, 23, 59, 0, 0, time.UTC)
 	cfg := Config{MaxDailyLoss: 10, MaxConsecutiveLosses: 0, CooldownDuration: 2 * time.Hour}
 	b := New(cfg)
-	b.now = func() time.Time { return now }
+	clk := clock.NewSimulated(now)
+	b.SetClock(clk)
 
 	b.RecordTrade(1, -15)
 	if b.State(1) != StateOpen {
@@ -288,7 +295,7 @@ func TestDailyResetDoesNotClearOpenState(t *testing.T) {
 	}
 
 	// next day — loss resets but breaker stays open (cooldown not expired)
-	b.now = func() time.Time { return now.Add(10 * time.Minute) }
+	clk.Set(now.Add(10 * time.Minute))
 	if b.State(1) != StateOpen {
 		t.Fatal("breaker should stay open across day boundary when cooldown hasn't expired")
 	}
@@ -17,6 +17,9 @@ const (
 	defaultMaxTokens = 1024
 	apiVersion       = "2023-06-01"
 	maxRetries       = 3
+
+	// ProviderAnthropic is the provider name recorded on claude decisions.
+	ProviderAnthropic = "anthropic"
 )
 
 // wraps the claude api with retry and backoff
@@ -68,19 +71,26 @@ func NewClient(apiKey string, opts ...Option) *Client {
 	return c
 }
 
+// Model returns the model name requests are sent to.
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Name returns the provider name, for fallback chains and decision logs.
+func (c *Client) Name() string {
+	return ProviderAnthropic
+}
+
 // sends all context to claude and returns a structured trading decision
 func (c *Client) Analyze(ctx context.Context, input *AnalysisInput) (*Decision, error) {
 	start := time.Now()
 
-	systemPrompt := buildSystemPrompt()
-	userPrompt := buildUserPrompt(input)
-
 	reqBody := apiRequest{
 		Model:     c.model,
 		MaxTokens: c.maxTokens,
-		System:    systemPrompt,
+		System:    systemPrompt(input),
 		Messages: []apiMessage{
-			{Role: "user", Content: userPrompt},
+			{Role: "user", Content: buildUserPrompt(input)},
 		},
 	}
 
@@ -89,18 +99,23 @@ func (c *Client) Analyze(ctx context.Context, input *AnalysisInput) (*Decision,
 		return nil, fmt.Errorf("claude api call failed: %w", err)
 	}
 
+	usage := Usage{InputTokens: respBody.Usage.InputTokens, OutputTokens: respBody.Usage.OutputTokens}
 	text := extractText(respBody)
 	if text == "" {
-		return nil, fmt.Errorf("empty response from claude")
+		return nil, &UsageError{Model: c.model, Usage: usage, Err: fmt.Errorf("empty response from claude")}
 	}
 
 	decision, err := ParseDecision(text)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse claude response: %w", err)
+		return nil, &UsageError{Model: c.model, Usage: usage, Err: fmt.Errorf("failed to parse claude response: %w", err)}
 	}
 
 	decision.Timestamp = time.Now()
 	decision.Latency = time.Since(start)
+	decision.Provider = ProviderAnthropic
+	decision.Model = c.model
+	decision.Usage = usage
+	tagPrompt(decision, input)
 
 	return decision, nil
 }
@@ -3,6 +3,7 @@ package claude
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -80,6 +81,8 @@ func TestAnalyzeSuccess(t *testing.T) {
 			},
 			StopReason: "end_turn",
 		}
+		resp.Usage.InputTokens = 2400
+		resp.Usage.OutputTokens = 120
 		json.NewEncoder(w).Encode(resp)
 	}))
 	defer server.Close()
@@ -149,6 +152,12 @@ func TestAnalyzeSuccess(t *testing.T) {
 	if decision.Latency <= 0 {
 		t.Error("expected positive latency")
 	}
+	if decision.Provider != ProviderAnthropic || decision.Model != defaultModel {
+		t.Errorf("expected anthropic/%s, got %s/%s", defaultModel, decision.Provider, decision.Model)
+	}
+	if decision.Usage.InputTokens != 2400 || decision.Usage.OutputTokens != 120 {
+		t.Errorf("expected the usage block to be kept, got %+v", decision.Usage)
+	}
 }
 
 func TestAnalyzeWithoutOptionalData(t *testing.T) {
@@ -287,7 +296,9 @@ func TestExtractTextEmpty(t *testing.T) {
 
 func TestEmptyResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(apiResponse{Content: []apiContentBlock{}})
+		resp := apiResponse{Content: []apiContentBlock{}}
+		resp.Usage.InputTokens = 2400
+		json.NewEncoder(w).Encode(resp)
 	}))
 	defer server.Close()
 
@@ -296,7 +307,12 @@ func TestEmptyResponse(t *testing.T) {
 		Market: MarketData{Symbol: "BTC/USDT", Price: 42000},
 	})
 	if err == nil {
-		t.Error("expected error for empty response")
+		t.Fatal("expected error for empty response")
+	}
+	// the call was billed, so the usage comes back with the error
+	var ue *UsageError
+	if !errors.As(err, &ue) || ue.Usage.InputTokens != 2400 || ue.Model != c.model {
+		t.Errorf("expected the billed usage with the error, got %v", err)
 	}
 }
 
@@ -0,0 +1,195 @@
+// openai-compatible provider — any /chat/completions api (openai, openrouter,
+// or a local ollama/vllm server) given the same prompts as claude.
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o"
+
+	// ProviderOpenAI is the default provider name for OpenAIClient.
+	ProviderOpenAI = "openai"
+)
+
+// talks to an openai-compatible chat completions api with retry and backoff
+type OpenAIClient struct {
+	name       string
+	apiKey     string
+	model      string
+	maxTokens  int
+	baseURL    string
+	httpClient *http.Client
+}
+
+// optional configuration for the openai client
+type OpenAIOption func(*OpenAIClient)
+
+// overrides the default model
+func WithOpenAIModel(model string) OpenAIOption {
+	return func(c *OpenAIClient) { c.model = model }
+}
+
+// overrides the default max tokens
+func WithOpenAIMaxTokens(maxTokens int) OpenAIOption {
+	return func(c *OpenAIClient) { c.maxTokens = maxTokens }
+}
+
+// overrides the provider name recorded on decisions, e.g. "ollama"
+func WithOpenAIName(name string) OpenAIOption {
+	return func(c *OpenAIClient) { c.name = name }
+}
+
+// overrides the default http client
+func WithOpenAIHTTPClient(hc *http.Client) OpenAIOption {
+	return func(c *OpenAIClient) { c.httpClient = hc }
+}
+
+// creates a new openai-compatible client. an empty baseURL means openai
+// itself; local servers usually take an empty apiKey.
+func NewOpenAIClient(baseURL, apiKey string, opts ...OpenAIOption) *OpenAIClient {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	c := &OpenAIClient{
+		name:      ProviderOpenAI,
+		apiKey:    apiKey,
+		model:     defaultOpenAIModel,
+		maxTokens: defaultMaxTokens,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			// local models are slower than hosted ones
+			Timeout: 90 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Name returns the provider name recorded on decisions.
+func (c *OpenAIClient) Name() string {
+	return c.name
+}
+
+// Model returns the model name requests are sent to.
+func (c *OpenAIClient) Model() string {
+	return c.model
+}
+
+// sends all context to the model and returns a structured trading decision
+func (c *OpenAIClient) Analyze(ctx context.Context, input *AnalysisInput) (*Decision, error) {
+	start := time.Now()
+
+	reqBody := chatRequest{
+		Model:     c.model,
+		MaxTokens: c.maxTokens,
+		Messages: []apiMessage{
+			{Role: "system", Content: systemPrompt(input)},
+			{Role: "user", Content: buildUserPrompt(input)},
+		},
+	}
+
+	resp, err := c.sendWithRetry(ctx, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("%s api call failed: %w", c.name, err)
+	}
+
+	usage := Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return nil, &UsageError{Model: c.model, Usage: usage, Err: fmt.Errorf("empty response from %s", c.name)}
+	}
+
+	decision, err := ParseDecision(resp.Choices[0].Message.Content)
+	if err != nil {
+		return nil, &UsageError{Model: c.model, Usage: usage, Err: fmt.Errorf("failed to parse %s response: %w", c.name, err)}
+	}
+
+	decision.Timestamp = time.Now()
+	decision.Latency = time.Since(start)
+	decision.Provider = c.name
+	decision.Model = c.model
+	decision.Usage = usage
+	tagPrompt(decision, input)
+	if resp.Model != "" {
+		decision.Model = resp.Model
+	}
+
+	return decision, nil
+}
+
+// sends the request with exponential backoff on rate limits and server errors
+func (c *OpenAIClient) sendWithRetry(ctx context.Context, reqBody chatRequest) (*chatResponse, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("request failed: %w", err)
+			continue
+		}
+
+		respBytes, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read response: %w", err)
+			continue
+		}
+
+		// success
+		if resp.StatusCode == http.StatusOK {
+			var result chatResponse
+			if err := json.Unmarshal(respBytes, &result); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+			return &result, nil
+		}
+
+		// rate limited or server trouble — retry
+		var apiErr chatError
+		json.Unmarshal(respBytes, &apiErr)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("status %d (attempt %d/%d): %s", resp.StatusCode, attempt+1, maxRetries+1, apiErr.Error.Message)
+			continue
+		}
+
+		// non-retryable error
+		return nil, fmt.Errorf("%s api error %d: %s", c.name, resp.StatusCode, apiErr.Error.Message)
+	}
+
+	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+}
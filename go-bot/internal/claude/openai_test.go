@@ -0,0 +1,80 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIAnalyze(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("no api key should mean no authorization header")
+		}
+
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "llama3.1:70b" {
+			t.Errorf("expected model llama3.1:70b, got %s", req.Model)
+		}
+		if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[0].Content != buildSystemPrompt() {
+			t.Fatalf("expected the claude system prompt first, got %+v", req.Messages)
+		}
+		if !strings.Contains(req.Messages[1].Content, "SOL/USDT") {
+			t.Error("expected user prompt to contain symbol")
+		}
+
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"llama3.1:70b","choices":[{"message":{"role":"assistant",` +
+			`"content":"{\"action\":\"SELL\",\"confidence\":72,\"entry\":140,\"stop_loss\":146,\"take_profit\":128,\"position_size\":100,\"reasoning\":\"Rejected at resistance.\"}"},` +
+			`"finish_reason":"stop"}],"usage":{"prompt_tokens":1800,"completion_tokens":90}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(server.URL+"/v1/", "", WithOpenAIModel("llama3.1:70b"), WithOpenAIName("ollama"))
+	decision, err := c.Analyze(context.Background(), &AnalysisInput{
+		Market: MarketData{Symbol: "SOL/USDT", Price: 140},
+	})
+	if err != nil {
+		t.Fatalf("analyze failed: %v", err)
+	}
+	if decision.Action != ActionSell || decision.Confidence != 72 || decision.Plan.RiskReward <= 0 {
+		t.Errorf("unexpected decision %+v", decision)
+	}
+	if decision.Provider != "ollama" || decision.Model != "llama3.1:70b" {
+		t.Errorf("expected ollama/llama3.1:70b, got %s/%s", decision.Provider, decision.Model)
+	}
+	if decision.Usage.InputTokens != 1800 || decision.Usage.OutputTokens != 90 {
+		t.Errorf("expected usage 1800/90, got %+v", decision.Usage)
+	}
+	if decision.PromptVersion != DefaultPromptVersion {
+		t.Errorf("expected prompt version %s, got %q", DefaultPromptVersion, decision.PromptVersion)
+	}
+}
+
+func TestOpenAINonRetryableError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid api key","type":"invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	c := NewOpenAIClient(server.URL, "sk-test")
+	_, err := c.Analyze(context.Background(), &AnalysisInput{Market: MarketData{Symbol: "BTC/USDT"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("expected the api error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("auth errors shouldn't be retried, got %d calls", calls)
+	}
+}
@@ -1,63 +1,64 @@
 package claude
 
 import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"strings"
 )
 
+// DefaultPromptVersion is the built-in system prompt, used when an analysis
+// has no prompt variant.
+const DefaultPromptVersion = "v1"
+
+//go:embed prompts/v1.txt
+var defaultSystemPrompt string
+
+// DefaultPrompt returns the built-in system prompt.
+func DefaultPrompt() PromptVariant {
+	return PromptVariant{Version: DefaultPromptVersion, System: buildSystemPrompt()}
+}
+
+// RenderPrompt returns the system and user prompts Analyze would send for an input.
+func RenderPrompt(input *AnalysisInput) (system, user string) {
+	return systemPrompt(input), buildUserPrompt(input)
+}
+
+// PromptHash returns a stable key for the exact request (model + rendered prompts),
+// so identical analyses can be served from a response cache.
+func PromptHash(model string, input *AnalysisInput) string {
+	system, user := RenderPrompt(input)
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(system))
+	h.Write([]byte{0})
+	h.Write([]byte(user))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // builds the system prompt that tells claude how to respond
 func buildSystemPrompt() string {
-	return `You are a crypto trading analyst. Given market data, technical indicators, and ML predictions, you must provide a structured trading decision.
-
-You MUST respond with EXACTLY this JSON format and nothing else:
-{
-  "action": "BUY" | "SELL" | "HOLD",
-  "confidence": <number 0-100>,
-  "entry": <price>,
-  "stop_loss": <price>,
-  "take_profit": <price>,
-  "position_size": <usd amount>,
-  "reasoning": "<one paragraph explaining your decision>"
+	return strings.TrimSpace(defaultSystemPrompt)
 }
 
-Rules:
-- Only recommend BUY or SELL if confidence >= 60
-- Stop loss should limit risk to 1-3% of position
-- Take profit should give at least 1:2 risk/reward ratio
-- Position size should be proportional to confidence (higher confidence = larger position, max $500)
-- If data is insufficient or conflicting, choose HOLD
-- Be conservative — false positives are worse than missed opportunities
-- Consider ALL available signals: indicators, ML predictions, sentiment, market regime, and alternative data
-- Keep reasoning concise (under 100 words)
-- When order flow data is available, use buy/sell ratio and depth imbalance for entry timing
-- When on-chain data shows high exchange inflows (positive net flow), be more cautious about buying
-- When fear/greed index is extreme (< 20 or > 80), consider contrarian positions
-- When higher-timeframe context is provided, use it for confirmation:
-  * Only take longs if at least one HTF trend is "up" or "neutral"
-  * Only take shorts if at least one HTF trend is "down" or "neutral"
-  * If HTF and primary timeframe disagree, reduce confidence by 15-20
-  * HTF overbought/oversold adds weight to reversal signals
-- When market regime is provided, adapt strategy accordingly:
-  * Trending: favor trend-following entries, wider stops
-  * Ranging: favor mean-reversion at support/resistance
-  * Volatile: reduce position size, use wider stops
-  * Quiet: watch for breakout setups, wait for confirmation
-- IMPORTANT: Account for trading costs when sizing positions and setting targets.
-  Typical spot fees are 0.10% maker / 0.10% taker (round-trip ~0.20%).
-  Futures fees are 0.02% maker / 0.04% taker plus 8h funding rate.
-  Only recommend trades where expected profit clearly exceeds total costs.
-  Avoid small scalps that fees would eat up.
-- When ATR/ADX/Stochastic data is available, incorporate it:
-  * High ATR (>3%) = volatile market — reduce size or widen stops
-  * ADX > 25 = trending — favor trend-following strategies
-  * ADX < 15 = no trend — favor mean-reversion or wait
-  * Stochastic oversold + bullish cross = potential long entry
-  * Stochastic overbought + bearish cross = potential short entry
-- SELF-LEARNING: When trade history is provided, analyze your past decisions:
-  * Identify patterns in winning vs losing trades
-  * Adjust confidence based on recent accuracy (lower if losing streak)
-  * Avoid market conditions that led to consecutive losses
-  * If win rate < 40%, increase HOLD bias until conditions improve`
+// systemPrompt is the input's prompt variant, or the default one
+func systemPrompt(input *AnalysisInput) string {
+	if input.Prompt != nil && input.Prompt.System != "" {
+		return input.Prompt.System
+	}
+	return buildSystemPrompt()
+}
+
+// tagPrompt records on the decision which prompt produced it
+func tagPrompt(d *Decision, input *AnalysisInput) {
+	d.PromptVersion = DefaultPromptVersion
+	if input.Prompt != nil && input.Prompt.Version != "" {
+		d.PromptVersion = input.Prompt.Version
+		d.PromptExperiment = input.Prompt.Experiment
+	}
 }
 
 // builds the user prompt with all available analysis data
@@ -93,6 +94,20 @@ func buildUserPrompt(input *AnalysisInput) string {
 		b.WriteString("\n")
 	}
 
+	// chart patterns
+	if input.Patterns != nil {
+		b.WriteString("## Chart Patterns\n")
+		b.WriteString(formatPatterns(input.Patterns))
+		b.WriteString("\n")
+	}
+
+	// second opinion
+	if input.Second != nil {
+		b.WriteString("## Second Opinion\n")
+		b.WriteString(formatSecondOpinion(input.Second))
+		b.WriteString("\n")
+	}
+
 	// trading costs
 	if input.Costs != nil {
 		b.WriteString("## Trading Costs\n")
@@ -107,6 +122,13 @@ func buildUserPrompt(input *AnalysisInput) string {
 		b.WriteString("\n")
 	}
 
+	// price structure
+	if input.Structure != nil {
+		b.WriteString("## Market Structure\n")
+		b.WriteString(formatStructure(input.Structure))
+		b.WriteString("\n")
+	}
+
 	// alternative data sources
 	if input.AltData != nil {
 		b.WriteString("## Alternative Data\n")
@@ -114,6 +136,20 @@ func buildUserPrompt(input *AnalysisInput) string {
 		b.WriteString("\n")
 	}
 
+	// btc, eth and the total market
+	if input.MarketContext != nil {
+		b.WriteString("## Overall Market\n")
+		b.WriteString(formatMarketContext(input.MarketContext))
+		b.WriteString("\n")
+	}
+
+	// scheduled macro releases and unlocks
+	if len(input.Events) > 0 {
+		b.WriteString("## Upcoming Events\n")
+		b.WriteString(formatEvents(input.Events))
+		b.WriteString("\n")
+	}
+
 	// higher-timeframe context
 	if len(input.HTFContext) > 0 {
 		b.WriteString("## Higher Timeframe Context\n")
@@ -121,6 +157,23 @@ func buildUserPrompt(input *AnalysisInput) string {
 		b.WriteString("\n")
 	}
 
+	// the user's open positions
+	if input.Portfolio != nil {
+		b.WriteString("## Your Open Portfolio\n")
+		b.WriteString(formatPortfolio(input.Portfolio))
+		b.WriteString("\n")
+	}
+
+	// sections from custom pipeline stages
+	for _, sec := range input.Sections {
+		if sec.Title == "" || sec.Body == "" {
+			continue
+		}
+		b.WriteString("## " + sec.Title + "\n")
+		b.WriteString(strings.TrimRight(sec.Body, "\n"))
+		b.WriteString("\n\n")
+	}
+
 	// trade history for self-learning
 	if len(input.TradeHistory) > 0 {
 		b.WriteString("## Recent Trade History (Your Past Decisions)\n")
@@ -160,11 +213,24 @@ func formatIndicators(ind *Indicators) string {
 		ind.BBUpper, ind.BBMiddle, ind.BBLower))
 
 	// ema
-	emaSignal := "bearish"
-	if ind.EMA12 > ind.EMA26 {
-		emaSignal = "bullish"
+	if ind.EMA12 > 0 && ind.EMA26 > 0 {
+		emaSignal := "bearish"
+		if ind.EMA12 > ind.EMA26 {
+			emaSignal = "bullish"
+		}
+		b.WriteString(fmt.Sprintf("- EMA: 12=%.2f, 26=%.2f (%s crossover)\n", ind.EMA12, ind.EMA26, emaSignal))
+	}
+	if len(ind.EMAs) > 0 {
+		parts := make([]string, len(ind.EMAs))
+		for i, e := range ind.EMAs {
+			parts[i] = fmt.Sprintf("%d=%.2f", e.Period, e.Value)
+		}
+		line := "- EMA stack: " + strings.Join(parts, ", ")
+		if ind.EMAAlignment != "" {
+			line += fmt.Sprintf(" (%s alignment)", strings.ToLower(ind.EMAAlignment))
+		}
+		b.WriteString(line + "\n")
 	}
-	b.WriteString(fmt.Sprintf("- EMA: 12=%.2f, 26=%.2f (%s crossover)\n", ind.EMA12, ind.EMA26, emaSignal))
 
 	// volume
 	if ind.VolumeSpike {
@@ -188,13 +254,75 @@ func formatIndicators(ind *Indicators) string {
 		b.WriteString(fmt.Sprintf("- Stochastic: %%K=%.1f, %%D=%.1f (%s)\n", ind.StochK, ind.StochD, ind.StochSignal))
 	}
 
+	// vwap
+	if ind.VWAP > 0 {
+		b.WriteString(fmt.Sprintf("- VWAP (session): %.2f, bands %.2f-%.2f", ind.VWAP, ind.VWAPLower, ind.VWAPUpper))
+		if ind.VWAPAnchored > 0 {
+			b.WriteString(fmt.Sprintf(", anchored=%.2f", ind.VWAPAnchored))
+		}
+		b.WriteString("\n")
+	}
+
+	// ichimoku
+	if ind.IchimokuSignal != "" {
+		b.WriteString(fmt.Sprintf("- Ichimoku: tenkan=%.2f, kijun=%.2f, cloud=%.2f/%.2f (%s, TK cross: %s)\n",
+			ind.IchimokuTenkan, ind.IchimokuKijun, ind.IchimokuSenkouA, ind.IchimokuSenkouB,
+			strings.ToLower(ind.IchimokuSignal), strings.ToLower(ind.IchimokuTKCross)))
+	}
+
+	// obv
+	if ind.OBVSignal != "" {
+		line := fmt.Sprintf("- OBV: %s (slope %.2f)", strings.ToLower(ind.OBVSignal), ind.OBVSlope)
+		if ind.OBVDivergence != "" && ind.OBVDivergence != "NONE" {
+			line += fmt.Sprintf(", %s divergence vs price", strings.ToLower(ind.OBVDivergence))
+		}
+		b.WriteString(line + "\n")
+	}
+
+	// supertrend
+	if ind.SupertrendDir != "" {
+		line := fmt.Sprintf("- Supertrend: %s at %.2f", ind.SupertrendDir, ind.Supertrend)
+		if ind.SupertrendFlipped {
+			line += " (just flipped)"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	// donchian
+	if ind.DonchianSignal != "" {
+		b.WriteString(fmt.Sprintf("- Donchian(%d): upper=%.2f, lower=%.2f (%s)\n",
+			ind.DonchianPeriod, ind.DonchianUpper, ind.DonchianLower, strings.ToLower(ind.DonchianSignal)))
+	}
+
+	// pivots
+	if ind.Pivot > 0 {
+		b.WriteString(fmt.Sprintf("- Pivots (classic): P=%.2f, R1=%.2f, R2=%.2f, S1=%.2f, S2=%.2f\n",
+			ind.Pivot, ind.PivotR1, ind.PivotR2, ind.PivotS1, ind.PivotS2))
+		b.WriteString(fmt.Sprintf("- Pivots (fibonacci): R1=%.2f, R2=%.2f, S1=%.2f, S2=%.2f\n",
+			ind.FibR1, ind.FibR2, ind.FibS1, ind.FibS2))
+	}
+
 	return b.String()
 }
 
 // formats ml prediction for the prompt
 func formatPrediction(pred *MLPrediction) string {
-	return fmt.Sprintf("- Direction: %s\n- Magnitude: %.2f%%\n- Confidence: %.0f%%\n- Timeframe: %s\n",
+	out := fmt.Sprintf("- Direction: %s\n- Magnitude: %.2f%%\n- Confidence: %.0f%%\n- Timeframe: %s\n",
 		pred.Direction, pred.Magnitude, pred.Confidence*100, pred.Timeframe)
+	if len(pred.Models) == 0 {
+		return out
+	}
+
+	var b strings.Builder
+	b.WriteString(out)
+	agree := int(pred.Agreement*float64(len(pred.Models)) + 0.5)
+	b.WriteString(fmt.Sprintf("- Model agreement: %.0f%% (%d of %d models)\n", pred.Agreement*100, agree, len(pred.Models)))
+	b.WriteString(fmt.Sprintf("- Dispersion: %.2f%% (spread of the models' predicted moves)\n", pred.Dispersion))
+	for _, m := range pred.Models {
+		b.WriteString(fmt.Sprintf("  * %s: %s %.2f%%, %.0f%% confidence, weight %.2f\n",
+			m.Model, m.Direction, m.Magnitude, m.Confidence*100, m.Weight))
+	}
+	return b.String()
 }
 
 // formats sentiment data for the prompt
@@ -203,6 +331,107 @@ func formatSentiment(sent *Sentiment) string {
 		sent.Label, sent.Score, sent.Confidence*100)
 }
 
+// formats detected chart patterns for the prompt
+func formatPatterns(cp *ChartPatterns) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("- Overall: %s (strength %.2f)\n", cp.Signal, cp.Strength))
+	for _, p := range cp.Patterns {
+		b.WriteString(fmt.Sprintf("- %s: %s, %.0f%% confidence", p.Name, p.Direction, p.Confidence*100))
+		if p.Neckline > 0 {
+			b.WriteString(fmt.Sprintf(", neckline %.2f", p.Neckline))
+		}
+		if p.Target > 0 {
+			b.WriteString(fmt.Sprintf(", target %.2f", p.Target))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formats an advisory second opinion for the prompt
+func formatSecondOpinion(s *SecondOpinion) string {
+	return fmt.Sprintf("- Source: %s\n- Action: %s (%s)\n- Confidence: %.0f%%\n",
+		strings.ReplaceAll(s.Source, "_", " "), s.Action, s.Detail, s.Confidence*100)
+}
+
+// formats btc/eth trends, dominance, total market cap and relative strength
+func formatMarketContext(m *MarketContext) string {
+	var b strings.Builder
+	for _, a := range []*AssetTrend{m.BTC, m.ETH} {
+		if a == nil {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- %s: $%.2f, %+.2f%% over %d %s candles, trend %s, regime %s (ADX %.1f)\n",
+			a.Symbol, a.Price, a.ChangePct, m.Window, m.Timeframe, a.Trend, a.Regime, a.ADX))
+	}
+	if m.BTCDominance > 0 {
+		b.WriteString(fmt.Sprintf("- BTC dominance: %.1f%%\n", m.BTCDominance))
+	}
+	if m.TotalMarketCap > 0 {
+		b.WriteString(fmt.Sprintf("- Total market cap: $%.2fT (%+.2f%% 24h)\n", m.TotalMarketCap/1e12, m.MarketCapChange24h))
+	}
+	if rs := m.RelativeStrength; rs != nil {
+		verdict := "outperforming"
+		if rs.RelativePct < 0 {
+			verdict = "underperforming"
+		}
+		b.WriteString(fmt.Sprintf("- Relative strength vs BTC: %+.2f%% vs %+.2f%% over the same candles (%s by %.2f%%)\n",
+			rs.SymbolChangePct, rs.BTCChangePct, verdict, math.Abs(rs.RelativePct)))
+	}
+	return b.String()
+}
+
+// formats scheduled events with how far away they are
+func formatEvents(events []CalendarEvent) string {
+	var b strings.Builder
+	for _, e := range events {
+		when := fmt.Sprintf("in %.1fh", e.HoursAway)
+		if e.HoursAway < 0 {
+			when = fmt.Sprintf("%.1fh ago", -e.HoursAway)
+		}
+		b.WriteString(fmt.Sprintf("- %s (%s importance): %s, %s", e.Title, e.Importance, e.Time.UTC().Format("Jan 2 15:04 UTC"), when))
+		if e.InBlackout {
+			b.WriteString(" — blackout active, new entries are blocked")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formats the user's open positions, exposure and correlation to the candidate
+func formatPortfolio(p *PortfolioContext) string {
+	var b strings.Builder
+	if len(p.Positions) == 0 {
+		b.WriteString("- No open positions\n")
+		return b.String()
+	}
+	for _, pos := range p.Positions {
+		mode := "live"
+		if pos.Paper {
+			mode = "paper"
+		}
+		lev := ""
+		if pos.Leverage > 1 {
+			lev = fmt.Sprintf(" %dx", pos.Leverage)
+		}
+		b.WriteString(fmt.Sprintf("- %s %s %s%s (%s): $%.0f notional, PnL %+.2f%%\n",
+			pos.Symbol, pos.Side, pos.Type, lev, mode, pos.Notional, pos.PnLPct))
+	}
+	b.WriteString(fmt.Sprintf("- Exposure: $%.0f total, $%.0f long, $%.0f short, $%.0f net\n",
+		p.TotalExposure, p.LongExposure, p.ShortExposure, p.NetExposure))
+	if p.MarginInUse > 0 {
+		b.WriteString(fmt.Sprintf("- Margin in use: $%.0f\n", p.MarginInUse))
+	}
+	b.WriteString(fmt.Sprintf("- Largest position: %.0f%% of exposure\n", p.ConcentrationPct))
+	for _, c := range p.Correlations {
+		b.WriteString(fmt.Sprintf("- Correlation with %s: %.2f (%s)\n", c.Symbol, c.Value, c.Risk))
+	}
+	for _, w := range p.Warnings {
+		b.WriteString(fmt.Sprintf("- Warning: %s\n", w))
+	}
+	return b.String()
+}
+
 // formats trading cost context for the prompt
 func formatTradingCosts(costs *TradingCosts) string {
 	var b strings.Builder
@@ -232,6 +461,41 @@ func formatRegime(r *RegimeInfo) string {
 	return b.String()
 }
 
+// formats market structure for the prompt
+func formatStructure(ms *MarketStructure) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("- Structure trend: %s\n", ms.Trend))
+	if ms.LastEvent != nil {
+		b.WriteString(fmt.Sprintf("- Last break: %s %s through %.2f\n",
+			ms.LastEvent.Direction, ms.LastEvent.Type, ms.LastEvent.Price))
+	}
+	if len(ms.Supports) > 0 {
+		b.WriteString("- Support: " + formatPriceLevels(ms.Supports) + "\n")
+	}
+	if len(ms.Resistances) > 0 {
+		b.WriteString("- Resistance: " + formatPriceLevels(ms.Resistances) + "\n")
+	}
+	if ms.SupportTrendline > 0 {
+		b.WriteString(fmt.Sprintf("- Support trendline at %.2f\n", ms.SupportTrendline))
+	}
+	if ms.ResistanceTrendline > 0 {
+		b.WriteString(fmt.Sprintf("- Resistance trendline at %.2f\n", ms.ResistanceTrendline))
+	}
+	return b.String()
+}
+
+func formatPriceLevels(levels []PriceLevel) string {
+	parts := make([]string, len(levels))
+	for i, l := range levels {
+		if l.Source == "liquidity" {
+			parts[i] = fmt.Sprintf("%.2f (order book wall $%.0f)", l.Price, l.SizeUSD)
+		} else {
+			parts[i] = fmt.Sprintf("%.2f (%d touches)", l.Price, l.Touches)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 // formats alternative data for the prompt
 func formatAltData(alt *AltData) string {
 	var b strings.Builder
@@ -257,6 +521,9 @@ func formatAltData(alt *AltData) string {
 		b.WriteString(fmt.Sprintf("- Large Buy Orders: %d, Large Sell Orders: %d\n",
 			alt.OrderFlow.LargeBuyOrders, alt.OrderFlow.LargeSellOrders))
 		b.WriteString(fmt.Sprintf("- Spread: %.1f bps\n", alt.OrderFlow.SpreadBps))
+		for _, w := range alt.OrderFlow.Walls {
+			b.WriteString(fmt.Sprintf("- %s wall: $%.0f at %.2f\n", w.Side, w.SizeUSD, w.Price))
+		}
 	}
 
 	if alt.OnChain != nil {
@@ -3,6 +3,7 @@ package claude
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildSystemPrompt(t *testing.T) {
@@ -239,6 +240,70 @@ func TestEMACrossoverDirection(t *testing.T) {
 	}
 }
 
+func TestFormatIndicatorsWithoutEMAs(t *testing.T) {
+	result := formatIndicators(&Indicators{RSI: 50})
+	if strings.Contains(result, "EMA") {
+		t.Errorf("missing emas should be omitted, got:\n%s", result)
+	}
+}
+
+func TestFormatIndicatorsStructure(t *testing.T) {
+	ind := &Indicators{
+		RSI:   50,
+		EMA12: 105,
+		EMA26: 103,
+		EMAs:              []EMALevel{{Period: 12, Value: 105}, {Period: 26, Value: 103}, {Period: 50, Value: 100}},
+		EMAAlignment:      "BULLISH",
+		VWAP:              104,
+		VWAPUpper:         106,
+		VWAPLower:         102,
+		VWAPAnchored:      99,
+		IchimokuTenkan:    104,
+		IchimokuKijun:     102,
+		IchimokuSenkouA:   101,
+		IchimokuSenkouB:   98,
+		IchimokuSignal:    "ABOVE_CLOUD",
+		IchimokuTKCross:   "BULLISH",
+		OBVSlope:          0.45,
+		OBVSignal:         "RISING",
+		OBVDivergence:     "NONE",
+		Supertrend:        101.5,
+		SupertrendDir:     "UP",
+		SupertrendFlipped: true,
+		DonchianUpper:     107,
+		DonchianLower:     95,
+		DonchianSignal:    "BREAKOUT_UP",
+		DonchianPeriod:    55,
+		Pivot:             103,
+		PivotR1:           105,
+		PivotR2:           108,
+		PivotS1:           100,
+		PivotS2:           98,
+		FibR1:             104.5,
+		FibR2:             106,
+		FibS1:             101.5,
+		FibS2:             100,
+	}
+	result := formatIndicators(ind)
+	for _, want := range []string{
+		"EMA stack: 12=105.00, 26=103.00, 50=100.00 (bullish alignment)",
+		"VWAP (session): 104.00, bands 102.00-106.00, anchored=99.00",
+		"Ichimoku: tenkan=104.00, kijun=102.00, cloud=101.00/98.00 (above_cloud, TK cross: bullish)",
+		"OBV: rising (slope 0.45)",
+		"Supertrend: UP at 101.50 (just flipped)",
+		"Donchian(55): upper=107.00, lower=95.00 (breakout_up)",
+		"Pivots (classic): P=103.00, R1=105.00, R2=108.00, S1=100.00, S2=98.00",
+		"Pivots (fibonacci): R1=104.50, R2=106.00, S1=101.50, S2=100.00",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "divergence") {
+		t.Error("NONE divergence should be omitted")
+	}
+}
+
 func TestFormatTradingCostsFullFields(t *testing.T) {
 	costs := &TradingCosts{
 		SpotMakerFeePct: 0.10,
@@ -521,3 +586,270 @@ func TestBuildUserPromptWithHTFContext(t *testing.T) {
 		t.Error("prompt should contain Higher Timeframe Context section")
 	}
 }
+
+func TestBuildUserPromptWithSections(t *testing.T) {
+	input := &AnalysisInput{
+		Market: MarketData{Symbol: "BTC/USDT", Price: 42000},
+		Sections: []PromptSection{
+			{Title: "Options Flow", Body: "- Put/call ratio: 0.62\n"},
+			{Title: "Empty", Body: ""},
+		},
+		TradeHistory: []TradeOutcome{{Symbol: "BTC/USDT", Action: "BUY"}},
+	}
+	prompt := buildUserPrompt(input)
+	if !strings.Contains(prompt, "## Options Flow\n- Put/call ratio: 0.62\n\n") {
+		t.Errorf("prompt should contain the stage section, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "## Empty") {
+		t.Error("empty sections should be left out")
+	}
+	if strings.Index(prompt, "Options Flow") > strings.Index(prompt, "Recent Trade History") {
+		t.Error("stage sections should come before the trade history")
+	}
+}
+
+func TestBuildUserPromptWithPortfolio(t *testing.T) {
+	input := &AnalysisInput{
+		Market: MarketData{Symbol: "SOL/USDT", Price: 150},
+		Portfolio: &PortfolioContext{
+			Positions: []PortfolioPosition{
+				{Symbol: "BTC/USDT", Side: "LONG", Type: "FUTURES", Notional: 3000, Leverage: 5, PnLPct: 2.5},
+				{Symbol: "ETH/USDT", Side: "LONG", Type: "SPOT", Paper: true, Notional: 1000, PnLPct: -1},
+			},
+			TotalExposure: 4000,
+			LongExposure:  4000,
+			NetExposure:   4000,
+			MarginInUse:   600,
+			Correlations:  []SymbolCorrelation{{Symbol: "BTC/USDT", Value: 0.86, Risk: "HIGH"}},
+		},
+	}
+	prompt := buildUserPrompt(input)
+	for _, want := range []string{
+		"## Your Open Portfolio\n",
+		"- BTC/USDT LONG FUTURES 5x (live): $3000 notional, PnL +2.50%\n",
+		"- ETH/USDT LONG SPOT (paper): $1000 notional, PnL -1.00%\n",
+		"- Exposure: $4000 total, $4000 long, $0 short, $4000 net\n",
+		"- Margin in use: $600\n",
+		"- Correlation with BTC/USDT: 0.86 (HIGH)\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(buildUserPrompt(&AnalysisInput{Market: input.Market}), "Open Portfolio") {
+		t.Error("the portfolio section should only appear when a portfolio is set")
+	}
+}
+
+func TestBuildUserPromptWithMarketContext(t *testing.T) {
+	input := &AnalysisInput{
+		Market: MarketData{Symbol: "SOL/USDT", Price: 150},
+		MarketContext: &MarketContext{
+			Timeframe:          "4h",
+			Window:             20,
+			BTC:                &AssetTrend{Symbol: "BTC/USDT", Price: 64000, ChangePct: -3.2, Trend: "down", Regime: "trending", ADX: 31},
+			BTCDominance:       55.4,
+			TotalMarketCap:     2.35e12,
+			MarketCapChange24h: -1.5,
+			RelativeStrength:   &RelativeStrength{SymbolChangePct: -6.1, BTCChangePct: -3.2, RelativePct: -2.9},
+		},
+	}
+	prompt := buildUserPrompt(input)
+	for _, want := range []string{
+		"## Overall Market\n",
+		"- BTC/USDT: $64000.00, -3.20% over 20 4h candles, trend down, regime trending (ADX 31.0)\n",
+		"- BTC dominance: 55.4%\n",
+		"- Total market cap: $2.35T (-1.50% 24h)\n",
+		"(underperforming by 2.90%)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestBuildUserPromptWithEvents(t *testing.T) {
+	input := &AnalysisInput{
+		Market: MarketData{Symbol: "BTC/USDT", Price: 64000},
+		Events: []CalendarEvent{
+			{Title: "US CPI", Importance: "high", Time: time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC), HoursAway: -0.5, InBlackout: true},
+			{Title: "FOMC", Importance: "high", Time: time.Date(2026, 11, 13, 19, 0, 0, 0, time.UTC), HoursAway: 29},
+		},
+	}
+	prompt := buildUserPrompt(input)
+	for _, want := range []string{
+		"## Upcoming Events\n",
+		"- US CPI (high importance): Nov 12 13:30 UTC, 0.5h ago — blackout active, new entries are blocked\n",
+		"- FOMC (high importance): Nov 13 19:00 UTC, in 29.0h\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestPromptHash(t *testing.T) {
+	input := &AnalysisInput{Market: MarketData{Symbol: "BTC/USDT", Price: 42000}}
+	same := &AnalysisInput{Market: MarketData{Symbol: "BTC/USDT", Price: 42000}}
+	moved := &AnalysisInput{Market: MarketData{Symbol: "BTC/USDT", Price: 42001}}
+
+	h := PromptHash("model-a", input)
+	if len(h) != 64 {
+		t.Fatalf("hash length = %d, want 64", len(h))
+	}
+	if h != PromptHash("model-a", same) {
+		t.Error("identical inputs should hash the same")
+	}
+	if h == PromptHash("model-a", moved) {
+		t.Error("different prompts should hash differently")
+	}
+	if h == PromptHash("model-b", input) {
+		t.Error("different models should hash differently")
+	}
+}
+
+func TestPromptVariant(t *testing.T) {
+	input := &AnalysisInput{Market: MarketData{Symbol: "BTC/USDT", Price: 42000}}
+	d := &Decision{}
+	tagPrompt(d, input)
+	if systemPrompt(input) != DefaultPrompt().System || d.PromptVersion != DefaultPromptVersion || d.PromptExperiment != "" {
+		t.Errorf("expected the default prompt, got %s/%s", d.PromptExperiment, d.PromptVersion)
+	}
+	base := PromptHash("model-a", input)
+
+	variant := *input
+	variant.Prompt = &PromptVariant{Experiment: "terse", Version: "v2", System: "Answer in JSON."}
+	tagPrompt(d, &variant)
+	if system, _ := RenderPrompt(&variant); system != "Answer in JSON." {
+		t.Errorf("expected the variant's system prompt, got %q", system)
+	}
+	if d.PromptVersion != "v2" || d.PromptExperiment != "terse" {
+		t.Errorf("expected terse/v2, got %s/%s", d.PromptExperiment, d.PromptVersion)
+	}
+	if PromptHash("model-a", &variant) == base {
+		t.Error("different prompt variants should hash differently")
+	}
+}
+
+func TestFormatStructure(t *testing.T) {
+	ms := &MarketStructure{
+		Trend:     "bullish",
+		LastEvent: &StructureEvent{Type: "BOS", Direction: "bullish", Price: 43100},
+		Supports: []PriceLevel{
+			{Price: 42000, SizeUSD: 1500000, Source: "liquidity"},
+			{Price: 41200, Touches: 3, Source: "swing"},
+		},
+		Resistances:      []PriceLevel{{Price: 44000, Touches: 2, Source: "swing"}},
+		SupportTrendline: 41800,
+	}
+	result := formatStructure(ms)
+	checks := []string{
+		"Structure trend: bullish",
+		"Last break: bullish BOS through 43100.00",
+		"Support: 42000.00 (order book wall $1500000), 41200.00 (3 touches)",
+		"Resistance: 44000.00 (2 touches)",
+		"Support trendline at 41800.00",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("structure should contain %q, got:\n%s", check, result)
+		}
+	}
+	if strings.Contains(result, "Resistance trendline") {
+		t.Error("missing resistance trendline shouldn't be shown")
+	}
+
+	prompt := buildUserPrompt(&AnalysisInput{Market: MarketData{Symbol: "BTC/USDT"}, Structure: ms})
+	if !strings.Contains(prompt, "## Market Structure") {
+		t.Error("user prompt should include the structure section")
+	}
+	if !strings.Contains(buildSystemPrompt(), "market structure") {
+		t.Error("system prompt should explain how to use market structure")
+	}
+}
+
+func TestFormatAltDataWalls(t *testing.T) {
+	alt := &AltData{OrderFlow: &OrderFlowData{
+		BuySellRatio: 1,
+		Walls:        []OrderBookWall{{Side: "bid", Price: 41950, SizeUSD: 2000000}},
+	}}
+	if result := formatAltData(alt); !strings.Contains(result, "bid wall: $2000000 at 41950.00") {
+		t.Errorf("walls should be listed, got:\n%s", result)
+	}
+}
+
+func TestFormatPatterns(t *testing.T) {
+	cp := &ChartPatterns{
+		Signal:   "bearish",
+		Strength: 0.58,
+		Patterns: []ChartPattern{
+			{Name: "head_shoulders", Direction: "bearish", Confidence: 0.68, Neckline: 41000, Target: 39500},
+			{Name: "symmetrical_triangle", Direction: "neutral", Confidence: 0.4},
+		},
+	}
+	result := formatPatterns(cp)
+	checks := []string{
+		"Overall: bearish (strength 0.58)",
+		"head_shoulders: bearish, 68% confidence, neckline 41000.00, target 39500.00",
+		"symmetrical_triangle: neutral, 40% confidence\n",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("patterns should contain %q, got:\n%s", check, result)
+		}
+	}
+
+	prompt := buildUserPrompt(&AnalysisInput{Market: MarketData{Symbol: "BTC/USDT"}, Patterns: cp})
+	if !strings.Contains(prompt, "## Chart Patterns") {
+		t.Error("user prompt should include the patterns section")
+	}
+}
+
+func TestFormatPredictionEnsemble(t *testing.T) {
+	pred := &MLPrediction{
+		Direction:  "up",
+		Magnitude:  1.5,
+		Confidence: 0.7,
+		Timeframe:  "4h",
+		Agreement:  2.0 / 3,
+		Dispersion: 1.7,
+		Models: []ModelOutput{
+			{Model: "gradient_boosting", Direction: "up", Magnitude: 3, Confidence: 0.6, Weight: 0.3},
+			{Model: "lstm", Direction: "up", Magnitude: 2, Confidence: 0.8, Weight: 0.5},
+			{Model: "random_forest", Direction: "down", Magnitude: 1, Confidence: 0.55, Weight: 0.2},
+		},
+	}
+	result := formatPrediction(pred)
+	checks := []string{
+		"Model agreement: 67% (2 of 3 models)",
+		"Dispersion: 1.70%",
+		"lstm: up 2.00%, 80% confidence, weight 0.50",
+		"random_forest: down 1.00%, 55% confidence, weight 0.20",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("prediction should contain %q, got:\n%s", check, result)
+		}
+	}
+
+	single := formatPrediction(&MLPrediction{Direction: "up", Magnitude: 1, Confidence: 0.6, Timeframe: "4h"})
+	if strings.Contains(single, "agreement") {
+		t.Errorf("single-model prediction shouldn't show agreement, got:\n%s", single)
+	}
+}
+
+func TestFormatSecondOpinion(t *testing.T) {
+	so := &SecondOpinion{Source: "rl_agent", Action: "BUY", Detail: "buy_large", Confidence: 0.64}
+	want := "- Source: rl agent\n- Action: BUY (buy_large)\n- Confidence: 64%\n"
+	if got := formatSecondOpinion(so); got != want {
+		t.Errorf("formatSecondOpinion() = %q, want %q", got, want)
+	}
+
+	prompt := buildUserPrompt(&AnalysisInput{Market: MarketData{Symbol: "BTC/USDT"}, Second: so})
+	if !strings.Contains(prompt, "## Second Opinion") {
+		t.Error("user prompt should include the second opinion section")
+	}
+	if prompt := buildUserPrompt(&AnalysisInput{Market: MarketData{Symbol: "BTC/USDT"}}); strings.Contains(prompt, "## Second Opinion") {
+		t.Error("user prompt shouldn't include a second opinion section without one")
+	}
+}
@@ -41,14 +41,72 @@ type Indicators struct {
 	StochK        float64 `json:"stoch_k"`
 	StochD        float64 `json:"stoch_d"`
 	StochSignal   string  `json:"stoch_signal"`
+
+	// multi-period emas (ascending by period) and how they're stacked
+	EMAs         []EMALevel `json:"emas,omitempty"`
+	EMAAlignment string     `json:"ema_alignment,omitempty"` // BULLISH, BEARISH, MIXED
+
+	VWAP         float64 `json:"vwap,omitempty"` // session vwap, resets each utc day
+	VWAPUpper    float64 `json:"vwap_upper,omitempty"`
+	VWAPLower    float64 `json:"vwap_lower,omitempty"`
+	VWAPAnchored float64 `json:"vwap_anchored,omitempty"`
+
+	IchimokuTenkan  float64 `json:"ichimoku_tenkan,omitempty"`
+	IchimokuKijun   float64 `json:"ichimoku_kijun,omitempty"`
+	IchimokuSenkouA float64 `json:"ichimoku_senkou_a,omitempty"`
+	IchimokuSenkouB float64 `json:"ichimoku_senkou_b,omitempty"`
+	IchimokuSignal  string  `json:"ichimoku_signal,omitempty"`   // ABOVE_CLOUD, IN_CLOUD, BELOW_CLOUD
+	IchimokuTKCross string  `json:"ichimoku_tk_cross,omitempty"` // BULLISH, BEARISH, NONE
+
+	OBVSlope      float64 `json:"obv_slope,omitempty"`
+	OBVSignal     string  `json:"obv_signal,omitempty"`     // RISING, FALLING, FLAT
+	OBVDivergence string  `json:"obv_divergence,omitempty"` // BULLISH, BEARISH, NONE
+
+	Supertrend        float64 `json:"supertrend,omitempty"`
+	SupertrendDir     string  `json:"supertrend_dir,omitempty"` // UP, DOWN
+	SupertrendFlipped bool    `json:"supertrend_flipped,omitempty"`
+
+	DonchianUpper  float64 `json:"donchian_upper,omitempty"`
+	DonchianLower  float64 `json:"donchian_lower,omitempty"`
+	DonchianSignal string  `json:"donchian_signal,omitempty"` // BREAKOUT_UP, BREAKOUT_DOWN, INSIDE
+	DonchianPeriod int     `json:"donchian_period,omitempty"`
+
+	// previous utc day's classic and fibonacci pivots
+	Pivot   float64 `json:"pivot,omitempty"`
+	PivotR1 float64 `json:"pivot_r1,omitempty"`
+	PivotR2 float64 `json:"pivot_r2,omitempty"`
+	PivotS1 float64 `json:"pivot_s1,omitempty"`
+	PivotS2 float64 `json:"pivot_s2,omitempty"`
+	FibR1   float64 `json:"fib_r1,omitempty"`
+	FibR2   float64 `json:"fib_r2,omitempty"`
+	FibS1   float64 `json:"fib_s1,omitempty"`
+	FibS2   float64 `json:"fib_s2,omitempty"`
+}
+
+// one ema in a multi-period set
+type EMALevel struct {
+	Period int     `json:"period"`
+	Value  float64 `json:"value"`
 }
 
 // ml predictions from the python service
 type MLPrediction struct {
+	Direction  string        `json:"direction"`
+	Magnitude  float64       `json:"magnitude"`
+	Confidence float64       `json:"confidence"`
+	Timeframe  string        `json:"timeframe"`
+	Models     []ModelOutput `json:"models,omitempty"`     // per-model votes when the ensemble answered
+	Agreement  float64       `json:"agreement,omitempty"`  // share of models agreeing with Direction (0-1)
+	Dispersion float64       `json:"dispersion,omitempty"` // std dev of the models' predicted moves, in %
+}
+
+// one ensemble member's prediction
+type ModelOutput struct {
+	Model      string  `json:"model"`
 	Direction  string  `json:"direction"`
 	Magnitude  float64 `json:"magnitude"`
 	Confidence float64 `json:"confidence"`
-	Timeframe  string  `json:"timeframe"`
+	Weight     float64 `json:"weight"`
 }
 
 // sentiment data from the python service
@@ -90,16 +148,158 @@ type RegimeInfo struct {
 }
 
 // bundles all context for claude to analyze
+
 type AnalysisInput struct {
-	Market       MarketData    `json:"market"`
-	Indicators   *Indicators   `json:"indicators,omitempty"`
-	Prediction   *MLPrediction `json:"prediction,omitempty"`
-	Sentiment    *Sentiment    `json:"sentiment,omitempty"`
-	Costs        *TradingCosts `json:"costs,omitempty"`
-	Regime       *RegimeInfo   `json:"regime,omitempty"`
-	AltData      *AltData      `json:"alt_data,omitempty"`       // alternative data sources
-	HTFContext   []HTFSnapshot `json:"htf_context,omitempty"`    // higher-timeframe context
-	TradeHistory []TradeOutcome `json:"trade_history,omitempty"` // recent trade outcomes for learning
+	Market        MarketData        `json:"market"`
+	Indicators    *Indicators       `json:"indicators,omitempty"`
+	Prediction    *MLPrediction     `json:"prediction,omitempty"`
+	Sentiment     *Sentiment        `json:"sentiment,omitempty"`
+	Costs         *TradingCosts     `json:"costs,omitempty"`
+	Regime        *RegimeInfo       `json:"regime,omitempty"`
+	AltData       *AltData          `json:"alt_data,omitempty"`       // alternative data sources
+	HTFContext    []HTFSnapshot     `json:"htf_context,omitempty"`    // higher-timeframe context
+	TradeHistory  []TradeOutcome    `json:"trade_history,omitempty"`  // recent trade outcomes for learning
+	Structure     *MarketStructure  `json:"structure,omitempty"`      // swing levels, trendlines, BOS/CHOCH
+	Patterns      *ChartPatterns    `json:"patterns,omitempty"`       // chart patterns from the python service
+	Second        *SecondOpinion    `json:"second_opinion,omitempty"` // advisory call from the RL agent
+	Sections      []PromptSection   `json:"sections,omitempty"`       // contributed by custom pipeline stages
+	Portfolio     *PortfolioContext `json:"portfolio,omitempty"`      // the user's open positions, set per user
+	MarketContext *MarketContext    `json:"market_context,omitempty"` // btc, eth and total market, for altcoins
+	Events        []CalendarEvent   `json:"events,omitempty"`         // scheduled macro releases and token unlocks
+	Prompt        *PromptVariant    `json:"-"`                        // system prompt to use; nil = the default
+}
+
+// a versioned system prompt, and the experiment that assigned it if any
+type PromptVariant struct {
+	Experiment string
+	Version    string
+	System     string
+}
+
+// a scheduled event that can move the market
+type CalendarEvent struct {
+	Title      string    `json:"title"`
+	Importance string    `json:"importance"` // high, medium or low
+	Time       time.Time `json:"time"`
+	HoursAway  float64   `json:"hours_away"`  // negative once it has passed
+	InBlackout bool      `json:"in_blackout"` // new entries are blocked right now
+}
+
+// what bitcoin, ethereum and the whole market are doing, for altcoin analyses
+type MarketContext struct {
+	Timeframe          string            `json:"timeframe"`
+	Window             int               `json:"window"` // candles the changes are measured over
+	BTC                *AssetTrend       `json:"btc,omitempty"`
+	ETH                *AssetTrend       `json:"eth,omitempty"`
+	BTCDominance       float64           `json:"btc_dominance,omitempty"`    // % of total market cap, 0 when unavailable
+	TotalMarketCap     float64           `json:"total_market_cap,omitempty"` // USD
+	MarketCapChange24h float64           `json:"market_cap_change_24h"`      // %
+	RelativeStrength   *RelativeStrength `json:"relative_strength,omitempty"`
+}
+
+// trend and regime of a benchmark asset
+type AssetTrend struct {
+	Symbol    string  `json:"symbol"`
+	Price     float64 `json:"price"`
+	ChangePct float64 `json:"change_pct"` // over the window
+	Trend     string  `json:"trend"`      // up, down, neutral
+	Regime    string  `json:"regime"`     // trending, ranging, volatile, quiet
+	ADX       float64 `json:"adx"`
+}
+
+// the analyzed symbol's performance against BTC over the window
+type RelativeStrength struct {
+	SymbolChangePct float64 `json:"symbol_change_pct"`
+	BTCChangePct    float64 `json:"btc_change_pct"`
+	RelativePct     float64 `json:"relative_pct"` // symbol minus BTC; positive = outperforming
+}
+
+// the user's open positions and how the candidate symbol relates to them
+type PortfolioContext struct {
+	Positions        []PortfolioPosition `json:"positions"`
+	TotalExposure    float64             `json:"total_exposure"` // notional USDT
+	LongExposure     float64             `json:"long_exposure"`
+	ShortExposure    float64             `json:"short_exposure"`
+	NetExposure      float64             `json:"net_exposure"`      // long - short
+	MarginInUse      float64             `json:"margin_in_use"`     // across futures positions
+	ConcentrationPct float64             `json:"concentration_pct"` // largest position as % of total
+	Correlations     []SymbolCorrelation `json:"correlations,omitempty"`
+	Warnings         []string            `json:"warnings,omitempty"`
+}
+
+// an open position as shown to claude
+type PortfolioPosition struct {
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"` // LONG or SHORT
+	Type     string  `json:"type"` // SPOT or FUTURES
+	Paper    bool    `json:"paper"`
+	Notional float64 `json:"notional"` // USDT
+	Leverage int     `json:"leverage,omitempty"`
+	PnLPct   float64 `json:"pnl_pct"`
+}
+
+// return correlation between the candidate symbol and a held one
+type SymbolCorrelation struct {
+	Symbol string  `json:"symbol"`
+	Value  float64 `json:"value"` // pearson, -1 to 1
+	Risk   string  `json:"risk"`  // HIGH, MEDIUM or LOW
+}
+
+// a free-form prompt section added by a pipeline stage
+type PromptSection struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// an advisory decision from another model, shown for comparison only
+type SecondOpinion struct {
+	Source     string  `json:"source"`     // e.g. rl_agent
+	Action     string  `json:"action"`     // BUY, SELL or HOLD
+	Detail     string  `json:"detail"`     // the model's own action name, e.g. buy_large
+	Confidence float64 `json:"confidence"` // 0-1
+}
+
+// chart patterns detected by the python service, highest confidence first
+type ChartPatterns struct {
+	Signal   string         `json:"signal"`   // bullish, bearish or neutral
+	Strength float64        `json:"strength"` // 0-1
+	Summary  string         `json:"summary,omitempty"`
+	Patterns []ChartPattern `json:"patterns"`
+}
+
+// a single detected pattern
+type ChartPattern struct {
+	Name       string  `json:"name"` // e.g. double_bottom, head_shoulders, bull_flag
+	Direction  string  `json:"direction"`
+	Confidence float64 `json:"confidence"`         // 0-1
+	Target     float64 `json:"target,omitempty"`   // measured-move target
+	Neckline   float64 `json:"neckline,omitempty"` // breakout level
+}
+
+// price structure: nearest support/resistance from swing clusters and order
+// book walls, trendlines and the latest structure break
+type MarketStructure struct {
+	Trend               string          `json:"trend"` // bullish, bearish, ranging
+	LastEvent           *StructureEvent `json:"last_event,omitempty"`
+	Supports            []PriceLevel    `json:"supports,omitempty"`          // nearest first
+	Resistances         []PriceLevel    `json:"resistances,omitempty"`       // nearest first
+	SupportTrendline    float64         `json:"support_trendline,omitempty"` // projected to the current bar
+	ResistanceTrendline float64         `json:"resistance_trendline,omitempty"`
+}
+
+// a break of a prior swing
+type StructureEvent struct {
+	Type      string  `json:"type"`      // BOS or CHOCH
+	Direction string  `json:"direction"` // bullish or bearish
+	Price     float64 `json:"price"`     // the swing that was broken
+}
+
+// a support or resistance price
+type PriceLevel struct {
+	Price   float64 `json:"price"`
+	Touches int     `json:"touches,omitempty"`  // swing touches
+	SizeUSD float64 `json:"size_usd,omitempty"` // resting size of a liquidity wall
+	Source  string  `json:"source"`             // swing or liquidity
 }
 
 // TradeOutcome records the result of a past AI decision for self-learning
@@ -135,11 +335,19 @@ type AltData struct {
 
 // order flow / market microstructure data
 type OrderFlowData struct {
-	BuySellRatio    float64 `json:"buy_sell_ratio"`    // >1 = more buyers
-	DepthImbalance  float64 `json:"depth_imbalance"`   // positive = buy wall
-	LargeBuyOrders  int     `json:"large_buy_orders"`
-	LargeSellOrders int     `json:"large_sell_orders"`
-	SpreadBps       float64 `json:"spread_bps"`
+	BuySellRatio    float64         `json:"buy_sell_ratio"`  // >1 = more buyers
+	DepthImbalance  float64         `json:"depth_imbalance"` // positive = buy wall
+	LargeBuyOrders  int             `json:"large_buy_orders"`
+	LargeSellOrders int             `json:"large_sell_orders"`
+	SpreadBps       float64         `json:"spread_bps"`
+	Walls           []OrderBookWall `json:"walls,omitempty"`
+}
+
+// an outsized resting order in the book
+type OrderBookWall struct {
+	Side    string  `json:"side"` // bid or ask
+	Price   float64 `json:"price"`
+	SizeUSD float64 `json:"size_usd"`
 }
 
 // on-chain metrics
@@ -180,8 +388,32 @@ type Decision struct {
 	Reasoning  string    `json:"reasoning"`
 	Timestamp  time.Time `json:"timestamp"`
 	Latency    time.Duration `json:"latency"`
+	Provider   string    `json:"provider,omitempty"` // which provider answered, e.g. anthropic or ollama
+	Model      string    `json:"model,omitempty"`
+	Usage      Usage     `json:"usage"`
+	PromptVersion    string `json:"prompt_version,omitempty"`
+	PromptExperiment string `json:"prompt_experiment,omitempty"` // set when an experiment chose the prompt
+}
+
+// tokens an ai call consumed and what they cost
+type Usage struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"` // 0 when the model has no price
+}
+
+// UsageError is a failed call that still consumed tokens, e.g. a response
+// that couldn't be parsed. The tokens are billed, so budgets count them.
+type UsageError struct {
+	Model string
+	Usage Usage
+	Err   error
 }
 
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
 // claude api message format
 type apiMessage struct {
 	Role    string `json:"role"`
@@ -221,3 +453,32 @@ type apiError struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
+
+// openai-compatible chat completions request body
+type chatRequest struct {
+	Model     string       `json:"model"`
+	MaxTokens int          `json:"max_tokens"`
+	Messages  []apiMessage `json:"messages"`
+}
+
+// openai-compatible chat completions response body
+type chatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      apiMessage `json:"message"`
+		FinishReason string     `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openai-compatible error response
+type chatError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
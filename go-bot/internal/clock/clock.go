@@ -0,0 +1,150 @@
+// time source abstraction. monitors, expiry loops, funding windows and daily
+// resets take a Clock so they can run against simulated time in tests and
+// replays instead of sleeping on the wall clock.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of the current time and of tickers.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C until stopped (mirrors time.Ticker).
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real returns the wall clock.
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{t: time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time { return r.t.C }
+func (r *realTicker) Stop()               { r.t.Stop() }
+
+// Simulated is a manually advanced clock. Time only moves on Advance or Set,
+// and tickers fire for every period crossed, in chronological order. Like
+// time.Ticker, a tick is dropped if the previous one hasn't been received.
+type Simulated struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	tickers []*simTicker
+}
+
+// NewSimulated creates a simulated clock starting at start.
+func NewSimulated(start time.Time) *Simulated {
+	s := &Simulated{now: start}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *Simulated) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+func (s *Simulated) Since(t time.Time) time.Duration {
+	return s.Now().Sub(t)
+}
+
+func (s *Simulated) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &simTicker{clock: s, period: d, next: s.now.Add(d), ch: make(chan time.Time, 1)}
+	s.tickers = append(s.tickers, t)
+	s.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by d, firing due tickers along the way.
+func (s *Simulated) Advance(d time.Duration) {
+	s.Set(s.Now().Add(d))
+}
+
+// Set moves the clock to t (never backwards), firing due tickers along the way.
+func (s *Simulated) Set(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		due := s.nextDue(t)
+		if due == nil {
+			break
+		}
+		s.now = due.next
+		select {
+		case due.ch <- due.next:
+		default:
+		}
+		due.next = due.next.Add(due.period)
+	}
+	if t.After(s.now) {
+		s.now = t
+	}
+}
+
+// BlockUntil waits until at least n tickers are active, so a test can be sure
+// a goroutine has started its loop before advancing time.
+func (s *Simulated) BlockUntil(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.tickers) < n {
+		s.cond.Wait()
+	}
+}
+
+// nextDue returns the ticker with the earliest deadline at or before t.
+func (s *Simulated) nextDue(t time.Time) *simTicker {
+	sort.SliceStable(s.tickers, func(i, j int) bool { return s.tickers[i].next.Before(s.tickers[j].next) })
+	if len(s.tickers) == 0 || s.tickers[0].next.After(t) {
+		return nil
+	}
+	return s.tickers[0]
+}
+
+func (s *Simulated) remove(t *simTicker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, st := range s.tickers {
+		if st == t {
+			s.tickers = append(s.tickers[:i], s.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type simTicker struct {
+	clock  *Simulated
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *simTicker) C() <-chan time.Time { return t.ch }
+func (t *simTicker) Stop()               { t.clock.remove(t) }
@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSimulated_NowAndSince(t *testing.T) {
+	c := NewSimulated(epoch)
+	if !c.Now().Equal(epoch) {
+		t.Fatalf("now = %v, want %v", c.Now(), epoch)
+	}
+	c.Advance(90 * time.Minute)
+	if got := c.Since(epoch); got != 90*time.Minute {
+		t.Errorf("since = %v, want 90m", got)
+	}
+
+	// Set never moves backwards
+	c.Set(epoch)
+	if got := c.Since(epoch); got != 90*time.Minute {
+		t.Errorf("since after backwards Set = %v, want 90m", got)
+	}
+}
+
+func TestSimulated_TickerFires(t *testing.T) {
+	c := NewSimulated(epoch)
+	tk := c.NewTicker(time.Minute)
+	defer tk.Stop()
+
+	c.Advance(30 * time.Second)
+	select {
+	case <-tk.C():
+		t.Fatal("ticker fired early")
+	default:
+	}
+
+	c.Advance(30 * time.Second)
+	select {
+	case at := <-tk.C():
+		if !at.Equal(epoch.Add(time.Minute)) {
+			t.Errorf("tick at %v, want %v", at, epoch.Add(time.Minute))
+		}
+	default:
+		t.Fatal("ticker did not fire")
+	}
+}
+
+func TestSimulated_TickerDropsUnreadTicks(t *testing.T) {
+	c := NewSimulated(epoch)
+	tk := c.NewTicker(time.Minute)
+
+	c.Advance(5 * time.Minute)
+	if at := <-tk.C(); !at.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("buffered tick = %v, want the first one", at)
+	}
+	select {
+	case <-tk.C():
+		t.Fatal("expected later ticks to be dropped like time.Ticker")
+	default:
+	}
+
+	tk.Stop()
+	c.Advance(5 * time.Minute)
+	select {
+	case <-tk.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestSimulated_TickersInterleave(t *testing.T) {
+	c := NewSimulated(epoch)
+	fast := c.NewTicker(time.Minute)
+	slow := c.NewTicker(3 * time.Minute)
+
+	// consume every tick as it happens to observe ordering
+	var order []string
+	for i := 0; i < 3; i++ {
+		c.Advance(time.Minute)
+		select {
+		case <-fast.C():
+			order = append(order, "fast")
+		default:
+		}
+		select {
+		case <-slow.C():
+			order = append(order, "slow")
+		default:
+		}
+	}
+	want := []string{"fast", "fast", "fast", "slow"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if !c.Now().Equal(epoch.Add(3 * time.Minute)) {
+		t.Errorf("now = %v", c.Now())
+	}
+}
+
+func TestSimulated_BlockUntil(t *testing.T) {
+	c := NewSimulated(epoch)
+	done := make(chan struct{})
+	go func() {
+		c.BlockUntil(1)
+		close(done)
+	}()
+
+	c.NewTicker(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("BlockUntil did not return after a ticker was created")
+	}
+}
+
+func TestReal(t *testing.T) {
+	c := Real()
+	before := time.Now()
+	if c.Now().Before(before) {
+		t.Error("real clock behind time.Now")
+	}
+	tk := c.NewTicker(time.Millisecond)
+	defer tk.Stop()
+	select {
+	case <-tk.C():
+	case <-time.After(time.Second):
+		t.Fatal("real ticker did not fire")
+	}
+}
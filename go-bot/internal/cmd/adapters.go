@@ -4,18 +4,25 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"math"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+	"github.com/trading-bot/go-bot/internal/analysis"
 	"github.com/trading-bot/go-bot/internal/binance"
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/claude"
 	"github.com/trading-bot/go-bot/internal/database"
 	"github.com/trading-bot/go-bot/internal/datasources"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/livetrading"
 	"github.com/trading-bot/go-bot/internal/pipeline"
+	"github.com/trading-bot/go-bot/internal/usage"
 	"github.com/trading-bot/go-bot/internal/user"
 	"github.com/trading-bot/go-bot/internal/watchlist"
 )
@@ -265,6 +272,126 @@ func (a *candleStoreAdapter) LatestTime(ctx context.Context, symbol, interval st
 	return a.repo.LatestTime(ctx, symbol, interval)
 }
 
+func (a *candleStoreAdapter) RecentCandles(ctx context.Context, symbol, interval string, limit int) ([]*pipeline.CandleRecord, error) {
+	rows, err := a.repo.GetLatest(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([]*pipeline.CandleRecord, len(rows))
+	for i, c := range rows {
+		candles[i] = &pipeline.CandleRecord{
+			Time:        c.Time,
+			Symbol:      c.Symbol,
+			Interval:    c.Interval,
+			Open:        c.Open,
+			High:        c.High,
+			Low:         c.Low,
+			Close:       c.Close,
+			Volume:      c.Volume,
+			QuoteVolume: c.QuoteVolume,
+			TradeCount:  c.TradeCount,
+		}
+	}
+	return candles, nil
+}
+
+// indicatorStateBackend is satisfied by both the postgres and redis snapshot stores.
+type indicatorStateBackend interface {
+	SaveBatch(ctx context.Context, records []*database.IndicatorStateRecord) error
+	LoadAll(ctx context.Context) ([]*database.IndicatorStateRecord, error)
+}
+
+// bridges a database snapshot store to pipeline.IndicatorStateStore.
+type indicatorStateStoreAdapter struct {
+	backend indicatorStateBackend
+}
+
+func (a *indicatorStateStoreAdapter) SaveIndicatorStates(ctx context.Context, snapshots []*pipeline.IndicatorSnapshot) error {
+	records := make([]*database.IndicatorStateRecord, len(snapshots))
+	for i, s := range snapshots {
+		records[i] = &database.IndicatorStateRecord{
+			Symbol:         s.Symbol,
+			Interval:       s.Interval,
+			State:          s.State,
+			LastCandleTime: s.LastCandle,
+		}
+	}
+	return a.backend.SaveBatch(ctx, records)
+}
+
+func (a *indicatorStateStoreAdapter) LoadIndicatorStates(ctx context.Context) ([]*pipeline.IndicatorSnapshot, error) {
+	records, err := a.backend.LoadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*pipeline.IndicatorSnapshot, len(records))
+	for i, r := range records {
+		snapshots[i] = &pipeline.IndicatorSnapshot{
+			Symbol:     r.Symbol,
+			Interval:   r.Interval,
+			LastCandle: r.LastCandleTime,
+			State:      r.State,
+		}
+	}
+	return snapshots, nil
+}
+
+// bridges database.EnsemblePredictionRepository to pipeline.EnsemblePredictionStore.
+type ensemblePredictionStoreAdapter struct {
+	repo *database.EnsemblePredictionRepository
+}
+
+func (a *ensemblePredictionStoreAdapter) SaveEnsemblePrediction(ctx context.Context, rec *pipeline.EnsemblePredictionRecord) error {
+	details := make(map[string]database.ModelVoteRecord, len(rec.Votes))
+	for _, v := range rec.Votes {
+		details[v.Model] = database.ModelVoteRecord{
+			Direction:  v.Direction,
+			Magnitude:  v.Magnitude,
+			Confidence: v.Confidence,
+			Weight:     v.Weight,
+		}
+	}
+	id, err := a.repo.Insert(ctx, &database.EnsemblePredictionRecord{
+		Symbol:         rec.Symbol,
+		Timeframe:      rec.Timeframe,
+		Direction:      rec.Direction,
+		Magnitude:      rec.Magnitude,
+		Confidence:     rec.Confidence,
+		PredictedPrice: rec.PredictedPrice,
+		CurrentPrice:   rec.CurrentPrice,
+		ModelDetails:   details,
+		HorizonAt:      rec.HorizonAt,
+	})
+	rec.ID = id
+	return err
+}
+
+func (a *ensemblePredictionStoreAdapter) DueEnsemblePredictions(ctx context.Context, now time.Time, limit int) ([]*pipeline.EnsemblePredictionRecord, error) {
+	rows, err := a.repo.Due(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*pipeline.EnsemblePredictionRecord, len(rows))
+	for i, r := range rows {
+		records[i] = &pipeline.EnsemblePredictionRecord{
+			ID:             r.ID,
+			Symbol:         r.Symbol,
+			Timeframe:      r.Timeframe,
+			Direction:      r.Direction,
+			Magnitude:      r.Magnitude,
+			Confidence:     r.Confidence,
+			PredictedPrice: r.PredictedPrice,
+			CurrentPrice:   r.CurrentPrice,
+			HorizonAt:      r.HorizonAt,
+		}
+	}
+	return records, nil
+}
+
+func (a *ensemblePredictionStoreAdapter) ResolveEnsemblePrediction(ctx context.Context, id int, realizedPrice float64, realizedDirection string) error {
+	return a.repo.Resolve(ctx, id, realizedPrice, realizedDirection)
+}
+
 // aggregates all unique symbols across all users' watchlists.
 // implements pipeline.SymbolProvider.
 type watchlistSymbolProvider struct {
@@ -296,6 +423,46 @@ func (p *watchlistSymbolProvider) ActiveSymbols(ctx context.Context) ([]string,
 	return symbols, nil
 }
 
+// coinGeckoGlobalAdapter bridges datasources.CoinGeckoProvider to pipeline.GlobalMarketProvider.
+type coinGeckoGlobalAdapter struct {
+	cg *datasources.CoinGeckoProvider
+}
+
+func (a *coinGeckoGlobalAdapter) GlobalMarket(ctx context.Context) (*pipeline.GlobalMarket, error) {
+	g, err := a.cg.GetGlobal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline.GlobalMarket{
+		TotalMarketCap:     g.TotalMarketCap,
+		MarketCapChange24h: g.MarketCapChange24h,
+		BTCDominance:       g.BTCDominance,
+	}, nil
+}
+
+// calendarEventsAdapter bridges calendar.Calendar to pipeline.EventProvider.
+type calendarEventsAdapter struct {
+	cal *calendar.Calendar
+}
+
+func (a *calendarEventsAdapter) UpcomingEvents(symbol string) []claude.CalendarEvent {
+	events := a.cal.Upcoming(symbol)
+	if len(events) == 0 {
+		return nil
+	}
+	out := make([]claude.CalendarEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, claude.CalendarEvent{
+			Title:      e.Title,
+			Importance: string(e.Importance),
+			Time:       e.Time,
+			HoursAway:  math.Round(a.cal.Until(e).Hours()*10) / 10,
+			InBlackout: a.cal.InBlackout(e),
+		})
+	}
+	return out
+}
+
 // altDataAdapter bridges datasources.Aggregator to pipeline.AltDataProvider.
 // Converts datasources.AlternativeData -> claude.AltData for the pipeline.
 type altDataAdapter struct {
@@ -318,6 +485,13 @@ func (a *altDataAdapter) Fetch(ctx context.Context, symbol string) *claude.AltDa
 			LargeSellOrders: raw.OrderFlow.LargeSellOrders,
 			SpreadBps:       raw.OrderFlow.SpreadBps,
 		}
+		for _, w := range raw.OrderFlow.Walls {
+			result.OrderFlow.Walls = append(result.OrderFlow.Walls, claude.OrderBookWall{
+				Side:    w.Side,
+				Price:   w.Price,
+				SizeUSD: w.SizeUSD,
+			})
+		}
 	}
 
 	if raw.OnChain != nil {
@@ -414,3 +588,216 @@ func (a *dcaPriceAdapter) GetPrice(ctx context.Context, symbol string) (*exchang
 	}
 	return a.rest.GetPrice(ctx, symbol)
 }
+
+// newIndicatorProvider connects to the rust engine when one is configured and
+// wraps it so failed calls fall back to the native go engine. Without an
+// address, or when the dial fails, the go engine is used on its own and the
+// dial error is returned for the caller to report.
+func newIndicatorProvider(addr string) (pipeline.IndicatorProvider, func(), error) {
+	local := analysis.NewLocal()
+	if addr == "" {
+		return local, func() {}, nil
+	}
+	grpcClient, err := analysis.NewClient(addr)
+	if err != nil {
+		return local, func() {}, err
+	}
+	return analysis.NewFallback(grpcClient, local), func() { grpcClient.Close() }, nil
+}
+
+// modelRunStoreAdapter bridges database.ModelRunRepository to pipeline.ModelRunStore
+type modelRunStoreAdapter struct {
+	repo *database.ModelRunRepository
+}
+
+func (a *modelRunStoreAdapter) SaveModelRun(ctx context.Context, rec *pipeline.ModelRunRecord) error {
+	row := &database.ModelRunRecord{
+		Symbol:              rec.Symbol,
+		Timeframe:           rec.Timeframe,
+		DriftDetected:       rec.DriftDetected,
+		DriftReason:         rec.DriftReason,
+		DriftRecommendation: rec.DriftRecommendation,
+		DriftChecks:         rec.DriftChecks,
+		Staged:              rec.Staged,
+		CandidateAccuracy:   rec.CandidateAccuracy,
+		DeployedAccuracy:    rec.DeployedAccuracy,
+		WalkForwardAccuracy: rec.WalkForwardAccuracy,
+		WalkForwardFolds:    rec.WalkForwardFolds,
+		Reason:              rec.Reason,
+		PromotedBy:          rec.PromotedBy,
+	}
+	// drifted checks get a retrain_runs row, including retrains that were skipped
+	switch rec.Outcome {
+	case pipeline.ModelRunFailed, pipeline.ModelRunRejected, pipeline.ModelRunPromoted, pipeline.ModelRunSkipped:
+		row.Outcome = rec.Outcome
+	}
+	if err := a.repo.Insert(ctx, row); err != nil {
+		return err
+	}
+	rec.ID = row.ID
+	rec.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (a *modelRunStoreAdapter) LatestStagedModelRun(ctx context.Context) (*pipeline.ModelRunRecord, error) {
+	row, err := a.repo.LatestStaged(ctx)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return &pipeline.ModelRunRecord{
+		ID:                  row.ID,
+		Symbol:              row.Symbol,
+		Timeframe:           row.Timeframe,
+		DriftDetected:       row.DriftDetected,
+		DriftReason:         row.DriftReason,
+		DriftRecommendation: row.DriftRecommendation,
+		Staged:              row.Staged,
+		CandidateAccuracy:   row.CandidateAccuracy,
+		DeployedAccuracy:    row.DeployedAccuracy,
+		WalkForwardAccuracy: row.WalkForwardAccuracy,
+		WalkForwardFolds:    row.WalkForwardFolds,
+		Outcome:             row.Outcome,
+		Reason:              row.Reason,
+		PromotedBy:          row.PromotedBy,
+		CreatedAt:           row.CreatedAt,
+	}, nil
+}
+
+func (a *modelRunStoreAdapter) MarkModelRunPromoted(ctx context.Context, id int, by string) error {
+	return a.repo.MarkPromoted(ctx, id, by)
+}
+
+// modelPromoterAdapter bridges pipeline.ModelGate to telegram.ModelPromoter
+type modelPromoterAdapter struct {
+	gate *pipeline.ModelGate
+}
+
+func (a *modelPromoterAdapter) PromoteModel(ctx context.Context, by string) (string, error) {
+	rec, err := a.gate.Promote(ctx, by)
+	if err != nil {
+		return "", err
+	}
+	msg := fmt.Sprintf("✅ promoted the ML candidate from run #%d (%s %s)", rec.ID, rec.Symbol, rec.Timeframe)
+	if rec.Reason != "" {
+		msg += "\nthe gate had held it back: " + rec.Reason
+	}
+	return msg, nil
+}
+
+// stores shared scanner analyses in redis as JSON so several bot instances
+// reuse each other's results.
+type redisResultCache struct {
+	client *database.RedisClient
+}
+
+const analysisCachePrefix = "analysis:"
+
+func (a *redisResultCache) GetResult(ctx context.Context, key string) (*pipeline.Result, error) {
+	data, err := a.client.Get(ctx, analysisCachePrefix+key)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached analysis: %w", err)
+	}
+	result := &pipeline.Result{}
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return nil, fmt.Errorf("failed to decode cached analysis: %w", err)
+	}
+	return result, nil
+}
+
+func (a *redisResultCache) SetResult(ctx context.Context, key string, result *pipeline.Result, ttl time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode analysis: %w", err)
+	}
+	if err := a.client.Set(ctx, analysisCachePrefix+key, data, ttl); err != nil {
+		return fmt.Errorf("failed to cache analysis: %w", err)
+	}
+	return nil
+}
+
+// bridges database.PreFilterRepository to pipeline.PreFilterStore.
+type preFilterStoreAdapter struct {
+	repo *database.PreFilterRepository
+}
+
+func (a *preFilterStoreAdapter) SavePreFilterCheck(ctx context.Context, check *pipeline.PreFilterCheck) error {
+	return a.repo.Insert(ctx, &database.PreFilterCheckRecord{
+		Symbol:     check.Symbol,
+		Timeframe:  check.Timeframe,
+		Score:      check.Score,
+		Threshold:  check.Threshold,
+		Skipped:    check.Skipped,
+		Components: check.Components,
+		Signals:    check.Signals,
+		Price:      check.Price,
+	})
+}
+
+// bridges database.PositionRepository to pipeline.PositionReader.
+// covers paper and live positions, spot and futures.
+type openPositionsAdapter struct {
+	repo *database.PositionRepository
+}
+
+func (a *openPositionsAdapter) OpenPositions(ctx context.Context, userID int) ([]pipeline.OpenPosition, error) {
+	rows, err := a.repo.ListByUser(ctx, userID, "OPEN", 100)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]pipeline.OpenPosition, len(rows))
+	for i, p := range rows {
+		notional := p.NotionalValue
+		if notional == 0 {
+			notional = p.PositionSize
+		}
+		positions[i] = pipeline.OpenPosition{
+			Symbol:     p.Symbol,
+			Side:       p.Side,
+			Type:       p.PositionType,
+			Paper:      p.IsPaper,
+			Quantity:   p.Quantity,
+			EntryPrice: p.EntryPrice,
+			Notional:   notional,
+			Margin:     p.Margin,
+			Leverage:   p.Leverage,
+		}
+	}
+	return positions, nil
+}
+
+// bridges database.AIDecisionRepository to usage.Store.
+type usageStoreAdapter struct {
+	repo *database.AIDecisionRepository
+}
+
+func (a *usageStoreAdapter) UsageTotals(ctx context.Context, userID int, since time.Time) (usage.Totals, error) {
+	row, err := a.repo.UsageTotals(ctx, userID, since)
+	if err != nil {
+		return usage.Totals{}, err
+	}
+	return usageTotals(row), nil
+}
+
+func (a *usageStoreAdapter) UsageBySymbol(ctx context.Context, userID int, since time.Time, limit int) ([]usage.SymbolTotals, error) {
+	rows, err := a.repo.UsageBySymbol(ctx, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	totals := make([]usage.SymbolTotals, len(rows))
+	for i, r := range rows {
+		totals[i] = usage.SymbolTotals{Symbol: r.Symbol, Totals: usageTotals(r)}
+	}
+	return totals, nil
+}
+
+func usageTotals(r *database.AIUsageRow) usage.Totals {
+	return usage.Totals{
+		Calls:        r.Calls,
+		InputTokens:  r.InputTokens,
+		OutputTokens: r.OutputTokens,
+		CostUSD:      r.CostUSD,
+	}
+}
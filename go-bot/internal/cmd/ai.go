@@ -1,20 +1,97 @@
-// ai subcommand — interactive analysis of a single symbol
+// ai subcommand — interactive analysis of a single symbol and pre-filter tuning
 package cmd
 
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/trading-bot/go-bot/internal/analysis"
 	"github.com/trading-bot/go-bot/internal/binance"
 	"github.com/trading-bot/go-bot/internal/claude"
 	"github.com/trading-bot/go-bot/internal/config"
+	"github.com/trading-bot/go-bot/internal/database"
 	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
 	"github.com/trading-bot/go-bot/internal/pipeline"
+	"github.com/trading-bot/go-bot/internal/prompts"
+	"github.com/trading-bot/go-bot/internal/usage"
 )
 
+// builds the llm fallback chain from config, in ai.providers order.
+// returns nil when no provider is configured.
+func newAIChain(cfg *config.Config) *pipeline.FallbackAI {
+	var providers []pipeline.NamedAIProvider
+	for _, name := range cfg.AI.Providers {
+		switch name {
+		case "claude":
+			if cfg.Claude.APIKey == "" {
+				continue
+			}
+			c := claude.NewClient(
+				cfg.Claude.APIKey,
+				claude.WithModel(cfg.Claude.Model),
+				claude.WithMaxTokens(cfg.Claude.MaxTokens),
+			)
+			providers = append(providers, pipeline.NamedAIProvider{Name: c.Name(), AI: c})
+		case "openai":
+			if !cfg.OpenAI.Configured() {
+				continue
+			}
+			c := claude.NewOpenAIClient(
+				cfg.OpenAI.BaseURL,
+				cfg.OpenAI.APIKey,
+				claude.WithOpenAIModel(cfg.OpenAI.Model),
+				claude.WithOpenAIMaxTokens(cfg.OpenAI.MaxTokens),
+				claude.WithOpenAIName(cfg.OpenAI.Name),
+			)
+			providers = append(providers, pipeline.NamedAIProvider{Name: c.Name(), AI: c})
+		}
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+
+	chain := pipeline.NewFallbackAI(providers...)
+	chain.SetHealthPolicy(cfg.AI.FailureThreshold, cfg.AI.Cooldown())
+	if prices, err := usage.ParsePrices(cfg.AI.Prices); err != nil {
+		slog.Warn("ai prices ignored, costs will not be tracked", "error", err)
+	} else {
+		chain.SetPricing(prices)
+	}
+	return chain
+}
+
+// loads the prompt templates and the experiment configured under prompts.*
+func newPromptSelector(cfg *config.Config) (*prompts.Selector, error) {
+	lib, err := prompts.Load(cfg.Prompts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+	var exp *prompts.Experiment
+	if cfg.Prompts.Experiment != "" {
+		variants, err := prompts.ParseVariants(cfg.Prompts.Variants)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompts.variants: %w", err)
+		}
+		exp = &prompts.Experiment{Name: cfg.Prompts.Experiment, Unit: cfg.Prompts.Unit, Variants: variants}
+	}
+	return prompts.NewSelector(lib, cfg.Prompts.Default, exp)
+}
+
+// builds the daily ai budgets and restores today's spend from the decision
+// log, so a restart doesn't hand out a fresh budget
+func newAIBudget(ctx context.Context, cfg *config.Config, decisions *database.AIDecisionRepository) *usage.Budget {
+	budget := usage.NewBudget(cfg.AI.DailyBudgetUSD, cfg.AI.GlobalDailyBudgetUSD)
+	spend, err := decisions.CostByUserSince(ctx, budget.DayStart())
+	if err != nil {
+		slog.Warn("failed to restore today's ai spend", "error", err)
+		return budget
+	}
+	budget.Seed(spend)
+	return budget
+}
+
 var aiCmd = &cobra.Command{
 	Use:   "ai",
 	Short: "AI analysis commands",
@@ -38,17 +115,12 @@ var aiAnalyzeCmd = &cobra.Command{
 		// binance client
 		binanceClient := binance.NewClient(cfg.Binance.APIURL(), cfg.Binance.Testnet)
 
-		// rust indicators (optional)
-		var indicatorProvider pipeline.IndicatorProvider
-		if cfg.RustEngine.Address != "" {
-			grpcClient, err := analysis.NewClient(cfg.RustEngine.Address)
-			if err != nil {
-				fmt.Printf("⚠️  Rust engine unavailable: %v\n", err)
-			} else {
-				defer grpcClient.Close()
-				indicatorProvider = grpcClient
-			}
+		// rust indicators (optional, native go indicators otherwise)
+		indicatorProvider, closeIndicators, err := newIndicatorProvider(cfg.RustEngine.Address)
+		if err != nil {
+			fmt.Printf("⚠️  Rust engine unavailable, using Go indicators: %v\n", err)
 		}
+		defer closeIndicators()
 
 		// ml service (optional)
 		var mlProvider pipeline.MLProvider
@@ -56,24 +128,45 @@ var aiAnalyzeCmd = &cobra.Command{
 			mlProvider = mlclient.NewClient(cfg.MLService.BaseURL)
 		}
 
-		// claude (required)
-		var aiProvider pipeline.AIProvider
-		if cfg.Claude.APIKey != "" {
-			aiProvider = claude.NewClient(
-				cfg.Claude.APIKey,
-				claude.WithModel(cfg.Claude.Model),
-				claude.WithMaxTokens(cfg.Claude.MaxTokens),
-			)
-		} else {
-			return fmt.Errorf("CLAUDE_API_KEY is required for ai analyze")
+		// llm providers (at least one required)
+		aiChain := newAIChain(cfg)
+		if aiChain == nil {
+			return fmt.Errorf("CLAUDE_API_KEY or OPENAI_BASE_URL/OPENAI_API_KEY is required for ai analyze")
 		}
 
-		pipe := pipeline.New(binanceClient, indicatorProvider, mlProvider, aiProvider)
+		pipe := pipeline.New(binanceClient, indicatorProvider, mlProvider, aiChain)
+		if patterns, ok := mlProvider.(pipeline.PatternProvider); ok {
+			pipe.SetPatterns(patterns)
+		}
+		if ensemble, ok := mlProvider.(pipeline.EnsembleProvider); ok {
+			pipe.SetEnsemble(ensemble)
+		}
+		if rl, ok := mlProvider.(pipeline.RLProvider); ok && cfg.Trading.RLSecondOpinion {
+			pipe.SetRL(rl)
+		}
 		pipe.SetTimeframes(cfg.Trading.Timeframes)
 
+		// a specific template, to try one before putting it in an experiment
+		var prompt *claude.PromptVariant
+		if aiPromptVersion != "" {
+			lib, err := prompts.Load(cfg.Prompts.Dir)
+			if err != nil {
+				return fmt.Errorf("failed to load prompt templates: %w", err)
+			}
+			t, ok := lib.Get(aiPromptVersion)
+			if !ok {
+				return fmt.Errorf("prompt version %q not found (have %v)", aiPromptVersion, lib.Versions())
+			}
+			prompt = &t
+		}
+
 		fmt.Printf("🔍 Analyzing %s...\n\n", symbol)
 
-		result, err := pipe.Analyze(ctx, symbol)
+		market, err := pipe.Prepare(ctx, symbol)
+		if err != nil {
+			return fmt.Errorf("analysis failed: %w", err)
+		}
+		result, err := pipe.Decide(ctx, market, nil, prompt)
 		if err != nil {
 			return fmt.Errorf("analysis failed: %w", err)
 		}
@@ -107,7 +200,7 @@ var aiAnalyzeCmd = &cobra.Command{
 
 		if result.Decision != nil {
 			d := result.Decision
-			fmt.Printf("\n🤖 Decision: %s | Confidence: %.0f%%\n", d.Action, d.Confidence)
+			fmt.Printf("\n🤖 Decision: %s | Confidence: %.0f%% (%s/%s)\n", d.Action, d.Confidence, d.Provider, d.Model)
 			if d.Plan.Entry > 0 {
 				fmt.Printf("   Entry: $%.2f | SL: $%.2f | TP: $%.2f\n", d.Plan.Entry, d.Plan.StopLoss, d.Plan.TakeProfit)
 				fmt.Printf("   Position: $%.0f | R/R: 1:%.1f\n", d.Plan.PositionSize, d.Plan.RiskReward)
@@ -115,9 +208,25 @@ var aiAnalyzeCmd = &cobra.Command{
 			if d.Reasoning != "" {
 				fmt.Printf("   Reasoning: %s\n", d.Reasoning)
 			}
+			if d.PromptVersion != "" {
+				fmt.Printf("   Prompt: %s\n", d.PromptVersion)
+			}
+			if d.Usage.InputTokens > 0 {
+				fmt.Printf("   Tokens: %d in / %d out | Cost: $%.4f\n", d.Usage.InputTokens, d.Usage.OutputTokens, d.Usage.CostUSD)
+			}
 		}
 
 		fmt.Printf("\n⏱️  Pipeline latency: %s\n", result.Latency.Round(time.Millisecond))
+		for _, st := range result.Stages {
+			status := "ok"
+			switch {
+			case st.Skipped:
+				status = "skipped"
+			case st.Err != "":
+				status = "failed"
+			}
+			fmt.Printf("   %-14s %8s  %s\n", st.Name, st.Latency.Round(time.Millisecond), status)
+		}
 
 		if len(result.Errors) > 0 {
 			fmt.Printf("⚠️  Warnings: %v\n", result.Errors)
@@ -127,7 +236,281 @@ var aiAnalyzeCmd = &cobra.Command{
 	},
 }
 
+var aiPromptVersion string
+
+var (
+	aiPFSymbol   string
+	aiPFDays     int
+	aiPFInterval string
+	aiPFHorizon  time.Duration
+	aiPFMove     float64
+)
+
+var aiPreFilterReportCmd = &cobra.Command{
+	Use:   "prefilter-report",
+	Short: "compare setups the pre-filter skipped with those sent to Claude",
+	Long: `Score recorded pre-filter checks against what the price did next, to tune
+trading.prefilter_min_score.
+
+The move is measured between the price at the check and the close of the
+stored --interval candle --horizon later. A move of at least --move percent
+in either direction counts as an opportunity; a skipped check followed by
+one is a missed opportunity.
+
+Examples:
+  bot ai prefilter-report
+  bot ai prefilter-report --symbol BTC/USDT --days 14 --horizon 24h --move 2`,
+	RunE: runAIPreFilterReport,
+}
+
+var (
+	aiPRExperiment string
+	aiPRDays       int
+	aiPRInterval   string
+	aiPRHorizon    time.Duration
+	aiPRCost       float64
+	aiPRAlpha      float64
+)
+
+var aiPromptReportCmd = &cobra.Command{
+	Use:   "prompt-report",
+	Short: "compare the prompt variants of an experiment",
+	Long: `Score the decisions made under a prompt experiment against what the price
+did next, per prompt variant, and test each variant against the control (the
+first of prompts.variants).
+
+The move is measured between the close of the stored --interval candle at
+the decision and the one --horizon later. BUY and SELL decisions are trades;
+a trade wins when it moved the right way by more than the --cost round trip.
+Calibration is the Brier score of the stated confidence as a win probability.
+
+Win rates are compared with a two-proportion z-test, expectancy and Brier
+scores with Welch's t-test. Differences with p below --alpha are flagged.
+
+Examples:
+  bot ai prompt-report
+  bot ai prompt-report --experiment terse-2026-11 --days 14 --horizon 24h`,
+	RunE: runAIPromptReport,
+}
+
+var aiPatternDays int
+
+var aiPatternReportCmd = &cobra.Command{
+	Use:   "pattern-report",
+	Short: "show how trades did per chart pattern present at the decision",
+	Long: `Aggregate closed trades by the chart patterns the ml service detected when
+the decision to open them was made, to see which patterns actually pay.
+
+A closed position is paired with the latest BUY/SELL decision of the same user,
+symbol and direction made shortly before it opened. A trade counts once for
+every pattern on its decision, so the rows overlap.
+
+Examples:
+  bot ai pattern-report
+  bot ai pattern-report --days 90`,
+	RunE: runAIPatternReport,
+}
+
 func init() {
+	aiAnalyzeCmd.Flags().StringVar(&aiPromptVersion, "prompt", "", "system prompt version to use (default: the built-in prompt)")
+
+	aiPromptReportCmd.Flags().StringVar(&aiPRExperiment, "experiment", "", "experiment name (default: prompts.experiment)")
+	aiPromptReportCmd.Flags().IntVar(&aiPRDays, "days", 30, "look back this many days")
+	aiPromptReportCmd.Flags().StringVar(&aiPRInterval, "interval", "1h", "stored candle interval used to price the outcome")
+	aiPromptReportCmd.Flags().DurationVar(&aiPRHorizon, "horizon", 4*time.Hour, "how long after the decision to measure the move")
+	aiPromptReportCmd.Flags().Float64Var(&aiPRCost, "cost", 0.2, "round-trip trading cost in percent taken off every trade")
+	aiPromptReportCmd.Flags().Float64Var(&aiPRAlpha, "alpha", 0.05, "significance level")
+
+	aiPreFilterReportCmd.Flags().StringVar(&aiPFSymbol, "symbol", "", "filter by trading pair")
+	aiPreFilterReportCmd.Flags().IntVar(&aiPFDays, "days", 30, "look back this many days")
+	aiPreFilterReportCmd.Flags().StringVar(&aiPFInterval, "interval", "1h", "stored candle interval used to price the outcome")
+	aiPreFilterReportCmd.Flags().DurationVar(&aiPFHorizon, "horizon", 4*time.Hour, "how long after the check to measure the move")
+	aiPreFilterReportCmd.Flags().Float64Var(&aiPFMove, "move", 1.0, "move in percent that counts as an opportunity")
+
+	aiPatternReportCmd.Flags().IntVar(&aiPatternDays, "days", 30, "look back this many days")
+
 	aiCmd.AddCommand(aiAnalyzeCmd)
+	aiCmd.AddCommand(aiPatternReportCmd)
+	aiCmd.AddCommand(aiPreFilterReportCmd)
+	aiCmd.AddCommand(aiPromptReportCmd)
 	rootCmd.AddCommand(aiCmd)
 }
+
+func runAIPreFilterReport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -aiPFDays)
+	rows, err := database.NewPreFilterRepository(pg.Pool()).Outcomes(ctx, aiPFSymbol, aiPFInterval, aiPFHorizon, since)
+	if err != nil {
+		return err
+	}
+	outcomes := make([]pipeline.PreFilterOutcome, 0, len(rows))
+	for _, r := range rows {
+		if r.PriceAt <= 0 {
+			continue
+		}
+		outcomes = append(outcomes, pipeline.PreFilterOutcome{
+			Symbol:  r.Symbol,
+			Score:   r.Score,
+			Skipped: r.Skipped,
+			MovePct: (r.PriceAfter - r.PriceAt) / r.PriceAt * 100,
+			At:      r.CheckedAt,
+		})
+	}
+	if len(outcomes) == 0 {
+		fmt.Println("no pre-filter checks with a measurable outcome yet")
+		return nil
+	}
+	report := pipeline.SummarizePreFilter(outcomes, aiPFMove)
+
+	fmt.Printf("🚦 Pre-filter — last %d days, %s horizon, ±%.2f%% move (current threshold %.0f)\n\n",
+		aiPFDays, aiPFHorizon, aiPFMove, cfg.Trading.PreFilterMinScore)
+	fmt.Printf("Checks:    %d\n", report.Checks)
+	fmt.Printf("Skipped:   %d (%.1f%%)\n", report.Skipped, report.SkipRate())
+	fmt.Printf("  missed:  %d (%.1f%% of skipped)\n", report.Missed, report.MissRate())
+	fmt.Printf("Analyzed:  %d\n", report.Analyzed)
+	if report.Analyzed > 0 {
+		fmt.Printf("  moved:   %d (%.1f%% of analyzed)\n", report.AnalyzedMoved, float64(report.AnalyzedMoved)/float64(report.Analyzed)*100)
+	}
+
+	fmt.Printf("\n%-8s %7s %8s %9s %7s %7s\n", "SCORE", "CHECKS", "SKIPPED", "ANALYZED", "MOVED", "MOVE%")
+	for _, b := range report.Bands {
+		fmt.Printf("%3d-%-4d %7d %8d %9d %7d %6.1f%%\n",
+			b.From, b.From+10, b.Checks, b.Skipped, b.Analyzed, b.Moved, float64(b.Moved)/float64(b.Checks)*100)
+	}
+	return nil
+}
+
+func runAIPromptReport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	experiment := aiPRExperiment
+	if experiment == "" {
+		experiment = cfg.Prompts.Experiment
+	}
+	if experiment == "" {
+		return fmt.Errorf("no experiment: pass --experiment or set prompts.experiment")
+	}
+
+	// the configured variants order the report when it's the running experiment
+	var versions []string
+	if experiment == cfg.Prompts.Experiment {
+		variants, err := prompts.ParseVariants(cfg.Prompts.Variants)
+		if err != nil {
+			return fmt.Errorf("invalid prompts.variants: %w", err)
+		}
+		for _, v := range variants {
+			versions = append(versions, v.Version)
+		}
+	}
+
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -aiPRDays)
+	rows, err := database.NewAIDecisionRepository(pg.Pool()).PromptOutcomes(ctx, experiment, aiPRInterval, aiPRHorizon, since)
+	if err != nil {
+		return err
+	}
+	outcomes := make([]prompts.Outcome, 0, len(rows))
+	for _, r := range rows {
+		if r.PriceAt <= 0 {
+			continue
+		}
+		outcomes = append(outcomes, prompts.Outcome{
+			Version:    r.PromptVersion,
+			Action:     r.Decision,
+			Confidence: float64(r.Confidence),
+			MovePct:    (r.PriceAfter - r.PriceAt) / r.PriceAt * 100,
+		})
+	}
+	if len(outcomes) == 0 {
+		fmt.Printf("no decisions from experiment %q with a measurable outcome yet\n", experiment)
+		return nil
+	}
+	report := prompts.Compare(outcomes, versions, aiPRCost)
+
+	fmt.Printf("🧪 Prompt experiment %s — last %d days, %s horizon, %.2f%% round-trip cost\n\n",
+		experiment, aiPRDays, aiPRHorizon, aiPRCost)
+	fmt.Printf("%-16s %9s %7s %8s %11s %8s %8s\n", "VARIANT", "DECISIONS", "TRADES", "WIN%", "EXPECTANCY", "CONF%", "BRIER")
+	for _, v := range report.Variants {
+		label := v.Version
+		if v.Version == report.Control {
+			label += " (ctl)"
+		}
+		fmt.Printf("%-16s %9d %7d %7.1f%% %+10.3f%% %7.1f%% %8.3f\n",
+			label, v.Decisions, v.Trades, v.WinRate()*100, v.Expectancy, v.AvgConfidence, v.Brier)
+	}
+
+	if len(report.Comparisons) == 0 {
+		return nil
+	}
+	mark := func(p float64) string {
+		if p < aiPRAlpha {
+			return "*"
+		}
+		return " "
+	}
+	fmt.Printf("\nvs control %s (* = p < %.2f)\n", report.Control, aiPRAlpha)
+	fmt.Printf("%-16s %16s %20s %18s\n", "VARIANT", "WIN% (p)", "EXPECTANCY (p)", "BRIER (p)")
+	for _, c := range report.Comparisons {
+		fmt.Printf("%-16s %+6.1f (%.3f)%s %+9.3f%% (%.3f)%s %+8.3f (%.3f)%s\n",
+			c.Version,
+			c.WinRateDiff, c.WinRateP, mark(c.WinRateP),
+			c.ExpectancyDiff, c.ExpectancyP, mark(c.ExpectancyP),
+			c.BrierDiff, c.BrierP, mark(c.BrierP))
+	}
+	return nil
+}
+
+func runAIPatternReport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -aiPatternDays)
+	rows, err := database.NewAIDecisionRepository(pg.Pool()).PatternPerformance(ctx, since)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("no closed trades with detected patterns yet")
+		return nil
+	}
+
+	fmt.Printf("📐 Chart patterns — closed trades of the last %d days\n\n", aiPatternDays)
+	fmt.Printf("%-24s %-9s %7s %7s %9s\n", "PATTERN", "DIRECTION", "TRADES", "WIN%", "AVG PNL")
+	for _, r := range rows {
+		fmt.Printf("%-24s %-9s %7d %6.1f%% %+8.2f%%\n",
+			r.Pattern, r.Direction, r.Trades, float64(r.Wins)/float64(r.Trades)*100, r.AvgPnLPct)
+	}
+	return nil
+}
@@ -3,15 +3,22 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/trading-bot/go-bot/internal/backtest"
 	"github.com/trading-bot/go-bot/internal/binance"
+	"github.com/trading-bot/go-bot/internal/claude"
 	"github.com/trading-bot/go-bot/internal/config"
 	"github.com/trading-bot/go-bot/internal/database"
+	"github.com/trading-bot/go-bot/internal/exchange"
+	"github.com/trading-bot/go-bot/internal/pipeline"
+	"github.com/trading-bot/go-bot/internal/usage"
 )
 
 var (
@@ -27,6 +34,51 @@ var (
 	btCSVFile   string
 	btCSVFormat string
 	btTrailPct  float64
+	btBenchmark string
+	btOutput    string // "html", "json", "csv"
+	btOutFile   string
+	btNoSave    bool
+
+	btIntrabar      string
+	btIntrabarCSV   string
+	btSlippageModel bool
+
+	btMonteCarlo int
+	btMCMethod   string
+	btRuinPct    float64
+	btMCSeed     int64
+
+	// strategy parameters
+	btStopPct        float64
+	btTargetPct      float64
+	btPositionPct    float64
+	btSMAFast        int
+	btSMASlow        int
+	btRSIPeriod      int
+	btRSIOversold    float64
+	btRSIOverbought  float64
+	btBBPeriod       int
+	btBBStdDev       float64
+	btMACDFast       int
+	btMACDSlow       int
+	btMACDSignal     int
+	btDonchianPeriod int
+	btATRPeriod      int
+	btATRStop        float64
+	btATRTarget      float64
+	btRegimeTrend    string
+	btRegimeRange    string
+	btRegimeLookback int
+
+	btAICache         string
+	btAIMaxCalls      int
+	btAIMaxCost       float64
+	btAIEvery         int
+	btAIMinConfidence float64
+	btAIDryRun        bool
+
+	btListSymbol string
+	btListLimit  int
 )
 
 var backtestCmd = &cobra.Command{
@@ -35,20 +87,54 @@ var backtestCmd = &cobra.Command{
 	Long: `Run a backtesting simulation on historical candle data.
 
 Sources: database (db), binance api (binance), or csv file (csv).
-Strategies: sma-crossover, rsi-mean-reversion.
+Strategies: sma-crossover, rsi-mean-reversion, bollinger-breakout, macd-trend,
+donchian-breakout, regime-switch, ai-pipeline.
+
+regime-switch detects the market regime (ADX/ATR) each bar and delegates to
+--regime-trend while trending and --regime-range while ranging; it stays
+flat in volatile and quiet markets.
+
+The ai-pipeline strategy replays the live decision pipeline (indicators,
+regime, higher-timeframe context and Claude) bar by bar. Decisions are
+cached by prompt hash in --ai-cache, so reruns are free and deterministic.
+The number of uncached calls and their cost are estimated up front and the
+run is refused if --ai-max-calls or --ai-max-cost would be exceeded.
+
+Each run is saved to the database (unless --no-save) so it can be
+browsed later with "bot backtest list" and "bot backtest compare".
 
 Examples:
   bot backtest --symbol BTC/USDT --interval 4h --start 2024-01-01 --end 2024-12-31 --strategy sma-crossover
-  bot backtest --source csv --csv-file data.csv --strategy rsi-mean-reversion --capital 50000`,
+  bot backtest --source csv --csv-file data.csv --strategy rsi-mean-reversion --capital 50000
+  bot backtest --strategy sma-crossover --output html --output-file report.html
+  bot backtest --strategy sma-crossover --monte-carlo 5000 --ruin-pct 30
+  bot backtest --symbol ETH/USDT --strategy macd-trend --benchmark BTC/USDT
+  bot backtest --source db --interval 4h --intrabar 1m --slippage-model
+  bot backtest --strategy donchian-breakout --donchian-period 55 --atr-stop 2 --atr-target 6
+  bot backtest --strategy regime-switch --regime-trend macd-trend --regime-range bollinger-breakout
+  bot backtest --strategy ai-pipeline --interval 4h --ai-every 6 --ai-max-calls 200 --ai-dry-run`,
 	RunE: runBacktest,
 }
 
+var backtestListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list saved backtest runs",
+	RunE:  runBacktestList,
+}
+
+var backtestCompareCmd = &cobra.Command{
+	Use:   "compare <id> <id> [id...]",
+	Short: "compare saved backtest runs side by side",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runBacktestCompare,
+}
+
 func init() {
 	backtestCmd.Flags().StringVar(&btSymbol, "symbol", "BTC/USDT", "trading pair")
 	backtestCmd.Flags().StringVar(&btInterval, "interval", "4h", "candle interval (1m,5m,15m,1h,4h,1d)")
 	backtestCmd.Flags().StringVar(&btStart, "start", "", "start date (YYYY-MM-DD)")
 	backtestCmd.Flags().StringVar(&btEnd, "end", "", "end date (YYYY-MM-DD)")
-	backtestCmd.Flags().StringVar(&btStrategy, "strategy", "sma-crossover", "strategy name (sma-crossover, rsi-mean-reversion)")
+	backtestCmd.Flags().StringVar(&btStrategy, "strategy", "sma-crossover", "strategy name (see above)")
 	backtestCmd.Flags().Float64Var(&btCapital, "capital", 10000, "initial capital in USD")
 	backtestCmd.Flags().Float64Var(&btFeeRate, "fee-rate", 0.001, "per-trade fee rate (0.001 = 0.1%)")
 	backtestCmd.Flags().Float64Var(&btSlippage, "slippage", 0.0005, "simulated slippage (0.0005 = 0.05%)")
@@ -56,13 +142,60 @@ func init() {
 	backtestCmd.Flags().StringVar(&btCSVFile, "csv-file", "", "CSV file path (required for --source csv)")
 	backtestCmd.Flags().StringVar(&btCSVFormat, "csv-format", "unix_ms", "CSV time format: unix_ms, rfc3339")
 	backtestCmd.Flags().Float64Var(&btTrailPct, "trailing-stop", 0, "trailing stop percent (0 = disabled, e.g. 0.02 = 2%)")
-
+	backtestCmd.Flags().StringVar(&btBenchmark, "benchmark", "", "benchmark symbol held buy-and-hold for comparison (e.g. ETH/USDT); buy-and-hold of --symbol is always included")
+	backtestCmd.Flags().StringVar(&btOutput, "output", "", "write a report file: html, json, csv (csv = trade list)")
+	backtestCmd.Flags().StringVar(&btOutFile, "output-file", "", "report file path (default: backtest_<symbol>_<strategy>_<timestamp>.<ext>)")
+	backtestCmd.Flags().BoolVar(&btNoSave, "no-save", false, "don't persist the run to the database")
+	backtestCmd.Flags().StringVar(&btIntrabar, "intrabar", "", "lower timeframe used to resolve bars that hit both stop and target (e.g. 1m)")
+	backtestCmd.Flags().StringVar(&btIntrabarCSV, "intrabar-csv-file", "", "CSV file with lower-timeframe candles (required for --intrabar with --source csv)")
+	backtestCmd.Flags().BoolVar(&btSlippageModel, "slippage-model", false, "price fills from recorded live slippage (requires db) instead of --slippage")
+	backtestCmd.Flags().IntVar(&btMonteCarlo, "monte-carlo", 0, "run N monte carlo resamples of the trade sequence (0 = disabled)")
+	backtestCmd.Flags().StringVar(&btMCMethod, "mc-method", backtest.MCBootstrap, "monte carlo method: bootstrap, shuffle (drawdown and ruin only)")
+	backtestCmd.Flags().Float64Var(&btRuinPct, "ruin-pct", 50, "loss of capital (percent) counted as ruin for monte carlo")
+	backtestCmd.Flags().Int64Var(&btMCSeed, "mc-seed", 0, "monte carlo random seed (0 = random)")
+
+	backtestCmd.Flags().Float64Var(&btStopPct, "stop-pct", 0.02, "stop loss as fraction of entry for percent-based strategies")
+	backtestCmd.Flags().Float64Var(&btTargetPct, "target-pct", 0.04, "take profit as fraction of entry for percent-based strategies")
+	backtestCmd.Flags().Float64Var(&btPositionPct, "position-pct", 0.2, "fraction of capital per trade")
+	backtestCmd.Flags().IntVar(&btSMAFast, "sma-fast", 10, "sma-crossover fast period")
+	backtestCmd.Flags().IntVar(&btSMASlow, "sma-slow", 30, "sma-crossover slow period")
+	backtestCmd.Flags().IntVar(&btRSIPeriod, "rsi-period", 14, "rsi-mean-reversion RSI period")
+	backtestCmd.Flags().Float64Var(&btRSIOversold, "rsi-oversold", 30, "rsi-mean-reversion buy threshold")
+	backtestCmd.Flags().Float64Var(&btRSIOverbought, "rsi-overbought", 70, "rsi-mean-reversion sell threshold")
+	backtestCmd.Flags().IntVar(&btBBPeriod, "bb-period", 20, "bollinger-breakout band period")
+	backtestCmd.Flags().Float64Var(&btBBStdDev, "bb-stddev", 2, "bollinger-breakout band width in standard deviations")
+	backtestCmd.Flags().IntVar(&btMACDFast, "macd-fast", 12, "macd-trend fast EMA period")
+	backtestCmd.Flags().IntVar(&btMACDSlow, "macd-slow", 26, "macd-trend slow EMA period")
+	backtestCmd.Flags().IntVar(&btMACDSignal, "macd-signal", 9, "macd-trend signal EMA period")
+	backtestCmd.Flags().IntVar(&btDonchianPeriod, "donchian-period", 20, "donchian-breakout channel period")
+	backtestCmd.Flags().IntVar(&btATRPeriod, "atr-period", 20, "donchian-breakout ATR period")
+	backtestCmd.Flags().Float64Var(&btATRStop, "atr-stop", 2, "donchian-breakout stop distance in ATRs")
+	backtestCmd.Flags().Float64Var(&btATRTarget, "atr-target", 4, "donchian-breakout target distance in ATRs")
+	backtestCmd.Flags().StringVar(&btRegimeTrend, "regime-trend", "donchian-breakout", "regime-switch strategy for trending markets")
+	backtestCmd.Flags().StringVar(&btRegimeRange, "regime-range", "rsi-mean-reversion", "regime-switch strategy for ranging markets")
+	backtestCmd.Flags().IntVar(&btRegimeLookback, "regime-lookback", 100, "regime-switch candles used for regime detection (min 28)")
+	backtestCmd.Flags().StringVar(&btAICache, "ai-cache", "backtest_ai_cache.json", "decision cache file for ai-pipeline (empty = no persistence)")
+	backtestCmd.Flags().IntVar(&btAIMaxCalls, "ai-max-calls", 500, "max uncached AI calls per run (0 = unlimited)")
+	backtestCmd.Flags().Float64Var(&btAIMaxCost, "ai-max-cost", 5, "max estimated AI cost in USD per run (0 = unlimited)")
+	backtestCmd.Flags().IntVar(&btAIEvery, "ai-every", 1, "ask the AI every N bars")
+	backtestCmd.Flags().Float64Var(&btAIMinConfidence, "ai-min-confidence", 60, "ignore AI decisions below this confidence")
+	backtestCmd.Flags().BoolVar(&btAIDryRun, "ai-dry-run", false, "print the AI call/cost estimate and exit")
+
+	backtestListCmd.Flags().StringVar(&btListSymbol, "symbol", "", "filter by trading pair")
+	backtestListCmd.Flags().IntVar(&btListLimit, "limit", 20, "max runs to show")
+
+	backtestCmd.AddCommand(backtestListCmd)
+	backtestCmd.AddCommand(backtestCompareCmd)
 	rootCmd.AddCommand(backtestCmd)
 }
 
 func runBacktest(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	if btOutput != "" && !backtest.ValidFormat(btOutput) {
+		return fmt.Errorf("unknown output format: %s (use html, json, or csv)", btOutput)
+	}
+
 	startTime, endTime, err := parseDateRange(btStart, btEnd)
 	if err != nil {
 		return err
@@ -76,9 +209,42 @@ func runBacktest(cmd *cobra.Command, args []string) error {
 		defer cleanup()
 	}
 
-	strategy, err := buildStrategy(btStrategy)
-	if err != nil {
-		return err
+	var (
+		strategy    backtest.Strategy
+		aiStrategy  *backtest.AIStrategy
+		benchLoader backtest.CandleLoader
+	)
+	if btStrategy == "ai-pipeline" {
+		candles, err := loader.LoadCandles(ctx, btSymbol, btInterval, startTime, endTime)
+		if err != nil {
+			return fmt.Errorf("load candles: %w", err)
+		}
+		var cleanupAI func()
+		aiStrategy, cleanupAI, err = buildAIStrategy(ctx)
+		if err != nil {
+			return err
+		}
+		defer cleanupAI()
+
+		est, err := aiStrategy.Prepare(candles)
+		if est != nil {
+			printAIEstimate(est)
+		}
+		if err != nil {
+			return err
+		}
+		if btAIDryRun {
+			return nil
+		}
+		// candles are already loaded; replay the same slice the plan was built from
+		benchLoader = loader
+		loader = backtest.NewSliceLoader(candles)
+		strategy = aiStrategy
+	} else {
+		strategy, err = buildStrategy(btStrategy)
+		if err != nil {
+			return err
+		}
 	}
 
 	cfg := backtest.Config{
@@ -90,6 +256,9 @@ func runBacktest(cmd *cobra.Command, args []string) error {
 		FeeRate:        btFeeRate,
 		Slippage:       btSlippage,
 		MaxOpenTrades:  1,
+
+		IntrabarInterval: btIntrabar,
+		Benchmark:        btBenchmark,
 	}
 	if btTrailPct > 0 {
 		cfg.TrailingStop = &backtest.TrailingStopConfig{
@@ -99,6 +268,27 @@ func runBacktest(cmd *cobra.Command, args []string) error {
 	}
 
 	engine := backtest.NewEngine(cfg, loader, strategy)
+	if benchLoader != nil {
+		engine.SetBenchmarkLoader(benchLoader)
+	}
+
+	if btIntrabar != "" && btSource == "csv" {
+		if btIntrabarCSV == "" {
+			return fmt.Errorf("--intrabar-csv-file is required for --intrabar with --source csv")
+		}
+		engine.SetIntrabarLoader(backtest.NewCSVLoader(btIntrabarCSV, btCSVFormat, true))
+	}
+
+	if btSlippageModel {
+		model, closeModel, err := loadSlippageModel(ctx, btSymbol)
+		if err != nil {
+			return err
+		}
+		defer closeModel()
+		engine.SetSlippageModel(model)
+		fmt.Printf("Slippage model: %.1f bps expected, %.1f bps worst case\n",
+			model.EstimateBps(btSymbol), model.WorstCaseBps(btSymbol))
+	}
 
 	fmt.Printf("Running backtest: %s %s [%s]\n", btSymbol, btInterval, strategy.Name())
 	fmt.Printf("Period: %s → %s | Capital: $%.2f | Fees: %.2f%%\n\n",
@@ -111,10 +301,416 @@ func runBacktest(cmd *cobra.Command, args []string) error {
 
 	metrics := backtest.ComputeMetrics(result)
 	printReport(result, metrics)
+	if aiStrategy != nil {
+		st := aiStrategy.Stats()
+		fmt.Printf("  AI: %d calls, %d cached, %d errors, %d signals\n\n", st.Calls, st.CacheHits, st.Errors, st.Signals)
+	}
+
+	report := backtest.NewReport(result, metrics)
+
+	if btMonteCarlo > 0 {
+		mc, err := backtest.RunMonteCarlo(result, backtest.MonteCarloConfig{
+			Iterations: btMonteCarlo,
+			Method:     btMCMethod,
+			Capital:    btCapital,
+			RuinPct:    btRuinPct,
+			Seed:       btMCSeed,
+		})
+		if err != nil {
+			fmt.Printf("warning: monte carlo skipped: %v\n", err)
+		} else {
+			report.MonteCarlo = mc
+			printMonteCarlo(mc)
+		}
+	}
+
+	if !btNoSave {
+		if id, err := saveBacktestRun(ctx, report); err != nil {
+			fmt.Printf("warning: backtest run not saved: %v\n", err)
+		} else {
+			report.ID = id
+			fmt.Printf("Saved as run #%d\n", id)
+		}
+	}
+
+	if btOutput != "" {
+		path := btOutFile
+		if path == "" {
+			path = defaultReportPath(report, btOutput)
+		}
+		if err := writeReportFile(report, btOutput, path); err != nil {
+			return err
+		}
+		fmt.Printf("Report written to %s\n", path)
+	}
+
+	return nil
+}
+
+func printMonteCarlo(mc *backtest.MonteCarloResult) {
+	sep := strings.Repeat("─", 55)
+
+	fmt.Printf("  MONTE CARLO (%d × %s, %.0f%% CI)\n", mc.Iterations, mc.Method, mc.Confidence*100)
+	fmt.Println(sep)
+
+	fmt.Printf("  %-15s %9s %9s %9s\n", "", "lower", "median", "upper")
+	if mc.ReturnPct != nil {
+		fmt.Printf("  %-15s %8.2f%% %8.2f%% %8.2f%%\n", "Return:",
+			mc.ReturnPct.Lower, mc.ReturnPct.Median, mc.ReturnPct.Upper)
+	}
+	fmt.Printf("  %-15s %8.2f%% %8.2f%% %8.2f%%\n", "Max Drawdown:",
+		mc.MaxDrawdownPct.Lower, mc.MaxDrawdownPct.Median, mc.MaxDrawdownPct.Upper)
+	if mc.SharpeRatio != nil {
+		fmt.Printf("  %-15s %9.2f %9.2f %9.2f\n", "Sharpe:",
+			mc.SharpeRatio.Lower, mc.SharpeRatio.Median, mc.SharpeRatio.Upper)
+	}
+	if mc.ProbSharpePositive != nil {
+		fmt.Printf("  P(Sharpe > 0):   %.1f%%\n", *mc.ProbSharpePositive*100)
+	}
+	if mc.ProbLoss != nil {
+		fmt.Printf("  P(Loss):         %.1f%%\n", *mc.ProbLoss*100)
+	}
+	fmt.Printf("  Risk of Ruin:    %.1f%% (%.0f%% loss of $%.2f)\n", mc.RiskOfRuin*100, mc.RuinPct, mc.Capital)
+
+	fmt.Println(sep)
+}
+
+// buildAIStrategy wires the live pipeline (indicators + claude) into a backtest strategy.
+// The returned cleanup saves the decision cache and closes connections.
+func buildAIStrategy(ctx context.Context) (*backtest.AIStrategy, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Claude.APIKey == "" {
+		return nil, nil, fmt.Errorf("CLAUDE_API_KEY is required for the ai-pipeline strategy")
+	}
+
+	var closers []func()
+	cleanup := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	// rust indicators when configured, native go indicators otherwise
+	indicatorProvider, closeIndicators, err := newIndicatorProvider(cfg.RustEngine.Address)
+	if err != nil {
+		fmt.Printf("warning: rust engine unavailable, using go indicators: %v\n", err)
+	}
+	closers = append(closers, closeIndicators)
+
+	ai := claude.NewClient(
+		cfg.Claude.APIKey,
+		claude.WithModel(cfg.Claude.Model),
+		claude.WithMaxTokens(cfg.Claude.MaxTokens),
+	)
+
+	// the cost estimate uses the same ai.prices table as live usage tracking
+	prices, err := usage.ParsePrices(cfg.AI.Prices)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("invalid ai.prices: %w", err)
+	}
+	price, ok := prices.Lookup(ai.Model())
+	if !ok {
+		if btAIMaxCost > 0 {
+			cleanup()
+			return nil, nil, fmt.Errorf("no ai.prices entry for %s, --ai-max-cost can't be checked", ai.Model())
+		}
+		fmt.Printf("warning: no ai.prices entry for %s, cost estimate will be $0\n", ai.Model())
+	}
+
+	// the backtest interval is the primary timeframe; configured higher ones add HTF context
+	timeframes := []string{btInterval}
+	for _, tf := range cfg.Trading.Timeframes {
+		if tf != btInterval {
+			timeframes = append(timeframes, tf)
+		}
+	}
+	pipe := pipeline.New(nil, indicatorProvider, nil, ai)
+	pipe.SetTimeframes(timeframes)
+
+	var cache backtest.DecisionCache
+	if btAICache != "" {
+		fileCache, err := backtest.LoadFileDecisionCache(btAICache)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		cache = fileCache
+		closers = append(closers, func() {
+			if err := fileCache.Save(); err != nil {
+				fmt.Printf("warning: ai decision cache not saved: %v\n", err)
+			}
+		})
+	}
+
+	strategy := backtest.NewAIStrategy(ctx, backtest.AIStrategyConfig{
+		Symbol:             btSymbol,
+		Model:              ai.Model(),
+		Lookback:           pipe.HistoryNeeded(),
+		EveryN:             btAIEvery,
+		MinConfidence:      btAIMinConfidence,
+		MaxCalls:           btAIMaxCalls,
+		MaxCostUSD:         btAIMaxCost,
+		InputPricePerMTok:  price.Input,
+		OutputPricePerMTok: price.Output,
+		OutputTokens:       cfg.Claude.MaxTokens,
+	}, pipe, ai, cache)
+	return strategy, cleanup, nil
+}
+
+func printAIEstimate(est *backtest.AICostEstimate) {
+	fmt.Printf("AI plan: %d evaluations (%d cached, %d uncached", est.Evaluations, est.CachedCalls, est.UncachedCalls)
+	if est.BuildErrors > 0 {
+		fmt.Printf(", %d skipped", est.BuildErrors)
+	}
+	fmt.Printf(")\n")
+	fmt.Printf("AI cost: ~%d input + %d output tokens ≈ $%.2f", est.EstInputTokens, est.EstOutputTokens, est.EstCostUSD)
+	if est.MaxCalls > 0 || est.MaxCostUSD > 0 {
+		fmt.Printf(" (budget: %d calls, $%.2f)", est.MaxCalls, est.MaxCostUSD)
+	}
+	fmt.Println()
+	if !est.WithinBudget {
+		fmt.Printf("AI budget exceeded: %s — raise --ai-max-calls/--ai-max-cost or use --ai-every\n", est.BudgetViolations)
+	}
+	fmt.Println()
+}
+
+// loadSlippageModel warms an adaptive slippage model from recorded live fills.
+func loadSlippageModel(ctx context.Context, symbol string) (*exchange.SlippageModel, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config for slippage model: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to db for slippage model: %w", err)
+	}
+
+	model := exchange.NewSlippageModel(exchange.NewSlippageStore(pg.Pool()), btSlippage*10000)
+	loadCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := model.LoadHistory(loadCtx, []string{symbol}); err != nil {
+		pg.Close()
+		return nil, nil, fmt.Errorf("load slippage history: %w", err)
+	}
+	return model, pg.Close, nil
+}
+
+// saveBacktestRun persists the report to the backtest_runs table.
+func saveBacktestRun(ctx context.Context, report *backtest.Report) (int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, fmt.Errorf("load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return 0, fmt.Errorf("connect to db: %w", err)
+	}
+	defer pg.Close()
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return 0, fmt.Errorf("encode report: %w", err)
+	}
+
+	m := report.Metrics
+	run := &database.BacktestRunRecord{
+		Symbol:         report.Config.Symbol,
+		Interval:       report.Config.Interval,
+		Strategy:       report.Strategy,
+		StartTime:      report.Config.StartTime,
+		EndTime:        report.Config.EndTime,
+		InitialCapital: report.Config.InitialCapital,
+		FinalEquity:    report.FinalEquity,
+		TotalReturnPct: m.TotalReturnPct,
+		SharpeRatio:    m.SharpeRatio,
+		MaxDrawdownPct: m.MaxDrawdown,
+		WinRate:        m.WinRate,
+		TotalTrades:    m.TotalTrades,
+		Report:         payload,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return database.NewBacktestRunRepository(pg.Pool()).Insert(ctx, run)
+}
+
+func defaultReportPath(report *backtest.Report, format string) string {
+	symbol := strings.ReplaceAll(report.Config.Symbol, "/", "")
+	return fmt.Sprintf("backtest_%s_%s_%s.%s",
+		symbol, report.Strategy, report.CreatedAt.Format("20060102_150405"), format)
+}
+
+func writeReportFile(report *backtest.Report, format, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create report file: %w", err)
+	}
+	if err := report.Write(f, format); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s report: %w", format, err)
+	}
+	return f.Close()
+}
+
+func openBacktestRuns() (*database.BacktestRunRepository, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	return database.NewBacktestRunRepository(pg.Pool()), pg.Close, nil
+}
+
+func runBacktestList(cmd *cobra.Command, args []string) error {
+	repo, cleanup, err := openBacktestRuns()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runs, err := repo.List(ctx, btListSymbol, btListLimit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("no saved backtest runs")
+		return nil
+	}
+
+	fmt.Printf("%-6s %-16s %-12s %-5s %-23s %10s %8s %8s %7s %6s\n",
+		"ID", "CREATED", "SYMBOL", "TF", "STRATEGY", "RETURN", "SHARPE", "MAX DD", "WIN%", "TRADES")
+	for _, r := range runs {
+		fmt.Printf("%-6d %-16s %-12s %-5s %-23s %9.2f%% %8.2f %7.2f%% %6.1f%% %6d\n",
+			r.ID, r.CreatedAt.Local().Format("2006-01-02 15:04"), r.Symbol, r.Interval, r.Strategy,
+			r.TotalReturnPct, r.SharpeRatio, r.MaxDrawdownPct, r.WinRate, r.TotalTrades)
+	}
+	return nil
+}
+
+func runBacktestCompare(cmd *cobra.Command, args []string) error {
+	ids := make([]int, len(args))
+	for i, a := range args {
+		id, err := strconv.Atoi(a)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid run id %q", a)
+		}
+		ids[i] = id
+	}
+
+	repo, cleanup, err := openBacktestRuns()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
+	reports := make([]*backtest.Report, len(ids))
+	for i, id := range ids {
+		run, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("backtest run %d not found", id)
+		}
+		var r backtest.Report
+		if err := json.Unmarshal(run.Report, &r); err != nil {
+			return fmt.Errorf("decode run %d: %w", id, err)
+		}
+		r.ID = run.ID
+		if r.Metrics == nil {
+			r.Metrics = &backtest.Metrics{}
+		}
+		reports[i] = &r
+	}
+
+	printComparison(reports)
 	return nil
 }
 
+// printComparison prints saved runs as columns, one metric per row.
+func printComparison(reports []*backtest.Report) {
+	rows := []struct {
+		label string
+		value func(r *backtest.Report) string
+	}{
+		{"Symbol", func(r *backtest.Report) string { return r.Config.Symbol }},
+		{"Interval", func(r *backtest.Report) string { return r.Config.Interval }},
+		{"Strategy", func(r *backtest.Report) string { return r.Strategy }},
+		{"Period", func(r *backtest.Report) string {
+			return r.Config.StartTime.Format("06-01-02") + "→" + r.Config.EndTime.Format("06-01-02")
+		}},
+		{"Final Equity", func(r *backtest.Report) string { return fmt.Sprintf("$%.2f", r.FinalEquity) }},
+		{"Total Return", func(r *backtest.Report) string { return fmt.Sprintf("%.2f%%", r.Metrics.TotalReturnPct) }},
+		{"Annualized", func(r *backtest.Report) string { return fmt.Sprintf("%.2f%%", r.Metrics.AnnualizedReturn) }},
+		{"Trades", func(r *backtest.Report) string { return strconv.Itoa(r.Metrics.TotalTrades) }},
+		{"Win Rate", func(r *backtest.Report) string { return fmt.Sprintf("%.1f%%", r.Metrics.WinRate) }},
+		{"Profit Factor", func(r *backtest.Report) string { return fmt.Sprintf("%.2f", r.Metrics.ProfitFactor) }},
+		{"Max Drawdown", func(r *backtest.Report) string { return fmt.Sprintf("%.2f%%", r.Metrics.MaxDrawdown) }},
+		{"Sharpe", func(r *backtest.Report) string { return fmt.Sprintf("%.2f", r.Metrics.SharpeRatio) }},
+		{"Sortino", func(r *backtest.Report) string { return fmt.Sprintf("%.2f", r.Metrics.SortinoRatio) }},
+		{"Calmar", func(r *backtest.Report) string { return fmt.Sprintf("%.2f", r.Metrics.CalmarRatio) }},
+		{"Total Fees", func(r *backtest.Report) string { return fmt.Sprintf("$%.2f", r.Metrics.TotalFees) }},
+		{"Exposure", func(r *backtest.Report) string { return fmt.Sprintf("%.1f%%", r.Metrics.ExposurePct) }},
+		{"vs Buy & Hold", func(r *backtest.Report) string {
+			if r.Metrics.BuyAndHold == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%+.2f%%", r.Metrics.BuyAndHold.ExcessReturnPct)
+		}},
+		{"Alpha / Beta", func(r *backtest.Report) string {
+			if r.Metrics.BuyAndHold == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%% / %.2f", r.Metrics.BuyAndHold.Alpha, r.Metrics.BuyAndHold.Beta)
+		}},
+		{"MC Return CI", func(r *backtest.Report) string {
+			if r.MonteCarlo == nil || r.MonteCarlo.ReturnPct == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%..%.1f%%", r.MonteCarlo.ReturnPct.Lower, r.MonteCarlo.ReturnPct.Upper)
+		}},
+		{"MC P(Sharpe>0)", func(r *backtest.Report) string {
+			if r.MonteCarlo == nil || r.MonteCarlo.ProbSharpePositive == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%", *r.MonteCarlo.ProbSharpePositive*100)
+		}},
+		{"MC Risk of Ruin", func(r *backtest.Report) string {
+			if r.MonteCarlo == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%", r.MonteCarlo.RiskOfRuin*100)
+		}},
+	}
+
+	fmt.Printf("  %-15s", "")
+	for _, r := range reports {
+		fmt.Printf(" %20s", fmt.Sprintf("run #%d", r.ID))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 17+21*len(reports)))
+	for _, row := range rows {
+		fmt.Printf("  %-15s", row.label)
+		for _, r := range reports {
+			fmt.Printf(" %20s", row.value(r))
+		}
+		fmt.Println()
+	}
+}
+
 func parseDateRange(start, end string) (time.Time, time.Time, error) {
 	layout := "2006-01-02"
 	var startTime, endTime time.Time
@@ -181,11 +777,30 @@ func buildLoader(ctx context.Context, source string) (backtest.CandleLoader, fun
 func buildStrategy(name string) (backtest.Strategy, error) {
 	switch name {
 	case "sma-crossover":
-		return backtest.NewSMACrossover(10, 30, 0.02, 0.04, 0.2), nil
+		return backtest.NewSMACrossover(btSMAFast, btSMASlow, btStopPct, btTargetPct, btPositionPct), nil
 	case "rsi-mean-reversion":
-		return backtest.NewRSIMeanReversion(14, 30, 70, 0.02, 0.04, 0.2), nil
+		return backtest.NewRSIMeanReversion(btRSIPeriod, btRSIOversold, btRSIOverbought, btStopPct, btTargetPct, btPositionPct), nil
+	case "bollinger-breakout":
+		return backtest.NewBollingerBreakout(btBBPeriod, btBBStdDev, btStopPct, btTargetPct, btPositionPct)
+	case "macd-trend":
+		return backtest.NewMACDTrend(btMACDFast, btMACDSlow, btMACDSignal, btStopPct, btTargetPct, btPositionPct)
+	case "donchian-breakout":
+		return backtest.NewDonchianBreakout(btDonchianPeriod, btATRPeriod, btATRStop, btATRTarget, btPositionPct)
+	case "regime-switch":
+		if btRegimeTrend == name || btRegimeRange == name {
+			return nil, fmt.Errorf("regime-switch cannot delegate to itself")
+		}
+		trend, err := buildStrategy(btRegimeTrend)
+		if err != nil {
+			return nil, fmt.Errorf("--regime-trend: %w", err)
+		}
+		ranging, err := buildStrategy(btRegimeRange)
+		if err != nil {
+			return nil, fmt.Errorf("--regime-range: %w", err)
+		}
+		return backtest.NewRegimeSwitch(trend, ranging, btRegimeLookback)
 	default:
-		return nil, fmt.Errorf("unknown strategy: %s (available: sma-crossover, rsi-mean-reversion)", name)
+		return nil, fmt.Errorf("unknown strategy: %s (available: sma-crossover, rsi-mean-reversion, bollinger-breakout, macd-trend, donchian-breakout, regime-switch, ai-pipeline)", name)
 	}
 }
 
@@ -197,10 +812,15 @@ func printReport(result *backtest.Result, m *backtest.Metrics) {
 	fmt.Println(sep)
 
 	fmt.Printf("  Symbol:          %s\n", result.Config.Symbol)
-	fmt.Printf("  Strategy:        %s\n", result.Config.Interval)
+	fmt.Printf("  Interval:        %s\n", result.Config.Interval)
+	fmt.Printf("  Strategy:        %s\n", result.Strategy)
 	fmt.Printf("  Period:          %s → %s\n",
 		m.StartDate.Format("2006-01-02"), m.EndDate.Format("2006-01-02"))
 	fmt.Printf("  Candles:         %d\n", result.TotalCandles)
+	if result.AmbiguousBars > 0 {
+		fmt.Printf("  Ambiguous Bars:  %d (%d resolved intrabar, rest assumed stop)\n",
+			result.AmbiguousBars, result.IntrabarResolved)
+	}
 	fmt.Printf("  Execution Time:  %s\n", result.Duration.Round(time.Millisecond))
 
 	fmt.Println(sep)
@@ -236,9 +856,35 @@ func printReport(result *backtest.Result, m *backtest.Metrics) {
 	fmt.Printf("  Sharpe Ratio:    %.2f\n", m.SharpeRatio)
 	fmt.Printf("  Sortino Ratio:   %.2f\n", m.SortinoRatio)
 	fmt.Printf("  Calmar Ratio:    %.2f\n", m.CalmarRatio)
+	fmt.Printf("  Exposure:        %.1f%%\n", m.ExposurePct)
 
 	fmt.Println(sep)
 
+	if m.BuyAndHold != nil {
+		fmt.Println("  VS BENCHMARK")
+		fmt.Println(sep)
+		fmt.Printf("  %-15s %9s %9s %8s %6s %7s %8s %8s\n", "", "return", "excess", "alpha", "beta", "IR", "up cap", "dn cap")
+		for _, bm := range []*backtest.BenchmarkMetrics{m.BuyAndHold, m.Benchmark} {
+			if bm == nil {
+				continue
+			}
+			fmt.Printf("  %-15s %8.2f%% %8.2f%% %7.2f%% %6.2f %7.2f %7.1f%% %7.1f%%\n",
+				bm.Name, bm.TotalReturnPct, bm.ExcessReturnPct, bm.Alpha, bm.Beta,
+				bm.InformationRatio, bm.UpCapture, bm.DownCapture)
+		}
+		fmt.Println(sep)
+	}
+
+	if len(m.Yearly) > 0 {
+		fmt.Println("  RETURNS BY YEAR")
+		fmt.Println(sep)
+		fmt.Printf("  %-8s %10s %10s %10s\n", "", "strategy", "buy&hold", "diff")
+		for _, y := range m.Yearly {
+			fmt.Printf("  %-8s %9.2f%% %9.2f%% %9.2f%%\n", y.Period, y.ReturnPct, y.BuyAndHoldPct, y.ReturnPct-y.BuyAndHoldPct)
+		}
+		fmt.Println(sep)
+	}
+
 	// top 5 trades
 	if len(result.Trades) > 0 {
 		fmt.Println("  TOP TRADES")
@@ -4,6 +4,8 @@ package cmd
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/trading-bot/go-bot/internal/pipeline"
+	"github.com/trading-bot/go-bot/internal/usage"
 )
 
 var (
@@ -111,3 +113,89 @@ var (
 		Help: "Total infrastructure alert events",
 	}, []string{"service", "type"}) // type=down|recovery
 )
+
+// exposes the shared analyzer's counters. called once, when the scanner starts.
+func registerSharedAnalysisMetrics(shared *pipeline.SharedAnalyzer) {
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trading_bot_analysis_cache_hits_total",
+		Help: "Scanner analyses served from the shared cache",
+	}, func() float64 { return float64(shared.Stats().Hits) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trading_bot_analysis_cache_misses_total",
+		Help: "Scanner analyses that ran the full pipeline",
+	}, func() float64 { return float64(shared.Stats().Misses) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trading_bot_analysis_inflight_joins_total",
+		Help: "Scanner analyses that waited on an identical analysis already running",
+	}, func() float64 { return float64(shared.Stats().Joined) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trading_bot_analysis_llm_calls_saved_total",
+		Help: "Claude calls avoided by sharing analyses between users",
+	}, func() float64 { return float64(shared.Stats().SavedCalls()) })
+}
+
+// exposes how many analyses the pre-filter skipped versus sent to claude.
+func registerPreFilterMetrics(filter *pipeline.PreFilter) {
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "trading_bot_prefilter_checks_total",
+		Help:        "Setups scored by the pre-filter before the Claude call",
+		ConstLabels: prometheus.Labels{"result": "skipped"},
+	}, func() float64 { skipped, _ := filter.Stats(); return float64(skipped) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "trading_bot_prefilter_checks_total",
+		Help:        "Setups scored by the pre-filter before the Claude call",
+		ConstLabels: prometheus.Labels{"result": "analyzed"},
+	}, func() float64 { _, analyzed := filter.Stats(); return float64(analyzed) })
+}
+
+// exposes per-provider health of the llm fallback chain.
+func registerAIProviderMetrics(chain *pipeline.FallbackAI) {
+	for i, h := range chain.Health() {
+		labels := prometheus.Labels{"provider": h.Name}
+
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "trading_bot_ai_provider_healthy",
+			Help:        "1 if the llm provider is usable, 0 while it is in cooldown",
+			ConstLabels: labels,
+		}, func() float64 {
+			if chain.Health()[i].Healthy {
+				return 1
+			}
+			return 0
+		})
+
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "trading_bot_ai_provider_failures_total",
+			Help:        "Failed calls to the llm provider",
+			ConstLabels: labels,
+		}, func() float64 { return float64(chain.Health()[i].Failures) })
+
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "trading_bot_ai_provider_successes_total",
+			Help:        "Decisions produced by the llm provider",
+			ConstLabels: labels,
+		}, func() float64 { return float64(chain.Health()[i].Successes) })
+	}
+}
+
+// exposes today's ai spend against the global budget.
+func registerAIBudgetMetrics(budget *usage.Budget) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "trading_bot_ai_spend_today_usd",
+		Help: "LLM spend across all users since midnight UTC",
+	}, func() float64 { return budget.Status(0).GlobalSpent })
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "trading_bot_ai_budget_exhausted",
+		Help: "1 while the global daily ai budget is spent and scans are pre-filter-only",
+	}, func() float64 {
+		if budget.Status(0).Exhausted {
+			return 1
+		}
+		return 0
+	})
+}
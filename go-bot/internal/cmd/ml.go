@@ -0,0 +1,349 @@
+// ml command — trains the python ml service's models and inspects them from
+// recorded predictions and decisions.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/trading-bot/go-bot/internal/config"
+	"github.com/trading-bot/go-bot/internal/database"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+	"github.com/trading-bot/go-bot/internal/pipeline"
+)
+
+var (
+	mlAccSymbol string
+	mlAccDays   int
+
+	mlRLSymbol   string
+	mlRLInterval string
+	mlRLFrom     string
+	mlRLTo       string
+	mlRLEpisodes int
+	mlRLBalance  float64
+
+	mlCmpSymbol    string
+	mlCmpDays      int
+	mlCmpInterval  string
+	mlCmpHorizon   time.Duration
+	mlCmpThreshold float64
+	mlCmpShow      int
+)
+
+var mlCmd = &cobra.Command{
+	Use:   "ml",
+	Short: "ml model tools",
+}
+
+var mlAccuracyCmd = &cobra.Command{
+	Use:   "accuracy",
+	Short: "show each ensemble model's realized accuracy per symbol",
+	Long: `Show the realized directional accuracy of every ensemble model, per symbol,
+next to the weight the ensemble currently gives it.
+
+Predictions are recorded by "bot run" whenever the pipeline uses the
+ensemble, and scored against the price once their horizon (one candle of
+the primary timeframe) has passed.
+
+The REVIEW column compares each model's weight with its share of the
+symbol's accuracy: "over" means the ensemble trusts the model more than its
+record supports, "under" the reverse.
+
+Examples:
+  bot ml accuracy
+  bot ml accuracy --symbol BTC/USDT --days 7`,
+	RunE: runMLAccuracy,
+}
+
+var mlRLTrainCmd = &cobra.Command{
+	Use:   "rl-train",
+	Short: "train the RL agent on stored candles",
+	Long: `Train the reinforcement-learning agent on candles from the database
+(filled by data ingestion) and log the run.
+
+The trained agent is used as an advisory second opinion when
+trading.rl_second_opinion is enabled.
+
+Examples:
+  bot ml rl-train --symbol BTC/USDT --interval 4h --from 2024-01-01 --to 2024-12-31
+  bot ml rl-train --symbol ETH/USDT --interval 1h --from 2024-06-01 --episodes 300`,
+	RunE: runMLRLTrain,
+}
+
+var mlRLCompareCmd = &cobra.Command{
+	Use:   "rl-compare",
+	Short: "compare the RL agent's second opinions with Claude's decisions",
+	Long: `Score the decisions where the RL agent and Claude disagreed against what
+the price did next.
+
+The move is measured between the close of the stored --interval candle at
+the decision and the one --horizon later. A BUY is right when the price rose
+more than --threshold percent, a SELL when it fell more than that, and a
+HOLD when it stayed within it. An analysis shared across users counts once.
+
+Examples:
+  bot ml rl-compare
+  bot ml rl-compare --symbol BTC/USDT --days 14 --horizon 24h --threshold 0.5`,
+	RunE: runMLRLCompare,
+}
+
+func init() {
+	mlAccuracyCmd.Flags().StringVar(&mlAccSymbol, "symbol", "", "filter by trading pair")
+	mlAccuracyCmd.Flags().IntVar(&mlAccDays, "days", 30, "look back this many days")
+
+	mlRLTrainCmd.Flags().StringVar(&mlRLSymbol, "symbol", "BTC/USDT", "trading pair")
+	mlRLTrainCmd.Flags().StringVar(&mlRLInterval, "interval", "4h", "candle interval")
+	mlRLTrainCmd.Flags().StringVar(&mlRLFrom, "from", "", "start date (YYYY-MM-DD, default 3 months ago)")
+	mlRLTrainCmd.Flags().StringVar(&mlRLTo, "to", "", "end date (YYYY-MM-DD, default now)")
+	mlRLTrainCmd.Flags().IntVar(&mlRLEpisodes, "episodes", 100, "training episodes (1-1000)")
+	mlRLTrainCmd.Flags().Float64Var(&mlRLBalance, "balance", 10000, "simulated starting balance in USD")
+
+	mlRLCompareCmd.Flags().StringVar(&mlCmpSymbol, "symbol", "", "filter by trading pair")
+	mlRLCompareCmd.Flags().IntVar(&mlCmpDays, "days", 30, "look back this many days")
+	mlRLCompareCmd.Flags().StringVar(&mlCmpInterval, "interval", "1h", "stored candle interval used to price the outcome")
+	mlRLCompareCmd.Flags().DurationVar(&mlCmpHorizon, "horizon", 4*time.Hour, "how long after the decision to measure the move")
+	mlRLCompareCmd.Flags().Float64Var(&mlCmpThreshold, "threshold", 0.3, "move in percent that separates BUY/SELL from HOLD")
+	mlRLCompareCmd.Flags().IntVar(&mlCmpShow, "show", 20, "most recent disagreements to list")
+
+	mlCmd.AddCommand(mlAccuracyCmd)
+	mlCmd.AddCommand(mlRLTrainCmd)
+	mlCmd.AddCommand(mlRLCompareCmd)
+	rootCmd.AddCommand(mlCmd)
+}
+
+func runMLAccuracy(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -mlAccDays)
+	rows, err := database.NewEnsemblePredictionRepository(pg.Pool()).ModelAccuracy(ctx, mlAccSymbol, since)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("no scored ensemble predictions yet")
+		return nil
+	}
+
+	review := weightReview(rows)
+	fmt.Printf("%-12s %-18s %6s %7s %8s %8s  %s\n", "SYMBOL", "MODEL", "PREDS", "ACC%", "AVG W", "LAST W", "REVIEW")
+	for _, r := range rows {
+		if r.Model == "ensemble" {
+			fmt.Printf("%-12s %-18s %6d %6.1f%% %8s %8s\n", r.Symbol, r.Model, r.Predictions, r.Accuracy(), "-", "-")
+			continue
+		}
+		fmt.Printf("%-12s %-18s %6d %6.1f%% %8.2f %8.2f  %s\n",
+			r.Symbol, r.Model, r.Predictions, r.Accuracy(), r.AvgWeight, r.LastWeight, review[r])
+	}
+	return nil
+}
+
+// weightReview flags models whose current weight differs from their share of
+// the symbol's total accuracy by more than 10 points
+func weightReview(rows []*database.ModelAccuracyRow) map[*database.ModelAccuracyRow]string {
+	const tolerance = 0.10
+
+	totals := make(map[string]float64)
+	for _, r := range rows {
+		if r.Model != "ensemble" {
+			totals[r.Symbol] += r.Accuracy()
+		}
+	}
+	review := make(map[*database.ModelAccuracyRow]string)
+	for _, r := range rows {
+		if r.Model == "ensemble" || totals[r.Symbol] == 0 {
+			continue
+		}
+		share := r.Accuracy() / totals[r.Symbol]
+		switch {
+		case r.LastWeight-share > tolerance:
+			review[r] = fmt.Sprintf("over (fair %.2f)", share)
+		case share-r.LastWeight > tolerance:
+			review[r] = fmt.Sprintf("under (fair %.2f)", share)
+		default:
+			review[r] = "ok"
+		}
+	}
+	return review
+}
+
+func runMLRLTrain(cmd *cobra.Command, args []string) error {
+	if mlRLEpisodes < 1 || mlRLEpisodes > 1000 {
+		return fmt.Errorf("--episodes must be between 1 and 1000")
+	}
+	from, to, err := parseDateRange(mlRLFrom, mlRLTo)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.MLService.BaseURL == "" {
+		return fmt.Errorf("ml_service.base_url is required for rl-train")
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+	defer cancel()
+
+	records, err := database.NewCandleRepository(pg.Pool()).GetRange(ctx, mlRLSymbol, mlRLInterval, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to load candles: %w", err)
+	}
+	if len(records) < 50 {
+		return fmt.Errorf("need at least 50 stored %s candles for %s, found %d", mlRLInterval, mlRLSymbol, len(records))
+	}
+	candles := make([]mlclient.Candle, len(records))
+	for i, r := range records {
+		candles[i] = mlclient.Candle{
+			Open:      r.Open,
+			High:      r.High,
+			Low:       r.Low,
+			Close:     r.Close,
+			Volume:    r.Volume,
+			Timestamp: r.Time.Unix(),
+		}
+	}
+
+	fmt.Printf("🧠 Training RL agent on %d %s %s candles (%s → %s), %d episodes...\n",
+		len(candles), mlRLSymbol, mlRLInterval, from.Format("2006-01-02"), to.Format("2006-01-02"), mlRLEpisodes)
+
+	client := mlclient.NewClient(cfg.MLService.BaseURL)
+	client.SetTimeout(60 * time.Minute)
+	started := time.Now()
+	resp, err := client.TrainRL(ctx, &mlclient.RLTrainRequest{
+		Candles:        candles,
+		Episodes:       mlRLEpisodes,
+		InitialBalance: mlRLBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = database.NewRLTrainingRepository(pg.Pool()).Insert(ctx, &database.RLTrainingRecord{
+		Success:         resp.Success,
+		Reason:          resp.Reason,
+		Episodes:        resp.Episodes,
+		AvgRewardLast20: resp.AvgRewardLast20,
+		AvgPnLLast20:    resp.AvgPnlLast20,
+		BestPnL:         resp.BestPnl,
+		FinalEpsilon:    resp.FinalEpsilon,
+		InitialBalance:  mlRLBalance,
+		Symbol:          mlRLSymbol,
+		Interval:        mlRLInterval,
+		From:            from,
+		To:              to,
+		CandleCount:     len(candles),
+		StartedAt:       started,
+		CompletedAt:     time.Now(),
+	})
+	if err != nil {
+		fmt.Printf("⚠️  failed to log training run: %v\n", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("rl training failed: %s", resp.Reason)
+	}
+	fmt.Printf("✅ Trained in %s\n", time.Since(started).Round(time.Second))
+	fmt.Printf("   Episodes:            %d\n", resp.Episodes)
+	fmt.Printf("   Avg reward (last 20): %.4f\n", resp.AvgRewardLast20)
+	fmt.Printf("   Avg P&L (last 20):    %.2f\n", resp.AvgPnlLast20)
+	fmt.Printf("   Best P&L:             %.2f\n", resp.BestPnl)
+	fmt.Printf("   Final epsilon:        %.3f\n", resp.FinalEpsilon)
+	return nil
+}
+
+func runMLRLCompare(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	pg, err := database.NewPostgresClient(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("postgresql connection failed: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -mlCmpDays)
+	rows, err := database.NewAIDecisionRepository(pg.Pool()).RLOpinionOutcomes(ctx, mlCmpSymbol, mlCmpInterval, mlCmpHorizon, since)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("no decisions with an RL second opinion and a measurable outcome yet")
+		return nil
+	}
+
+	outcomes := make([]pipeline.OpinionOutcome, 0, len(rows))
+	for _, r := range rows {
+		if r.PriceAt <= 0 {
+			continue
+		}
+		outcomes = append(outcomes, pipeline.OpinionOutcome{
+			DecisionID:   r.DecisionID,
+			Symbol:       r.Symbol,
+			Claude:       r.Decision,
+			RL:           r.RLAction,
+			RLConfidence: r.RLConfidence,
+			MovePct:      (r.PriceAfter - r.PriceAt) / r.PriceAt * 100,
+			At:           r.CreatedAt,
+		})
+	}
+	report := pipeline.CompareOpinions(outcomes, mlCmpThreshold)
+
+	disagreed := len(report.Disagreements)
+	fmt.Printf("🤝 Claude vs RL agent — last %d days, %s horizon, ±%.2f%% threshold\n\n", mlCmpDays, mlCmpHorizon, mlCmpThreshold)
+	fmt.Printf("Decisions:      %d\n", report.Total)
+	fmt.Printf("Agreed:         %d (%.1f%%)\n", report.Agreed, report.AgreementRate())
+	fmt.Printf("Disagreed:      %d\n", disagreed)
+	if disagreed == 0 {
+		return nil
+	}
+	pct := func(n int) float64 { return float64(n) / float64(disagreed) * 100 }
+	fmt.Printf("  Claude right: %d (%.1f%%)\n", report.ClaudeRight, pct(report.ClaudeRight))
+	fmt.Printf("  RL right:     %d (%.1f%%)\n", report.RLRight, pct(report.RLRight))
+	fmt.Printf("  Neither:      %d (%.1f%%)\n", report.NeitherRight, pct(report.NeitherRight))
+
+	shown := report.Disagreements
+	if mlCmpShow >= 0 && len(shown) > mlCmpShow {
+		shown = shown[len(shown)-mlCmpShow:]
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+	fmt.Printf("\n%-8s %-16s %-12s %-6s %-11s %8s  %s\n", "ID", "TIME", "SYMBOL", "CLAUDE", "RL", "MOVE", "RIGHT")
+	for _, d := range shown {
+		right := "neither"
+		if d.ClaudeRight {
+			right = "claude"
+		} else if d.RLRight {
+			right = "rl"
+		}
+		fmt.Printf("%-8d %-16s %-12s %-6s %-4s (%3.0f%%) %+7.2f%%  %s\n",
+			d.DecisionID, d.At.Local().Format("2006-01-02 15:04"), d.Symbol,
+			d.Claude, d.RL, d.RLConfidence*100, d.MovePct, right)
+	}
+	return nil
+}
@@ -226,20 +226,24 @@ func (a *decisionLoggerAdapter) LogDecision(ctx context.Context, userID int, sym
 	wasApproved := &approved
 
 	rec := &database.AIDecisionRecord{
-		UserID:          userID,
-		Symbol:          symbol,
-		Decision:        string(result.Decision.Action),
-		Confidence:      int(result.Decision.Confidence),
-		EntryPrice:      result.Decision.Plan.Entry,
-		StopLoss:        result.Decision.Plan.StopLoss,
-		TakeProfit:      result.Decision.Plan.TakeProfit,
-		PositionSizeUSD: result.Decision.Plan.PositionSize,
-		RiskRewardRatio: result.Decision.Plan.RiskReward,
-		Reasoning:       result.Decision.Reasoning,
-		LatencyMs:       int(result.Latency.Milliseconds()),
-		WasApproved:     wasApproved,
-		WasExecuted:     false,
-		FilterReason:    filterReason,
+		UserID:           userID,
+		Symbol:           symbol,
+		Decision:         string(result.Decision.Action),
+		Confidence:       int(result.Decision.Confidence),
+		EntryPrice:       result.Decision.Plan.Entry,
+		StopLoss:         result.Decision.Plan.StopLoss,
+		TakeProfit:       result.Decision.Plan.TakeProfit,
+		PositionSizeUSD:  result.Decision.Plan.PositionSize,
+		RiskRewardRatio:  result.Decision.Plan.RiskReward,
+		Reasoning:        result.Decision.Reasoning,
+		Provider:         result.Decision.Provider,
+		Model:            result.Decision.Model,
+		PromptVersion:    result.Decision.PromptVersion,
+		PromptExperiment: result.Decision.PromptExperiment,
+		LatencyMs:        int(result.Latency.Milliseconds()),
+		WasApproved:      wasApproved,
+		WasExecuted:      false,
+		FilterReason:     filterReason,
 	}
 
 	// populate indicator data if available
@@ -260,6 +264,11 @@ func (a *decisionLoggerAdapter) LogDecision(ctx context.Context, userID int, sym
 			"predicted_price": result.Prediction.PredictedPrice,
 			"confidence":      result.Prediction.Confidence,
 		}
+		if result.Ensemble != nil {
+			rec.MLPrediction["models"] = result.Ensemble.Votes()
+			rec.MLPrediction["agreement"] = result.Ensemble.Agreement()
+			rec.MLPrediction["dispersion"] = result.Ensemble.Dispersion()
+		}
 	}
 
 	// populate sentiment if available
@@ -270,6 +279,28 @@ func (a *decisionLoggerAdapter) LogDecision(ctx context.Context, userID int, sym
 		}
 	}
 
+	// record detected chart patterns so their outcomes can be measured
+	if result.Patterns != nil {
+		rec.PatternsData = result.Patterns.Patterns
+	}
+
+	// a shared decision's ai call was logged with the analysis that made it
+	usage := result.Decision.Usage
+	if !result.Shared {
+		rec.PromptTokens = usage.InputTokens
+		rec.CompletionTokens = usage.OutputTokens
+		rec.CostUSD = usage.CostUSD
+	}
+
+	// record the RL agent's call so `bot ml rl-compare` can score disagreements
+	if op := result.RL; op != nil && op.Confidence() > 0 {
+		rec.RLOpinion = map[string]interface{}{
+			"action":     op.Signal(),
+			"detail":     op.Action,
+			"confidence": op.Confidence(),
+		}
+	}
+
 	id, err := a.decisions.Insert(ctx, rec)
 	if err != nil {
 		slog.Error("failed to log ai decision", "symbol", symbol, "user_id", userID, "error", err)
@@ -280,10 +311,39 @@ func (a *decisionLoggerAdapter) LogDecision(ctx context.Context, userID int, sym
 	if err := a.daily.IncrementDecision(ctx, userID, approved); err != nil {
 		slog.Error("failed to increment decision stats", "user_id", userID, "error", err)
 	}
+	if rec.PromptTokens > 0 {
+		if err := a.daily.AddAIUsage(ctx, userID, rec.PromptTokens, rec.CompletionTokens, rec.CostUSD); err != nil {
+			slog.Error("failed to add ai usage stats", "user_id", userID, "error", err)
+		}
+	}
 
 	return id
 }
 
+// LogFailedAICall logs an ai call that failed after being billed as a HOLD
+// filtered with ai_failed, so usage reports and the budgets restored from
+// ai_decisions count its tokens.
+func (a *decisionLoggerAdapter) LogFailedAICall(ctx context.Context, userID int, symbol string, u claude.Usage, callErr error) {
+	approved := false
+	rec := &database.AIDecisionRecord{
+		UserID:           userID,
+		Symbol:           symbol,
+		Decision:         string(claude.ActionHold),
+		Reasoning:        callErr.Error(),
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		CostUSD:          u.CostUSD,
+		WasApproved:      &approved,
+		FilterReason:     "ai_failed",
+	}
+	if _, err := a.decisions.Insert(ctx, rec); err != nil {
+		slog.Error("failed to log failed ai call", "symbol", symbol, "user_id", userID, "error", err)
+	}
+	if err := a.daily.AddAIUsage(ctx, userID, u.InputTokens, u.OutputTokens, u.CostUSD); err != nil {
+		slog.Error("failed to add ai usage stats", "user_id", userID, "error", err)
+	}
+}
+
 func (a *decisionLoggerAdapter) IncrementNotification(ctx context.Context, userID int) {
 	if err := a.daily.IncrementNotification(ctx, userID); err != nil {
 		slog.Error("failed to increment notification stats", "user_id", userID, "error", err)
@@ -12,13 +12,12 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/trading-bot/go-bot/internal/analysis"
 	"github.com/trading-bot/go-bot/internal/api"
 	"github.com/trading-bot/go-bot/internal/autotuner"
 	"github.com/trading-bot/go-bot/internal/binance"
 	"github.com/trading-bot/go-bot/internal/bybit"
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
-	"github.com/trading-bot/go-bot/internal/claude"
 	"github.com/trading-bot/go-bot/internal/config"
 	"github.com/trading-bot/go-bot/internal/database"
 	"github.com/trading-bot/go-bot/internal/datasources"
@@ -35,6 +34,7 @@ import (
 	"github.com/trading-bot/go-bot/internal/scanner"
 	"github.com/trading-bot/go-bot/internal/security"
 	"github.com/trading-bot/go-bot/internal/telegram"
+	"github.com/trading-bot/go-bot/internal/usage"
 	"github.com/trading-bot/go-bot/internal/user"
 	"github.com/trading-bot/go-bot/internal/watchlist"
 	"github.com/trading-bot/go-bot/internal/whatsapp"
@@ -132,18 +132,17 @@ func runBot(cmd *cobra.Command, args []string) error {
 
 	// --- phase 2: analysis pipeline ---
 
-	// grpc client for rust indicators engine (optional — degrades gracefully)
-	var indicatorProvider pipeline.IndicatorProvider
-	if cfg.RustEngine.Address != "" {
-		grpcClient, err := analysis.NewClient(cfg.RustEngine.Address)
-		if err != nil {
-			log.Printf("warning: rust engine not available at %s: %v", cfg.RustEngine.Address, err)
-		} else {
-			defer grpcClient.Close()
-			indicatorProvider = grpcClient
-			log.Printf("connected to rust engine at %s", cfg.RustEngine.Address)
-		}
+	// grpc client for rust indicators engine (optional — falls back to native go indicators)
+	indicatorProvider, closeIndicators, err := newIndicatorProvider(cfg.RustEngine.Address)
+	switch {
+	case err != nil:
+		log.Printf("warning: rust engine not available at %s, using go indicators: %v", cfg.RustEngine.Address, err)
+	case cfg.RustEngine.Address != "":
+		log.Printf("connected to rust engine at %s", cfg.RustEngine.Address)
+	default:
+		log.Println("rust engine not configured, using go indicators")
 	}
+	defer closeIndicators()
 
 	// http client for python ml service (optional)
 	var mlProvider pipeline.MLProvider
@@ -154,20 +153,76 @@ func runBot(cmd *cobra.Command, args []string) error {
 		log.Printf("ml service configured at %s", cfg.MLService.BaseURL)
 	}
 
-	// claude ai client
+	// llm providers — claude first, openai-compatible backups in ai.providers order
 	var aiProvider pipeline.AIProvider
-	if cfg.Claude.APIKey != "" {
-		claudeClient := claude.NewClient(
-			cfg.Claude.APIKey,
-			claude.WithModel(cfg.Claude.Model),
-			claude.WithMaxTokens(cfg.Claude.MaxTokens),
-		)
-		aiProvider = claudeClient
-		log.Println("claude ai client initialized")
+	if aiChain := newAIChain(cfg); aiChain != nil {
+		aiProvider = aiChain
+		registerAIProviderMetrics(aiChain)
+		names := make([]string, 0, len(aiChain.Health()))
+		for _, h := range aiChain.Health() {
+			names = append(names, h.Name)
+		}
+		log.Printf("ai providers initialized: %v", names)
 	}
 
 	// assemble the analysis pipeline
 	pipe := pipeline.New(binanceClient, indicatorProvider, mlProvider, aiProvider)
+	var modelGate *pipeline.ModelGate
+	if mlClient != nil {
+		pipe.SetPatterns(mlClient)
+
+		// prefer the ensemble, and score each model's votes once their horizon passes
+		pipe.SetEnsemble(mlClient)
+		predictionTracker := pipeline.NewPredictionTracker(
+			&ensemblePredictionStoreAdapter{repo: database.NewEnsemblePredictionRepository(pg.Pool())},
+			binanceClient,
+		)
+		pipe.SetPredictionTracker(predictionTracker)
+		go predictionTracker.Run(ctx, 5*time.Minute)
+
+		// the RL agent's call as an advisory second opinion
+		if cfg.Trading.RLSecondOpinion {
+			pipe.SetRL(mlClient)
+		}
+
+		// retrained models are staged and only promoted through the walk-forward
+		// gate; training calls get their own client with a long timeout
+		trainClient := mlclient.NewClient(cfg.MLService.BaseURL)
+		trainClient.SetTimeout(15 * time.Minute)
+		modelGate = pipeline.NewModelGate(
+			trainClient,
+			&modelRunStoreAdapter{repo: database.NewModelRunRepository(pg.Pool())},
+			pipeline.GateConfig{
+				MinAccuracy:    cfg.Trading.MLPromoteMinAccuracy,
+				MinImprovement: cfg.Trading.MLPromoteMinImprovement,
+				Splits:         cfg.Trading.MLWalkForwardSplits,
+			},
+		)
+	}
+
+	// streaming indicator state — fed by data ingestion, read by the pipeline,
+	// snapshotted so it survives restarts
+	indicatorState := pipeline.NewIndicatorState(binanceClient)
+	switch cfg.Trading.IndicatorStateStore {
+	case "postgres":
+		indicatorState.SetStore(&indicatorStateStoreAdapter{backend: database.NewIndicatorStateRepository(pg.Pool())})
+	case "redis":
+		redisClient, err := database.NewRedisClient(cfg.Redis)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		defer redisClient.Close()
+		indicatorState.SetStore(&indicatorStateStoreAdapter{backend: database.NewRedisIndicatorStateStore(redisClient)})
+	}
+	if n, err := indicatorState.Restore(ctx); err != nil {
+		slog.Warn("indicator state restore failed, starting cold", "error", err)
+	} else if n > 0 {
+		log.Printf("restored %d indicator streams", n)
+	}
+	pipe.SetIndicatorState(indicatorState)
+	if cfg.Trading.IndicatorSnapshotMinutes > 0 {
+		go indicatorState.Run(ctx, time.Duration(cfg.Trading.IndicatorSnapshotMinutes)*time.Minute)
+	}
 
 	// --- alternative data sources ---
 
@@ -232,6 +287,34 @@ func runBot(cmd *cobra.Command, args []string) error {
 	pipe.SetTimeframes(cfg.Trading.Timeframes)
 	log.Printf("pipeline configured with alt data + multi-timeframe %v", cfg.Trading.Timeframes)
 
+	// btc, eth and total-market context for altcoin analyses, refreshed once per scan cycle
+	if cfg.Trading.MarketContext {
+		marketContext := pipeline.NewMarketContextProvider(binanceClient, cfg.Trading.Timeframes[0], cfg.Trading.ScannerInterval())
+		marketContext.SetGlobal(&coinGeckoGlobalAdapter{cg: datasources.NewCoinGeckoProvider(cfg.DataSources.CoinGeckoAPIKey)})
+		pipe.SetMarketContext(marketContext)
+	}
+
+	// event calendar — blackouts around macro releases and token unlocks
+	var eventCalendar *calendar.Calendar
+	if cfg.Calendar.Enabled {
+		eventCalendar = newEventCalendar(cfg.Calendar)
+		if err := eventCalendar.Refresh(ctx); err != nil {
+			slog.Warn("event calendar: initial load failed", "error", err)
+		}
+		go eventCalendar.Run(ctx, cfg.Calendar.RefreshInterval())
+		pipe.SetEvents(&calendarEventsAdapter{cal: eventCalendar})
+		log.Printf("event calendar enabled (%d events loaded)", len(eventCalendar.Events()))
+	}
+
+	// skip claude for setups too weak to act on
+	if cfg.Trading.PreFilterMinScore > 0 {
+		preFilter := pipeline.NewPreFilter(cfg.Trading.PreFilterMinScore)
+		preFilter.SetStore(&preFilterStoreAdapter{repo: database.NewPreFilterRepository(pg.Pool())})
+		pipe.SetPreFilter(preFilter)
+		registerPreFilterMetrics(preFilter)
+		log.Printf("pre-filter enabled (min setup score %.0f)", cfg.Trading.PreFilterMinScore)
+	}
+
 	// slippage model (adaptive, learns from trade fills)
 	slippageStore := exchange.NewSlippageStore(pg.Pool())
 	slippageModel := exchange.NewSlippageModel(slippageStore, 5.0)
@@ -273,6 +356,7 @@ func runBot(cmd *cobra.Command, args []string) error {
 	// trade logging repositories (shared by all executors and scanner)
 	tradeRepo := database.NewTradeRepository(pg.Pool())
 	dailyStatsRepo := database.NewDailyStatsRepository(pg.Pool())
+	decisionRepo := database.NewAIDecisionRepository(pg.Pool())
 	paperExecutor.SetTradeLogger(&spotTradeLoggerAdapter{trades: tradeRepo, daily: dailyStatsRepo})
 
 	paperMonitor := papertrading.NewMonitor(paperExecutor, prices, papertrading.DefaultMonitorConfig())
@@ -362,10 +446,21 @@ func runBot(cmd *cobra.Command, args []string) error {
 	log.Printf("portfolio circuit breaker enabled (daily loss limit: $%.0f, max consecutive losses: %d, cooldown: %s)",
 		cbConfig.MaxDailyLoss, cbConfig.MaxConsecutiveLosses, cbConfig.CooldownDuration)
 
+	// event blackouts refuse new entries on every executor
+	if eventCalendar != nil {
+		paperExecutor.SetCalendar(eventCalendar)
+		liveExecutor.SetCalendar(eventCalendar)
+		levPaperExecutor.SetCalendar(eventCalendar)
+		levLiveExecutor.SetCalendar(eventCalendar)
+	}
+
 	// --- DCA executor ---
 	dcaCfg := dca.DefaultConfig()
 	dcaPrices := &dcaPriceAdapter{ws: wsCache, rest: binanceClient}
 	dcaExecutor := dca.NewExecutor(dcaCfg, dcaPrices)
+	if eventCalendar != nil {
+		dcaExecutor.SetCalendar(eventCalendar)
+	}
 	dcaExecutor.Start()
 	defer dcaExecutor.Stop()
 	log.Println("DCA executor started")
@@ -397,6 +492,12 @@ func runBot(cmd *cobra.Command, args []string) error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	// llm spend — daily budgets per user and overall, restored from today's
+	// decisions; the scanner goes pre-filter-only once one is spent
+	aiBudget := newAIBudget(ctx, cfg, decisionRepo)
+	usageReporter := usage.NewReporter(&usageStoreAdapter{repo: decisionRepo}, aiBudget)
+	registerAIBudgetMetrics(aiBudget)
+
 	// --- start telegram bot ---
 
 	var telegramBot *telegram.Bot
@@ -408,6 +509,15 @@ func runBot(cmd *cobra.Command, args []string) error {
 		handler.SetExchangeTestnet("binance", cfg.Binance.Testnet)
 		handler.SetExchangeTestnet("bybit", cfg.Bybit.Testnet)
 		handler.SetExchangeRegistry(exchangeRegistry)
+		handler.SetUsageReporter(usageReporter)
+		if modelGate != nil && cfg.Telegram.AdminChatID != 0 {
+			handler.SetModelPromoter(&modelPromoterAdapter{gate: modelGate}, cfg.Telegram.AdminChatID)
+			modelGate.SetNotifier(func(text string) {
+				if err := telegramBot.SendMessage(cfg.Telegram.AdminChatID, text); err != nil {
+					slog.Warn("failed to send model promotion alert via telegram", "error", err)
+				}
+			})
+		}
 
 		handler.SetTradingDeps(&telegram.TradingDeps{
 			OppManager:       oppManager,
@@ -527,21 +637,61 @@ func runBot(cmd *cobra.Command, args []string) error {
 		scannerCfg.DefaultMinConfidence = cfg.Trading.DefaultConfidenceThreshold
 	}
 
-	bgScanner := scanner.New(userSvc, watchSvc, prefsSvc, pipe, notifier, scannerCfg)
+	// candle repository (shared by portfolio context, analytics API and data ingestion)
+	candleRepo := database.NewCandleRepository(pg.Pool())
+
+	// users watching the same symbol share one analysis per cycle; their own
+	// confidence, duplicate and daily-limit filters run on the shared result
+	sharedAnalyzer := pipeline.NewSharedAnalyzer(pipe, cfg.Trading.Timeframes[0], time.Duration(cfg.Trading.AnalysisCacheSeconds)*time.Second)
+	if cfg.Trading.AnalysisCacheStore == "redis" {
+		analysisRedis, err := database.NewRedisClient(cfg.Redis)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		defer analysisRedis.Close()
+		sharedAnalyzer.SetCache(&redisResultCache{client: analysisRedis})
+	}
+	// users holding positions get a decision that accounts for them; the
+	// market data under it is still shared
+	if cfg.Trading.PortfolioContext {
+		sharedAnalyzer.SetPortfolios(pipeline.NewPortfolioBuilder(
+			&openPositionsAdapter{repo: posRepo},
+			&candleStoreAdapter{repo: candleRepo},
+			cfg.Trading.Timeframes[0],
+		))
+	}
+	sharedAnalyzer.SetBudget(aiBudget)
+	decisionLogger := &decisionLoggerAdapter{decisions: decisionRepo, daily: dailyStatsRepo}
+	sharedAnalyzer.SetFailedCallLogger(decisionLogger)
+	// prompt experiments — each analysis asks with the variant its user or
+	// symbol is assigned, and the decision records it
+	promptSelector, err := newPromptSelector(cfg)
+	if err != nil {
+		return err
+	}
+	sharedAnalyzer.SetPrompts(promptSelector)
+	if exp := promptSelector.Experiment(); exp != nil {
+		log.Printf("prompt experiment %s running (per %s, variants %v)", exp.Name, exp.Unit, cfg.Prompts.Variants)
+	}
+	registerSharedAnalysisMetrics(sharedAnalyzer)
+
+	bgScanner := scanner.New(userSvc, watchSvc, prefsSvc, sharedAnalyzer, notifier, scannerCfg)
 
 	// wire decision logging to persist AI decisions and daily stats
-	decisionRepo := database.NewAIDecisionRepository(pg.Pool())
-	bgScanner.SetLogger(&decisionLoggerAdapter{decisions: decisionRepo, daily: dailyStatsRepo})
+	bgScanner.SetLogger(decisionLogger)
+	if eventCalendar != nil {
+		bgScanner.SetEntryGuard(eventCalendar)
+	}
 
 	// self-learning: feed recent trade outcomes to Claude
 	pipe.SetTradeHistory(&tradeHistoryAdapter{repo: decisionRepo})
 
-	// candle repository (shared by analytics API and data ingestion)
-	candleRepo := database.NewCandleRepository(pg.Pool())
-
 	// --- analytics REST API ---
 	if cfg.API.Enabled {
 		apiSrv := api.NewServer(posRepo, tradeRepo, decisionRepo, dailyStatsRepo, candleRepo, cfg.API.Key)
+		apiSrv.SetBacktestRuns(database.NewBacktestRunRepository(pg.Pool()))
+		apiSrv.SetModelRuns(database.NewModelRunRepository(pg.Pool()))
+		apiSrv.SetUsage(usageReporter)
 		apiSrv.RegisterRoutes(httpMux)
 		log.Println("analytics API enabled on :8080/api/*")
 	}
@@ -555,59 +705,23 @@ func runBot(cmd *cobra.Command, args []string) error {
 	ingestCfg := pipeline.DefaultIngestionConfig()
 	ingestCfg.Intervals = cfg.Trading.Timeframes
 	dataIngest := pipeline.NewDataIngestion(binanceClient, &candleStoreAdapter{repo: candleRepo}, symbolProvider, ingestCfg)
+	dataIngest.SetIndicatorState(indicatorState)
 	dataIngest.Start(ctx)
 	defer dataIngest.Stop()
 	log.Printf("data ingestion started (%s poll interval, timeframes %v)", ingestCfg.PollInterval, ingestCfg.Intervals)
 
-	// --- drift detection + auto-retrain loop (if ML service available) ---
-	// drift check is lightweight (statistical tests on recent candles) — runs every hour.
-	// retrain is expensive — only triggers when drift is actually detected.
-	if mlClient != nil {
+	// --- drift monitor + gated retrain (if ML service available) ---
+	// drift checks are lightweight (statistical tests on stored candles) and run for
+	// every watched symbol and timeframe. retrain is expensive — it only runs for the
+	// scopes that drifted, and the candidate is only promoted once walk-forward
+	// validation clears the gate.
+	if modelGate != nil {
 		driftInterval := time.Duration(cfg.Trading.DriftCheckIntervalMinutes) * time.Minute
-		primaryTF := cfg.Trading.Timeframes[0]
-		go func() {
-			ticker := time.NewTicker(driftInterval)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					driftCtx, driftCancel := context.WithTimeout(ctx, 2*time.Minute)
-					candles, err := binanceClient.GetCandles(driftCtx, "BTC/USDT", primaryTF, 100)
-					if err != nil {
-						slog.Warn("drift check: failed to fetch candles", "error", err)
-						driftCancel()
-						continue
-					}
-					mlCandles := make([]mlclient.Candle, len(candles))
-					for i, c := range candles {
-						mlCandles[i] = mlclient.Candle{
-							Open: c.Open, High: c.High, Low: c.Low,
-							Close: c.Close, Volume: c.Volume, Timestamp: c.OpenTime.Unix(),
-						}
-					}
-					driftResp, err := mlClient.CheckDrift(driftCtx, &mlclient.DriftCheckRequest{Candles: mlCandles})
-					if err != nil {
-						slog.Warn("drift check failed", "error", err)
-						driftCancel()
-						continue
-					}
-					slog.Info("drift check complete", "detected", driftResp.DriftDetected, "reason", driftResp.Reason)
-					if driftResp.DriftDetected {
-						slog.Warn("concept drift detected, triggering retrain", "recommendation", driftResp.Recommendation)
-						retrainResp, err := mlClient.Retrain(driftCtx, &mlclient.RetrainRequest{Candles: mlCandles, Epochs: 50})
-						if err != nil {
-							slog.Error("auto-retrain failed", "error", err)
-						} else {
-							slog.Info("auto-retrain complete", "success", retrainResp.Success, "promoted", retrainResp.Promoted)
-						}
-					}
-					driftCancel()
-				}
-			}
-		}()
-		log.Printf("drift detection loop started (%dm interval, %s candles, retrain on drift only)", cfg.Trading.DriftCheckIntervalMinutes, primaryTF)
+		driftMonitor := pipeline.NewDriftMonitor(modelGate, symbolProvider, &candleStoreAdapter{repo: candleRepo},
+			cfg.Trading.Timeframes, pipeline.DefaultDriftMonitorConfig())
+		go driftMonitor.Run(ctx, driftInterval)
+		log.Printf("drift monitor started (%dm interval, watchlist x %v, promote at >=%.0f%% walk-forward accuracy)",
+			cfg.Trading.DriftCheckIntervalMinutes, cfg.Trading.Timeframes, cfg.Trading.MLPromoteMinAccuracy*100)
 	}
 
 	// --- monitor event routing ---
@@ -792,6 +906,30 @@ func routePaperEvent(event papertrading.Event, tgBot *telegram.Bot, notifier *sc
 	}
 }
 
+// builds the event calendar from config: blackout windows per importance,
+// a local file and an optional http source
+func newEventCalendar(cc config.CalendarConfig) *calendar.Calendar {
+	windows := map[calendar.Importance]calendar.Window{}
+	for imp, m := range map[calendar.Importance][2]int{
+		calendar.High:   {cc.HighBeforeMinutes, cc.HighAfterMinutes},
+		calendar.Medium: {cc.MediumBeforeMinutes, cc.MediumAfterMinutes},
+		calendar.Low:    {cc.LowBeforeMinutes, cc.LowAfterMinutes},
+	} {
+		if m[0] > 0 || m[1] > 0 {
+			windows[imp] = calendar.Window{Before: time.Duration(m[0]) * time.Minute, After: time.Duration(m[1]) * time.Minute}
+		}
+	}
+
+	cal := calendar.New(calendar.Config{Windows: windows, Lookahead: time.Duration(cc.LookaheadHours) * time.Hour})
+	if cc.File != "" {
+		cal.AddSource(calendar.NewFileSource(cc.File))
+	}
+	if cc.URL != "" {
+		cal.AddSource(calendar.NewHTTPSource(cc.URL))
+	}
+	return cal
+}
+
 // routes live trading events to the appropriate notification channel
 func routeLiveEvent(event livetrading.Event, tgBot *telegram.Bot, notifier *scannerNotifier) {
 	if event.Position == nil {
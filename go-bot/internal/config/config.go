@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,12 +20,16 @@ type Config struct {
 	Binance     BinanceConfig
 	Bybit       BybitConfig
 	Claude      ClaudeConfig
+	OpenAI      OpenAIConfig
+	AI          AIConfig
 	Trading     TradingConfig
 	RustEngine  RustEngineConfig
 	MLService   MLServiceConfig
 	Leverage    LeverageConfig
 	API         APIConfig
 	DataSources DataSourcesConfig
+	Calendar    CalendarConfig
+	Prompts     PromptsConfig
 	LogLevel    string
 }
 
@@ -35,6 +40,36 @@ type DataSourcesConfig struct {
 	CoinGeckoAPIKey  string // optional — empty for free tier
 }
 
+// holds the system prompt templates and the prompt experiment, if any
+type PromptsConfig struct {
+	Dir        string   // <version>.txt templates on top of the built-in v1
+	Default    string   // version used outside the experiment
+	Experiment string   // name recorded with each decision; empty = no experiment
+	Unit       string   // what is assigned to a variant: user or symbol
+	Variants   []string // "version=weight", the first is the control
+}
+
+// holds event calendar settings — blackout windows around macro releases
+// and token unlocks, in minutes before/after the event (0/0 = no blackout)
+type CalendarConfig struct {
+	Enabled             bool
+	File                string // local .yaml/.yml or .ics file
+	URL                 string // optional http source serving the same formats
+	RefreshMinutes      int
+	HighBeforeMinutes   int
+	HighAfterMinutes    int
+	MediumBeforeMinutes int
+	MediumAfterMinutes  int
+	LowBeforeMinutes    int
+	LowAfterMinutes     int
+	LookaheadHours      int // how far ahead upcoming events are shown to claude
+}
+
+// returns the calendar refresh interval as a duration
+func (c CalendarConfig) RefreshInterval() time.Duration {
+	return time.Duration(c.RefreshMinutes) * time.Minute
+}
+
 // holds postgres connection settings
 type DatabaseConfig struct {
 	Host     string
@@ -151,6 +186,39 @@ type ClaudeConfig struct {
 	MaxTokens int
 }
 
+// holds settings for an openai-compatible provider — openai itself, or a
+// local ollama/vllm server via its base url
+type OpenAIConfig struct {
+	BaseURL   string // empty = api.openai.com
+	APIKey    string // empty for local servers
+	Model     string
+	MaxTokens int
+	Name      string // provider name recorded with decisions, e.g. "ollama"
+}
+
+// configured reports whether the provider has somewhere to send requests
+func (o OpenAIConfig) Configured() bool {
+	return o.BaseURL != "" || o.APIKey != ""
+}
+
+// holds the ai provider fallback chain
+type AIConfig struct {
+	Providers        []string // tried in order; unconfigured ones are skipped
+	FailureThreshold int      // consecutive failures before a provider cools down
+	CooldownSeconds  int
+
+	// "model=input/output" in USD per million tokens; a model matches the
+	// longest entry it starts with, and unlisted models cost nothing
+	Prices               []string
+	DailyBudgetUSD       float64 // per user; 0 = unlimited
+	GlobalDailyBudgetUSD float64 // across all users; 0 = unlimited
+}
+
+// returns the provider cooldown as a duration
+func (a AIConfig) Cooldown() time.Duration {
+	return time.Duration(a.CooldownSeconds) * time.Second
+}
+
 // holds grpc connection settings for the rust indicators engine
 type RustEngineConfig struct {
 	Address string
@@ -169,6 +237,17 @@ type TradingConfig struct {
 	OpportunityExpiryMinutes   int
 	Timeframes                 []string // primary + confirmation timeframes, e.g. ["4h", "1d"]
 	DriftCheckIntervalMinutes  int      // how often to run ML drift detection (minutes)
+	IndicatorStateStore        string   // where streaming indicator snapshots go: postgres, redis or none
+	IndicatorSnapshotMinutes   int      // how often to snapshot streaming indicator state (minutes)
+	RLSecondOpinion            bool     // show claude the RL agent's action as an advisory second opinion
+	MLPromoteMinAccuracy       float64  // minimum walk-forward direction accuracy (0-1) to promote a retrained model
+	MLPromoteMinImprovement    float64  // minimum accuracy gain (0-1) over the deployed model to promote
+	MLWalkForwardSplits        int      // walk-forward folds run before promotion
+	AnalysisCacheSeconds       int      // how long scanner analyses are shared between users (0 = only dedupe in-flight)
+	AnalysisCacheStore         string   // where shared analyses are cached: memory or redis
+	PreFilterMinScore          float64  // setups scoring below this (0-100) skip claude (0 = off)
+	PortfolioContext           bool     // show claude each user's open positions and their correlation to the symbol
+	MarketContext              bool     // show claude btc/eth trends, dominance and total market cap for altcoins
 }
 
 // returns the scanner interval as a duration
@@ -244,6 +323,22 @@ func Load() (*Config, error) {
 			Model:     viper.GetString("claude.model"),
 			MaxTokens: viper.GetInt("claude.max_tokens"),
 		},
+		OpenAI: OpenAIConfig{
+			BaseURL:   viper.GetString("openai.base_url"),
+			APIKey:    viper.GetString("openai.api_key"),
+			Model:     viper.GetString("openai.model"),
+			MaxTokens: viper.GetInt("openai.max_tokens"),
+			Name:      viper.GetString("openai.name"),
+		},
+		AI: AIConfig{
+			Providers:        parseStringSlice("ai.providers"),
+			FailureThreshold: viper.GetInt("ai.failure_threshold"),
+			CooldownSeconds:  viper.GetInt("ai.cooldown_seconds"),
+
+			Prices:               parseStringSlice("ai.prices"),
+			DailyBudgetUSD:       viper.GetFloat64("ai.daily_budget_usd"),
+			GlobalDailyBudgetUSD: viper.GetFloat64("ai.global_daily_budget_usd"),
+		},
 		RustEngine: RustEngineConfig{
 			Address: viper.GetString("rust_engine.address"),
 		},
@@ -257,6 +352,17 @@ func Load() (*Config, error) {
 			OpportunityExpiryMinutes:   viper.GetInt("trading.opportunity_expiry_minutes"),
 			Timeframes:                 parseStringSlice("trading.timeframes"),
 			DriftCheckIntervalMinutes:  viper.GetInt("trading.drift_check_interval_minutes"),
+			IndicatorStateStore:        viper.GetString("trading.indicator_state_store"),
+			IndicatorSnapshotMinutes:   viper.GetInt("trading.indicator_snapshot_minutes"),
+			RLSecondOpinion:            viper.GetBool("trading.rl_second_opinion"),
+			MLPromoteMinAccuracy:       viper.GetFloat64("trading.ml_promote_min_accuracy"),
+			MLPromoteMinImprovement:    viper.GetFloat64("trading.ml_promote_min_improvement"),
+			MLWalkForwardSplits:        viper.GetInt("trading.ml_walk_forward_splits"),
+			AnalysisCacheSeconds:       viper.GetInt("trading.analysis_cache_seconds"),
+			AnalysisCacheStore:         viper.GetString("trading.analysis_cache_store"),
+			PreFilterMinScore:          viper.GetFloat64("trading.prefilter_min_score"),
+			PortfolioContext:           viper.GetBool("trading.portfolio_context"),
+			MarketContext:              viper.GetBool("trading.market_context"),
 		},
 		Leverage: LeverageConfig{
 			HardMaxLeverage:         viper.GetInt("leverage.hard_max_leverage"),
@@ -276,6 +382,26 @@ func Load() (*Config, error) {
 			CoinGlassAPIKey:  viper.GetString("datasources.coinglass_api_key"),
 			CoinGeckoAPIKey:  viper.GetString("datasources.coingecko_api_key"),
 		},
+		Calendar: CalendarConfig{
+			Enabled:             viper.GetBool("calendar.enabled"),
+			File:                viper.GetString("calendar.file"),
+			URL:                 viper.GetString("calendar.url"),
+			RefreshMinutes:      viper.GetInt("calendar.refresh_minutes"),
+			HighBeforeMinutes:   viper.GetInt("calendar.high_before_minutes"),
+			HighAfterMinutes:    viper.GetInt("calendar.high_after_minutes"),
+			MediumBeforeMinutes: viper.GetInt("calendar.medium_before_minutes"),
+			MediumAfterMinutes:  viper.GetInt("calendar.medium_after_minutes"),
+			LowBeforeMinutes:    viper.GetInt("calendar.low_before_minutes"),
+			LowAfterMinutes:     viper.GetInt("calendar.low_after_minutes"),
+			LookaheadHours:      viper.GetInt("calendar.lookahead_hours"),
+		},
+		Prompts: PromptsConfig{
+			Dir:        viper.GetString("prompts.dir"),
+			Default:    viper.GetString("prompts.default"),
+			Experiment: viper.GetString("prompts.experiment"),
+			Unit:       viper.GetString("prompts.unit"),
+			Variants:   parseStringSlice("prompts.variants"),
+		},
 		LogLevel: viper.GetString("log_level"),
 	}
 
@@ -334,6 +460,27 @@ func setDefaults() {
 	viper.SetDefault("claude.model", "claude-sonnet-4-20250514")
 	viper.SetDefault("claude.max_tokens", 4096)
 
+	// openai-compatible provider
+	viper.SetDefault("openai.base_url", "")
+	viper.SetDefault("openai.api_key", "")
+	viper.SetDefault("openai.model", "gpt-4o")
+	viper.SetDefault("openai.max_tokens", 4096)
+	viper.SetDefault("openai.name", "openai")
+
+	// ai provider fallback chain
+	viper.SetDefault("ai.providers", []string{"claude", "openai"})
+	viper.SetDefault("ai.failure_threshold", 3)
+	viper.SetDefault("ai.cooldown_seconds", 300)
+	viper.SetDefault("ai.prices", []string{
+		"claude-sonnet-4=3/15",
+		"claude-opus-4=15/75",
+		"claude-3-5-haiku=0.8/4",
+		"gpt-4o-mini=0.15/0.6",
+		"gpt-4o=2.5/10",
+	})
+	viper.SetDefault("ai.daily_budget_usd", 0)
+	viper.SetDefault("ai.global_daily_budget_usd", 0)
+
 	// rust engine (grpc indicators)
 	viper.SetDefault("rust_engine.address", "localhost:50051")
 
@@ -347,6 +494,17 @@ func setDefaults() {
 	viper.SetDefault("trading.opportunity_expiry_minutes", 15)
 	viper.SetDefault("trading.timeframes", []string{"4h", "1d"})
 	viper.SetDefault("trading.drift_check_interval_minutes", 60)
+	viper.SetDefault("trading.indicator_state_store", "postgres")
+	viper.SetDefault("trading.indicator_snapshot_minutes", 5)
+	viper.SetDefault("trading.rl_second_opinion", false)
+	viper.SetDefault("trading.ml_promote_min_accuracy", 0.52)
+	viper.SetDefault("trading.ml_promote_min_improvement", 0.01)
+	viper.SetDefault("trading.ml_walk_forward_splits", 5)
+	viper.SetDefault("trading.analysis_cache_seconds", 240)
+	viper.SetDefault("trading.analysis_cache_store", "memory")
+	viper.SetDefault("trading.prefilter_min_score", 25)
+	viper.SetDefault("trading.portfolio_context", true)
+	viper.SetDefault("trading.market_context", true)
 
 	// leverage
 	viper.SetDefault("leverage.hard_max_leverage", 20)
@@ -356,6 +514,26 @@ func setDefaults() {
 	viper.SetDefault("leverage.liquidation_auto_close_pct", 2)
 	viper.SetDefault("leverage.monitor_interval_seconds", 30)
 
+	// event calendar
+	viper.SetDefault("calendar.enabled", false)
+	viper.SetDefault("calendar.file", "events.yaml")
+	viper.SetDefault("calendar.url", "")
+	viper.SetDefault("calendar.refresh_minutes", 30)
+	viper.SetDefault("calendar.high_before_minutes", 60)
+	viper.SetDefault("calendar.high_after_minutes", 120)
+	viper.SetDefault("calendar.medium_before_minutes", 30)
+	viper.SetDefault("calendar.medium_after_minutes", 60)
+	viper.SetDefault("calendar.low_before_minutes", 0)
+	viper.SetDefault("calendar.low_after_minutes", 0)
+	viper.SetDefault("calendar.lookahead_hours", 48)
+
+	// prompt templates and experiments
+	viper.SetDefault("prompts.dir", "prompts")
+	viper.SetDefault("prompts.default", "v1")
+	viper.SetDefault("prompts.experiment", "")
+	viper.SetDefault("prompts.unit", "user")
+	viper.SetDefault("prompts.variants", []string{})
+
 	// logging
 	viper.SetDefault("log_level", "info")
 }
@@ -401,6 +579,89 @@ func Validate(cfg *Config) error {
 			return fmt.Errorf("trading.timeframes contains invalid timeframe %q", tf)
 		}
 	}
+	switch cfg.Trading.IndicatorStateStore {
+	case "", "postgres", "redis", "none":
+	default:
+		return fmt.Errorf("trading.indicator_state_store must be postgres, redis or none, got %q", cfg.Trading.IndicatorStateStore)
+	}
+	if cfg.Trading.MLPromoteMinAccuracy < 0 || cfg.Trading.MLPromoteMinAccuracy > 1 {
+		return fmt.Errorf("trading.ml_promote_min_accuracy must be 0-1, got %.2f", cfg.Trading.MLPromoteMinAccuracy)
+	}
+	if cfg.Trading.MLWalkForwardSplits != 0 && (cfg.Trading.MLWalkForwardSplits < 2 || cfg.Trading.MLWalkForwardSplits > 20) {
+		return fmt.Errorf("trading.ml_walk_forward_splits must be 2-20, got %d", cfg.Trading.MLWalkForwardSplits)
+	}
+	if cfg.Trading.AnalysisCacheSeconds < 0 {
+		return fmt.Errorf("trading.analysis_cache_seconds must not be negative, got %d", cfg.Trading.AnalysisCacheSeconds)
+	}
+	switch cfg.Trading.AnalysisCacheStore {
+	case "", "memory", "redis":
+	default:
+		return fmt.Errorf("trading.analysis_cache_store must be memory or redis, got %q", cfg.Trading.AnalysisCacheStore)
+	}
+	if cfg.Trading.PreFilterMinScore < 0 || cfg.Trading.PreFilterMinScore > 100 {
+		return fmt.Errorf("trading.prefilter_min_score must be 0-100, got %.0f", cfg.Trading.PreFilterMinScore)
+	}
+
+	// ai provider chain
+	for _, p := range cfg.AI.Providers {
+		if p != "claude" && p != "openai" {
+			return fmt.Errorf("ai.providers contains unknown provider %q (claude or openai)", p)
+		}
+	}
+	if cfg.AI.FailureThreshold < 0 {
+		return fmt.Errorf("ai.failure_threshold must not be negative, got %d", cfg.AI.FailureThreshold)
+	}
+	if cfg.AI.CooldownSeconds < 0 {
+		return fmt.Errorf("ai.cooldown_seconds must not be negative, got %d", cfg.AI.CooldownSeconds)
+	}
+	for _, p := range cfg.AI.Prices {
+		if !validPrice(p) {
+			return fmt.Errorf("ai.prices entry %q must look like model=input/output", p)
+		}
+	}
+	if cfg.AI.DailyBudgetUSD < 0 || cfg.AI.GlobalDailyBudgetUSD < 0 {
+		return fmt.Errorf("ai daily budgets must not be negative, got %.2f per user / %.2f global", cfg.AI.DailyBudgetUSD, cfg.AI.GlobalDailyBudgetUSD)
+	}
+
+	// prompt experiment
+	if cfg.Prompts.Experiment != "" {
+		if cfg.Prompts.Unit != "user" && cfg.Prompts.Unit != "symbol" {
+			return fmt.Errorf("prompts.unit must be user or symbol, got %q", cfg.Prompts.Unit)
+		}
+		if len(cfg.Prompts.Variants) < 2 {
+			return fmt.Errorf("prompts.experiment %q needs at least two prompts.variants", cfg.Prompts.Experiment)
+		}
+		for _, v := range cfg.Prompts.Variants {
+			if !validVariant(v) {
+				return fmt.Errorf("prompts.variants entry %q must look like version=weight", v)
+			}
+		}
+	}
+
+	// event calendar bounds
+	if cfg.Calendar.Enabled {
+		if cfg.Calendar.File == "" && cfg.Calendar.URL == "" {
+			return fmt.Errorf("calendar.file or calendar.url is required when the calendar is enabled")
+		}
+		if cfg.Calendar.RefreshMinutes <= 0 {
+			return fmt.Errorf("calendar.refresh_minutes must be positive, got %d", cfg.Calendar.RefreshMinutes)
+		}
+		for name, m := range map[string]int{
+			"high_before_minutes":   cfg.Calendar.HighBeforeMinutes,
+			"high_after_minutes":    cfg.Calendar.HighAfterMinutes,
+			"medium_before_minutes": cfg.Calendar.MediumBeforeMinutes,
+			"medium_after_minutes":  cfg.Calendar.MediumAfterMinutes,
+			"low_before_minutes":    cfg.Calendar.LowBeforeMinutes,
+			"low_after_minutes":     cfg.Calendar.LowAfterMinutes,
+		} {
+			if m < 0 || m > 24*60 {
+				return fmt.Errorf("calendar.%s must be 0-1440, got %d", name, m)
+			}
+		}
+		if cfg.Calendar.LookaheadHours < 0 || cfg.Calendar.LookaheadHours > 24*14 {
+			return fmt.Errorf("calendar.lookahead_hours must be 0-336, got %d", cfg.Calendar.LookaheadHours)
+		}
+	}
 
 	// database connection
 	if cfg.Database.Host == "" {
@@ -412,3 +673,34 @@ func Validate(cfg *Config) error {
 
 	return nil
 }
+
+// validPrice checks an ai.prices entry: a model name and two non-negative
+// USD rates per million tokens
+func validPrice(entry string) bool {
+	model, rates, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	in, out, ok2 := strings.Cut(rates, "/")
+	if !ok || !ok2 || strings.TrimSpace(model) == "" {
+		return false
+	}
+	for _, r := range []string{in, out} {
+		v, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		if err != nil || v < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// validVariant checks a prompts.variants entry: a version with an optional
+// positive weight
+func validVariant(entry string) bool {
+	version, weight, hasWeight := strings.Cut(strings.TrimSpace(entry), "=")
+	if strings.TrimSpace(version) == "" {
+		return false
+	}
+	if !hasWeight {
+		return true
+	}
+	w, err := strconv.Atoi(strings.TrimSpace(weight))
+	return err == nil && w > 0
+}
@@ -224,6 +224,93 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "trading.timeframes contains invalid timeframe \"2d\"",
 		},
+		{
+			name:    "invalid indicator state store",
+			modify:  func(cfg *Config) { cfg.Trading.IndicatorStateStore = "memcached" },
+			wantErr: true,
+			errMsg:  "trading.indicator_state_store must be postgres, redis or none, got \"memcached\"",
+		},
+		{
+			name:    "promotion accuracy as percent",
+			modify:  func(cfg *Config) { cfg.Trading.MLPromoteMinAccuracy = 55 },
+			wantErr: true,
+			errMsg:  "trading.ml_promote_min_accuracy must be 0-1, got 55.00",
+		},
+		{
+			name:    "too few walk-forward splits",
+			modify:  func(cfg *Config) { cfg.Trading.MLWalkForwardSplits = 1 },
+			wantErr: true,
+			errMsg:  "trading.ml_walk_forward_splits must be 2-20, got 1",
+		},
+		{
+			name:    "invalid analysis cache store",
+			modify:  func(cfg *Config) { cfg.Trading.AnalysisCacheStore = "postgres" },
+			wantErr: true,
+			errMsg:  "trading.analysis_cache_store must be memory or redis, got \"postgres\"",
+		},
+		{
+			name:    "pre-filter score above 100",
+			modify:  func(cfg *Config) { cfg.Trading.PreFilterMinScore = 120 },
+			wantErr: true,
+			errMsg:  "trading.prefilter_min_score must be 0-100, got 120",
+		},
+		{
+			name:    "unknown ai provider",
+			modify:  func(cfg *Config) { cfg.AI.Providers = []string{"claude", "gemini"} },
+			wantErr: true,
+			errMsg:  `ai.providers contains unknown provider "gemini" (claude or openai)`,
+		},
+		{
+			name:    "malformed ai price",
+			modify:  func(cfg *Config) { cfg.AI.Prices = []string{"gpt-4o=2.5"} },
+			wantErr: true,
+			errMsg:  `ai.prices entry "gpt-4o=2.5" must look like model=input/output`,
+		},
+		{
+			name: "prompt experiment with one variant",
+			modify: func(cfg *Config) {
+				cfg.Prompts.Experiment = "terse"
+				cfg.Prompts.Unit = "user"
+				cfg.Prompts.Variants = []string{"v2=50"}
+			},
+			wantErr: true,
+			errMsg:  `prompts.experiment "terse" needs at least two prompts.variants`,
+		},
+		{
+			name: "prompt experiment with a bad weight",
+			modify: func(cfg *Config) {
+				cfg.Prompts.Experiment = "terse"
+				cfg.Prompts.Unit = "user"
+				cfg.Prompts.Variants = []string{"v1=50", "v2=half"}
+			},
+			wantErr: true,
+			errMsg:  `prompts.variants entry "v2=half" must look like version=weight`,
+		},
+		{
+			name:    "negative ai budget",
+			modify:  func(cfg *Config) { cfg.AI.DailyBudgetUSD = -1 },
+			wantErr: true,
+			errMsg:  "ai daily budgets must not be negative, got -1.00 per user / 0.00 global",
+		},
+		{
+			name:    "calendar without a source",
+			modify:  func(cfg *Config) { cfg.Calendar = CalendarConfig{Enabled: true, RefreshMinutes: 30} },
+			wantErr: true,
+			errMsg:  "calendar.file or calendar.url is required when the calendar is enabled",
+		},
+		{
+			name: "negative calendar window",
+			modify: func(cfg *Config) {
+				cfg.Calendar = CalendarConfig{Enabled: true, File: "events.yaml", RefreshMinutes: 30, HighBeforeMinutes: -5}
+			},
+			wantErr: true,
+			errMsg:  "calendar.high_before_minutes must be 0-1440, got -5",
+		},
+		{
+			name:    "disabled calendar is not checked",
+			modify:  func(cfg *Config) { cfg.Calendar = CalendarConfig{RefreshMinutes: -1} },
+			wantErr: false,
+		},
 		{
 			name:    "empty database host",
 			modify:  func(cfg *Config) { cfg.Database.Host = "" },
@@ -292,6 +379,48 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Trading.ScannerIntervalMinutes != 5 {
 		t.Errorf("trading.scanner_interval_minutes = %d, want %d", cfg.Trading.ScannerIntervalMinutes, 5)
 	}
+	if cfg.Trading.RLSecondOpinion {
+		t.Error("trading.rl_second_opinion should default to false")
+	}
+	if cfg.Trading.AnalysisCacheSeconds != 240 || cfg.Trading.AnalysisCacheStore != "memory" {
+		t.Errorf("trading.analysis_cache = %ds in %q, want 240s in memory", cfg.Trading.AnalysisCacheSeconds, cfg.Trading.AnalysisCacheStore)
+	}
+	if cfg.Trading.PreFilterMinScore != 25 {
+		t.Errorf("trading.prefilter_min_score = %.0f, want 25", cfg.Trading.PreFilterMinScore)
+	}
+	if !cfg.Trading.PortfolioContext {
+		t.Error("trading.portfolio_context should default to true")
+	}
+	if !cfg.Trading.MarketContext {
+		t.Error("trading.market_context should default to true")
+	}
+
+	// check ai provider defaults
+	if len(cfg.AI.Providers) != 2 || cfg.AI.Providers[0] != "claude" || cfg.AI.Providers[1] != "openai" {
+		t.Errorf("ai.providers = %v, want [claude openai]", cfg.AI.Providers)
+	}
+	if cfg.AI.FailureThreshold != 3 || cfg.AI.Cooldown() != 5*time.Minute {
+		t.Errorf("ai health policy = %d failures / %s, want 3 / 5m", cfg.AI.FailureThreshold, cfg.AI.Cooldown())
+	}
+	if len(cfg.AI.Prices) == 0 || cfg.AI.DailyBudgetUSD != 0 || cfg.AI.GlobalDailyBudgetUSD != 0 {
+		t.Errorf("ai usage = %v prices, $%.2f/$%.2f budgets, want priced and unlimited", cfg.AI.Prices, cfg.AI.DailyBudgetUSD, cfg.AI.GlobalDailyBudgetUSD)
+	}
+	if cfg.OpenAI.Configured() || cfg.OpenAI.Name != "openai" {
+		t.Errorf("openai = %+v, want unconfigured by default", cfg.OpenAI)
+	}
+
+	// check prompt defaults
+	if cfg.Prompts.Dir != "prompts" || cfg.Prompts.Default != "v1" || cfg.Prompts.Experiment != "" || cfg.Prompts.Unit != "user" {
+		t.Errorf("prompts = %+v, want the built-in v1 and no experiment", cfg.Prompts)
+	}
+
+	// check calendar defaults
+	if cfg.Calendar.Enabled || cfg.Calendar.File != "events.yaml" || cfg.Calendar.RefreshInterval() != 30*time.Minute {
+		t.Errorf("calendar = %+v, want disabled with events.yaml refreshed every 30m", cfg.Calendar)
+	}
+	if cfg.Calendar.HighBeforeMinutes != 60 || cfg.Calendar.HighAfterMinutes != 120 || cfg.Calendar.LowAfterMinutes != 0 {
+		t.Errorf("calendar windows = %+v, want high 60/120 and no low blackout", cfg.Calendar)
+	}
 
 	// check log level default
 	if cfg.LogLevel != "info" {
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -26,11 +27,18 @@ type AIDecisionRecord struct {
 	PositionSizeUSD  float64
 	RiskRewardRatio  float64
 	Reasoning        string
-	IndicatorsData   map[string]interface{} // stored as JSONB
-	MLPrediction     map[string]interface{} // stored as JSONB
-	SentimentData    map[string]interface{} // stored as JSONB
+	IndicatorsData   map[string]interface{}   // stored as JSONB
+	MLPrediction     map[string]interface{}   // stored as JSONB
+	SentimentData    map[string]interface{}   // stored as JSONB
+	PatternsData     []map[string]interface{} // chart patterns, stored as JSONB
+	RLOpinion        map[string]interface{}   // rl agent's call on the same analysis, stored as JSONB
+	Provider         string                   // llm provider that produced the decision, e.g. "anthropic"
+	Model            string                   // model name reported by the provider
+	PromptVersion    string                   // system prompt version the decision was made with
+	PromptExperiment string                   // experiment that assigned the prompt, if any
 	PromptTokens     int
 	CompletionTokens int
+	CostUSD          float64 // priced cost of the ai call; 0 when shared or unpriced
 	LatencyMs        int
 	WasApproved      *bool // nil = pending, true = approved, false = rejected/expired
 	WasExecuted      bool
@@ -52,20 +60,27 @@ func (r *AIDecisionRepository) Insert(ctx context.Context, d *AIDecisionRecord)
 	indJSON, _ := json.Marshal(d.IndicatorsData)
 	mlJSON, _ := json.Marshal(d.MLPrediction)
 	sentJSON, _ := json.Marshal(d.SentimentData)
+	patJSON, _ := json.Marshal(d.PatternsData)
+	var rlJSON []byte
+	if d.RLOpinion != nil {
+		rlJSON, _ = json.Marshal(d.RLOpinion)
+	}
 
 	query := `
 		INSERT INTO ai_decisions (
 			user_id, symbol, timeframe, decision, confidence,
 			entry_price, stop_loss, take_profit, position_size_usd, risk_reward_ratio,
-			reasoning, indicators_data, ml_prediction, sentiment_data,
+			reasoning, indicators_data, ml_prediction, sentiment_data, patterns_data, rl_opinion,
 			prompt_tokens, completion_tokens, latency_ms,
-			was_approved, was_executed
+			was_approved, was_executed, ai_provider, ai_model, cost_usd,
+			prompt_version, prompt_experiment
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9, $10,
-			$11, $12, $13, $14,
-			$15, $16, $17,
-			$18, $19
+			$11, $12, $13, $14, $15, $16,
+			$17, $18, $19,
+			$20, $21, $22, $23, $24,
+			$25, $26
 		)
 		RETURNING id`
 
@@ -74,9 +89,10 @@ func (r *AIDecisionRepository) Insert(ctx context.Context, d *AIDecisionRecord)
 		d.UserID, d.Symbol, nullStr(d.Timeframe), d.Decision, d.Confidence,
 		nullFloat(d.EntryPrice), nullFloat(d.StopLoss), nullFloat(d.TakeProfit),
 		nullFloat(d.PositionSizeUSD), nullFloat(d.RiskRewardRatio),
-		nullStr(d.Reasoning), indJSON, mlJSON, sentJSON,
+		nullStr(d.Reasoning), indJSON, mlJSON, sentJSON, patJSON, rlJSON,
 		d.PromptTokens, d.CompletionTokens, d.LatencyMs,
-		d.WasApproved, d.WasExecuted,
+		d.WasApproved, d.WasExecuted, nullStr(d.Provider), nullStr(d.Model), d.CostUSD,
+		nullStr(d.PromptVersion), nullStr(d.PromptExperiment),
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert ai_decision: %w", err)
@@ -113,11 +129,12 @@ func (r *AIDecisionRepository) RecentBySymbol(ctx context.Context, userID int, s
 		       COALESCE(position_size_usd, 0), COALESCE(risk_reward_ratio, 0),
 		       COALESCE(reasoning, ''),
 		       COALESCE(indicators_data, '{}'::jsonb), COALESCE(ml_prediction, '{}'::jsonb),
-		       COALESCE(sentiment_data, '{}'::jsonb),
-		       COALESCE(prompt_tokens, 0), COALESCE(completion_tokens, 0), COALESCE(latency_ms, 0),
-		       was_approved, was_executed, created_at
+		       COALESCE(sentiment_data, '{}'::jsonb), COALESCE(patterns_data, '[]'::jsonb),
+		       COALESCE(prompt_tokens, 0), COALESCE(completion_tokens, 0), COALESCE(cost_usd, 0), COALESCE(latency_ms, 0),
+		       was_approved, was_executed, COALESCE(ai_provider, ''), COALESCE(ai_model, ''),
+		       COALESCE(prompt_version, ''), COALESCE(prompt_experiment, ''), created_at
 		FROM ai_decisions
-		WHERE user_id = $1 AND symbol = $2
+		WHERE user_id = $1 AND symbol = $2 AND filter_reason IS DISTINCT FROM 'ai_failed'
 		ORDER BY created_at DESC
 		LIMIT $3`
 
@@ -130,15 +147,16 @@ func (r *AIDecisionRepository) RecentBySymbol(ctx context.Context, userID int, s
 	var results []*AIDecisionRecord
 	for rows.Next() {
 		d := &AIDecisionRecord{}
-		var indJSON, mlJSON, sentJSON []byte
+		var indJSON, mlJSON, sentJSON, patJSON []byte
 		if err := rows.Scan(
 			&d.ID, &d.UserID, &d.Symbol, &d.Timeframe, &d.Decision, &d.Confidence,
 			&d.EntryPrice, &d.StopLoss, &d.TakeProfit,
 			&d.PositionSizeUSD, &d.RiskRewardRatio,
 			&d.Reasoning,
-			&indJSON, &mlJSON, &sentJSON,
-			&d.PromptTokens, &d.CompletionTokens, &d.LatencyMs,
-			&d.WasApproved, &d.WasExecuted, &d.CreatedAt,
+			&indJSON, &mlJSON, &sentJSON, &patJSON,
+			&d.PromptTokens, &d.CompletionTokens, &d.CostUSD, &d.LatencyMs,
+			&d.WasApproved, &d.WasExecuted, &d.Provider, &d.Model,
+			&d.PromptVersion, &d.PromptExperiment, &d.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan ai_decision row: %w", err)
 		}
@@ -151,6 +169,9 @@ func (r *AIDecisionRepository) RecentBySymbol(ctx context.Context, userID int, s
 		if err := json.Unmarshal(sentJSON, &d.SentimentData); err != nil {
 			log.Printf("warning: failed to unmarshal sentiment data for decision %d: %v", d.ID, err)
 		}
+		if err := json.Unmarshal(patJSON, &d.PatternsData); err != nil {
+			log.Printf("warning: failed to unmarshal patterns data for decision %d: %v", d.ID, err)
+		}
 		results = append(results, d)
 	}
 	return results, rows.Err()
@@ -163,7 +184,8 @@ func (r *AIDecisionRepository) CountByUserToday(ctx context.Context, userID int)
 			COUNT(*) as total,
 			COUNT(*) FILTER (WHERE was_approved = TRUE) as approved
 		FROM ai_decisions
-		WHERE user_id = $1 AND created_at >= CURRENT_DATE`
+		WHERE user_id = $1 AND created_at >= CURRENT_DATE
+		  AND filter_reason IS DISTINCT FROM 'ai_failed'`
 
 	var total, approved int
 	err := r.pool.QueryRow(ctx, query, userID).Scan(&total, &approved)
@@ -173,6 +195,115 @@ func (r *AIDecisionRepository) CountByUserToday(ctx context.Context, userID int)
 	return total, approved, nil
 }
 
+// AIUsageRow sums the ai calls behind logged decisions. Symbol and Day are
+// set depending on the grouping.
+type AIUsageRow struct {
+	Symbol       string
+	Day          time.Time
+	Calls        int
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// usageColumns aggregates the calls among ai_decisions rows; rows with no
+// tokens are pre-filtered or shared decisions and aren't calls. Billed calls
+// that failed are logged as ai_failed rows and count too.
+const usageColumns = `
+	COUNT(*) FILTER (WHERE COALESCE(prompt_tokens, 0) > 0),
+	COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+	COALESCE(SUM(cost_usd), 0)`
+
+// UsageTotals sums ai usage since a time for a user, or everyone when userID is 0.
+func (r *AIDecisionRepository) UsageTotals(ctx context.Context, userID int, since time.Time) (*AIUsageRow, error) {
+	query := `SELECT ` + usageColumns + `
+		FROM ai_decisions
+		WHERE ($1 = 0 OR user_id = $1) AND created_at >= $2`
+
+	u := &AIUsageRow{}
+	err := r.pool.QueryRow(ctx, query, userID, since).Scan(&u.Calls, &u.InputTokens, &u.OutputTokens, &u.CostUSD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum ai usage: %w", err)
+	}
+	return u, nil
+}
+
+// UsageBySymbol sums ai usage per symbol since a time, most expensive first.
+func (r *AIDecisionRepository) UsageBySymbol(ctx context.Context, userID int, since time.Time, limit int) ([]*AIUsageRow, error) {
+	query := `SELECT symbol, ` + usageColumns + `
+		FROM ai_decisions
+		WHERE ($1 = 0 OR user_id = $1) AND created_at >= $2
+		GROUP BY symbol
+		ORDER BY 5 DESC, 2 DESC
+		LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, userID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ai usage by symbol: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*AIUsageRow
+	for rows.Next() {
+		u := &AIUsageRow{}
+		if err := rows.Scan(&u.Symbol, &u.Calls, &u.InputTokens, &u.OutputTokens, &u.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan ai usage row: %w", err)
+		}
+		results = append(results, u)
+	}
+	return results, rows.Err()
+}
+
+// UsageByDay sums ai usage per utc day since a time, oldest first.
+func (r *AIDecisionRepository) UsageByDay(ctx context.Context, userID int, since time.Time) ([]*AIUsageRow, error) {
+	query := `SELECT date_trunc('day', created_at AT TIME ZONE 'UTC'), ` + usageColumns + `
+		FROM ai_decisions
+		WHERE ($1 = 0 OR user_id = $1) AND created_at >= $2
+		GROUP BY 1
+		ORDER BY 1`
+
+	rows, err := r.pool.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ai usage by day: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*AIUsageRow
+	for rows.Next() {
+		u := &AIUsageRow{}
+		if err := rows.Scan(&u.Day, &u.Calls, &u.InputTokens, &u.OutputTokens, &u.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan ai usage row: %w", err)
+		}
+		results = append(results, u)
+	}
+	return results, rows.Err()
+}
+
+// CostByUserSince returns each user's ai spend since a time, for restoring
+// budgets after a restart.
+func (r *AIDecisionRepository) CostByUserSince(ctx context.Context, since time.Time) (map[int]float64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, COALESCE(SUM(cost_usd), 0)
+		FROM ai_decisions
+		WHERE created_at >= $1
+		GROUP BY user_id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ai spend: %w", err)
+	}
+	defer rows.Close()
+
+	spend := make(map[int]float64)
+	for rows.Next() {
+		var userID int
+		var cost float64
+		if err := rows.Scan(&userID, &cost); err != nil {
+			return nil, fmt.Errorf("failed to scan ai spend row: %w", err)
+		}
+		spend[userID] = cost
+	}
+	return spend, rows.Err()
+}
+
 // WinRateBySymbol calculates the win/loss ratio for executed trades on a symbol.
 func (r *AIDecisionRepository) WinRateBySymbol(ctx context.Context, userID int, symbol string) (wins, losses int, err error) {
 	query := `
@@ -239,3 +370,282 @@ func (r *AIDecisionRepository) RecentOutcomes(ctx context.Context, limit int) ([
 	}
 	return results, rows.Err()
 }
+
+// PatternStatRow is the track record of one chart pattern across executed trades.
+type PatternStatRow struct {
+	Pattern   string
+	Direction string
+	Trades    int
+	Wins      int
+	AvgPnLPct float64
+}
+
+// PatternPerformance aggregates closed-trade outcomes of decisions made since
+// the given time by the chart patterns that were present when the decision
+// was made. A closed position belongs to the latest BUY/SELL decision of the
+// same user, symbol and direction made shortly before it opened; a trade
+// counts once for every pattern on its decision.
+func (r *AIDecisionRepository) PatternPerformance(ctx context.Context, since time.Time) ([]*PatternStatRow, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, symbol, decision, patterns_data, created_at
+		FROM ai_decisions
+		WHERE decision IN ('BUY', 'SELL') AND jsonb_typeof(patterns_data) = 'array'
+		  AND jsonb_array_length(patterns_data) > 0 AND created_at >= $1
+		ORDER BY created_at`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pattern decisions: %w", err)
+	}
+	var decisions []patternDecision
+	for rows.Next() {
+		var d patternDecision
+		var patJSON []byte
+		if err := rows.Scan(&d.UserID, &d.Symbol, &d.Decision, &patJSON, &d.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pattern decision: %w", err)
+		}
+		if err := json.Unmarshal(patJSON, &d.Patterns); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode patterns: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(decisions) == 0 {
+		return nil, nil
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT user_id, symbol, side,
+		       COALESCE(realized_pnl / NULLIF(position_size, 0) * 100, 0),
+		       opened_at
+		FROM positions
+		WHERE status = 'CLOSED' AND opened_at >= $1`, since.Add(-patternTradeBefore))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed positions: %w", err)
+	}
+	defer rows.Close()
+	var trades []patternTrade
+	for rows.Next() {
+		var t patternTrade
+		if err := rows.Scan(&t.UserID, &t.Symbol, &t.Side, &t.PnLPct, &t.OpenedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan closed position: %w", err)
+		}
+		trades = append(trades, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return patternStats(decisions, trades), nil
+}
+
+// a closed position may open this long before or after the decision behind it
+const (
+	patternTradeBefore = time.Minute
+	patternTradeAfter  = 5 * time.Minute
+)
+
+// patternDecision is a logged BUY/SELL decision with its chart patterns
+type patternDecision struct {
+	UserID    int
+	Symbol    string
+	Decision  string
+	CreatedAt time.Time
+	Patterns  []patternTag
+}
+
+// patternTag is the part of a stored chart pattern the report groups by
+type patternTag struct {
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+}
+
+// patternTrade is a closed position
+type patternTrade struct {
+	UserID   int
+	Symbol   string
+	Side     string // LONG or SHORT
+	PnLPct   float64
+	OpenedAt time.Time
+}
+
+// patternStats pairs every closed position with the latest matching decision
+// and aggregates the outcomes per pattern, most traded first
+func patternStats(decisions []patternDecision, trades []patternTrade) []*PatternStatRow {
+	type key struct{ pattern, direction string }
+	stats := make(map[key]*PatternStatRow)
+	var order []key
+	for _, t := range trades {
+		var match *patternDecision
+		for i := range decisions {
+			d := &decisions[i]
+			if d.UserID != t.UserID || d.Symbol != t.Symbol || decisionSide(d.Decision) != t.Side {
+				continue
+			}
+			if t.OpenedAt.Before(d.CreatedAt.Add(-patternTradeBefore)) || t.OpenedAt.After(d.CreatedAt.Add(patternTradeAfter)) {
+				continue
+			}
+			if match == nil || d.CreatedAt.After(match.CreatedAt) {
+				match = d
+			}
+		}
+		if match == nil {
+			continue
+		}
+		for _, p := range match.Patterns {
+			k := key{p.Name, p.Direction}
+			s, ok := stats[k]
+			if !ok {
+				s = &PatternStatRow{Pattern: p.Name, Direction: p.Direction}
+				stats[k] = s
+				order = append(order, k)
+			}
+			// AvgPnLPct holds the sum until the end
+			s.Trades++
+			s.AvgPnLPct += t.PnLPct
+			if t.PnLPct > 0 {
+				s.Wins++
+			}
+		}
+	}
+
+	results := make([]*PatternStatRow, 0, len(order))
+	for _, k := range order {
+		s := stats[k]
+		s.AvgPnLPct /= float64(s.Trades)
+		results = append(results, s)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Trades > results[j].Trades })
+	return results
+}
+
+// decisionSide is the position side a BUY or SELL decision opens
+func decisionSide(decision string) string {
+	if decision == "BUY" {
+		return "LONG"
+	}
+	return "SHORT"
+}
+
+// RLOpinionRow is a logged decision with the RL agent's call and the prices
+// at the decision and one horizon later.
+type RLOpinionRow struct {
+	DecisionID   int
+	Symbol       string
+	Decision     string
+	RLAction     string
+	RLConfidence float64
+	PriceAt      float64
+	PriceAfter   float64
+	CreatedAt    time.Time
+}
+
+// RLOpinionOutcomes loads decisions that carry an RL opinion, with the close
+// of the stored candle (of the given interval) at the decision and after the
+// horizon. Only the analyses that made an ai call count, so a decision shared
+// across users is scored once; decisions whose horizon hasn't passed, or
+// without stored candles, are left out. An empty symbol matches all symbols.
+func (r *AIDecisionRepository) RLOpinionOutcomes(ctx context.Context, symbol, interval string, horizon time.Duration, since time.Time) ([]*RLOpinionRow, error) {
+	query := `
+		SELECT d.id, d.symbol, d.decision,
+		       COALESCE(d.rl_opinion->>'action', 'HOLD'),
+		       COALESCE((d.rl_opinion->>'confidence')::double precision, 0),
+		       c0.close, c1.close, d.created_at
+		FROM ai_decisions d
+		JOIN LATERAL (
+			SELECT close FROM candles
+			WHERE symbol = d.symbol AND interval = $3 AND time <= d.created_at
+			ORDER BY time DESC LIMIT 1
+		) c0 ON TRUE
+		JOIN LATERAL (
+			SELECT close FROM candles
+			WHERE symbol = d.symbol AND interval = $3 AND time <= d.created_at + $4 * interval '1 second'
+			ORDER BY time DESC LIMIT 1
+		) c1 ON TRUE
+		WHERE d.rl_opinion IS NOT NULL
+		  AND COALESCE(d.prompt_tokens, 0) > 0
+		  AND d.created_at >= $1
+		  AND d.created_at + $4 * interval '1 second' <= NOW()
+		  AND ($2 = '' OR d.symbol = $2)
+		ORDER BY d.created_at`
+
+	rows, err := r.pool.Query(ctx, query, since, symbol, interval, horizon.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rl opinions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*RLOpinionRow
+	for rows.Next() {
+		o := &RLOpinionRow{}
+		if err := rows.Scan(
+			&o.DecisionID, &o.Symbol, &o.Decision, &o.RLAction, &o.RLConfidence,
+			&o.PriceAt, &o.PriceAfter, &o.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rl opinion row: %w", err)
+		}
+		results = append(results, o)
+	}
+	return results, rows.Err()
+}
+
+// PromptOutcomeRow is a decision from a prompt experiment with the prices
+// at the decision and one horizon later.
+type PromptOutcomeRow struct {
+	DecisionID    int
+	Symbol        string
+	PromptVersion string
+	Decision      string
+	Confidence    int
+	PriceAt       float64
+	PriceAfter    float64
+	CreatedAt     time.Time
+}
+
+// PromptOutcomes loads the ai calls made under an experiment, with the
+// close of the stored candle (of the given interval) at the decision and
+// after the horizon. Shared copies of a decision (no tokens), decisions
+// whose horizon hasn't passed and ones without stored candles are left out.
+func (r *AIDecisionRepository) PromptOutcomes(ctx context.Context, experiment, interval string, horizon time.Duration, since time.Time) ([]*PromptOutcomeRow, error) {
+	query := `
+		SELECT d.id, d.symbol, d.prompt_version, d.decision, d.confidence,
+		       c0.close, c1.close, d.created_at
+		FROM ai_decisions d
+		JOIN LATERAL (
+			SELECT close FROM candles
+			WHERE symbol = d.symbol AND interval = $3 AND time <= d.created_at
+			ORDER BY time DESC LIMIT 1
+		) c0 ON TRUE
+		JOIN LATERAL (
+			SELECT close FROM candles
+			WHERE symbol = d.symbol AND interval = $3 AND time <= d.created_at + $4 * interval '1 second'
+			ORDER BY time DESC LIMIT 1
+		) c1 ON TRUE
+		WHERE d.prompt_experiment = $1
+		  AND d.prompt_version IS NOT NULL
+		  AND COALESCE(d.prompt_tokens, 0) > 0
+		  AND d.created_at >= $2
+		  AND d.created_at + $4 * interval '1 second' <= NOW()
+		ORDER BY d.created_at`
+
+	rows, err := r.pool.Query(ctx, query, experiment, since, interval, horizon.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*PromptOutcomeRow
+	for rows.Next() {
+		o := &PromptOutcomeRow{}
+		if err := rows.Scan(
+			&o.DecisionID, &o.Symbol, &o.PromptVersion, &o.Decision, &o.Confidence,
+			&o.PriceAt, &o.PriceAfter, &o.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt outcome row: %w", err)
+		}
+		results = append(results, o)
+	}
+	return results, rows.Err()
+}
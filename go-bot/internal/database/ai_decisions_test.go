@@ -38,7 +38,7 @@ func TestAIDecisionRecord_Fields(t *testing.T) {
 
 func TestAIDecisionRecord_FilterReasons(t *testing.T) {
 	reasons := []string{"none", "hold", "low_confidence", "confidence_decay", "duplicate",
-		"daily_limit", "safety_blocked", "expired", "user_rejected"}
+		"daily_limit", "safety_blocked", "expired", "user_rejected", "ai_failed"}
 
 	for _, reason := range reasons {
 		t.Run(reason, func(t *testing.T) {
@@ -126,3 +126,28 @@ func TestNewAIDecisionRepository(t *testing.T) {
 		t.Fatal("expected non-nil repository")
 	}
 }
+
+func TestPatternStats(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	flag := patternDecision{UserID: 1, Symbol: "BTC/USDT", Decision: "BUY", CreatedAt: at,
+		Patterns: []patternTag{{Name: "bull_flag", Direction: "bullish"}}}
+	// the same call on a symbol that was never traded
+	unexecuted := flag
+	unexecuted.Symbol = "ETH/USDT"
+
+	trades := []patternTrade{
+		{UserID: 1, Symbol: "BTC/USDT", Side: "LONG", PnLPct: 4, OpenedAt: at.Add(2 * time.Minute)},
+		{UserID: 1, Symbol: "BTC/USDT", Side: "SHORT", PnLPct: -3, OpenedAt: at.Add(time.Minute)}, // wrong direction
+		{UserID: 2, Symbol: "BTC/USDT", Side: "LONG", PnLPct: -1, OpenedAt: at},                   // another user
+		{UserID: 1, Symbol: "BTC/USDT", Side: "LONG", PnLPct: -2, OpenedAt: at.Add(time.Hour)},    // long after the decision
+	}
+
+	stats := patternStats([]patternDecision{flag, unexecuted}, trades)
+	if len(stats) != 1 {
+		t.Fatalf("expected one pattern, got %d", len(stats))
+	}
+	s := stats[0]
+	if s.Pattern != "bull_flag" || s.Direction != "bullish" || s.Trades != 1 || s.Wins != 1 || s.AvgPnLPct != 4 {
+		t.Errorf("expected the one closed trade behind the decision, got %+v", s)
+	}
+}
@@ -0,0 +1,131 @@
+// backtest run persistence — stores every backtest run with its full report
+// (config, metrics, trades, equity curve) so runs can be listed and compared later.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BacktestRunRecord is a row in the backtest_runs table.
+// Report holds the full serialized backtest report and is only populated by GetByID.
+type BacktestRunRecord struct {
+	ID             int
+	Symbol         string
+	Interval       string
+	Strategy       string
+	StartTime      time.Time
+	EndTime        time.Time
+	InitialCapital float64
+	FinalEquity    float64
+	TotalReturnPct float64
+	SharpeRatio    float64
+	MaxDrawdownPct float64
+	WinRate        float64
+	TotalTrades    int
+	Report         json.RawMessage
+	CreatedAt      time.Time
+}
+
+// BacktestRunRepository handles backtest run persistence.
+type BacktestRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBacktestRunRepository(pool *pgxpool.Pool) *BacktestRunRepository {
+	return &BacktestRunRepository{pool: pool}
+}
+
+// Insert writes a backtest run. Returns the auto-generated ID.
+func (r *BacktestRunRepository) Insert(ctx context.Context, run *BacktestRunRecord) (int, error) {
+	query := `
+		INSERT INTO backtest_runs (
+			symbol, interval, strategy, start_time, end_time,
+			initial_capital, final_equity, total_return_pct, sharpe_ratio,
+			max_drawdown_pct, win_rate, total_trades, report
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		run.Symbol, run.Interval, run.Strategy, run.StartTime, run.EndTime,
+		run.InitialCapital, run.FinalEquity, run.TotalReturnPct, run.SharpeRatio,
+		run.MaxDrawdownPct, run.WinRate, run.TotalTrades, []byte(run.Report),
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert backtest run: %w", err)
+	}
+	return run.ID, nil
+}
+
+// List returns run summaries (without the report payload), newest first.
+// An empty symbol matches all symbols.
+func (r *BacktestRunRepository) List(ctx context.Context, symbol string, limit int) ([]*BacktestRunRecord, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := `
+		SELECT id, symbol, interval, strategy, start_time, end_time,
+		       initial_capital, final_equity, total_return_pct, sharpe_ratio,
+		       max_drawdown_pct, win_rate, total_trades, created_at
+		FROM backtest_runs`
+
+	args := []any{}
+	if symbol != "" {
+		query += " WHERE symbol = $1"
+		args = append(args, symbol)
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query backtest runs: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*BacktestRunRecord
+	for rows.Next() {
+		run := &BacktestRunRecord{}
+		if err := rows.Scan(
+			&run.ID, &run.Symbol, &run.Interval, &run.Strategy, &run.StartTime, &run.EndTime,
+			&run.InitialCapital, &run.FinalEquity, &run.TotalReturnPct, &run.SharpeRatio,
+			&run.MaxDrawdownPct, &run.WinRate, &run.TotalTrades, &run.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan backtest run: %w", err)
+		}
+		results = append(results, run)
+	}
+	return results, rows.Err()
+}
+
+// GetByID loads a single run including its full report. Returns nil, nil if not found.
+func (r *BacktestRunRepository) GetByID(ctx context.Context, id int) (*BacktestRunRecord, error) {
+	query := `
+		SELECT id, symbol, interval, strategy, start_time, end_time,
+		       initial_capital, final_equity, total_return_pct, sharpe_ratio,
+		       max_drawdown_pct, win_rate, total_trades, report, created_at
+		FROM backtest_runs
+		WHERE id = $1`
+
+	run := &BacktestRunRecord{}
+	var report []byte
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&run.ID, &run.Symbol, &run.Interval, &run.Strategy, &run.StartTime, &run.EndTime,
+		&run.InitialCapital, &run.FinalEquity, &run.TotalReturnPct, &run.SharpeRatio,
+		&run.MaxDrawdownPct, &run.WinRate, &run.TotalTrades, &report, &run.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backtest run %d: %w", id, err)
+	}
+	run.Report = report
+	return run, nil
+}
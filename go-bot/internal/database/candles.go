@@ -106,6 +106,41 @@ func (r *CandleRepository) GetRange(ctx context.Context, symbol, interval string
 	return result, rows.Err()
 }
 
+// GetLatest returns up to limit of the most recent candles for a symbol+interval, oldest first.
+func (r *CandleRepository) GetLatest(ctx context.Context, symbol, interval string, limit int) ([]*CandleRecord, error) {
+	query := `
+		SELECT time, symbol, interval, open, high, low, close, volume, quote_volume, trade_count
+		FROM (
+			SELECT time, symbol, interval, open, high, low, close, volume,
+			       COALESCE(quote_volume, 0) AS quote_volume, COALESCE(trade_count, 0) AS trade_count
+			FROM candles
+			WHERE symbol = $1 AND interval = $2
+			ORDER BY time DESC
+			LIMIT $3
+		) latest
+		ORDER BY time ASC`
+
+	rows, err := r.pool.Query(ctx, query, symbol, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query latest candles: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*CandleRecord
+	for rows.Next() {
+		c := &CandleRecord{}
+		if err := rows.Scan(
+			&c.Time, &c.Symbol, &c.Interval,
+			&c.Open, &c.High, &c.Low, &c.Close,
+			&c.Volume, &c.QuoteVolume, &c.TradeCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan candle: %w", err)
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
 // LatestTime returns the most recent candle time for a symbol+interval (or zero if none).
 func (r *CandleRepository) LatestTime(ctx context.Context, symbol, interval string) (time.Time, error) {
 	var t time.Time
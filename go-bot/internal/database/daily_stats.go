@@ -24,6 +24,10 @@ type DailyStatsRecord struct {
 	AIDecisionsMade    int
 	AIDecisionsApproved int
 	NotificationsSent  int
+	AICalls            int
+	AIInputTokens      int64
+	AIOutputTokens     int64
+	AICostUSD          float64
 }
 
 // DailyStatsRepository handles daily stats upserts and queries.
@@ -85,6 +89,25 @@ func (r *DailyStatsRepository) IncrementDecision(ctx context.Context, userID int
 	return nil
 }
 
+// AddAIUsage adds an ai call's tokens and cost to the user's day.
+func (r *DailyStatsRepository) AddAIUsage(ctx context.Context, userID int, inputTokens, outputTokens int, cost float64) error {
+	query := `
+		INSERT INTO daily_stats (user_id, date, ai_calls, ai_input_tokens, ai_output_tokens, ai_cost_usd)
+		VALUES ($1, CURRENT_DATE, 1, $2, $3, $4)
+		ON CONFLICT (user_id, date) DO UPDATE SET
+			ai_calls = daily_stats.ai_calls + 1,
+			ai_input_tokens = daily_stats.ai_input_tokens + $2,
+			ai_output_tokens = daily_stats.ai_output_tokens + $3,
+			ai_cost_usd = daily_stats.ai_cost_usd + $4,
+			updated_at = NOW()`
+
+	_, err := r.pool.Exec(ctx, query, userID, inputTokens, outputTokens, cost)
+	if err != nil {
+		return fmt.Errorf("failed to add ai usage for user %d: %w", userID, err)
+	}
+	return nil
+}
+
 // IncrementNotification increments the notifications_sent counter.
 func (r *DailyStatsRepository) IncrementNotification(ctx context.Context, userID int) error {
 	query := `
@@ -123,7 +146,8 @@ func (r *DailyStatsRepository) GetForDate(ctx context.Context, userID int, date
 		SELECT id, user_id, date, total_trades, winning_trades, losing_trades,
 		       COALESCE(realized_pnl, 0), COALESCE(unrealized_pnl, 0),
 		       COALESCE(fees_paid, 0), COALESCE(funding_paid, 0),
-		       ai_decisions_made, ai_decisions_approved, notifications_sent
+		       ai_decisions_made, ai_decisions_approved, notifications_sent,
+		       COALESCE(ai_calls, 0), COALESCE(ai_input_tokens, 0), COALESCE(ai_output_tokens, 0), COALESCE(ai_cost_usd, 0)
 		FROM daily_stats
 		WHERE user_id = $1 AND date = $2`
 
@@ -134,6 +158,7 @@ func (r *DailyStatsRepository) GetForDate(ctx context.Context, userID int, date
 		&d.RealizedPnL, &d.UnrealizedPnL,
 		&d.FeesPaid, &d.FundingPaid,
 		&d.AIDecisionsMade, &d.AIDecisionsApproved, &d.NotificationsSent,
+		&d.AICalls, &d.AIInputTokens, &d.AIOutputTokens, &d.AICostUSD,
 	)
 	if err != nil {
 		return nil, err
@@ -147,7 +172,8 @@ func (r *DailyStatsRepository) GetRange(ctx context.Context, userID int, from, t
 		SELECT id, user_id, date, total_trades, winning_trades, losing_trades,
 		       COALESCE(realized_pnl, 0), COALESCE(unrealized_pnl, 0),
 		       COALESCE(fees_paid, 0), COALESCE(funding_paid, 0),
-		       ai_decisions_made, ai_decisions_approved, notifications_sent
+		       ai_decisions_made, ai_decisions_approved, notifications_sent,
+		       COALESCE(ai_calls, 0), COALESCE(ai_input_tokens, 0), COALESCE(ai_output_tokens, 0), COALESCE(ai_cost_usd, 0)
 		FROM daily_stats
 		WHERE user_id = $1 AND date >= $2 AND date <= $3
 		ORDER BY date ASC`
@@ -167,6 +193,7 @@ func (r *DailyStatsRepository) GetRange(ctx context.Context, userID int, from, t
 			&d.RealizedPnL, &d.UnrealizedPnL,
 			&d.FeesPaid, &d.FundingPaid,
 			&d.AIDecisionsMade, &d.AIDecisionsApproved, &d.NotificationsSent,
+			&d.AICalls, &d.AIInputTokens, &d.AIOutputTokens, &d.AICostUSD,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan daily stats row: %w", err)
 		}
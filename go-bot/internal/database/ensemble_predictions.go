@@ -0,0 +1,179 @@
+// ensemble prediction persistence — stores every ensemble prediction with its
+// per-model votes and realized outcome, and aggregates per-model accuracy.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ModelVoteRecord is one model's vote, stored in model_details keyed by model
+// name in the same shape the ml service returns.
+type ModelVoteRecord struct {
+	Direction  string  `json:"direction"`
+	Magnitude  float64 `json:"magnitude"`
+	Confidence float64 `json:"confidence"`
+	Weight     float64 `json:"weight"`
+}
+
+// EnsemblePredictionRecord is a row in the ensemble_predictions table.
+type EnsemblePredictionRecord struct {
+	ID             int
+	Symbol         string
+	Timeframe      string
+	Direction      string // UP, DOWN or NEUTRAL
+	Magnitude      float64
+	Confidence     float64
+	PredictedPrice float64
+	CurrentPrice   float64
+	ModelDetails   map[string]ModelVoteRecord // stored as JSONB
+	HorizonAt      time.Time
+	CreatedAt      time.Time
+}
+
+// ModelAccuracyRow is one model's realized directional accuracy on a symbol.
+// The ensemble's own vote is reported under the model name "ensemble".
+type ModelAccuracyRow struct {
+	Symbol      string
+	Model       string
+	Predictions int
+	Correct     int
+	AvgWeight   float64
+	LastWeight  float64 // weight in the most recent scored prediction
+}
+
+// Accuracy returns the hit rate in percent.
+func (r *ModelAccuracyRow) Accuracy() float64 {
+	if r.Predictions == 0 {
+		return 0
+	}
+	return float64(r.Correct) / float64(r.Predictions) * 100
+}
+
+// EnsemblePredictionRepository handles ensemble prediction persistence.
+type EnsemblePredictionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEnsemblePredictionRepository(pool *pgxpool.Pool) *EnsemblePredictionRepository {
+	return &EnsemblePredictionRepository{pool: pool}
+}
+
+// Insert writes a prediction. Returns the auto-generated ID.
+func (r *EnsemblePredictionRepository) Insert(ctx context.Context, p *EnsemblePredictionRecord) (int, error) {
+	details, err := json.Marshal(p.ModelDetails)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode model details: %w", err)
+	}
+
+	query := `
+		INSERT INTO ensemble_predictions (
+			symbol, timeframe, direction, magnitude, confidence,
+			predicted_price, current_price, model_count, model_details, horizon_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	err = r.pool.QueryRow(ctx, query,
+		p.Symbol, p.Timeframe, p.Direction, p.Magnitude, p.Confidence,
+		nullFloat(p.PredictedPrice), p.CurrentPrice, len(p.ModelDetails), details, p.HorizonAt,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert ensemble prediction: %w", err)
+	}
+	return p.ID, nil
+}
+
+// Due returns unresolved predictions whose horizon is at or before now, oldest first.
+func (r *EnsemblePredictionRepository) Due(ctx context.Context, now time.Time, limit int) ([]*EnsemblePredictionRecord, error) {
+	query := `
+		SELECT id, symbol, timeframe, direction, magnitude, confidence,
+		       COALESCE(predicted_price, 0), current_price,
+		       COALESCE(model_details, '{}'::jsonb), horizon_at, created_at
+		FROM ensemble_predictions
+		WHERE resolved_at IS NULL AND horizon_at <= $1
+		ORDER BY horizon_at
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due predictions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*EnsemblePredictionRecord
+	for rows.Next() {
+		p := &EnsemblePredictionRecord{}
+		var details []byte
+		if err := rows.Scan(
+			&p.ID, &p.Symbol, &p.Timeframe, &p.Direction, &p.Magnitude, &p.Confidence,
+			&p.PredictedPrice, &p.CurrentPrice, &details, &p.HorizonAt, &p.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ensemble prediction row: %w", err)
+		}
+		if err := json.Unmarshal(details, &p.ModelDetails); err != nil {
+			return nil, fmt.Errorf("failed to decode model details for prediction %d: %w", p.ID, err)
+		}
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
+// Resolve stores the realized outcome. An empty direction marks the
+// prediction resolved without a score (it expired before it was checked).
+func (r *EnsemblePredictionRepository) Resolve(ctx context.Context, id int, realizedPrice float64, realizedDirection string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE ensemble_predictions
+		SET realized_price = $2, realized_direction = $3, resolved_at = NOW()
+		WHERE id = $1`,
+		id, nullFloat(realizedPrice), nullStr(realizedDirection))
+	if err != nil {
+		return fmt.Errorf("failed to resolve ensemble prediction %d: %w", id, err)
+	}
+	return nil
+}
+
+// ModelAccuracy aggregates each model's directional accuracy per symbol over
+// scored predictions since the given time. An empty symbol matches all symbols.
+func (r *EnsemblePredictionRepository) ModelAccuracy(ctx context.Context, symbol string, since time.Time) ([]*ModelAccuracyRow, error) {
+	query := `
+		WITH scored AS (
+			SELECT e.symbol, m.key AS model, UPPER(m.value->>'direction') AS direction,
+			       COALESCE((m.value->>'weight')::double precision, 0) AS weight,
+			       e.realized_direction, e.created_at
+			FROM ensemble_predictions e
+			CROSS JOIN LATERAL jsonb_each(e.model_details) AS m
+			WHERE e.realized_direction IS NOT NULL AND e.created_at >= $1
+			UNION ALL
+			SELECT e.symbol, 'ensemble', e.direction, 1, e.realized_direction, e.created_at
+			FROM ensemble_predictions e
+			WHERE e.realized_direction IS NOT NULL AND e.created_at >= $1
+		)
+		SELECT symbol, model, COUNT(*),
+		       COUNT(*) FILTER (WHERE direction = realized_direction),
+		       AVG(weight),
+		       (ARRAY_AGG(weight ORDER BY created_at DESC))[1]
+		FROM scored
+		WHERE $2 = '' OR symbol = $2
+		GROUP BY symbol, model
+		ORDER BY symbol, model`
+
+	rows, err := r.pool.Query(ctx, query, since, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*ModelAccuracyRow
+	for rows.Next() {
+		a := &ModelAccuracyRow{}
+		if err := rows.Scan(&a.Symbol, &a.Model, &a.Predictions, &a.Correct, &a.AvgWeight, &a.LastWeight); err != nil {
+			return nil, fmt.Errorf("failed to scan model accuracy row: %w", err)
+		}
+		results = append(results, a)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,141 @@
+// indicator state persistence — snapshots of the streaming indicator
+// calculators, keyed by symbol and interval, in postgres or redis.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IndicatorStateRecord is one serialized indicator stream.
+type IndicatorStateRecord struct {
+	Symbol         string          `json:"symbol"`
+	Interval       string          `json:"interval"`
+	State          json.RawMessage `json:"state"`
+	LastCandleTime time.Time       `json:"last_candle_time"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// IndicatorStateRepository stores snapshots in the indicator_state table.
+type IndicatorStateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIndicatorStateRepository(pool *pgxpool.Pool) *IndicatorStateRepository {
+	return &IndicatorStateRepository{pool: pool}
+}
+
+// SaveBatch upserts snapshots, replacing any previous state for the same symbol and interval.
+func (r *IndicatorStateRepository) SaveBatch(ctx context.Context, records []*IndicatorStateRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO indicator_state (symbol, interval, state, last_candle_time, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (symbol, interval) DO UPDATE SET
+			state = EXCLUDED.state,
+			last_candle_time = EXCLUDED.last_candle_time,
+			updated_at = EXCLUDED.updated_at`
+
+	for _, rec := range records {
+		if _, err := tx.Exec(ctx, query, rec.Symbol, rec.Interval, []byte(rec.State), rec.LastCandleTime); err != nil {
+			return fmt.Errorf("upsert indicator state %s %s: %w", rec.Symbol, rec.Interval, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// LoadAll returns every stored snapshot.
+func (r *IndicatorStateRepository) LoadAll(ctx context.Context) ([]*IndicatorStateRecord, error) {
+	query := `
+		SELECT symbol, interval, state, last_candle_time, updated_at
+		FROM indicator_state
+		ORDER BY symbol, interval`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query indicator state: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*IndicatorStateRecord
+	for rows.Next() {
+		rec := &IndicatorStateRecord{}
+		var state []byte
+		if err := rows.Scan(&rec.Symbol, &rec.Interval, &state, &rec.LastCandleTime, &rec.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan indicator state: %w", err)
+		}
+		rec.State = state
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
+
+// indicatorStateKey is the redis hash holding every snapshot, one field per symbol and interval
+const indicatorStateKey = "indicator_state"
+
+// RedisIndicatorStateStore stores snapshots in a redis hash.
+type RedisIndicatorStateStore struct {
+	client *RedisClient
+}
+
+func NewRedisIndicatorStateStore(client *RedisClient) *RedisIndicatorStateStore {
+	return &RedisIndicatorStateStore{client: client}
+}
+
+// SaveBatch writes snapshots into the hash in one round trip.
+func (s *RedisIndicatorStateStore) SaveBatch(ctx context.Context, records []*IndicatorStateRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	fields := make([]any, 0, len(records)*2)
+	now := time.Now().UTC()
+	for _, rec := range records {
+		rec.UpdatedAt = now
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to encode indicator state %s %s: %w", rec.Symbol, rec.Interval, err)
+		}
+		fields = append(fields, rec.Symbol+"|"+rec.Interval, data)
+	}
+
+	if err := s.client.Client().HSet(ctx, indicatorStateKey, fields...).Err(); err != nil {
+		return fmt.Errorf("failed to save indicator state: %w", err)
+	}
+	return nil
+}
+
+// LoadAll returns every stored snapshot. Unreadable entries are skipped.
+func (s *RedisIndicatorStateStore) LoadAll(ctx context.Context) ([]*IndicatorStateRecord, error) {
+	entries, err := s.client.Client().HGetAll(ctx, indicatorStateKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indicator state: %w", err)
+	}
+
+	result := make([]*IndicatorStateRecord, 0, len(entries))
+	for _, data := range entries {
+		rec := &IndicatorStateRecord{}
+		if err := json.Unmarshal([]byte(data), rec); err != nil {
+			continue
+		}
+		result = append(result, rec)
+	}
+	return result, nil
+}
@@ -0,0 +1,222 @@
+// model run persistence — logs every ML drift check and, for checks that
+// triggered a retrain, the candidate's validation and promotion decision.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ModelRunRecord is a drift_checks row joined with the retrain_runs row it
+// triggered, if any. Accuracies are 0-1.
+type ModelRunRecord struct {
+	ID                  int // retrain_runs.id, 0 when no retrain ran
+	DriftCheckID        int
+	Symbol              string
+	Timeframe           string
+	DriftDetected       bool
+	DriftReason         string
+	DriftRecommendation string
+	DriftChecks         map[string]interface{} // stored as JSONB
+	Staged              bool
+	CandidateAccuracy   float64
+	DeployedAccuracy    float64
+	WalkForwardAccuracy float64
+	WalkForwardFolds    int
+	Outcome             string // failed, rejected, promoted or skipped; empty without drift
+	Reason              string
+	PromotedBy          string
+	CreatedAt           time.Time
+}
+
+// ModelRunRepository handles drift check and retrain history persistence.
+type ModelRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewModelRunRepository(pool *pgxpool.Pool) *ModelRunRepository {
+	return &ModelRunRepository{pool: pool}
+}
+
+// Insert logs the drift check and, when a retrain was attempted (Outcome is
+// set), the retrain run. Sets rec.DriftCheckID and rec.ID.
+func (r *ModelRunRepository) Insert(ctx context.Context, rec *ModelRunRecord) error {
+	var checks []byte
+	if len(rec.DriftChecks) > 0 {
+		b, err := json.Marshal(rec.DriftChecks)
+		if err != nil {
+			return fmt.Errorf("failed to encode drift checks: %w", err)
+		}
+		checks = b
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO drift_checks (drift_detected, reason, recommendation, checks, symbol, timeframe)
+		VALUES ($1, $2, $3, COALESCE($4::jsonb, '{}'::jsonb), $5, $6)
+		RETURNING id, checked_at`,
+		rec.DriftDetected, rec.DriftReason, rec.DriftRecommendation, checks, rec.Symbol, rec.Timeframe,
+	).Scan(&rec.DriftCheckID, &rec.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert drift check: %w", err)
+	}
+
+	if rec.Outcome != "" {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO retrain_runs (
+				drift_check_id, symbol, timeframe, success, reason, promoted,
+				new_model_metrics, current_model_metrics, staged,
+				walk_forward_accuracy, walk_forward_folds, outcome,
+				promoted_by, promoted_at, completed_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6,
+				jsonb_build_object('direction_accuracy', $7::double precision),
+				jsonb_build_object('direction_accuracy', $8::double precision), $9,
+				$10, $11, $12,
+				$13, CASE WHEN $6 THEN NOW() END, NOW()
+			)
+			RETURNING id`,
+			rec.DriftCheckID, rec.Symbol, rec.Timeframe, rec.Staged, nullStr(rec.Reason), rec.Outcome == "promoted",
+			rec.CandidateAccuracy, rec.DeployedAccuracy, rec.Staged,
+			nullFloat(rec.WalkForwardAccuracy), rec.WalkForwardFolds, nullStr(rec.Outcome),
+			nullStr(rec.PromotedBy),
+		).Scan(&rec.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert retrain run: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// LatestStaged returns the most recent retrain run that staged a candidate,
+// or nil if there is none.
+func (r *ModelRunRepository) LatestStaged(ctx context.Context) (*ModelRunRecord, error) {
+	query := `
+		SELECT rr.id, COALESCE(rr.drift_check_id, 0), COALESCE(rr.symbol, ''), COALESCE(rr.timeframe, ''),
+		       COALESCE(dc.reason, ''), COALESCE(dc.recommendation, ''),
+		       COALESCE((rr.new_model_metrics->>'direction_accuracy')::double precision, 0),
+		       COALESCE((rr.current_model_metrics->>'direction_accuracy')::double precision, 0),
+		       COALESCE(rr.walk_forward_accuracy, 0), COALESCE(rr.walk_forward_folds, 0),
+		       COALESCE(rr.outcome, ''), COALESCE(rr.reason, ''), COALESCE(rr.promoted_by, ''),
+		       rr.started_at
+		FROM retrain_runs rr
+		LEFT JOIN drift_checks dc ON dc.id = rr.drift_check_id
+		WHERE rr.staged = TRUE
+		ORDER BY rr.id DESC
+		LIMIT 1`
+
+	rec := &ModelRunRecord{DriftDetected: true, Staged: true}
+	err := r.pool.QueryRow(ctx, query).Scan(
+		&rec.ID, &rec.DriftCheckID, &rec.Symbol, &rec.Timeframe,
+		&rec.DriftReason, &rec.DriftRecommendation,
+		&rec.CandidateAccuracy, &rec.DeployedAccuracy,
+		&rec.WalkForwardAccuracy, &rec.WalkForwardFolds,
+		&rec.Outcome, &rec.Reason, &rec.PromotedBy,
+		&rec.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged retrain run: %w", err)
+	}
+	return rec, nil
+}
+
+// MarkPromoted records a manual promotion of the run's candidate.
+func (r *ModelRunRepository) MarkPromoted(ctx context.Context, id int, by string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE retrain_runs
+		SET promoted = TRUE, outcome = 'promoted', promoted_by = $2, promoted_at = NOW()
+		WHERE id = $1`,
+		id, by)
+	if err != nil {
+		return fmt.Errorf("failed to mark retrain run %d promoted: %w", id, err)
+	}
+	return nil
+}
+
+// modelRunColumns selects a drift check with the retrain run it triggered
+const modelRunColumns = `
+	COALESCE(rr.id, 0), dc.id, COALESCE(dc.symbol, ''), COALESCE(dc.timeframe, ''),
+	dc.drift_detected, dc.reason, dc.recommendation, COALESCE(dc.checks, '{}'::jsonb),
+	COALESCE(rr.staged, FALSE),
+	COALESCE((rr.new_model_metrics->>'direction_accuracy')::double precision, 0),
+	COALESCE((rr.current_model_metrics->>'direction_accuracy')::double precision, 0),
+	COALESCE(rr.walk_forward_accuracy, 0), COALESCE(rr.walk_forward_folds, 0),
+	COALESCE(rr.outcome, ''), COALESCE(rr.reason, ''), COALESCE(rr.promoted_by, ''),
+	dc.checked_at`
+
+// LatestDriftReports returns the most recent drift check for every symbol and
+// timeframe. An empty symbol matches all symbols.
+func (r *ModelRunRepository) LatestDriftReports(ctx context.Context, symbol string) ([]*ModelRunRecord, error) {
+	query := `
+		SELECT ` + modelRunColumns + `
+		FROM (
+			SELECT DISTINCT ON (symbol, timeframe) *
+			FROM drift_checks
+			WHERE symbol IS NOT NULL AND ($1 = '' OR symbol = $1)
+			ORDER BY symbol, timeframe, checked_at DESC
+		) dc
+		LEFT JOIN retrain_runs rr ON rr.drift_check_id = dc.id
+		ORDER BY dc.symbol, dc.timeframe`
+
+	return r.queryRuns(ctx, "latest drift reports", query, symbol)
+}
+
+// DriftHistory returns drift checks newest first. Empty symbol or timeframe match all.
+func (r *ModelRunRepository) DriftHistory(ctx context.Context, symbol, timeframe string, limit int) ([]*ModelRunRecord, error) {
+	query := `
+		SELECT ` + modelRunColumns + `
+		FROM drift_checks dc
+		LEFT JOIN retrain_runs rr ON rr.drift_check_id = dc.id
+		WHERE ($1 = '' OR dc.symbol = $1) AND ($2 = '' OR dc.timeframe = $2)
+		ORDER BY dc.checked_at DESC
+		LIMIT $3`
+
+	return r.queryRuns(ctx, "drift history", query, symbol, timeframe, limit)
+}
+
+func (r *ModelRunRepository) queryRuns(ctx context.Context, what, query string, args ...interface{}) ([]*ModelRunRecord, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", what, err)
+	}
+	defer rows.Close()
+
+	var results []*ModelRunRecord
+	for rows.Next() {
+		rec := &ModelRunRecord{}
+		var checks []byte
+		if err := rows.Scan(
+			&rec.ID, &rec.DriftCheckID, &rec.Symbol, &rec.Timeframe,
+			&rec.DriftDetected, &rec.DriftReason, &rec.DriftRecommendation, &checks,
+			&rec.Staged, &rec.CandidateAccuracy, &rec.DeployedAccuracy,
+			&rec.WalkForwardAccuracy, &rec.WalkForwardFolds,
+			&rec.Outcome, &rec.Reason, &rec.PromotedBy,
+			&rec.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", what, err)
+		}
+		if err := json.Unmarshal(checks, &rec.DriftChecks); err != nil {
+			return nil, fmt.Errorf("failed to decode drift checks for check %d: %w", rec.DriftCheckID, err)
+		}
+		results = append(results, rec)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,103 @@
+// pre-filter check persistence — logs every setup score computed before the
+// claude call so the threshold can be tuned against the moves that followed.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PreFilterCheckRecord is a flat row for the prefilter_checks table.
+type PreFilterCheckRecord struct {
+	ID         int
+	Symbol     string
+	Timeframe  string
+	Score      float64
+	Threshold  float64
+	Skipped    bool
+	Components map[string]float64 // stored as JSONB
+	Signals    []string
+	Price      float64
+	CheckedAt  time.Time
+}
+
+// PreFilterRepository handles pre-filter check persistence.
+type PreFilterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPreFilterRepository(pool *pgxpool.Pool) *PreFilterRepository {
+	return &PreFilterRepository{pool: pool}
+}
+
+// Insert logs a check. Sets rec.ID and rec.CheckedAt.
+func (r *PreFilterRepository) Insert(ctx context.Context, rec *PreFilterCheckRecord) error {
+	components, err := json.Marshal(rec.Components)
+	if err != nil {
+		return fmt.Errorf("failed to encode pre-filter components: %w", err)
+	}
+	signals := rec.Signals
+	if signals == nil {
+		signals = []string{}
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO prefilter_checks (symbol, timeframe, score, threshold, skipped, components, signals, price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, checked_at`,
+		rec.Symbol, rec.Timeframe, rec.Score, rec.Threshold, rec.Skipped, components, signals, rec.Price,
+	).Scan(&rec.ID, &rec.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert pre-filter check: %w", err)
+	}
+	return nil
+}
+
+// PreFilterOutcomeRow is a check with the stored close one horizon later.
+type PreFilterOutcomeRow struct {
+	Symbol     string
+	Score      float64
+	Skipped    bool
+	PriceAt    float64
+	PriceAfter float64
+	CheckedAt  time.Time
+}
+
+// Outcomes loads checks since the given time with the close of the stored
+// candle (of the given interval) one horizon after each check. Checks whose
+// horizon hasn't passed, or without stored candles, are left out. An empty
+// symbol matches all symbols.
+func (r *PreFilterRepository) Outcomes(ctx context.Context, symbol, interval string, horizon time.Duration, since time.Time) ([]*PreFilterOutcomeRow, error) {
+	query := `
+		SELECT p.symbol, p.score, p.skipped, p.price, c.close, p.checked_at
+		FROM prefilter_checks p
+		JOIN LATERAL (
+			SELECT close FROM candles
+			WHERE symbol = p.symbol AND interval = $3 AND time <= p.checked_at + $4 * interval '1 second'
+			ORDER BY time DESC LIMIT 1
+		) c ON TRUE
+		WHERE p.checked_at >= $1
+		  AND p.checked_at + $4 * interval '1 second' <= NOW()
+		  AND ($2 = '' OR p.symbol = $2)
+		ORDER BY p.checked_at`
+
+	rows, err := r.pool.Query(ctx, query, since, symbol, interval, horizon.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pre-filter outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*PreFilterOutcomeRow
+	for rows.Next() {
+		o := &PreFilterOutcomeRow{}
+		if err := rows.Scan(&o.Symbol, &o.Score, &o.Skipped, &o.PriceAt, &o.PriceAfter, &o.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pre-filter outcome row: %w", err)
+		}
+		results = append(results, o)
+	}
+	return results, rows.Err()
+}
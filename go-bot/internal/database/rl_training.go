@@ -0,0 +1,64 @@
+// rl training persistence — logs every RL agent training run with the market
+// data it was trained on.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RLTrainingRecord is a row in the rl_training_episodes table.
+type RLTrainingRecord struct {
+	ID              int
+	Success         bool
+	Reason          string
+	Episodes        int
+	AvgRewardLast20 float64
+	AvgPnLLast20    float64
+	BestPnL         float64
+	FinalEpsilon    float64
+	InitialBalance  float64
+	Symbol          string
+	Interval        string
+	From            time.Time
+	To              time.Time
+	CandleCount     int
+	StartedAt       time.Time
+	CompletedAt     time.Time
+}
+
+// RLTrainingRepository handles RL training run persistence.
+type RLTrainingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRLTrainingRepository(pool *pgxpool.Pool) *RLTrainingRepository {
+	return &RLTrainingRepository{pool: pool}
+}
+
+// Insert writes a training run. Returns the auto-generated ID.
+func (r *RLTrainingRepository) Insert(ctx context.Context, rec *RLTrainingRecord) (int, error) {
+	query := `
+		INSERT INTO rl_training_episodes (
+			success, reason, episodes, avg_reward_last_20, avg_pnl_last_20,
+			best_pnl, final_epsilon, initial_balance,
+			symbol, interval, from_time, to_time, candle_count,
+			started_at, completed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`
+
+	var id int
+	err := r.pool.QueryRow(ctx, query,
+		rec.Success, nullStr(rec.Reason), rec.Episodes, rec.AvgRewardLast20, rec.AvgPnLLast20,
+		rec.BestPnL, rec.FinalEpsilon, rec.InitialBalance,
+		rec.Symbol, rec.Interval, rec.From, rec.To, rec.CandleCount,
+		rec.StartedAt, rec.CompletedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert rl training run: %w", err)
+	}
+	return id, nil
+}
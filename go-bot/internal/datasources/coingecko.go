@@ -133,6 +133,59 @@ func (cg *CoinGeckoProvider) GetMarketData(ctx context.Context, symbol string) (
 	return result, nil
 }
 
+// CoinGeckoGlobal holds market-wide data from CoinGecko.
+type CoinGeckoGlobal struct {
+	TotalMarketCap     float64 // USD
+	TotalVolume        float64 // USD, 24h
+	MarketCapChange24h float64 // percent
+	BTCDominance       float64 // BTC share of total market cap, percent
+	ETHDominance       float64
+}
+
+// coingecko /global response
+type cgGlobalResponse struct {
+	Data struct {
+		TotalMarketCap                  map[string]float64 `json:"total_market_cap"`
+		TotalVolume                     map[string]float64 `json:"total_volume"`
+		MarketCapPercentage             map[string]float64 `json:"market_cap_percentage"`
+		MarketCapChangePercentage24hUSD float64            `json:"market_cap_change_percentage_24h_usd"`
+	} `json:"data"`
+}
+
+// GetGlobal fetches total market cap, its 24h change and BTC/ETH dominance.
+func (cg *CoinGeckoProvider) GetGlobal(ctx context.Context) (*CoinGeckoGlobal, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cg.baseURL+"/global", nil)
+	if err != nil {
+		return nil, err
+	}
+	if cg.apiKey != "" {
+		req.Header.Set("x-cg-pro-api-key", cg.apiKey)
+	}
+
+	resp, err := cg.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("coingecko request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko returned %d", resp.StatusCode)
+	}
+
+	var data cgGlobalResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("coingecko decode failed: %w", err)
+	}
+
+	return &CoinGeckoGlobal{
+		TotalMarketCap:     data.Data.TotalMarketCap["usd"],
+		TotalVolume:        data.Data.TotalVolume["usd"],
+		MarketCapChange24h: data.Data.MarketCapChangePercentage24hUSD,
+		BTCDominance:       data.Data.MarketCapPercentage["btc"],
+		ETHDominance:       data.Data.MarketCapPercentage["eth"],
+	}, nil
+}
+
 // GetMetrics implements OnChainProvider using CoinGecko data.
 // Maps market + community data to on-chain-like metrics.
 func (cg *CoinGeckoProvider) GetMetrics(ctx context.Context, symbol string) (*OnChainMetrics, error) {
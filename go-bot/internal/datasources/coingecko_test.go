@@ -86,6 +86,36 @@ func TestCoinGeckoGetMetrics(t *testing.T) {
 	}
 }
 
+func TestCoinGeckoGetGlobal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/global" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {
+			"total_market_cap": {"usd": 2400000000000},
+			"total_volume": {"usd": 90000000000},
+			"market_cap_percentage": {"btc": 54.2, "eth": 16.8},
+			"market_cap_change_percentage_24h_usd": -1.75
+		}}`))
+	}))
+	defer srv.Close()
+
+	cg := NewCoinGeckoProvider("")
+	cg.baseURL = srv.URL
+
+	global, err := cg.GetGlobal(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if global.TotalMarketCap != 2400000000000 || global.MarketCapChange24h != -1.75 {
+		t.Errorf("unexpected market cap data %+v", global)
+	}
+	if global.BTCDominance != 54.2 || global.ETHDominance != 16.8 {
+		t.Errorf("unexpected dominance %+v", global)
+	}
+}
+
 func TestCoinGeckoSupportedSymbols(t *testing.T) {
 	cg := NewCoinGeckoProvider("")
 	syms := cg.SupportedSymbols()
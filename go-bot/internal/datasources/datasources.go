@@ -33,17 +33,26 @@ type OnChainProvider interface {
 
 // OrderFlowSnapshot captures buy/sell pressure and market depth.
 type OrderFlowSnapshot struct {
-	Symbol          string    `json:"symbol"`
-	BuyVolume       float64   `json:"buy_volume"`       // taker buy volume (aggressor buys)
-	SellVolume      float64   `json:"sell_volume"`      // taker sell volume (aggressor sells)
-	BuySellRatio    float64   `json:"buy_sell_ratio"`   // >1 = more buyers
-	LargeBuyOrders  int       `json:"large_buy_orders"` // orders > $50k
-	LargeSellOrders int       `json:"large_sell_orders"`
-	BidDepthUSD     float64   `json:"bid_depth_usd"`    // total bid liquidity within 1%
-	AskDepthUSD     float64   `json:"ask_depth_usd"`    // total ask liquidity within 1%
-	DepthImbalance  float64   `json:"depth_imbalance"`  // (bid-ask)/(bid+ask), positive = buy wall
-	SpreadBps       float64   `json:"spread_bps"`       // current spread in basis points
-	FetchedAt       time.Time `json:"fetched_at"`
+	Symbol          string          `json:"symbol"`
+	BuyVolume       float64         `json:"buy_volume"`       // taker buy volume (aggressor buys)
+	SellVolume      float64         `json:"sell_volume"`      // taker sell volume (aggressor sells)
+	BuySellRatio    float64         `json:"buy_sell_ratio"`   // >1 = more buyers
+	LargeBuyOrders  int             `json:"large_buy_orders"` // orders > $50k
+	LargeSellOrders int             `json:"large_sell_orders"`
+	BidDepthUSD     float64         `json:"bid_depth_usd"`   // total bid liquidity within 1%
+	AskDepthUSD     float64         `json:"ask_depth_usd"`   // total ask liquidity within 1%
+	DepthImbalance  float64         `json:"depth_imbalance"` // (bid-ask)/(bid+ask), positive = buy wall
+	SpreadBps       float64         `json:"spread_bps"`      // current spread in basis points
+	Walls           []OrderBookWall `json:"walls,omitempty"` // outsized resting orders, nearest first
+	FetchedAt       time.Time       `json:"fetched_at"`
+}
+
+// OrderBookWall is a price level holding far more size than its neighbours.
+type OrderBookWall struct {
+	Side     string  `json:"side"` // "bid" or "ask"
+	Price    float64 `json:"price"`
+	SizeUSD  float64 `json:"size_usd"`
+	Multiple float64 `json:"multiple"` // size relative to the side's average level
 }
 
 // OrderFlowProvider fetches real-time order flow data.
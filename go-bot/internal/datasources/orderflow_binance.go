@@ -63,7 +63,7 @@ func (b *BinanceOrderFlow) GetSnapshot(ctx context.Context, symbol string) (*Ord
 	tradeCh := make(chan tradesResult, 1)
 
 	go func() {
-		d, err := b.fetchDepth(ctx, sym, 20)
+		d, err := b.fetchDepth(ctx, sym, wallDepthLevels)
 		depthCh <- depthResult{d, err}
 	}()
 
@@ -87,8 +87,9 @@ func (b *BinanceOrderFlow) GetSnapshot(ctx context.Context, symbol string) (*Ord
 		FetchedAt: time.Now(),
 	}
 
-	// compute order book depth
-	snap.BidDepthUSD, snap.AskDepthUSD = computeDepthUSD(dr.depth)
+	// compute order book depth near the touch, walls over the whole fetched book
+	snap.BidDepthUSD, snap.AskDepthUSD = computeDepthUSD(dr.depth, depthLevels)
+	snap.Walls = detectWalls(dr.depth)
 	if snap.BidDepthUSD+snap.AskDepthUSD > 0 {
 		snap.DepthImbalance = (snap.BidDepthUSD - snap.AskDepthUSD) / (snap.BidDepthUSD + snap.AskDepthUSD)
 	}
@@ -178,13 +179,26 @@ func (b *BinanceOrderFlow) fetchAggTrades(ctx context.Context, symbol string, li
 	return trades, nil
 }
 
-func computeDepthUSD(depth *binanceDepthResponse) (bidUSD, askUSD float64) {
-	for _, entry := range depth.Bids {
+const (
+	depthLevels     = 20  // levels summed into bid/ask depth
+	wallDepthLevels = 100 // levels fetched and scanned for walls
+	wallMultiple    = 4.0 // a level this many times the side's average is a wall
+	maxWallsPerSide = 3
+)
+
+func computeDepthUSD(depth *binanceDepthResponse, levels int) (bidUSD, askUSD float64) {
+	for i, entry := range depth.Bids {
+		if i >= levels {
+			break
+		}
 		price := parseFloat(entry[0])
 		qty := parseFloat(entry[1])
 		bidUSD += price * qty
 	}
-	for _, entry := range depth.Asks {
+	for i, entry := range depth.Asks {
+		if i >= levels {
+			break
+		}
 		price := parseFloat(entry[0])
 		qty := parseFloat(entry[1])
 		askUSD += price * qty
@@ -192,6 +206,44 @@ func computeDepthUSD(depth *binanceDepthResponse) (bidUSD, askUSD float64) {
 	return
 }
 
+// detectWalls returns the levels on each side holding at least wallMultiple
+// times that side's average level size, nearest to the touch first
+func detectWalls(depth *binanceDepthResponse) []OrderBookWall {
+	var walls []OrderBookWall
+	walls = append(walls, sideWalls("bid", depth.Bids)...)
+	walls = append(walls, sideWalls("ask", depth.Asks)...)
+	return walls
+}
+
+func sideWalls(side string, entries []binanceDepthEntry) []OrderBookWall {
+	if len(entries) == 0 {
+		return nil
+	}
+	prices := make([]float64, len(entries))
+	sizes := make([]float64, len(entries))
+	var total float64
+	for i, entry := range entries {
+		prices[i] = parseFloat(entry[0])
+		sizes[i] = prices[i] * parseFloat(entry[1])
+		total += sizes[i]
+	}
+	avg := total / float64(len(entries))
+	if avg <= 0 {
+		return nil
+	}
+
+	var walls []OrderBookWall
+	for i := range entries {
+		if multiple := sizes[i] / avg; multiple >= wallMultiple {
+			walls = append(walls, OrderBookWall{Side: side, Price: prices[i], SizeUSD: sizes[i], Multiple: multiple})
+			if len(walls) == maxWallsPerSide {
+				break
+			}
+		}
+	}
+	return walls
+}
+
 func normalizeBinanceSymbol(symbol string) string {
 	result := make([]byte, 0, len(symbol))
 	for i := 0; i < len(symbol); i++ {
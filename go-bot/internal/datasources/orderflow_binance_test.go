@@ -132,13 +132,48 @@ func TestComputeDepthUSD(t *testing.T) {
 			{"101.00", "8.0"}, // 808 USD
 		},
 	}
-	bidUSD, askUSD := computeDepthUSD(depth)
+	bidUSD, askUSD := computeDepthUSD(depth, depthLevels)
 	if bidUSD < 1494 || bidUSD > 1496 {
 		t.Errorf("expected bid depth ~1495, got %f", bidUSD)
 	}
 	if askUSD < 807 || askUSD > 809 {
 		t.Errorf("expected ask depth ~808, got %f", askUSD)
 	}
+
+	// only the first levels count
+	bidUSD, _ = computeDepthUSD(depth, 1)
+	if bidUSD < 999 || bidUSD > 1001 {
+		t.Errorf("expected top-level bid depth ~1000, got %f", bidUSD)
+	}
+}
+
+func TestDetectWalls(t *testing.T) {
+	depth := &binanceDepthResponse{
+		Bids: []binanceDepthEntry{
+			{"100.00", "1.0"},
+			{"99.00", "1.0"},
+			{"98.00", "20.0"}, // wall
+			{"97.00", "1.0"},
+			{"96.00", "1.0"},
+			{"95.00", "1.0"},
+		},
+		Asks: []binanceDepthEntry{
+			{"101.00", "1.0"},
+			{"102.00", "1.5"},
+			{"103.00", "1.0"},
+		},
+	}
+	walls := detectWalls(depth)
+	if len(walls) != 1 {
+		t.Fatalf("expected 1 wall, got %+v", walls)
+	}
+	w := walls[0]
+	if w.Side != "bid" || w.Price != 98 || w.SizeUSD != 1960 {
+		t.Errorf("unexpected wall %+v", w)
+	}
+	if w.Multiple < 4 {
+		t.Errorf("expected multiple >= 4, got %.2f", w.Multiple)
+	}
 }
 
 func TestNormalizeBinanceSymbol(t *testing.T) {
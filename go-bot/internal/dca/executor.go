@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/opportunity"
 )
@@ -80,6 +82,8 @@ type Executor struct {
 	plans    map[string]*Plan
 	config   Config
 	price    PriceProvider
+	clock    clock.Clock
+	calendar *calendar.Calendar
 	stopCh   chan struct{}
 	running  bool
 	onRound  Callback
@@ -91,6 +95,7 @@ func NewExecutor(cfg Config, price PriceProvider) *Executor {
 		plans:  make(map[string]*Plan),
 		config: cfg,
 		price:  price,
+		clock:  clock.Real(),
 		stopCh: make(chan struct{}),
 	}
 }
@@ -102,6 +107,21 @@ func (e *Executor) OnRound(cb Callback) {
 	e.onRound = cb
 }
 
+// SetClock sets the time source for plan timestamps and the round scheduler.
+// Call before Start.
+func (e *Executor) SetClock(c clock.Clock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clock = c
+}
+
+// SetCalendar holds back rounds during event blackout windows. Call before Start.
+func (e *Executor) SetCalendar(c *calendar.Calendar) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calendar = c
+}
+
 // CreatePlan builds a DCA plan from an approved opportunity
 func (e *Executor) CreatePlan(opp *opportunity.Opportunity) (*Plan, error) {
 	if opp.Result == nil || opp.Result.Decision == nil {
@@ -125,7 +145,7 @@ func (e *Executor) CreatePlan(opp *opportunity.Opportunity) (*Plan, error) {
 		TakeProfit:    opp.Result.Decision.Plan.TakeProfit,
 		TotalSize:     totalSize,
 		Status:        "active",
-		CreatedAt:     time.Now(),
+		CreatedAt:     e.clock.Now(),
 	}
 
 	e.mu.Lock()
@@ -202,8 +222,17 @@ func (e *Executor) ExecuteRound(ctx context.Context, plan *Plan, placer OrderPla
 		e.mu.Unlock()
 		return nil
 	}
+	cal := e.calendar
 	e.mu.Unlock()
 
+	// every round adds to the position, so none fill around high-impact events;
+	// the round stays pending and runs once the window closes
+	if cal != nil {
+		if ok, reason := cal.AllowEntry(plan.Symbol); !ok {
+			return fmt.Errorf("event blackout: %s", reason)
+		}
+	}
+
 	// check price condition
 	if e.config.PriceDropPct > 0 && plan.AvgEntryPrice > 0 {
 		ticker, err := e.price.GetPrice(ctx, plan.Symbol)
@@ -238,7 +267,7 @@ func (e *Executor) ExecuteRound(ctx context.Context, plan *Plan, placer OrderPla
 		return fmt.Errorf("place order round %d: %w", round.Number, err)
 	}
 
-	now := time.Now()
+	now := e.clock.Now()
 	round.Executed = true
 	round.Price = order.AvgPrice
 	round.ExecutedAt = &now
@@ -307,14 +336,14 @@ func (e *Executor) Stop() {
 
 // loop checks for pending rounds at the configured interval
 func (e *Executor) loop() {
-	ticker := time.NewTicker(e.config.Interval)
+	ticker := e.clock.NewTicker(e.config.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-e.stopCh:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			e.tickPlans()
 		}
 	}
@@ -340,7 +369,7 @@ func (e *Executor) tickPlans() {
 				lastExec = *r.ExecutedAt
 			}
 		}
-		if lastExec.IsZero() || time.Since(lastExec) >= e.config.Interval {
+		if lastExec.IsZero() || e.clock.Since(lastExec) >= e.config.Interval {
 			// plan is ready for next round — handled by the caller via ExecuteRound
 			// we just track the readiness state
 			_ = plan // placeholder for notification/callback integration
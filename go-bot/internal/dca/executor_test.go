@@ -2,10 +2,13 @@ package dca
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/opportunity"
 	"github.com/trading-bot/go-bot/internal/pipeline"
@@ -111,6 +114,32 @@ func TestExecuteRound(t *testing.T) {
 	}
 }
 
+type eventSource []calendar.Event
+
+func (s eventSource) Name() string                                   { return "test" }
+func (s eventSource) Load(context.Context) ([]calendar.Event, error) { return s, nil }
+
+func TestExecuteRoundEventBlackout(t *testing.T) {
+	exec := NewExecutor(DefaultConfig(), &mockPriceProvider{})
+	opp := makeTestOpp()
+	plan, _ := exec.CreatePlan(opp)
+
+	cal := calendar.New(calendar.DefaultConfig())
+	cal.AddSource(eventSource{{Title: "FOMC", Time: time.Now().Add(20 * time.Minute), Importance: calendar.High}})
+	if err := cal.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exec.SetCalendar(cal)
+
+	err := exec.ExecuteRound(context.Background(), plan, &mockOrderPlacer{})
+	if err == nil || !strings.Contains(err.Error(), "event blackout: FOMC") {
+		t.Fatalf("expected the blackout to hold the round, got %v", err)
+	}
+	if r := plan.Rounds[0]; r.Executed || r.Skipped {
+		t.Errorf("round 1 should stay pending for after the blackout, got %+v", r)
+	}
+}
+
 func TestExecuteAllRounds(t *testing.T) {
 	exec := NewExecutor(DefaultConfig(), &mockPriceProvider{})
 	opp := makeTestOpp()
@@ -134,6 +163,32 @@ func TestExecuteAllRounds(t *testing.T) {
 	}
 }
 
+func TestSimulatedClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	exec := NewExecutor(DefaultConfig(), &mockPriceProvider{})
+	exec.SetClock(clk)
+
+	plan, _ := exec.CreatePlan(makeTestOpp())
+	if !plan.CreatedAt.Equal(start) {
+		t.Errorf("expected CreatedAt %v, got %v", start, plan.CreatedAt)
+	}
+
+	clk.Advance(2 * time.Hour)
+	if err := exec.ExecuteRound(context.Background(), plan, &mockOrderPlacer{}); err != nil {
+		t.Fatalf("ExecuteRound failed: %v", err)
+	}
+	if got := plan.Rounds[0].ExecutedAt; got == nil || !got.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected ExecutedAt %v, got %v", start.Add(2*time.Hour), got)
+	}
+
+	// the scheduler runs on the injected clock, so it ticks without sleeping
+	exec.Start()
+	clk.BlockUntil(1)
+	clk.Advance(3 * DefaultConfig().Interval)
+	exec.Stop()
+}
+
 func TestCancelPlan(t *testing.T) {
 	exec := NewExecutor(DefaultConfig(), &mockPriceProvider{})
 	opp := makeTestOpp()
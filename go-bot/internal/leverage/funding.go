@@ -3,6 +3,8 @@ package leverage
 import (
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // records funding fee payments per position and provides cumulative totals.
@@ -18,15 +20,24 @@ type FundingPayment struct {
 type FundingTracker struct {
 	mu       sync.Mutex
 	payments map[string][]FundingPayment // position id -> payments
+	clock    clock.Clock
 }
 
 // creates a new funding tracker with an initialized payments map
 func NewFundingTracker() *FundingTracker {
 	return &FundingTracker{
 		payments: make(map[string][]FundingPayment),
+		clock:    clock.Real(),
 	}
 }
 
+// sets the time source used for payment timestamps and funding windows
+func (t *FundingTracker) SetClock(c clock.Clock) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = c
+}
+
 // records a funding fee payment for a position.
 // the amount is calculated as rate * notional.
 func (t *FundingTracker) RecordPayment(positionID string, rate float64, notional float64) {
@@ -38,7 +49,7 @@ func (t *FundingTracker) RecordPayment(positionID string, rate float64, notional
 		PositionID: positionID,
 		Rate:       rate,
 		Amount:     amount,
-		Timestamp:  time.Now().UTC(),
+		Timestamp:  t.clock.Now().UTC(),
 	}
 	t.payments[positionID] = append(t.payments[positionID], payment)
 }
@@ -87,7 +98,7 @@ func (t *FundingTracker) IsFundingDue(positionID string) bool {
 	}
 
 	lastPayment := payments[len(payments)-1].Timestamp
-	now := time.Now().UTC()
+	now := t.clock.Now().UTC()
 
 	// find the most recent funding time that is at or before now
 	latestFunding := mostRecentFundingTime(now)
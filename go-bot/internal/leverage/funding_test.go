@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 func TestNewFundingTracker(t *testing.T) {
@@ -164,6 +166,39 @@ func TestIsFundingDue_OldPayment(t *testing.T) {
 	}
 }
 
+func TestIsFundingDue_SimulatedWindows(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	tracker := NewFundingTracker()
+	tracker.SetClock(clk)
+
+	tracker.RecordPayment("pos-1", 0.0001, 10000.0)
+	if got := tracker.Payments("pos-1")[0].Timestamp; !got.Equal(clk.Now()) {
+		t.Fatalf("payment timestamp = %v, want simulated now %v", got, clk.Now())
+	}
+
+	// 15:59 is still inside the 08:00 window
+	clk.Set(time.Date(2024, 3, 1, 15, 59, 0, 0, time.UTC))
+	if tracker.IsFundingDue("pos-1") {
+		t.Error("funding should not be due before the 16:00 window")
+	}
+
+	clk.Set(time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC))
+	if !tracker.IsFundingDue("pos-1") {
+		t.Error("funding should be due at 16:00")
+	}
+
+	// paying at 16:00 closes the window until midnight
+	tracker.RecordPayment("pos-1", 0.0001, 10000.0)
+	clk.Advance(7 * time.Hour)
+	if tracker.IsFundingDue("pos-1") {
+		t.Error("funding should not be due at 23:00")
+	}
+	clk.Advance(time.Hour)
+	if !tracker.IsFundingDue("pos-1") {
+		t.Error("funding should be due at midnight")
+	}
+}
+
 func TestMostRecentFundingTime(t *testing.T) {
 	tests := []struct {
 		name     string
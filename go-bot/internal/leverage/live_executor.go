@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/binance"
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 )
 
@@ -41,8 +43,10 @@ type LiveExecutor struct {
 	funding   *FundingTracker
 	prices    MarkPriceProvider
 	breaker   *circuitbreaker.Breaker       // nil if no circuit breaker configured
+	calendar  *calendar.Calendar            // nil if no event calendar configured
 	store     LeveragePositionStore          // nil if no persistence configured
 	trades    LeverageTradeLogger            // nil if no logging configured
+	clock     clock.Clock
 	nextID    int
 }
 
@@ -61,14 +65,25 @@ func NewLiveExecutor(
 		safety:    safety,
 		funding:   funding,
 		prices:    prices,
+		clock:     clock.Real(),
 	}
 }
 
+// SetClock sets the time source for open and close timestamps.
+func (e *LiveExecutor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
 // SetCircuitBreaker configures portfolio circuit breaker.
 func (e *LiveExecutor) SetCircuitBreaker(b *circuitbreaker.Breaker) {
 	e.breaker = b
 }
 
+// SetCalendar blocks new entries during event blackout windows.
+func (e *LiveExecutor) SetCalendar(c *calendar.Calendar) {
+	e.calendar = c
+}
+
 // SetStore configures position persistence. Call before Start.
 func (e *LiveExecutor) SetStore(store LeveragePositionStore) {
 	e.store = store
@@ -125,6 +140,13 @@ func (e *LiveExecutor) OpenPosition(
 		}
 	}
 
+	// no new entries around high-impact events
+	if e.calendar != nil {
+		if ok, reason := e.calendar.AllowEntry(symbol); !ok {
+			return nil, fmt.Errorf("event blackout: %s", reason)
+		}
+	}
+
 	// run safety checks if checker is configured
 	if e.safety != nil {
 		result := e.safety.Check(userID, symbol, leverage, margin, markPrice, string(side))
@@ -263,7 +285,7 @@ func (e *LiveExecutor) OpenPosition(
 		MarginType:       "isolated",
 		IsPaper:          false,
 		Status:           "open",
-		OpenedAt:         time.Now(),
+		OpenedAt:         e.clock.Now(),
 		Platform:         platform,
 		MainOrderID:      mainOrder.OrderID,
 		SLOrderID:        slOrderID,
@@ -371,7 +393,7 @@ func (e *LiveExecutor) Close(posID string, reason string) (*LeveragePosition, er
 	pnl -= fundingFees
 
 	e.mu.Lock()
-	now := time.Now()
+	now := e.clock.Now()
 	pos.Status = "closed"
 	pos.CloseReason = reason
 	pos.ClosePrice = closePrice
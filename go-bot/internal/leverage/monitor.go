@@ -7,6 +7,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // event types for leverage position monitoring
@@ -83,6 +85,7 @@ type Monitor struct {
 	prices       MarkPriceProvider
 	funding      *FundingTracker
 	config       MonitorConfig
+	clock        clock.Clock
 	OnEvent      func(LevEvent)
 
 	mu             sync.Mutex
@@ -109,6 +112,7 @@ func NewMonitor(
 		prices:         prices,
 		funding:        funding,
 		config:         config,
+		clock:          clock.Real(),
 		lastNotified:   make(map[string]time.Time),
 		lastAlertLevel: make(map[string]AlertLevel),
 	}
@@ -128,6 +132,13 @@ func WithMarkPriceUpdater(u MarkPriceUpdater) MonitorOption {
 	}
 }
 
+// WithClock sets the time source for the check ticker and notification cooldowns
+func WithClock(c clock.Clock) MonitorOption {
+	return func(m *Monitor) {
+		m.clock = c
+	}
+}
+
 // starts the background monitoring goroutine
 func (m *Monitor) Start(ctx context.Context) {
 	m.mu.Lock()
@@ -170,14 +181,14 @@ func (m *Monitor) run(ctx context.Context) {
 		close(m.done)
 	}()
 
-	ticker := time.NewTicker(m.config.CheckInterval)
+	ticker := m.clock.NewTicker(m.config.CheckInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			m.CheckPositions()
 		}
 	}
@@ -338,14 +349,14 @@ func (m *Monitor) shouldNotify(posID string, level AlertLevel) bool {
 	}
 
 	cooldown := m.cooldownForLevel(level)
-	return time.Since(lastTime) >= cooldown
+	return m.clock.Since(lastTime) >= cooldown
 }
 
 // records a notification timestamp and alert level for a position
 func (m *Monitor) recordNotification(posID string, level AlertLevel) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.lastNotified[posID] = time.Now()
+	m.lastNotified[posID] = m.clock.Now()
 	m.lastAlertLevel[posID] = level
 }
 
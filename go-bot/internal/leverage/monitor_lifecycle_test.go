@@ -7,6 +7,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // --- mark price provider error ---
@@ -304,6 +306,23 @@ func TestMonitor_ShouldNotify_Deescalation(t *testing.T) {
 	}
 }
 
+func TestMonitor_ShouldNotify_CooldownElapses(t *testing.T) {
+	lister := &mockLister{}
+	prices := &mockMarkPrices{prices: make(map[string]float64)}
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	mon := NewMonitor(lister, newMockCloser(), prices, NewFundingTracker(), DefaultMonitorConfig(), WithClock(clk))
+
+	mon.recordNotification("pos_1", AlertWarning)
+	clk.Advance(mon.config.WarningCooldown - time.Second)
+	if mon.shouldNotify("pos_1", AlertWarning) {
+		t.Error("should not notify one second before the cooldown ends")
+	}
+	clk.Advance(time.Second)
+	if !mon.shouldNotify("pos_1", AlertWarning) {
+		t.Error("should notify once the cooldown has elapsed")
+	}
+}
+
 // --- multiple positions ---
 
 func TestMonitor_MultiplePositions(t *testing.T) {
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // dbCtx returns a context with a 5-second timeout for best-effort DB operations
@@ -47,6 +49,8 @@ type PaperExecutor struct {
 	store     LeveragePositionStore
 	trades    LeverageTradeLogger // nil if no logging configured
 	breaker   *circuitbreaker.Breaker // nil if no circuit breaker configured
+	calendar  *calendar.Calendar      // nil if no event calendar configured
+	clock     clock.Clock
 	nextID    int
 }
 
@@ -57,6 +61,7 @@ func NewPaperExecutor(prices PriceProvider, safety *SafetyChecker, funding *Fund
 		prices:    prices,
 		safety:    safety,
 		funding:   funding,
+		clock:     clock.Real(),
 	}
 }
 
@@ -75,6 +80,16 @@ func (e *PaperExecutor) SetCircuitBreaker(b *circuitbreaker.Breaker) {
 	e.breaker = b
 }
 
+// SetCalendar blocks new entries during event blackout windows. Call before Start.
+func (e *PaperExecutor) SetCalendar(c *calendar.Calendar) {
+	e.calendar = c
+}
+
+// SetClock sets the time source for open and close timestamps. Call before Start.
+func (e *PaperExecutor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
 // SetNextID sets the starting ID for new positions (used for recovery).
 func (e *PaperExecutor) SetNextID(id int) {
 	e.mu.Lock()
@@ -119,6 +134,13 @@ func (e *PaperExecutor) OpenPosition(
 		}
 	}
 
+	// no new entries around high-impact events
+	if e.calendar != nil {
+		if ok, reason := e.calendar.AllowEntry(symbol); !ok {
+			return nil, fmt.Errorf("event blackout: %s", reason)
+		}
+	}
+
 	// run safety checks if configured
 	if e.safety != nil {
 		result := e.safety.Check(userID, symbol, leverage, margin, price, string(side))
@@ -152,7 +174,7 @@ func (e *PaperExecutor) OpenPosition(
 		MarginType:       "isolated",
 		IsPaper:          true,
 		Status:           "open",
-		OpenedAt:         time.Now(),
+		OpenedAt:         e.clock.Now(),
 		Platform:         platform,
 	}
 
@@ -230,7 +252,7 @@ func (e *PaperExecutor) Close(posID string, reason string) (*LeveragePosition, e
 	// subtract funding fees from pnl
 	pos.PnL = rawPnL - pos.FundingPaid
 
-	now := time.Now()
+	now := e.clock.Now()
 	pos.Status = "closed"
 	pos.CloseReason = reason
 	pos.ClosePrice = closePrice
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // emergency stop handler - closes all positions for a user and disables trading
@@ -65,6 +67,7 @@ type ConfirmationManager struct {
 	mu            sync.RWMutex
 	confirmations map[int]*Confirmation
 	phrase        string
+	clock         clock.Clock
 }
 
 // the phrase users must type to confirm risk acknowledgment
@@ -74,9 +77,15 @@ func NewConfirmationManager() *ConfirmationManager {
 	return &ConfirmationManager{
 		confirmations: make(map[int]*Confirmation),
 		phrase:        DefaultConfirmPhrase,
+		clock:         clock.Real(),
 	}
 }
 
+// SetClock replaces the time source for confirmation timestamps.
+func (c *ConfirmationManager) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
 // attempts to confirm a user by checking their input against the required phrase
 func (c *ConfirmationManager) Confirm(userID int, input string) bool {
 	if input != c.phrase {
@@ -89,7 +98,7 @@ func (c *ConfirmationManager) Confirm(userID int, input string) bool {
 	c.confirmations[userID] = &Confirmation{
 		UserID:      userID,
 		Confirmed:   true,
-		ConfirmedAt: time.Now(),
+		ConfirmedAt: c.clock.Now(),
 	}
 	return true
 }
@@ -10,8 +10,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/opportunity"
 )
@@ -93,11 +95,13 @@ type Executor struct {
 	safety       *SafetyChecker
 	losses       LossTracker
 	breaker      *circuitbreaker.Breaker // nil if no circuit breaker configured
+	calendar     *calendar.Calendar      // nil if no event calendar configured
 	store        PositionStore           // nil if no persistence configured
 	trades       TradeLogger             // nil if no logging configured
 	slippage     SlippageRecorder        // nil if no slippage tracking configured
 	failedOrders FailedOrderRecorder     // nil if no dead-letter queue configured
 	exchanges    PrimaryExchangeResolver // nil if exchange routing is not wired
+	clock        clock.Clock
 	nextID       int
 }
 
@@ -108,6 +112,7 @@ func NewExecutor(orders exchange.OrderExecutor, keys KeyDecryptor, safety *Safet
 		keys:      keys,
 		safety:    safety,
 		losses:    losses,
+		clock:     clock.Real(),
 	}
 }
 
@@ -116,11 +121,21 @@ func (e *Executor) SetSafetyChecker(safety *SafetyChecker) {
 	e.safety = safety
 }
 
+// SetClock sets the time source for open and close timestamps.
+func (e *Executor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
 // SetCircuitBreaker configures portfolio circuit breaker.
 func (e *Executor) SetCircuitBreaker(b *circuitbreaker.Breaker) {
 	e.breaker = b
 }
 
+// SetCalendar blocks new entries during event blackout windows.
+func (e *Executor) SetCalendar(c *calendar.Calendar) {
+	e.calendar = c
+}
+
 // SetStore configures position persistence. Call before Start.
 func (e *Executor) SetStore(store PositionStore) {
 	e.store = store
@@ -201,6 +216,13 @@ func (e *Executor) Execute(opp *opportunity.Opportunity) (*LivePosition, error)
 		}
 	}
 
+	// no new entries around high-impact events
+	if e.calendar != nil {
+		if ok, reason := e.calendar.AllowEntry(opp.Symbol); !ok {
+			return nil, fmt.Errorf("event blackout: %s", reason)
+		}
+	}
+
 	// run safety checks
 	if e.safety != nil {
 		result := e.safety.Check(opp.UserID, opp.Symbol, plan.PositionSize, asset)
@@ -305,7 +327,7 @@ func (e *Executor) Execute(opp *opportunity.Opportunity) (*LivePosition, error)
 		SLOrderID:    slOrderID,
 		TPOrderID:    tpOrderID,
 		Status:       "open",
-		OpenedAt:     time.Now(),
+		OpenedAt:     e.clock.Now(),
 		Platform:     opp.Platform,
 	}
 
@@ -399,7 +421,7 @@ func (e *Executor) Close(posID string, reason string) (*LivePosition, error) {
 	}
 
 	e.mu.Lock()
-	now := time.Now()
+	now := e.clock.Now()
 	pos.Status = "closed"
 	pos.CloseReason = reason
 	pos.ClosePrice = closeOrder.AvgPrice
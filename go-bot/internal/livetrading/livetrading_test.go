@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/opportunity"
 	"github.com/trading-bot/go-bot/internal/pipeline"
@@ -542,6 +543,24 @@ func TestLossTracker_ResetDaily(t *testing.T) {
 	}
 }
 
+func TestLossTracker_SimulatedDays(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	sim := clock.NewSimulated(day1)
+	tracker := NewLossTracker()
+	tracker.SetClock(sim)
+	tracker.RecordLoss(1, -10)
+
+	sim.Advance(2 * time.Hour)
+	tracker.RecordLoss(1, -4)
+	tracker.ResetDaily()
+	if got := tracker.DailyLoss(1, day1); got != 0 {
+		t.Errorf("yesterday's loss should be reset, got %.2f", got)
+	}
+	if got := tracker.DailyLoss(1, sim.Now()); got != 4 {
+		t.Errorf("today's loss = %.2f, want 4", got)
+	}
+}
+
 // --- executor tests ---
 
 func TestExecutor_Execute(t *testing.T) {
@@ -802,6 +821,30 @@ func TestExecutor_Close(t *testing.T) {
 	}
 }
 
+func TestExecutor_SimulatedClockTimestamps(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sim := clock.NewSimulated(start)
+	exec := NewExecutor(newMockOrders(), newMockKeys(), nil, nil)
+	exec.SetClock(sim)
+
+	pos, err := exec.Execute(testOpp("BTCUSDT", claude.ActionBuy, 41800, 44200, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pos.OpenedAt.Equal(start) {
+		t.Errorf("opened at %v, want %v", pos.OpenedAt, start)
+	}
+
+	sim.Advance(3 * time.Hour)
+	closed, err := exec.Close(pos.ID, "manual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed.ClosedAt == nil || !closed.ClosedAt.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("closed at %v, want 3h after open", closed.ClosedAt)
+	}
+}
+
 func TestExecutor_Close_NotFound(t *testing.T) {
 	exec := NewExecutor(newMockOrders(), newMockKeys(), nil, nil)
 	_, err := exec.Close("nonexistent", "manual")
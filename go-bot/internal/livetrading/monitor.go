@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 )
 
@@ -61,6 +62,7 @@ type Monitor struct {
 	prices   PriceProvider
 	config   MonitorConfig
 	OnEvent  func(Event)
+	clock    clock.Clock
 
 	mu            sync.Mutex
 	lastNotified  map[string]time.Time
@@ -78,11 +80,17 @@ func NewMonitor(executor *Executor, orders exchange.OrderExecutor, keys KeyDecry
 		keys:          keys,
 		prices:        prices,
 		config:        config,
+		clock:         clock.Real(),
 		lastNotified:  make(map[string]time.Time),
 		lastEventType: make(map[string]EventType),
 	}
 }
 
+// SetClock replaces the time source for ticks and cooldowns. Call before Start.
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
 // starts the background monitoring goroutine
 func (m *Monitor) Start(ctx context.Context) {
 	m.mu.Lock()
@@ -124,14 +132,14 @@ func (m *Monitor) run(ctx context.Context) {
 		close(m.done)
 	}()
 
-	ticker := time.NewTicker(m.config.CheckInterval)
+	ticker := m.clock.NewTicker(m.config.CheckInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			m.CheckPositions()
 		}
 	}
@@ -216,11 +224,11 @@ func (m *Monitor) shouldSendPeriodic(pos *LivePosition) bool {
 		return true
 	}
 
-	if time.Since(lastTime) < m.config.CooldownPeriod {
+	if m.clock.Since(lastTime) < m.config.CooldownPeriod {
 		return false
 	}
 
-	return time.Since(lastTime) >= interval
+	return m.clock.Since(lastTime) >= interval
 }
 
 // returns the update interval based on position size category
@@ -237,7 +245,7 @@ func (m *Monitor) periodicInterval(size float64) time.Duration {
 func (m *Monitor) recordNotification(posID string, eventType EventType) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.lastNotified[posID] = time.Now()
+	m.lastNotified[posID] = m.clock.Now()
 	m.lastEventType[posID] = eventType
 }
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 )
 
@@ -658,14 +659,14 @@ func TestCheckPositions_NoOpenPositions(t *testing.T) {
 // --- record notification ---
 
 func TestRecordNotification(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	mon := &Monitor{
+		clock:         clk,
 		lastNotified:  make(map[string]time.Time),
 		lastEventType: make(map[string]EventType),
 	}
 
-	before := time.Now()
 	mon.recordNotification("pos_1", EventTPHit)
-	after := time.Now()
 
 	mon.mu.Lock()
 	defer mon.mu.Unlock()
@@ -674,8 +675,8 @@ func TestRecordNotification(t *testing.T) {
 	if !ok {
 		t.Fatal("pos_1 should be recorded in lastNotified")
 	}
-	if ts.Before(before) || ts.After(after) {
-		t.Errorf("timestamp out of range: %v not between %v and %v", ts, before, after)
+	if !ts.Equal(clk.Now()) {
+		t.Errorf("timestamp = %v, want %v", ts, clk.Now())
 	}
 
 	if mon.lastEventType["pos_1"] != EventTPHit {
@@ -686,8 +687,10 @@ func TestRecordNotification(t *testing.T) {
 // --- shouldSendPeriodic ---
 
 func TestShouldSendPeriodic_FirstTime(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	mon := &Monitor{
 		config:       DefaultMonitorConfig(),
+		clock:        clk,
 		lastNotified: make(map[string]time.Time),
 	}
 
@@ -699,13 +702,15 @@ func TestShouldSendPeriodic_FirstTime(t *testing.T) {
 }
 
 func TestShouldSendPeriodic_WithinCooldown(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	mon := &Monitor{
 		config:       DefaultMonitorConfig(),
+		clock:        clk,
 		lastNotified: make(map[string]time.Time),
 	}
 
 	// notified just now — within 15m cooldown
-	mon.lastNotified["pos_1"] = time.Now()
+	mon.lastNotified["pos_1"] = clk.Now()
 	pos := &LivePosition{ID: "pos_1", PositionSize: 250.0}
 
 	if mon.shouldSendPeriodic(pos) {
@@ -714,13 +719,15 @@ func TestShouldSendPeriodic_WithinCooldown(t *testing.T) {
 }
 
 func TestShouldSendPeriodic_AfterCooldownBeforeInterval(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	mon := &Monitor{
 		config:       DefaultMonitorConfig(),
+		clock:        clk,
 		lastNotified: make(map[string]time.Time),
 	}
 
 	// notified 20 minutes ago — past cooldown (15m) but before medium interval (30m)
-	mon.lastNotified["pos_1"] = time.Now().Add(-20 * time.Minute)
+	mon.lastNotified["pos_1"] = clk.Now().Add(-20 * time.Minute)
 	pos := &LivePosition{ID: "pos_1", PositionSize: 250.0}
 
 	if mon.shouldSendPeriodic(pos) {
@@ -729,13 +736,15 @@ func TestShouldSendPeriodic_AfterCooldownBeforeInterval(t *testing.T) {
 }
 
 func TestShouldSendPeriodic_AfterInterval(t *testing.T) {
+	clk := clock.NewSimulated(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	mon := &Monitor{
 		config:       DefaultMonitorConfig(),
+		clock:        clk,
 		lastNotified: make(map[string]time.Time),
 	}
 
 	// notified 35 minutes ago — past both cooldown (15m) and medium interval (30m)
-	mon.lastNotified["pos_1"] = time.Now().Add(-35 * time.Minute)
+	mon.lastNotified["pos_1"] = clk.Now().Add(-35 * time.Minute)
 	pos := &LivePosition{ID: "pos_1", PositionSize: 250.0}
 
 	if !mon.shouldSendPeriodic(pos) {
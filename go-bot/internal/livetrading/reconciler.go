@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 )
 
@@ -51,6 +52,7 @@ type Reconciler struct {
 	keys     KeyDecryptor
 	config   ReconcilerConfig
 	onMismatch OnMismatchFunc
+	clock    clock.Clock
 
 	mu           sync.Mutex
 	mismatches   []Mismatch
@@ -68,6 +70,7 @@ func NewReconciler(executor *Executor, orders exchange.OrderExecutor, keys KeyDe
 		orders:        orders,
 		keys:          keys,
 		config:        config,
+		clock:         clock.Real(),
 		mismatches:    make([]Mismatch, 0),
 		pendingOrders: make(map[string]time.Time),
 	}
@@ -78,12 +81,17 @@ func (r *Reconciler) SetOnMismatch(fn OnMismatchFunc) {
 	r.onMismatch = fn
 }
 
+// SetClock replaces the time source for ticks and order ages. Call before Start.
+func (r *Reconciler) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
 // TrackOrder records that an order was just placed for a position.
 // The reconciler will wait OrderCheckDelay before verifying the fill.
 func (r *Reconciler) TrackOrder(positionID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.pendingOrders[positionID] = time.Now()
+	r.pendingOrders[positionID] = r.clock.Now()
 }
 
 // Mismatches returns all detected mismatches (most recent first).
@@ -130,14 +138,14 @@ func (r *Reconciler) run(ctx context.Context) {
 		close(r.done)
 	}()
 
-	ticker := time.NewTicker(r.config.Interval)
+	ticker := r.clock.NewTicker(r.config.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			r.Reconcile()
 		}
 	}
@@ -156,7 +164,7 @@ func (r *Reconciler) Reconcile() {
 		r.mu.Lock()
 		placedAt, isPending := r.pendingOrders[pos.ID]
 		r.mu.Unlock()
-		if isPending && time.Since(placedAt) < r.config.OrderCheckDelay {
+		if isPending && r.clock.Since(placedAt) < r.config.OrderCheckDelay {
 			continue
 		}
 
@@ -196,7 +204,7 @@ func (r *Reconciler) reconcilePosition(pos *LivePosition) {
 				Expected:   pos.Quantity,
 				Actual:     mainOrder.ExecutedQty,
 				Details:    fmt.Sprintf("main order %d partially filled: %.8f of %.8f", pos.MainOrderID, mainOrder.ExecutedQty, pos.Quantity),
-				DetectedAt: time.Now(),
+				DetectedAt: r.clock.Now(),
 			}
 			r.recordMismatch(m)
 		}
@@ -221,7 +229,7 @@ func (r *Reconciler) reconcilePosition(pos *LivePosition) {
 					Expected:   pos.Quantity,
 					Actual:     mainOrder.ExecutedQty,
 					Details:    fmt.Sprintf("main order %d filled qty %.8f != position qty %.8f", pos.MainOrderID, mainOrder.ExecutedQty, pos.Quantity),
-					DetectedAt: time.Now(),
+					DetectedAt: r.clock.Now(),
 				}
 				r.recordMismatch(m)
 			}
@@ -240,14 +248,14 @@ func (r *Reconciler) reconcilePosition(pos *LivePosition) {
 				UserID:     pos.UserID,
 				Type:       "orphaned_sl_tp",
 				Details:    fmt.Sprintf("SL order %d is %s — position has no stop loss protection", pos.SLOrderID, slOrder.Status),
-				DetectedAt: time.Now(),
+				DetectedAt: r.clock.Now(),
 			}
 			r.recordMismatch(m)
 		}
 	}
 
 	// 3. Check for stale orders (placed long ago, still not filled)
-	if time.Since(pos.OpenedAt) > r.config.StaleOrderAge {
+	if r.clock.Since(pos.OpenedAt) > r.config.StaleOrderAge {
 		if pos.MainOrderID > 0 {
 			mainOrder, err := r.orders.GetOrder(pos.Symbol, pos.MainOrderID, apiKey, apiSecret)
 			if err == nil && mainOrder.Status == exchange.OrderStatusNew {
@@ -256,8 +264,8 @@ func (r *Reconciler) reconcilePosition(pos *LivePosition) {
 					Symbol:     pos.Symbol,
 					UserID:     pos.UserID,
 					Type:       "stale_order",
-					Details:    fmt.Sprintf("main order %d still NEW after %s", pos.MainOrderID, time.Since(pos.OpenedAt).Round(time.Second)),
-					DetectedAt: time.Now(),
+					Details:    fmt.Sprintf("main order %d still NEW after %s", pos.MainOrderID, r.clock.Since(pos.OpenedAt).Round(time.Second)),
+					DetectedAt: r.clock.Now(),
 				}
 				r.recordMismatch(m)
 			}
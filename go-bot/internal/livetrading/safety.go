@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // a single check that must pass before trading is allowed
@@ -67,6 +69,7 @@ type SafetyChecker struct {
 	positions PositionCounter
 	losses    LossTracker
 	confirm   *ConfirmationManager
+	clock     clock.Clock
 }
 
 func NewSafetyChecker(config SafetyConfig, balance BalanceProvider, positions PositionCounter, losses LossTracker, confirm *ConfirmationManager) *SafetyChecker {
@@ -76,9 +79,15 @@ func NewSafetyChecker(config SafetyConfig, balance BalanceProvider, positions Po
 		positions: positions,
 		losses:    losses,
 		confirm:   confirm,
+		clock:     clock.Real(),
 	}
 }
 
+// SetClock replaces the time source that picks the daily loss window.
+func (s *SafetyChecker) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // runs all pre-trade safety checks and returns the aggregate result
 func (s *SafetyChecker) Check(userID int, symbol string, positionSize float64, asset string) SafetyResult {
 	var checks []CheckResult
@@ -173,7 +182,7 @@ func (s *SafetyChecker) Check(userID int, symbol string, positionSize float64, a
 	// check 6: daily loss limit
 	lossCheck := CheckResult{Name: "daily_loss"}
 	if s.losses != nil {
-		dailyLoss := s.losses.DailyLoss(userID, time.Now())
+		dailyLoss := s.losses.DailyLoss(userID, s.clock.Now())
 		remaining := s.config.DailyLossLimit - dailyLoss
 		if remaining <= 0 {
 			lossCheck.Passed = false
@@ -211,14 +220,21 @@ func (s *SafetyChecker) Check(userID int, symbol string, positionSize float64, a
 type InMemoryLossTracker struct {
 	mu     sync.Mutex
 	losses map[int]map[string]float64 // userID -> date -> cumulative loss
+	clock  clock.Clock
 }
 
 func NewLossTracker() *InMemoryLossTracker {
 	return &InMemoryLossTracker{
 		losses: make(map[int]map[string]float64),
+		clock:  clock.Real(),
 	}
 }
 
+// SetClock replaces the time source that dates recorded losses.
+func (t *InMemoryLossTracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
 func (t *InMemoryLossTracker) DailyLoss(userID int, date time.Time) float64 {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -238,7 +254,7 @@ func (t *InMemoryLossTracker) RecordLoss(userID int, amount float64) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	key := time.Now().Format("2006-01-02")
+	key := t.clock.Now().Format("2006-01-02")
 	if _, ok := t.losses[userID]; !ok {
 		t.losses[userID] = make(map[string]float64)
 	}
@@ -250,7 +266,7 @@ func (t *InMemoryLossTracker) ResetDaily() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	today := time.Now().Format("2006-01-02")
+	today := t.clock.Now().Format("2006-01-02")
 	for uid := range t.losses {
 		for date := range t.losses[uid] {
 			if date != today {
@@ -7,7 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -81,6 +84,86 @@ type EnsemblePredictionResponse struct {
 	IsEnsemble     bool                   `json:"is_ensemble"`
 }
 
+// ModelVote is one model's output from an ensemble prediction.
+type ModelVote struct {
+	Model      string  `json:"model"`
+	Direction  string  `json:"direction"` // up or down
+	Magnitude  float64 `json:"magnitude"` // predicted move in percent
+	Confidence float64 `json:"confidence"`
+	Weight     float64 `json:"weight"` // weight in the vote, from rolling accuracy
+}
+
+// Votes decodes model_details into per-model outputs, sorted by model name.
+func (r *EnsemblePredictionResponse) Votes() []ModelVote {
+	votes := make([]ModelVote, 0, len(r.ModelDetails))
+	for name, raw := range r.ModelDetails {
+		details, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		v := ModelVote{Model: name}
+		v.Direction, _ = details["direction"].(string)
+		v.Magnitude, _ = details["magnitude"].(float64)
+		v.Confidence, _ = details["confidence"].(float64)
+		v.Weight, _ = details["weight"].(float64)
+		votes = append(votes, v)
+	}
+	sort.Slice(votes, func(i, j int) bool { return votes[i].Model < votes[j].Model })
+	return votes
+}
+
+// Agreement is the share of models voting in the ensemble's direction (0-1).
+func (r *EnsemblePredictionResponse) Agreement() float64 {
+	votes := r.Votes()
+	if len(votes) == 0 {
+		return 0
+	}
+	agree := 0
+	for _, v := range votes {
+		if v.Direction == r.Direction {
+			agree++
+		}
+	}
+	return float64(agree) / float64(len(votes))
+}
+
+// Dispersion is the standard deviation of the models' signed predicted
+// moves, in percentage points. High dispersion means the models disagree on
+// size as well as direction.
+func (r *EnsemblePredictionResponse) Dispersion() float64 {
+	votes := r.Votes()
+	if len(votes) < 2 {
+		return 0
+	}
+	moves := make([]float64, len(votes))
+	var mean float64
+	for i, v := range votes {
+		moves[i] = v.Magnitude
+		if v.Direction == "down" {
+			moves[i] = -v.Magnitude
+		}
+		mean += moves[i]
+	}
+	mean /= float64(len(moves))
+	var variance float64
+	for _, m := range moves {
+		variance += (m - mean) * (m - mean)
+	}
+	return math.Sqrt(variance / float64(len(moves)))
+}
+
+// AsPrediction converts the ensemble output to the single-model response shape.
+func (r *EnsemblePredictionResponse) AsPrediction() *PricePredictionResponse {
+	return &PricePredictionResponse{
+		Direction:      r.Direction,
+		Magnitude:      r.Magnitude,
+		Confidence:     r.Confidence,
+		Timeframe:      r.Timeframe,
+		PredictedPrice: r.PredictedPrice,
+		CurrentPrice:   r.CurrentPrice,
+	}
+}
+
 // --- pattern detection ---
 
 type PatternDetectRequest struct {
@@ -113,8 +196,10 @@ type DriftCheckResponse struct {
 // --- retraining ---
 
 type RetrainRequest struct {
-	Candles []Candle `json:"candles"`
-	Epochs  int      `json:"epochs"`
+	Candles   []Candle   `json:"candles"`
+	Series    [][]Candle `json:"series,omitempty"` // more series (other symbols or timeframes) pooled into training
+	Epochs    int        `json:"epochs"`
+	StageOnly bool       `json:"stage_only,omitempty"` // keep the new model as a candidate for PromoteModel
 }
 
 type RetrainResponse struct {
@@ -122,12 +207,35 @@ type RetrainResponse struct {
 	Message             string                 `json:"message,omitempty"`
 	Reason              string                 `json:"reason,omitempty"`
 	Promoted            bool                   `json:"promoted,omitempty"`
+	Staged              bool                   `json:"staged,omitempty"`
 	NewModelMetrics     map[string]interface{} `json:"new_model_metrics,omitempty"`
 	CurrentModelMetrics map[string]interface{} `json:"current_model_metrics,omitempty"`
 	TrainingSamples     int                    `json:"training_samples,omitempty"`
 	ValidationSamples   int                    `json:"validation_samples,omitempty"`
 }
 
+// NewAccuracy returns the retrained model's direction accuracy (0-1) on the validation split.
+func (r *RetrainResponse) NewAccuracy() float64 {
+	return metricFloat(r.NewModelMetrics, "direction_accuracy")
+}
+
+// CurrentAccuracy returns the deployed model's direction accuracy (0-1) on
+// the same split; 0 when no model is deployed.
+func (r *RetrainResponse) CurrentAccuracy() float64 {
+	return metricFloat(r.CurrentModelMetrics, "direction_accuracy")
+}
+
+func metricFloat(m map[string]interface{}, key string) float64 {
+	v, _ := m[key].(float64)
+	return v
+}
+
+type PromoteResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
 // --- walk-forward validation ---
 
 type WalkForwardRequest struct {
@@ -144,6 +252,29 @@ type WalkForwardResponse struct {
 	AvgMagnitudeRMSE     float64                  `json:"avg_magnitude_rmse,omitempty"`
 }
 
+// CandidateValidationRequest scores the staged candidate on the last Holdout
+// candles, which it must not have been trained on.
+type CandidateValidationRequest struct {
+	Candles []Candle   `json:"candles"`
+	Series  [][]Candle `json:"series,omitempty"` // the other series the candidate was trained on
+	Holdout int        `json:"holdout"`
+	NSplits int        `json:"n_splits"`
+}
+
+// CandidateValidationResponse compares the staged candidate with the deployed
+// model over the held-out folds. Accuracies are 0-1.
+type CandidateValidationResponse struct {
+	Success              bool                     `json:"success"`
+	Reason               string                   `json:"reason,omitempty"`
+	NSplits              int                      `json:"n_splits,omitempty"`
+	Folds                []map[string]interface{} `json:"folds,omitempty"`
+	AvgDirectionAccuracy float64                  `json:"avg_direction_accuracy,omitempty"`
+	AvgDeployedAccuracy  float64                  `json:"avg_deployed_direction_accuracy,omitempty"`
+	AvgImprovement       float64                  `json:"avg_improvement,omitempty"`
+	FoldsImproved        int                      `json:"folds_improved,omitempty"`
+	AvgMagnitudeRMSE     float64                  `json:"avg_magnitude_rmse,omitempty"`
+}
+
 // --- RL agent ---
 
 type RLTrainRequest struct {
@@ -176,6 +307,36 @@ type RLActionResponse struct {
 	Exploring bool      `json:"exploring"`
 }
 
+// Signal maps the agent's action (hold, buy_small, buy_large, sell_small,
+// sell_all) to BUY, SELL or HOLD.
+func (r *RLActionResponse) Signal() string {
+	switch {
+	case strings.HasPrefix(r.Action, "buy"):
+		return "BUY"
+	case strings.HasPrefix(r.Action, "sell"):
+		return "SELL"
+	default:
+		return "HOLD"
+	}
+}
+
+// Confidence is the softmax probability of the chosen action over the
+// Q-values (0-1). Zero when the agent is untrained or exploring.
+func (r *RLActionResponse) Confidence() float64 {
+	if r.Exploring || len(r.QValues) == 0 || r.ActionIdx < 0 || r.ActionIdx >= len(r.QValues) {
+		return 0
+	}
+	maxQ := r.QValues[0]
+	for _, q := range r.QValues[1:] {
+		maxQ = math.Max(maxQ, q)
+	}
+	var sum float64
+	for _, q := range r.QValues {
+		sum += math.Exp(q - maxQ)
+	}
+	return math.Exp(r.QValues[r.ActionIdx]-maxQ) / sum
+}
+
 // creates a new ml service client
 func NewClient(baseURL string) *Client {
 	return &Client{
@@ -186,6 +347,12 @@ func NewClient(baseURL string) *Client {
 	}
 }
 
+// SetTimeout replaces the per-request timeout (10s by default). Training
+// calls need far longer than predictions.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
 // checks if the ml service is running
 func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
 	resp, err := c.get(ctx, "/health")
@@ -363,6 +530,24 @@ func (c *Client) Retrain(ctx context.Context, req *RetrainRequest) (*RetrainResp
 	return &result, nil
 }
 
+// PromoteModel promotes the candidate staged by a StageOnly retrain.
+func (c *Client) PromoteModel(ctx context.Context) (*PromoteResponse, error) {
+	resp, err := c.post(ctx, "/promote", []byte("{}"))
+	if err != nil {
+		return nil, fmt.Errorf("promote failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("promote returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	var result PromoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode promote response: %w", err)
+	}
+	return &result, nil
+}
+
 // WalkForwardValidate runs walk-forward cross-validation.
 func (c *Client) WalkForwardValidate(ctx context.Context, req *WalkForwardRequest) (*WalkForwardResponse, error) {
 	body, err := json.Marshal(req)
@@ -385,6 +570,29 @@ func (c *Client) WalkForwardValidate(ctx context.Context, req *WalkForwardReques
 	return &result, nil
 }
 
+// ValidateCandidate scores the staged candidate and the deployed model on
+// held-out folds without training anything.
+func (c *Client) ValidateCandidate(ctx context.Context, req *CandidateValidationRequest) (*CandidateValidationResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := c.post(ctx, "/validate-candidate", body)
+	if err != nil {
+		return nil, fmt.Errorf("candidate validation failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("candidate validation returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	var result CandidateValidationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode candidate validation response: %w", err)
+	}
+	return &result, nil
+}
+
 // TrainRL trains the RL agent from historical candle data.
 func (c *Client) TrainRL(ctx context.Context, req *RLTrainRequest) (*RLTrainResponse, error) {
 	body, err := json.Marshal(req)
@@ -221,3 +221,71 @@ func TestSentimentConnectionRefused(t *testing.T) {
 		t.Error("expected connection error")
 	}
 }
+
+func TestEnsembleVotes(t *testing.T) {
+	var resp EnsemblePredictionResponse
+	body := `{"direction":"up","magnitude":1.5,"confidence":0.7,"model_count":3,"is_ensemble":true,
+		"model_details":{
+			"random_forest":{"direction":"down","magnitude":1.0,"confidence":0.55,"weight":0.2},
+			"lstm":{"direction":"up","magnitude":2.0,"confidence":0.8,"weight":0.5},
+			"gradient_boosting":{"direction":"up","magnitude":3.0,"confidence":0.6,"weight":0.3}}}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	votes := resp.Votes()
+	if len(votes) != 3 || votes[0].Model != "gradient_boosting" || votes[1].Model != "lstm" {
+		t.Fatalf("expected votes sorted by model, got %+v", votes)
+	}
+	if votes[1].Direction != "up" || votes[1].Magnitude != 2.0 || votes[1].Weight != 0.5 {
+		t.Errorf("unexpected lstm vote %+v", votes[1])
+	}
+	if got := resp.Agreement(); got < 0.666 || got > 0.667 {
+		t.Errorf("agreement = %.3f, want 2/3", got)
+	}
+	// signed moves 3, 2, -1: mean 4/3, population std dev ~1.700
+	if got := resp.Dispersion(); got < 1.69 || got > 1.71 {
+		t.Errorf("dispersion = %.3f, want ~1.70", got)
+	}
+	if p := resp.AsPrediction(); p.Direction != "up" || p.Confidence != 0.7 {
+		t.Errorf("unexpected conversion %+v", p)
+	}
+
+	empty := &EnsemblePredictionResponse{}
+	if empty.Agreement() != 0 || empty.Dispersion() != 0 || len(empty.Votes()) != 0 {
+		t.Error("expected zero values without model details")
+	}
+}
+
+func TestRLActionSignalAndConfidence(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{"hold", "HOLD"},
+		{"buy_small", "BUY"},
+		{"buy_large", "BUY"},
+		{"sell_small", "SELL"},
+		{"sell_all", "SELL"},
+	}
+	for _, tt := range tests {
+		if got := (&RLActionResponse{Action: tt.action}).Signal(); got != tt.want {
+			t.Errorf("Signal(%s) = %s, want %s", tt.action, got, tt.want)
+		}
+	}
+
+	// equal Q-values split the probability evenly
+	even := &RLActionResponse{Action: "hold", ActionIdx: 0, QValues: []float64{1, 1, 1, 1}}
+	if got := even.Confidence(); got < 0.2499 || got > 0.2501 {
+		t.Errorf("confidence = %.4f, want 0.25", got)
+	}
+	// a dominant action approaches 1
+	sure := &RLActionResponse{Action: "buy_large", ActionIdx: 2, QValues: []float64{0, 0, 10, 0, 0}}
+	if got := sure.Confidence(); got < 0.99 {
+		t.Errorf("confidence = %.4f, want > 0.99", got)
+	}
+	untrained := &RLActionResponse{Action: "hold", Exploring: true}
+	if untrained.Confidence() != 0 {
+		t.Error("untrained agent should have zero confidence")
+	}
+}
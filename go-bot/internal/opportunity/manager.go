@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/pipeline"
 )
 
@@ -82,8 +83,7 @@ type Manager struct {
 	// callbacks
 	onExpire StateChangeCallback
 
-	// for testing
-	nowFunc func() time.Time
+	clock clock.Clock
 }
 
 // creates a new opportunity manager
@@ -92,10 +92,17 @@ func NewManager(cfg Config) *Manager {
 		opportunities: make(map[string]*Opportunity),
 		config:        cfg,
 		stopCh:        make(chan struct{}),
-		nowFunc:       time.Now,
+		clock:         clock.Real(),
 	}
 }
 
+// SetClock replaces the time source used for creation times and expiry
+func (m *Manager) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+}
+
 // SetStore enables db persistence for opportunities
 func (m *Manager) SetStore(store *Store) {
 	m.mu.Lock()
@@ -279,14 +286,16 @@ func (m *Manager) StopExpiry() {
 
 // background loop that checks for expired opportunities
 func (m *Manager) expiryLoop() {
-	ticker := time.NewTicker(m.config.CleanupInterval)
+	m.mu.RLock()
+	ticker := m.clock.NewTicker(m.config.CleanupInterval)
+	m.mu.RUnlock()
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-m.stopCh:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			m.expireOld()
 		}
 	}
@@ -361,7 +370,7 @@ func (m *Manager) Count() int {
 }
 
 func (m *Manager) now() time.Time {
-	return m.nowFunc()
+	return m.clock.Now()
 }
 
 // syncToDB persists opportunity state to the database (best-effort, logs errors)
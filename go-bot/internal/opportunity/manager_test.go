@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/pipeline"
 	"github.com/trading-bot/go-bot/internal/user"
@@ -309,13 +310,13 @@ func TestExpireOld(t *testing.T) {
 	m := testManager()
 	result := testResult("BTC/USDT", claude.ActionBuy, 85)
 
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	id := m.Create(1, "BTC/USDT", result, "telegram")
 
 	// advance past expiry (15 min default)
-	now = now.Add(16 * time.Minute)
+	clk.Advance(16 * time.Minute)
 	m.expireOld()
 
 	opp := m.Get(id)
@@ -327,17 +328,51 @@ func TestExpireOld(t *testing.T) {
 	}
 }
 
+func TestExpiryLoop_SimulatedClock(t *testing.T) {
+	m := testManager()
+	clk := clock.NewSimulated(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	m.SetClock(clk)
+
+	expired := make(chan *Opportunity, 1)
+	m.OnExpire(func(opp *Opportunity) { expired <- opp })
+
+	id := m.Create(1, "BTC/USDT", testResult("BTC/USDT", claude.ActionBuy, 85), "telegram")
+	m.StartExpiry()
+	defer m.StopExpiry()
+	clk.BlockUntil(1)
+
+	// 14 minutes of cleanup ticks: still inside the 15 minute window
+	for i := 0; i < 14; i++ {
+		clk.Advance(time.Minute)
+	}
+	select {
+	case <-expired:
+		t.Fatal("expired before the expiry window")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(2 * time.Minute)
+	select {
+	case opp := <-expired:
+		if opp.ID != id || opp.ResolvedAt == nil || !opp.ResolvedAt.Equal(clk.Now()) {
+			t.Errorf("expired %+v at %v", opp, clk.Now())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expiry loop did not expire the opportunity")
+	}
+}
+
 func TestExpireOldDoesNotExpireFresh(t *testing.T) {
 	m := testManager()
 	result := testResult("BTC/USDT", claude.ActionBuy, 85)
 
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	id := m.Create(1, "BTC/USDT", result, "telegram")
 
 	// only 5 min passed — should not expire
-	now = now.Add(5 * time.Minute)
+	clk.Advance(5 * time.Minute)
 	m.expireOld()
 
 	opp := m.Get(id)
@@ -350,14 +385,14 @@ func TestExpireOldDoesNotExpireResolved(t *testing.T) {
 	m := testManager()
 	result := testResult("BTC/USDT", claude.ActionBuy, 85)
 
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	id := m.Create(1, "BTC/USDT", result, "telegram")
 	m.Approve(id, 1)
 
 	// advance past expiry
-	now = now.Add(20 * time.Minute)
+	clk.Advance(20 * time.Minute)
 	m.expireOld()
 
 	opp := m.Get(id)
@@ -370,8 +405,8 @@ func TestExpireCallback(t *testing.T) {
 	m := testManager()
 	result := testResult("BTC/USDT", claude.ActionBuy, 85)
 
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	m.Create(1, "BTC/USDT", result, "telegram")
 
@@ -380,7 +415,7 @@ func TestExpireCallback(t *testing.T) {
 		expiredOpp = opp
 	})
 
-	now = now.Add(16 * time.Minute)
+	clk.Advance(16 * time.Minute)
 	m.expireOld()
 
 	if expiredOpp == nil {
@@ -393,19 +428,19 @@ func TestExpireCallback(t *testing.T) {
 
 func TestExpireMultiple(t *testing.T) {
 	m := testManager()
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	r := testResult("BTC/USDT", claude.ActionBuy, 85)
 	m.Create(1, "BTC/USDT", r, "telegram") // will expire
 	m.Create(1, "ETH/USDT", r, "telegram") // will expire
 
 	// create one that's "newer"
-	now = now.Add(10 * time.Minute)
+	clk.Advance(10 * time.Minute)
 	m.Create(1, "SOL/USDT", r, "telegram") // 10 min newer
 
 	// advance to 16 min from start — first two expire, third doesn't
-	now = now.Add(6 * time.Minute) // total: 16 min from start
+	clk.Advance(6 * time.Minute) // total: 16 min from start
 
 	count := 0
 	m.OnExpire(func(opp *Opportunity) { count++ })
@@ -425,8 +460,8 @@ func TestExpireMultiple(t *testing.T) {
 
 func TestCleanup(t *testing.T) {
 	m := testManager()
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	r := testResult("BTC/USDT", claude.ActionBuy, 85)
 	id1 := m.Create(1, "BTC/USDT", r, "telegram")
@@ -437,7 +472,7 @@ func TestCleanup(t *testing.T) {
 	m.Reject(id2, 1)
 
 	// advance 2 hours
-	now = now.Add(2 * time.Hour)
+	clk.Advance(2 * time.Hour)
 	removed := m.Cleanup(1 * time.Hour)
 
 	if removed != 2 {
@@ -818,12 +853,12 @@ func TestExpireThenApprove(t *testing.T) {
 	m := testManager()
 	result := testResult("BTC/USDT", claude.ActionBuy, 85)
 
-	now := time.Now()
-	m.nowFunc = func() time.Time { return now }
+	clk := clock.NewSimulated(time.Now())
+	m.SetClock(clk)
 
 	id := m.Create(1, "BTC/USDT", result, "telegram")
 
-	now = now.Add(16 * time.Minute)
+	clk.Advance(16 * time.Minute)
 	m.expireOld()
 
 	ok := m.Approve(id, 1)
@@ -7,16 +7,23 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // Store handles database operations for opportunities
 type Store struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	clock clock.Clock
 }
 
 // NewStore creates a new opportunity store
 func NewStore(pool *pgxpool.Pool) *Store {
-	return &Store{pool: pool}
+	return &Store{pool: pool, clock: clock.Real()}
+}
+
+// SetClock replaces the time source for expiry and cleanup cutoffs.
+func (s *Store) SetClock(c clock.Clock) {
+	s.clock = c
 }
 
 // OpportunityRow is the db representation of an opportunity
@@ -164,7 +171,7 @@ func (s *Store) GetByID(ctx context.Context, id string) (*OpportunityRow, error)
 
 // DeleteOlderThan removes resolved opportunities older than the given duration
 func (s *Store) DeleteOlderThan(ctx context.Context, maxAge time.Duration) (int64, error) {
-	cutoff := time.Now().Add(-maxAge)
+	cutoff := s.clock.Now().Add(-maxAge)
 	query := `DELETE FROM opportunities WHERE status != 'pending' AND resolved_at < $1`
 	tag, err := s.pool.Exec(ctx, query, cutoff)
 	if err != nil {
@@ -175,8 +182,8 @@ func (s *Store) DeleteOlderThan(ctx context.Context, maxAge time.Duration) (int6
 
 // ExpirePending marks all pending opportunities older than the given duration as expired
 func (s *Store) ExpirePending(ctx context.Context, maxAge time.Duration) (int64, error) {
-	cutoff := time.Now().Add(-maxAge)
-	now := time.Now()
+	now := s.clock.Now()
+	cutoff := now.Add(-maxAge)
 	query := `UPDATE opportunities SET status = 'expired', resolved_at = $1 WHERE status = 'pending' AND created_at < $2`
 	tag, err := s.pool.Exec(ctx, query, now, cutoff)
 	if err != nil {
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
+	"github.com/trading-bot/go-bot/internal/clock"
 	"github.com/trading-bot/go-bot/internal/opportunity"
 )
 
@@ -46,6 +48,8 @@ type Executor struct {
 	store     PositionStore // nil if no persistence configured
 	trades    TradeLogger   // nil if no logging configured
 	breaker   *circuitbreaker.Breaker // nil if no circuit breaker configured
+	calendar  *calendar.Calendar      // nil if no event calendar configured
+	clock     clock.Clock
 	nextID    int
 }
 
@@ -53,9 +57,15 @@ func NewExecutor(prices PriceProvider) *Executor {
 	return &Executor{
 		positions: make(map[string]*Position),
 		prices:    prices,
+		clock:     clock.Real(),
 	}
 }
 
+// SetClock replaces the time source for open/close timestamps. Call before Start.
+func (e *Executor) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
 // SetStore configures position persistence. Call before Start.
 func (e *Executor) SetStore(store PositionStore) {
 	e.store = store
@@ -71,6 +81,11 @@ func (e *Executor) SetCircuitBreaker(b *circuitbreaker.Breaker) {
 	e.breaker = b
 }
 
+// SetCalendar blocks new entries during event blackout windows. Call before Start.
+func (e *Executor) SetCalendar(c *calendar.Calendar) {
+	e.calendar = c
+}
+
 // SetNextID sets the starting ID for new positions (used for recovery).
 func (e *Executor) SetNextID(id int) {
 	e.mu.Lock()
@@ -108,6 +123,13 @@ func (e *Executor) Execute(opp *opportunity.Opportunity) (*Position, error) {
 		}
 	}
 
+	// no new entries around high-impact events
+	if e.calendar != nil {
+		if ok, reason := e.calendar.AllowEntry(opp.Symbol); !ok {
+			return nil, fmt.Errorf("event blackout: %s", reason)
+		}
+	}
+
 	price, err := e.prices.GetPrice(opp.Symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get price for %s: %w", opp.Symbol, err)
@@ -138,7 +160,7 @@ func (e *Executor) Execute(opp *opportunity.Opportunity) (*Position, error) {
 		TakeProfit:    plan.TakeProfit,
 		PositionSize:  plan.PositionSize,
 		Status:        PositionOpen,
-		OpenedAt:      time.Now(),
+		OpenedAt:      e.clock.Now(),
 		HitMilestones: make(map[float64]bool),
 		Platform:      opp.Platform,
 	}
@@ -178,7 +200,7 @@ func (e *Executor) Close(posID string, reason CloseReason, price float64) (*Posi
 		return nil, fmt.Errorf("position already closed: %s", posID)
 	}
 
-	now := time.Now()
+	now := e.clock.Now()
 	pos.Status = PositionClosed
 	pos.CloseReason = reason
 	pos.ClosePrice = price
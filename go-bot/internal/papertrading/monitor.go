@@ -7,6 +7,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
 )
 
 // notification event types
@@ -57,6 +59,7 @@ type Monitor struct {
 	prices   PriceProvider
 	config   MonitorConfig
 	OnEvent  func(Event)
+	clock    clock.Clock
 
 	mu            sync.Mutex
 	lastNotified  map[string]time.Time  // posID -> last notification time
@@ -73,12 +76,18 @@ func NewMonitor(executor *Executor, prices PriceProvider, config MonitorConfig)
 		executor:      executor,
 		prices:        prices,
 		config:        config,
+		clock:         clock.Real(),
 		lastNotified:  make(map[string]time.Time),
 		lastEventType: make(map[string]EventType),
 		lastEventKey:  make(map[string]string),
 	}
 }
 
+// SetClock replaces the time source for ticks and cooldowns. Call before Start.
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
 // starts the background monitoring goroutine
 func (m *Monitor) Start(ctx context.Context) {
 	m.mu.Lock()
@@ -120,14 +129,14 @@ func (m *Monitor) run(ctx context.Context) {
 		close(m.done)
 	}()
 
-	ticker := time.NewTicker(m.config.CheckInterval)
+	ticker := m.clock.NewTicker(m.config.CheckInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			m.CheckPositions()
 		}
 	}
@@ -202,11 +211,11 @@ func (m *Monitor) shouldSendPeriodic(pos *Position) bool {
 		return true
 	}
 
-	if time.Since(lastTime) < m.config.CooldownPeriod {
+	if m.clock.Since(lastTime) < m.config.CooldownPeriod {
 		return false
 	}
 
-	return time.Since(lastTime) >= interval
+	return m.clock.Since(lastTime) >= interval
 }
 
 // returns the update interval based on position size category
@@ -230,13 +239,13 @@ func (m *Monitor) CanNotify(posID string) bool {
 		return true
 	}
 
-	return time.Since(lastTime) >= m.config.CooldownPeriod
+	return m.clock.Since(lastTime) >= m.config.CooldownPeriod
 }
 
 func (m *Monitor) recordNotification(posID string, eventType EventType) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.lastNotified[posID] = time.Now()
+	m.lastNotified[posID] = m.clock.Now()
 	m.lastEventType[posID] = eventType
 }
 
@@ -251,7 +260,7 @@ func (m *Monitor) isDuplicate(posID string, eventType EventType) bool {
 	}
 
 	lastTime := m.lastNotified[posID]
-	return lastType == eventType && time.Since(lastTime) < m.config.CooldownPeriod
+	return lastType == eventType && m.clock.Since(lastTime) < m.config.CooldownPeriod
 }
 
 func (m *Monitor) emit(event Event) {
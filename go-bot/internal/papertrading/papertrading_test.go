@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/trading-bot/go-bot/internal/calendar"
 	"github.com/trading-bot/go-bot/internal/circuitbreaker"
 	"github.com/trading-bot/go-bot/internal/claude"
 	"github.com/trading-bot/go-bot/internal/opportunity"
@@ -1862,6 +1863,32 @@ func TestExecutor_CircuitBreakerAllows(t *testing.T) {
 	}
 }
 
+// --- event calendar integration ---
+
+type eventSource []calendar.Event
+
+func (s eventSource) Name() string                                   { return "test" }
+func (s eventSource) Load(context.Context) ([]calendar.Event, error) { return s, nil }
+
+func TestExecutor_EventBlackoutBlocks(t *testing.T) {
+	prices := newMockPrices()
+	prices.set("BTCUSDT", 42000)
+	exec := NewExecutor(prices)
+
+	cal := calendar.New(calendar.DefaultConfig())
+	cal.AddSource(eventSource{{Title: "FOMC", Time: time.Now().Add(20 * time.Minute), Importance: calendar.High}})
+	if err := cal.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exec.SetCalendar(cal)
+
+	opp := testOpp("BTCUSDT", claude.ActionBuy, 42000, 41500, 43000, 500)
+	_, err := exec.Execute(opp)
+	if err == nil || !strings.Contains(err.Error(), "event blackout: FOMC") {
+		t.Fatalf("expected the blackout to block the entry, got %v", err)
+	}
+}
+
 func TestExecutor_NilCircuitBreakerAllows(t *testing.T) {
 	prices := newMockPrices()
 	prices.set("BTCUSDT", 42000)
@@ -0,0 +1,219 @@
+// ai fallback chain — tries llm providers in order so an outage or rate limit
+// at one of them doesn't stop the scanner. a provider that keeps failing is
+// put in cooldown and moved to the back of the chain until it expires.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultProviderCooldown = 5 * time.Minute
+)
+
+// AIPricer prices an ai call from the model and its token usage.
+type AIPricer interface {
+	Cost(model string, u claude.Usage) float64
+}
+
+// an ai provider with a name for health reports and decision logs
+type NamedAIProvider struct {
+	Name string
+	AI   AIProvider
+}
+
+// ProviderHealth is a snapshot of one provider in the chain.
+type ProviderHealth struct {
+	Name                string
+	Healthy             bool // false while in cooldown
+	ConsecutiveFailures int
+	Successes           int64
+	Failures            int64
+	LastError           string
+	LastSuccess         time.Time
+	CooldownUntil       time.Time
+}
+
+type providerState struct {
+	NamedAIProvider
+	health ProviderHealth
+}
+
+// FallbackAI implements AIProvider over an ordered list of providers.
+type FallbackAI struct {
+	mu               sync.Mutex
+	providers        []*providerState
+	failureThreshold int           // consecutive failures before cooldown
+	cooldown         time.Duration // how long a failing provider is skipped
+	pricing          AIPricer      // nil = calls are not priced
+	clock            clock.Clock
+}
+
+// NewFallbackAI creates a chain that tries providers in the given order.
+func NewFallbackAI(providers ...NamedAIProvider) *FallbackAI {
+	f := &FallbackAI{
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultProviderCooldown,
+		clock:            clock.Real(),
+	}
+	for _, p := range providers {
+		f.providers = append(f.providers, &providerState{
+			NamedAIProvider: p,
+			health:          ProviderHealth{Name: p.Name, Healthy: true},
+		})
+	}
+	return f
+}
+
+// SetHealthPolicy sets how many consecutive failures put a provider in
+// cooldown, and for how long.
+func (f *FallbackAI) SetHealthPolicy(failureThreshold int, cooldown time.Duration) {
+	if failureThreshold > 0 {
+		f.failureThreshold = failureThreshold
+	}
+	if cooldown >= 0 {
+		f.cooldown = cooldown
+	}
+}
+
+// SetPricing fills in the cost of every decision's token usage.
+func (f *FallbackAI) SetPricing(pricing AIPricer) {
+	f.pricing = pricing
+}
+
+// SetClock replaces the time source cooldowns are measured with.
+func (f *FallbackAI) SetClock(clk clock.Clock) {
+	f.clock = clk
+}
+
+// Analyze asks each healthy provider in turn and returns the first decision.
+// Providers in cooldown are only tried once every healthy one has failed,
+// since a stale cooldown is better than no decision. Tokens billed by
+// providers that failed on the way are added to the decision's usage, or
+// returned in a *claude.UsageError when every provider failed.
+func (f *FallbackAI) Analyze(ctx context.Context, input *claude.AnalysisInput) (*claude.Decision, error) {
+	if len(f.providers) == 0 {
+		return nil, fmt.Errorf("no ai providers configured")
+	}
+
+	order := f.order()
+	var errs []error
+	var failed claude.Usage // billed by failed calls
+	for i, p := range order {
+		decision, err := p.AI.Analyze(ctx, input)
+		if err == nil {
+			f.recordSuccess(p)
+			if decision.Provider == "" {
+				decision.Provider = p.Name
+			}
+			decision.Usage.CostUSD = f.cost(decision.Model, decision.Usage)
+			decision.Usage = addUsage(decision.Usage, failed)
+			if i > 0 {
+				slog.Info("ai fallback: decision from backup provider", "provider", p.Name, "symbol", input.Market.Symbol)
+			}
+			return decision, nil
+		}
+		var ue *claude.UsageError
+		if errors.As(err, &ue) {
+			u := ue.Usage
+			u.CostUSD = f.cost(ue.Model, u)
+			failed = addUsage(failed, u)
+		}
+		if ctx.Err() != nil {
+			return nil, withUsage(ctx.Err(), failed)
+		}
+		f.recordFailure(p, err)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		slog.Warn("ai fallback: provider failed", "provider", p.Name, "symbol", input.Market.Symbol, "error", err)
+	}
+	return nil, withUsage(fmt.Errorf("all ai providers failed: %w", errors.Join(errs...)), failed)
+}
+
+// cost prices a call, 0 without pricing
+func (f *FallbackAI) cost(model string, u claude.Usage) float64 {
+	if f.pricing == nil {
+		return 0
+	}
+	return f.pricing.Cost(model, u)
+}
+
+func addUsage(a, b claude.Usage) claude.Usage {
+	return claude.Usage{
+		InputTokens:  a.InputTokens + b.InputTokens,
+		OutputTokens: a.OutputTokens + b.OutputTokens,
+		CostUSD:      a.CostUSD + b.CostUSD,
+	}
+}
+
+// withUsage attaches the usage of failed calls to the error, when there was any
+func withUsage(err error, u claude.Usage) error {
+	if u == (claude.Usage{}) {
+		return err
+	}
+	return &claude.UsageError{Usage: u, Err: err}
+}
+
+// order returns the healthy providers first, in configured order, followed
+// by those in cooldown
+func (f *FallbackAI) order() []*providerState {
+	now := f.clock.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	healthy := make([]*providerState, 0, len(f.providers))
+	var cooling []*providerState
+	for _, p := range f.providers {
+		if now.Before(p.health.CooldownUntil) {
+			cooling = append(cooling, p)
+			continue
+		}
+		p.health.Healthy = true
+		healthy = append(healthy, p)
+	}
+	return append(healthy, cooling...)
+}
+
+func (f *FallbackAI) recordSuccess(p *providerState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.health.Successes++
+	p.health.ConsecutiveFailures = 0
+	p.health.Healthy = true
+	p.health.CooldownUntil = time.Time{}
+	p.health.LastSuccess = f.clock.Now()
+}
+
+func (f *FallbackAI) recordFailure(p *providerState, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.health.Failures++
+	p.health.ConsecutiveFailures++
+	p.health.LastError = err.Error()
+	if p.health.ConsecutiveFailures >= f.failureThreshold {
+		p.health.Healthy = false
+		p.health.CooldownUntil = f.clock.Now().Add(f.cooldown)
+	}
+}
+
+// Health returns a snapshot of every provider, in chain order.
+func (f *FallbackAI) Health() []ProviderHealth {
+	now := f.clock.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]ProviderHealth, len(f.providers))
+	for i, p := range f.providers {
+		out[i] = p.health
+		out[i].Healthy = !now.Before(p.health.CooldownUntil)
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// scriptedAI fails while fail is set and counts calls
+type scriptedAI struct {
+	fail  bool
+	calls int
+}
+
+func (s *scriptedAI) Analyze(context.Context, *claude.AnalysisInput) (*claude.Decision, error) {
+	s.calls++
+	if s.fail {
+		return nil, errors.New("overloaded")
+	}
+	return &claude.Decision{Action: claude.ActionHold, Confidence: 50, Usage: claude.Usage{InputTokens: 900, OutputTokens: 100}}, nil
+}
+
+type flatPricer float64
+
+func (p flatPricer) Cost(_ string, u claude.Usage) float64 {
+	return float64(p) * float64(u.InputTokens+u.OutputTokens)
+}
+
+func TestFallbackAI(t *testing.T) {
+	primary, backup := &scriptedAI{}, &scriptedAI{}
+	sim := clock.NewSimulated(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	f := NewFallbackAI(NamedAIProvider{Name: "anthropic", AI: primary}, NamedAIProvider{Name: "ollama", AI: backup})
+	f.SetHealthPolicy(2, time.Minute)
+	f.SetClock(sim)
+	f.SetPricing(flatPricer(0.00001))
+	ctx := context.Background()
+	input := &claude.AnalysisInput{Market: claude.MarketData{Symbol: "BTC/USDT"}}
+
+	d, err := f.Analyze(ctx, input)
+	if err != nil || d.Provider != "anthropic" || backup.calls != 0 {
+		t.Fatalf("expected the primary to answer, got %+v, %v", d, err)
+	}
+	if d.Usage.CostUSD != 0.01 {
+		t.Errorf("expected the call to be priced at $0.01, got %v", d.Usage.CostUSD)
+	}
+
+	primary.fail = true
+	for i := 0; i < 2; i++ {
+		if d, err := f.Analyze(ctx, input); err != nil || d.Provider != "ollama" {
+			t.Fatalf("expected the backup to answer, got %+v, %v", d, err)
+		}
+	}
+	h := f.Health()
+	if h[0].Healthy || h[0].ConsecutiveFailures != 2 || h[0].LastError != "overloaded" || !h[1].Healthy {
+		t.Fatalf("expected the primary in cooldown, got %+v", h)
+	}
+
+	// in cooldown the primary is skipped while the backup works
+	if _, err := f.Analyze(ctx, input); err != nil || primary.calls != 3 {
+		t.Errorf("primary should be skipped during cooldown, called %d times, %v", primary.calls, err)
+	}
+
+	// after the cooldown it is tried first again
+	primary.fail = false
+	sim.Advance(2 * time.Minute)
+	if d, _ := f.Analyze(ctx, input); d == nil || d.Provider != "anthropic" {
+		t.Errorf("expected the primary back after its cooldown, got %+v", d)
+	}
+	if h := f.Health(); !h[0].Healthy || h[0].ConsecutiveFailures != 0 || h[0].Successes != 2 {
+		t.Errorf("expected the primary healthy again, got %+v", h[0])
+	}
+
+	primary.fail, backup.fail = true, true
+	if _, err := f.Analyze(ctx, input); err == nil || !strings.Contains(err.Error(), "anthropic") || !strings.Contains(err.Error(), "ollama") {
+		t.Errorf("expected both provider errors, got %v", err)
+	}
+}
+
+// billedFailureAI answers with something that can't be parsed, after the
+// provider has billed the tokens
+type billedFailureAI struct{}
+
+func (billedFailureAI) Analyze(context.Context, *claude.AnalysisInput) (*claude.Decision, error) {
+	return nil, &claude.UsageError{Model: "m", Usage: claude.Usage{InputTokens: 500, OutputTokens: 500}, Err: errors.New("failed to parse response")}
+}
+
+func TestFallbackAIChargesFailedCalls(t *testing.T) {
+	backup := &scriptedAI{}
+	f := NewFallbackAI(NamedAIProvider{Name: "anthropic", AI: billedFailureAI{}}, NamedAIProvider{Name: "ollama", AI: backup})
+	f.SetPricing(flatPricer(0.00001))
+	ctx := context.Background()
+	input := &claude.AnalysisInput{Market: claude.MarketData{Symbol: "BTC/USDT"}}
+
+	d, err := f.Analyze(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Usage.InputTokens != 1400 || math.Abs(d.Usage.CostUSD-0.02) > 1e-9 {
+		t.Errorf("expected the failed call's tokens on the decision, got %+v", d.Usage)
+	}
+
+	backup.fail = true
+	_, err = f.Analyze(ctx, input)
+	var ue *claude.UsageError
+	if !errors.As(err, &ue) || math.Abs(ue.Usage.CostUSD-0.01) > 1e-9 {
+		t.Errorf("expected the billed usage with the error, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+// drift monitor — checks the ML model for concept drift on every watched
+// symbol and configured timeframe using candles already stored by data
+// ingestion, records a drift report per scope, and retrains through the
+// promotion gate on the pooled candles of the scopes that drifted.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// CandleReader reads stored candles (implemented via database.CandleRepository).
+type CandleReader interface {
+	// RecentCandles returns up to limit of the latest candles, oldest first.
+	RecentCandles(ctx context.Context, symbol, interval string, limit int) ([]*CandleRecord, error)
+}
+
+// DriftMonitorConfig controls the drift monitor.
+type DriftMonitorConfig struct {
+	Candles         int // candles per drift check and retrain, at least the gate's MinCandles
+	MinCandles      int // scopes with fewer stored candles aren't checked
+	MaxPooledScopes int // drifted scopes pooled into a cycle's retrain; the rest wait
+}
+
+// DefaultDriftMonitorConfig returns sensible defaults.
+func DefaultDriftMonitorConfig() DriftMonitorConfig {
+	return DriftMonitorConfig{
+		Candles:         500,
+		MinCandles:      60, // too few for a meaningful drift check
+		MaxPooledScopes: 10,
+	}
+}
+
+// DriftMonitor runs drift checks across watched symbols and timeframes.
+type DriftMonitor struct {
+	gate       *ModelGate
+	symbols    SymbolProvider
+	candles    CandleReader
+	timeframes []string
+	cfg        DriftMonitorConfig
+	clock      clock.Clock
+}
+
+// NewDriftMonitor creates a monitor over the active watchlist symbols and timeframes.
+func NewDriftMonitor(gate *ModelGate, symbols SymbolProvider, candles CandleReader, timeframes []string, cfg DriftMonitorConfig) *DriftMonitor {
+	def := DefaultDriftMonitorConfig()
+	if cfg.Candles <= 0 {
+		cfg.Candles = def.Candles
+	}
+	if need := gate.MinCandles(); cfg.Candles < need {
+		cfg.Candles = need
+	}
+	if cfg.MinCandles <= 0 {
+		cfg.MinCandles = def.MinCandles
+	}
+	if cfg.MaxPooledScopes <= 0 {
+		cfg.MaxPooledScopes = def.MaxPooledScopes
+	}
+	return &DriftMonitor{
+		gate:       gate,
+		symbols:    symbols,
+		candles:    candles,
+		timeframes: timeframes,
+		cfg:        cfg,
+		clock:      clock.Real(),
+	}
+}
+
+// SetClock replaces the time source used by Run.
+func (m *DriftMonitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// driftScope is one symbol and timeframe with its candles
+type driftScope struct {
+	rec     *ModelRunRecord
+	candles []mlclient.Candle
+}
+
+// RunOnce checks every scope, retrains once on the pooled candles of the
+// drifted scopes, most urgent first up to the per-cycle limit, and records a
+// report for every scope checked. A cancelled cycle records the drifted scopes
+// found so far as skipped.
+func (m *DriftMonitor) RunOnce(ctx context.Context) ([]*ModelRunRecord, error) {
+	symbols, err := m.symbols.ActiveSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(symbols)
+
+	var drifted []*driftScope
+	var reports []*ModelRunRecord
+	for _, symbol := range symbols {
+		for _, tf := range m.timeframes {
+			if ctx.Err() != nil {
+				m.skipDrifted(ctx, drifted, "cycle cancelled")
+				return reports, ctx.Err()
+			}
+			scope, err := m.check(ctx, symbol, tf)
+			if err != nil {
+				slog.Warn("drift monitor: check failed", "symbol", symbol, "timeframe", tf, "error", err)
+				continue
+			}
+			if scope == nil {
+				continue
+			}
+			reports = append(reports, scope.rec)
+			if scope.rec.DriftDetected {
+				drifted = append(drifted, scope)
+			} else {
+				m.gate.record(ctx, scope.rec)
+			}
+		}
+	}
+
+	// the model is shared by every scope, so the drifted ones are pooled into a
+	// single retrain, urgent drift first; the urgent scope owns the candidate
+	sort.SliceStable(drifted, func(i, j int) bool {
+		return drifted[i].rec.DriftRecommendation == "urgent_retrain" && drifted[j].rec.DriftRecommendation != "urgent_retrain"
+	})
+	var (
+		pooled []*ModelRunRecord
+		series [][]mlclient.Candle
+	)
+	for _, scope := range drifted {
+		switch need := m.gate.MinCandles(); {
+		case len(scope.candles) < need:
+			scope.rec.Outcome = ModelRunSkipped
+			scope.rec.Reason = fmt.Sprintf("%d stored candles are too few to retrain and validate, need %d", len(scope.candles), need)
+		case len(pooled) >= m.cfg.MaxPooledScopes:
+			scope.rec.Outcome = ModelRunSkipped
+			scope.rec.Reason = "pooled retrain limit for this cycle reached"
+		default:
+			pooled = append(pooled, scope.rec)
+			series = append(series, scope.candles)
+		}
+	}
+	if len(pooled) > 0 {
+		m.gate.retrainAndNotify(ctx, pooled, series)
+	}
+	for _, scope := range drifted {
+		m.gate.record(ctx, scope.rec)
+	}
+	return reports, nil
+}
+
+// skipDrifted records drifted scopes that won't be retrained this cycle as
+// skipped, even when ctx is already cancelled
+func (m *DriftMonitor) skipDrifted(ctx context.Context, drifted []*driftScope, reason string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, scope := range drifted {
+		scope.rec.Outcome = ModelRunSkipped
+		scope.rec.Reason = reason
+		m.gate.record(ctx, scope.rec)
+	}
+}
+
+// check loads a scope's stored candles and runs the drift check. Returns nil
+// when there aren't enough candles yet.
+func (m *DriftMonitor) check(ctx context.Context, symbol, tf string) (*driftScope, error) {
+	records, err := m.candles.RecentCandles(ctx, symbol, tf, m.cfg.Candles)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < m.cfg.MinCandles {
+		slog.Debug("drift monitor: not enough stored candles", "symbol", symbol, "timeframe", tf, "count", len(records))
+		return nil, nil
+	}
+
+	candles := make([]mlclient.Candle, len(records))
+	for i, c := range records {
+		candles[i] = mlclient.Candle{
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+			Timestamp: c.Time.Unix(),
+		}
+	}
+	rec, err := m.gate.detect(ctx, symbol, tf, candles)
+	if err != nil {
+		return nil, err
+	}
+	return &driftScope{rec: rec, candles: candles}, nil
+}
+
+// Run checks all scopes on every tick until ctx is cancelled.
+func (m *DriftMonitor) Run(ctx context.Context, every time.Duration) {
+	ticker := m.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			reports, err := m.RunOnce(ctx)
+			if err != nil {
+				slog.Error("drift monitor: cycle failed", "error", err)
+				continue
+			}
+			drifted := 0
+			for _, r := range reports {
+				if r.DriftDetected {
+					drifted++
+				}
+			}
+			slog.Info("drift monitor: cycle complete", "scopes", len(reports), "drifted", drifted)
+		}
+	}
+}
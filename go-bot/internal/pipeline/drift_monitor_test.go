@@ -0,0 +1,163 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+type mockSymbols struct{ symbols []string }
+
+func (m *mockSymbols) ActiveSymbols(_ context.Context) ([]string, error) {
+	return m.symbols, nil
+}
+
+type mockCandleReader struct {
+	counts map[string]int // per symbol|interval, default 100
+}
+
+func (m *mockCandleReader) RecentCandles(_ context.Context, symbol, interval string, limit int) ([]*CandleRecord, error) {
+	n, ok := m.counts[stateKey(symbol, interval)]
+	if !ok {
+		n = limit
+	}
+	out := make([]*CandleRecord, n)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range out {
+		// encode the scope in the price so the drift mock can tell scopes apart
+		price := 100.0
+		if symbol == "ETH/USDT" {
+			price = 200
+		}
+		if interval == "1d" {
+			price++
+		}
+		out[i] = &CandleRecord{Time: base.Add(time.Duration(i) * time.Hour), Symbol: symbol, Interval: interval, Open: price, High: price, Low: price, Close: price}
+	}
+	return out, nil
+}
+
+// scopeDriftTrainer reports drift for candles whose close matches a drifted scope
+type scopeDriftTrainer struct {
+	mockTrainer
+	drifted  map[float64]string // close price -> recommendation
+	retrains [][]float64        // first close of each pooled series per retrain
+}
+
+func (m *scopeDriftTrainer) CheckDrift(_ context.Context, req *mlclient.DriftCheckRequest) (*mlclient.DriftCheckResponse, error) {
+	rec, ok := m.drifted[req.Candles[0].Close]
+	return &mlclient.DriftCheckResponse{DriftDetected: ok, Reason: "psi", Recommendation: rec}, nil
+}
+
+func (m *scopeDriftTrainer) Retrain(ctx context.Context, req *mlclient.RetrainRequest) (*mlclient.RetrainResponse, error) {
+	pooled := []float64{req.Candles[0].Close}
+	for _, series := range req.Series {
+		pooled = append(pooled, series[0].Close)
+	}
+	m.retrains = append(m.retrains, pooled)
+	return m.mockTrainer.Retrain(ctx, req)
+}
+
+func runDriftMonitor(t *testing.T, cfg DriftMonitorConfig) (*scopeDriftTrainer, *mockRunStore, map[string]*ModelRunRecord) {
+	t.Helper()
+	trainer := &scopeDriftTrainer{
+		mockTrainer: mockTrainer{candidate: 0.6, deployed: 0.5, wfAccuracy: 0.58},
+		drifted:     map[float64]string{201: "urgent_retrain", 100: "retrain"}, // ETH 1d, BTC 4h
+	}
+	store := &mockRunStore{}
+	gate := NewModelGate(trainer, store, DefaultGateConfig())
+	m := NewDriftMonitor(gate, &mockSymbols{symbols: []string{"ETH/USDT", "BTC/USDT", "SOL/USDT"}},
+		&mockCandleReader{counts: map[string]int{"SOL/USDT|4h": 30, "SOL/USDT|1d": 30}},
+		[]string{"4h", "1d"}, cfg)
+
+	reports, err := m.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 scopes checked (SOL has too few candles), got %d", len(reports))
+	}
+	if len(store.runs) != 4 {
+		t.Errorf("expected a report recorded per scope, got %d", len(store.runs))
+	}
+	runs := make(map[string]*ModelRunRecord)
+	for _, r := range store.runs {
+		runs[stateKey(r.Symbol, r.Timeframe)] = r
+	}
+	return trainer, store, runs
+}
+
+func TestDriftMonitorPoolsDriftedScopes(t *testing.T) {
+	trainer, _, runs := runDriftMonitor(t, DefaultDriftMonitorConfig())
+
+	// one retrain on both drifted scopes, the urgent one first
+	if len(trainer.retrains) != 1 || len(trainer.retrains[0]) != 2 || trainer.retrains[0][0] != 201 || trainer.retrains[0][1] != 100 {
+		t.Fatalf("expected one retrain pooling ETH/USDT 1d and BTC/USDT 4h, got %v", trainer.retrains)
+	}
+	want := map[string]string{
+		"ETH/USDT|1d": ModelRunPromoted,
+		"BTC/USDT|4h": ModelRunPromoted,
+		"BTC/USDT|1d": ModelRunNoDrift,
+		"ETH/USDT|4h": ModelRunNoDrift,
+	}
+	for k, v := range want {
+		if runs[k].Outcome != v {
+			t.Errorf("%s outcome = %q, want %q", k, runs[k].Outcome, v)
+		}
+	}
+	if len(runs["ETH/USDT|1d"].PooledWith) != 1 || runs["BTC/USDT|4h"].Staged {
+		t.Errorf("the urgent scope should own the pooled candidate, got %+v and %+v", runs["ETH/USDT|1d"], runs["BTC/USDT|4h"])
+	}
+}
+
+func TestDriftMonitorSkipsScopesPastThePoolLimit(t *testing.T) {
+	cfg := DefaultDriftMonitorConfig()
+	cfg.MaxPooledScopes = 1
+	trainer, _, runs := runDriftMonitor(t, cfg)
+
+	if len(trainer.retrains) != 1 || len(trainer.retrains[0]) != 1 || trainer.retrains[0][0] != 201 {
+		t.Fatalf("expected only ETH/USDT 1d to be retrained, got %v", trainer.retrains)
+	}
+	if r := runs["BTC/USDT|4h"]; r.Outcome != ModelRunSkipped || r.Reason == "" {
+		t.Errorf("BTC/USDT 4h should be recorded as skipped, got %+v", r)
+	}
+}
+
+// cancellingTrainer cancels the cycle once it has checked the scope with the given close
+type cancellingTrainer struct {
+	*scopeDriftTrainer
+	after  float64
+	cancel context.CancelFunc
+}
+
+func (m *cancellingTrainer) CheckDrift(ctx context.Context, req *mlclient.DriftCheckRequest) (*mlclient.DriftCheckResponse, error) {
+	if req.Candles[0].Close == m.after {
+		defer m.cancel()
+	}
+	return m.scopeDriftTrainer.CheckDrift(ctx, req)
+}
+
+func TestDriftMonitorRecordsDriftedScopesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	trainer := &cancellingTrainer{
+		scopeDriftTrainer: &scopeDriftTrainer{drifted: map[float64]string{100: "retrain"}}, // BTC 4h
+		after:             100,
+		cancel:            cancel,
+	}
+	store := &mockRunStore{}
+	m := NewDriftMonitor(NewModelGate(trainer, store, DefaultGateConfig()), &mockSymbols{symbols: []string{"BTC/USDT", "ETH/USDT"}},
+		&mockCandleReader{}, []string{"4h", "1d"}, DefaultDriftMonitorConfig())
+
+	if _, err := m.RunOnce(ctx); err != context.Canceled {
+		t.Fatalf("expected the cycle to stop on cancel, got %v", err)
+	}
+	if len(trainer.retrains) != 0 {
+		t.Errorf("a cancelled cycle shouldn't retrain, got %v", trainer.retrains)
+	}
+	if len(store.runs) != 1 || store.runs[0].Outcome != ModelRunSkipped || store.runs[0].Reason != "cycle cancelled" {
+		t.Errorf("the drifted BTC/USDT 4h scope should be recorded as skipped, got %+v", store.runs)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/claude"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
 )
 
 // formats the pipeline result as a telegram message (markdown v1)
@@ -36,6 +38,14 @@ func FormatTelegramMessage(r *Result) string {
 			r.Decision.Plan.RiskReward))
 	}
 
+	// key levels, and whether the plan respects them
+	if levels := formatKeyLevels(r.Structure); levels != "" {
+		b.WriteString(fmt.Sprintf("🧱 *Levels:* %s\n", levels))
+	}
+	for _, w := range planLevelWarnings(r) {
+		b.WriteString(fmt.Sprintf("⚠️ %s\n", w))
+	}
+
 	b.WriteString("\n")
 
 	// market data
@@ -58,8 +68,14 @@ func FormatTelegramMessage(r *Result) string {
 		} else if r.Prediction.Direction == "down" {
 			arrow = "↓"
 		}
-		b.WriteString(fmt.Sprintf("🤖 *ML:* %s%.2f%% (%.0f%% conf, %s)\n",
-			arrow, r.Prediction.Magnitude, r.Prediction.Confidence*100, r.Prediction.Timeframe))
+		agreement := ""
+		if r.Ensemble != nil {
+			if votes := r.Ensemble.Votes(); len(votes) > 1 {
+				agreement = fmt.Sprintf(", %.0f/%d models agree", r.Ensemble.Agreement()*float64(len(votes)), len(votes))
+			}
+		}
+		b.WriteString(fmt.Sprintf("🤖 *ML:* %s%.2f%% (%.0f%% conf, %s%s)\n",
+			arrow, r.Prediction.Magnitude, r.Prediction.Confidence*100, r.Prediction.Timeframe, agreement))
 	}
 
 	// sentiment
@@ -68,6 +84,11 @@ func FormatTelegramMessage(r *Result) string {
 			r.Sentiment.Label, r.Sentiment.Score, r.Sentiment.Confidence*100))
 	}
 
+	// chart patterns
+	if names := formatPatternNames(r.Patterns); names != "" {
+		b.WriteString(fmt.Sprintf("📐 *Patterns:* %s\n", names))
+	}
+
 	// reasoning
 	if r.Decision.Reasoning != "" {
 		b.WriteString(fmt.Sprintf("\n💡 %s\n", r.Decision.Reasoning))
@@ -123,6 +144,17 @@ func FormatDiscordFields(r *Result) (title string, description string, fields []
 		})
 	}
 
+	if levels := formatKeyLevels(r.Structure); levels != "" {
+		if warnings := planLevelWarnings(r); len(warnings) > 0 {
+			levels += "\n⚠️ " + strings.Join(warnings, "\n⚠️ ")
+		}
+		fields = append(fields, DiscordField{
+			Name:   "Key Levels",
+			Value:  levels,
+			Inline: false,
+		})
+	}
+
 	if r.Indicators != nil {
 		fields = append(fields, DiscordField{
 			Name:   "Indicators",
@@ -154,6 +186,77 @@ func FormatDiscordFields(r *Result) (title string, description string, fields []
 	return title, description, fields, color
 }
 
+// formats the nearest supports and resistances, e.g. "S $41,200, $40,850 | R $43,500"
+func formatKeyLevels(ms *claude.MarketStructure) string {
+	if ms == nil || (len(ms.Supports) == 0 && len(ms.Resistances) == 0) {
+		return ""
+	}
+	join := func(levels []claude.PriceLevel) string {
+		parts := make([]string, len(levels))
+		for i, l := range levels {
+			parts[i] = "$" + formatNum(l.Price)
+		}
+		return strings.Join(parts, ", ")
+	}
+	var parts []string
+	if len(ms.Supports) > 0 {
+		parts = append(parts, "S "+join(ms.Supports))
+	}
+	if len(ms.Resistances) > 0 {
+		parts = append(parts, "R "+join(ms.Resistances))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formats detected patterns, e.g. "double bottom (72%), bull flag (55%)".
+// underscores would open italics in telegram markdown.
+func formatPatternNames(resp *mlclient.PatternDetectResponse) string {
+	cp := toChartPatterns(resp)
+	if cp == nil {
+		return ""
+	}
+	parts := make([]string, len(cp.Patterns))
+	for i, p := range cp.Patterns {
+		parts[i] = fmt.Sprintf("%s (%.0f%%)", strings.ReplaceAll(p.Name, "_", " "), p.Confidence*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// planLevelWarnings flags stops sitting inside the nearest level and
+// targets set beyond the next one
+func planLevelWarnings(r *Result) []string {
+	if r.Decision == nil || r.Structure == nil || r.Decision.Plan.Entry <= 0 {
+		return nil
+	}
+	plan := r.Decision.Plan
+	var support, resistance float64
+	if len(r.Structure.Supports) > 0 {
+		support = r.Structure.Supports[0].Price
+	}
+	if len(r.Structure.Resistances) > 0 {
+		resistance = r.Structure.Resistances[0].Price
+	}
+
+	var warnings []string
+	switch r.Decision.Action {
+	case claude.ActionBuy:
+		if support > 0 && plan.StopLoss > support && plan.StopLoss < plan.Entry {
+			warnings = append(warnings, fmt.Sprintf("SL sits above support $%s", formatNum(support)))
+		}
+		if resistance > 0 && plan.TakeProfit > resistance {
+			warnings = append(warnings, fmt.Sprintf("TP is beyond resistance $%s", formatNum(resistance)))
+		}
+	case claude.ActionSell:
+		if resistance > 0 && plan.StopLoss < resistance && plan.StopLoss > plan.Entry {
+			warnings = append(warnings, fmt.Sprintf("SL sits below resistance $%s", formatNum(resistance)))
+		}
+		if support > 0 && plan.TakeProfit > 0 && plan.TakeProfit < support {
+			warnings = append(warnings, fmt.Sprintf("TP is beyond support $%s", formatNum(support)))
+		}
+	}
+	return warnings
+}
+
 // formats a compact indicators summary
 func formatIndicatorsSummary(ind *analysis.AnalysisResult) string {
 	var parts []string
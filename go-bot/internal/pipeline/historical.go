@@ -0,0 +1,157 @@
+// historical input reconstruction — rebuilds the claude input for a past bar from
+// candles alone, so backtests can replay the real decision pipeline.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// HistoryNeeded is how many primary candles BuildInput wants: the live
+// primary window, or enough to resample the live window of every higher
+// timeframe, whichever is more.
+func (p *Pipeline) HistoryNeeded() int {
+	need := primaryCandles
+	primary := intervalToDuration(p.timeframe)
+	if primary == 0 {
+		return need
+	}
+	for _, tf := range p.timeframes[1:] {
+		d := intervalToDuration(tf)
+		if d <= primary || d%primary != 0 {
+			continue
+		}
+		if n := int(d/primary) * htfCandles; n > need {
+			need = n
+		}
+	}
+	return need
+}
+
+// BuildInput assembles the AI input as of the last candle in the slice by
+// running the registered point-in-time stages (indicators, higher-timeframe
+// context resampled from the primary candles, market structure). Live-only
+// stages such as ML, sentiment, alt data and trade history are left out.
+// Indicators read the same window as a live analysis; pass HistoryNeeded
+// candles for full HTF context. Any stage failure fails the bar.
+func (p *Pipeline) BuildInput(ctx context.Context, symbol string, candles []exchange.Candle) (*claude.AnalysisInput, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles for %s", symbol)
+	}
+
+	ticker := syntheticTicker(symbol, candles, intervalToDuration(p.timeframe))
+
+	history := candles
+	if len(candles) > primaryCandles {
+		candles = candles[len(candles)-primaryCandles:]
+	}
+	sc := newStageContext(symbol, p.timeframe, ticker, candles)
+	sc.History = history
+
+	var stages []Stage
+	for _, s := range p.stages {
+		if s.PointInTime {
+			s.Required = true
+			stages = append(stages, s)
+		}
+	}
+	if _, err := runStages(ctx, stages, sc); err != nil {
+		return nil, err
+	}
+	return newAIInput(sc, stages), nil
+}
+
+// resampledHTFContext builds HTF snapshots by aggregating primary candles
+// into each higher timeframe that is a whole multiple of the primary one.
+func (p *Pipeline) resampledHTFContext(ctx context.Context, candles []exchange.Candle) []claude.HTFSnapshot {
+	primary := intervalToDuration(p.timeframe)
+	if primary == 0 {
+		return nil
+	}
+
+	var snapshots []claude.HTFSnapshot
+	for _, tf := range p.timeframes[1:] {
+		d := intervalToDuration(tf)
+		if d <= primary || d%primary != 0 {
+			continue
+		}
+		htf := resampleCandles(candles, int(d/primary))
+		if len(htf) < minHTFCandles {
+			continue
+		}
+		if len(htf) > htfCandles {
+			htf = htf[len(htf)-htfCandles:]
+		}
+		ind, err := p.indicators.AnalyzeAll(ctx, exchangeToAnalysisCandles(htf), nil)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, htfSnapshot(tf, htf, ind))
+	}
+	return snapshots
+}
+
+// resampleCandles aggregates every factor candles into one, aligned so the
+// last output candle ends on the last input candle.
+func resampleCandles(candles []exchange.Candle, factor int) []exchange.Candle {
+	if factor <= 1 {
+		return candles
+	}
+
+	var out []exchange.Candle
+	for end := len(candles); end-factor >= 0; end -= factor {
+		group := candles[end-factor : end]
+		c := exchange.Candle{
+			OpenTime:  group[0].OpenTime,
+			Open:      group[0].Open,
+			High:      group[0].High,
+			Low:       group[0].Low,
+			Close:     group[len(group)-1].Close,
+			CloseTime: group[len(group)-1].CloseTime,
+		}
+		for _, g := range group {
+			if g.High > c.High {
+				c.High = g.High
+			}
+			if g.Low < c.Low {
+				c.Low = g.Low
+			}
+			c.Volume += g.Volume
+		}
+		out = append(out, c)
+	}
+
+	// built newest-first, flip to chronological order
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// syntheticTicker derives price, 24h change and 24h quote volume from candles.
+func syntheticTicker(symbol string, candles []exchange.Candle, interval time.Duration) *exchange.Ticker {
+	last := candles[len(candles)-1]
+	t := &exchange.Ticker{Symbol: symbol, Price: last.Close}
+
+	bars := 1
+	if interval > 0 && interval < 24*time.Hour {
+		bars = int(24 * time.Hour / interval)
+	}
+	if bars > len(candles)-1 {
+		bars = len(candles) - 1
+	}
+
+	for _, c := range candles[len(candles)-bars:] {
+		t.QuoteVolume += c.Volume * c.Close
+	}
+	if bars > 0 {
+		if ref := candles[len(candles)-1-bars].Close; ref > 0 {
+			t.ChangePct = (last.Close - ref) / ref * 100
+		}
+	}
+	return t
+}
@@ -0,0 +1,199 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+func hourlyCandles(n int) []exchange.Candle {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]exchange.Candle, n)
+	for i := range candles {
+		price := 100 + float64(i)
+		candles[i] = exchange.Candle{
+			OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Open:     price - 0.5,
+			High:     price + 1,
+			Low:      price - 1,
+			Close:    price,
+			Volume:   10,
+		}
+	}
+	return candles
+}
+
+func TestResampleCandles(t *testing.T) {
+	candles := hourlyCandles(10)
+	out := resampleCandles(candles, 4)
+
+	// 10 bars / 4 = 2 full groups, aligned to the most recent bar
+	if len(out) != 2 {
+		t.Fatalf("len = %d, want 2", len(out))
+	}
+	last := out[1]
+	if last.OpenTime != candles[6].OpenTime {
+		t.Errorf("last open time = %v, want %v", last.OpenTime, candles[6].OpenTime)
+	}
+	if last.Open != candles[6].Open || last.Close != candles[9].Close {
+		t.Errorf("open/close = %f/%f, want %f/%f", last.Open, last.Close, candles[6].Open, candles[9].Close)
+	}
+	if last.High != candles[9].High || last.Low != candles[6].Low {
+		t.Errorf("high/low = %f/%f", last.High, last.Low)
+	}
+	if last.Volume != 40 {
+		t.Errorf("volume = %f, want 40", last.Volume)
+	}
+	if out[0].OpenTime.After(out[1].OpenTime) {
+		t.Error("output should be chronological")
+	}
+}
+
+func TestSyntheticTicker(t *testing.T) {
+	candles := hourlyCandles(30)
+	ticker := syntheticTicker("BTC/USDT", candles, time.Hour)
+
+	if ticker.Price != 129 {
+		t.Errorf("price = %f, want 129", ticker.Price)
+	}
+	// 24 bars back: close 105 → 129
+	want := (129.0 - 105.0) / 105.0 * 100
+	if math.Abs(ticker.ChangePct-want) > 1e-9 {
+		t.Errorf("change = %f, want %f", ticker.ChangePct, want)
+	}
+	if ticker.QuoteVolume <= 0 {
+		t.Error("expected positive quote volume")
+	}
+}
+
+func TestSyntheticTicker_ShortHistory(t *testing.T) {
+	ticker := syntheticTicker("BTC/USDT", hourlyCandles(1), time.Hour)
+	if ticker.Price != 100 || ticker.ChangePct != 0 {
+		t.Errorf("single candle ticker = %+v", ticker)
+	}
+}
+
+func TestBuildInput(t *testing.T) {
+	ind := &mockIndicators{result: &analysis.AnalysisResult{
+		RSI:  &analysis.RSIResult{Value: 55},
+		MACD: &analysis.MACDResult{MACDLine: 1, SignalLine: 0.5, Histogram: 0.5},
+		EMA:  &analysis.EMAResult{Value: 120},
+	}}
+	p := New(nil, ind, nil, nil)
+	p.SetTimeframes([]string{"1h", "4h"})
+
+	input, err := p.BuildInput(context.Background(), "BTC/USDT", hourlyCandles(150))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Market.Price != 249 {
+		t.Errorf("price = %f, want 249", input.Market.Price)
+	}
+	if input.Indicators == nil || input.Indicators.RSI != 55 {
+		t.Error("expected indicators from provider")
+	}
+	if input.Regime == nil {
+		t.Error("expected regime detection")
+	}
+	if len(input.HTFContext) != 1 || input.HTFContext[0].Timeframe != "4h" {
+		t.Fatalf("htf context = %+v, want one 4h snapshot", input.HTFContext)
+	}
+	if input.HTFContext[0].TrendDir != "up" {
+		t.Errorf("htf trend = %s, want up", input.HTFContext[0].TrendDir)
+	}
+	if input.Prediction != nil || input.Sentiment != nil || input.AltData != nil {
+		t.Error("live-only sources should be omitted")
+	}
+}
+
+func TestBuildInput_DailyContextFromHistory(t *testing.T) {
+	ind := &mockIndicators{result: &analysis.AnalysisResult{RSI: &analysis.RSIResult{Value: 55}}}
+	p := New(nil, ind, nil, nil)
+	p.SetTimeframes([]string{"4h", "1d"})
+
+	// six 4h candles per day, the live daily window is 50 days
+	if got := p.HistoryNeeded(); got != 300 {
+		t.Fatalf("history needed = %d, want 300", got)
+	}
+	short, err := p.BuildInput(context.Background(), "BTC/USDT", hourlyCandles(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(short.HTFContext) != 0 {
+		t.Errorf("100 candles resample to 16 days, too few for a snapshot, got %+v", short.HTFContext)
+	}
+	full, err := p.BuildInput(context.Background(), "BTC/USDT", hourlyCandles(p.HistoryNeeded()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full.HTFContext) != 1 || full.HTFContext[0].Timeframe != "1d" {
+		t.Errorf("htf context = %+v, want one 1d snapshot", full.HTFContext)
+	}
+
+	p.SetTimeframes([]string{"1h"})
+	if got := p.HistoryNeeded(); got != primaryCandles {
+		t.Errorf("history needed without htf = %d, want %d", got, primaryCandles)
+	}
+}
+
+func TestBuildInput_RunsPointInTimeStages(t *testing.T) {
+	p := New(nil, nil, nil, nil)
+	var replayed []exchange.Candle
+	if err := p.AddStage(Stage{
+		Name:        "replayable",
+		Provides:    []string{"replayable"},
+		PointInTime: true,
+		Run: func(_ context.Context, sc *StageContext) error {
+			replayed = sc.History
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddStage(Stage{
+		Name:     "live",
+		Provides: []string{"live"},
+		Run: func(context.Context, *StageContext) error {
+			t.Error("live-only stages shouldn't run when rebuilding a past bar")
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := p.BuildInput(context.Background(), "BTC/USDT", hourlyCandles(150))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 150 {
+		t.Errorf("point-in-time stage saw %d candles of history, want 150", len(replayed))
+	}
+	if input.Structure == nil {
+		t.Error("expected the structure stage to run without alt data")
+	}
+}
+
+func TestBuildInput_NoIndicatorProvider(t *testing.T) {
+	p := New(nil, nil, nil, nil)
+	input, err := p.BuildInput(context.Background(), "BTC/USDT", hourlyCandles(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Indicators == nil || input.Indicators.RSI == 0 {
+		t.Error("expected native go indicators without a provider")
+	}
+	if input.Regime == nil {
+		t.Error("expected regime")
+	}
+}
+
+func TestBuildInput_Empty(t *testing.T) {
+	p := New(nil, nil, nil, nil)
+	if _, err := p.BuildInput(context.Background(), "BTC/USDT", nil); err == nil {
+		t.Error("expected error with no candles")
+	}
+}
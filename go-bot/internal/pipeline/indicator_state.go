@@ -0,0 +1,345 @@
+// streaming indicator state — keeps one analysis.Stream per symbol and
+// interval, fed as candles close (by data ingestion or the pipeline's own
+// fetches), so analysis reads indicators in O(1) instead of shipping 100
+// candles to the engine and recomputing everything per request.
+// streams are snapshotted to a store (postgres or redis) and restored on start.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+const (
+	// candles fetched to rebuild a stream on cold start or after a gap
+	defaultWarmupCandles = 500
+	// a stream answers only after seeing as many candles as the pipeline analyzes
+	minStreamCandles = 100
+)
+
+// IndicatorSnapshot is one persisted stream.
+type IndicatorSnapshot struct {
+	Symbol     string
+	Interval   string
+	LastCandle time.Time // open time of the last candle folded into State
+	State      []byte
+}
+
+// IndicatorStateStore persists stream snapshots (implemented via database.IndicatorStateRepository
+// or database.RedisIndicatorStateStore).
+type IndicatorStateStore interface {
+	SaveIndicatorStates(ctx context.Context, snapshots []*IndicatorSnapshot) error
+	LoadIndicatorStates(ctx context.Context) ([]*IndicatorSnapshot, error)
+}
+
+type indicatorStream struct {
+	symbol   string
+	interval string
+	stream   *analysis.Stream
+	dirty    bool // changed since the last snapshot
+}
+
+// IndicatorState holds streaming indicators for every symbol and interval seen.
+type IndicatorState struct {
+	fetcher CandleFetcher
+	store   IndicatorStateStore
+	clock   clock.Clock
+	warmup  int
+
+	mu      sync.RWMutex
+	streams map[string]*indicatorStream
+}
+
+// NewIndicatorState creates an empty state. fetcher is used to rebuild a
+// stream from history on cold start or after a gap; nil rebuilds from
+// whatever candles were ingested.
+func NewIndicatorState(fetcher CandleFetcher) *IndicatorState {
+	return &IndicatorState{
+		fetcher: fetcher,
+		clock:   clock.Real(),
+		warmup:  defaultWarmupCandles,
+		streams: make(map[string]*indicatorStream),
+	}
+}
+
+// SetClock replaces the time source used to decide which candles are closed.
+func (s *IndicatorState) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetStore configures where snapshots are saved and restored from.
+func (s *IndicatorState) SetStore(store IndicatorStateStore) {
+	s.store = store
+}
+
+// Len returns the number of tracked streams.
+func (s *IndicatorState) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.streams)
+}
+
+func stateKey(symbol, interval string) string {
+	return symbol + "|" + interval
+}
+
+// Ingest feeds candles for one symbol and interval, oldest first as the
+// exchange returns them. Candles that haven't closed yet or were already
+// seen are skipped. A cold stream, or one with a gap before the new
+// candles, is rebuilt from history. Returns the number of candles applied.
+func (s *IndicatorState) Ingest(ctx context.Context, symbol, interval string, candles []exchange.Candle) (int, error) {
+	closed := s.closedCandles(interval, candles)
+	if len(closed) == 0 {
+		return 0, nil
+	}
+	key := stateKey(symbol, interval)
+
+	s.mu.Lock()
+	if entry, ok := s.streams[key]; ok && !hasGap(entry.stream, interval, closed) {
+		n := entry.apply(closed)
+		s.mu.Unlock()
+		return n, nil
+	}
+	s.mu.Unlock()
+
+	// rebuild outside the lock so readers aren't blocked on the fetch
+	history := closed
+	if s.fetcher != nil {
+		fetched, err := s.fetcher.GetCandles(ctx, symbol, interval, s.warmup)
+		if err != nil {
+			return 0, fmt.Errorf("failed to warm indicator state for %s %s: %w", symbol, interval, err)
+		}
+		history = s.closedCandles(interval, fetched)
+	}
+	entry := &indicatorStream{symbol: symbol, interval: interval, stream: analysis.NewStream(nil)}
+	n := entry.apply(history)
+	if entry.stream.Count() == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a concurrent ingest may have rebuilt it first; keep whichever is further along
+	if cur, ok := s.streams[key]; !ok || cur.stream.LastTime() < entry.stream.LastTime() {
+		s.streams[key] = entry
+	}
+	return n, nil
+}
+
+// Latest returns the streamed indicators when the stream is warm and has
+// folded in the most recently closed candle. ok is false otherwise, and the
+// caller should fall back to a batch computation.
+func (s *IndicatorState) Latest(symbol, interval string) (*analysis.AnalysisResult, bool) {
+	dur := intervalToDuration(interval)
+	if dur <= 0 {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.streams[stateKey(symbol, interval)]
+	if !ok || entry.stream.Count() < minStreamCandles {
+		return nil, false
+	}
+	// the candle after the last one seen must not have closed yet
+	nextClose := time.Unix(entry.stream.LastTime(), 0).Add(2 * dur)
+	if !s.clock.Now().Before(nextClose) {
+		return nil, false
+	}
+	return entry.stream.Result(), true
+}
+
+// batchHistory returns the candles a batch computation should read when
+// Latest misses: only closed ones, as the stream folds in, and as deep as the
+// stream's warm-up so smoothed indicators (ema, macd, wilder rsi/atr/adx) are
+// seeded over the same history. Cumulative levels (obv, vwap without an
+// anchor) still depend on where the history starts.
+func (s *IndicatorState) batchHistory(ctx context.Context, symbol, interval string, candles []exchange.Candle) []exchange.Candle {
+	closed := s.closedCandles(interval, candles)
+	if s.fetcher == nil || len(closed) >= s.warmup {
+		return closed
+	}
+	fetched, err := s.fetcher.GetCandles(ctx, symbol, interval, s.warmup)
+	if err != nil {
+		slog.Debug("indicator state: batch history fetch failed", "symbol", symbol, "interval", interval, "error", err)
+		return closed
+	}
+	if history := s.closedCandles(interval, fetched); len(history) > len(closed) {
+		return history
+	}
+	return closed
+}
+
+// Snapshot saves every stream that changed since the last snapshot.
+func (s *IndicatorState) Snapshot(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	var (
+		snapshots []*IndicatorSnapshot
+		saved     []*indicatorStream
+	)
+	for _, entry := range s.streams {
+		if !entry.dirty {
+			continue
+		}
+		data, err := entry.stream.MarshalJSON()
+		if err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to encode %s %s: %w", entry.symbol, entry.interval, err)
+		}
+		snapshots = append(snapshots, &IndicatorSnapshot{
+			Symbol:     entry.symbol,
+			Interval:   entry.interval,
+			LastCandle: time.Unix(entry.stream.LastTime(), 0).UTC(),
+			State:      data,
+		})
+		entry.dirty = false
+		saved = append(saved, entry)
+	}
+	s.mu.Unlock()
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+	if err := s.store.SaveIndicatorStates(ctx, snapshots); err != nil {
+		s.mu.Lock()
+		for _, entry := range saved {
+			entry.dirty = true
+		}
+		s.mu.Unlock()
+		return fmt.Errorf("failed to save indicator state: %w", err)
+	}
+	return nil
+}
+
+// Restore loads saved streams. Snapshots built with different indicator
+// parameters are discarded; stale ones are caught up (or rebuilt) by the
+// next Ingest. Returns the number of streams restored.
+func (s *IndicatorState) Restore(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+	snapshots, err := s.store.LoadIndicatorStates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load indicator state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	restored := 0
+	for _, snap := range snapshots {
+		stream := &analysis.Stream{}
+		if err := stream.UnmarshalJSON(snap.State); err != nil {
+			slog.Warn("indicator state: discarding unreadable snapshot",
+				"symbol", snap.Symbol, "interval", snap.Interval, "error", err)
+			continue
+		}
+		if !stream.Compatible(nil) {
+			slog.Info("indicator state: discarding snapshot with different parameters",
+				"symbol", snap.Symbol, "interval", snap.Interval)
+			continue
+		}
+		key := stateKey(snap.Symbol, snap.Interval)
+		if _, ok := s.streams[key]; ok {
+			continue
+		}
+		s.streams[key] = &indicatorStream{symbol: snap.Symbol, interval: snap.Interval, stream: stream}
+		restored++
+	}
+	return restored, nil
+}
+
+// Run snapshots on every tick until ctx is cancelled, then takes a final one.
+func (s *IndicatorState) Run(ctx context.Context, every time.Duration) {
+	ticker := s.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.Snapshot(finalCtx); err != nil {
+				slog.Error("indicator state: final snapshot failed", "error", err)
+			}
+			cancel()
+			return
+		case <-ticker.C():
+			if err := s.Snapshot(ctx); err != nil {
+				slog.Error("indicator state: snapshot failed", "error", err)
+			}
+		}
+	}
+}
+
+// closedCandles keeps the candles whose close time has passed
+func (s *IndicatorState) closedCandles(interval string, candles []exchange.Candle) []exchange.Candle {
+	now := s.clock.Now()
+	dur := intervalToDuration(interval)
+	closed := make([]exchange.Candle, 0, len(candles))
+	for _, c := range candles {
+		closeTime := c.CloseTime
+		if closeTime.IsZero() {
+			if dur <= 0 {
+				continue
+			}
+			closeTime = c.OpenTime.Add(dur)
+		}
+		if closeTime.After(now) {
+			continue
+		}
+		closed = append(closed, c)
+	}
+	return closed
+}
+
+// hasGap reports whether candles were missed between the stream's last
+// candle and the first new one
+func hasGap(stream *analysis.Stream, interval string, candles []exchange.Candle) bool {
+	dur := intervalToDuration(interval)
+	if stream.Count() == 0 {
+		return true
+	}
+	for _, c := range candles {
+		if c.OpenTime.Unix() <= stream.LastTime() {
+			continue
+		}
+		return dur > 0 && c.OpenTime.Unix() > stream.LastTime()+int64(dur/time.Second)
+	}
+	return false
+}
+
+// apply folds new candles into the stream, skipping ones already seen
+func (e *indicatorStream) apply(candles []exchange.Candle) int {
+	applied := 0
+	for _, c := range candles {
+		if e.stream.Count() > 0 && c.OpenTime.Unix() <= e.stream.LastTime() {
+			continue
+		}
+		err := e.stream.Update(analysis.Candle{
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+			Timestamp: c.OpenTime.Unix(),
+		})
+		if err != nil {
+			continue
+		}
+		applied++
+	}
+	if applied > 0 {
+		e.dirty = true
+	}
+	return applied
+}
@@ -0,0 +1,300 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// mock snapshot store
+type mockIndicatorStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*IndicatorSnapshot
+	saves     int
+	err       error
+}
+
+func (m *mockIndicatorStore) SaveIndicatorStates(_ context.Context, snapshots []*IndicatorSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if m.snapshots == nil {
+		m.snapshots = make(map[string]*IndicatorSnapshot)
+	}
+	for _, s := range snapshots {
+		m.snapshots[stateKey(s.Symbol, s.Interval)] = s
+	}
+	m.saves++
+	return nil
+}
+
+func (m *mockIndicatorStore) LoadIndicatorStates(_ context.Context) ([]*IndicatorSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*IndicatorSnapshot
+	for _, s := range m.snapshots {
+		out = append(out, s)
+	}
+	return out, m.err
+}
+
+// streamCandles builds n contiguous 4h candles; the last one
+// is still open at the returned time
+func streamCandles(n int) ([]exchange.Candle, time.Time) {
+	candles := makeCandles(n, "BTC/USDT")
+	for i := range candles {
+		candles[i].Close += float64(i%7) * 15
+		candles[i].CloseTime = candles[i].OpenTime.Add(4*time.Hour - time.Millisecond)
+	}
+	return candles, candles[n-1].OpenTime.Add(time.Hour)
+}
+
+// newestFetcher returns the newest limit candles like the exchange does
+type newestFetcher struct {
+	candles []exchange.Candle
+	calls   int
+}
+
+func (f *newestFetcher) GetCandles(_ context.Context, _, _ string, limit int) ([]exchange.Candle, error) {
+	f.calls++
+	if len(f.candles) > limit {
+		return f.candles[len(f.candles)-limit:], nil
+	}
+	return f.candles, nil
+}
+
+func TestIndicatorState_IngestSkipsOpenCandle(t *testing.T) {
+	candles, now := streamCandles(121)
+	state := NewIndicatorState(nil)
+	state.SetClock(clock.NewSimulated(now))
+
+	n, err := state.Ingest(context.Background(), "BTC/USDT", "4h", candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 120 {
+		t.Fatalf("expected 120 closed candles applied, got %d", n)
+	}
+
+	got, ok := state.Latest("BTC/USDT", "4h")
+	if !ok {
+		t.Fatal("expected a fresh result")
+	}
+	want, _ := analysis.NewLocal().AnalyzeAll(context.Background(), exchangeToAnalysisCandles(candles[:120]), nil)
+	if got.RSI == nil || got.RSI.Value != want.RSI.Value {
+		t.Errorf("streamed RSI %+v, batch %+v", got.RSI, want.RSI)
+	}
+	if got.OverallSignal != want.OverallSignal {
+		t.Errorf("streamed signal %s, batch %s", got.OverallSignal, want.OverallSignal)
+	}
+
+	// re-ingesting the same batch is a no-op
+	if n, _ := state.Ingest(context.Background(), "BTC/USDT", "4h", candles); n != 0 {
+		t.Errorf("expected duplicates to be skipped, applied %d", n)
+	}
+}
+
+func TestIndicatorState_LatestRequiresWarmAndFresh(t *testing.T) {
+	candles, now := streamCandles(51)
+	sim := clock.NewSimulated(now)
+	state := NewIndicatorState(nil)
+	state.SetClock(sim)
+
+	state.Ingest(context.Background(), "BTC/USDT", "4h", candles)
+	if _, ok := state.Latest("BTC/USDT", "4h"); ok {
+		t.Error("a stream with 50 candles shouldn't answer")
+	}
+
+	candles, now = streamCandles(121)
+	sim.Set(now)
+	state.Ingest(context.Background(), "BTC/USDT", "4h", candles)
+	if _, ok := state.Latest("BTC/USDT", "4h"); !ok {
+		t.Fatal("expected a fresh result")
+	}
+
+	// the open candle closes without being ingested
+	sim.Advance(3 * time.Hour)
+	if _, ok := state.Latest("BTC/USDT", "4h"); ok {
+		t.Error("expected a stale stream to miss")
+	}
+	if _, ok := state.Latest("ETH/USDT", "4h"); ok {
+		t.Error("expected an unknown symbol to miss")
+	}
+}
+
+func TestIndicatorState_GapRebuildsFromFetcher(t *testing.T) {
+	all, now := streamCandles(301)
+	fetcher := &newestFetcher{candles: all}
+	sim := clock.NewSimulated(all[149].OpenTime.Add(time.Hour))
+	state := NewIndicatorState(fetcher)
+	state.SetClock(sim)
+
+	// cold start warms from history rather than the small batch
+	if n, _ := state.Ingest(context.Background(), "BTC/USDT", "4h", all[140:150]); n != 149 {
+		t.Fatalf("expected 149 warm-up candles, got %d", n)
+	}
+	if fetcher.calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", fetcher.calls)
+	}
+
+	// contiguous candles are applied directly
+	sim.Set(all[159].OpenTime.Add(time.Hour))
+	if n, _ := state.Ingest(context.Background(), "BTC/USDT", "4h", all[145:160]); n != 10 {
+		t.Errorf("expected 10 new candles, got %d", n)
+	}
+	if fetcher.calls != 1 {
+		t.Errorf("contiguous ingest shouldn't fetch, got %d calls", fetcher.calls)
+	}
+
+	// a gap forces a rebuild
+	sim.Set(now)
+	if _, err := state.Ingest(context.Background(), "BTC/USDT", "4h", all[290:]); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.calls != 2 {
+		t.Errorf("expected a rebuild fetch after the gap, got %d calls", fetcher.calls)
+	}
+	if _, ok := state.Latest("BTC/USDT", "4h"); !ok {
+		t.Error("expected a fresh result after the rebuild")
+	}
+
+	failing := NewIndicatorState(&mockCandleFetcher{err: errors.New("down")})
+	failing.SetClock(sim)
+	if _, err := failing.Ingest(context.Background(), "BTC/USDT", "4h", all[290:]); err == nil {
+		t.Error("expected warm-up fetch error")
+	}
+}
+
+func TestIndicatorState_SnapshotRestore(t *testing.T) {
+	candles, now := streamCandles(121)
+	store := &mockIndicatorStore{}
+	state := NewIndicatorState(nil)
+	state.SetClock(clock.NewSimulated(now))
+	state.SetStore(store)
+
+	state.Ingest(context.Background(), "BTC/USDT", "4h", candles)
+	if err := state.Snapshot(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// nothing changed, nothing to save
+	state.Snapshot(context.Background())
+	if store.saves != 1 {
+		t.Errorf("expected 1 save, got %d", store.saves)
+	}
+	snap := store.snapshots[stateKey("BTC/USDT", "4h")]
+	if snap == nil || !snap.LastCandle.Equal(candles[119].OpenTime) {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	restored := NewIndicatorState(nil)
+	restored.SetClock(clock.NewSimulated(now))
+	restored.SetStore(store)
+	n, err := restored.Restore(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("restore: n=%d err=%v", n, err)
+	}
+	got, ok := restored.Latest("BTC/USDT", "4h")
+	want, _ := state.Latest("BTC/USDT", "4h")
+	if !ok || got.RSI.Value != want.RSI.Value {
+		t.Errorf("restored result differs: %+v vs %+v", got.RSI, want.RSI)
+	}
+
+	// a failed save keeps the streams dirty for the next attempt
+	state.Ingest(context.Background(), "ETH/USDT", "4h", candles)
+	store.err = errors.New("down")
+	if err := state.Snapshot(context.Background()); err == nil {
+		t.Fatal("expected save error")
+	}
+	store.err = nil
+	state.Snapshot(context.Background())
+	if store.snapshots[stateKey("ETH/USDT", "4h")] == nil {
+		t.Error("expected the retry to save ETH/USDT")
+	}
+}
+
+func TestPipelineUsesIndicatorState(t *testing.T) {
+	candles, now := streamCandles(121)
+	ind := &mockIndicators{result: testIndicators()}
+	p := New(&mockExchange{ticker: testTicker(), candles: candles}, ind, nil, &mockAI{decision: testDecision()})
+
+	state := NewIndicatorState(nil)
+	state.SetClock(clock.NewSimulated(now))
+	p.SetIndicatorState(state)
+
+	result, err := p.Analyze(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Indicators == ind.result {
+		t.Error("expected indicators from the streaming state")
+	}
+	if state.Len() != 1 {
+		t.Errorf("expected the pipeline's candles to seed the state, got %d streams", state.Len())
+	}
+}
+
+// recordingIndicators remembers the candles of the last batch computation
+type recordingIndicators struct {
+	mockIndicators
+	got []analysis.Candle
+}
+
+func (r *recordingIndicators) AnalyzeAll(ctx context.Context, candles []analysis.Candle, opts *analysis.AnalyzeOptions) (*analysis.AnalysisResult, error) {
+	r.got = candles
+	return r.mockIndicators.AnalyzeAll(ctx, candles, opts)
+}
+
+// flakyFetcher fails its first call
+type flakyFetcher struct {
+	newestFetcher
+	failed bool
+}
+
+func (f *flakyFetcher) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]exchange.Candle, error) {
+	if !f.failed {
+		f.failed = true
+		return nil, errors.New("timeout")
+	}
+	return f.newestFetcher.GetCandles(ctx, symbol, interval, limit)
+}
+
+func TestPipelineBatchFallbackReadsClosedHistory(t *testing.T) {
+	// a cold stream: the fallback drops the forming candle like the stream does
+	candles, now := streamCandles(51)
+	ind := &recordingIndicators{mockIndicators: mockIndicators{result: testIndicators()}}
+	p := New(&mockExchange{ticker: testTicker(), candles: candles}, ind, nil, &mockAI{decision: testDecision()})
+	state := NewIndicatorState(nil)
+	state.SetClock(clock.NewSimulated(now))
+	p.SetIndicatorState(state)
+
+	if _, err := p.Analyze(context.Background(), "BTC/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ind.got) != 50 || ind.got[49].Timestamp != candles[49].OpenTime.Unix() {
+		t.Fatalf("batch read %d candles, want the 50 closed ones", len(ind.got))
+	}
+
+	// the warm-up fetch failed during ingest: the fallback reads the
+	// warm-up depth rather than the 100 candles analysis fetched
+	all, now := streamCandles(301)
+	ind = &recordingIndicators{mockIndicators: mockIndicators{result: testIndicators()}}
+	p = New(&mockExchange{ticker: testTicker(), candles: all[201:]}, ind, nil, &mockAI{decision: testDecision()})
+	state = NewIndicatorState(&flakyFetcher{newestFetcher: newestFetcher{candles: all}})
+	state.SetClock(clock.NewSimulated(now))
+	p.SetIndicatorState(state)
+
+	if _, err := p.Analyze(context.Background(), "BTC/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ind.got) != 300 || ind.got[299].Timestamp != all[299].OpenTime.Unix() {
+		t.Errorf("batch read %d candles, want the 300 closed ones of the warm-up history", len(ind.got))
+	}
+}
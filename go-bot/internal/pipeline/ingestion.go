@@ -64,6 +64,7 @@ type DataIngestion struct {
 	store    CandleStore
 	symbols  SymbolProvider
 	config   DataIngestionConfig
+	state    *IndicatorState
 
 	mu      sync.Mutex
 	running bool
@@ -85,6 +86,11 @@ func NewDataIngestion(fetcher CandleFetcher, store CandleStore, symbols SymbolPr
 	}
 }
 
+// SetIndicatorState feeds every fetched batch into the streaming indicators.
+func (d *DataIngestion) SetIndicatorState(state *IndicatorState) {
+	d.state = state
+}
+
 // Start begins the background candle ingestion loop.
 func (d *DataIngestion) Start(ctx context.Context) {
 	d.mu.Lock()
@@ -210,7 +216,18 @@ func (d *DataIngestion) ingestSymbol(ctx context.Context, symbol, interval strin
 		}
 	}
 
-	return d.store.UpsertBatch(ctx, records)
+	stored, err := d.store.UpsertBatch(ctx, records)
+	if err != nil {
+		return stored, err
+	}
+
+	if d.state != nil {
+		if _, err := d.state.Ingest(ctx, symbol, interval, candles); err != nil {
+			slog.Warn("data ingestion: indicator state update failed",
+				"symbol", symbol, "interval", interval, "error", err)
+		}
+	}
+	return stored, nil
 }
 
 // intervalToDuration converts a candle interval string to a time.Duration.
@@ -218,6 +235,8 @@ func intervalToDuration(interval string) time.Duration {
 	switch interval {
 	case "1m":
 		return time.Minute
+	case "3m":
+		return 3 * time.Minute
 	case "5m":
 		return 5 * time.Minute
 	case "15m":
@@ -226,10 +245,22 @@ func intervalToDuration(interval string) time.Duration {
 		return 30 * time.Minute
 	case "1h":
 		return time.Hour
+	case "2h":
+		return 2 * time.Hour
 	case "4h":
 		return 4 * time.Hour
+	case "6h":
+		return 6 * time.Hour
+	case "8h":
+		return 8 * time.Hour
+	case "12h":
+		return 12 * time.Hour
 	case "1d":
 		return 24 * time.Hour
+	case "3d":
+		return 72 * time.Hour
+	case "1w":
+		return 7 * 24 * time.Hour
 	default:
 		return 0
 	}
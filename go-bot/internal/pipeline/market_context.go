@@ -0,0 +1,208 @@
+// market context — what BTC, ETH and the whole market are doing, so altcoin
+// analyses don't happen in a vacuum. the benchmark trends come from exchange
+// candles and the market-wide numbers from coingecko; both are fetched once
+// per cycle and shared by every symbol, while relative strength against BTC
+// is computed per symbol.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+	"github.com/trading-bot/go-bot/internal/regime"
+)
+
+const (
+	btcSymbol = "BTC/USDT"
+	ethSymbol = "ETH/USDT"
+
+	// changes and relative strength are measured over this many candles
+	marketContextWindow = 20
+)
+
+// GlobalMarket is market-wide data, e.g. from coingecko.
+type GlobalMarket struct {
+	TotalMarketCap     float64 // USD
+	MarketCapChange24h float64 // percent
+	BTCDominance       float64 // percent of total market cap
+}
+
+// GlobalMarketProvider fetches market-wide data.
+type GlobalMarketProvider interface {
+	GlobalMarket(ctx context.Context) (*GlobalMarket, error)
+}
+
+// MarketContextProvider builds the market context for non-BTC symbols.
+type MarketContextProvider struct {
+	exchange  ExchangeProvider
+	global    GlobalMarketProvider
+	timeframe string
+	ttl       time.Duration
+	clock     clock.Clock
+
+	// held while refreshing so concurrent analyses fetch once per cycle
+	mu        sync.Mutex
+	snapshot  *marketSnapshot
+	fetchedAt time.Time
+}
+
+// marketSnapshot is the shared part of the context plus the btc candles
+// relative strength is measured against
+type marketSnapshot struct {
+	context    claude.MarketContext
+	btcCandles []exchange.Candle
+}
+
+// NewMarketContextProvider reads benchmark candles of the given timeframe and
+// refreshes them after ttl, typically one scan cycle.
+func NewMarketContextProvider(ex ExchangeProvider, timeframe string, ttl time.Duration) *MarketContextProvider {
+	return &MarketContextProvider{
+		exchange:  ex,
+		timeframe: timeframe,
+		ttl:       ttl,
+		clock:     clock.Real(),
+	}
+}
+
+// SetGlobal adds BTC dominance and total market cap to the context.
+func (m *MarketContextProvider) SetGlobal(global GlobalMarketProvider) {
+	m.global = global
+}
+
+// SetClock replaces the time source used for the cache.
+func (m *MarketContextProvider) SetClock(clk clock.Clock) {
+	m.clock = clk
+}
+
+// For returns the market context for a symbol given its candles of the same
+// timeframe, oldest first. Returns nil for BTC itself.
+func (m *MarketContextProvider) For(ctx context.Context, symbol string, candles []exchange.Candle) (*claude.MarketContext, error) {
+	if isBTC(symbol) {
+		return nil, nil
+	}
+	snap, err := m.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mc := snap.context
+	mc.RelativeStrength = relativeStrength(candles, snap.btcCandles, marketContextWindow)
+	return &mc, nil
+}
+
+// current returns the cached snapshot, refreshing it once it's older than the ttl
+func (m *MarketContextProvider) current(ctx context.Context) (*marketSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.snapshot != nil && m.clock.Now().Sub(m.fetchedAt) < m.ttl {
+		return m.snapshot, nil
+	}
+
+	btcCandles, err := m.exchange.GetCandles(ctx, btcSymbol, m.timeframe, 100)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s candles: %w", btcSymbol, err)
+	}
+	snap := &marketSnapshot{
+		context:    claude.MarketContext{Timeframe: m.timeframe, Window: marketContextWindow},
+		btcCandles: btcCandles,
+	}
+	snap.context.BTC = assetTrend(btcSymbol, btcCandles, marketContextWindow)
+
+	// eth and the global numbers are nice to have
+	if ethCandles, err := m.exchange.GetCandles(ctx, ethSymbol, m.timeframe, 100); err != nil {
+		slog.Warn("market context: eth candles unavailable", "error", err)
+	} else {
+		snap.context.ETH = assetTrend(ethSymbol, ethCandles, marketContextWindow)
+	}
+	if m.global != nil {
+		if g, err := m.global.GlobalMarket(ctx); err != nil {
+			slog.Warn("market context: global market data unavailable", "error", err)
+		} else {
+			snap.context.BTCDominance = g.BTCDominance
+			snap.context.TotalMarketCap = g.TotalMarketCap
+			snap.context.MarketCapChange24h = g.MarketCapChange24h
+		}
+	}
+
+	m.snapshot = snap
+	m.fetchedAt = m.clock.Now()
+	return snap, nil
+}
+
+// assetTrend summarizes a benchmark's change over the window and its regime
+func assetTrend(symbol string, candles []exchange.Candle, window int) *claude.AssetTrend {
+	if len(candles) == 0 {
+		return nil
+	}
+	price := candles[len(candles)-1].Close
+	trend := &claude.AssetTrend{
+		Symbol:    symbol,
+		Price:     price,
+		ChangePct: round2(windowChange(candles, window)),
+		Trend:     "neutral",
+	}
+	if len(candles) >= 28 {
+		det := regime.Detect(exchangeToRegimeCandles(candles), price)
+		trend.Trend = det.TrendDir
+		trend.Regime = string(det.Regime)
+		trend.ADX = round2(det.ADX)
+	}
+	return trend
+}
+
+// relativeStrength compares the symbol's change with btc's over the same
+// candles; nil when either doesn't cover the window
+func relativeStrength(candles, btcCandles []exchange.Candle, window int) *claude.RelativeStrength {
+	if len(candles) <= window || len(btcCandles) <= window {
+		return nil
+	}
+	// measure from the same candle when both series are timestamped
+	btc := btcCandles
+	last := candles[len(candles)-1].OpenTime
+	for i := len(btc) - 1; !last.IsZero() && i >= 0; i-- {
+		if btc[i].OpenTime.Equal(last) {
+			btc = btc[:i+1]
+			break
+		}
+	}
+	if len(btc) <= window {
+		return nil
+	}
+
+	sym := windowChange(candles, window)
+	ref := windowChange(btc, window)
+	return &claude.RelativeStrength{
+		SymbolChangePct: round2(sym),
+		BTCChangePct:    round2(ref),
+		RelativePct:     round2(sym - ref),
+	}
+}
+
+// windowChange is the percent change of the close over the last window candles
+func windowChange(candles []exchange.Candle, window int) float64 {
+	if len(candles) < 2 {
+		return 0
+	}
+	from := candles[max(0, len(candles)-1-window)].Close
+	if from == 0 {
+		return 0
+	}
+	return (candles[len(candles)-1].Close - from) / from * 100
+}
+
+func isBTC(symbol string) bool {
+	return strings.HasPrefix(strings.ToUpper(symbol), "BTC/")
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,146 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+)
+
+// symbolExchange serves candles per symbol and counts candle requests
+type symbolExchange struct {
+	candles map[string][]exchange.Candle
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (m *symbolExchange) GetPrice(_ context.Context, symbol string) (*exchange.Ticker, error) {
+	c := m.candles[symbol]
+	return &exchange.Ticker{Symbol: symbol, Price: c[len(c)-1].Close}, nil
+}
+
+func (m *symbolExchange) GetCandles(_ context.Context, symbol, _ string, _ int) ([]exchange.Candle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[symbol]++
+	c, ok := m.candles[symbol]
+	if !ok {
+		return nil, errors.New("unknown symbol")
+	}
+	return c, nil
+}
+
+// trendCandles returns n candles whose close moves by step percent per candle
+func trendCandles(n int, start, step float64) []exchange.Candle {
+	candles := make([]exchange.Candle, n)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	price := start
+	for i := range candles {
+		candles[i] = exchange.Candle{
+			OpenTime: base.Add(time.Duration(i) * 4 * time.Hour),
+			Open:     price,
+			High:     price * 1.01,
+			Low:      price * 0.99,
+			Close:    price * (1 + step/100),
+		}
+		price = candles[i].Close
+	}
+	return candles
+}
+
+type mockGlobal struct {
+	market *GlobalMarket
+}
+
+func (m *mockGlobal) GlobalMarket(context.Context) (*GlobalMarket, error) {
+	return m.market, nil
+}
+
+func TestMarketContextProvider(t *testing.T) {
+	ex := &symbolExchange{candles: map[string][]exchange.Candle{
+		btcSymbol:  trendCandles(100, 40000, 0.5),
+		ethSymbol:  trendCandles(100, 2000, 0.2),
+		"SOL/USDT": trendCandles(100, 100, -0.3),
+	}}
+	sim := clock.NewSimulated(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	m := NewMarketContextProvider(ex, "4h", 5*time.Minute)
+	m.SetClock(sim)
+	m.SetGlobal(&mockGlobal{market: &GlobalMarket{TotalMarketCap: 2.4e12, MarketCapChange24h: 1.2, BTCDominance: 54}})
+	ctx := context.Background()
+
+	mc, err := m.For(ctx, "SOL/USDT", ex.candles["SOL/USDT"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc.BTC == nil || mc.BTC.Trend != "up" || mc.BTC.ChangePct <= 0 || mc.ETH == nil {
+		t.Errorf("expected btc and eth trends, got btc %+v eth %+v", mc.BTC, mc.ETH)
+	}
+	if mc.BTCDominance != 54 || mc.TotalMarketCap != 2.4e12 {
+		t.Errorf("expected the global numbers, got %+v", mc)
+	}
+	rs := mc.RelativeStrength
+	if rs == nil || rs.SymbolChangePct >= 0 || rs.RelativePct >= 0 || rs.RelativePct != round2(rs.SymbolChangePct-rs.BTCChangePct) {
+		t.Errorf("sol should underperform btc, got %+v", rs)
+	}
+
+	// a second symbol in the same cycle reuses the snapshot
+	if _, err := m.For(ctx, ethSymbol, ex.candles[ethSymbol]); err != nil {
+		t.Fatal(err)
+	}
+	if ex.calls[btcSymbol] != 1 {
+		t.Errorf("btc candles should be fetched once per cycle, got %d", ex.calls[btcSymbol])
+	}
+	sim.Advance(6 * time.Minute)
+	if _, err := m.For(ctx, "SOL/USDT", ex.candles["SOL/USDT"]); err != nil {
+		t.Fatal(err)
+	}
+	if ex.calls[btcSymbol] != 2 {
+		t.Errorf("an expired snapshot should be refreshed, got %d fetches", ex.calls[btcSymbol])
+	}
+
+	if mc, err := m.For(ctx, btcSymbol, ex.candles[btcSymbol]); mc != nil || err != nil {
+		t.Errorf("btc gets no market context, got %+v, %v", mc, err)
+	}
+}
+
+func TestPipelineMarketContextStage(t *testing.T) {
+	ex := &symbolExchange{candles: map[string][]exchange.Candle{
+		btcSymbol:  trendCandles(100, 40000, 0.5),
+		"SOL/USDT": trendCandles(100, 100, 0.8),
+	}}
+	ai := &mockAI{decision: testDecision()}
+	p := New(ex, &mockIndicators{result: testIndicators()}, nil, ai)
+	p.SetMarketContext(NewMarketContextProvider(ex, "4h", time.Minute))
+
+	if _, err := p.Analyze(context.Background(), "SOL/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	mc := ai.input.MarketContext
+	if mc == nil || mc.BTC == nil || mc.RelativeStrength == nil || mc.RelativeStrength.RelativePct <= 0 {
+		t.Fatalf("expected btc context with sol outperforming, got %+v", mc)
+	}
+	if mc.ETH != nil {
+		t.Error("eth candles failed, so eth should be left out")
+	}
+
+	result, err := p.Analyze(context.Background(), btcSymbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ai.input.MarketContext != nil {
+		t.Error("btc analyses shouldn't get market context")
+	}
+	for _, r := range result.Stages {
+		if r.Name == "market_context" && !r.Skipped {
+			t.Errorf("the stage should be skipped for btc, got %+v", r)
+		}
+	}
+}
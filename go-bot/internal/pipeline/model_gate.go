@@ -0,0 +1,328 @@
+// ML model promotion gate — when the ml service reports concept drift, a
+// candidate model is retrained and staged on all but the latest candles, then
+// scored with the deployed model on walk-forward folds of the held-out ones.
+// It's only promoted when its held-out accuracy clears a minimum and beats the
+// deployed model's by a minimum margin. Every check is recorded, admins are
+// told the outcome, and a rejected candidate can still be promoted by hand.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// Model run outcomes.
+const (
+	ModelRunNoDrift  = "no_drift"
+	ModelRunFailed   = "failed"   // retraining, validation or promotion errored
+	ModelRunRejected = "rejected" // candidate staged but below the gate
+	ModelRunPromoted = "promoted"
+	ModelRunSkipped  = "skipped" // drift found but the retrain was deferred or lacked history
+)
+
+// ModelTrainer is the slice of the ml service the gate drives
+// (implemented by mlclient.Client).
+type ModelTrainer interface {
+	CheckDrift(ctx context.Context, req *mlclient.DriftCheckRequest) (*mlclient.DriftCheckResponse, error)
+	Retrain(ctx context.Context, req *mlclient.RetrainRequest) (*mlclient.RetrainResponse, error)
+	ValidateCandidate(ctx context.Context, req *mlclient.CandidateValidationRequest) (*mlclient.CandidateValidationResponse, error)
+	PromoteModel(ctx context.Context) (*mlclient.PromoteResponse, error)
+}
+
+// ModelRunRecord is one drift check and, when drift was found, the retrain,
+// validation and promotion decision that followed. Accuracies are 0-1;
+// CandidateAccuracy is from the retrain's own validation split, the deployed
+// and walk-forward accuracies are averaged over the held-out folds.
+type ModelRunRecord struct {
+	ID                  int
+	Symbol              string
+	Timeframe           string
+	DriftDetected       bool
+	DriftReason         string
+	DriftRecommendation string
+	DriftChecks         map[string]interface{} // per-test details from the ml service
+	Staged              bool                   // a candidate model was staged in the ml service
+	CandidateAccuracy   float64
+	DeployedAccuracy    float64
+	WalkForwardAccuracy float64
+	WalkForwardFolds    int
+	Outcome             string
+	Reason              string
+	PromotedBy          string // "gate", or the admin who forced the promotion
+	CreatedAt           time.Time
+
+	PooledWith []string // other scopes whose candles went into the same retrain
+}
+
+// ModelRunStore persists model runs (implemented via database.ModelRunRepository,
+// which logs every drift check and keeps the retrain history).
+type ModelRunStore interface {
+	SaveModelRun(ctx context.Context, rec *ModelRunRecord) error
+	// LatestStagedModelRun returns the most recent run that staged a
+	// candidate, or nil if there is none.
+	LatestStagedModelRun(ctx context.Context) (*ModelRunRecord, error)
+	MarkModelRunPromoted(ctx context.Context, id int, by string) error
+}
+
+// GateConfig holds the promotion thresholds.
+type GateConfig struct {
+	MinAccuracy    float64 // minimum held-out direction accuracy, 0-1
+	MinImprovement float64 // minimum held-out accuracy gain over the deployed model, 0-1
+	Splits         int     // walk-forward folds
+	Epochs         int     // retraining epochs
+}
+
+const (
+	// held-out candles per validation fold; the candidate never trains on them
+	foldCandles = 20
+	// fewest candles a candidate is retrained on
+	minTrainCandles = 200
+)
+
+// DefaultGateConfig returns the default promotion thresholds.
+func DefaultGateConfig() GateConfig {
+	return GateConfig{
+		MinAccuracy:    0.52,
+		MinImprovement: 0.01,
+		Splits:         5,
+		Epochs:         50,
+	}
+}
+
+// ModelGate runs drift checks and gates promotion of retrained models.
+type ModelGate struct {
+	trainer ModelTrainer
+	store   ModelRunStore
+	cfg     GateConfig
+	notify  func(text string)
+}
+
+// NewModelGate creates a gate that retrains through trainer and records runs in store.
+func NewModelGate(trainer ModelTrainer, store ModelRunStore, cfg GateConfig) *ModelGate {
+	def := DefaultGateConfig()
+	if cfg.Splits <= 0 {
+		cfg.Splits = def.Splits
+	}
+	if cfg.Epochs <= 0 {
+		cfg.Epochs = def.Epochs
+	}
+	return &ModelGate{trainer: trainer, store: store, cfg: cfg}
+}
+
+// MinCandles is the history a retrain needs: the training window plus the
+// held-out candles of every fold.
+func (g *ModelGate) MinCandles() int {
+	return minTrainCandles + g.cfg.Splits*foldCandles
+}
+
+// SetNotifier sets where retrain outcomes are reported (e.g. the admin chat).
+func (g *ModelGate) SetNotifier(fn func(text string)) {
+	g.notify = fn
+}
+
+// Check runs a drift check on candles and, if drift is detected, retrains a
+// candidate, validates it walk-forward and promotes it when it clears the
+// gate. The run is recorded whatever the outcome; an error is only returned
+// when the drift check itself fails.
+func (g *ModelGate) Check(ctx context.Context, symbol, timeframe string, candles []mlclient.Candle) (*ModelRunRecord, error) {
+	rec, err := g.detect(ctx, symbol, timeframe, candles)
+	if err != nil {
+		return nil, err
+	}
+	if rec.DriftDetected {
+		g.retrainAndNotify(ctx, []*ModelRunRecord{rec}, [][]mlclient.Candle{candles})
+	}
+	g.record(ctx, rec)
+	return rec, nil
+}
+
+// detect runs the drift check for one symbol and timeframe
+func (g *ModelGate) detect(ctx context.Context, symbol, timeframe string, candles []mlclient.Candle) (*ModelRunRecord, error) {
+	drift, err := g.trainer.CheckDrift(ctx, &mlclient.DriftCheckRequest{Candles: candles})
+	if err != nil {
+		return nil, fmt.Errorf("drift check failed: %w", err)
+	}
+	return &ModelRunRecord{
+		Symbol:              symbol,
+		Timeframe:           timeframe,
+		DriftDetected:       drift.DriftDetected,
+		DriftReason:         drift.Reason,
+		DriftRecommendation: drift.Recommendation,
+		DriftChecks:         drift.Checks,
+		Outcome:             ModelRunNoDrift,
+	}, nil
+}
+
+// retrainAndNotify retrains one candidate on the pooled candles of the
+// drifted scopes (series[i] belongs to scopes[i]) and gives every scope the
+// outcome. The model is shared by all symbols and timeframes, so training it
+// on a single scope would fit it to that scope alone. The first scope owns
+// the staged candidate.
+func (g *ModelGate) retrainAndNotify(ctx context.Context, scopes []*ModelRunRecord, series [][]mlclient.Candle) {
+	primary := scopes[0]
+	slog.Warn("concept drift detected, retraining candidate",
+		"symbol", primary.Symbol, "timeframe", primary.Timeframe, "reason", primary.DriftReason, "scopes", len(scopes))
+	g.retrain(ctx, primary, series)
+	for _, rec := range scopes[1:] {
+		rec.CandidateAccuracy = primary.CandidateAccuracy
+		rec.DeployedAccuracy = primary.DeployedAccuracy
+		rec.WalkForwardAccuracy = primary.WalkForwardAccuracy
+		rec.WalkForwardFolds = primary.WalkForwardFolds
+		rec.Outcome, rec.Reason, rec.PromotedBy = primary.Outcome, primary.Reason, primary.PromotedBy
+		primary.PooledWith = append(primary.PooledWith, rec.Symbol+" "+rec.Timeframe)
+	}
+	g.send(primary.Summary())
+}
+
+func (g *ModelGate) record(ctx context.Context, rec *ModelRunRecord) {
+	if err := g.store.SaveModelRun(ctx, rec); err != nil {
+		slog.Error("failed to record model run", "symbol", rec.Symbol, "timeframe", rec.Timeframe, "error", err)
+	}
+}
+
+// retrain stages a candidate trained on all but the held-out candles of
+// every series, validates it on them and applies the gate, filling in rec
+func (g *ModelGate) retrain(ctx context.Context, rec *ModelRunRecord, series [][]mlclient.Candle) {
+	need := g.MinCandles()
+	for _, candles := range series {
+		if len(candles) < need {
+			rec.Outcome = ModelRunSkipped
+			rec.Reason = fmt.Sprintf("%d candles are too few to retrain and validate, need %d", len(candles), need)
+			return
+		}
+	}
+	holdout := g.cfg.Splits * foldCandles
+	train := make([][]mlclient.Candle, len(series))
+	for i, candles := range series {
+		train[i] = candles[:len(candles)-holdout]
+	}
+
+	resp, err := g.trainer.Retrain(ctx, &mlclient.RetrainRequest{Candles: train[0], Series: train[1:], Epochs: g.cfg.Epochs, StageOnly: true})
+	if err != nil {
+		rec.Outcome, rec.Reason = ModelRunFailed, err.Error()
+		return
+	}
+	if !resp.Success {
+		rec.Outcome, rec.Reason = ModelRunFailed, "retrain failed: "+resp.Reason
+		return
+	}
+	rec.Staged = resp.Staged
+	rec.CandidateAccuracy = resp.NewAccuracy()
+	if !rec.Staged {
+		// an ml service without staging promotes (or not) on its own
+		rec.Outcome, rec.Reason = ModelRunFailed, "ml service did not stage the candidate"
+		return
+	}
+
+	v, err := g.trainer.ValidateCandidate(ctx, &mlclient.CandidateValidationRequest{
+		Candles: series[0],
+		Series:  series[1:],
+		Holdout: holdout,
+		NSplits: g.cfg.Splits,
+	})
+	if err != nil {
+		rec.Outcome, rec.Reason = ModelRunFailed, err.Error()
+		return
+	}
+	if !v.Success {
+		rec.Outcome, rec.Reason = ModelRunFailed, "walk-forward failed: "+v.Reason
+		return
+	}
+	rec.WalkForwardAccuracy = v.AvgDirectionAccuracy
+	rec.DeployedAccuracy = v.AvgDeployedAccuracy
+	rec.WalkForwardFolds = len(v.Folds)
+
+	if reason := g.reject(rec); reason != "" {
+		rec.Outcome, rec.Reason = ModelRunRejected, reason
+		return
+	}
+
+	if err := g.promote(ctx); err != nil {
+		rec.Outcome, rec.Reason = ModelRunFailed, err.Error()
+		return
+	}
+	rec.Outcome, rec.PromotedBy = ModelRunPromoted, "gate"
+}
+
+// reject returns why a validated candidate misses the gate, or "" if it passes
+func (g *ModelGate) reject(rec *ModelRunRecord) string {
+	if rec.WalkForwardAccuracy < g.cfg.MinAccuracy {
+		return fmt.Sprintf("walk-forward accuracy %.1f%% is below the %.1f%% minimum",
+			rec.WalkForwardAccuracy*100, g.cfg.MinAccuracy*100)
+	}
+	if gain := rec.WalkForwardAccuracy - rec.DeployedAccuracy; gain < g.cfg.MinImprovement {
+		return fmt.Sprintf("improvement over the deployed model %+.1f pts is below the %.1f pts minimum",
+			gain*100, g.cfg.MinImprovement*100)
+	}
+	return ""
+}
+
+func (g *ModelGate) promote(ctx context.Context) error {
+	resp, err := g.trainer.PromoteModel(ctx)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("promotion refused: %s", resp.Reason)
+	}
+	return nil
+}
+
+// Promote force-promotes the most recently staged candidate on an admin's
+// say-so, bypassing the gate. Returns the run that staged it.
+func (g *ModelGate) Promote(ctx context.Context, by string) (*ModelRunRecord, error) {
+	rec, err := g.store.LatestStagedModelRun(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load staged model run: %w", err)
+	}
+	if rec == nil || rec.Outcome == ModelRunPromoted {
+		return nil, fmt.Errorf("no staged candidate model to promote")
+	}
+	if err := g.promote(ctx); err != nil {
+		return nil, err
+	}
+	if err := g.store.MarkModelRunPromoted(ctx, rec.ID, by); err != nil {
+		slog.Error("model promoted but failed to record it", "run_id", rec.ID, "error", err)
+	}
+	slog.Info("staged model candidate promoted manually", "run_id", rec.ID, "by", by)
+	rec.Outcome, rec.PromotedBy = ModelRunPromoted, by
+	return rec, nil
+}
+
+func (g *ModelGate) send(text string) {
+	if g.notify != nil {
+		g.notify(text)
+	}
+}
+
+// Summary describes the run for an admin notification.
+func (r *ModelRunRecord) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧠 ML drift on %s %s — %s\n", r.Symbol, r.Timeframe, strings.ReplaceAll(r.DriftReason, "_", " "))
+	if len(r.PooledWith) > 0 {
+		fmt.Fprintf(&b, "Retrained on pooled candles with %s\n", strings.Join(r.PooledWith, ", "))
+	}
+	if r.WalkForwardFolds > 0 {
+		fmt.Fprintf(&b, "Walk-forward: candidate %.1f%% vs deployed %.1f%% direction accuracy over %d held-out folds\n",
+			r.WalkForwardAccuracy*100, r.DeployedAccuracy*100, r.WalkForwardFolds)
+	}
+	switch r.Outcome {
+	case ModelRunPromoted:
+		b.WriteString("✅ Candidate promoted")
+	case ModelRunRejected:
+		fmt.Fprintf(&b, "❌ Not promoted: %s", r.Reason)
+	case ModelRunSkipped:
+		fmt.Fprintf(&b, "⏸️ Retrain skipped: %s", r.Reason)
+	default:
+		fmt.Fprintf(&b, "⚠️ Retrain failed: %s", r.Reason)
+	}
+	if r.Staged && r.Outcome != ModelRunPromoted {
+		b.WriteString("\nThe candidate stays staged; /mlpromote promotes it anyway.")
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+type mockTrainer struct {
+	drift      bool
+	retrainErr error
+	candidate  float64
+	deployed   float64
+	wfAccuracy float64
+	promoted   int
+
+	trained   int // candles in the last retrain
+	validated *mlclient.CandidateValidationRequest
+}
+
+func (m *mockTrainer) CheckDrift(_ context.Context, _ *mlclient.DriftCheckRequest) (*mlclient.DriftCheckResponse, error) {
+	return &mlclient.DriftCheckResponse{DriftDetected: m.drift, Reason: "feature_drift", Recommendation: "retrain"}, nil
+}
+
+func (m *mockTrainer) Retrain(_ context.Context, req *mlclient.RetrainRequest) (*mlclient.RetrainResponse, error) {
+	if m.retrainErr != nil {
+		return nil, m.retrainErr
+	}
+	m.trained = len(req.Candles)
+	return &mlclient.RetrainResponse{
+		Success:         true,
+		Staged:          req.StageOnly,
+		NewModelMetrics: map[string]interface{}{"direction_accuracy": m.candidate},
+	}, nil
+}
+
+func (m *mockTrainer) ValidateCandidate(_ context.Context, req *mlclient.CandidateValidationRequest) (*mlclient.CandidateValidationResponse, error) {
+	m.validated = req
+	return &mlclient.CandidateValidationResponse{
+		Success:              true,
+		Folds:                make([]map[string]interface{}, req.NSplits),
+		AvgDirectionAccuracy: m.wfAccuracy,
+		AvgDeployedAccuracy:  m.deployed,
+	}, nil
+}
+
+func gateCandles(n int) []mlclient.Candle {
+	candles := make([]mlclient.Candle, n)
+	for i := range candles {
+		candles[i] = mlclient.Candle{Close: 100 + float64(i), Timestamp: int64(i) * 3600}
+	}
+	return candles
+}
+
+func (m *mockTrainer) PromoteModel(_ context.Context) (*mlclient.PromoteResponse, error) {
+	m.promoted++
+	return &mlclient.PromoteResponse{Success: true}, nil
+}
+
+type mockRunStore struct {
+	runs       []*ModelRunRecord
+	promotedBy map[int]string
+}
+
+func (m *mockRunStore) SaveModelRun(_ context.Context, rec *ModelRunRecord) error {
+	rec.ID = len(m.runs) + 1
+	m.runs = append(m.runs, rec)
+	return nil
+}
+
+func (m *mockRunStore) LatestStagedModelRun(_ context.Context) (*ModelRunRecord, error) {
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		if m.runs[i].Staged {
+			cp := *m.runs[i]
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRunStore) MarkModelRunPromoted(_ context.Context, id int, by string) error {
+	if m.promotedBy == nil {
+		m.promotedBy = make(map[int]string)
+	}
+	m.promotedBy[id] = by
+	m.runs[id-1].Outcome = ModelRunPromoted
+	return nil
+}
+
+func TestModelGateCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		trainer     *mockTrainer
+		wantOutcome string
+		wantReason  string
+		wantNotify  bool
+	}{
+		{"no drift", &mockTrainer{}, ModelRunNoDrift, "", false},
+		{"passes gate", &mockTrainer{drift: true, candidate: 0.58, deployed: 0.53, wfAccuracy: 0.56}, ModelRunPromoted, "", true},
+		{"low walk-forward accuracy", &mockTrainer{drift: true, candidate: 0.58, deployed: 0.53, wfAccuracy: 0.49}, ModelRunRejected, "walk-forward accuracy 49.0%", true},
+		// the retrain split looks better, but on held-out folds the gain is half a point
+		{"no improvement", &mockTrainer{drift: true, candidate: 0.6, deployed: 0.53, wfAccuracy: 0.535}, ModelRunRejected, "improvement over the deployed model +0.5 pts", true},
+		{"retrain error", &mockTrainer{drift: true, retrainErr: errors.New("ml down")}, ModelRunFailed, "ml down", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockRunStore{}
+			g := NewModelGate(tt.trainer, store, DefaultGateConfig())
+			var notes []string
+			g.SetNotifier(func(text string) { notes = append(notes, text) })
+
+			rec, err := g.Check(context.Background(), "BTC/USDT", "4h", gateCandles(g.MinCandles()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Outcome != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q (reason %q)", rec.Outcome, tt.wantOutcome, rec.Reason)
+			}
+			if !strings.Contains(rec.Reason, tt.wantReason) {
+				t.Errorf("reason = %q, want it to contain %q", rec.Reason, tt.wantReason)
+			}
+			if len(store.runs) != 1 {
+				t.Errorf("expected the run to be recorded, got %d runs", len(store.runs))
+			}
+			wantPromoted := 0
+			if tt.wantOutcome == ModelRunPromoted {
+				wantPromoted = 1
+			}
+			if tt.trainer.promoted != wantPromoted {
+				t.Errorf("promote calls = %d, want %d", tt.trainer.promoted, wantPromoted)
+			}
+			if (len(notes) > 0) != tt.wantNotify {
+				t.Errorf("notified = %v, want %v", len(notes) > 0, tt.wantNotify)
+			}
+			if tt.wantOutcome == ModelRunRejected && !strings.Contains(notes[0], "/mlpromote") {
+				t.Errorf("rejection notice should mention /mlpromote, got:\n%s", notes[0])
+			}
+		})
+	}
+}
+
+func TestModelGatePromote(t *testing.T) {
+	trainer := &mockTrainer{drift: true, candidate: 0.58, deployed: 0.53, wfAccuracy: 0.45}
+	store := &mockRunStore{}
+	g := NewModelGate(trainer, store, DefaultGateConfig())
+
+	if _, err := g.Promote(context.Background(), "admin"); err == nil {
+		t.Fatal("expected an error with nothing staged")
+	}
+
+	if _, err := g.Check(context.Background(), "BTC/USDT", "4h", gateCandles(g.MinCandles())); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := g.Promote(context.Background(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trainer.promoted != 1 || rec.Outcome != ModelRunPromoted || store.promotedBy[rec.ID] != "admin" {
+		t.Errorf("expected a recorded manual promotion, got promote calls %d, run %+v, marks %v", trainer.promoted, rec, store.promotedBy)
+	}
+
+	if _, err := g.Promote(context.Background(), "admin"); err == nil {
+		t.Error("a promoted candidate shouldn't be promoted twice")
+	}
+}
+
+func TestModelGateHoldsOutValidationCandles(t *testing.T) {
+	trainer := &mockTrainer{drift: true, candidate: 0.58, deployed: 0.53, wfAccuracy: 0.56}
+	g := NewModelGate(trainer, &mockRunStore{}, DefaultGateConfig())
+	if g.MinCandles() != 300 {
+		t.Fatalf("min candles = %d, want 200 to train on plus 5 folds of 20", g.MinCandles())
+	}
+
+	if _, err := g.Check(context.Background(), "BTC/USDT", "4h", gateCandles(400)); err != nil {
+		t.Fatal(err)
+	}
+	if trainer.trained != 300 {
+		t.Errorf("retrained on %d candles, want the 300 before the held-out ones", trainer.trained)
+	}
+	if v := trainer.validated; v == nil || len(v.Candles) != 400 || v.Holdout != 100 || v.NSplits != 5 {
+		t.Errorf("unexpected validation request %+v", v)
+	}
+
+	trainer.trained = 0
+	rec, err := g.Check(context.Background(), "BTC/USDT", "4h", gateCandles(299))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Outcome != ModelRunSkipped || trainer.trained != 0 {
+		t.Errorf("expected a skipped run without a retrain, got %q (%s)", rec.Outcome, rec.Reason)
+	}
+}
@@ -5,7 +5,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
-	"sync"
+	"log/slog"
 	"time"
 
 	"github.com/trading-bot/go-bot/internal/analysis"
@@ -13,6 +13,7 @@ import (
 	"github.com/trading-bot/go-bot/internal/exchange"
 	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
 	"github.com/trading-bot/go-bot/internal/regime"
+	"github.com/trading-bot/go-bot/internal/structure"
 )
 
 // provides market data
@@ -33,6 +34,21 @@ type MLProvider interface {
 	IsAvailable(ctx context.Context) bool
 }
 
+// provides weighted ensemble predictions from python
+type EnsembleProvider interface {
+	EnsemblePredict(ctx context.Context, req *mlclient.EnsemblePredictionRequest) (*mlclient.EnsemblePredictionResponse, error)
+}
+
+// provides chart pattern detection from python
+type PatternProvider interface {
+	DetectPatterns(ctx context.Context, req *mlclient.PatternDetectRequest) (*mlclient.PatternDetectResponse, error)
+}
+
+// provides the reinforcement-learning agent's action from python
+type RLProvider interface {
+	GetRLAction(ctx context.Context, req *mlclient.RLActionRequest) (*mlclient.RLActionResponse, error)
+}
+
 // provides ai decisions from claude
 type AIProvider interface {
 	Analyze(ctx context.Context, input *claude.AnalysisInput) (*claude.Decision, error)
@@ -43,6 +59,11 @@ type AltDataProvider interface {
 	Fetch(ctx context.Context, symbol string) *claude.AltData
 }
 
+// provides scheduled macro releases and token unlocks near a symbol
+type EventProvider interface {
+	UpcomingEvents(symbol string) []claude.CalendarEvent
+}
+
 // provides recent trade outcomes for self-learning feedback
 type TradeHistoryProvider interface {
 	RecentOutcomes(ctx context.Context, limit int) ([]claude.TradeOutcome, error)
@@ -54,28 +75,50 @@ type Result struct {
 	Ticker     *exchange.Ticker
 	Indicators *analysis.AnalysisResult
 	Prediction *mlclient.PricePredictionResponse
+	Ensemble   *mlclient.EnsemblePredictionResponse // set when the prediction came from the ensemble
 	Sentiment  *mlclient.SentimentResponse
+	Patterns   *mlclient.PatternDetectResponse
+	RL         *mlclient.RLActionResponse // advisory second opinion
+	PreFilter  *PreFilterScore            // set when the pre-filter scored the setup
 	AltData    *claude.AltData
+	Structure  *claude.MarketStructure
+	Input      *claude.AnalysisInput    // what claude was (or would be) asked; nil when pre-filtered
+	Portfolio  *claude.PortfolioContext // the user's positions the decision accounted for, if any
 	Decision   *claude.Decision
+	Shared     bool // decision made for another user's analysis; its ai usage was counted there
 	Latency    time.Duration
+	Stages     []StageReport // per-stage latency and errors, in registration order
 	Errors     []string
 }
 
 // orchestrates the analysis pipeline
 type Pipeline struct {
-	exchange     ExchangeProvider
-	indicators   IndicatorProvider
-	ml           MLProvider
-	ai           AIProvider
-	altData      AltDataProvider
-	tradeHistory TradeHistoryProvider
-	timeframe    string
-	timeframes   []string // for multi-timeframe analysis
+	exchange      ExchangeProvider
+	indicators    IndicatorProvider
+	ml            MLProvider
+	ensemble      EnsembleProvider
+	predictions   *PredictionTracker
+	patterns      PatternProvider
+	rl            RLProvider
+	ai            AIProvider
+	altData       AltDataProvider
+	marketContext *MarketContextProvider
+	events        EventProvider
+	tradeHistory  TradeHistoryProvider
+	state         *IndicatorState
+	preFilter     *PreFilter
+	stages        []Stage
+	timeframe     string
+	timeframes    []string // for multi-timeframe analysis
 }
 
-// creates a new pipeline with all service clients
+// creates a new pipeline with all service clients.
+// a nil indicator provider falls back to the native go engine.
 func New(ex ExchangeProvider, ind IndicatorProvider, ml MLProvider, ai AIProvider) *Pipeline {
-	return &Pipeline{
+	if ind == nil {
+		ind = analysis.NewLocal()
+	}
+	p := &Pipeline{
 		exchange:   ex,
 		indicators: ind,
 		ml:         ml,
@@ -83,6 +126,36 @@ func New(ex ExchangeProvider, ind IndicatorProvider, ml MLProvider, ai AIProvide
 		timeframe:  "4h",
 		timeframes: []string{"4h"},
 	}
+	for _, s := range p.defaultStages() {
+		if err := p.AddStage(s); err != nil {
+			panic(err) // the built-in stages are static
+		}
+	}
+	return p
+}
+
+// SetEnsemble makes the pipeline prefer ensemble predictions, falling back to
+// the single model when the ensemble errors or has no fitted models.
+func (p *Pipeline) SetEnsemble(provider EnsembleProvider) {
+	p.ensemble = provider
+}
+
+// SetPredictionTracker records ensemble predictions so each model's
+// realized accuracy can be measured.
+func (p *Pipeline) SetPredictionTracker(tracker *PredictionTracker) {
+	p.predictions = tracker
+}
+
+// SetPatterns configures chart pattern detection. Detection runs alongside
+// prediction and sentiment and is skipped when it fails or times out.
+func (p *Pipeline) SetPatterns(provider PatternProvider) {
+	p.patterns = provider
+}
+
+// SetRL adds the RL agent's action to the analysis as an advisory second
+// opinion. Like pattern detection it's skipped when it fails or times out.
+func (p *Pipeline) SetRL(provider RLProvider) {
+	p.rl = provider
 }
 
 // SetAltData configures the alternative data provider.
@@ -90,11 +163,29 @@ func (p *Pipeline) SetAltData(provider AltDataProvider) {
 	p.altData = provider
 }
 
+// SetMarketContext adds BTC, ETH and total-market context to analyses of
+// every non-BTC symbol.
+func (p *Pipeline) SetMarketContext(provider *MarketContextProvider) {
+	p.marketContext = provider
+}
+
+// SetEvents adds upcoming calendar events to claude's input.
+func (p *Pipeline) SetEvents(provider EventProvider) {
+	p.events = provider
+}
+
 // SetTradeHistory configures the trade history provider for self-learning.
 func (p *Pipeline) SetTradeHistory(provider TradeHistoryProvider) {
 	p.tradeHistory = provider
 }
 
+// SetIndicatorState lets the pipeline read indicators from streaming state
+// when it has caught up with the latest closed candle, instead of
+// recomputing them on every request.
+func (p *Pipeline) SetIndicatorState(state *IndicatorState) {
+	p.state = state
+}
+
 // SetTimeframes configures multi-timeframe analysis.
 // The first timeframe is the primary decision timeframe.
 func (p *Pipeline) SetTimeframes(timeframes []string) {
@@ -105,8 +196,21 @@ func (p *Pipeline) SetTimeframes(timeframes []string) {
 	p.timeframe = timeframes[0]
 }
 
-// runs the full analysis pipeline for a symbol
+// runs the full analysis pipeline for a symbol: fetches market data, runs
+// every stage, then asks claude unless the pre-filter skips the setup
 func (p *Pipeline) Analyze(ctx context.Context, symbol string) (*Result, error) {
+	market, err := p.Prepare(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return p.Decide(ctx, market, nil, nil)
+}
+
+// Prepare runs the market-data part of the analysis, which is the same for
+// every user: fetches market data, runs the stages, applies the pre-filter
+// and builds claude's input. The decision is only set when the pre-filter
+// skipped the setup.
+func (p *Pipeline) Prepare(ctx context.Context, symbol string) (*Result, error) {
 	start := time.Now()
 	result := &Result{Symbol: symbol}
 
@@ -117,114 +221,77 @@ func (p *Pipeline) Analyze(ctx context.Context, symbol string) (*Result, error)
 	}
 	result.Ticker = ticker
 
-	// step 2+3: run rust indicators and python ml in parallel
-	analysisCandles := exchangeToAnalysisCandles(candles)
-	mlCandles := exchangeToMLCandles(candles)
-
-	var (
-		indicators *analysis.AnalysisResult
-		prediction *mlclient.PricePredictionResponse
-		sentiment  *mlclient.SentimentResponse
-		altData    *claude.AltData
-		htfCtx     []claude.HTFSnapshot
-		indErr     error
-		predErr    error
-		sentErr    error
-		wg         sync.WaitGroup
-	)
-
-	wg.Add(5)
-
-	// rust indicators
-	go func() {
-		defer wg.Done()
-		indicators, indErr = p.indicators.AnalyzeAll(ctx, analysisCandles, nil)
-	}()
-
-	// ml price prediction
-	go func() {
-		defer wg.Done()
-		if p.ml != nil && p.ml.IsAvailable(ctx) {
-			prediction, predErr = p.ml.PredictPrice(ctx, &mlclient.PricePredictionRequest{
-				Symbol:    symbol,
-				Candles:   mlCandles,
-				Timeframe: p.timeframe,
-			})
-		}
-	}()
-
-	// ml sentiment
-	go func() {
-		defer wg.Done()
-		if p.ml != nil && p.ml.IsAvailable(ctx) {
-			sentimentText := fmt.Sprintf("%s price %.2f 24h change %.2f%%", symbol, ticker.Price, ticker.ChangePct)
-			sentiment, sentErr = p.ml.AnalyzeSentiment(ctx, sentimentText)
-		}
-	}()
-
-	// alternative data (order flow, on-chain, sentiment, funding)
-	go func() {
-		defer wg.Done()
-		if p.altData != nil {
-			altData = p.altData.Fetch(ctx, symbol)
-		}
-	}()
-
-	// higher-timeframe context (skip primary timeframe, fetch others)
-	go func() {
-		defer wg.Done()
-		if len(p.timeframes) > 1 {
-			htfCtx = p.fetchHTFContext(ctx, symbol)
-		}
-	}()
-
-	wg.Wait()
-
-	// collect results, log errors but continue
-	if indErr != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("indicators: %v", indErr))
-	} else {
-		result.Indicators = indicators
+	// step 2: run the stages, concurrently where their inputs allow
+	sc := newStageContext(symbol, p.timeframe, ticker, candles)
+	result.Stages, err = runStages(ctx, p.stages, sc)
+	if err != nil {
+		return nil, err
 	}
-
-	if predErr != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("prediction: %v", predErr))
-	} else {
-		result.Prediction = prediction
+	// failed optional stages are recorded, the analysis continues without them
+	for _, r := range result.Stages {
+		if r.Err != "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", r.Name, r.Err))
+		}
 	}
-
-	if sentErr != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("sentiment: %v", sentErr))
-	} else {
-		result.Sentiment = sentiment
+	result.Indicators = stageOutput[*analysis.AnalysisResult](sc, OutputIndicators)
+	result.Prediction = stageOutput[*mlclient.PricePredictionResponse](sc, OutputPrediction)
+	result.Ensemble = stageOutput[*mlclient.EnsemblePredictionResponse](sc, OutputEnsemble)
+	result.Sentiment = stageOutput[*mlclient.SentimentResponse](sc, OutputSentiment)
+	result.Patterns = stageOutput[*mlclient.PatternDetectResponse](sc, OutputPatterns)
+	result.RL = stageOutput[*mlclient.RLActionResponse](sc, OutputRL)
+	result.AltData = stageOutput[*claude.AltData](sc, OutputAltData)
+	result.Structure = stageOutput[*claude.MarketStructure](sc, OutputStructure)
+
+	// step 3: skip claude when the setup is too weak to act on
+	if p.preFilter != nil {
+		result.PreFilter = p.preFilter.check(ctx, symbol, p.timeframe, ticker.Price, result.Indicators, result.AltData)
+		if result.PreFilter.Skipped {
+			result.Decision = result.PreFilter.holdDecision(p.preFilter.clock.Now())
+			result.Latency = time.Since(start)
+			return result, nil
+		}
 	}
 
-	result.AltData = altData
+	// step 4: collect every stage's contribution for claude
+	result.Input = newAIInput(sc, p.stages)
+	result.Latency = time.Since(start)
 
-	// step 4: feed everything to claude
-	aiInput := buildAIInput(symbol, ticker, candles, indicators, prediction, sentiment, altData)
-	aiInput.HTFContext = htfCtx
+	return result, nil
+}
 
-	// self-learning: feed recent trade outcomes
-	if p.tradeHistory != nil {
-		outcomes, histErr := p.tradeHistory.RecentOutcomes(ctx, 10)
-		if histErr != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("trade_history: %v", histErr))
-		} else if len(outcomes) > 0 {
-			aiInput.TradeHistory = outcomes
-		}
+// Decide asks claude about a prepared analysis, with the user's portfolio
+// and a prompt variant when given. The prepared result may be shared and
+// isn't modified; a pre-filtered one is returned as is.
+func (p *Pipeline) Decide(ctx context.Context, market *Result, portfolio *claude.PortfolioContext, prompt *claude.PromptVariant) (*Result, error) {
+	if market.Decision != nil || market.Input == nil {
+		return market, nil
 	}
+	start := time.Now()
 
-	decision, err := p.ai.Analyze(ctx, aiInput)
+	input := *market.Input
+	input.Portfolio = portfolio
+	input.Prompt = prompt
+	decision, err := p.ai.Analyze(ctx, &input)
 	if err != nil {
 		return nil, fmt.Errorf("ai analysis failed: %w", err)
 	}
-	result.Decision = decision
-	result.Latency = time.Since(start)
 
-	return result, nil
+	result := *market
+	result.Input = &input
+	result.Portfolio = portfolio
+	result.Decision = decision
+	result.Latency = market.Latency + time.Since(start)
+	return &result, nil
 }
 
+// candles each analysis reads: the primary window, the higher-timeframe
+// window, and the fewest higher-timeframe candles worth a snapshot
+const (
+	primaryCandles = 100
+	htfCandles     = 50
+	minHTFCandles  = 28
+)
+
 // fetches ticker and candles from the exchange
 func (p *Pipeline) fetchMarketData(ctx context.Context, symbol string) (*exchange.Ticker, []exchange.Candle, error) {
 	ticker, err := p.exchange.GetPrice(ctx, symbol)
@@ -232,7 +299,7 @@ func (p *Pipeline) fetchMarketData(ctx context.Context, symbol string) (*exchang
 		return nil, nil, fmt.Errorf("price fetch failed: %w", err)
 	}
 
-	candles, err := p.exchange.GetCandles(ctx, symbol, p.timeframe, 100)
+	candles, err := p.exchange.GetCandles(ctx, symbol, p.timeframe, primaryCandles)
 	if err != nil {
 		return nil, nil, fmt.Errorf("candle fetch failed: %w", err)
 	}
@@ -240,49 +307,192 @@ func (p *Pipeline) fetchMarketData(ctx context.Context, symbol string) (*exchang
 	return ticker, candles, nil
 }
 
+// predict asks the ensemble first and falls back to the single model
+func (p *Pipeline) predict(ctx context.Context, symbol string, candles []mlclient.Candle) (*mlclient.PricePredictionResponse, *mlclient.EnsemblePredictionResponse, error) {
+	if p.ensemble != nil {
+		resp, err := p.ensemble.EnsemblePredict(ctx, &mlclient.EnsemblePredictionRequest{
+			Symbol:    symbol,
+			Candles:   candles,
+			Timeframe: p.timeframe,
+		})
+		switch {
+		case err != nil:
+			slog.Debug("ensemble prediction failed, using single model", "symbol", symbol, "error", err)
+		case resp.ModelCount == 0:
+			slog.Debug("ensemble has no fitted models, using single model", "symbol", symbol)
+		default:
+			if p.predictions != nil {
+				p.predictions.Record(ctx, symbol, resp)
+			}
+			return resp.AsPrediction(), resp, nil
+		}
+	}
+	pred, err := p.ml.PredictPrice(ctx, &mlclient.PricePredictionRequest{
+		Symbol:    symbol,
+		Candles:   candles,
+		Timeframe: p.timeframe,
+	})
+	return pred, nil, err
+}
+
+// addEnsembleVotes attaches per-model votes, agreement and dispersion
+func addEnsembleVotes(pred *claude.MLPrediction, resp *mlclient.EnsemblePredictionResponse) {
+	for _, v := range resp.Votes() {
+		pred.Models = append(pred.Models, claude.ModelOutput{
+			Model:      v.Model,
+			Direction:  v.Direction,
+			Magnitude:  v.Magnitude,
+			Confidence: v.Confidence,
+			Weight:     v.Weight,
+		})
+	}
+	pred.Agreement = resp.Agreement()
+	pred.Dispersion = resp.Dispersion()
+}
+
+// the ml service rejects shorter series
+const minPatternCandles = 20
+
 // fetchHTFContext fetches candles for higher timeframes and runs indicators
 // to provide multi-timeframe confirmation signals
 func (p *Pipeline) fetchHTFContext(ctx context.Context, symbol string) []claude.HTFSnapshot {
 	var snapshots []claude.HTFSnapshot
 	for _, tf := range p.timeframes[1:] {
-		candles, err := p.exchange.GetCandles(ctx, symbol, tf, 50)
-		if err != nil || len(candles) < 28 {
+		candles, err := p.exchange.GetCandles(ctx, symbol, tf, htfCandles)
+		if err != nil || len(candles) < minHTFCandles {
 			continue
 		}
-		ac := exchangeToAnalysisCandles(candles)
-		ind, err := p.indicators.AnalyzeAll(ctx, ac, nil)
+		ind, err := p.analyzeIndicators(ctx, symbol, tf, candles, exchangeToAnalysisCandles(candles))
 		if err != nil {
 			continue
 		}
-		snap := claude.HTFSnapshot{Timeframe: tf}
-		if ind.RSI != nil {
-			snap.RSI = ind.RSI.Value
+		snapshots = append(snapshots, htfSnapshot(tf, candles, ind))
+	}
+	return snapshots
+}
+
+// analyzeIndicators serves from streaming state when it's current, otherwise
+// computes from the fetched candles. With streaming state the batch fallback
+// reads the same closed, warm-up deep history the stream would, so a symbol
+// doesn't flip between forming-candle and closed-candle readings; without it
+// the fetched window, forming candle included, is analyzed as before.
+func (p *Pipeline) analyzeIndicators(ctx context.Context, symbol, interval string, candles []exchange.Candle, ac []analysis.Candle) (*analysis.AnalysisResult, error) {
+	if p.state != nil {
+		if _, err := p.state.Ingest(ctx, symbol, interval, candles); err != nil {
+			slog.Debug("indicator state ingest failed", "symbol", symbol, "interval", interval, "error", err)
+		}
+		if ind, ok := p.state.Latest(symbol, interval); ok {
+			return ind, nil
 		}
-		if ind.MACD != nil {
-			snap.MACDHist = ind.MACD.Histogram
+		if history := p.state.batchHistory(ctx, symbol, interval, candles); len(history) > 0 {
+			ac = exchangeToAnalysisCandles(history)
 		}
-		if ind.Bollinger != nil && ind.Bollinger.Upper > ind.Bollinger.Lower {
-			lastClose := candles[len(candles)-1].Close
-			snap.BBPosition = (lastClose - ind.Bollinger.Lower) / (ind.Bollinger.Upper - ind.Bollinger.Lower)
+	}
+	return p.indicators.AnalyzeAll(ctx, ac, nil)
+}
+
+// htfSnapshot summarizes higher-timeframe indicators into a confirmation snapshot
+func htfSnapshot(tf string, candles []exchange.Candle, ind *analysis.AnalysisResult) claude.HTFSnapshot {
+	snap := claude.HTFSnapshot{Timeframe: tf}
+	if ind.RSI != nil {
+		snap.RSI = ind.RSI.Value
+	}
+	if ind.MACD != nil {
+		snap.MACDHist = ind.MACD.Histogram
+	}
+	if ind.Bollinger != nil && ind.Bollinger.Upper > ind.Bollinger.Lower {
+		lastClose := candles[len(candles)-1].Close
+		snap.BBPosition = (lastClose - ind.Bollinger.Lower) / (ind.Bollinger.Upper - ind.Bollinger.Lower)
+	}
+	if ind.EMA != nil && len(candles) >= 2 {
+		prevClose := candles[len(candles)-2].Close
+		currClose := candles[len(candles)-1].Close
+		snap.EMASlope = (currClose - prevClose) / prevClose * 100
+	}
+	// determine trend direction from indicators
+	if ind.MACD != nil && ind.EMA != nil {
+		if ind.MACD.Histogram > 0 && snap.EMASlope > 0 {
+			snap.TrendDir = "up"
+		} else if ind.MACD.Histogram < 0 && snap.EMASlope < 0 {
+			snap.TrendDir = "down"
+		} else {
+			snap.TrendDir = "neutral"
 		}
-		if ind.EMA != nil && len(candles) >= 2 {
-			prevClose := candles[len(candles)-2].Close
-			currClose := candles[len(candles)-1].Close
-			snap.EMASlope = (currClose - prevClose) / prevClose * 100
+	}
+	return snap
+}
+
+// structureLevels is how many supports and resistances are passed on
+const structureLevels = 3
+
+// buildStructure reads swing structure from the candles and liquidity zones
+// from order book walls, keeping the nearest levels either side of price
+func buildStructure(candles []exchange.Candle, price float64, alt *claude.AltData) *claude.MarketStructure {
+	if len(candles) == 0 {
+		return nil
+	}
+	sc := make([]structure.Candle, len(candles))
+	for i, c := range candles {
+		sc[i] = structure.Candle{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume}
+	}
+	var walls []structure.Wall
+	if alt != nil && alt.OrderFlow != nil {
+		for _, w := range alt.OrderFlow.Walls {
+			walls = append(walls, structure.Wall{Side: w.Side, Price: w.Price, SizeUSD: w.SizeUSD})
 		}
-		// determine trend direction from indicators
-		if ind.MACD != nil && ind.EMA != nil {
-			if ind.MACD.Histogram > 0 && snap.EMASlope > 0 {
-				snap.TrendDir = "up"
-			} else if ind.MACD.Histogram < 0 && snap.EMASlope < 0 {
-				snap.TrendDir = "down"
-			} else {
-				snap.TrendDir = "neutral"
-			}
+	}
+
+	a := structure.Analyze(sc, price, walls)
+	if price <= 0 {
+		price = candles[len(candles)-1].Close
+	}
+	ms := &claude.MarketStructure{Trend: a.Trend}
+	if a.LastEvent != nil {
+		ms.LastEvent = &claude.StructureEvent{Type: a.LastEvent.Type, Direction: a.LastEvent.Direction, Price: a.LastEvent.Price}
+	}
+	supports, resistances := a.Nearest(price, structureLevels)
+	ms.Supports = toPriceLevels(supports)
+	ms.Resistances = toPriceLevels(resistances)
+	if a.SupportLine != nil && !a.SupportLine.Broken && a.SupportLine.Value > 0 {
+		ms.SupportTrendline = a.SupportLine.Value
+	}
+	if a.ResistanceLine != nil && !a.ResistanceLine.Broken && a.ResistanceLine.Value > 0 {
+		ms.ResistanceTrendline = a.ResistanceLine.Value
+	}
+	return ms
+}
+
+// toChartPatterns keeps the fields claude needs from the ml service's pattern dicts
+func toChartPatterns(resp *mlclient.PatternDetectResponse) *claude.ChartPatterns {
+	if resp == nil || len(resp.Patterns) == 0 {
+		return nil
+	}
+	out := &claude.ChartPatterns{
+		Signal:   resp.Signal,
+		Strength: resp.SignalStrength,
+		Summary:  resp.Summary,
+	}
+	for _, raw := range resp.Patterns {
+		pat := claude.ChartPattern{}
+		pat.Name, _ = raw["name"].(string)
+		pat.Direction, _ = raw["direction"].(string)
+		pat.Confidence, _ = raw["confidence"].(float64)
+		pat.Target, _ = raw["target"].(float64)
+		pat.Neckline, _ = raw["neckline"].(float64)
+		if pat.Name == "" {
+			continue
 		}
-		snapshots = append(snapshots, snap)
+		out.Patterns = append(out.Patterns, pat)
 	}
-	return snapshots
+	return out
+}
+
+func toPriceLevels(levels []structure.NearLevel) []claude.PriceLevel {
+	out := make([]claude.PriceLevel, len(levels))
+	for i, l := range levels {
+		out[i] = claude.PriceLevel{Price: l.Price, Touches: l.Touches, SizeUSD: l.SizeUSD, Source: l.Source}
+	}
+	return out
 }
 
 // converts exchange candles to analysis candles for the rust engine
@@ -332,87 +542,145 @@ func exchangeToRegimeCandles(candles []exchange.Candle) []regime.Candle {
 	return result
 }
 
-// assembles the ai input from all available analysis data
-func buildAIInput(
-	symbol string,
-	ticker *exchange.Ticker,
-	candles []exchange.Candle,
-	indicators *analysis.AnalysisResult,
-	prediction *mlclient.PricePredictionResponse,
-	sentiment *mlclient.SentimentResponse,
-	altData *claude.AltData,
-) *claude.AnalysisInput {
+// newAIInput assembles claude's input from the market data and the
+// contribution of every stage, in registration order
+func newAIInput(sc *StageContext, stages []Stage) *claude.AnalysisInput {
 	input := &claude.AnalysisInput{
 		Market: claude.MarketData{
-			Symbol:    symbol,
-			Price:     ticker.Price,
-			Volume24h: ticker.QuoteVolume,
-			Change24h: ticker.ChangePct,
+			Symbol:    sc.Symbol,
+			Price:     sc.Ticker.Price,
+			Volume24h: sc.Ticker.QuoteVolume,
+			Change24h: sc.Ticker.ChangePct,
 		},
+		Costs: claude.DefaultTradingCosts(),
 	}
-
-	if indicators != nil {
-		ind := &claude.Indicators{}
-		if indicators.RSI != nil {
-			ind.RSI = indicators.RSI.Value
-		}
-		if indicators.MACD != nil {
-			ind.MACDValue = indicators.MACD.MACDLine
-			ind.MACDSignal = indicators.MACD.SignalLine
-			ind.MACDHist = indicators.MACD.Histogram
-		}
-		if indicators.Bollinger != nil {
-			ind.BBUpper = indicators.Bollinger.Upper
-			ind.BBMiddle = indicators.Bollinger.Middle
-			ind.BBLower = indicators.Bollinger.Lower
+	for _, s := range stages {
+		if s.Prompt != nil {
+			s.Prompt(sc, input)
 		}
-		if indicators.EMA != nil {
-			ind.EMA12 = indicators.EMA.Value
-			ind.EMA26 = indicators.EMA.Value * 0.98 // approximation when only one ema available
-		}
-		if indicators.Volume != nil {
-			ind.VolumeSpike = indicators.Volume.IsSpike
-		}
-		if indicators.ATR != nil {
-			ind.ATR = indicators.ATR.Value
-			ind.ATRPercent = indicators.ATR.Percent
-			ind.ATRSignal = indicators.ATR.Signal
-		}
-		if indicators.ADX != nil {
-			ind.ADX = indicators.ADX.Value
-			ind.ADXSignal = indicators.ADX.Signal
-			ind.ADXTrendDir = indicators.ADX.TrendDir
-		}
-		if indicators.Stochastic != nil {
-			ind.StochK = indicators.Stochastic.K
-			ind.StochD = indicators.Stochastic.D
-			ind.StochSignal = indicators.Stochastic.Signal
-		}
-		input.Indicators = ind
 	}
+	return input
+}
 
-	if prediction != nil {
-		input.Prediction = &claude.MLPrediction{
-			Direction:  prediction.Direction,
-			Magnitude:  prediction.Magnitude,
-			Confidence: prediction.Confidence,
-			Timeframe:  prediction.Timeframe,
+// toClaudeIndicators flattens indicator results for the prompt
+func toClaudeIndicators(indicators *analysis.AnalysisResult) *claude.Indicators {
+	if indicators == nil {
+		return nil
+	}
+	ind := &claude.Indicators{}
+	if indicators.RSI != nil {
+		ind.RSI = indicators.RSI.Value
+	}
+	if indicators.MACD != nil {
+		ind.MACDValue = indicators.MACD.MACDLine
+		ind.MACDSignal = indicators.MACD.SignalLine
+		ind.MACDHist = indicators.MACD.Histogram
+	}
+	if indicators.Bollinger != nil {
+		ind.BBUpper = indicators.Bollinger.Upper
+		ind.BBMiddle = indicators.Bollinger.Middle
+		ind.BBLower = indicators.Bollinger.Lower
+	}
+	for _, e := range indicators.EMAs {
+		switch e.Period {
+		case 12:
+			ind.EMA12 = e.Value
+		case 26:
+			ind.EMA26 = e.Value
 		}
+		ind.EMAs = append(ind.EMAs, claude.EMALevel{Period: int(e.Period), Value: e.Value})
+	}
+	ind.EMAAlignment = indicators.EMAAlignment
+	if indicators.Volume != nil {
+		ind.VolumeSpike = indicators.Volume.IsSpike
+	}
+	if indicators.ATR != nil {
+		ind.ATR = indicators.ATR.Value
+		ind.ATRPercent = indicators.ATR.Percent
+		ind.ATRSignal = indicators.ATR.Signal
+	}
+	if indicators.ADX != nil {
+		ind.ADX = indicators.ADX.Value
+		ind.ADXSignal = indicators.ADX.Signal
+		ind.ADXTrendDir = indicators.ADX.TrendDir
+	}
+	if indicators.Stochastic != nil {
+		ind.StochK = indicators.Stochastic.K
+		ind.StochD = indicators.Stochastic.D
+		ind.StochSignal = indicators.Stochastic.Signal
+	}
+	if indicators.VWAP != nil {
+		ind.VWAP = indicators.VWAP.Session
+		ind.VWAPUpper = indicators.VWAP.SessionUpper
+		ind.VWAPLower = indicators.VWAP.SessionLower
+		ind.VWAPAnchored = indicators.VWAP.Anchored
+	}
+	if indicators.Ichimoku != nil {
+		ind.IchimokuTenkan = indicators.Ichimoku.Tenkan
+		ind.IchimokuKijun = indicators.Ichimoku.Kijun
+		ind.IchimokuSenkouA = indicators.Ichimoku.SenkouA
+		ind.IchimokuSenkouB = indicators.Ichimoku.SenkouB
+		ind.IchimokuSignal = indicators.Ichimoku.Signal
+		ind.IchimokuTKCross = indicators.Ichimoku.TKCross
 	}
+	if indicators.OBV != nil {
+		ind.OBVSlope = indicators.OBV.Slope
+		ind.OBVSignal = indicators.OBV.Signal
+		ind.OBVDivergence = indicators.OBV.Divergence
+	}
+	if indicators.Supertrend != nil {
+		ind.Supertrend = indicators.Supertrend.Value
+		ind.SupertrendDir = indicators.Supertrend.Direction
+		ind.SupertrendFlipped = indicators.Supertrend.Flipped
+	}
+	if indicators.Donchian != nil {
+		ind.DonchianUpper = indicators.Donchian.Upper
+		ind.DonchianLower = indicators.Donchian.Lower
+		ind.DonchianSignal = indicators.Donchian.Signal
+		ind.DonchianPeriod = indicators.Donchian.Period
+	}
+	if indicators.Pivots != nil {
+		ind.Pivot = indicators.Pivots.Classic.Pivot
+		ind.PivotR1 = indicators.Pivots.Classic.R1
+		ind.PivotR2 = indicators.Pivots.Classic.R2
+		ind.PivotS1 = indicators.Pivots.Classic.S1
+		ind.PivotS2 = indicators.Pivots.Classic.S2
+		ind.FibR1 = indicators.Pivots.Fibonacci.R1
+		ind.FibR2 = indicators.Pivots.Fibonacci.R2
+		ind.FibS1 = indicators.Pivots.Fibonacci.S1
+		ind.FibS2 = indicators.Pivots.Fibonacci.S2
+	}
+	return ind
+}
 
-	if sentiment != nil {
-		input.Sentiment = &claude.Sentiment{
-			Score:      sentiment.Score,
-			Label:      sentiment.Label,
-			Confidence: sentiment.Confidence,
-		}
+func toClaudePrediction(prediction *mlclient.PricePredictionResponse) *claude.MLPrediction {
+	if prediction == nil {
+		return nil
+	}
+	return &claude.MLPrediction{
+		Direction:  prediction.Direction,
+		Magnitude:  prediction.Magnitude,
+		Confidence: prediction.Confidence,
+		Timeframe:  prediction.Timeframe,
 	}
+}
 
-	input.Costs = claude.DefaultTradingCosts()
+func toClaudeSentiment(sentiment *mlclient.SentimentResponse) *claude.Sentiment {
+	if sentiment == nil {
+		return nil
+	}
+	return &claude.Sentiment{
+		Score:      sentiment.Score,
+		Label:      sentiment.Label,
+		Confidence: sentiment.Confidence,
+	}
+}
 
-	// use rust engine regime data when available, fall back to go-side detection
+// regimeInfo uses the indicator engine's regime when available and falls back
+// to go-side detection on the candles
+func regimeInfo(indicators *analysis.AnalysisResult, candles []exchange.Candle, price float64) *claude.RegimeInfo {
 	if indicators != nil && indicators.Regime != nil {
-		input.Regime = &claude.RegimeInfo{
+		return &claude.RegimeInfo{
 			Regime:      indicators.Regime.Regime,
 			ADX:         indicators.Regime.ADX,
 			ATRPercent:  indicators.Regime.ATRPercent,
@@ -420,20 +688,17 @@ func buildAIInput(
 			Confidence:  indicators.Regime.Confidence,
 			Description: indicators.Regime.Description,
 		}
-	} else if len(candles) >= 28 {
-		regimeCandles := exchangeToRegimeCandles(candles)
-		det := regime.Detect(regimeCandles, ticker.Price)
-		input.Regime = &claude.RegimeInfo{
-			Regime:      string(det.Regime),
-			ADX:         det.ADX,
-			ATRPercent:  det.ATRPercent,
-			TrendDir:    det.TrendDir,
-			Confidence:  det.Confidence,
-			Description: det.Description,
-		}
 	}
-
-	input.AltData = altData
-
-	return input
+	if len(candles) < 28 {
+		return nil
+	}
+	det := regime.Detect(exchangeToRegimeCandles(candles), price)
+	return &claude.RegimeInfo{
+		Regime:      string(det.Regime),
+		ADX:         det.ADX,
+		ATRPercent:  det.ATRPercent,
+		TrendDir:    det.TrendDir,
+		Confidence:  det.Confidence,
+		Description: det.Description,
+	}
 }
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -66,9 +67,11 @@ func (m *mockML) IsAvailable(_ context.Context) bool {
 type mockAI struct {
 	decision *claude.Decision
 	err      error
+	input    *claude.AnalysisInput // last input seen
 }
 
-func (m *mockAI) Analyze(_ context.Context, _ *claude.AnalysisInput) (*claude.Decision, error) {
+func (m *mockAI) Analyze(_ context.Context, input *claude.AnalysisInput) (*claude.Decision, error) {
+	m.input = input
 	return m.decision, m.err
 }
 
@@ -111,9 +114,17 @@ func testIndicators() *analysis.AnalysisResult {
 		Bollinger:     &analysis.BollingerResult{Upper: 44000, Middle: 42500, Lower: 41000, Signal: "lower_band"},
 		EMA:           &analysis.EMAResult{Value: 42300, Trend: "bullish"},
 		Volume:        &analysis.VolumeResult{IsSpike: false, CurrentVolume: 500, AverageVolume: 450, Ratio: 1.1, Signal: "normal"},
+		Supertrend:    &analysis.SupertrendResult{Value: 41700, Direction: "UP"},
+		Pivots:        &analysis.PivotResult{Classic: analysis.PivotLevels{Pivot: 42000, R1: 42800}, Position: "ABOVE_PIVOT"},
+		EMAAlignment:  "BULLISH",
 		OverallSignal: "bullish",
 		BullishCount:  3,
 		BearishCount:  1,
+		EMAs: []analysis.EMALevel{
+			{Period: 12, Value: 42350, Trend: "ABOVE"},
+			{Period: 26, Value: 42200, Trend: "ABOVE"},
+			{Period: 50, Value: 41900, Trend: "ABOVE"},
+		},
 	}
 }
 
@@ -370,13 +381,23 @@ func TestExchangeToMLCandles(t *testing.T) {
 
 // --- ai input builder tests ---
 
+// stageInput assembles claude's input from stage outputs set directly
+func stageInput(symbol string, ticker *exchange.Ticker, candles []exchange.Candle, outputs map[string]any) *claude.AnalysisInput {
+	p := New(nil, nil, nil, nil)
+	sc := newStageContext(symbol, p.timeframe, ticker, candles)
+	for k, v := range outputs {
+		sc.Set(k, v)
+	}
+	return newAIInput(sc, p.stages)
+}
+
 func TestBuildAIInputFull(t *testing.T) {
 	ticker := testTicker()
 	ind := testIndicators()
 	pred := testPrediction()
 	sent := testSentiment()
 
-	input := buildAIInput("BTC/USDT", ticker, nil, ind, pred, sent, nil)
+	input := stageInput("BTC/USDT", ticker, nil, map[string]any{OutputIndicators: ind, OutputPrediction: pred, OutputSentiment: sent})
 
 	if input.Market.Symbol != "BTC/USDT" {
 		t.Errorf("expected symbol BTC/USDT, got %s", input.Market.Symbol)
@@ -390,6 +411,16 @@ func TestBuildAIInputFull(t *testing.T) {
 	if input.Indicators.RSI != 32.5 {
 		t.Errorf("expected rsi 32.5, got %.1f", input.Indicators.RSI)
 	}
+	// ema 12/26 come from the multi-period set, not the single ema
+	if input.Indicators.EMA12 != 42350 || input.Indicators.EMA26 != 42200 {
+		t.Errorf("expected ema12/26 42350/42200, got %.0f/%.0f", input.Indicators.EMA12, input.Indicators.EMA26)
+	}
+	if len(input.Indicators.EMAs) != 3 || input.Indicators.EMAAlignment != "BULLISH" {
+		t.Errorf("expected 3 emas with bullish alignment, got %+v %s", input.Indicators.EMAs, input.Indicators.EMAAlignment)
+	}
+	if input.Indicators.SupertrendDir != "UP" || input.Indicators.Pivot != 42000 || input.Indicators.PivotR1 != 42800 {
+		t.Errorf("structure indicators not mapped: %+v", input.Indicators)
+	}
 	if input.Prediction == nil {
 		t.Fatal("expected prediction")
 	}
@@ -406,7 +437,7 @@ func TestBuildAIInputFull(t *testing.T) {
 
 func TestBuildAIInputMinimal(t *testing.T) {
 	ticker := testTicker()
-	input := buildAIInput("ETH/USDT", ticker, nil, nil, nil, nil, nil)
+	input := stageInput("ETH/USDT", ticker, nil, nil)
 
 	if input.Market.Symbol != "ETH/USDT" {
 		t.Errorf("expected symbol ETH/USDT, got %s", input.Market.Symbol)
@@ -776,7 +807,7 @@ func TestBuildAIInputWithAltData(t *testing.T) {
 	alt := &claude.AltData{
 		OrderFlow: &claude.OrderFlowData{BuySellRatio: 1.5},
 	}
-	input := buildAIInput("BTC/USDT", ticker, nil, nil, nil, nil, alt)
+	input := stageInput("BTC/USDT", ticker, nil, map[string]any{OutputAltData: alt})
 	if input.AltData == nil {
 		t.Fatal("expected alt data")
 	}
@@ -788,7 +819,7 @@ func TestBuildAIInputWithAltData(t *testing.T) {
 func TestBuildAIInputWithRegime(t *testing.T) {
 	ticker := testTicker()
 	candles := testCandles(50)
-	input := buildAIInput("BTC/USDT", ticker, candles, testIndicators(), nil, nil, nil)
+	input := stageInput("BTC/USDT", ticker, candles, map[string]any{OutputIndicators: testIndicators()})
 	if input.Regime == nil {
 		t.Fatal("expected regime detection with 50 candles")
 	}
@@ -796,3 +827,219 @@ func TestBuildAIInputWithRegime(t *testing.T) {
 		t.Error("expected non-empty regime")
 	}
 }
+
+func TestBuildStructureUsesWalls(t *testing.T) {
+	alt := &claude.AltData{OrderFlow: &claude.OrderFlowData{Walls: []claude.OrderBookWall{
+		{Side: "bid", Price: 42000, SizeUSD: 1.5e6},
+		{Side: "ask", Price: 43500, SizeUSD: 2e6},
+	}}}
+	ms := buildStructure(testCandles(100), 42500, alt)
+	if ms == nil {
+		t.Fatal("expected a structure read")
+	}
+	var wallSupport, wallResistance bool
+	for _, l := range ms.Supports {
+		wallSupport = wallSupport || (l.Price == 42000 && l.Source == "liquidity")
+	}
+	for _, l := range ms.Resistances {
+		wallResistance = wallResistance || (l.Price == 43500 && l.Source == "liquidity")
+	}
+	if !wallSupport || !wallResistance {
+		t.Errorf("expected walls among the nearest levels, got S %+v R %+v", ms.Supports, ms.Resistances)
+	}
+	if buildStructure(nil, 42500, alt) != nil {
+		t.Error("expected nil without candles")
+	}
+}
+
+func TestFormatTelegramLevelWarnings(t *testing.T) {
+	result := &Result{
+		Symbol:   "BTC/USDT",
+		Ticker:   testTicker(),
+		Decision: testDecision(),
+		Structure: &claude.MarketStructure{
+			Supports:    []claude.PriceLevel{{Price: 41500, Touches: 3, Source: "swing"}},
+			Resistances: []claude.PriceLevel{{Price: 43800, SizeUSD: 2e6, Source: "liquidity"}},
+		},
+	}
+
+	msg := FormatTelegramMessage(result)
+	for _, check := range []string{"Levels:", "S $41500.00", "R $43800.00", "SL sits above support", "TP is beyond resistance"} {
+		if !strings.Contains(msg, check) {
+			t.Errorf("telegram message should contain %q, got:\n%s", check, msg)
+		}
+	}
+
+	// a plan that respects the levels has no warnings
+	result.Decision.Plan.StopLoss = 41300
+	result.Decision.Plan.TakeProfit = 43700
+	if msg := FormatTelegramMessage(result); strings.Contains(msg, "⚠️") {
+		t.Errorf("expected no warnings, got:\n%s", msg)
+	}
+
+	_, _, fields, _ := FormatDiscordFields(result)
+	var found bool
+	for _, f := range fields {
+		found = found || f.Name == "Key Levels"
+	}
+	if !found {
+		t.Error("expected a Key Levels discord field")
+	}
+}
+
+// --- mock pattern provider ---
+
+type mockPatterns struct {
+	resp  *mlclient.PatternDetectResponse
+	err   error
+	delay time.Duration
+}
+
+func (m *mockPatterns) DetectPatterns(ctx context.Context, _ *mlclient.PatternDetectRequest) (*mlclient.PatternDetectResponse, error) {
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return m.resp, m.err
+}
+
+func testPatterns() *mlclient.PatternDetectResponse {
+	return &mlclient.PatternDetectResponse{
+		Patterns: []map[string]interface{}{
+			{"name": "double_bottom", "direction": "bullish", "confidence": 0.72, "neckline": 43000.0, "target": 44500.0},
+			{"name": "bull_flag", "direction": "bullish", "confidence": 0.55},
+		},
+		PatternCount:   2,
+		Signal:         "bullish",
+		SignalStrength: 0.64,
+	}
+}
+
+func TestPipelinePatternStage(t *testing.T) {
+	ai := &mockAI{decision: testDecision()}
+	p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, nil, ai)
+	p.SetPatterns(&mockPatterns{resp: testPatterns()})
+
+	result, err := p.Analyze(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Patterns == nil || result.Patterns.PatternCount != 2 {
+		t.Fatalf("expected patterns on the result, got %+v", result.Patterns)
+	}
+	cp := ai.input.Patterns
+	if cp == nil || len(cp.Patterns) != 2 || cp.Signal != "bullish" {
+		t.Fatalf("expected patterns in the ai input, got %+v", cp)
+	}
+	if got := cp.Patterns[0]; got.Name != "double_bottom" || got.Confidence != 0.72 || got.Target != 44500 || got.Neckline != 43000 {
+		t.Errorf("unexpected first pattern %+v", got)
+	}
+	if msg := FormatTelegramMessage(result); !strings.Contains(msg, "double bottom (72%), bull flag (55%)") {
+		t.Errorf("telegram message should list patterns, got:\n%s", msg)
+	}
+}
+
+func TestPipelinePatternStageDegrades(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns *mockPatterns
+	}{
+		{"error", &mockPatterns{err: errors.New("ml down")}},
+		{"timeout", &mockPatterns{resp: testPatterns(), delay: advisoryTimeout + time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "timeout" && testing.Short() {
+				t.Skip("waits for the pattern timeout")
+			}
+			ai := &mockAI{decision: testDecision()}
+			p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, nil, ai)
+			p.SetPatterns(tt.patterns)
+
+			result, err := p.Analyze(context.Background(), "BTC/USDT")
+			if err != nil {
+				t.Fatalf("pattern failure shouldn't fail the analysis: %v", err)
+			}
+			if result.Patterns != nil || ai.input.Patterns != nil {
+				t.Error("expected no patterns")
+			}
+			if len(result.Errors) == 0 || !strings.HasPrefix(result.Errors[len(result.Errors)-1], "patterns:") {
+				t.Errorf("expected a patterns error, got %v", result.Errors)
+			}
+		})
+	}
+}
+
+type mockRL struct {
+	resp *mlclient.RLActionResponse
+	err  error
+}
+
+func (m *mockRL) GetRLAction(_ context.Context, _ *mlclient.RLActionRequest) (*mlclient.RLActionResponse, error) {
+	return m.resp, m.err
+}
+
+func TestPipelineRLSecondOpinion(t *testing.T) {
+	tests := []struct {
+		name    string
+		rl      *mockRL
+		want    string // expected action in the ai input, empty for none
+		wantErr bool
+	}{
+		{"trained", &mockRL{resp: &mlclient.RLActionResponse{Action: "sell_all", ActionIdx: 4, QValues: []float64{0.1, 0, 0, 0.2, 1.5}}}, "SELL", false},
+		{"exploring", &mockRL{resp: &mlclient.RLActionResponse{Action: "buy_small", ActionIdx: 1, QValues: []float64{0, 1, 0, 0, 0}, Exploring: true}}, "", false},
+		{"untrained", &mockRL{err: errors.New("agent not trained")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &mockAI{decision: testDecision()}
+			p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, nil, ai)
+			p.SetRL(tt.rl)
+
+			result, err := p.Analyze(context.Background(), "BTC/USDT")
+			if err != nil {
+				t.Fatalf("rl stage shouldn't fail the analysis: %v", err)
+			}
+			got := ""
+			if ai.input.Second != nil {
+				got = ai.input.Second.Action
+				if ai.input.Second.Detail != tt.rl.resp.Action || ai.input.Second.Confidence <= 0.5 {
+					t.Errorf("unexpected second opinion %+v", ai.input.Second)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("second opinion action = %q, want %q", got, tt.want)
+			}
+			hasErr := len(result.Errors) > 0 && strings.HasPrefix(result.Errors[len(result.Errors)-1], "rl:")
+			if hasErr != tt.wantErr {
+				t.Errorf("rl error recorded = %v, want %v (errors %v)", hasErr, tt.wantErr, result.Errors)
+			}
+		})
+	}
+}
+
+func TestCompareOpinions(t *testing.T) {
+	outcomes := []OpinionOutcome{
+		{DecisionID: 1, Claude: "BUY", RL: "BUY", MovePct: 1.2},
+		{DecisionID: 2, Claude: "BUY", RL: "HOLD", MovePct: 0.8},   // claude right
+		{DecisionID: 3, Claude: "HOLD", RL: "SELL", MovePct: -0.9}, // rl right
+		{DecisionID: 4, Claude: "BUY", RL: "SELL", MovePct: 0.1},   // neither
+		{DecisionID: 5, Claude: "SELL", RL: "HOLD", MovePct: 0.2},  // rl right
+	}
+	r := CompareOpinions(outcomes, 0.3)
+	if r.Total != 5 || r.Agreed != 1 || len(r.Disagreements) != 4 {
+		t.Fatalf("unexpected totals %+v", r)
+	}
+	if r.ClaudeRight != 1 || r.RLRight != 2 || r.NeitherRight != 1 {
+		t.Errorf("claude/rl/neither = %d/%d/%d, want 1/2/1", r.ClaudeRight, r.RLRight, r.NeitherRight)
+	}
+	if d := r.Disagreements[0]; d.DecisionID != 2 || !d.ClaudeRight || d.RLRight {
+		t.Errorf("unexpected first disagreement %+v", d)
+	}
+	if got := r.AgreementRate(); got != 20 {
+		t.Errorf("AgreementRate() = %v, want 20", got)
+	}
+}
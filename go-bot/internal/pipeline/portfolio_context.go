@@ -0,0 +1,200 @@
+// portfolio context — the user's open paper, live and leverage positions,
+// their exposure, and how the candidate symbol's returns correlate with each
+// held symbol over stored candles, so claude doesn't decide in isolation.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/portfolio"
+)
+
+// OpenPosition is an open position of a user, paper or live, spot or futures.
+type OpenPosition struct {
+	Symbol     string
+	Side       string // LONG or SHORT
+	Type       string // SPOT or FUTURES
+	Paper      bool
+	Quantity   float64
+	EntryPrice float64
+	Notional   float64 // USDT at entry, used when no price is stored
+	Margin     float64 // futures only
+	Leverage   int
+}
+
+// PositionReader lists a user's open positions (implemented via database.PositionRepository).
+type PositionReader interface {
+	OpenPositions(ctx context.Context, userID int) ([]OpenPosition, error)
+}
+
+// PortfolioProvider builds the portfolio context for a user and candidate symbol.
+type PortfolioProvider interface {
+	// Portfolio returns nil when the user holds nothing.
+	Portfolio(ctx context.Context, userID int, symbol string) (*claude.PortfolioContext, error)
+}
+
+// correlations need a few weeks of 4h returns to mean anything
+const (
+	defaultCorrelationCandles = 120
+	minCorrelationReturns     = 20
+)
+
+// PortfolioBuilder computes portfolio contexts from stored positions and candles.
+type PortfolioBuilder struct {
+	positions PositionReader
+	candles   CandleReader
+	interval  string
+	lookback  int
+}
+
+// NewPortfolioBuilder correlates returns of stored candles of the given interval.
+func NewPortfolioBuilder(positions PositionReader, candles CandleReader, interval string) *PortfolioBuilder {
+	return &PortfolioBuilder{
+		positions: positions,
+		candles:   candles,
+		interval:  interval,
+		lookback:  defaultCorrelationCandles,
+	}
+}
+
+// Portfolio values the user's open positions at the latest stored close and
+// correlates the candidate symbol with every other held symbol.
+func (b *PortfolioBuilder) Portfolio(ctx context.Context, userID int, symbol string) (*claude.PortfolioContext, error) {
+	open, err := b.positions.OpenPositions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open positions: %w", err)
+	}
+	if len(open) == 0 {
+		return nil, nil
+	}
+
+	// candles for the candidate and every held symbol
+	candles := make(map[string][]*CandleRecord)
+	symbols := []string{symbol}
+	for _, pos := range open {
+		symbols = append(symbols, pos.Symbol)
+	}
+	for _, s := range symbols {
+		if _, ok := candles[s]; ok {
+			continue
+		}
+		candles[s] = b.recentCandles(ctx, s)
+	}
+
+	pc := &claude.PortfolioContext{}
+	positions := make([]portfolio.Position, 0, len(open))
+	for _, pos := range open {
+		current := pos.EntryPrice
+		if c := candles[pos.Symbol]; len(c) > 0 {
+			current = c[len(c)-1].Close
+		}
+		notional := pos.Notional
+		if pos.Quantity > 0 && current > 0 {
+			notional = pos.Quantity * current
+		}
+		pnl := 0.0
+		if pos.EntryPrice > 0 {
+			pnl = (current - pos.EntryPrice) / pos.EntryPrice * 100
+			if pos.Side == "SHORT" {
+				pnl = -pnl
+			}
+			if pos.Leverage > 1 {
+				pnl *= float64(pos.Leverage)
+			}
+		}
+
+		positions = append(positions, portfolio.Position{
+			Symbol:    pos.Symbol,
+			Side:      pos.Side,
+			Size:      notional,
+			EntryPx:   pos.EntryPrice,
+			CurrentPx: current,
+			PnLPct:    pnl,
+		})
+		pc.Positions = append(pc.Positions, claude.PortfolioPosition{
+			Symbol:   pos.Symbol,
+			Side:     pos.Side,
+			Type:     pos.Type,
+			Paper:    pos.Paper,
+			Notional: math.Round(notional*100) / 100,
+			Leverage: pos.Leverage,
+			PnLPct:   math.Round(pnl*100) / 100,
+		})
+		pc.MarginInUse += pos.Margin
+	}
+
+	risk := portfolio.AnalyzeRisk(positions)
+	pc.TotalExposure = risk.TotalExposure
+	pc.LongExposure = risk.LongExposure
+	pc.ShortExposure = risk.ShortExposure
+	pc.NetExposure = risk.NetExposure
+	pc.ConcentrationPct = math.Round(risk.ConcentrationPct*10) / 10
+	for _, s := range risk.Suggestions {
+		pc.Warnings = append(pc.Warnings, strings.TrimLeftFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }))
+	}
+	pc.Correlations = candidateCorrelations(symbol, positions, candles)
+	return pc, nil
+}
+
+// recentCandles returns stored candles, oldest first; nil when none could be read
+func (b *PortfolioBuilder) recentCandles(ctx context.Context, symbol string) []*CandleRecord {
+	candles, err := b.candles.RecentCandles(ctx, symbol, b.interval, b.lookback)
+	if err != nil {
+		slog.Warn("portfolio: failed to read candles", "symbol", symbol, "interval", b.interval, "error", err)
+		return nil
+	}
+	return candles
+}
+
+// candidateCorrelations correlates the candidate with each held symbol over
+// the candles they share, most correlated first
+func candidateCorrelations(symbol string, positions []portfolio.Position, candles map[string][]*CandleRecord) []claude.SymbolCorrelation {
+	var out []claude.SymbolCorrelation
+	seen := map[string]bool{symbol: true}
+	for _, pos := range positions {
+		if seen[pos.Symbol] {
+			continue
+		}
+		seen[pos.Symbol] = true
+
+		a, b := alignCloses(candles[symbol], candles[pos.Symbol])
+		returns := map[string][]float64{
+			symbol:     portfolio.PriceToReturns(a),
+			pos.Symbol: portfolio.PriceToReturns(b),
+		}
+		// pairs without enough shared history are left out
+		if len(returns[symbol]) < minCorrelationReturns {
+			continue
+		}
+		pair := []portfolio.Position{{Symbol: symbol}, {Symbol: pos.Symbol}}
+		for _, c := range portfolio.AnalyzeCorrelations(pair, returns) {
+			out = append(out, claude.SymbolCorrelation{Symbol: pos.Symbol, Value: c.Value, Risk: c.Risk})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return math.Abs(out[i].Value) > math.Abs(out[j].Value) })
+	return out
+}
+
+// alignCloses returns the closes of the candles both series have at the same
+// open time, so a missing or lagging bar can't shift one series against the other
+func alignCloses(a, b []*CandleRecord) ([]float64, []float64) {
+	byTime := make(map[int64]float64, len(b))
+	for _, c := range b {
+		byTime[c.Time.UnixMilli()] = c.Close
+	}
+	var closesA, closesB []float64
+	for _, c := range a {
+		if other, ok := byTime[c.Time.UnixMilli()]; ok {
+			closesA = append(closesA, c.Close)
+			closesB = append(closesB, other)
+		}
+	}
+	return closesA, closesB
+}
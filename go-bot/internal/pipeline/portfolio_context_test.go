@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/portfolio"
+)
+
+type mockPositionReader struct {
+	positions map[int][]OpenPosition
+}
+
+func (m *mockPositionReader) OpenPositions(_ context.Context, userID int) ([]OpenPosition, error) {
+	return m.positions[userID], nil
+}
+
+// closesReader serves stored candles from close series, all ending at the same bar
+type closesReader map[string][]float64
+
+func (m closesReader) RecentCandles(_ context.Context, symbol, interval string, limit int) ([]*CandleRecord, error) {
+	closes := m[symbol]
+	if len(closes) > limit {
+		closes = closes[len(closes)-limit:]
+	}
+	return candleSeries(symbol, interval, closes, len(closes)), nil
+}
+
+// candleSeries stamps closes as consecutive 4h bars ending at bar end of a fixed day
+func candleSeries(symbol, interval string, closes []float64, end int) []*CandleRecord {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]*CandleRecord, len(closes))
+	for i, c := range closes {
+		at := start.Add(time.Duration(end-len(closes)+i) * 4 * time.Hour)
+		out[i] = &CandleRecord{Time: at, Symbol: symbol, Interval: interval, Close: c}
+	}
+	return out
+}
+
+// wave returns n closes oscillating around base; phase shifts the cycle
+func wave(n int, base, phase float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = base * (1 + 0.02*math.Sin(float64(i)/3+phase))
+	}
+	return out
+}
+
+func TestPortfolioBuilder(t *testing.T) {
+	positions := &mockPositionReader{positions: map[int][]OpenPosition{
+		1: {
+			{Symbol: "BTC/USDT", Side: "LONG", Type: "FUTURES", Quantity: 0.1, EntryPrice: 40000, Margin: 800, Leverage: 5},
+			{Symbol: "SOL/USDT", Side: "SHORT", Type: "SPOT", Paper: true, Notional: 500, EntryPrice: 100},
+		},
+	}}
+	candles := closesReader{
+		"ETH/USDT": wave(80, 2000, 0),
+		"BTC/USDT": wave(80, 42000, 0.1),        // moves with eth
+		"SOL/USDT": wave(80, 100, math.Pi)[:10], // too little history to correlate
+	}
+	b := NewPortfolioBuilder(positions, candles, "4h")
+
+	pc, err := b.Portfolio(context.Background(), 1, "ETH/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pc.Positions) != 2 {
+		t.Fatalf("positions = %+v", pc.Positions)
+	}
+	btc := pc.Positions[0]
+	last := candles["BTC/USDT"][79]
+	if btc.Notional != math.Round(0.1*last*100)/100 || btc.Leverage != 5 || btc.Paper {
+		t.Errorf("btc position should be valued at the latest close, got %+v", btc)
+	}
+	if want := (last - 40000) / 40000 * 100 * 5; math.Abs(btc.PnLPct-want) > 0.01 {
+		t.Errorf("btc pnl = %.2f, want %.2f with leverage", btc.PnLPct, want)
+	}
+	if pc.MarginInUse != 800 || pc.ShortExposure == 0 || pc.NetExposure != pc.LongExposure-pc.ShortExposure {
+		t.Errorf("unexpected exposure %+v", pc)
+	}
+	if len(pc.Correlations) != 1 || pc.Correlations[0].Symbol != "BTC/USDT" || pc.Correlations[0].Risk != "HIGH" {
+		t.Errorf("expected a high correlation with btc only, got %+v", pc.Correlations)
+	}
+
+	if pc, err := b.Portfolio(context.Background(), 2, "ETH/USDT"); err != nil || pc != nil {
+		t.Errorf("a user without positions should get no portfolio, got %+v, %v", pc, err)
+	}
+}
+
+func TestPipelineDecideWithPortfolio(t *testing.T) {
+	ai := &mockAI{decision: testDecision()}
+	p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, nil, ai)
+
+	market, err := p.Prepare(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if market.Decision != nil || market.Input == nil {
+		t.Fatalf("prepare should build claude's input without asking it, got %+v", market)
+	}
+
+	pc := &claude.PortfolioContext{Positions: []claude.PortfolioPosition{{Symbol: "ETH/USDT", Side: "LONG"}}}
+	result, err := p.Decide(context.Background(), market, pc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ai.input.Portfolio != pc || ai.input.Indicators == nil {
+		t.Error("claude should see the market input and the portfolio")
+	}
+	if result.Decision == nil || result.Portfolio != pc {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if market.Input.Portfolio != nil || market.Decision != nil {
+		t.Error("the shared market result must not be modified")
+	}
+}
+
+func TestCandidateCorrelationsAlignOnOpenTime(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	eth := make([]float64, 80)
+	eth[0] = 2000
+	for i := 1; i < len(eth); i++ {
+		eth[i] = eth[i-1] * (1 + 0.02*(rng.Float64()-0.5))
+	}
+	btc := make([]float64, len(eth))
+	for i, c := range eth {
+		btc[i] = c * 20
+	}
+	candles := map[string][]*CandleRecord{
+		"ETH/USDT": candleSeries("ETH/USDT", "4h", eth, 80),
+		// btc's latest bar isn't stored yet
+		"BTC/USDT": candleSeries("BTC/USDT", "4h", btc[:79], 79),
+	}
+
+	got := candidateCorrelations("ETH/USDT", []portfolio.Position{{Symbol: "BTC/USDT"}}, candles)
+	if len(got) != 1 || got[0].Value < 0.99 || got[0].Risk != "HIGH" {
+		t.Errorf("series moving together should correlate once aligned by open time, got %+v", got)
+	}
+}
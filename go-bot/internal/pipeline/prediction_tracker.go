@@ -0,0 +1,191 @@
+// ensemble prediction tracking — records each ensemble prediction with its
+// per-model votes and, once the prediction horizon has passed, the realized
+// price, so every model's accuracy can be measured per symbol and the
+// ensemble weights reviewed against it.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+const (
+	// a prediction resolved later than this after its horizon is dropped
+	// rather than scored against a price it didn't predict
+	defaultResolveWindow = 30 * time.Minute
+	// predictions resolved per evaluation pass
+	resolveBatch = 200
+)
+
+// EnsemblePredictionRecord is one recorded ensemble prediction.
+type EnsemblePredictionRecord struct {
+	ID             int
+	Symbol         string
+	Timeframe      string
+	Direction      string // UP or DOWN
+	Magnitude      float64
+	Confidence     float64
+	PredictedPrice float64
+	CurrentPrice   float64
+	Votes          []mlclient.ModelVote
+	HorizonAt      time.Time // when the prediction can be scored
+}
+
+// EnsemblePredictionStore persists predictions and their outcomes
+// (implemented via database.EnsemblePredictionRepository).
+type EnsemblePredictionStore interface {
+	SaveEnsemblePrediction(ctx context.Context, rec *EnsemblePredictionRecord) error
+	// DueEnsemblePredictions returns unresolved predictions whose horizon is at or before now.
+	DueEnsemblePredictions(ctx context.Context, now time.Time, limit int) ([]*EnsemblePredictionRecord, error)
+	// ResolveEnsemblePrediction stores the realized price and direction; an
+	// empty direction marks the prediction as expired without a score.
+	ResolveEnsemblePrediction(ctx context.Context, id int, realizedPrice float64, realizedDirection string) error
+}
+
+// PriceFetcher fetches the current price for a symbol.
+type PriceFetcher interface {
+	GetPrice(ctx context.Context, symbol string) (*exchange.Ticker, error)
+}
+
+// PredictionTracker records ensemble predictions and scores them once due.
+type PredictionTracker struct {
+	store         EnsemblePredictionStore
+	prices        PriceFetcher
+	clock         clock.Clock
+	resolveWindow time.Duration
+
+	mu       sync.Mutex
+	recorded map[string]time.Time // last record per symbol and timeframe
+}
+
+// NewPredictionTracker creates a tracker that saves to store and scores
+// predictions against prices.
+func NewPredictionTracker(store EnsemblePredictionStore, prices PriceFetcher) *PredictionTracker {
+	return &PredictionTracker{
+		store:         store,
+		prices:        prices,
+		clock:         clock.Real(),
+		resolveWindow: defaultResolveWindow,
+		recorded:      make(map[string]time.Time),
+	}
+}
+
+// SetClock replaces the time source used for horizons and resolution.
+func (t *PredictionTracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// Record saves an ensemble prediction, best-effort. Only one prediction per
+// symbol and timeframe is kept per candle interval, so the scanner analyzing
+// a symbol for several users doesn't count the same call several times.
+func (t *PredictionTracker) Record(ctx context.Context, symbol string, resp *mlclient.EnsemblePredictionResponse) {
+	horizon := intervalToDuration(resp.Timeframe)
+	if horizon <= 0 {
+		horizon = 4 * time.Hour
+	}
+	now := t.clock.Now()
+	key := stateKey(symbol, resp.Timeframe)
+
+	t.mu.Lock()
+	if last, ok := t.recorded[key]; ok && now.Sub(last) < horizon {
+		t.mu.Unlock()
+		return
+	}
+	t.recorded[key] = now
+	t.mu.Unlock()
+
+	rec := &EnsemblePredictionRecord{
+		Symbol:         symbol,
+		Timeframe:      resp.Timeframe,
+		Direction:      strings.ToUpper(resp.Direction),
+		Magnitude:      resp.Magnitude,
+		Confidence:     resp.Confidence,
+		PredictedPrice: resp.PredictedPrice,
+		CurrentPrice:   resp.CurrentPrice,
+		Votes:          resp.Votes(),
+		HorizonAt:      now.Add(horizon),
+	}
+	if err := t.store.SaveEnsemblePrediction(ctx, rec); err != nil {
+		slog.Warn("failed to record ensemble prediction", "symbol", symbol, "error", err)
+		t.mu.Lock()
+		delete(t.recorded, key)
+		t.mu.Unlock()
+	}
+}
+
+// Evaluate scores every prediction whose horizon has passed against the
+// current price. Returns the number of predictions scored.
+func (t *PredictionTracker) Evaluate(ctx context.Context) (int, error) {
+	now := t.clock.Now()
+	due, err := t.store.DueEnsemblePredictions(ctx, now, resolveBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due predictions: %w", err)
+	}
+
+	prices := make(map[string]float64)
+	scored := 0
+	for _, rec := range due {
+		if now.Sub(rec.HorizonAt) > t.resolveWindow {
+			if err := t.store.ResolveEnsemblePrediction(ctx, rec.ID, 0, ""); err != nil {
+				return scored, fmt.Errorf("failed to expire prediction %d: %w", rec.ID, err)
+			}
+			continue
+		}
+
+		price, ok := prices[rec.Symbol]
+		if !ok {
+			ticker, err := t.prices.GetPrice(ctx, rec.Symbol)
+			if err != nil {
+				slog.Warn("prediction tracker: price fetch failed", "symbol", rec.Symbol, "error", err)
+				continue
+			}
+			price = ticker.Price
+			prices[rec.Symbol] = price
+		}
+
+		if err := t.store.ResolveEnsemblePrediction(ctx, rec.ID, price, realizedDirection(rec.CurrentPrice, price)); err != nil {
+			return scored, fmt.Errorf("failed to resolve prediction %d: %w", rec.ID, err)
+		}
+		scored++
+	}
+	return scored, nil
+}
+
+// Run evaluates due predictions on every tick until ctx is cancelled.
+func (t *PredictionTracker) Run(ctx context.Context, every time.Duration) {
+	ticker := t.clock.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if n, err := t.Evaluate(ctx); err != nil {
+				slog.Error("prediction tracker: evaluation failed", "error", err)
+			} else if n > 0 {
+				slog.Debug("prediction tracker: scored predictions", "count", n)
+			}
+		}
+	}
+}
+
+// realizedDirection compares the price at prediction time with the price at the horizon
+func realizedDirection(from, to float64) string {
+	switch {
+	case to > from:
+		return "UP"
+	case to < from:
+		return "DOWN"
+	default:
+		return "NEUTRAL"
+	}
+}
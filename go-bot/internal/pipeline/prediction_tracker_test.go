@@ -0,0 +1,204 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+	"github.com/trading-bot/go-bot/internal/exchange"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// mock ensemble provider
+type mockEnsemble struct {
+	resp  *mlclient.EnsemblePredictionResponse
+	err   error
+	calls int
+}
+
+func (m *mockEnsemble) EnsemblePredict(_ context.Context, _ *mlclient.EnsemblePredictionRequest) (*mlclient.EnsemblePredictionResponse, error) {
+	m.calls++
+	return m.resp, m.err
+}
+
+func testEnsemble() *mlclient.EnsemblePredictionResponse {
+	return &mlclient.EnsemblePredictionResponse{
+		Direction:      "up",
+		Magnitude:      1.8,
+		Confidence:     0.74,
+		Timeframe:      "4h",
+		PredictedPrice: 43214.1,
+		CurrentPrice:   42450,
+		ModelCount:     2,
+		IsEnsemble:     true,
+		ModelDetails: map[string]interface{}{
+			"lstm":          map[string]interface{}{"direction": "up", "magnitude": 2.0, "confidence": 0.8, "weight": 0.6},
+			"random_forest": map[string]interface{}{"direction": "down", "magnitude": 0.5, "confidence": 0.55, "weight": 0.4},
+		},
+	}
+}
+
+// mock prediction store
+type mockPredictionStore struct {
+	mu       sync.Mutex
+	saved    []*EnsemblePredictionRecord
+	resolved map[int]string
+	prices   map[int]float64
+}
+
+func (m *mockPredictionStore) SaveEnsemblePrediction(_ context.Context, rec *EnsemblePredictionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec.ID = len(m.saved) + 1
+	m.saved = append(m.saved, rec)
+	return nil
+}
+
+func (m *mockPredictionStore) DueEnsemblePredictions(_ context.Context, now time.Time, limit int) ([]*EnsemblePredictionRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*EnsemblePredictionRecord
+	for _, rec := range m.saved {
+		if _, done := m.resolved[rec.ID]; !done && !rec.HorizonAt.After(now) && len(due) < limit {
+			due = append(due, rec)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockPredictionStore) ResolveEnsemblePrediction(_ context.Context, id int, price float64, direction string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resolved == nil {
+		m.resolved = make(map[int]string)
+		m.prices = make(map[int]float64)
+	}
+	m.resolved[id] = direction
+	m.prices[id] = price
+	return nil
+}
+
+type mockPrices struct {
+	price float64
+	err   error
+}
+
+func (m *mockPrices) GetPrice(_ context.Context, symbol string) (*exchange.Ticker, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &exchange.Ticker{Symbol: symbol, Price: m.price}, nil
+}
+
+func TestPipelinePrefersEnsemble(t *testing.T) {
+	ai := &mockAI{decision: testDecision()}
+	ml := &mockML{prediction: testPrediction(), sentiment: testSentiment(), available: true}
+	p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, ml, ai)
+	ens := &mockEnsemble{resp: testEnsemble()}
+	p.SetEnsemble(ens)
+	store := &mockPredictionStore{}
+	p.SetPredictionTracker(NewPredictionTracker(store, &mockPrices{}))
+
+	result, err := p.Analyze(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Ensemble == nil || result.Prediction.Magnitude != 1.8 {
+		t.Fatalf("expected the ensemble prediction, got %+v", result.Prediction)
+	}
+	pred := ai.input.Prediction
+	if len(pred.Models) != 2 || pred.Models[0].Model != "lstm" || pred.Agreement != 0.5 {
+		t.Errorf("expected model votes in the ai input, got %+v", pred)
+	}
+	if pred.Dispersion == 0 {
+		t.Error("expected dispersion for split models")
+	}
+	if len(store.saved) != 1 || store.saved[0].Direction != "UP" || len(store.saved[0].Votes) != 2 {
+		t.Errorf("expected the prediction to be recorded, got %+v", store.saved)
+	}
+}
+
+func TestPipelineEnsembleFallsBack(t *testing.T) {
+	tests := []struct {
+		name string
+		ens  *mockEnsemble
+	}{
+		{"error", &mockEnsemble{err: errors.New("not loaded")}},
+		{"no models", &mockEnsemble{resp: &mlclient.EnsemblePredictionResponse{Direction: "up", ModelCount: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ml := &mockML{prediction: testPrediction(), sentiment: testSentiment(), available: true}
+			p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, ml, &mockAI{decision: testDecision()})
+			p.SetEnsemble(tt.ens)
+
+			result, err := p.Analyze(context.Background(), "BTC/USDT")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.ens.calls != 1 {
+				t.Errorf("expected the ensemble to be tried once, got %d", tt.ens.calls)
+			}
+			if result.Ensemble != nil || result.Prediction != ml.prediction {
+				t.Errorf("expected the single-model prediction, got %+v", result.Prediction)
+			}
+		})
+	}
+}
+
+func TestPredictionTracker_RecordAndEvaluate(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	sim := clock.NewSimulated(start)
+	store := &mockPredictionStore{}
+	prices := &mockPrices{price: 42000}
+	tracker := NewPredictionTracker(store, prices)
+	tracker.SetClock(sim)
+
+	tracker.Record(context.Background(), "BTC/USDT", testEnsemble())
+	// a second call in the same candle is the same prediction
+	sim.Advance(time.Hour)
+	tracker.Record(context.Background(), "BTC/USDT", testEnsemble())
+	tracker.Record(context.Background(), "ETH/USDT", testEnsemble())
+	if len(store.saved) != 2 {
+		t.Fatalf("expected one record per symbol, got %d", len(store.saved))
+	}
+	if !store.saved[0].HorizonAt.Equal(start.Add(4 * time.Hour)) {
+		t.Errorf("horizon = %v, want one 4h candle", store.saved[0].HorizonAt)
+	}
+
+	// nothing is due before the horizon
+	if n, _ := tracker.Evaluate(context.Background()); n != 0 {
+		t.Errorf("expected nothing due, scored %d", n)
+	}
+
+	// BTC is due; the price fell, so the UP call was wrong
+	sim.Set(start.Add(4*time.Hour + time.Minute))
+	n, err := tracker.Evaluate(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 scored, got %d (%v)", n, err)
+	}
+	if store.resolved[1] != "DOWN" || store.prices[1] != 42000 {
+		t.Errorf("unexpected resolution %q at %.2f", store.resolved[1], store.prices[1])
+	}
+
+	// ETH is checked too late and expires unscored
+	sim.Set(start.Add(6 * time.Hour))
+	if n, _ := tracker.Evaluate(context.Background()); n != 0 {
+		t.Errorf("expected the late prediction to expire, scored %d", n)
+	}
+	if dir, ok := store.resolved[2]; !ok || dir != "" {
+		t.Errorf("expected ETH expired without a direction, got %q", dir)
+	}
+
+	// a price failure leaves the prediction for the next pass
+	tracker.Record(context.Background(), "SOL/USDT", testEnsemble())
+	sim.Advance(4 * time.Hour)
+	prices.err = errors.New("down")
+	tracker.Evaluate(context.Background())
+	if _, ok := store.resolved[3]; ok {
+		t.Error("expected SOL to stay unresolved after a price failure")
+	}
+}
@@ -0,0 +1,287 @@
+// pre-filter — a cheap deterministic score of indicators, regime, volume and
+// alternative data computed before the claude call. setups scoring below the
+// threshold are returned as HOLD without asking claude. every check is
+// recorded so the threshold can be tuned against the moves it skipped.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// score components and their maximum points; they add up to 100
+const (
+	preFilterIndicatorsMax = 40
+	preFilterRegimeMax     = 20
+	preFilterVolumeMax     = 20
+	preFilterAltDataMax    = 20
+)
+
+// PreFilterScore is the pre-filter's verdict on one analysis.
+type PreFilterScore struct {
+	Score      float64            // 0-100
+	Threshold  float64            // minimum score to reach claude
+	Skipped    bool               // claude was not asked
+	Components map[string]float64 // indicators, regime, volume, alt_data
+	Signals    []string           // what contributed, for the hold reason
+}
+
+// PreFilterCheck is a scored analysis as recorded for tuning.
+type PreFilterCheck struct {
+	Symbol    string
+	Timeframe string
+	Price     float64
+	PreFilterScore
+}
+
+// PreFilterStore persists pre-filter checks (implemented via database.PreFilterRepository).
+type PreFilterStore interface {
+	SavePreFilterCheck(ctx context.Context, check *PreFilterCheck) error
+}
+
+// PreFilter gates the claude call on a deterministic setup score.
+type PreFilter struct {
+	minScore float64
+	store    PreFilterStore // nil = checks are only counted
+	clock    clock.Clock
+
+	skipped  atomic.Int64
+	analyzed atomic.Int64
+}
+
+// NewPreFilter skips claude for setups scoring below minScore (0-100).
+func NewPreFilter(minScore float64) *PreFilter {
+	return &PreFilter{minScore: minScore, clock: clock.Real()}
+}
+
+// SetClock replaces the time source for the hold decisions it returns.
+func (f *PreFilter) SetClock(c clock.Clock) {
+	f.clock = c
+}
+
+// SetStore records every check so skipped setups can be scored against the
+// moves that followed.
+func (f *PreFilter) SetStore(store PreFilterStore) {
+	f.store = store
+}
+
+// Stats returns how many analyses were skipped and sent to claude since startup.
+func (f *PreFilter) Stats() (skipped, analyzed int64) {
+	return f.skipped.Load(), f.analyzed.Load()
+}
+
+// SetPreFilter skips the claude call for setups the pre-filter scores below
+// its threshold.
+func (p *Pipeline) SetPreFilter(f *PreFilter) {
+	p.preFilter = f
+}
+
+// check scores the analysis, counts and records it. Without indicators there's
+// nothing to score, so the analysis goes to claude.
+func (f *PreFilter) check(ctx context.Context, symbol, timeframe string, price float64, ind *analysis.AnalysisResult, alt *claude.AltData) *PreFilterScore {
+	score := ScoreSetup(ind, alt)
+	score.Threshold = f.minScore
+	score.Skipped = ind != nil && score.Score < f.minScore
+
+	if score.Skipped {
+		f.skipped.Add(1)
+	} else {
+		f.analyzed.Add(1)
+	}
+	if f.store != nil {
+		rec := &PreFilterCheck{Symbol: symbol, Timeframe: timeframe, Price: price, PreFilterScore: *score}
+		if err := f.store.SavePreFilterCheck(ctx, rec); err != nil {
+			slog.Warn("pre-filter: failed to record check", "symbol", symbol, "error", err)
+		}
+	}
+	return score
+}
+
+// holdDecision is returned in place of claude's for a skipped setup
+func (s *PreFilterScore) holdDecision(now time.Time) *claude.Decision {
+	signals := "no signals"
+	if len(s.Signals) > 0 {
+		signals = strings.Join(s.Signals, ", ")
+	}
+	return &claude.Decision{
+		Action:    claude.ActionHold,
+		Reasoning: fmt.Sprintf("pre-filter: setup score %.0f below %.0f (%s)", s.Score, s.Threshold, signals),
+		Timestamp: now,
+	}
+}
+
+// ScoreSetup rates how much is going on in a setup, 0-100. It doesn't pick a
+// direction; a strong bearish setup scores as high as a strong bullish one.
+func ScoreSetup(ind *analysis.AnalysisResult, alt *claude.AltData) *PreFilterScore {
+	s := &PreFilterScore{Components: make(map[string]float64)}
+	add := func(component string, points float64, signal string) {
+		s.Components[component] += points
+		if signal != "" {
+			s.Signals = append(s.Signals, signal)
+		}
+	}
+
+	if ind != nil {
+		// indicators: agreement between signals and discrete triggers
+		if imbalance := math.Abs(float64(ind.BullishCount - ind.BearishCount)); imbalance >= 2 {
+			add("indicators", math.Min(imbalance*4, 16), fmt.Sprintf("%s signals %d/%d", strings.ToLower(ind.OverallSignal), ind.BullishCount, ind.BearishCount))
+		}
+		if ind.RSI != nil && (ind.RSI.Value <= 30 || ind.RSI.Value >= 70) {
+			add("indicators", 8, fmt.Sprintf("rsi %.0f", ind.RSI.Value))
+		}
+		if ind.MACD != nil && ind.MACD.Crossover {
+			add("indicators", 8, "macd cross")
+		}
+		if ind.Supertrend != nil && ind.Supertrend.Flipped {
+			add("indicators", 8, "supertrend flip")
+		}
+		if ind.Donchian != nil && strings.HasPrefix(ind.Donchian.Signal, "BREAKOUT") {
+			add("indicators", 8, strings.ToLower(ind.Donchian.Signal))
+		}
+
+		// regime: trends and volatility are tradeable, quiet markets rarely are
+		if ind.Regime != nil {
+			switch ind.Regime.Regime {
+			case "trending":
+				add("regime", 20, "trending")
+			case "volatile":
+				add("regime", 12, "volatile")
+			case "ranging":
+				add("regime", 6, "")
+			}
+		}
+
+		// volume: a spike confirms the move
+		if ind.Volume != nil {
+			switch {
+			case ind.Volume.IsSpike:
+				add("volume", 20, fmt.Sprintf("volume %.1fx", ind.Volume.Ratio))
+			case ind.Volume.Ratio >= 1.2:
+				add("volume", 10, fmt.Sprintf("volume %.1fx", ind.Volume.Ratio))
+			}
+		}
+	}
+
+	// alternative data: positioning and flow extremes
+	if alt != nil {
+		if alt.FundingRate != nil && math.Abs(alt.FundingRate.Rate) >= 0.0005 {
+			add("alt_data", 8, fmt.Sprintf("funding %.3f%%", alt.FundingRate.Rate*100))
+		}
+		if of := alt.OrderFlow; of != nil {
+			if of.BuySellRatio >= 1.3 || (of.BuySellRatio > 0 && of.BuySellRatio <= 0.77) {
+				add("alt_data", 6, fmt.Sprintf("buy/sell %.2f", of.BuySellRatio))
+			}
+			if math.Abs(of.DepthImbalance) >= 0.2 {
+				add("alt_data", 6, "book imbalance")
+			}
+		}
+		if alt.Sentiment != nil && math.Abs(alt.Sentiment.OverallScore) >= 0.5 {
+			add("alt_data", 6, fmt.Sprintf("sentiment %+.2f", alt.Sentiment.OverallScore))
+		}
+	}
+
+	caps := map[string]float64{
+		"indicators": preFilterIndicatorsMax,
+		"regime":     preFilterRegimeMax,
+		"volume":     preFilterVolumeMax,
+		"alt_data":   preFilterAltDataMax,
+	}
+	for component, points := range s.Components {
+		s.Components[component] = math.Min(points, caps[component])
+		s.Score += s.Components[component]
+	}
+	return s
+}
+
+// PreFilterOutcome is a recorded check with the price move that followed.
+type PreFilterOutcome struct {
+	Symbol  string
+	Score   float64
+	Skipped bool
+	MovePct float64 // price change over the horizon, percent
+	At      time.Time
+}
+
+// PreFilterBand summarizes checks within a 10-point score band.
+type PreFilterBand struct {
+	From     int // band covers scores From to From+10
+	Checks   int
+	Skipped  int
+	Moved    int // checks followed by a move of at least the threshold
+	Analyzed int
+}
+
+// PreFilterReport summarizes skipped versus analysed checks and the moves the
+// skipped ones missed.
+type PreFilterReport struct {
+	Checks        int
+	Skipped       int
+	Analyzed      int
+	Missed        int // skipped checks followed by a move of at least the threshold
+	AnalyzedMoved int
+	Bands         []PreFilterBand // ascending by score
+}
+
+// SkipRate returns the share of checks that skipped claude, in percent.
+func (r *PreFilterReport) SkipRate() float64 {
+	if r.Checks == 0 {
+		return 0
+	}
+	return float64(r.Skipped) / float64(r.Checks) * 100
+}
+
+// MissRate returns the share of skipped checks followed by a move, in percent.
+func (r *PreFilterReport) MissRate() float64 {
+	if r.Skipped == 0 {
+		return 0
+	}
+	return float64(r.Missed) / float64(r.Skipped) * 100
+}
+
+// SummarizePreFilter counts a move of thresholdPct or more in either direction
+// as an opportunity.
+func SummarizePreFilter(outcomes []PreFilterOutcome, thresholdPct float64) *PreFilterReport {
+	report := &PreFilterReport{Checks: len(outcomes)}
+	bands := make(map[int]*PreFilterBand)
+	for _, o := range outcomes {
+		moved := math.Abs(o.MovePct) >= thresholdPct
+		from := int(math.Min(o.Score, 99)) / 10 * 10
+		band, ok := bands[from]
+		if !ok {
+			band = &PreFilterBand{From: from}
+			bands[from] = band
+		}
+		band.Checks++
+		if moved {
+			band.Moved++
+		}
+		if o.Skipped {
+			report.Skipped++
+			band.Skipped++
+			if moved {
+				report.Missed++
+			}
+		} else {
+			report.Analyzed++
+			band.Analyzed++
+			if moved {
+				report.AnalyzedMoved++
+			}
+		}
+	}
+	for _, b := range bands {
+		report.Bands = append(report.Bands, *b)
+	}
+	sort.Slice(report.Bands, func(i, j int) bool { return report.Bands[i].From < report.Bands[j].From })
+	return report
+}
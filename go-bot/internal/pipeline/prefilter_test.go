@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+type mockPreFilterStore struct {
+	checks []*PreFilterCheck
+}
+
+func (m *mockPreFilterStore) SavePreFilterCheck(_ context.Context, check *PreFilterCheck) error {
+	m.checks = append(m.checks, check)
+	return nil
+}
+
+func TestScoreSetup(t *testing.T) {
+	flat := ScoreSetup(testIndicators(), nil)
+	if flat.Score != 8 {
+		t.Errorf("flat setup score = %.0f, want 8 (components %v)", flat.Score, flat.Components)
+	}
+
+	ind := testIndicators()
+	ind.BullishCount, ind.BearishCount = 9, 1
+	ind.RSI.Value = 25
+	ind.MACD.Crossover = true
+	ind.Supertrend.Flipped = true
+	ind.Regime = &analysis.RegimeResult{Regime: "trending"}
+	ind.Volume.IsSpike = true
+	alt := &claude.AltData{
+		FundingRate: &claude.FundingData{Rate: -0.001},
+		OrderFlow:   &claude.OrderFlowData{BuySellRatio: 0.6, DepthImbalance: -0.4},
+		Sentiment:   &claude.SentimentData{OverallScore: -0.7},
+	}
+	strong := ScoreSetup(ind, alt)
+	if strong.Components["indicators"] != preFilterIndicatorsMax || strong.Components["alt_data"] != preFilterAltDataMax {
+		t.Errorf("components should be capped, got %v", strong.Components)
+	}
+	if strong.Score != 100 {
+		t.Errorf("strong setup score = %.0f, want 100", strong.Score)
+	}
+}
+
+func TestPipelinePreFilter(t *testing.T) {
+	strong := testIndicators()
+	strong.Regime = &analysis.RegimeResult{Regime: "trending"}
+	strong.Volume.IsSpike = true
+
+	tests := []struct {
+		name        string
+		indicators  *analysis.AnalysisResult
+		indErr      bool
+		wantSkipped bool
+	}{
+		{"flat setup skips claude", testIndicators(), false, true},
+		{"strong setup reaches claude", strong, false, false},
+		{"no indicators reaches claude", nil, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &mockAI{decision: testDecision()}
+			ind := &mockIndicators{result: tt.indicators}
+			if tt.indErr {
+				ind.err = errors.New("rust engine down")
+			}
+			p := New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, ind, nil, ai)
+			store := &mockPreFilterStore{}
+			filter := NewPreFilter(25)
+			filter.SetStore(store)
+			p.SetPreFilter(filter)
+
+			result, err := p.Analyze(context.Background(), "BTC/USDT")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (ai.input == nil) != tt.wantSkipped {
+				t.Errorf("claude called = %v, want %v", ai.input != nil, !tt.wantSkipped)
+			}
+			if result.PreFilter == nil || result.PreFilter.Skipped != tt.wantSkipped {
+				t.Fatalf("pre-filter verdict = %+v, want skipped %v", result.PreFilter, tt.wantSkipped)
+			}
+			if tt.wantSkipped {
+				if result.Decision.Action != claude.ActionHold || !strings.Contains(result.Decision.Reasoning, "below 25") {
+					t.Errorf("expected a HOLD with the pre-filter reason, got %+v", result.Decision)
+				}
+			}
+			if len(store.checks) != 1 || store.checks[0].Symbol != "BTC/USDT" || store.checks[0].Price != testTicker().Price {
+				t.Errorf("expected the check to be recorded, got %+v", store.checks)
+			}
+			skipped, analyzed := filter.Stats()
+			if (skipped == 1) != tt.wantSkipped || skipped+analyzed != 1 {
+				t.Errorf("stats = %d skipped, %d analyzed", skipped, analyzed)
+			}
+		})
+	}
+}
+
+func TestSummarizePreFilter(t *testing.T) {
+	outcomes := []PreFilterOutcome{
+		{Score: 8, Skipped: true, MovePct: 0.2},
+		{Score: 12, Skipped: true, MovePct: -1.5}, // missed
+		{Score: 18, Skipped: true, MovePct: 0.4},
+		{Score: 40, MovePct: 2.1},
+		{Score: 100, MovePct: 0.1},
+	}
+	r := SummarizePreFilter(outcomes, 1)
+	if r.Checks != 5 || r.Skipped != 3 || r.Analyzed != 2 || r.Missed != 1 || r.AnalyzedMoved != 1 {
+		t.Errorf("unexpected report %+v", r)
+	}
+	if r.SkipRate() != 60 {
+		t.Errorf("skip rate = %.1f, want 60", r.SkipRate())
+	}
+	if len(r.Bands) != 4 || r.Bands[0].From != 0 || r.Bands[1].From != 10 || r.Bands[1].Checks != 2 || r.Bands[3].From != 90 {
+		t.Errorf("unexpected bands %+v", r.Bands)
+	}
+}
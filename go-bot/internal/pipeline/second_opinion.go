@@ -0,0 +1,107 @@
+// RL second opinion — asks the reinforcement-learning agent what it would do
+// and shows Claude the answer as advisory input. Logged decisions carry the
+// agent's call, so CompareOpinions can later score who was right when the
+// two disagreed.
+package pipeline
+
+import (
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// the agent is asked from a flat position with its default training balance
+const rlBalance = 10000
+
+// secondOpinion converts the agent's action for the prompt. An untrained or
+// exploring agent has no opinion worth showing.
+func secondOpinion(resp *mlclient.RLActionResponse) *claude.SecondOpinion {
+	if resp == nil || resp.Confidence() == 0 {
+		return nil
+	}
+	return &claude.SecondOpinion{
+		Source:     "rl_agent",
+		Action:     resp.Signal(),
+		Detail:     resp.Action,
+		Confidence: resp.Confidence(),
+	}
+}
+
+// OpinionOutcome is a logged decision with the RL agent's call on the same
+// analysis and the price move that followed.
+type OpinionOutcome struct {
+	DecisionID   int
+	Symbol       string
+	Claude       string // BUY, SELL or HOLD
+	RL           string // BUY, SELL or HOLD
+	RLConfidence float64
+	MovePct      float64 // price change over the horizon, percent
+	At           time.Time
+}
+
+// ScoredDisagreement is a disagreement with each side's verdict.
+type ScoredDisagreement struct {
+	OpinionOutcome
+	ClaudeRight bool
+	RLRight     bool
+}
+
+// OpinionReport summarizes how Claude and the RL agent compared.
+type OpinionReport struct {
+	Total         int
+	Agreed        int
+	ClaudeRight   int // disagreements Claude called correctly
+	RLRight       int // disagreements the agent called correctly
+	NeitherRight  int
+	Disagreements []ScoredDisagreement
+}
+
+// AgreementRate returns the share of decisions both sides agreed on, in percent.
+func (r *OpinionReport) AgreementRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Agreed) / float64(r.Total) * 100
+}
+
+// CompareOpinions scores every disagreement. A BUY is right when the price
+// rose more than thresholdPct, a SELL when it fell more than thresholdPct,
+// and a HOLD when it stayed within it, so at most one side of a
+// disagreement can be right.
+func CompareOpinions(outcomes []OpinionOutcome, thresholdPct float64) *OpinionReport {
+	report := &OpinionReport{Total: len(outcomes)}
+	for _, o := range outcomes {
+		if o.Claude == o.RL {
+			report.Agreed++
+			continue
+		}
+		d := ScoredDisagreement{
+			OpinionOutcome: o,
+			ClaudeRight:    callRight(o.Claude, o.MovePct, thresholdPct),
+			RLRight:        callRight(o.RL, o.MovePct, thresholdPct),
+		}
+		switch {
+		case d.ClaudeRight:
+			report.ClaudeRight++
+		case d.RLRight:
+			report.RLRight++
+		default:
+			report.NeitherRight++
+		}
+		report.Disagreements = append(report.Disagreements, d)
+	}
+	return report
+}
+
+// callRight judges one call against the move that followed
+func callRight(action string, movePct, thresholdPct float64) bool {
+	switch action {
+	case "BUY":
+		return movePct > thresholdPct
+	case "SELL":
+		return movePct < -thresholdPct
+	default:
+		return movePct >= -thresholdPct && movePct <= thresholdPct
+	}
+}
@@ -0,0 +1,387 @@
+// shared analysis — deduplicates concurrent analyses of the same symbol and
+// caches results per symbol and timeframe for a short TTL, so every user
+// watching a symbol shares one data fetch and one claude call per cycle.
+// with a portfolio provider, users holding positions get their own claude
+// call on the shared market data; users with the same (or no) positions
+// still share one. user-specific filters are applied by the caller.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// Analyzer runs the analysis pipeline for a symbol.
+type Analyzer interface {
+	Analyze(ctx context.Context, symbol string) (*Result, error)
+}
+
+// StagedAnalyzer splits an analysis into the market-data part shared by all
+// users and the claude call, which depends on the user's portfolio.
+type StagedAnalyzer interface {
+	Prepare(ctx context.Context, symbol string) (*Result, error)
+	Decide(ctx context.Context, market *Result, portfolio *claude.PortfolioContext, prompt *claude.PromptVariant) (*Result, error)
+}
+
+// PromptSelector picks the system prompt variant for a user's analysis of a
+// symbol; nil means the default prompt.
+type PromptSelector interface {
+	Prompt(userID int, symbol string) *claude.PromptVariant
+}
+
+// AIBudget limits what each user's analyses may spend on ai calls.
+type AIBudget interface {
+	// AllowAI reports whether the user may trigger an ai call, with the
+	// reason when not. User 0 is an analysis not made for any user.
+	AllowAI(userID int) (bool, string)
+	RecordAI(userID int, costUSD float64)
+}
+
+// FailedAICallLogger persists the usage of ai calls that failed after being
+// billed, so spend reports and budgets restored after a restart include it.
+type FailedAICallLogger interface {
+	LogFailedAICall(ctx context.Context, userID int, symbol string, usage claude.Usage, err error)
+}
+
+// ResultCache stores analysis results shared across users.
+type ResultCache interface {
+	// GetResult returns the cached result, or nil when missing or expired.
+	GetResult(ctx context.Context, key string) (*Result, error)
+	SetResult(ctx context.Context, key string, result *Result, ttl time.Duration) error
+}
+
+// SharedAnalysisStats counts how analyses were served.
+type SharedAnalysisStats struct {
+	Hits   int64 // served from the cache
+	Misses int64 // ran the full pipeline
+	Joined int64 // waited on an identical analysis already in flight
+}
+
+// SavedCalls is the number of full pipeline runs, and so claude calls, avoided.
+func (s SharedAnalysisStats) SavedCalls() int64 {
+	return s.Hits + s.Joined
+}
+
+// SharedAnalyzer wraps an analyzer with in-flight deduplication and a TTL
+// cache. Results are shared between callers and must not be modified.
+type SharedAnalyzer struct {
+	analyzer   Analyzer
+	timeframe  string
+	ttl        time.Duration
+	cache      ResultCache
+	portfolios PortfolioProvider
+	budget     AIBudget           // nil = no spend limits
+	failed     FailedAICallLogger // nil = failed calls aren't persisted
+	prompts    PromptSelector     // nil = the default prompt for everyone
+	clock      clock.Clock
+
+	mu       sync.Mutex
+	inflight map[string]*analysisCall
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	joined atomic.Int64
+}
+
+// analysisCall is an analysis in progress that other callers can wait on
+type analysisCall struct {
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+// NewSharedAnalyzer caches results of the analyzer's primary timeframe for
+// ttl in memory. A zero ttl only deduplicates in-flight requests.
+func NewSharedAnalyzer(analyzer Analyzer, timeframe string, ttl time.Duration) *SharedAnalyzer {
+	return &SharedAnalyzer{
+		analyzer:  analyzer,
+		timeframe: timeframe,
+		ttl:       ttl,
+		cache:     NewMemoryResultCache(),
+		clock:     clock.Real(),
+		inflight:  make(map[string]*analysisCall),
+	}
+}
+
+// SetClock replaces the time source for the budget holds it makes. The
+// in-memory cache has its own clock.
+func (s *SharedAnalyzer) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetCache replaces the in-memory cache, e.g. with redis so several bot
+// instances share results.
+func (s *SharedAnalyzer) SetCache(cache ResultCache) {
+	s.cache = cache
+}
+
+// SetPortfolios makes AnalyzeFor account for each user's open positions.
+// Needs an analyzer that implements StagedAnalyzer.
+func (s *SharedAnalyzer) SetPortfolios(portfolios PortfolioProvider) {
+	s.portfolios = portfolios
+}
+
+// SetBudget charges each ai call to the user whose analysis made it. Users
+// over budget get pre-filter-only analyses until it resets. Needs an
+// analyzer that implements StagedAnalyzer to skip the ai call.
+func (s *SharedAnalyzer) SetBudget(budget AIBudget) {
+	s.budget = budget
+}
+
+// SetFailedCallLogger persists the usage of billed ai calls that failed.
+func (s *SharedAnalyzer) SetFailedCallLogger(logger FailedAICallLogger) {
+	s.failed = logger
+}
+
+// SetPrompts runs prompt experiments: each analysis asks the ai with the
+// variant the selector assigns, and only callers on the same variant share
+// a decision. Needs an analyzer that implements StagedAnalyzer.
+func (s *SharedAnalyzer) SetPrompts(prompts PromptSelector) {
+	s.prompts = prompts
+}
+
+// Analyze returns a cached result for the symbol if one is fresh, joins an
+// identical analysis already running, or runs the pipeline.
+func (s *SharedAnalyzer) Analyze(ctx context.Context, symbol string) (*Result, error) {
+	return s.decide(ctx, 0, symbol, nil)
+}
+
+// AnalyzeFor is Analyze with the user's open positions in claude's input.
+// Users without positions, or whose portfolio can't be loaded, share the
+// plain analysis.
+func (s *SharedAnalyzer) AnalyzeFor(ctx context.Context, userID int, symbol string) (*Result, error) {
+	var pc *claude.PortfolioContext
+	if _, staged := s.analyzer.(StagedAnalyzer); staged && s.portfolios != nil {
+		var err error
+		pc, err = s.portfolios.Portfolio(ctx, userID, symbol)
+		if err != nil {
+			slog.Warn("shared analysis: portfolio unavailable, using the shared decision", "user_id", userID, "symbol", symbol, "error", err)
+			pc = nil
+		}
+	}
+	return s.decide(ctx, userID, symbol, pc)
+}
+
+// decide shares the decision for a symbol and portfolio and, for staged
+// analyzers, the market data under it. A decision made for another caller
+// comes back as a copy marked Shared, so its ai usage is only counted once.
+func (s *SharedAnalyzer) decide(ctx context.Context, userID int, symbol string, pc *claude.PortfolioContext) (*Result, error) {
+	key := stateKey(symbol, s.timeframe)
+	staged, ok := s.analyzer.(StagedAnalyzer)
+	if ok && s.budget != nil {
+		if allowed, reason := s.budget.AllowAI(userID); !allowed {
+			return s.preFilterOnly(ctx, staged, symbol, reason)
+		}
+	}
+
+	var result *Result
+	var fresh bool
+	var err error
+	if !ok {
+		result, fresh, err = s.share(ctx, key, true, func() (*Result, error) {
+			return s.analyzer.Analyze(ctx, symbol)
+		})
+	} else {
+		if pc != nil {
+			key += "|portfolio:" + portfolioKey(pc)
+		}
+		var prompt *claude.PromptVariant
+		if s.prompts != nil {
+			prompt = s.prompts.Prompt(userID, symbol)
+		}
+		if prompt != nil {
+			key += "|prompt:" + prompt.Version
+		}
+		result, fresh, err = s.share(ctx, key, true, func() (*Result, error) {
+			market, err := s.market(ctx, staged, symbol)
+			if err != nil {
+				return nil, err
+			}
+			return staged.Decide(ctx, market, pc, prompt)
+		})
+	}
+	if err != nil {
+		// failed calls can still have been billed, e.g. an unparseable answer
+		var ue *claude.UsageError
+		if fresh && errors.As(err, &ue) {
+			if s.budget != nil {
+				s.budget.RecordAI(userID, ue.Usage.CostUSD)
+			}
+			if s.failed != nil {
+				s.failed.LogFailedAICall(ctx, userID, symbol, ue.Usage, err)
+			}
+		}
+		return nil, err
+	}
+
+	if !fresh {
+		shared := *result
+		shared.Shared = true
+		return &shared, nil
+	}
+	if s.budget != nil && result.Decision != nil {
+		s.budget.RecordAI(userID, result.Decision.Usage.CostUSD)
+	}
+	return result, nil
+}
+
+// market shares the prepared market data for a symbol
+func (s *SharedAnalyzer) market(ctx context.Context, staged StagedAnalyzer, symbol string) (*Result, error) {
+	market, _, err := s.share(ctx, stateKey(symbol, s.timeframe)+"|market", false, func() (*Result, error) {
+		return staged.Prepare(ctx, symbol)
+	})
+	return market, err
+}
+
+// preFilterOnly analyzes the symbol without the ai call: setups the
+// pre-filter skips keep its hold, everything else is held for the budget
+func (s *SharedAnalyzer) preFilterOnly(ctx context.Context, staged StagedAnalyzer, symbol, reason string) (*Result, error) {
+	market, err := s.market(ctx, staged, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if market.Decision != nil || market.Input == nil {
+		return market, nil
+	}
+
+	msg := "ai budget: " + reason
+	if market.PreFilter != nil {
+		msg += fmt.Sprintf(", pre-filter-only mode (setup score %.0f passed)", market.PreFilter.Score)
+	}
+	result := *market
+	result.Decision = &claude.Decision{Action: claude.ActionHold, Reasoning: msg, Timestamp: s.clock.Now()}
+	return &result, nil
+}
+
+// share serves key from the cache, joins a run in flight, or runs fn and
+// caches its result; fresh is true when fn ran for this caller. Only
+// decisions are counted in the stats.
+func (s *SharedAnalyzer) share(ctx context.Context, key string, counted bool, fn func() (*Result, error)) (*Result, bool, error) {
+	count := func(c *atomic.Int64) {
+		if counted {
+			c.Add(1)
+		}
+	}
+
+	if s.ttl > 0 {
+		result, err := s.cache.GetResult(ctx, key)
+		if err != nil {
+			slog.Warn("shared analysis: cache read failed", "key", key, "error", err)
+		} else if result != nil {
+			count(&s.hits)
+			return result, false, nil
+		}
+	}
+
+	s.mu.Lock()
+	if call, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		count(&s.joined)
+		select {
+		case <-call.done:
+			return call.result, false, call.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	call := &analysisCall{done: make(chan struct{})}
+	s.inflight[key] = call
+	s.mu.Unlock()
+
+	count(&s.misses)
+	call.result, call.err = fn()
+	if call.err == nil && s.ttl > 0 {
+		if err := s.cache.SetResult(ctx, key, call.result, s.ttl); err != nil {
+			slog.Warn("shared analysis: cache write failed", "key", key, "error", err)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.result, true, call.err
+}
+
+// portfolioKey fingerprints a portfolio so users holding the same positions
+// share a decision
+func portfolioKey(pc *claude.PortfolioContext) string {
+	data, _ := json.Marshal(pc)
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Stats returns hit, miss and join counts since startup.
+func (s *SharedAnalyzer) Stats() SharedAnalysisStats {
+	return SharedAnalysisStats{
+		Hits:   s.hits.Load(),
+		Misses: s.misses.Load(),
+		Joined: s.joined.Load(),
+	}
+}
+
+// MemoryResultCache is an in-process ResultCache.
+type MemoryResultCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResult
+	clock   clock.Clock
+}
+
+type cachedResult struct {
+	result    *Result
+	expiresAt time.Time
+}
+
+func NewMemoryResultCache() *MemoryResultCache {
+	return &MemoryResultCache{
+		entries: make(map[string]cachedResult),
+		clock:   clock.Real(),
+	}
+}
+
+// SetClock replaces the time source used for expiry.
+func (c *MemoryResultCache) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+func (c *MemoryResultCache) GetResult(_ context.Context, key string) (*Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, nil
+	}
+	return entry.result, nil
+}
+
+func (c *MemoryResultCache) SetResult(_ context.Context, key string, result *Result, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	// drop expired entries so symbols leaving the watchlist don't linger
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedResult{result: result, expiresAt: now.Add(ttl)}
+	return nil
+}
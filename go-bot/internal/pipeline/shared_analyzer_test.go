@@ -0,0 +1,321 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// countingAnalyzer blocks until release is closed and counts pipeline runs
+type countingAnalyzer struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (a *countingAnalyzer) Analyze(_ context.Context, symbol string) (*Result, error) {
+	a.calls.Add(1)
+	if a.release != nil {
+		<-a.release
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &Result{Symbol: symbol, Decision: &claude.Decision{Action: claude.ActionBuy, Confidence: 85}}, nil
+}
+
+func TestSharedAnalyzerDeduplicatesInFlight(t *testing.T) {
+	inner := &countingAnalyzer{release: make(chan struct{})}
+	shared := NewSharedAnalyzer(inner, "4h", time.Minute)
+
+	const users = 10
+	var wg sync.WaitGroup
+	results := make([]*Result, users)
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = shared.Analyze(context.Background(), "BTC/USDT")
+		}(i)
+	}
+	// let every caller reach the in-flight analysis before it finishes
+	for shared.Stats().Joined < users-1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(inner.release)
+	wg.Wait()
+
+	if n := inner.calls.Load(); n != 1 {
+		t.Fatalf("expected one pipeline run for %d users, got %d", users, n)
+	}
+	for i, r := range results {
+		if r == nil || r.Symbol != "BTC/USDT" {
+			t.Fatalf("user %d got result %+v", i, r)
+		}
+	}
+	stats := shared.Stats()
+	if stats.Misses != 1 || stats.SavedCalls() != users-1 {
+		t.Errorf("stats = %+v, saved %d", stats, stats.SavedCalls())
+	}
+}
+
+func TestSharedAnalyzerCacheTTL(t *testing.T) {
+	inner := &countingAnalyzer{}
+	sim := clock.NewSimulated(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewMemoryResultCache()
+	cache.SetClock(sim)
+	shared := NewSharedAnalyzer(inner, "4h", 4*time.Minute)
+	shared.SetCache(cache)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := shared.Analyze(ctx, "BTC/USDT"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := shared.Analyze(ctx, "ETH/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if n := inner.calls.Load(); n != 2 {
+		t.Fatalf("expected one run per symbol while fresh, got %d", n)
+	}
+
+	sim.Advance(5 * time.Minute)
+	if _, err := shared.Analyze(ctx, "BTC/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if n := inner.calls.Load(); n != 3 {
+		t.Errorf("expected an expired result to be re-analyzed, got %d runs", n)
+	}
+	if stats := shared.Stats(); stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("stats = %+v, want 2 hits and 3 misses", stats)
+	}
+}
+
+func TestSharedAnalyzerDoesNotCacheErrors(t *testing.T) {
+	inner := &countingAnalyzer{err: errors.New("exchange down")}
+	shared := NewSharedAnalyzer(inner, "4h", time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := shared.Analyze(context.Background(), "BTC/USDT"); err == nil {
+			t.Fatal("expected the analysis error")
+		}
+	}
+	if n := inner.calls.Load(); n != 2 {
+		t.Errorf("failed analyses should be retried, got %d runs", n)
+	}
+}
+
+// stagedAnalyzer counts market-data runs and claude calls separately
+type stagedAnalyzer struct {
+	prepares atomic.Int32
+	decides  atomic.Int32
+}
+
+func (a *stagedAnalyzer) Analyze(ctx context.Context, symbol string) (*Result, error) {
+	market, err := a.Prepare(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return a.Decide(ctx, market, nil, nil)
+}
+
+func (a *stagedAnalyzer) Prepare(_ context.Context, symbol string) (*Result, error) {
+	a.prepares.Add(1)
+	return &Result{Symbol: symbol, Input: &claude.AnalysisInput{}}, nil
+}
+
+func (a *stagedAnalyzer) Decide(_ context.Context, market *Result, pc *claude.PortfolioContext, prompt *claude.PromptVariant) (*Result, error) {
+	a.decides.Add(1)
+	result := *market
+	result.Portfolio = pc
+	result.Decision = &claude.Decision{Action: claude.ActionHold}
+	if prompt != nil {
+		result.Decision.PromptVersion = prompt.Version
+	}
+	return &result, nil
+}
+
+type mockPortfolios map[int]*claude.PortfolioContext
+
+func (m mockPortfolios) Portfolio(_ context.Context, userID int, _ string) (*claude.PortfolioContext, error) {
+	return m[userID], nil
+}
+
+func TestSharedAnalyzerPerUserPortfolio(t *testing.T) {
+	btcLong := &claude.PortfolioContext{Positions: []claude.PortfolioPosition{{Symbol: "BTC/USDT", Side: "LONG", Notional: 1000}}}
+	inner := &stagedAnalyzer{}
+	shared := NewSharedAnalyzer(inner, "4h", time.Minute)
+	shared.SetPortfolios(mockPortfolios{
+		2: btcLong,
+		3: {Positions: []claude.PortfolioPosition{{Symbol: "BTC/USDT", Side: "LONG", Notional: 1000}}},
+	})
+	ctx := context.Background()
+
+	results := make(map[int]*Result)
+	for _, user := range []int{1, 2, 3, 4} {
+		r, err := shared.AnalyzeFor(ctx, user, "ETH/USDT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[user] = r
+	}
+
+	if n := inner.prepares.Load(); n != 1 {
+		t.Errorf("market data should be shared by every user, got %d runs", n)
+	}
+	if n := inner.decides.Load(); n != 2 {
+		t.Errorf("expected one decision for flat users and one for the btc long, got %d", n)
+	}
+	if results[1].Portfolio != nil || results[4].Decision != results[1].Decision || !results[4].Shared || results[1].Shared {
+		t.Error("users without positions should share the plain decision")
+	}
+	if results[2].Portfolio != btcLong || results[3].Decision != results[2].Decision || !results[3].Shared {
+		t.Error("users holding the same positions should share a decision that saw them")
+	}
+	if stats := shared.Stats(); stats.Misses != 2 || stats.Hits != 2 {
+		t.Errorf("stats = %+v, want 2 misses and 2 hits", stats)
+	}
+}
+
+type mockBudget struct {
+	blocked map[int]bool
+	spent   map[int]float64
+}
+
+func (b *mockBudget) AllowAI(userID int) (bool, string) {
+	if b.blocked[userID] {
+		return false, "daily ai budget of $1.00 spent"
+	}
+	return true, ""
+}
+
+func (b *mockBudget) RecordAI(userID int, cost float64) {
+	b.spent[userID] += cost
+}
+
+// pricedAnalyzer is a staged analyzer whose decisions cost $0.02
+type pricedAnalyzer struct {
+	stagedAnalyzer
+}
+
+func (a *pricedAnalyzer) Decide(ctx context.Context, market *Result, pc *claude.PortfolioContext, prompt *claude.PromptVariant) (*Result, error) {
+	result, _ := a.stagedAnalyzer.Decide(ctx, market, pc, prompt)
+	result.Decision.Usage = claude.Usage{InputTokens: 3000, OutputTokens: 200, CostUSD: 0.02}
+	return result, nil
+}
+
+func TestSharedAnalyzerBudget(t *testing.T) {
+	inner := &pricedAnalyzer{}
+	budget := &mockBudget{blocked: map[int]bool{3: true}, spent: map[int]float64{}}
+	shared := NewSharedAnalyzer(inner, "4h", time.Minute)
+	shared.SetBudget(budget)
+	ctx := context.Background()
+
+	first, err := shared.AnalyzeFor(ctx, 1, "SOL/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := shared.AnalyzeFor(ctx, 2, "SOL/USDT")
+	if first.Shared || !second.Shared {
+		t.Errorf("only the second user should get a shared decision, got %v/%v", first.Shared, second.Shared)
+	}
+	if budget.spent[1] != 0.02 || budget.spent[2] != 0 {
+		t.Errorf("the call should be charged to the user who made it, got %v", budget.spent)
+	}
+
+	// over budget: market data only, no ai call
+	held, err := shared.AnalyzeFor(ctx, 3, "ETH/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := inner.decides.Load(); n != 1 {
+		t.Errorf("an exhausted budget shouldn't reach the ai, got %d calls", n)
+	}
+	if held.Decision == nil || held.Decision.Action != claude.ActionHold || !strings.Contains(held.Decision.Reasoning, "ai budget") {
+		t.Errorf("expected a budget hold, got %+v", held.Decision)
+	}
+}
+
+// unparseableAnalyzer is a staged analyzer whose ai answers can't be parsed
+type unparseableAnalyzer struct {
+	stagedAnalyzer
+}
+
+func (a *unparseableAnalyzer) Decide(context.Context, *Result, *claude.PortfolioContext, *claude.PromptVariant) (*Result, error) {
+	return nil, fmt.Errorf("ai analysis failed: %w", &claude.UsageError{Usage: claude.Usage{CostUSD: 0.03}, Err: errors.New("bad json")})
+}
+
+// failedCalls records the failed ai calls it is asked to persist
+type failedCalls struct {
+	logged []claude.Usage
+}
+
+func (f *failedCalls) LogFailedAICall(_ context.Context, _ int, _ string, u claude.Usage, _ error) {
+	f.logged = append(f.logged, u)
+}
+
+func TestSharedAnalyzerBudgetChargesFailedCalls(t *testing.T) {
+	budget := &mockBudget{spent: map[int]float64{}}
+	failed := &failedCalls{}
+	shared := NewSharedAnalyzer(&unparseableAnalyzer{}, "4h", time.Minute)
+	shared.SetBudget(budget)
+	shared.SetFailedCallLogger(failed)
+
+	if _, err := shared.AnalyzeFor(context.Background(), 1, "SOL/USDT"); err == nil {
+		t.Fatal("expected the ai error")
+	}
+	if budget.spent[1] != 0.03 {
+		t.Errorf("a billed failure should be charged, got %v", budget.spent)
+	}
+	if len(failed.logged) != 1 || failed.logged[0].CostUSD != 0.03 {
+		t.Errorf("a billed failure should be persisted, got %+v", failed.logged)
+	}
+}
+
+// evenOddPrompts puts even users on v2 and everyone else on the default
+type evenOddPrompts struct{}
+
+func (evenOddPrompts) Prompt(userID int, _ string) *claude.PromptVariant {
+	if userID%2 == 0 {
+		return &claude.PromptVariant{Experiment: "terse", Version: "v2", System: "Answer in JSON."}
+	}
+	return nil
+}
+
+func TestSharedAnalyzerPromptVariants(t *testing.T) {
+	inner := &stagedAnalyzer{}
+	shared := NewSharedAnalyzer(inner, "4h", time.Minute)
+	shared.SetPrompts(evenOddPrompts{})
+	ctx := context.Background()
+
+	results := make(map[int]*Result)
+	for _, user := range []int{1, 2, 3, 4} {
+		r, err := shared.AnalyzeFor(ctx, user, "ETH/USDT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[user] = r
+	}
+
+	if n := inner.prepares.Load(); n != 1 {
+		t.Errorf("market data should be shared across variants, got %d runs", n)
+	}
+	if n := inner.decides.Load(); n != 2 {
+		t.Errorf("expected one decision per prompt variant, got %d", n)
+	}
+	if results[2].Decision.PromptVersion != "v2" || results[4].Decision != results[2].Decision {
+		t.Error("users on v2 should share the v2 decision")
+	}
+	if results[1].Decision.PromptVersion != "" || results[3].Decision != results[1].Decision {
+		t.Error("users on the default prompt should share the default decision")
+	}
+}
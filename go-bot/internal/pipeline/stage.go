@@ -0,0 +1,200 @@
+// analysis stages — every data source feeding claude is a stage. stages
+// declare the outputs they read and write, run concurrently once their inputs
+// are ready, and add their outputs to the prompt. new sources are added with
+// Pipeline.AddStage without touching Analyze.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/exchange"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// ErrSkipStage is returned by a stage with nothing to do, e.g. when its
+// provider isn't configured. The stage is reported as skipped, not failed.
+var ErrSkipStage = errors.New("stage skipped")
+
+// Stage is one step of the analysis.
+type Stage struct {
+	Name     string
+	Needs    []string      // output keys read from other stages; the stage starts once their producers finish
+	Provides []string      // output keys written with StageContext.Set
+	Timeout  time.Duration // 0 = bounded only by the analysis context
+	Required bool          // an error fails the whole analysis instead of being recorded
+
+	// PointInTime stages rebuild their outputs from candles alone, so
+	// BuildInput runs them when replaying past bars. They read
+	// StageContext.History instead of live sources when it's set.
+	PointInTime bool
+
+	Run func(ctx context.Context, sc *StageContext) error
+
+	// Prompt adds the stage's outputs to claude's input. Optional; called
+	// after all stages finish, in registration order, whether or not the
+	// stage succeeded.
+	Prompt func(sc *StageContext, input *claude.AnalysisInput)
+}
+
+// StageReport is how a stage went in one analysis.
+type StageReport struct {
+	Name    string
+	Latency time.Duration
+	Skipped bool
+	Err     string // empty on success
+}
+
+// StageContext carries the market data every stage starts from and the
+// outputs stages share. Safe for concurrent use.
+type StageContext struct {
+	Symbol    string
+	Timeframe string
+	Ticker    *exchange.Ticker
+	Candles   []exchange.Candle // primary timeframe, oldest first
+	MLCandles []mlclient.Candle // Candles converted for the ml service
+	History   []exchange.Candle // every candle up to a replayed bar, oldest first; nil when live
+
+	mu      sync.RWMutex
+	outputs map[string]any
+}
+
+func newStageContext(symbol, timeframe string, ticker *exchange.Ticker, candles []exchange.Candle) *StageContext {
+	return &StageContext{
+		Symbol:    symbol,
+		Timeframe: timeframe,
+		Ticker:    ticker,
+		Candles:   candles,
+		MLCandles: exchangeToMLCandles(candles),
+		outputs:   make(map[string]any),
+	}
+}
+
+// Set stores an output under a key the stage declared in Provides.
+func (sc *StageContext) Set(key string, value any) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.outputs[key] = value
+}
+
+// Get returns an output, or nil when its stage failed, was skipped or set nothing.
+func (sc *StageContext) Get(key string) any {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.outputs[key]
+}
+
+// stageOutput reads a typed output, returning the zero value when it's missing
+func stageOutput[T any](sc *StageContext, key string) T {
+	v, _ := sc.Get(key).(T)
+	return v
+}
+
+// AddStage registers a stage after the existing ones. Every key it needs must
+// be provided by a stage registered before it, which also rules out cycles.
+func (p *Pipeline) AddStage(s Stage) error {
+	if s.Name == "" || s.Run == nil {
+		return fmt.Errorf("stage needs a name and a run function")
+	}
+	provided := make(map[string]bool)
+	for _, existing := range p.stages {
+		if existing.Name == s.Name {
+			return fmt.Errorf("stage %q is already registered", s.Name)
+		}
+		for _, k := range existing.Provides {
+			provided[k] = true
+		}
+	}
+	for _, k := range s.Needs {
+		if !provided[k] {
+			return fmt.Errorf("stage %q needs %q, which no earlier stage provides", s.Name, k)
+		}
+	}
+	for _, k := range s.Provides {
+		if provided[k] {
+			return fmt.Errorf("stage %q provides %q, which another stage already provides", s.Name, k)
+		}
+	}
+	p.stages = append(p.stages, s)
+	return nil
+}
+
+// Stages returns the registered stage names in order.
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, s := range p.stages {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// runStages runs every stage as soon as the stages it needs have finished.
+// Needs without a producer among stages, e.g. live-only outputs in a replay,
+// aren't waited on. Returns an error only when a required stage fails.
+func runStages(ctx context.Context, stages []Stage, sc *StageContext) ([]StageReport, error) {
+	finished := make([]chan struct{}, len(stages))
+	producers := make(map[string]chan struct{})
+	for i, s := range stages {
+		finished[i] = make(chan struct{})
+		for _, k := range s.Provides {
+			producers[k] = finished[i]
+		}
+	}
+
+	reports := make([]StageReport, len(stages))
+	errs := make([]error, len(stages))
+	var wg sync.WaitGroup
+	for i := range stages {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(finished[i])
+			s := stages[i]
+			for _, k := range s.Needs {
+				done, ok := producers[k]
+				if !ok {
+					continue
+				}
+				select {
+				case <-done:
+				case <-ctx.Done():
+				}
+			}
+			reports[i], errs[i] = runStage(ctx, s, sc)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, s := range stages {
+		if s.Required && errs[i] != nil {
+			return reports, fmt.Errorf("%s stage failed: %w", s.Name, errs[i])
+		}
+	}
+	return reports, nil
+}
+
+// runStage runs one stage within its timeout
+func runStage(ctx context.Context, s Stage, sc *StageContext) (StageReport, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := s.Run(ctx, sc)
+	report := StageReport{Name: s.Name, Latency: time.Since(start)}
+	switch {
+	case errors.Is(err, ErrSkipStage):
+		report.Skipped = true
+		return report, nil
+	case err != nil:
+		report.Err = err.Error()
+		return report, err
+	}
+	return report, nil
+}
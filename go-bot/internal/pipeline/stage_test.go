@@ -0,0 +1,178 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+func newStageTestPipeline(ai *mockAI) *Pipeline {
+	return New(&mockExchange{ticker: testTicker(), candles: testCandles(100)}, &mockIndicators{result: testIndicators()}, nil, ai)
+}
+
+func TestPipelineCustomStage(t *testing.T) {
+	ai := &mockAI{decision: testDecision()}
+	p := newStageTestPipeline(ai)
+
+	err := p.AddStage(Stage{
+		Name:     "rsi_bucket",
+		Needs:    []string{OutputIndicators},
+		Provides: []string{"rsi_bucket"},
+		Run: func(_ context.Context, sc *StageContext) error {
+			ind := stageOutput[*analysis.AnalysisResult](sc, OutputIndicators)
+			if ind == nil {
+				return errors.New("indicators missing")
+			}
+			sc.Set("rsi_bucket", fmt.Sprintf("%d-%d", int(ind.RSI.Value)/10*10, int(ind.RSI.Value)/10*10+10))
+			return nil
+		},
+		Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+			if b, ok := sc.Get("rsi_bucket").(string); ok {
+				input.Sections = append(input.Sections, claude.PromptSection{Title: "RSI Bucket", Body: b})
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := p.Analyze(context.Background(), "BTC/USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ai.input.Sections) != 1 || ai.input.Sections[0].Body != "30-40" {
+		t.Errorf("expected the stage's prompt section after its input was ready, got %+v", ai.input.Sections)
+	}
+	if ai.input.Indicators == nil || ai.input.Regime == nil {
+		t.Error("built-in stages should still fill the ai input")
+	}
+
+	reports := make(map[string]StageReport)
+	for _, r := range result.Stages {
+		reports[r.Name] = r
+	}
+	if len(result.Stages) != len(p.Stages()) {
+		t.Errorf("expected a report per stage, got %d for %v", len(result.Stages), p.Stages())
+	}
+	if r := reports["rsi_bucket"]; r.Err != "" || r.Skipped {
+		t.Errorf("custom stage report = %+v", r)
+	}
+	if !reports["prediction"].Skipped || !reports["rl"].Skipped {
+		t.Error("stages without a provider should be reported as skipped")
+	}
+}
+
+func TestPipelineStageFailures(t *testing.T) {
+	failing := func(required bool) Stage {
+		return Stage{
+			Name:     "options",
+			Provides: []string{"options"},
+			Required: required,
+			Run: func(context.Context, *StageContext) error {
+				return errors.New("deribit down")
+			},
+		}
+	}
+
+	t.Run("optional", func(t *testing.T) {
+		ai := &mockAI{decision: testDecision()}
+		p := newStageTestPipeline(ai)
+		if err := p.AddStage(failing(false)); err != nil {
+			t.Fatal(err)
+		}
+		result, err := p.Analyze(context.Background(), "BTC/USDT")
+		if err != nil {
+			t.Fatalf("an optional stage shouldn't fail the analysis: %v", err)
+		}
+		if got := result.Errors[len(result.Errors)-1]; got != "options: deribit down" {
+			t.Errorf("last error = %q", got)
+		}
+		if ai.input == nil {
+			t.Error("claude should still be asked")
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		ai := &mockAI{decision: testDecision()}
+		p := newStageTestPipeline(ai)
+		if err := p.AddStage(failing(true)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := p.Analyze(context.Background(), "BTC/USDT")
+		if err == nil || !strings.Contains(err.Error(), "options stage failed") {
+			t.Fatalf("expected the required stage to fail the analysis, got %v", err)
+		}
+		if ai.input != nil {
+			t.Error("claude shouldn't be asked after a required stage failed")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		p := newStageTestPipeline(&mockAI{decision: testDecision()})
+		err := p.AddStage(Stage{
+			Name:    "slow",
+			Timeout: 20 * time.Millisecond,
+			Run: func(ctx context.Context, _ *StageContext) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := p.Analyze(context.Background(), "BTC/USDT")
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := result.Stages[len(result.Stages)-1]
+		if last.Name != "slow" || !strings.Contains(last.Err, "deadline") {
+			t.Errorf("expected the slow stage to time out, got %+v", last)
+		}
+	})
+}
+
+func TestAddStageValidation(t *testing.T) {
+	run := func(context.Context, *StageContext) error { return nil }
+	tests := []struct {
+		name  string
+		stage Stage
+		want  string
+	}{
+		{"duplicate name", Stage{Name: "indicators", Run: run}, "already registered"},
+		{"unknown input", Stage{Name: "x", Needs: []string{"options"}, Run: run}, "no earlier stage provides"},
+		{"duplicate output", Stage{Name: "x", Provides: []string{OutputSentiment}, Run: run}, "already provides"},
+		{"no run function", Stage{Name: "x"}, "run function"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newStageTestPipeline(&mockAI{})
+			err := p.AddStage(tt.stage)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+type mockEvents []claude.CalendarEvent
+
+func (m mockEvents) UpcomingEvents(string) []claude.CalendarEvent { return m }
+
+func TestPipelineEventsStage(t *testing.T) {
+	ai := &mockAI{decision: testDecision()}
+	p := newStageTestPipeline(ai)
+	p.SetEvents(mockEvents{{Title: "FOMC", Importance: "high", HoursAway: 3}})
+
+	if _, err := p.Analyze(context.Background(), "BTC/USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ai.input.Events) != 1 || ai.input.Events[0].Title != "FOMC" {
+		t.Errorf("expected the upcoming event in claude's input, got %+v", ai.input.Events)
+	}
+}
@@ -0,0 +1,251 @@
+// built-in analysis stages — indicators, ml prediction and sentiment, chart
+// patterns, the rl second opinion, alternative data, higher-timeframe
+// context, btc and total-market context, calendar events, market structure
+// and trade history.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/analysis"
+	"github.com/trading-bot/go-bot/internal/claude"
+	mlclient "github.com/trading-bot/go-bot/internal/ml-client"
+)
+
+// output keys of the built-in stages
+const (
+	OutputIndicators    = "indicators"     // *analysis.AnalysisResult
+	OutputPrediction    = "prediction"     // *mlclient.PricePredictionResponse
+	OutputEnsemble      = "ensemble"       // *mlclient.EnsemblePredictionResponse, when the ensemble answered
+	OutputSentiment     = "sentiment"      // *mlclient.SentimentResponse
+	OutputPatterns      = "patterns"       // *mlclient.PatternDetectResponse
+	OutputRL            = "rl"             // *mlclient.RLActionResponse
+	OutputAltData       = "alt_data"       // *claude.AltData
+	OutputHTF           = "htf"            // []claude.HTFSnapshot
+	OutputMarketContext = "market_context" // *claude.MarketContext, for non-BTC symbols
+	OutputEvents        = "events"         // []claude.CalendarEvent
+	OutputStructure     = "structure"      // *claude.MarketStructure
+	OutputTradeHistory  = "trade_history"  // []claude.TradeOutcome
+)
+
+// advisory stages (patterns, rl) are extras, so they get less time than the analysis
+const advisoryTimeout = 5 * time.Second
+
+// defaultStages returns the built-in stages. Providers are read when a stage
+// runs, so setters called after New take effect.
+func (p *Pipeline) defaultStages() []Stage {
+	return []Stage{
+		{
+			Name:        "indicators",
+			Provides:    []string{OutputIndicators},
+			PointInTime: true,
+			Run: func(ctx context.Context, sc *StageContext) error {
+				var ind *analysis.AnalysisResult
+				var err error
+				if sc.History != nil {
+					// streaming state is live, a replay reads only the window
+					ind, err = p.indicators.AnalyzeAll(ctx, exchangeToAnalysisCandles(sc.Candles), nil)
+				} else {
+					ind, err = p.analyzeIndicators(ctx, sc.Symbol, sc.Timeframe, sc.Candles, exchangeToAnalysisCandles(sc.Candles))
+				}
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputIndicators, ind)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				ind := stageOutput[*analysis.AnalysisResult](sc, OutputIndicators)
+				input.Indicators = toClaudeIndicators(ind)
+				input.Regime = regimeInfo(ind, sc.Candles, sc.Ticker.Price)
+			},
+		},
+		{
+			Name:     "prediction",
+			Provides: []string{OutputPrediction, OutputEnsemble},
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.ml == nil || !p.ml.IsAvailable(ctx) {
+					return ErrSkipStage
+				}
+				pred, ensemble, err := p.predict(ctx, sc.Symbol, sc.MLCandles)
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputPrediction, pred)
+				if ensemble != nil {
+					sc.Set(OutputEnsemble, ensemble)
+				}
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Prediction = toClaudePrediction(stageOutput[*mlclient.PricePredictionResponse](sc, OutputPrediction))
+				if ensemble := stageOutput[*mlclient.EnsemblePredictionResponse](sc, OutputEnsemble); input.Prediction != nil && ensemble != nil {
+					addEnsembleVotes(input.Prediction, ensemble)
+				}
+			},
+		},
+		{
+			Name:     "sentiment",
+			Provides: []string{OutputSentiment},
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.ml == nil || !p.ml.IsAvailable(ctx) {
+					return ErrSkipStage
+				}
+				text := fmt.Sprintf("%s price %.2f 24h change %.2f%%", sc.Symbol, sc.Ticker.Price, sc.Ticker.ChangePct)
+				sentiment, err := p.ml.AnalyzeSentiment(ctx, text)
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputSentiment, sentiment)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Sentiment = toClaudeSentiment(stageOutput[*mlclient.SentimentResponse](sc, OutputSentiment))
+			},
+		},
+		{
+			Name:     "patterns",
+			Provides: []string{OutputPatterns},
+			Timeout:  advisoryTimeout,
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.patterns == nil || len(sc.MLCandles) < minPatternCandles {
+					return ErrSkipStage
+				}
+				patterns, err := p.patterns.DetectPatterns(ctx, &mlclient.PatternDetectRequest{Symbol: sc.Symbol, Candles: sc.MLCandles})
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputPatterns, patterns)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Patterns = toChartPatterns(stageOutput[*mlclient.PatternDetectResponse](sc, OutputPatterns))
+			},
+		},
+		{
+			Name:     "rl",
+			Provides: []string{OutputRL},
+			Timeout:  advisoryTimeout,
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.rl == nil || len(sc.MLCandles) < minPatternCandles {
+					return ErrSkipStage
+				}
+				action, err := p.rl.GetRLAction(ctx, &mlclient.RLActionRequest{Candles: sc.MLCandles, Balance: rlBalance})
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputRL, action)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Second = secondOpinion(stageOutput[*mlclient.RLActionResponse](sc, OutputRL))
+			},
+		},
+		{
+			Name:     "alt_data",
+			Provides: []string{OutputAltData},
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.altData == nil {
+					return ErrSkipStage
+				}
+				if alt := p.altData.Fetch(ctx, sc.Symbol); alt != nil {
+					sc.Set(OutputAltData, alt)
+				}
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.AltData = stageOutput[*claude.AltData](sc, OutputAltData)
+			},
+		},
+		{
+			Name:        "htf",
+			Provides:    []string{OutputHTF},
+			PointInTime: true,
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if len(p.timeframes) < 2 {
+					return ErrSkipStage
+				}
+				if sc.History != nil {
+					sc.Set(OutputHTF, p.resampledHTFContext(ctx, sc.History))
+					return nil
+				}
+				sc.Set(OutputHTF, p.fetchHTFContext(ctx, sc.Symbol))
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.HTFContext = stageOutput[[]claude.HTFSnapshot](sc, OutputHTF)
+			},
+		},
+		{
+			Name:     "market_context",
+			Provides: []string{OutputMarketContext},
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.marketContext == nil || isBTC(sc.Symbol) {
+					return ErrSkipStage
+				}
+				mc, err := p.marketContext.For(ctx, sc.Symbol, sc.Candles)
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputMarketContext, mc)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.MarketContext = stageOutput[*claude.MarketContext](sc, OutputMarketContext)
+			},
+		},
+		{
+			Name:     "events",
+			Provides: []string{OutputEvents},
+			Run: func(_ context.Context, sc *StageContext) error {
+				if p.events == nil {
+					return ErrSkipStage
+				}
+				if events := p.events.UpcomingEvents(sc.Symbol); len(events) > 0 {
+					sc.Set(OutputEvents, events)
+				}
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Events = stageOutput[[]claude.CalendarEvent](sc, OutputEvents)
+			},
+		},
+		{
+			Name:        "structure",
+			Needs:       []string{OutputAltData},
+			Provides:    []string{OutputStructure},
+			PointInTime: true,
+			Run: func(_ context.Context, sc *StageContext) error {
+				if ms := buildStructure(sc.Candles, sc.Ticker.Price, stageOutput[*claude.AltData](sc, OutputAltData)); ms != nil {
+					sc.Set(OutputStructure, ms)
+				}
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				input.Structure = stageOutput[*claude.MarketStructure](sc, OutputStructure)
+			},
+		},
+		{
+			Name:     "trade_history",
+			Provides: []string{OutputTradeHistory},
+			Run: func(ctx context.Context, sc *StageContext) error {
+				if p.tradeHistory == nil {
+					return ErrSkipStage
+				}
+				outcomes, err := p.tradeHistory.RecentOutcomes(ctx, 10)
+				if err != nil {
+					return err
+				}
+				sc.Set(OutputTradeHistory, outcomes)
+				return nil
+			},
+			Prompt: func(sc *StageContext, input *claude.AnalysisInput) {
+				if outcomes := stageOutput[[]claude.TradeOutcome](sc, OutputTradeHistory); len(outcomes) > 0 {
+					input.TradeHistory = outcomes
+				}
+			},
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package prompts
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+// what an experiment assigns to variants
+const (
+	UnitUser   = "user"
+	UnitSymbol = "symbol"
+)
+
+// Variant is a prompt version and its share of the experiment's traffic.
+type Variant struct {
+	Version string
+	Weight  int
+}
+
+// ParseVariants reads "version=weight" entries, e.g. "v1=50"; a bare
+// version has weight 1.
+func ParseVariants(entries []string) ([]Variant, error) {
+	variants := make([]Variant, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		version, weight, hasWeight := strings.Cut(strings.TrimSpace(e), "=")
+		version = strings.TrimSpace(version)
+		v := Variant{Version: version, Weight: 1}
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("variant %q must look like version=weight with a positive weight", e)
+			}
+			v.Weight = w
+		}
+		if !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("variant %q has an invalid version", e)
+		}
+		if seen[version] {
+			return nil, fmt.Errorf("variant %s is listed twice", version)
+		}
+		seen[version] = true
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+// Experiment splits users or symbols between prompt variants. The first
+// variant is the control the others are compared against.
+type Experiment struct {
+	Name     string
+	Unit     string // UnitUser or UnitSymbol
+	Variants []Variant
+}
+
+// Assign returns the variant for a user's analysis of a symbol, or "" when
+// the analysis isn't part of the experiment (no user in a per-user one).
+// The same unit always lands on the same variant.
+func (e *Experiment) Assign(userID int, symbol string) string {
+	var key string
+	switch e.Unit {
+	case UnitUser:
+		if userID == 0 {
+			return ""
+		}
+		key = "user:" + strconv.Itoa(userID)
+	case UnitSymbol:
+		key = "symbol:" + symbol
+	default:
+		return ""
+	}
+
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return ""
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + "|" + key))
+	bucket := int(h.Sum32() % uint32(total))
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v.Version
+		}
+		bucket -= v.Weight
+	}
+	return ""
+}
+
+// Selector hands out system prompts: the experiment's variant for analyses
+// in it, the default version for everything else.
+type Selector struct {
+	library    *Library
+	defaultVer string
+	experiment *Experiment // nil = no experiment running
+}
+
+// NewSelector checks that the default and every variant exist in the library.
+func NewSelector(library *Library, defaultVersion string, experiment *Experiment) (*Selector, error) {
+	if _, ok := library.Get(defaultVersion); !ok {
+		return nil, fmt.Errorf("default prompt version %q not found (have %v)", defaultVersion, library.Versions())
+	}
+	if experiment != nil {
+		if experiment.Name == "" {
+			return nil, fmt.Errorf("prompt experiment needs a name")
+		}
+		if experiment.Unit != UnitUser && experiment.Unit != UnitSymbol {
+			return nil, fmt.Errorf("prompt experiment unit must be %s or %s, got %q", UnitUser, UnitSymbol, experiment.Unit)
+		}
+		if len(experiment.Variants) < 2 {
+			return nil, fmt.Errorf("prompt experiment %s needs at least two variants", experiment.Name)
+		}
+		for _, v := range experiment.Variants {
+			if _, ok := library.Get(v.Version); !ok {
+				return nil, fmt.Errorf("prompt experiment %s: version %q not found (have %v)", experiment.Name, v.Version, library.Versions())
+			}
+		}
+	}
+	return &Selector{library: library, defaultVer: defaultVersion, experiment: experiment}, nil
+}
+
+// Experiment returns the running experiment, or nil.
+func (s *Selector) Experiment() *Experiment {
+	return s.experiment
+}
+
+// Prompt returns the variant for a user's analysis of a symbol. Nil means
+// the built-in prompt outside any experiment, so nothing changes for
+// installs that never set one up.
+func (s *Selector) Prompt(userID int, symbol string) *claude.PromptVariant {
+	if s.experiment != nil {
+		if version := s.experiment.Assign(userID, symbol); version != "" {
+			t, _ := s.library.Get(version)
+			t.Experiment = s.experiment.Name
+			return &t
+		}
+	}
+	if s.defaultVer == claude.DefaultPromptVersion {
+		return nil
+	}
+	t, _ := s.library.Get(s.defaultVer)
+	return &t
+}
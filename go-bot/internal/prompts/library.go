@@ -0,0 +1,80 @@
+// prompt experiments — versioned system prompt templates kept as text files,
+// deterministic assignment of users or symbols to prompt variants, and the
+// report that compares how each variant's decisions played out.
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+// versionPattern keeps versions usable as file names and log labels
+var versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Library holds the system prompt templates by version: the built-in one
+// plus every <version>.txt in the prompt directory.
+type Library struct {
+	templates map[string]claude.PromptVariant
+}
+
+// NewLibrary returns a library with only the built-in prompt.
+func NewLibrary() *Library {
+	builtin := claude.DefaultPrompt()
+	return &Library{templates: map[string]claude.PromptVariant{builtin.Version: builtin}}
+}
+
+// Load reads the templates in dir on top of the built-in prompt. A missing
+// directory leaves only the built-in prompt. Templates are immutable once
+// decisions reference them — change a prompt by adding a new version.
+func Load(dir string) (*Library, error) {
+	lib := NewLibrary()
+	if dir == "" {
+		return lib, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	for _, path := range paths {
+		version := strings.TrimSuffix(filepath.Base(path), ".txt")
+		if !versionPattern.MatchString(version) {
+			return nil, fmt.Errorf("prompt template %s: invalid version %q", path, version)
+		}
+		if version == claude.DefaultPromptVersion {
+			return nil, fmt.Errorf("prompt template %s: %s is the built-in prompt, use a new version", path, version)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		system := strings.TrimSpace(string(data))
+		if system == "" {
+			return nil, fmt.Errorf("prompt template %s is empty", path)
+		}
+		lib.templates[version] = claude.PromptVariant{Version: version, System: system}
+	}
+	return lib, nil
+}
+
+// Get returns the template for a version.
+func (l *Library) Get(version string) (claude.PromptVariant, bool) {
+	t, ok := l.templates[version]
+	return t, ok
+}
+
+// Versions lists the available versions in order.
+func (l *Library) Versions() []string {
+	versions := make([]string, 0, len(l.templates))
+	for v := range l.templates {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
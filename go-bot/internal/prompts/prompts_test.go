@@ -0,0 +1,180 @@
+package prompts
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "v2-terse.txt"), []byte("  Answer in JSON.\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("not a template"), 0o644)
+
+	lib, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lib.Versions(); len(got) != 2 || got[0] != "v1" || got[1] != "v2-terse" {
+		t.Errorf("versions = %v, want [v1 v2-terse]", got)
+	}
+	if v, ok := lib.Get("v2-terse"); !ok || v.System != "Answer in JSON." || v.Version != "v2-terse" {
+		t.Errorf("unexpected template %+v", v)
+	}
+
+	if lib, err := Load(filepath.Join(dir, "missing")); err != nil || len(lib.Versions()) != 1 {
+		t.Errorf("a missing directory should leave the built-in prompt, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "v1.txt"), []byte("override"), 0o644)
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "built-in") {
+		t.Errorf("expected the built-in version to be protected, got %v", err)
+	}
+}
+
+func TestParseVariants(t *testing.T) {
+	variants, err := ParseVariants([]string{"v1=3", " v2 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != 2 || variants[0] != (Variant{"v1", 3}) || variants[1] != (Variant{"v2", 1}) {
+		t.Errorf("unexpected variants %+v", variants)
+	}
+	for _, bad := range [][]string{{"v1=0"}, {"v1=x"}, {"=5"}, {"v1", "v1=2"}, {"../v1"}} {
+		if _, err := ParseVariants(bad); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+}
+
+func TestAssign(t *testing.T) {
+	exp := &Experiment{Name: "terse", Unit: UnitUser, Variants: []Variant{{"v1", 1}, {"v2", 3}}}
+
+	counts := map[string]int{}
+	for user := 1; user <= 4000; user++ {
+		v := exp.Assign(user, "BTC/USDT")
+		if v != exp.Assign(user, "ETH/USDT") {
+			t.Fatalf("user %d should keep the variant across symbols", user)
+		}
+		counts[v]++
+	}
+	if share := float64(counts["v2"]) / 4000; math.Abs(share-0.75) > 0.03 {
+		t.Errorf("v2 should get ~75%% of users, got %.1f%% (%v)", share*100, counts)
+	}
+	if exp.Assign(0, "BTC/USDT") != "" {
+		t.Error("analyses without a user aren't part of a per-user experiment")
+	}
+
+	bySymbol := &Experiment{Name: "terse", Unit: UnitSymbol, Variants: exp.Variants}
+	if bySymbol.Assign(1, "SOL/USDT") != bySymbol.Assign(2, "SOL/USDT") {
+		t.Error("every user should get the symbol's variant")
+	}
+}
+
+func TestSelector(t *testing.T) {
+	lib := NewLibrary()
+	lib.templates["v2"] = claude.PromptVariant{Version: "v2", System: "Answer in JSON."}
+
+	plain, err := NewSelector(lib, "v1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Prompt(1, "BTC/USDT") != nil {
+		t.Error("no experiment on the built-in default should leave prompts alone")
+	}
+
+	exp := &Experiment{Name: "terse", Unit: UnitSymbol, Variants: []Variant{{"v1", 1}, {"v2", 1}}}
+	sel, err := NewSelector(lib, "v1", exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, symbol := range []string{"BTC/USDT", "ETH/USDT", "SOL/USDT", "XRP/USDT"} {
+		p := sel.Prompt(1, symbol)
+		if p == nil || p.Experiment != "terse" || p.Version != exp.Assign(1, symbol) {
+			t.Errorf("%s: expected the assigned variant, got %+v", symbol, p)
+		}
+	}
+
+	if _, err := NewSelector(lib, "v3", nil); err == nil {
+		t.Error("expected an unknown default version to fail")
+	}
+	bad := &Experiment{Name: "terse", Unit: UnitUser, Variants: []Variant{{"v1", 1}, {"v3", 1}}}
+	if _, err := NewSelector(lib, "v1", bad); err == nil || !strings.Contains(err.Error(), "v3") {
+		t.Errorf("expected the unknown variant to be named, got %v", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	// I_0.5(a, a) = 0.5 by symmetry, I_x(1, 1) = x
+	if got := regIncBeta(0.5, 3, 3); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("I_0.5(3,3) = %v, want 0.5", got)
+	}
+	if got := regIncBeta(0.3, 1, 1); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("I_0.3(1,1) = %v, want 0.3", got)
+	}
+	// student's t with 10 degrees of freedom: |t| > 2.228 has p = 0.05
+	if p := regIncBeta(10/(10+2.228*2.228), 5, 0.5); math.Abs(p-0.05) > 1e-3 {
+		t.Errorf("p(|t|>2.228, df=10) = %v, want 0.05", p)
+	}
+	// 60/100 vs 40/100 wins: z = 2.83, p = 0.0047
+	if p := twoProportionP(60, 100, 40, 100); math.Abs(p-0.0047) > 2e-4 {
+		t.Errorf("two-proportion p = %v, want 0.0047", p)
+	}
+	if p := welchP([]float64{1, 2, 3}, []float64{1, 2, 3}); math.Abs(p-1) > 1e-9 {
+		t.Errorf("identical samples should have p = 1, got %v", p)
+	}
+	if p := welchP([]float64{1}, []float64{5, 6}); p != 1 {
+		t.Errorf("too few samples should have p = 1, got %v", p)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var outcomes []Outcome
+	for i := 0; i < 40; i++ {
+		// control: coin flips at 70% confidence
+		move := 1.0
+		if i%2 == 1 {
+			move = -1
+		}
+		outcomes = append(outcomes, Outcome{Version: "v1", Action: "BUY", Confidence: 70, MovePct: move})
+		// challenger: sells that mostly work, honest 80% confidence
+		move = -2
+		if i%5 == 0 {
+			move = 1
+		}
+		outcomes = append(outcomes, Outcome{Version: "v2", Action: "SELL", Confidence: 80, MovePct: move})
+	}
+	outcomes = append(outcomes, Outcome{Version: "v2", Action: "HOLD", Confidence: 40, MovePct: 5})
+
+	rep := Compare(outcomes, []string{"v1", "v2"}, 0.2)
+	if rep.Control != "v1" || len(rep.Variants) != 2 || len(rep.Comparisons) != 1 {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+
+	control, challenger := rep.Variants[0], rep.Variants[1]
+	if control.Trades != 40 || control.WinRate() != 0.5 || math.Abs(control.Expectancy+0.2) > 1e-9 {
+		t.Errorf("control = %+v, want 40 trades, 50%% wins, -0.2%% expectancy after costs", control)
+	}
+	if challenger.Decisions != 41 || challenger.Trades != 40 || challenger.WinRate() != 0.8 {
+		t.Errorf("challenger = %+v, want 41 decisions, 40 trades, 80%% wins", challenger)
+	}
+	if math.Abs(control.CalibrationGap()-20) > 1e-9 || math.Abs(challenger.CalibrationGap()) > 1e-9 {
+		t.Errorf("calibration gaps = %.1f / %.1f, want 20 / 0", control.CalibrationGap(), challenger.CalibrationGap())
+	}
+
+	c := rep.Comparisons[0]
+	if c.Version != "v2" || math.Abs(c.WinRateDiff-30) > 1e-9 || c.WinRateP > 0.01 || c.ExpectancyP > 0.01 {
+		t.Errorf("expected v2 to win significantly, got %+v", c)
+	}
+	if !c.Significant(0.05) || c.BrierDiff >= 0 {
+		t.Errorf("expected v2 better calibrated, got %+v", c)
+	}
+
+	if rep := Compare(outcomes, []string{"v2"}, 0); rep.Control != "v2" || rep.Variants[1].Version != "v1" {
+		t.Errorf("unlisted versions should follow the listed ones, got %s first", rep.Control)
+	}
+}
@@ -0,0 +1,142 @@
+package prompts
+
+import "sort"
+
+// Outcome is a logged decision and what the price did after it.
+type Outcome struct {
+	Version    string
+	Action     string  // BUY, SELL or HOLD
+	Confidence float64 // 0-100
+	MovePct    float64 // price change over the horizon
+}
+
+// VariantStats is how one prompt version's decisions played out. Only BUY
+// and SELL decisions are trades; a trade wins when it moved the right way
+// by more than the round-trip cost.
+type VariantStats struct {
+	Version       string
+	Decisions     int
+	Trades        int
+	Wins          int
+	Expectancy    float64 // mean return per trade in %, net of costs
+	AvgConfidence float64 // mean stated confidence of the trades, 0-100
+	Brier         float64 // mean squared error of confidence as a win probability; lower is better calibrated
+
+	returns []float64
+	errors  []float64 // squared calibration error per trade
+}
+
+// WinRate is the share of trades that won, 0-1.
+func (v *VariantStats) WinRate() float64 {
+	if v.Trades == 0 {
+		return 0
+	}
+	return float64(v.Wins) / float64(v.Trades)
+}
+
+// CalibrationGap is stated confidence minus the realized win rate, in
+// points; positive means the variant is overconfident.
+func (v *VariantStats) CalibrationGap() float64 {
+	if v.Trades == 0 {
+		return 0
+	}
+	return v.AvgConfidence - v.WinRate()*100
+}
+
+// Comparison tests a variant against the control. Diffs are variant minus
+// control; p-values are two-sided.
+type Comparison struct {
+	Version        string
+	WinRateDiff    float64 // points
+	WinRateP       float64 // two-proportion z-test
+	ExpectancyDiff float64 // % per trade
+	ExpectancyP    float64 // welch's t-test on per-trade returns
+	BrierDiff      float64
+	BrierP         float64 // welch's t-test on per-trade squared errors
+}
+
+// Significant reports whether any metric differs at the given level.
+func (c Comparison) Significant(alpha float64) bool {
+	return c.WinRateP < alpha || c.ExpectancyP < alpha || c.BrierP < alpha
+}
+
+// Report compares the variants of an experiment.
+type Report struct {
+	Control     string
+	Variants    []*VariantStats // control first
+	Comparisons []Comparison    // every other variant against the control
+}
+
+// Compare scores each version's decisions. versions orders the report and
+// its first entry is the control; versions seen in the outcomes but not
+// listed follow in name order. costPct is the round-trip trading cost taken
+// off every trade's return.
+func Compare(outcomes []Outcome, versions []string, costPct float64) *Report {
+	byVersion := make(map[string]*VariantStats)
+	order := append([]string(nil), versions...)
+	for _, v := range versions {
+		byVersion[v] = &VariantStats{Version: v}
+	}
+	var extra []string
+	for _, o := range outcomes {
+		if _, ok := byVersion[o.Version]; !ok {
+			byVersion[o.Version] = &VariantStats{Version: o.Version}
+			extra = append(extra, o.Version)
+		}
+	}
+	sort.Strings(extra)
+	order = append(order, extra...)
+
+	for _, o := range outcomes {
+		s := byVersion[o.Version]
+		s.Decisions++
+		var ret float64
+		switch o.Action {
+		case "BUY":
+			ret = o.MovePct - costPct
+		case "SELL":
+			ret = -o.MovePct - costPct
+		default:
+			continue
+		}
+		s.Trades++
+		won := 0.0
+		if ret > 0 {
+			s.Wins++
+			won = 1
+		}
+		p := o.Confidence / 100
+		s.returns = append(s.returns, ret)
+		s.errors = append(s.errors, (p-won)*(p-won))
+		s.AvgConfidence += o.Confidence
+	}
+
+	rep := &Report{}
+	for _, v := range order {
+		s := byVersion[v]
+		if s.Trades > 0 {
+			s.AvgConfidence /= float64(s.Trades)
+			s.Expectancy, _ = meanVar(s.returns)
+			s.Brier, _ = meanVar(s.errors)
+		}
+		rep.Variants = append(rep.Variants, s)
+	}
+	if len(rep.Variants) == 0 {
+		return rep
+	}
+
+	control := rep.Variants[0]
+	rep.Control = control.Version
+	for _, s := range rep.Variants[1:] {
+		rep.Comparisons = append(rep.Comparisons, Comparison{
+			Version:        s.Version,
+			WinRateDiff:    (s.WinRate() - control.WinRate()) * 100,
+			WinRateP:       twoProportionP(s.Wins, s.Trades, control.Wins, control.Trades),
+			ExpectancyDiff: s.Expectancy - control.Expectancy,
+			ExpectancyP:    welchP(s.returns, control.returns),
+			BrierDiff:      s.Brier - control.Brier,
+			BrierP:         welchP(s.errors, control.errors),
+		})
+	}
+	return rep
+}
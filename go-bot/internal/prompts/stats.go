@@ -0,0 +1,124 @@
+package prompts
+
+import "math"
+
+// twoProportionP is the two-sided p-value of a z-test that two success
+// rates differ. 1 when either side has no samples.
+func twoProportionP(wins1, n1, wins2, n2 int) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+	pooled := float64(wins1+wins2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 1
+	}
+	z := (float64(wins1)/float64(n1) - float64(wins2)/float64(n2)) / se
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// welchP is the two-sided p-value of Welch's t-test that two samples have
+// different means. 1 when either side has fewer than two samples.
+func welchP(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+	meanA, varA := meanVar(a)
+	meanB, varB := meanVar(b)
+	na, nb := float64(len(a)), float64(len(b))
+	sa, sb := varA/na, varB/nb
+	if sa+sb == 0 {
+		return 1
+	}
+	t := (meanA - meanB) / math.Sqrt(sa+sb)
+	df := (sa + sb) * (sa + sb) / (sa*sa/(na-1) + sb*sb/(nb-1))
+	// P(|T| > t) for student's t with df degrees of freedom
+	return regIncBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// meanVar returns the mean and the sample variance
+func meanVar(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, ss / float64(len(xs)-1)
+}
+
+// regIncBeta is the regularized incomplete beta function I_x(a, b)
+func regIncBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	// the continued fraction converges fast below the mean, use symmetry above it
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(x, a, b) / a
+	}
+	return 1 - front*betaCF(1-x, b, a)/b
+}
+
+// betaCF evaluates the continued fraction for the incomplete beta function
+// by the modified Lentz method
+func betaCF(x, a, b float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	qab, qap, qam := a+b, a+1, a-1
+	c, d := 1.0, 1-qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
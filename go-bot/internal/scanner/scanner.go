@@ -37,6 +37,17 @@ type Analyzer interface {
 	Analyze(ctx context.Context, symbol string) (*pipeline.Result, error)
 }
 
+// an analyzer that can account for the user's open positions
+type UserAnalyzer interface {
+	AnalyzeFor(ctx context.Context, userID int, symbol string) (*pipeline.Result, error)
+}
+
+// reports whether new entries in a symbol are allowed right now
+// (implemented by calendar.Calendar for event blackouts)
+type EntryGuard interface {
+	AllowEntry(symbol string) (bool, string)
+}
+
 // sends notifications to users
 type Notifier interface {
 	NotifyTelegram(chatID int64, message string) error
@@ -89,6 +100,7 @@ type Scanner struct {
 	analyzer    Analyzer
 	notifier    Notifier
 	logger      DecisionLogger // nil = no logging
+	guard      EntryGuard     // nil = no blackouts
 	config      Config
 
 	mu          sync.RWMutex
@@ -130,6 +142,12 @@ func (s *Scanner) SetLogger(logger DecisionLogger) {
 	s.logger = logger
 }
 
+// SetEntryGuard suppresses opportunities for symbols the guard blocks,
+// e.g. during event blackout windows.
+func (s *Scanner) SetEntryGuard(guard EntryGuard) {
+	s.guard = guard
+}
+
 // starts the scanner loop in a goroutine. returns immediately.
 func (s *Scanner) Start(ctx context.Context) {
 	s.mu.Lock()
@@ -272,6 +290,14 @@ func (s *Scanner) runCycle(ctx context.Context) {
 	)
 }
 
+// analyzes a symbol for a user, with their portfolio when the analyzer supports it
+func (s *Scanner) analyze(ctx context.Context, userID int, symbol string) (*pipeline.Result, error) {
+	if ua, ok := s.analyzer.(UserAnalyzer); ok {
+		return ua.AnalyzeFor(ctx, userID, symbol)
+	}
+	return s.analyzer.Analyze(ctx, symbol)
+}
+
 // analyzes one symbol for one user and sends notification if warranted
 func (s *Scanner) analyzeAndNotify(
 	ctx context.Context,
@@ -285,7 +311,15 @@ func (s *Scanner) analyzeAndNotify(
 		return false
 	}
 
-	result, err := s.analyzer.Analyze(ctx, symbol)
+	// no new opportunities during blackouts; skipping also saves the analysis
+	if s.guard != nil {
+		if ok, reason := s.guard.AllowEntry(symbol); !ok {
+			slog.Debug("scanner: symbol in blackout", "symbol", symbol, "user_id", u.ID, "reason", reason)
+			return false
+		}
+	}
+
+	result, err := s.analyze(ctx, u.ID, symbol)
 	if err != nil {
 		slog.Error("scanner: analysis failed", "symbol", symbol, "user_id", u.ID, "error", err)
 		return false
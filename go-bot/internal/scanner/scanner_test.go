@@ -3,6 +3,7 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -779,6 +780,69 @@ func TestScanCycleMultipleUsers(t *testing.T) {
 	}
 }
 
+// userAnalyzer records which users each analysis was run for
+type userAnalyzer struct {
+	mockAnalyzer
+	users []int
+}
+
+func (m *userAnalyzer) AnalyzeFor(ctx context.Context, userID int, symbol string) (*pipeline.Result, error) {
+	m.mu.Lock()
+	m.users = append(m.users, userID)
+	m.mu.Unlock()
+	return m.Analyze(ctx, symbol)
+}
+
+func TestScanCycleAnalyzesPerUser(t *testing.T) {
+	users := []*user.User{testUser(1, 100), testUser(2, 200)}
+	items := map[int][]watchlist.Item{
+		1: {{Symbol: "BTC/USDT", IsActive: true}},
+		2: {{Symbol: "BTC/USDT", IsActive: true}},
+	}
+	s, notifier, _ := testScanner(users, items, nil)
+	analyzer := &userAnalyzer{mockAnalyzer: mockAnalyzer{results: map[string]*pipeline.Result{"BTC/USDT": buyResult("BTC/USDT", 85)}}}
+	s.analyzer = analyzer
+
+	s.runCycle(context.Background())
+
+	sort.Ints(analyzer.users)
+	if len(analyzer.users) != 2 || analyzer.users[0] != 1 || analyzer.users[1] != 2 {
+		t.Errorf("expected an analysis per user, got %v", analyzer.users)
+	}
+	if notifier.count() != 2 {
+		t.Errorf("expected 2 notifications, got %d", notifier.count())
+	}
+}
+
+type blockingGuard map[string]string
+
+func (g blockingGuard) AllowEntry(symbol string) (bool, string) {
+	reason, blocked := g[symbol]
+	return !blocked, reason
+}
+
+func TestScanCycleSkipsBlackout(t *testing.T) {
+	users := []*user.User{testUser(1, 100)}
+	items := map[int][]watchlist.Item{
+		1: {{Symbol: "BTC/USDT", IsActive: true}, {Symbol: "ETH/USDT", IsActive: true}},
+	}
+	results := map[string]*pipeline.Result{
+		"BTC/USDT": buyResult("BTC/USDT", 85),
+		"ETH/USDT": buyResult("ETH/USDT", 85),
+	}
+	s, notifier, analyzer := testScanner(users, items, results)
+	s.SetEntryGuard(blockingGuard{"BTC/USDT": "FOMC (high)"})
+
+	s.runCycle(context.Background())
+
+	if analyzer.callCount() != 1 || analyzer.calls[0] != "ETH/USDT" {
+		t.Errorf("expected only eth to be analyzed, got %v", analyzer.calls)
+	}
+	if notifier.count() != 1 {
+		t.Errorf("expected 1 notification, got %d", notifier.count())
+	}
+}
+
 func TestScanCycleAnalyzerError(t *testing.T) {
 	users := []*user.User{testUser(1, 100)}
 	items := map[int][]watchlist.Item{
@@ -0,0 +1,377 @@
+// market structure detection: swing highs/lows, horizontal support and
+// resistance clustered from swing touches, trendlines through recent swings,
+// break-of-structure / change-of-character events, and liquidity zones from
+// order book walls. gives Claude real price levels to check stops and
+// targets against.
+package structure
+
+import (
+	"math"
+	"sort"
+)
+
+// Candle represents OHLCV price data
+type Candle struct {
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// Wall is an outsized resting order from the order book
+type Wall struct {
+	Side    string // "bid" or "ask"
+	Price   float64
+	SizeUSD float64
+}
+
+// Swing is a confirmed local extreme
+type Swing struct {
+	Index int
+	Price float64
+	High  bool   // true for a swing high, false for a swing low
+	Label string // HH, LH for highs; HL, LL for lows; empty for the first of each
+}
+
+// Level is a horizontal price zone touched by several swings
+type Level struct {
+	Price     float64 // mean of the touches
+	Low       float64 // zone bounds
+	High      float64
+	Touches   int
+	LastIndex int    // most recent touch
+	Kind      string // support or resistance relative to the current price
+}
+
+// Trendline runs through the last two swing highs or lows
+type Trendline struct {
+	Kind   string  // support (through lows) or resistance (through highs)
+	Slope  float64 // price change per bar
+	Value  float64 // projected to the last candle
+	Broken bool    // the last close is on the wrong side
+	From   int     // swing indices the line runs through
+	To     int
+}
+
+// Event is a break of a prior swing. BOS continues the prevailing
+// structure, CHOCH breaks against it.
+type Event struct {
+	Type      string // BOS or CHOCH
+	Direction string // bullish or bearish
+	Price     float64
+	Index     int
+}
+
+// LiquidityZone is an order book wall positioned against the current price
+type LiquidityZone struct {
+	Price   float64
+	SizeUSD float64
+	Kind    string // support (bids below) or resistance (asks above)
+}
+
+// Analysis is the full structure read for a candle series
+type Analysis struct {
+	Trend          string // bullish, bearish or ranging
+	Swings         []Swing
+	Levels         []Level // ascending by price
+	Support        *Level  // nearest level below the price
+	Resistance     *Level  // nearest level above the price
+	SupportLine    *Trendline
+	ResistanceLine *Trendline
+	Events         []Event
+	LastEvent      *Event
+	Liquidity      []LiquidityZone // nearest first
+}
+
+// Config tunes detection
+type Config struct {
+	SwingStrength int     // bars on each side a swing must dominate
+	ClusterPct    float64 // swings within this % of each other form one level
+	MinTouches    int     // touches needed for a level
+}
+
+// DefaultConfig returns the settings used by Analyze
+func DefaultConfig() Config {
+	return Config{
+		SwingStrength: 2,
+		ClusterPct:    0.5,
+		MinTouches:    2,
+	}
+}
+
+// Analyze reads structure with the default config
+func Analyze(candles []Candle, price float64, walls []Wall) *Analysis {
+	return DefaultConfig().Analyze(candles, price, walls)
+}
+
+// Analyze reads structure from candles (oldest first). price places levels
+// and walls as support or resistance; zero uses the last close.
+func (c Config) Analyze(candles []Candle, price float64, walls []Wall) *Analysis {
+	a := &Analysis{Trend: "ranging"}
+	if len(candles) > 0 && price <= 0 {
+		price = candles[len(candles)-1].Close
+	}
+	a.Liquidity = liquidityZones(walls, price)
+	if len(candles) < 2*c.SwingStrength+1 {
+		return a
+	}
+
+	a.Swings = findSwings(candles, c.SwingStrength)
+	a.Levels = clusterLevels(a.Swings, c.ClusterPct, c.MinTouches, price)
+	for i := range a.Levels {
+		level := &a.Levels[i]
+		if level.Price < price {
+			a.Support = level
+		} else if level.Price > price && a.Resistance == nil {
+			a.Resistance = level
+		}
+	}
+
+	last := len(candles) - 1
+	a.SupportLine = trendline(a.Swings, false, last, candles[last].Close)
+	a.ResistanceLine = trendline(a.Swings, true, last, candles[last].Close)
+
+	a.Events, a.Trend = structureEvents(candles, a.Swings, c.SwingStrength)
+	if len(a.Events) > 0 {
+		a.LastEvent = &a.Events[len(a.Events)-1]
+	}
+	return a
+}
+
+// findSwings marks bars whose high (low) beats the SwingStrength bars on
+// each side. ties go to the earlier bar.
+func findSwings(candles []Candle, k int) []Swing {
+	var swings []Swing
+	var lastHigh, lastLow float64
+	var seenHigh, seenLow bool
+	for i := k; i < len(candles)-k; i++ {
+		isHigh, isLow := true, true
+		for j := i - k; j <= i+k; j++ {
+			if j == i {
+				continue
+			}
+			if (j < i && candles[j].High >= candles[i].High) || (j > i && candles[j].High > candles[i].High) {
+				isHigh = false
+			}
+			if (j < i && candles[j].Low <= candles[i].Low) || (j > i && candles[j].Low < candles[i].Low) {
+				isLow = false
+			}
+		}
+		if isHigh {
+			s := Swing{Index: i, Price: candles[i].High, High: true}
+			if seenHigh {
+				s.Label = "LH"
+				if s.Price > lastHigh {
+					s.Label = "HH"
+				}
+			}
+			swings = append(swings, s)
+			lastHigh, seenHigh = s.Price, true
+		}
+		if isLow {
+			s := Swing{Index: i, Price: candles[i].Low}
+			if seenLow {
+				s.Label = "HL"
+				if s.Price < lastLow {
+					s.Label = "LL"
+				}
+			}
+			swings = append(swings, s)
+			lastLow, seenLow = s.Price, true
+		}
+	}
+	return swings
+}
+
+// clusterLevels groups swing prices that sit within pct of the cluster's
+// lowest price, keeping clusters with at least minTouches
+func clusterLevels(swings []Swing, pct float64, minTouches int, price float64) []Level {
+	if len(swings) == 0 {
+		return nil
+	}
+	sorted := make([]Swing, len(swings))
+	copy(sorted, swings)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Price < sorted[j].Price })
+
+	var levels []Level
+	flush := func(group []Swing) {
+		if len(group) < minTouches {
+			return
+		}
+		l := Level{Low: group[0].Price, High: group[len(group)-1].Price, Touches: len(group)}
+		var sum float64
+		for _, s := range group {
+			sum += s.Price
+			if s.Index > l.LastIndex {
+				l.LastIndex = s.Index
+			}
+		}
+		l.Price = sum / float64(len(group))
+		l.Kind = "resistance"
+		if l.Price < price {
+			l.Kind = "support"
+		}
+		levels = append(levels, l)
+	}
+
+	group := []Swing{sorted[0]}
+	for _, s := range sorted[1:] {
+		if s.Price > group[0].Price*(1+pct/100) {
+			flush(group)
+			group = nil
+		}
+		group = append(group, s)
+	}
+	flush(group)
+	return levels
+}
+
+// trendline connects the last two swing highs (or lows) and projects the
+// line to the last bar
+func trendline(swings []Swing, highs bool, last int, lastClose float64) *Trendline {
+	var picked []Swing
+	for i := len(swings) - 1; i >= 0 && len(picked) < 2; i-- {
+		if swings[i].High == highs {
+			picked = append(picked, swings[i])
+		}
+	}
+	if len(picked) < 2 {
+		return nil
+	}
+	to, from := picked[0], picked[1]
+	slope := (to.Price - from.Price) / float64(to.Index-from.Index)
+	t := &Trendline{
+		Kind:  "support",
+		Slope: slope,
+		Value: to.Price + slope*float64(last-to.Index),
+		From:  from.Index,
+		To:    to.Index,
+	}
+	if highs {
+		t.Kind = "resistance"
+		t.Broken = lastClose > t.Value
+	} else {
+		t.Broken = lastClose < t.Value
+	}
+	return t
+}
+
+// structureEvents walks the candles and records each close through the most
+// recent confirmed swing. a swing only counts once it's confirmed, k bars
+// after it formed, and each swing can be broken once.
+func structureEvents(candles []Candle, swings []Swing, k int) ([]Event, string) {
+	var (
+		events          []Event
+		trend           string
+		swingHigh       *Swing
+		swingLow        *Swing
+		next            int
+		highBroken      bool
+		lowBroken       bool
+		lastHH, lastHL  bool
+		sawHigh, sawLow bool
+	)
+	for i, c := range candles {
+		for next < len(swings) && swings[next].Index+k <= i {
+			s := swings[next]
+			if s.High {
+				swingHigh, highBroken = &swings[next], false
+				lastHH, sawHigh = s.Label == "HH", s.Label != ""
+			} else {
+				swingLow, lowBroken = &swings[next], false
+				lastHL, sawLow = s.Label == "HL", s.Label != ""
+			}
+			next++
+		}
+
+		if swingHigh != nil && !highBroken && c.Close > swingHigh.Price {
+			kind := "BOS"
+			if trend == "bearish" {
+				kind = "CHOCH"
+			}
+			events = append(events, Event{Type: kind, Direction: "bullish", Price: swingHigh.Price, Index: i})
+			trend, highBroken = "bullish", true
+		}
+		if swingLow != nil && !lowBroken && c.Close < swingLow.Price {
+			kind := "BOS"
+			if trend == "bullish" {
+				kind = "CHOCH"
+			}
+			events = append(events, Event{Type: kind, Direction: "bearish", Price: swingLow.Price, Index: i})
+			trend, lowBroken = "bearish", true
+		}
+	}
+
+	// no breaks yet: fall back to the swing sequence
+	if trend == "" {
+		trend = "ranging"
+		if sawHigh && sawLow {
+			switch {
+			case lastHH && lastHL:
+				trend = "bullish"
+			case !lastHH && !lastHL:
+				trend = "bearish"
+			}
+		}
+	}
+	return events, trend
+}
+
+// liquidityZones turns walls into zones ordered by distance from price
+func liquidityZones(walls []Wall, price float64) []LiquidityZone {
+	zones := make([]LiquidityZone, 0, len(walls))
+	for _, w := range walls {
+		if w.Price <= 0 {
+			continue
+		}
+		kind := "resistance"
+		if w.Price < price {
+			kind = "support"
+		}
+		zones = append(zones, LiquidityZone{Price: w.Price, SizeUSD: w.SizeUSD, Kind: kind})
+	}
+	sort.SliceStable(zones, func(i, j int) bool {
+		return math.Abs(zones[i].Price-price) < math.Abs(zones[j].Price-price)
+	})
+	return zones
+}
+
+// Nearest returns up to n support and n resistance prices from levels and
+// liquidity zones, nearest to price first
+func (a *Analysis) Nearest(price float64, n int) (supports, resistances []NearLevel) {
+	for _, l := range a.Levels {
+		near := NearLevel{Price: l.Price, Touches: l.Touches, Source: "swing"}
+		if l.Price < price {
+			supports = append(supports, near)
+		} else if l.Price > price {
+			resistances = append(resistances, near)
+		}
+	}
+	for _, z := range a.Liquidity {
+		near := NearLevel{Price: z.Price, SizeUSD: z.SizeUSD, Source: "liquidity"}
+		if z.Price < price {
+			supports = append(supports, near)
+		} else if z.Price > price {
+			resistances = append(resistances, near)
+		}
+	}
+	byDistance := func(levels []NearLevel) []NearLevel {
+		sort.SliceStable(levels, func(i, j int) bool {
+			return math.Abs(levels[i].Price-price) < math.Abs(levels[j].Price-price)
+		})
+		if len(levels) > n {
+			levels = levels[:n]
+		}
+		return levels
+	}
+	return byDistance(supports), byDistance(resistances)
+}
+
+// NearLevel is a support or resistance price from either source
+type NearLevel struct {
+	Price   float64
+	Touches int     // swing touches (swing levels)
+	SizeUSD float64 // resting size (liquidity zones)
+	Source  string  // swing or liquidity
+}
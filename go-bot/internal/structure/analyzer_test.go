@@ -0,0 +1,145 @@
+package structure
+
+import (
+	"math"
+	"testing"
+)
+
+// candlesFrom builds candles around each close with a fixed half range
+func candlesFrom(closes ...float64) []Candle {
+	candles := make([]Candle, len(closes))
+	for i, c := range closes {
+		candles[i] = Candle{Open: c, High: c + 0.5, Low: c - 0.5, Close: c, Volume: 100}
+	}
+	return candles
+}
+
+func TestFindSwingsLabels(t *testing.T) {
+	// rising zigzag: higher highs and higher lows
+	candles := candlesFrom(100, 102, 104, 102, 100, 103, 106, 104, 102, 105, 108, 106, 104)
+	swings := findSwings(candles, 2)
+
+	var labels []string
+	for _, s := range swings {
+		labels = append(labels, s.Label)
+	}
+	want := []string{"", "", "HH", "HL", "HH"}
+	if len(labels) != len(want) {
+		t.Fatalf("expected swings %v, got %+v", want, swings)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("swing %d label = %q, want %q", i, labels[i], want[i])
+		}
+	}
+	if !swings[0].High || swings[0].Index != 2 || swings[0].Price != 104.5 {
+		t.Errorf("unexpected first swing %+v", swings[0])
+	}
+}
+
+func TestClusterLevels(t *testing.T) {
+	// range between ~100 and ~110, three touches of each side
+	candles := candlesFrom(105, 108, 110, 108, 105, 102, 100, 102, 105, 108, 110.2, 108,
+		105, 102, 100.1, 102, 105, 108, 109.9, 108, 105, 102, 99.8, 102, 104)
+	a := Analyze(candles, 0, nil)
+
+	if a.Resistance == nil || a.Resistance.Touches != 3 {
+		t.Fatalf("expected resistance with 3 touches, got %+v", a.Resistance)
+	}
+	if math.Abs(a.Resistance.Price-110.533) > 0.01 {
+		t.Errorf("resistance price = %.3f, want ~110.533", a.Resistance.Price)
+	}
+	if a.Support == nil || a.Support.Touches != 3 || a.Support.Kind != "support" {
+		t.Fatalf("expected support with 3 touches, got %+v", a.Support)
+	}
+	if a.Support.Low > a.Support.High || a.Support.Price < a.Support.Low || a.Support.Price > a.Support.High {
+		t.Errorf("support bounds don't contain its price: %+v", a.Support)
+	}
+}
+
+func TestTrendlineProjection(t *testing.T) {
+	candles := candlesFrom(100, 102, 104, 102, 100, 103, 106, 104, 102, 105, 108, 106, 104)
+	a := Analyze(candles, 0, nil)
+
+	// lows at bar 4 (99.5) and bar 8 (101.5): +0.5 per bar, 103.5 at bar 12
+	if a.SupportLine == nil {
+		t.Fatal("expected a support trendline")
+	}
+	if a.SupportLine.Slope != 0.5 || a.SupportLine.Value != 103.5 {
+		t.Errorf("unexpected support line %+v", a.SupportLine)
+	}
+	if a.SupportLine.Broken {
+		t.Error("close 104 is above the line, shouldn't be broken")
+	}
+	if a.ResistanceLine == nil || a.ResistanceLine.Kind != "resistance" {
+		t.Errorf("expected a resistance line, got %+v", a.ResistanceLine)
+	}
+}
+
+func TestStructureEvents(t *testing.T) {
+	// uptrend breaks each swing high (BOS), then rolls over through the
+	// last swing low (CHOCH) and keeps falling through the next (BOS)
+	candles := candlesFrom(100, 102, 104, 102, 100, 103, 106, 104, 102, 105, 108, 106, 104,
+		101, 98, 100, 102, 99, 96, 94)
+	a := Analyze(candles, 0, nil)
+
+	if len(a.Events) < 3 {
+		t.Fatalf("expected at least 3 events, got %+v", a.Events)
+	}
+	if e := a.Events[0]; e.Type != "BOS" || e.Direction != "bullish" || e.Price != 104.5 {
+		t.Errorf("first event = %+v, want bullish BOS at 104.5", e)
+	}
+
+	var choch *Event
+	for i := range a.Events {
+		if a.Events[i].Type == "CHOCH" {
+			choch = &a.Events[i]
+			break
+		}
+	}
+	if choch == nil || choch.Direction != "bearish" {
+		t.Fatalf("expected a bearish CHOCH, got %+v", a.Events)
+	}
+	if a.LastEvent == nil || a.LastEvent.Direction != "bearish" {
+		t.Errorf("last event = %+v, want bearish", a.LastEvent)
+	}
+	if a.Trend != "bearish" {
+		t.Errorf("trend = %s, want bearish", a.Trend)
+	}
+}
+
+func TestLiquidityAndNearest(t *testing.T) {
+	candles := candlesFrom(105, 108, 110, 108, 105, 102, 100, 102, 105, 108, 110.2, 108,
+		105, 102, 100.3, 102, 105)
+	walls := []Wall{
+		{Side: "ask", Price: 107, SizeUSD: 2e6},
+		{Side: "bid", Price: 103, SizeUSD: 1e6},
+		{Side: "bid", Price: 90, SizeUSD: 5e6},
+	}
+	a := Analyze(candles, 105, walls)
+
+	if len(a.Liquidity) != 3 || a.Liquidity[0].Price != 107 && a.Liquidity[0].Price != 103 {
+		t.Fatalf("expected zones nearest first, got %+v", a.Liquidity)
+	}
+	if a.Liquidity[2].Price != 90 || a.Liquidity[2].Kind != "support" {
+		t.Errorf("farthest zone = %+v", a.Liquidity[2])
+	}
+
+	supports, resistances := a.Nearest(105, 2)
+	if len(supports) != 2 || supports[0].Price != 103 || supports[0].Source != "liquidity" {
+		t.Errorf("supports = %+v", supports)
+	}
+	if supports[1].Source != "swing" || supports[1].Touches < 2 {
+		t.Errorf("second support should be the swing level, got %+v", supports[1])
+	}
+	if len(resistances) != 2 || resistances[0].Price != 107 || resistances[1].Source != "swing" {
+		t.Errorf("resistances = %+v", resistances)
+	}
+}
+
+func TestAnalyzeInsufficientData(t *testing.T) {
+	a := Analyze(candlesFrom(100, 101), 0, nil)
+	if a.Trend != "ranging" || len(a.Swings) != 0 || a.Support != nil || a.LastEvent != nil {
+		t.Errorf("expected an empty ranging analysis, got %+v", a)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/preferences"
+	"github.com/trading-bot/go-bot/internal/usage"
 	"github.com/trading-bot/go-bot/internal/user"
 	"github.com/trading-bot/go-bot/internal/watchlist"
 )
@@ -32,6 +33,17 @@ type exchangeClient interface {
 	GetBalance(ctx context.Context, apiKey, apiSecret string) ([]exchange.Balance, error)
 }
 
+// ModelPromoter force-promotes the staged ML candidate model, returning a
+// summary for the admin
+type ModelPromoter interface {
+	PromoteModel(ctx context.Context, by string) (string, error)
+}
+
+// UsageReporter summarizes a user's llm token usage, cost and budget
+type UsageReporter interface {
+	Report(ctx context.Context, userID int) (*usage.Report, error)
+}
+
 // per-user rate limiter for expensive commands
 type rateLimiter struct {
 	mu       sync.Mutex
@@ -82,7 +94,10 @@ type Handler struct {
 	prefsSvc        *preferences.Service
 	exchange        exchangeClient
 	registry        *exchange.Registry
-	trading         *TradingDeps // optional, set via SetTradingDeps
+	trading         *TradingDeps  // optional, set via SetTradingDeps
+	promoter        ModelPromoter // optional, set via SetModelPromoter
+	usage           UsageReporter // optional, set via SetUsageReporter
+	adminChatID     int64
 	limiter         *rateLimiter
 	testnet         bool
 	exchangeTestnet map[string]bool
@@ -119,6 +134,17 @@ func (h *Handler) SetExchangeTestnet(exchangeName string, testnet bool) {
 	h.exchangeTestnet[strings.ToLower(strings.TrimSpace(exchangeName))] = testnet
 }
 
+// SetModelPromoter enables /mlpromote for the admin chat.
+func (h *Handler) SetModelPromoter(p ModelPromoter, adminChatID int64) {
+	h.promoter = p
+	h.adminChatID = adminChatID
+}
+
+// SetUsageReporter enables /usage.
+func (h *Handler) SetUsageReporter(r UsageReporter) {
+	h.usage = r
+}
+
 func (h *Handler) SetExchangeRegistry(registry *exchange.Registry) {
 	h.registry = registry
 }
@@ -191,6 +217,11 @@ func (h *Handler) HandleUpdate(ctx context.Context, update Update) {
 		h.handleSettings(ctx, telegramID, chatID)
 	case "set":
 		h.handleSet(ctx, msg, telegramID, chatID)
+	// admin commands
+	case "mlpromote":
+		h.handleMLPromote(ctx, msg, chatID)
+	case "usage":
+		h.handleUsage(ctx, telegramID, chatID)
 	// exchange data commands (rate limited)
 	case "price", "p":
 		if !h.limiter.allow(telegramID) {
@@ -454,7 +485,8 @@ func (h *Handler) handleHelp(chatID int64) {
 			"/watchreset - reset to default top-10\n\n"+
 			"*preferences*\n"+
 			"/settings - view all your preferences\n"+
-			"/set <key> <value> - change a preference\n\n"+
+			"/set <key> <value> - change a preference\n"+
+			"/usage - ai calls, tokens and cost against your budget\n\n"+
 			"*trading*\n"+
 			"/positions - view open positions\n"+
 			"/close <id> - close a position\n"+
@@ -468,6 +500,80 @@ func (h *Handler) handleHelp(chatID int64) {
 	)
 }
 
+// promotes the staged ML candidate that the promotion gate rejected.
+// only answers in the admin chat; everyone else sees an unknown command.
+func (h *Handler) handleMLPromote(ctx context.Context, msg *Message, chatID int64) {
+	if h.promoter == nil || h.adminChatID == 0 || chatID != h.adminChatID {
+		h.send(chatID, "unknown command. type /help for available commands.")
+		return
+	}
+
+	by := msg.From.Username
+	if by == "" {
+		by = strconv.FormatInt(msg.From.ID, 10)
+	}
+	summary, err := h.promoter.PromoteModel(ctx, by)
+	if err != nil {
+		h.send(chatID, fmt.Sprintf("❌ promotion failed: %s", err))
+		return
+	}
+	h.send(chatID, summary)
+}
+
+// shows the user's ai usage today and this week, the symbols it went on,
+// and how much of the daily budget is left
+func (h *Handler) handleUsage(ctx context.Context, telegramID int64, chatID int64) {
+	if h.usage == nil {
+		h.send(chatID, "usage tracking is not enabled.")
+		return
+	}
+	userID, ok := h.getUserID(ctx, telegramID, chatID)
+	if !ok {
+		return
+	}
+
+	rep, err := h.usage.Report(ctx, userID)
+	if err != nil {
+		log.Printf("error loading ai usage for user %d: %v", userID, err)
+		h.send(chatID, "failed to load your usage. please try again.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*ai usage*\n\n")
+	sb.WriteString(fmt.Sprintf("today: %s\n", formatUsageTotals(rep.Today)))
+	sb.WriteString(fmt.Sprintf("last 7 days: %s\n", formatUsageTotals(rep.Week)))
+
+	if b := rep.Budget; b != nil {
+		sb.WriteString("\n*daily budget*\n")
+		sb.WriteString(fmt.Sprintf("you: %s\n", formatBudget(b.UserSpent, b.UserLimit)))
+		sb.WriteString(fmt.Sprintf("all users: %s\n", formatBudget(b.GlobalSpent, b.GlobalLimit)))
+		if b.Exhausted {
+			sb.WriteString(fmt.Sprintf("⚠️ %s — scans use the pre-filter only until 00:00 UTC\n", b.Reason))
+		}
+	}
+
+	if len(rep.BySymbol) > 0 {
+		sb.WriteString("\n*top symbols (7 days)*\n")
+		for _, s := range rep.BySymbol {
+			sb.WriteString(fmt.Sprintf("%s — %d calls, $%.4f\n", s.Symbol, s.Calls, s.CostUSD))
+		}
+	}
+
+	h.send(chatID, sb.String())
+}
+
+func formatUsageTotals(t usage.Totals) string {
+	return fmt.Sprintf("%d calls, %d in / %d out tokens, $%.4f", t.Calls, t.InputTokens, t.OutputTokens, t.CostUSD)
+}
+
+func formatBudget(spent, limit float64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("$%.2f (no limit)", spent)
+	}
+	return fmt.Sprintf("$%.2f of $%.2f", spent, limit)
+}
+
 // resolves a telegram id to an internal user id
 func (h *Handler) getUserID(ctx context.Context, telegramID int64, chatID int64) (int, bool) {
 	result, err := h.userSvc.Register(ctx, telegramID, "")
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/trading-bot/go-bot/internal/exchange"
 	"github.com/trading-bot/go-bot/internal/livetrading"
 	"github.com/trading-bot/go-bot/internal/preferences"
+	"github.com/trading-bot/go-bot/internal/usage"
 	"github.com/trading-bot/go-bot/internal/user"
 	"github.com/trading-bot/go-bot/internal/watchlist"
 )
@@ -2097,3 +2099,99 @@ func TestCallbackQuery_TakesPriority(t *testing.T) {
 		t.Error("expected /help NOT to be processed when callback query is present")
 	}
 }
+
+// --- /mlpromote tests ---
+
+type mockPromoter struct {
+	by  string
+	err error
+}
+
+func (m *mockPromoter) PromoteModel(_ context.Context, by string) (string, error) {
+	m.by = by
+	return "candidate promoted", m.err
+}
+
+func TestMLPromote_Admin(t *testing.T) {
+	env := newTestEnv()
+	promoter := &mockPromoter{}
+	env.handler.SetModelPromoter(promoter, 99)
+	env.handler.HandleUpdate(context.Background(), makeUpdate(1, 99, "/mlpromote"))
+
+	if promoter.by != "testuser" {
+		t.Errorf("expected promotion by testuser, got %q", promoter.by)
+	}
+	if env.bot.lastMessage() != "candidate promoted" {
+		t.Errorf("expected promotion summary, got: %s", env.bot.lastMessage())
+	}
+}
+
+func TestMLPromote_NotAdmin(t *testing.T) {
+	env := newTestEnv()
+	promoter := &mockPromoter{}
+	env.handler.SetModelPromoter(promoter, 99)
+	env.handler.HandleUpdate(context.Background(), makeUpdate(1, 1, "/mlpromote"))
+
+	if promoter.by != "" {
+		t.Error("non-admin chat must not promote")
+	}
+	if !strings.Contains(env.bot.lastMessage(), "unknown command") {
+		t.Errorf("expected unknown command message, got: %s", env.bot.lastMessage())
+	}
+}
+
+func TestMLPromote_Error(t *testing.T) {
+	env := newTestEnv()
+	env.handler.SetModelPromoter(&mockPromoter{err: errors.New("no staged candidate model to promote")}, 99)
+	env.handler.HandleUpdate(context.Background(), makeUpdate(1, 99, "/mlpromote"))
+
+	if !strings.Contains(env.bot.lastMessage(), "no staged candidate") {
+		t.Errorf("expected the error to be reported, got: %s", env.bot.lastMessage())
+	}
+}
+
+// --- /usage tests ---
+
+type mockUsageReporter struct {
+	userID int
+	report *usage.Report
+}
+
+func (m *mockUsageReporter) Report(_ context.Context, userID int) (*usage.Report, error) {
+	m.userID = userID
+	return m.report, nil
+}
+
+func TestUsage_Report(t *testing.T) {
+	env := newTestEnv()
+	u := env.seedActivatedUser(12345)
+	reporter := &mockUsageReporter{report: &usage.Report{
+		Today:    usage.Totals{Calls: 3, InputTokens: 7200, OutputTokens: 360, CostUSD: 0.027},
+		Week:     usage.Totals{Calls: 10, CostUSD: 0.09},
+		BySymbol: []usage.SymbolTotals{{Symbol: "BTC/USDT", Totals: usage.Totals{Calls: 6, CostUSD: 0.05}}},
+		Budget:   &usage.Status{UserSpent: 1.02, UserLimit: 1, GlobalSpent: 4, Exhausted: true, Reason: "daily ai budget of $1.00 spent"},
+	}}
+	env.handler.SetUsageReporter(reporter)
+
+	env.handler.HandleUpdate(context.Background(), makeUpdate(12345, 100, "/usage"))
+
+	if reporter.userID != u.ID {
+		t.Errorf("expected the report for user %d, got %d", u.ID, reporter.userID)
+	}
+	msg := env.bot.lastMessage()
+	for _, want := range []string{"today: 3 calls, 7200 in / 360 out tokens, $0.0270", "$1.02 of $1.00", "$4.00 (no limit)", "pre-filter only", "BTC/USDT — 6 calls"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in usage message, got: %s", want, msg)
+		}
+	}
+}
+
+func TestUsage_NotConfigured(t *testing.T) {
+	env := newTestEnv()
+	env.seedActivatedUser(12345)
+	env.handler.HandleUpdate(context.Background(), makeUpdate(12345, 100, "/usage"))
+
+	if !strings.Contains(env.bot.lastMessage(), "not enabled") {
+		t.Errorf("expected usage disabled message, got: %s", env.bot.lastMessage())
+	}
+}
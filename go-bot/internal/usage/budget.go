@@ -0,0 +1,126 @@
+package usage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// Budget tracks today's ai spend per user and overall against daily limits.
+// Spend resets at midnight UTC. A zero limit means unlimited.
+type Budget struct {
+	mu      sync.Mutex
+	perUser float64
+	global  float64
+	day     string // utc date the spend below belongs to
+	users   map[int]float64
+	total   float64
+	clock   clock.Clock
+}
+
+// NewBudget creates a budget with daily USD limits per user and overall.
+func NewBudget(perUser, global float64) *Budget {
+	return &Budget{
+		perUser: perUser,
+		global:  global,
+		users:   make(map[int]float64),
+		clock:   clock.Real(),
+	}
+}
+
+// SetClock replaces the time source used for the daily reset.
+func (b *Budget) SetClock(clk clock.Clock) {
+	b.clock = clk
+}
+
+// Seed sets today's spend, e.g. from the decision log after a restart.
+// User 0 is spend not attributed to any user.
+func (b *Budget) Seed(spend map[int]float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	b.users = make(map[int]float64, len(spend))
+	b.total = 0
+	for userID, cost := range spend {
+		if userID != 0 {
+			b.users[userID] = cost
+		}
+		b.total += cost
+	}
+}
+
+// RecordAI adds the cost of an ai call to the user's and the overall spend.
+func (b *Budget) RecordAI(userID int, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	if userID != 0 {
+		b.users[userID] += cost
+	}
+	b.total += cost
+}
+
+// AllowAI reports whether the user's analyses may still call the ai today,
+// with the reason when they may not.
+func (b *Budget) AllowAI(userID int) (bool, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover()
+	if b.global > 0 && b.total >= b.global {
+		return false, fmt.Sprintf("global daily ai budget of $%.2f spent", b.global)
+	}
+	if b.perUser > 0 && userID != 0 && b.users[userID] >= b.perUser {
+		return false, fmt.Sprintf("daily ai budget of $%.2f spent", b.perUser)
+	}
+	return true, ""
+}
+
+// Status is today's spend against the limits.
+type Status struct {
+	UserSpent   float64
+	UserLimit   float64 // 0 = unlimited
+	GlobalSpent float64
+	GlobalLimit float64 // 0 = unlimited
+	Exhausted   bool
+	Reason      string
+}
+
+// Status returns today's spend for the user and overall.
+func (b *Budget) Status(userID int) Status {
+	allowed, reason := b.AllowAI(userID)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Status{
+		UserSpent:   b.users[userID],
+		UserLimit:   b.perUser,
+		GlobalSpent: b.total,
+		GlobalLimit: b.global,
+		Exhausted:   !allowed,
+		Reason:      reason,
+	}
+}
+
+// DayStart is midnight UTC of the budget's current day, where the spend
+// restored by Seed should start.
+func (b *Budget) DayStart() time.Time {
+	now := b.clock.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// rollover clears the spend when the utc day changes. Callers hold mu.
+func (b *Budget) rollover() {
+	day := b.clock.Now().UTC().Format("2006-01-02")
+	if day == b.day {
+		return
+	}
+	if b.day != "" {
+		b.users = make(map[int]float64)
+		b.total = 0
+	}
+	b.day = day
+}
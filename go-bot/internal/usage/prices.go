@@ -0,0 +1,73 @@
+// llm usage accounting — prices per model and daily spend budgets per user
+// and overall. the scanner stops calling the ai once a budget is spent and
+// falls back to the deterministic pre-filter until the next utc day.
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+)
+
+// Price is what a model charges in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Prices maps model names, or model name prefixes, to their price.
+type Prices map[string]Price
+
+// ParsePrices reads "model=input/output" entries, USD per million tokens,
+// e.g. "claude-sonnet-4=3/15".
+func ParsePrices(entries []string) (Prices, error) {
+	prices := make(Prices, len(entries))
+	for _, e := range entries {
+		model, rates, ok := strings.Cut(strings.TrimSpace(e), "=")
+		in, out, ok2 := strings.Cut(rates, "/")
+		if !ok || !ok2 || model == "" {
+			return nil, fmt.Errorf("price %q must look like model=input/output", e)
+		}
+		input, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil || input < 0 {
+			return nil, fmt.Errorf("price %q has an invalid input rate", e)
+		}
+		output, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil || output < 0 {
+			return nil, fmt.Errorf("price %q has an invalid output rate", e)
+		}
+		prices[strings.TrimSpace(model)] = Price{Input: input, Output: output}
+	}
+	return prices, nil
+}
+
+// Lookup returns the price for a model: an exact match, else the longest
+// entry the model name starts with, so "claude-sonnet-4" covers dated
+// releases.
+func (p Prices) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range p {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// Cost returns what the usage cost in USD; models without a price, such
+// as local ones, cost nothing.
+func (p Prices) Cost(model string, u claude.Usage) float64 {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(u.InputTokens)*price.Input + float64(u.OutputTokens)*price.Output) / 1e6
+}
@@ -0,0 +1,77 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+// Totals sums the ai calls in a period.
+type Totals struct {
+	Calls        int
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// SymbolTotals is the usage spent analyzing one symbol.
+type SymbolTotals struct {
+	Symbol string
+	Totals
+}
+
+// Store aggregates logged ai usage. User 0 means every user.
+type Store interface {
+	UsageTotals(ctx context.Context, userID int, since time.Time) (Totals, error)
+	UsageBySymbol(ctx context.Context, userID int, since time.Time, limit int) ([]SymbolTotals, error)
+}
+
+// Report is a user's usage today and over the last week, with their budget.
+type Report struct {
+	Today    Totals
+	Week     Totals
+	BySymbol []SymbolTotals // last 7 days, most expensive first
+	Budget   *Status        // nil without budgets
+}
+
+// Reporter builds usage reports from the decision log.
+type Reporter struct {
+	store  Store
+	budget *Budget // nil = no budgets
+	clock  clock.Clock
+}
+
+func NewReporter(store Store, budget *Budget) *Reporter {
+	return &Reporter{store: store, budget: budget, clock: clock.Real()}
+}
+
+// SetClock replaces the time source the report periods start from.
+func (r *Reporter) SetClock(clk clock.Clock) {
+	r.clock = clk
+}
+
+// Report returns the user's usage; user 0 reports on everyone.
+func (r *Reporter) Report(ctx context.Context, userID int) (*Report, error) {
+	now := r.clock.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	week := today.AddDate(0, 0, -6)
+
+	rep := &Report{}
+	var err error
+	if rep.Today, err = r.store.UsageTotals(ctx, userID, today); err != nil {
+		return nil, fmt.Errorf("failed to load today's usage: %w", err)
+	}
+	if rep.Week, err = r.store.UsageTotals(ctx, userID, week); err != nil {
+		return nil, fmt.Errorf("failed to load the week's usage: %w", err)
+	}
+	if rep.BySymbol, err = r.store.UsageBySymbol(ctx, userID, week, 5); err != nil {
+		return nil, fmt.Errorf("failed to load usage by symbol: %w", err)
+	}
+	if r.budget != nil {
+		status := r.budget.Status(userID)
+		rep.Budget = &status
+	}
+	return rep, nil
+}
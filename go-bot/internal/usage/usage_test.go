@@ -0,0 +1,117 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trading-bot/go-bot/internal/claude"
+	"github.com/trading-bot/go-bot/internal/clock"
+)
+
+func TestPrices(t *testing.T) {
+	prices, err := ParsePrices([]string{"claude-sonnet-4=3/15", " claude-sonnet-4-5 = 4/20 ", "gpt-4o=2.5/10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"claude-sonnet-4-20250514", 0.0045},  // prefix match: 1000*3/1e6 + 100*15/1e6
+		{"claude-sonnet-4-5-20250929", 0.006}, // longest prefix wins
+		{"gpt-4o", 0.0035},
+		{"llama3.1:70b", 0}, // local model, no price
+	}
+	for _, tt := range tests {
+		got := prices.Cost(tt.model, claude.Usage{InputTokens: 1000, OutputTokens: 100})
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cost(%s) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"gpt-4o", "gpt-4o=2.5", "=1/2", "gpt-4o=x/10", "gpt-4o=-1/10"} {
+		if _, err := ParsePrices([]string{bad}); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	sim := clock.NewSimulated(time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC))
+	b := NewBudget(1, 2.5)
+	b.SetClock(sim)
+	b.Seed(map[int]float64{1: 0.6, 0: 0.2})
+
+	b.RecordAI(1, 0.5)
+	if ok, reason := b.AllowAI(1); ok || !strings.Contains(reason, "$1.00") {
+		t.Errorf("user 1 spent $1.10 of $1, got allowed=%v %q", ok, reason)
+	}
+	if ok, _ := b.AllowAI(2); !ok {
+		t.Error("user 2 has spent nothing")
+	}
+
+	b.RecordAI(2, 0.8)
+	b.RecordAI(0, 0.7)
+	if ok, reason := b.AllowAI(2); ok || !strings.Contains(reason, "global") {
+		t.Errorf("the global $2.50 is spent, got allowed=%v %q", ok, reason)
+	}
+	if s := b.Status(2); !s.Exhausted || math.Abs(s.GlobalSpent-2.8) > 1e-9 || math.Abs(s.UserSpent-0.8) > 1e-9 {
+		t.Errorf("unexpected status %+v", s)
+	}
+
+	if got := b.DayStart(); !got.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("day start = %v, want midnight of mar 1", got)
+	}
+
+	sim.Advance(3 * time.Hour) // past midnight utc
+	if got := b.DayStart(); !got.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("day start = %v, want midnight of mar 2", got)
+	}
+	if ok, _ := b.AllowAI(1); !ok {
+		t.Error("spend should reset on a new utc day")
+	}
+	if s := b.Status(1); s.UserSpent != 0 || s.GlobalSpent != 0 {
+		t.Errorf("expected a fresh day, got %+v", s)
+	}
+
+	if ok, _ := NewBudget(0, 0).AllowAI(1); !ok {
+		t.Error("zero limits mean unlimited")
+	}
+}
+
+type mockStore struct {
+	since []time.Time
+}
+
+func (m *mockStore) UsageTotals(_ context.Context, _ int, since time.Time) (Totals, error) {
+	m.since = append(m.since, since)
+	return Totals{Calls: len(m.since), CostUSD: 0.01 * float64(len(m.since))}, nil
+}
+
+func (m *mockStore) UsageBySymbol(context.Context, int, time.Time, int) ([]SymbolTotals, error) {
+	return []SymbolTotals{{Symbol: "BTC/USDT", Totals: Totals{Calls: 2}}}, nil
+}
+
+func TestReporter(t *testing.T) {
+	store := &mockStore{}
+	r := NewReporter(store, NewBudget(1, 0))
+	r.SetClock(clock.NewSimulated(time.Date(2026, 3, 8, 15, 30, 0, 0, time.UTC)))
+
+	rep, err := r.Report(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !store.since[0].Equal(time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)) || !store.since[1].Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected today and the last 7 days, got %v", store.since)
+	}
+	if rep.Today.Calls != 1 || rep.Week.Calls != 2 || len(rep.BySymbol) != 1 {
+		t.Errorf("unexpected report %+v", rep)
+	}
+	if rep.Budget == nil || rep.Budget.UserLimit != 1 {
+		t.Errorf("expected the budget status, got %+v", rep.Budget)
+	}
+}
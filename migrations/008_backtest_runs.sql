@@ -0,0 +1,24 @@
+-- persisted backtest runs.
+-- summary columns are denormalized for listing/comparison; the full report
+-- (config, metrics, trades, equity curve) is kept as JSONB.
+
+CREATE TABLE IF NOT EXISTS backtest_runs (
+    id                  SERIAL PRIMARY KEY,
+    symbol              TEXT NOT NULL,
+    interval            TEXT NOT NULL,
+    strategy            TEXT NOT NULL,
+    start_time          TIMESTAMPTZ NOT NULL,
+    end_time            TIMESTAMPTZ NOT NULL,
+    initial_capital     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    final_equity        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_return_pct    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    sharpe_ratio        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_drawdown_pct    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    win_rate            DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_trades        INTEGER NOT NULL DEFAULT 0,
+    report              JSONB NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtest_runs_symbol ON backtest_runs(symbol, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_backtest_runs_created ON backtest_runs(created_at DESC);
@@ -0,0 +1,12 @@
+-- streaming indicator state snapshots.
+-- one row per symbol/interval, overwritten on every snapshot so indicator
+-- streams can resume after a restart instead of warming up from scratch.
+
+CREATE TABLE IF NOT EXISTS indicator_state (
+    symbol              TEXT NOT NULL,
+    interval            TEXT NOT NULL,
+    state               JSONB NOT NULL,
+    last_candle_time    TIMESTAMPTZ NOT NULL,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (symbol, interval)
+);
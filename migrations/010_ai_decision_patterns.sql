@@ -0,0 +1,9 @@
+-- chart patterns detected at decision time.
+-- a JSON array of {name, direction, confidence, target, neckline} so pattern
+-- outcomes can be measured against the positions the decisions opened.
+
+ALTER TABLE ai_decisions
+    ADD COLUMN IF NOT EXISTS patterns_data JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_patterns
+    ON ai_decisions USING GIN (patterns_data);
@@ -0,0 +1,14 @@
+-- ensemble prediction outcomes.
+-- the pipeline records each ensemble prediction with its per-model votes in
+-- model_details; once the prediction horizon passes the realized price is
+-- stored so each model's accuracy can be measured per symbol.
+
+ALTER TABLE ensemble_predictions
+    ADD COLUMN IF NOT EXISTS horizon_at         TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS realized_price     DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS realized_direction VARCHAR(10)
+        CHECK (realized_direction IN ('UP', 'DOWN', 'NEUTRAL')),
+    ADD COLUMN IF NOT EXISTS resolved_at        TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_ensemble_predictions_due
+    ON ensemble_predictions(horizon_at) WHERE resolved_at IS NULL;
@@ -0,0 +1,17 @@
+-- rl agent second opinion.
+-- ai_decisions.rl_opinion holds the agent's call on the same analysis
+-- ({action, detail, confidence}) so disagreements with Claude can be scored.
+-- rl_training_episodes records which market data each training run used.
+
+ALTER TABLE ai_decisions
+    ADD COLUMN IF NOT EXISTS rl_opinion JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_rl_opinion
+    ON ai_decisions(created_at) WHERE rl_opinion IS NOT NULL;
+
+ALTER TABLE rl_training_episodes
+    ADD COLUMN IF NOT EXISTS symbol       VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS interval     VARCHAR(5),
+    ADD COLUMN IF NOT EXISTS from_time    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS to_time      TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS candle_count INTEGER;
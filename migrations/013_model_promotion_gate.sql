@@ -0,0 +1,23 @@
+-- model promotion gate.
+-- every drift check is logged in drift_checks; when drift triggers a retrain
+-- the candidate is staged, validated walk-forward, and the gate's decision is
+-- kept in retrain_runs alongside the check that triggered it. promoted_by is
+-- 'gate' or the admin who promoted a rejected candidate with /mlpromote.
+
+ALTER TABLE drift_checks
+    ADD COLUMN IF NOT EXISTS symbol    VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS timeframe VARCHAR(10);
+
+ALTER TABLE retrain_runs
+    ADD COLUMN IF NOT EXISTS drift_check_id        INTEGER REFERENCES drift_checks(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS symbol                VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS timeframe             VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS staged                BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS walk_forward_accuracy DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS walk_forward_folds    INTEGER,
+    ADD COLUMN IF NOT EXISTS outcome               VARCHAR(20)
+        CHECK (outcome IN ('failed', 'rejected', 'promoted')),
+    ADD COLUMN IF NOT EXISTS promoted_by           VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS promoted_at           TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_retrain_runs_staged ON retrain_runs(id DESC) WHERE staged = TRUE;
@@ -0,0 +1,6 @@
+-- per-scope drift reports.
+-- the drift monitor checks every watched symbol on every configured timeframe;
+-- the API reads the latest check per (symbol, timeframe).
+
+CREATE INDEX IF NOT EXISTS idx_drift_checks_scope
+    ON drift_checks(symbol, timeframe, checked_at DESC);
@@ -0,0 +1,20 @@
+-- deterministic pre-filter checks.
+-- the pipeline scores every setup before the claude call and skips claude
+-- below the threshold. every check is logged with the price at the time so
+-- skipped setups can be scored against the moves that followed.
+
+CREATE TABLE IF NOT EXISTS prefilter_checks (
+    id              SERIAL PRIMARY KEY,
+    symbol          VARCHAR(20) NOT NULL,
+    timeframe       VARCHAR(5) NOT NULL,
+    score           DOUBLE PRECISION NOT NULL,
+    threshold       DOUBLE PRECISION NOT NULL,
+    skipped         BOOLEAN NOT NULL,
+    components      JSONB NOT NULL DEFAULT '{}'::jsonb,
+    signals         TEXT[] NOT NULL DEFAULT '{}',
+    price           DOUBLE PRECISION NOT NULL,
+    checked_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_prefilter_checks_symbol_time
+    ON prefilter_checks(symbol, checked_at DESC);
@@ -0,0 +1,10 @@
+-- llm provider fallback chain.
+-- ai_decisions records which provider (anthropic, openai, ollama, ...) and
+-- which model produced each decision, so backup providers can be compared.
+
+ALTER TABLE ai_decisions
+    ADD COLUMN IF NOT EXISTS ai_provider VARCHAR(30),
+    ADD COLUMN IF NOT EXISTS ai_model    VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_provider
+    ON ai_decisions(ai_provider, created_at);
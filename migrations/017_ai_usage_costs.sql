@@ -0,0 +1,18 @@
+-- llm token usage and cost accounting.
+-- ai_decisions already has prompt_tokens/completion_tokens; cost_usd adds the
+-- priced cost of the call. decisions shared from another user's analysis are
+-- logged with zero usage so sums don't double count.
+-- the ai budgets restore today's spend from ai_decisions.cost_usd after a
+-- restart; daily_stats keeps per-user daily totals for reporting.
+
+ALTER TABLE ai_decisions
+    ADD COLUMN IF NOT EXISTS cost_usd DECIMAL(12, 6) DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_user_created
+    ON ai_decisions(user_id, created_at);
+
+ALTER TABLE daily_stats
+    ADD COLUMN IF NOT EXISTS ai_calls         INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ai_input_tokens  BIGINT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ai_output_tokens BIGINT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ai_cost_usd      DECIMAL(12, 6) DEFAULT 0;
@@ -0,0 +1,11 @@
+-- prompt experiments.
+-- ai_decisions records the system prompt version behind each decision and
+-- the experiment that assigned it, so variants can be compared.
+
+ALTER TABLE ai_decisions
+    ADD COLUMN IF NOT EXISTS prompt_version    VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS prompt_experiment VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_prompt_experiment
+    ON ai_decisions(prompt_experiment, prompt_version, created_at)
+    WHERE prompt_experiment IS NOT NULL;
@@ -0,0 +1,9 @@
+-- skipped retrains.
+-- drifted scopes that weren't retrained (too few candles, or past the pooled
+-- retrain limit for the cycle) are recorded in retrain_runs as skipped.
+
+ALTER TABLE retrain_runs DROP CONSTRAINT IF EXISTS retrain_runs_outcome_check;
+
+ALTER TABLE retrain_runs
+    ADD CONSTRAINT retrain_runs_outcome_check
+        CHECK (outcome IN ('failed', 'rejected', 'promoted', 'skipped'));
@@ -0,0 +1,22 @@
+-- billed ai calls that failed.
+-- a call whose answer couldn't be used is still billed; it is logged as a
+-- HOLD filtered with ai_failed so usage reports and the budgets restored
+-- from ai_decisions.cost_usd include its tokens.
+
+ALTER TABLE ai_decisions
+    DROP CONSTRAINT IF EXISTS ai_decisions_filter_reason_check;
+
+ALTER TABLE ai_decisions
+    ADD CONSTRAINT ai_decisions_filter_reason_check
+    CHECK (filter_reason IN (
+        'none',
+        'hold',
+        'low_confidence',
+        'confidence_decay',
+        'duplicate',
+        'daily_limit',
+        'safety_blocked',
+        'expired',
+        'user_rejected',
+        'ai_failed'
+    ));
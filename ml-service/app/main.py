@@ -17,8 +17,11 @@ from app.schemas import (
     DriftCheckResponse,
     RetrainRequest,
     RetrainResponse,
+    PromoteResponse,
     WalkForwardRequest,
     WalkForwardResponse,
+    CandidateValidationRequest,
+    CandidateValidationResponse,
     RLTrainRequest,
     RLTrainResponse,
     RLActionRequest,
@@ -87,7 +90,9 @@ async def root():
             "detect_patterns": "/patterns/detect",
             "check_drift": "/drift/check",
             "retrain": "/retrain",
+            "promote": "/promote",
             "walk_forward": "/walk-forward",
+            "validate_candidate": "/validate-candidate",
             "rl_train": "/rl/train",
             "rl_action": "/rl/action",
         },
@@ -165,20 +170,28 @@ async def check_drift(request: DriftCheckRequest):
         raise HTTPException(status_code=500, detail=f"drift check failed: {str(e)}")
 
 
+def _normalized_series(candle_models) -> tuple:
+    """features normalized over the series itself, with its closes"""
+    candles = [c.model_dump() for c in candle_models]
+    features = predictor._feature_engineer(candles)
+    closes = np.array([c["close"] for c in candles])
+
+    mean = features.mean(axis=0)
+    std = features.std(axis=0)
+    std[std == 0] = 1
+    return (features - mean) / std, closes
+
+
 @app.post("/retrain", response_model=RetrainResponse)
 async def retrain(request: RetrainRequest):
     """triggers model retraining with new data"""
     try:
-        candles = [c.model_dump() for c in request.candles]
-        features = predictor._feature_engineer(candles)
-        closes = np.array([c["close"] for c in candles])
+        normalized, closes = _normalized_series(request.candles)
+        extra = [_normalized_series(s) for s in request.series]
 
-        mean = features.mean(axis=0)
-        std = features.std(axis=0)
-        std[std == 0] = 1
-        normalized = (features - mean) / std
-
-        result = retrain_pipeline.retrain(normalized, closes, epochs=request.epochs)
+        result = retrain_pipeline.retrain(
+            normalized, closes, epochs=request.epochs, stage_only=request.stage_only, extra=extra,
+        )
 
         if result.get("promoted"):
             predictor._load_model()
@@ -188,6 +201,18 @@ async def retrain(request: RetrainRequest):
         raise HTTPException(status_code=500, detail=f"retraining failed: {str(e)}")
 
 
+@app.post("/promote", response_model=PromoteResponse)
+async def promote():
+    """promotes the candidate staged by a stage_only retrain"""
+    try:
+        result = retrain_pipeline.promote_candidate()
+        if result.get("success"):
+            predictor._load_model()
+        return PromoteResponse(**result)
+    except Exception as e:
+        raise HTTPException(status_code=500, detail=f"promotion failed: {str(e)}")
+
+
 @app.post("/walk-forward", response_model=WalkForwardResponse)
 async def walk_forward(request: WalkForwardRequest):
     """runs walk-forward cross-validation"""
@@ -209,6 +234,21 @@ async def walk_forward(request: WalkForwardRequest):
         raise HTTPException(status_code=500, detail=f"walk-forward failed: {str(e)}")
 
 
+@app.post("/validate-candidate", response_model=CandidateValidationResponse)
+async def validate_candidate(request: CandidateValidationRequest):
+    """scores the staged candidate against the deployed model on held-out folds"""
+    try:
+        normalized, closes = _normalized_series(request.candles)
+        extra = [_normalized_series(s) for s in request.series]
+
+        result = retrain_pipeline.validate_candidate(
+            normalized, closes, holdout=request.holdout, n_splits=request.n_splits, extra=extra,
+        )
+        return CandidateValidationResponse(**result)
+    except Exception as e:
+        raise HTTPException(status_code=500, detail=f"candidate validation failed: {str(e)}")
+
+
 @app.post("/rl/train", response_model=RLTrainResponse)
 async def rl_train(request: RLTrainRequest):
     """trains the RL agent from historical candle data"""
@@ -1,10 +1,13 @@
 # automated retraining pipeline
-# triggers retraining when drift is detected, validates new model before promoting
+# triggers retraining when drift is detected, validates new model before promoting.
+# with stage_only the new model is kept as a candidate and promoted later by the
+# caller (the go bot gates promotion on walk-forward validation).
 
 import logging
 import numpy as np
 import os
 import json
+import shutil
 from typing import Optional
 from datetime import datetime
 
@@ -24,6 +27,12 @@ try:
 except ImportError:
     SKLEARN_AVAILABLE = False
 
+MODEL = "lstm_price.pt"
+BACKUP_MODEL = "lstm_price_backup.pt"
+SCALER = "scaler_params.json"
+CANDIDATE_MODEL = "lstm_price_candidate.pt"
+CANDIDATE_SCALER = "scaler_params_candidate.json"
+
 
 class RetrainingPipeline:
     """automated retraining with validation and model promotion"""
@@ -34,26 +43,37 @@ class RetrainingPipeline:
         self.retrain_history: list[dict] = []
 
     def retrain(self, features: np.ndarray, closes: np.ndarray,
-                seq_length: int = 30, epochs: int = 50) -> dict:
+                seq_length: int = 30, epochs: int = 50,
+                stage_only: bool = False, extra: Optional[list] = None) -> dict:
         """runs the full retraining pipeline:
         1. creates sequences from new data
         2. trains new model with walk-forward validation
         3. compares against current model
-        4. promotes if improved
+        4. promotes if improved, or stages it as a candidate when stage_only
+
+        extra is a list of (features, closes) series, e.g. other symbols, pooled
+        into the training set. sequences never span two series.
         """
         if not TORCH_AVAILABLE:
             return {"success": False, "reason": "pytorch not available"}
 
-        if len(features) < seq_length + 10:
-            return {"success": False, "reason": "insufficient data for retraining"}
+        X_train, X_val, y_train, y_val = [], [], [], []
+        for f, c in [(features, closes)] + list(extra or []):
+            if len(f) < seq_length + 10:
+                return {"success": False, "reason": "insufficient data for retraining"}
 
-        # create sequences
-        X, y = self._create_sequences(features, closes, seq_length)
+            # create sequences
+            X, y = self._create_sequences(f, c, seq_length)
 
-        # walk-forward split
-        split = int(len(X) * 0.8)
-        X_train, X_val = X[:split], X[split:]
-        y_train, y_val = y[:split], y[split:]
+            # walk-forward split, per series so validation is always the latest data
+            split = int(len(X) * 0.8)
+            X_train.append(X[:split])
+            X_val.append(X[split:])
+            y_train.append(y[:split])
+            y_val.append(y[split:])
+
+        X_train, X_val = np.concatenate(X_train), np.concatenate(X_val)
+        y_train, y_val = np.concatenate(y_train), np.concatenate(y_val)
 
         if len(X_train) < 10 or len(X_val) < 5:
             return {"success": False, "reason": "insufficient data after split"}
@@ -81,7 +101,12 @@ class RetrainingPipeline:
             "validation_samples": len(X_val),
         }
 
-        if should_promote:
+        if stage_only:
+            self._save_model(new_model, features, CANDIDATE_MODEL, CANDIDATE_SCALER)
+            result["promoted"] = False
+            result["staged"] = True
+            result["message"] = "new model staged as candidate — awaiting promotion"
+        elif should_promote:
             self._promote_model(new_model, features)
             result["message"] = "new model promoted — improved performance"
         else:
@@ -90,6 +115,28 @@ class RetrainingPipeline:
         self.retrain_history.append(result)
         return result
 
+    def promote_candidate(self) -> dict:
+        """promotes the staged candidate, backing up the current model"""
+        candidate_path = os.path.join(self.model_dir, CANDIDATE_MODEL)
+        if not os.path.exists(candidate_path):
+            return {"success": False, "reason": "no staged candidate model"}
+
+        model_path = os.path.join(self.model_dir, MODEL)
+        if os.path.exists(model_path):
+            shutil.copy2(model_path, os.path.join(self.model_dir, BACKUP_MODEL))
+
+        os.replace(candidate_path, model_path)
+        candidate_scaler = os.path.join(self.model_dir, CANDIDATE_SCALER)
+        if os.path.exists(candidate_scaler):
+            os.replace(candidate_scaler, os.path.join(self.model_dir, SCALER))
+
+        logger.info("staged candidate model promoted")
+        return {
+            "success": True,
+            "message": "candidate model promoted",
+            "timestamp": datetime.utcnow().isoformat(),
+        }
+
     def walk_forward_validate(self, features: np.ndarray, closes: np.ndarray,
                               seq_length: int = 30, n_splits: int = 5) -> dict:
         """performs walk-forward cross-validation on the data"""
@@ -133,6 +180,59 @@ class RetrainingPipeline:
             "timestamp": datetime.utcnow().isoformat(),
         }
 
+    def validate_candidate(self, features: np.ndarray, closes: np.ndarray, holdout: int,
+                           seq_length: int = 30, n_splits: int = 5,
+                           extra: Optional[list] = None) -> dict:
+        """scores the staged candidate and the deployed model on the last `holdout`
+        candles of every series, which the candidate was not trained on, split
+        into n_splits consecutive folds. fold k pools the k-th slice of each
+        series' held-out data."""
+        if not TORCH_AVAILABLE:
+            return {"success": False, "reason": "pytorch not available"}
+
+        chunks = []
+        for f, c in [(features, closes)] + list(extra or []):
+            X, y = self._create_sequences(f, c, seq_length)
+            # sequence k predicts the move after candle seq_length + k; keep the
+            # ones that start from a held-out candle
+            start = max(len(f) - holdout - seq_length, 0)
+            X, y = X[start:], y[start:]
+            if len(X) < n_splits * 10:
+                return {"success": False, "reason": "insufficient held-out data for validation"}
+            chunks.append((np.array_split(X, n_splits), np.array_split(y, n_splits)))
+
+        input_size = chunks[0][0][0].shape[2]
+        candidate = self._load(CANDIDATE_MODEL, input_size)
+        if candidate is None:
+            return {"success": False, "reason": "no staged candidate model"}
+        deployed = self._load(MODEL, input_size)
+
+        fold_results = []
+        for fold in range(n_splits):
+            X_fold = np.concatenate([xs[fold] for xs, _ in chunks])
+            y_fold = np.concatenate([ys[fold] for _, ys in chunks])
+            metrics = self._evaluate(candidate, X_fold, y_fold)
+            current = 0.0
+            if deployed is not None:
+                current = self._evaluate(deployed, X_fold, y_fold)["direction_accuracy"]
+            metrics["deployed_direction_accuracy"] = current
+            metrics["improvement"] = round(metrics["direction_accuracy"] - current, 4)
+            metrics["fold"] = fold + 1
+            metrics["val_size"] = len(X_fold)
+            fold_results.append(metrics)
+
+        return {
+            "success": True,
+            "n_splits": n_splits,
+            "folds": fold_results,
+            "avg_direction_accuracy": round(float(np.mean([f["direction_accuracy"] for f in fold_results])), 4),
+            "avg_deployed_direction_accuracy": round(float(np.mean([f["deployed_direction_accuracy"] for f in fold_results])), 4),
+            "avg_improvement": round(float(np.mean([f["improvement"] for f in fold_results])), 4),
+            "folds_improved": sum(1 for f in fold_results if f["improvement"] > 0),
+            "avg_magnitude_rmse": round(float(np.mean([f["magnitude_rmse"] for f in fold_results])), 4),
+            "timestamp": datetime.utcnow().isoformat(),
+        }
+
     def _create_sequences(self, features: np.ndarray, closes: np.ndarray,
                           seq_length: int) -> tuple:
         """creates sequences for LSTM training"""
@@ -221,17 +321,24 @@ class RetrainingPipeline:
 
     def _evaluate_current(self, X_val: np.ndarray, y_val: np.ndarray) -> dict:
         """evaluates the currently deployed model"""
-        model_path = os.path.join(self.model_dir, "lstm_price.pt")
-        if not os.path.exists(model_path) or not TORCH_AVAILABLE:
+        model = self._load(MODEL, X_val.shape[2]) if TORCH_AVAILABLE else None
+        if model is None:
             return {"direction_accuracy": 0.0, "magnitude_rmse": float("inf")}
+        return self._evaluate(model, X_val, y_val)
 
+    def _load(self, model_name: str, input_size: int):
+        """loads saved model weights, or None if there are none"""
+        model_path = os.path.join(self.model_dir, model_name)
+        if not os.path.exists(model_path):
+            return None
         try:
             from app.predictor import LSTMModel
-            model = LSTMModel(input_size=X_val.shape[2])
+            model = LSTMModel(input_size=input_size)
             model.load_state_dict(torch.load(model_path, map_location="cpu"))
-            return self._evaluate(model, X_val, y_val)
-        except Exception:
-            return {"direction_accuracy": 0.0, "magnitude_rmse": float("inf")}
+            return model
+        except Exception as e:
+            logger.warning(f"failed to load {model_name}: {e}")
+            return None
 
     def _should_promote(self, new_metrics: dict, current_metrics: dict) -> bool:
         """decides if the new model is better than the current one"""
@@ -241,17 +348,20 @@ class RetrainingPipeline:
 
     def _promote_model(self, model, features: np.ndarray):
         """saves the new model and scaler params, backing up the old one"""
-        os.makedirs(self.model_dir, exist_ok=True)
-
-        model_path = os.path.join(self.model_dir, "lstm_price.pt")
-        backup_path = os.path.join(self.model_dir, "lstm_price_backup.pt")
+        model_path = os.path.join(self.model_dir, MODEL)
+        backup_path = os.path.join(self.model_dir, BACKUP_MODEL)
 
         # backup current model
         if os.path.exists(model_path):
-            import shutil
             shutil.copy2(model_path, backup_path)
 
-        torch.save(model.state_dict(), model_path)
+        self._save_model(model, features, MODEL, SCALER)
+        logger.info("new model promoted and saved")
+
+    def _save_model(self, model, features: np.ndarray, model_name: str, scaler_name: str):
+        """saves model weights and the scaler params of its training features"""
+        os.makedirs(self.model_dir, exist_ok=True)
+        torch.save(model.state_dict(), os.path.join(self.model_dir, model_name))
 
         # update scaler params
         if features.ndim == 3:
@@ -262,8 +372,6 @@ class RetrainingPipeline:
         std = flat.std(axis=0)
         std[std == 0] = 1
 
-        scaler_path = os.path.join(self.model_dir, "scaler_params.json")
+        scaler_path = os.path.join(self.model_dir, scaler_name)
         with open(scaler_path, "w") as f:
             json.dump({"mean": mean.tolist(), "std": std.tolist()}, f)
-
-        logger.info("new model promoted and saved")
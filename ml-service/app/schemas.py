@@ -116,7 +116,9 @@ class DriftCheckResponse(BaseModel):
 class RetrainRequest(BaseModel):
     """input for retraining pipeline"""
     candles: list[Candle] = Field(..., min_length=60, description="training candle data")
+    series: list[list[Candle]] = Field(default_factory=list, description="more candle series (other symbols or timeframes) pooled into training")
     epochs: int = Field(default=50, ge=1, le=500)
+    stage_only: bool = Field(default=False, description="stage the new model as a candidate instead of promoting it")
 
 
 class RetrainResponse(BaseModel):
@@ -125,12 +127,21 @@ class RetrainResponse(BaseModel):
     message: Optional[str] = None
     reason: Optional[str] = None
     promoted: Optional[bool] = None
+    staged: Optional[bool] = None
     new_model_metrics: Optional[dict] = None
     current_model_metrics: Optional[dict] = None
     training_samples: Optional[int] = None
     validation_samples: Optional[int] = None
 
 
+class PromoteResponse(BaseModel):
+    """output from promoting the staged candidate model"""
+    success: bool
+    message: Optional[str] = None
+    reason: Optional[str] = None
+    timestamp: Optional[str] = None
+
+
 # --- walk-forward ---
 
 class WalkForwardRequest(BaseModel):
@@ -149,6 +160,27 @@ class WalkForwardResponse(BaseModel):
     avg_magnitude_rmse: Optional[float] = None
 
 
+class CandidateValidationRequest(BaseModel):
+    """input for validating the staged candidate on candles it wasn't trained on"""
+    candles: list[Candle] = Field(..., min_length=60, description="training and held-out candle data")
+    series: list[list[Candle]] = Field(default_factory=list, description="the other series the candidate was trained on, with their held-out candles")
+    holdout: int = Field(..., ge=20, description="latest candles the candidate was not trained on")
+    n_splits: int = Field(default=5, ge=2, le=20)
+
+
+class CandidateValidationResponse(BaseModel):
+    """output from validating the staged candidate against the deployed model"""
+    success: bool
+    reason: Optional[str] = None
+    n_splits: Optional[int] = None
+    folds: Optional[list[dict]] = None
+    avg_direction_accuracy: Optional[float] = None
+    avg_deployed_direction_accuracy: Optional[float] = None
+    avg_improvement: Optional[float] = None
+    folds_improved: Optional[int] = None
+    avg_magnitude_rmse: Optional[float] = None
+
+
 # --- RL agent ---
 
 class RLTrainRequest(BaseModel):
@@ -118,6 +118,13 @@ class TestRetrainingPipeline:
         result = self.pipeline.retrain(features, closes)
         assert result["success"] is False
 
+    def test_insufficient_pooled_series(self):
+        features = np.random.randn(100, 5)
+        closes = np.cumsum(np.random.randn(100)) + 40000
+        short = (np.random.randn(20, 5), np.random.randn(20) * 100 + 3000)
+        result = self.pipeline.retrain(features, closes, extra=[short])
+        assert result["success"] is False
+
     def test_create_sequences(self):
         features = np.random.randn(100, 5)
         closes = np.cumsum(np.random.randn(100)) + 40000
@@ -147,3 +154,38 @@ class TestRetrainingPipeline:
         closes = np.random.randn(20) * 100 + 40000
         result = self.pipeline.walk_forward_validate(features, closes)
         assert result["success"] is False
+
+    def test_validate_candidate_insufficient_holdout(self, tmp_path):
+        pipeline = RetrainingPipeline(model_dir=str(tmp_path))
+        features = np.random.randn(300, 5)
+        closes = np.cumsum(np.random.randn(300)) + 40000
+        result = pipeline.validate_candidate(features, closes, holdout=30, n_splits=5)
+        assert result["success"] is False
+
+    def test_validate_candidate_without_candidate(self, tmp_path):
+        pipeline = RetrainingPipeline(model_dir=str(tmp_path))
+        features = np.random.randn(300, 5)
+        closes = np.cumsum(np.random.randn(300)) + 40000
+        result = pipeline.validate_candidate(features, closes, holdout=100, n_splits=5)
+        assert result["success"] is False
+
+    def test_promote_without_candidate(self, tmp_path):
+        pipeline = RetrainingPipeline(model_dir=str(tmp_path))
+        result = pipeline.promote_candidate()
+        assert result["success"] is False
+        assert "candidate" in result["reason"]
+
+    def test_promote_candidate_backs_up_current(self, tmp_path):
+        (tmp_path / "lstm_price.pt").write_bytes(b"current")
+        (tmp_path / "lstm_price_candidate.pt").write_bytes(b"candidate")
+        (tmp_path / "scaler_params_candidate.json").write_text('{"mean": [0], "std": [1]}')
+        pipeline = RetrainingPipeline(model_dir=str(tmp_path))
+
+        result = pipeline.promote_candidate()
+
+        assert result["success"] is True
+        assert (tmp_path / "lstm_price.pt").read_bytes() == b"candidate"
+        assert (tmp_path / "lstm_price_backup.pt").read_bytes() == b"current"
+        assert (tmp_path / "scaler_params.json").exists()
+        assert not (tmp_path / "lstm_price_candidate.pt").exists()
+        assert pipeline.promote_candidate()["success"] is False
@@ -0,0 +1,101 @@
+// donchian channel
+// highest high / lowest low over a lookback, with breakouts judged against the prior channel
+
+/// donchian result for the latest bar
+pub struct DonchianResult {
+    pub upper: f64,
+    pub middle: f64,
+    pub lower: f64,
+    pub width_percent: f64, // (upper - lower) / middle * 100
+    pub signal: String,     // BREAKOUT_UP, BREAKOUT_DOWN, INSIDE
+}
+
+/// calculates the donchian channel over the last `period` bars (default 20).
+/// a breakout is a close beyond the channel of the `period` bars before it.
+pub fn calculate(
+    highs: &[f64],
+    lows: &[f64],
+    closes: &[f64],
+    period: usize,
+) -> Option<DonchianResult> {
+    let len = closes.len();
+    if period == 0 || len < period + 1 || highs.len() != len || lows.len() != len {
+        return None;
+    }
+
+    let (upper, lower) = channel(highs, lows, len - period, len);
+    let (prev_upper, prev_lower) = channel(highs, lows, len - 1 - period, len - 1);
+    let middle = (upper + lower) / 2.0;
+    let width_percent = if middle.abs() > 1e-10 {
+        (upper - lower) / middle * 100.0
+    } else {
+        0.0
+    };
+
+    let close = closes[len - 1];
+    let signal = if close > prev_upper {
+        "BREAKOUT_UP"
+    } else if close < prev_lower {
+        "BREAKOUT_DOWN"
+    } else {
+        "INSIDE"
+    };
+
+    Some(DonchianResult {
+        upper,
+        middle,
+        lower,
+        width_percent,
+        signal: signal.to_string(),
+    })
+}
+
+fn channel(highs: &[f64], lows: &[f64], start: usize, end: usize) -> (f64, f64) {
+    let upper = highs[start..end]
+        .iter()
+        .cloned()
+        .fold(f64::NEG_INFINITY, f64::max);
+    let lower = lows[start..end]
+        .iter()
+        .cloned()
+        .fold(f64::INFINITY, f64::min);
+    (upper, lower)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_donchian_channel() {
+        let h = vec![10.0, 12.0, 11.0, 13.0, 12.0];
+        let l = vec![8.0, 9.0, 7.0, 10.0, 11.0];
+        let c = vec![9.0, 11.0, 10.0, 12.0, 11.5];
+        let result = calculate(&h, &l, &c, 3).unwrap();
+        assert!((result.upper - 13.0).abs() < 1e-10);
+        assert!((result.lower - 7.0).abs() < 1e-10);
+        assert!((result.middle - 10.0).abs() < 1e-10);
+        assert!((result.width_percent - 60.0).abs() < 1e-10);
+        assert_eq!(result.signal, "INSIDE");
+    }
+
+    #[test]
+    fn test_donchian_breakouts() {
+        let h = vec![10.0, 10.0, 10.0, 15.0];
+        let l = vec![9.0, 9.0, 9.0, 13.0];
+        let c = vec![9.5, 9.5, 9.5, 14.0];
+        assert_eq!(calculate(&h, &l, &c, 3).unwrap().signal, "BREAKOUT_UP");
+
+        let h = vec![10.0, 10.0, 10.0, 8.0];
+        let l = vec![9.0, 9.0, 9.0, 6.0];
+        let c = vec![9.5, 9.5, 9.5, 7.0];
+        assert_eq!(calculate(&h, &l, &c, 3).unwrap().signal, "BREAKOUT_DOWN");
+    }
+
+    #[test]
+    fn test_donchian_insufficient_data() {
+        let p = vec![1.0; 3];
+        assert!(calculate(&p, &p, &p, 3).is_none());
+        assert!(calculate(&p, &p, &p, 0).is_none());
+    }
+}
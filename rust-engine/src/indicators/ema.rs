@@ -49,6 +49,23 @@ pub fn trend(price: f64, ema_value: f64) -> &'static str {
     }
 }
 
+/// classifies how a set of emas is stacked. takes (period, value) pairs
+/// sorted by period: BULLISH when every shorter ema is above the longer one,
+/// BEARISH when every one is below, MIXED otherwise. fewer than two emas
+/// give an empty string.
+pub fn alignment(emas: &[(usize, f64)]) -> String {
+    if emas.len() < 2 {
+        return String::new();
+    }
+    if emas.windows(2).all(|w| w[0].1 > w[1].1) {
+        "BULLISH".to_string()
+    } else if emas.windows(2).all(|w| w[0].1 < w[1].1) {
+        "BEARISH".to_string()
+    } else {
+        "MIXED".to_string()
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -165,4 +182,15 @@ mod tests {
         assert_eq!(result.len(), 5);
         assert!((result[4] - 3.0).abs() < 1e-10); // sma = 15/5 = 3
     }
+
+    #[test]
+    fn test_alignment() {
+        assert_eq!(
+            alignment(&[(12, 105.0), (26, 103.0), (50, 100.0)]),
+            "BULLISH"
+        );
+        assert_eq!(alignment(&[(12, 95.0), (26, 97.0), (50, 100.0)]), "BEARISH");
+        assert_eq!(alignment(&[(12, 101.0), (26, 99.0), (50, 100.0)]), "MIXED");
+        assert_eq!(alignment(&[(12, 101.0)]), "");
+    }
 }
@@ -0,0 +1,218 @@
+// golden values shared with go-bot/internal/analysis/local_test.go.
+// the go port of the indicators is checked against these numbers, so they are
+// rebuilt here from the same candles with the AnalyzeAll defaults.
+//
+// after an intentional indicator change, print the new values with
+//   cargo test golden -- --ignored --nocapture
+// and paste them into both this table and the go test.
+
+use super::*;
+
+struct Candles {
+    highs: Vec<f64>,
+    lows: Vec<f64>,
+    closes: Vec<f64>,
+    volumes: Vec<f64>,
+    timestamps: Vec<i64>,
+}
+
+/// 120 bars 4h apart starting at the epoch, the last one with a volume spike.
+/// must stay identical to goldenCandles in the go test.
+fn golden_candles() -> Candles {
+    let n = 120;
+    let mut c = Candles {
+        highs: Vec::with_capacity(n),
+        lows: Vec::with_capacity(n),
+        closes: Vec::with_capacity(n),
+        volumes: Vec::with_capacity(n),
+        timestamps: Vec::with_capacity(n),
+    };
+    for i in 0..n {
+        let x = i as f64;
+        let base = 100.0 + 10.0 * (x * 0.15).sin() + x * 0.3;
+        let mut vol = 1000.0 + 300.0 * (x * 0.9).sin();
+        if i == n - 1 {
+            vol += 2500.0;
+        }
+        c.highs.push(base + 1.0 + 0.5 * (x * 0.7).cos().abs());
+        c.lows.push(base - 1.0 - 0.5 * (x * 0.4).sin().abs());
+        c.closes.push(base + 0.3 * (x * 1.3).sin());
+        c.volumes.push(vol);
+        c.timestamps.push(i as i64 * 4 * 3600);
+    }
+    c
+}
+
+/// runs every indicator with the server's default parameters and returns the
+/// values the go test asserts, named as in its assertClose calls.
+fn compute() -> Vec<(&'static str, f64)> {
+    let c = golden_candles();
+    let (h, l, cl) = (&c.highs, &c.lows, &c.closes);
+
+    let rsi = rsi::calculate(cl, 14).unwrap();
+    let macd = macd::calculate(cl, 12, 26, 9).unwrap();
+    let bb = bollinger::calculate(cl, 20, 2.0).unwrap();
+    let ema21 = ema::latest(cl, 21).unwrap();
+    let vol = volume::detect(&c.volumes, 20, 2.0).unwrap();
+    let atr = atr::calculate(h, l, cl, 14).unwrap();
+    let adx = adx::calculate(h, l, cl, 14).unwrap();
+    let stoch = stochastic::calculate(h, l, cl, 14, 3, 3).unwrap();
+    let regime = regime::classify(h, l, cl, 14).unwrap();
+    let vwap = vwap::calculate(h, l, cl, &c.volumes, &c.timestamps, 0).unwrap();
+    let vwap_at = vwap::calculate(h, l, cl, &c.volumes, &c.timestamps, 100 * 4 * 3600 + 1).unwrap();
+    let ichi = ichimoku::calculate(h, l, cl, 9, 26, 52).unwrap();
+    let obv = obv::calculate(cl, &c.volumes, 20).unwrap();
+    let st = supertrend::calculate(h, l, cl, 10, 3.0).unwrap();
+    let don = donchian::calculate(h, l, cl, 20).unwrap();
+    let piv = pivots::calculate(h, l, cl, &c.timestamps).unwrap();
+
+    assert_eq!(vwap_at.anchor_timestamp, 1454400);
+    assert_eq!(piv.session_timestamp, 1555200);
+
+    vec![
+        ("rsi", rsi.value),
+        ("rsi[13]", rsi.series[13]),
+        ("rsi[50]", rsi.series[50]),
+        ("macd", macd.macd_line),
+        ("macd signal", macd.signal_line),
+        ("macd hist", macd.histogram),
+        ("macd hist[33]", macd.histogram_series[33]),
+        ("macd hist[80]", macd.histogram_series[80]),
+        ("bb upper", bb.upper),
+        ("bb middle", bb.middle),
+        ("bb lower", bb.lower),
+        ("bb bandwidth", bb.bandwidth),
+        ("bb %b", bb.percent_b),
+        ("ema", ema21),
+        ("volume avg", vol.average_volume),
+        ("volume ratio", vol.ratio),
+        ("atr", atr.value),
+        ("atr %", atr.percent),
+        ("atr[13]", atr.series[13]),
+        ("atr[60]", atr.series[60]),
+        ("adx", adx.value),
+        ("+di", adx.plus_di),
+        ("-di", adx.minus_di),
+        ("adx[26]", adx.series[26]),
+        ("adx[90]", adx.series[90]),
+        ("stoch k", stoch.k),
+        ("stoch d", stoch.d),
+        ("stoch d[17]", stoch.d_series[17]),
+        ("stoch d[70]", stoch.d_series[70]),
+        ("regime confidence", regime.confidence),
+        ("vwap", vwap.session),
+        ("vwap upper", vwap.session_upper),
+        ("vwap lower", vwap.session_lower),
+        ("vwap anchored", vwap.anchored),
+        ("vwap anchored@101", vwap_at.anchored),
+        ("tenkan", ichi.tenkan),
+        ("kijun", ichi.kijun),
+        ("senkou a", ichi.senkou_a),
+        ("senkou b", ichi.senkou_b),
+        ("obv", obv.value),
+        ("obv slope", obv.slope),
+        ("obv[60]", obv.series[60]),
+        ("supertrend", st.value),
+        ("supertrend[10]", st.series[10]),
+        ("supertrend[70]", st.series[70]),
+        ("donchian upper", don.upper),
+        ("donchian lower", don.lower),
+        ("donchian width", don.width_percent),
+        ("pivot", piv.classic.pivot),
+        ("r1", piv.classic.r1),
+        ("r3", piv.classic.r3),
+        ("s2", piv.classic.s2),
+        ("s3", piv.classic.s3),
+        ("fib r2", piv.fibonacci.r2),
+        ("fib s1", piv.fibonacci.s1),
+        ("ema12", ema::latest(cl, 12).unwrap()),
+        ("ema26", ema::latest(cl, 26).unwrap()),
+        ("ema50", ema::latest(cl, 50).unwrap()),
+    ]
+}
+
+const GOLDEN: &[(&str, f64)] = &[
+    ("rsi", 46.12416852922325),
+    ("rsi[13]", 93.68702441508734),
+    ("rsi[50]", 90.04127463268296),
+    ("macd", -1.209678612250201),
+    ("macd signal", -0.8388896912117019),
+    ("macd hist", -0.37078892103849914),
+    ("macd hist[33]", -0.20031591965045914),
+    ("macd hist[80]", 0.41917364087126385),
+    ("bb upper", 136.3354659714733),
+    ("bb middle", 128.467209160638),
+    ("bb lower", 120.59895234980267),
+    ("bb bandwidth", 0.12249439934507622),
+    ("bb %b", 0.4118851176009358),
+    ("ema", 127.67794019190865),
+    ("volume avg", 996.3377224353628),
+    ("volume ratio", 3.5977678792844143),
+    ("atr", 2.6254080740447385),
+    ("atr %", 2.065939505804016),
+    ("atr[13]", 2.767641184187712),
+    ("atr[60]", 2.742078794815643),
+    ("adx", 35.09756860156656),
+    ("+di", 13.043903375213823),
+    ("-di", 15.535713524830305),
+    ("adx[26]", 38.54139063450936),
+    ("adx[90]", 49.938711761159276),
+    ("stoch k", 38.70541121873335),
+    ("stoch d", 27.91967514290663),
+    ("stoch d[17]", 79.1172808941217),
+    ("stoch d[70]", 9.630606808830734),
+    ("regime confidence", 70.19513720313311),
+    ("vwap", 125.91658240533076),
+    ("vwap upper", 127.17100322736798),
+    ("vwap lower", 124.66216158329354),
+    ("vwap anchored", 118.19275311495367),
+    ("vwap anchored@101", 127.8620238445712),
+    ("tenkan", 125.58573924713352),
+    ("kijun", 131.36429142608824),
+    ("senkou a", 128.66630460982432),
+    ("senkou b", 124.7786602444445),
+    ("obv", 8541.801499325611),
+    ("obv slope", -0.373702844202912),
+    ("obv[60]", 8499.013936691346),
+    ("supertrend", 132.17728785653404),
+    ("supertrend[10]", 104.65212658721724),
+    ("supertrend[70]", 120.25537746754452),
+    ("donchian upper", 137.8195380031143),
+    ("donchian lower", 122.85989990381107),
+    ("donchian width", 11.477420865580136),
+    ("pivot", 125.59105537223964),
+    ("r1", 128.21570008422816),
+    ("r3", 134.41492562636577),
+    ("s2", 119.39182983010203),
+    ("s3", 115.81724899995294),
+    ("fib r2", 129.42217675728068),
+    ("fib s1", 123.22295121514307),
+    ("ema12", 126.6329066173362),
+    ("ema26", 127.8425852295864),
+    ("ema50", 126.39438455229015),
+];
+
+#[test]
+fn test_matches_go_golden_values() {
+    let got = compute();
+    assert_eq!(got.len(), GOLDEN.len());
+    for ((name, value), (want_name, want)) in got.iter().zip(GOLDEN) {
+        assert_eq!(name, want_name);
+        let tol = 1e-9 * want.abs().max(1.0);
+        assert!(
+            (value - want).abs() <= tol,
+            "{} = {:?}, golden = {:?}",
+            name,
+            value,
+            want
+        );
+    }
+}
+
+#[test]
+#[ignore]
+fn print_golden_values() {
+    for (name, value) in compute() {
+        println!("assertClose(t, {:?}, ..., {:?})", name, value);
+    }
+}
@@ -0,0 +1,157 @@
+// ichimoku kinko hyo
+// tenkan/kijun midpoints plus the cloud projected kijun periods ahead
+
+/// ichimoku result for the latest bar
+pub struct IchimokuResult {
+    pub tenkan: f64,
+    pub kijun: f64,
+    pub senkou_a: f64,    // span a under the current bar (computed kijun bars ago)
+    pub senkou_b: f64,    // span b under the current bar
+    pub chikou: f64,      // lagging span: the current close
+    pub signal: String,   // ABOVE_CLOUD, IN_CLOUD, BELOW_CLOUD
+    pub tk_cross: String, // BULLISH, BEARISH, NONE
+    pub future_cloud: String, // BULLISH (span a >= span b), BEARISH
+}
+
+/// calculates ichimoku. tenkan (default 9), kijun (default 26), senkou (default 52).
+/// needs senkou + kijun candles so the cloud under the current bar exists.
+pub fn calculate(
+    highs: &[f64],
+    lows: &[f64],
+    closes: &[f64],
+    tenkan_period: usize,
+    kijun_period: usize,
+    senkou_period: usize,
+) -> Option<IchimokuResult> {
+    let len = closes.len();
+    if tenkan_period == 0
+        || kijun_period == 0
+        || senkou_period == 0
+        || highs.len() != len
+        || lows.len() != len
+        || len < senkou_period + kijun_period
+    {
+        return None;
+    }
+
+    let last = len - 1;
+    let tenkan = midpoint(highs, lows, last, tenkan_period);
+    let kijun = midpoint(highs, lows, last, kijun_period);
+
+    // the cloud under the current bar was plotted kijun_period bars ago
+    let origin = last - kijun_period;
+    let senkou_a = (midpoint(highs, lows, origin, tenkan_period)
+        + midpoint(highs, lows, origin, kijun_period))
+        / 2.0;
+    let senkou_b = midpoint(highs, lows, origin, senkou_period);
+
+    let close = closes[last];
+    let signal = if close > senkou_a.max(senkou_b) {
+        "ABOVE_CLOUD"
+    } else if close < senkou_a.min(senkou_b) {
+        "BELOW_CLOUD"
+    } else {
+        "IN_CLOUD"
+    };
+
+    let prev_tenkan = midpoint(highs, lows, last - 1, tenkan_period);
+    let prev_kijun = midpoint(highs, lows, last - 1, kijun_period);
+    let tk_cross = if prev_tenkan <= prev_kijun && tenkan > kijun {
+        "BULLISH"
+    } else if prev_tenkan >= prev_kijun && tenkan < kijun {
+        "BEARISH"
+    } else {
+        "NONE"
+    };
+
+    let future_a = (tenkan + kijun) / 2.0;
+    let future_b = midpoint(highs, lows, last, senkou_period);
+    let future_cloud = if future_a >= future_b {
+        "BULLISH"
+    } else {
+        "BEARISH"
+    };
+
+    Some(IchimokuResult {
+        tenkan,
+        kijun,
+        senkou_a,
+        senkou_b,
+        chikou: close,
+        signal: signal.to_string(),
+        tk_cross: tk_cross.to_string(),
+        future_cloud: future_cloud.to_string(),
+    })
+}
+
+/// (highest high + lowest low) / 2 over the `period` bars ending at `end`.
+/// callers guarantee end + 1 >= period.
+fn midpoint(highs: &[f64], lows: &[f64], end: usize, period: usize) -> f64 {
+    let start = end + 1 - period;
+    let highest = highs[start..=end]
+        .iter()
+        .cloned()
+        .fold(f64::NEG_INFINITY, f64::max);
+    let lowest = lows[start..=end]
+        .iter()
+        .cloned()
+        .fold(f64::INFINITY, f64::min);
+    (highest + lowest) / 2.0
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn trend(n: usize, step: f64) -> (Vec<f64>, Vec<f64>, Vec<f64>) {
+        let c: Vec<f64> = (0..n).map(|i| 100.0 + i as f64 * step).collect();
+        let h = c.iter().map(|x| x + 1.0).collect();
+        let l = c.iter().map(|x| x - 1.0).collect();
+        (h, l, c)
+    }
+
+    #[test]
+    fn test_ichimoku_uptrend_above_cloud() {
+        let (h, l, c) = trend(100, 1.0);
+        let result = calculate(&h, &l, &c, 9, 26, 52).unwrap();
+        assert_eq!(result.signal, "ABOVE_CLOUD");
+        assert_eq!(result.future_cloud, "BULLISH");
+        assert!(result.tenkan > result.kijun);
+        // tenkan = midpoint of closes 91..99 = 195
+        assert!((result.tenkan - 195.0).abs() < 1e-10);
+        assert!((result.chikou - 199.0).abs() < 1e-10);
+    }
+
+    #[test]
+    fn test_ichimoku_downtrend_below_cloud() {
+        let (h, l, c) = trend(100, -1.0);
+        let result = calculate(&h, &l, &c, 9, 26, 52).unwrap();
+        assert_eq!(result.signal, "BELOW_CLOUD");
+        assert_eq!(result.future_cloud, "BEARISH");
+    }
+
+    #[test]
+    fn test_ichimoku_tk_cross() {
+        // 70 bars down, then a rally; tenkan overtakes kijun on the 9th up bar
+        let mut c: Vec<f64> = (0..70).map(|i| 200.0 - i as f64).collect();
+        c.extend((1..=9).map(|j| 131.0 + 3.0 * j as f64));
+        let h: Vec<f64> = c.iter().map(|x| x + 1.0).collect();
+        let l: Vec<f64> = c.iter().map(|x| x - 1.0).collect();
+
+        let result = calculate(&h, &l, &c, 9, 26, 52).unwrap();
+        assert_eq!(result.tk_cross, "BULLISH");
+        assert!((result.tenkan - 146.0).abs() < 1e-10);
+        assert!((result.kijun - 144.5).abs() < 1e-10);
+
+        let before = calculate(&h[..78], &l[..78], &c[..78], 9, 26, 52).unwrap();
+        assert_eq!(before.tk_cross, "NONE");
+    }
+
+    #[test]
+    fn test_ichimoku_insufficient_data() {
+        let (h, l, c) = trend(77, 1.0);
+        assert!(calculate(&h, &l, &c, 9, 26, 52).is_none());
+        let (h, l, c) = trend(78, 1.0);
+        assert!(calculate(&h, &l, &c, 9, 26, 52).is_some());
+    }
+}
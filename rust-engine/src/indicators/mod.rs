@@ -1,9 +1,18 @@
 pub mod adx;
 pub mod atr;
 pub mod bollinger;
+pub mod donchian;
 pub mod ema;
+pub mod ichimoku;
 pub mod macd;
+pub mod obv;
+pub mod pivots;
 pub mod regime;
 pub mod rsi;
 pub mod stochastic;
+pub mod supertrend;
 pub mod volume;
+pub mod vwap;
+
+#[cfg(test)]
+mod golden;
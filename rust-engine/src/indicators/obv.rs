@@ -0,0 +1,101 @@
+// on-balance volume
+// cumulative volume signed by close-to-close direction
+
+/// obv result with lookback slope and price divergence
+pub struct OBVResult {
+    pub value: f64,
+    pub slope: f64,         // net obv change over the lookback / volume traded (-1..1)
+    pub signal: String,     // RISING, FALLING, FLAT
+    pub divergence: String, // BULLISH, BEARISH, NONE
+    pub series: Vec<f64>,
+}
+
+/// calculates obv and compares its lookback change against price
+pub fn calculate(closes: &[f64], volumes: &[f64], lookback: usize) -> Option<OBVResult> {
+    let len = closes.len();
+    if lookback == 0 || len < lookback + 1 || volumes.len() != len {
+        return None;
+    }
+
+    let mut series = Vec::with_capacity(len);
+    series.push(0.0);
+    for i in 1..len {
+        let prev = series[i - 1];
+        let next = if closes[i] > closes[i - 1] {
+            prev + volumes[i]
+        } else if closes[i] < closes[i - 1] {
+            prev - volumes[i]
+        } else {
+            prev
+        };
+        series.push(next);
+    }
+
+    let last = len - 1;
+    let traded: f64 = volumes[len - lookback..].iter().sum();
+    let slope = if traded > 1e-10 {
+        (series[last] - series[last - lookback]) / traded
+    } else {
+        0.0
+    };
+
+    let signal = if slope > 0.2 {
+        "RISING"
+    } else if slope < -0.2 {
+        "FALLING"
+    } else {
+        "FLAT"
+    };
+
+    let price_change = closes[last] - closes[last - lookback];
+    let divergence = if price_change > 0.0 && slope < -0.2 {
+        "BEARISH"
+    } else if price_change < 0.0 && slope > 0.2 {
+        "BULLISH"
+    } else {
+        "NONE"
+    };
+
+    Some(OBVResult {
+        value: series[last],
+        slope,
+        signal: signal.to_string(),
+        divergence: divergence.to_string(),
+        series,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_obv_accumulates() {
+        let closes = vec![10.0, 11.0, 10.5, 10.5, 12.0];
+        let volumes = vec![100.0, 200.0, 50.0, 70.0, 300.0];
+        let result = calculate(&closes, &volumes, 4).unwrap();
+        assert_eq!(result.series, vec![0.0, 200.0, 150.0, 150.0, 450.0]);
+        assert!((result.value - 450.0).abs() < 1e-10);
+        // 450 net over 620 traded
+        assert!((result.slope - 450.0 / 620.0).abs() < 1e-10);
+        assert_eq!(result.signal, "RISING");
+        assert_eq!(result.divergence, "NONE");
+    }
+
+    #[test]
+    fn test_obv_bearish_divergence() {
+        // price grinds higher on thin volume, drops on heavy volume
+        let closes = vec![10.0, 9.0, 9.5, 9.0, 9.6, 10.5];
+        let volumes = vec![100.0, 500.0, 50.0, 500.0, 50.0, 50.0];
+        let result = calculate(&closes, &volumes, 5).unwrap();
+        assert_eq!(result.signal, "FALLING");
+        assert_eq!(result.divergence, "BEARISH");
+    }
+
+    #[test]
+    fn test_obv_insufficient_data() {
+        assert!(calculate(&[1.0, 2.0], &[1.0, 1.0], 2).is_none());
+        assert!(calculate(&[1.0, 2.0, 3.0], &[1.0, 1.0], 2).is_none());
+        assert!(calculate(&[1.0, 2.0, 3.0], &[1.0, 1.0, 1.0], 0).is_none());
+    }
+}
@@ -0,0 +1,146 @@
+// classic and fibonacci pivot points
+// levels come from the previous completed utc day's high, low and close
+
+const SECONDS_PER_DAY: i64 = 86_400;
+
+/// one set of pivot levels
+pub struct PivotLevels {
+    pub pivot: f64,
+    pub r1: f64,
+    pub r2: f64,
+    pub r3: f64,
+    pub s1: f64,
+    pub s2: f64,
+    pub s3: f64,
+}
+
+/// pivot result with both level sets
+pub struct PivotResult {
+    pub classic: PivotLevels,
+    pub fibonacci: PivotLevels,
+    pub session_timestamp: i64, // start of the utc day the levels come from
+    pub position: String,       // ABOVE_PIVOT, BELOW_PIVOT
+}
+
+/// calculates pivots from the utc day before the last candle's day.
+/// timestamps are unix seconds; daily or larger candles use the previous candle.
+/// returns None when the window doesn't reach back into a previous day.
+pub fn calculate(
+    highs: &[f64],
+    lows: &[f64],
+    closes: &[f64],
+    timestamps: &[i64],
+) -> Option<PivotResult> {
+    let len = closes.len();
+    if len < 2 || highs.len() != len || lows.len() != len || timestamps.len() != len {
+        return None;
+    }
+
+    // walk back past the current day, then collect the day before it
+    let current_day = day(timestamps[len - 1]);
+    let end = (0..len)
+        .rev()
+        .find(|&i| day(timestamps[i]) != current_day)?;
+    let prev_day = day(timestamps[end]);
+    let mut start = end;
+    while start > 0 && day(timestamps[start - 1]) == prev_day {
+        start -= 1;
+    }
+
+    let high = highs[start..=end]
+        .iter()
+        .cloned()
+        .fold(f64::NEG_INFINITY, f64::max);
+    let low = lows[start..=end]
+        .iter()
+        .cloned()
+        .fold(f64::INFINITY, f64::min);
+    let close = closes[end];
+
+    let pivot = (high + low + close) / 3.0;
+    let range = high - low;
+    let classic = PivotLevels {
+        pivot,
+        r1: 2.0 * pivot - low,
+        r2: pivot + range,
+        r3: high + 2.0 * (pivot - low),
+        s1: 2.0 * pivot - high,
+        s2: pivot - range,
+        s3: low - 2.0 * (high - pivot),
+    };
+    let fibonacci = PivotLevels {
+        pivot,
+        r1: pivot + 0.382 * range,
+        r2: pivot + 0.618 * range,
+        r3: pivot + range,
+        s1: pivot - 0.382 * range,
+        s2: pivot - 0.618 * range,
+        s3: pivot - range,
+    };
+
+    let position = if closes[len - 1] > pivot {
+        "ABOVE_PIVOT"
+    } else {
+        "BELOW_PIVOT"
+    };
+
+    Some(PivotResult {
+        classic,
+        fibonacci,
+        session_timestamp: prev_day * SECONDS_PER_DAY,
+        position: position.to_string(),
+    })
+}
+
+fn day(ts: i64) -> i64 {
+    ts.div_euclid(SECONDS_PER_DAY)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_pivots_from_previous_day() {
+        // two 12h candles yesterday, one today
+        let h = vec![110.0, 120.0, 200.0];
+        let l = vec![90.0, 100.0, 150.0];
+        let c = vec![100.0, 105.0, 160.0];
+        let ts = vec![
+            SECONDS_PER_DAY,
+            SECONDS_PER_DAY + 43_200,
+            2 * SECONDS_PER_DAY,
+        ];
+        let result = calculate(&h, &l, &c, &ts).unwrap();
+        // high 120, low 90, close 105
+        assert!((result.classic.pivot - 105.0).abs() < 1e-10);
+        assert!((result.classic.r1 - 120.0).abs() < 1e-10);
+        assert!((result.classic.s1 - 90.0).abs() < 1e-10);
+        assert!((result.classic.r2 - 135.0).abs() < 1e-10);
+        assert!((result.classic.s2 - 75.0).abs() < 1e-10);
+        assert!((result.classic.r3 - 150.0).abs() < 1e-10);
+        assert!((result.classic.s3 - 60.0).abs() < 1e-10);
+        assert!((result.fibonacci.r1 - (105.0 + 0.382 * 30.0)).abs() < 1e-10);
+        assert!((result.fibonacci.s3 - 75.0).abs() < 1e-10);
+        assert_eq!(result.session_timestamp, SECONDS_PER_DAY);
+        assert_eq!(result.position, "ABOVE_PIVOT");
+    }
+
+    #[test]
+    fn test_pivots_daily_candles() {
+        let h = vec![10.0, 20.0, 30.0];
+        let l = vec![5.0, 10.0, 15.0];
+        let c = vec![8.0, 15.0, 16.0];
+        let ts = vec![0, SECONDS_PER_DAY, 2 * SECONDS_PER_DAY];
+        let result = calculate(&h, &l, &c, &ts).unwrap();
+        assert!((result.classic.pivot - 15.0).abs() < 1e-10);
+        assert_eq!(result.position, "ABOVE_PIVOT");
+    }
+
+    #[test]
+    fn test_pivots_single_day() {
+        let p = vec![10.0; 3];
+        assert!(calculate(&p, &p, &p, &[0, 3600, 7200]).is_none());
+        assert!(calculate(&[1.0], &[1.0], &[1.0], &[0]).is_none());
+    }
+}
@@ -0,0 +1,125 @@
+// supertrend
+// atr bands around hl2 that ratchet with the trend and flip on a close through them
+
+use super::atr;
+
+/// supertrend result with the active band and direction
+pub struct SupertrendResult {
+    pub value: f64,        // support in an uptrend, resistance in a downtrend
+    pub direction: String, // UP, DOWN
+    pub flipped: bool,     // direction changed on the last bar
+    pub series: Vec<f64>,  // one entry per candle, 0.0 before the first atr
+}
+
+/// calculates supertrend. period (default 10), multiplier (default 3.0).
+/// the trend starts UP on the first bar with an atr value.
+pub fn calculate(
+    highs: &[f64],
+    lows: &[f64],
+    closes: &[f64],
+    period: usize,
+    multiplier: f64,
+) -> Option<SupertrendResult> {
+    let len = closes.len();
+    if period == 0 || len < period + 2 {
+        return None;
+    }
+    // atr series is aligned to true ranges: entry j belongs to candle j + 1
+    let atr_series = atr::calculate(highs, lows, closes, period)?.series;
+
+    let mut series = vec![0.0; len];
+    let mut up = true;
+    let mut prev_up = true;
+    let (mut final_upper, mut final_lower) = (0.0, 0.0);
+
+    for i in period..len {
+        let hl2 = (highs[i] + lows[i]) / 2.0;
+        let band = multiplier * atr_series[i - 1];
+        let basic_upper = hl2 + band;
+        let basic_lower = hl2 - band;
+
+        if i == period {
+            final_upper = basic_upper;
+            final_lower = basic_lower;
+        } else {
+            let prev_close = closes[i - 1];
+            if basic_upper < final_upper || prev_close > final_upper {
+                final_upper = basic_upper;
+            }
+            if basic_lower > final_lower || prev_close < final_lower {
+                final_lower = basic_lower;
+            }
+        }
+
+        prev_up = up;
+        if up && closes[i] < final_lower {
+            up = false;
+        } else if !up && closes[i] > final_upper {
+            up = true;
+        }
+        series[i] = if up { final_lower } else { final_upper };
+    }
+
+    Some(SupertrendResult {
+        value: series[len - 1],
+        direction: if up { "UP" } else { "DOWN" }.to_string(),
+        flipped: up != prev_up,
+        series,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn ohlc(closes: &[f64]) -> (Vec<f64>, Vec<f64>) {
+        (
+            closes.iter().map(|c| c + 1.0).collect(),
+            closes.iter().map(|c| c - 1.0).collect(),
+        )
+    }
+
+    #[test]
+    fn test_supertrend_uptrend() {
+        let c: Vec<f64> = (0..40).map(|i| 100.0 + i as f64).collect();
+        let (h, l) = ohlc(&c);
+        let result = calculate(&h, &l, &c, 10, 3.0).unwrap();
+        assert_eq!(result.direction, "UP");
+        assert!(result.value < c[39], "support should sit below price");
+        assert!(!result.flipped);
+        assert_eq!(result.series.len(), 40);
+        assert_eq!(result.series[9], 0.0);
+    }
+
+    #[test]
+    fn test_supertrend_flips_down() {
+        let mut c: Vec<f64> = (0..30).map(|i| 100.0 + i as f64 * 0.5).collect();
+        c.push(80.0);
+        let (h, l) = ohlc(&c);
+        let result = calculate(&h, &l, &c, 10, 3.0).unwrap();
+        assert_eq!(result.direction, "DOWN");
+        assert!(result.flipped);
+        assert!(result.value > 80.0, "resistance should sit above price");
+    }
+
+    #[test]
+    fn test_supertrend_bands_ratchet() {
+        let c: Vec<f64> = (0..40).map(|i| 100.0 + i as f64).collect();
+        let (h, l) = ohlc(&c);
+        let result = calculate(&h, &l, &c, 10, 3.0).unwrap();
+        for i in 11..40 {
+            assert!(
+                result.series[i] >= result.series[i - 1],
+                "support never drops in an uptrend"
+            );
+        }
+    }
+
+    #[test]
+    fn test_supertrend_insufficient_data() {
+        let c = vec![100.0; 11];
+        let (h, l) = ohlc(&c);
+        assert!(calculate(&h, &l, &c, 10, 3.0).is_none());
+        assert!(calculate(&h, &l, &c, 0, 3.0).is_none());
+    }
+}
@@ -0,0 +1,157 @@
+// volume weighted average price
+// session vwap resets at each utc day; anchored vwap runs from a chosen candle
+
+const SECONDS_PER_DAY: i64 = 86_400;
+
+/// vwap result with session bands and the anchored value
+pub struct VWAPResult {
+    pub session: f64,
+    pub session_upper: f64,
+    pub session_lower: f64,
+    pub anchored: f64,
+    pub anchor_timestamp: i64,
+    pub signal: String, // ABOVE, BELOW
+    pub session_series: Vec<f64>,
+}
+
+/// calculates session and anchored vwap from typical price (h+l+c)/3.
+/// timestamps are unix seconds; anchor = 0 anchors at the first candle.
+/// returns None when the current session or the anchored range has no volume.
+pub fn calculate(
+    highs: &[f64],
+    lows: &[f64],
+    closes: &[f64],
+    volumes: &[f64],
+    timestamps: &[i64],
+    anchor: i64,
+) -> Option<VWAPResult> {
+    let len = closes.len();
+    if len == 0
+        || highs.len() != len
+        || lows.len() != len
+        || volumes.len() != len
+        || timestamps.len() != len
+    {
+        return None;
+    }
+
+    let typical: Vec<f64> = (0..len)
+        .map(|i| (highs[i] + lows[i] + closes[i]) / 3.0)
+        .collect();
+
+    // running session vwap, reset whenever the utc day changes
+    let mut session_series = Vec::with_capacity(len);
+    let mut session_start = 0;
+    let (mut pv, mut vol) = (0.0, 0.0);
+    for i in 0..len {
+        if i > 0 && day(timestamps[i]) != day(timestamps[i - 1]) {
+            session_start = i;
+            pv = 0.0;
+            vol = 0.0;
+        }
+        pv += typical[i] * volumes[i];
+        vol += volumes[i];
+        session_series.push(if vol > 0.0 { pv / vol } else { 0.0 });
+    }
+    if vol <= 0.0 {
+        return None;
+    }
+    let session = pv / vol;
+
+    // volume-weighted standard deviation around the session vwap
+    let mut variance = 0.0;
+    for i in session_start..len {
+        variance += volumes[i] * (typical[i] - session).powi(2);
+    }
+    let std_dev = (variance / vol).sqrt();
+
+    let anchor_idx = timestamps.iter().position(|&t| t >= anchor)?;
+    let (mut apv, mut avol) = (0.0, 0.0);
+    for i in anchor_idx..len {
+        apv += typical[i] * volumes[i];
+        avol += volumes[i];
+    }
+    if avol <= 0.0 {
+        return None;
+    }
+
+    let signal = if closes[len - 1] > session {
+        "ABOVE"
+    } else {
+        "BELOW"
+    };
+
+    Some(VWAPResult {
+        session,
+        session_upper: session + std_dev,
+        session_lower: session - std_dev,
+        anchored: apv / avol,
+        anchor_timestamp: timestamps[anchor_idx],
+        signal: signal.to_string(),
+        session_series,
+    })
+}
+
+fn day(ts: i64) -> i64 {
+    ts.div_euclid(SECONDS_PER_DAY)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_vwap_single_session() {
+        let h = vec![11.0, 21.0, 31.0];
+        let l = vec![9.0, 19.0, 29.0];
+        let c = vec![10.0, 20.0, 30.0];
+        let v = vec![1.0, 1.0, 2.0];
+        let ts = vec![0, 3600, 7200];
+        let result = calculate(&h, &l, &c, &v, &ts, 0).unwrap();
+        // (10 + 20 + 60) / 4
+        assert!((result.session - 22.5).abs() < 1e-10);
+        assert!((result.anchored - 22.5).abs() < 1e-10);
+        assert_eq!(result.signal, "ABOVE");
+        assert!(result.session_upper > result.session && result.session_lower < result.session);
+    }
+
+    #[test]
+    fn test_vwap_resets_each_day() {
+        let h = vec![101.0, 101.0, 51.0];
+        let l = vec![99.0, 99.0, 49.0];
+        let c = vec![100.0, 100.0, 50.0];
+        let v = vec![5.0, 5.0, 1.0];
+        let ts = vec![
+            SECONDS_PER_DAY - 7200,
+            SECONDS_PER_DAY - 3600,
+            SECONDS_PER_DAY,
+        ];
+        let result = calculate(&h, &l, &c, &v, &ts, 0).unwrap();
+        assert!((result.session - 50.0).abs() < 1e-10);
+        assert!((result.session_series[1] - 100.0).abs() < 1e-10);
+        // anchored from the first candle still includes the previous day
+        assert!((result.anchored - 1050.0 / 11.0).abs() < 1e-10);
+        assert_eq!(result.signal, "BELOW");
+    }
+
+    #[test]
+    fn test_vwap_anchor() {
+        let h = vec![11.0, 21.0, 31.0];
+        let l = vec![9.0, 19.0, 29.0];
+        let c = vec![10.0, 20.0, 30.0];
+        let v = vec![1.0, 1.0, 1.0];
+        let ts = vec![0, 3600, 7200];
+        let result = calculate(&h, &l, &c, &v, &ts, 3600).unwrap();
+        assert!((result.anchored - 25.0).abs() < 1e-10);
+        assert_eq!(result.anchor_timestamp, 3600);
+        assert!(calculate(&h, &l, &c, &v, &ts, 9999).is_none());
+    }
+
+    #[test]
+    fn test_vwap_no_volume() {
+        let p = vec![10.0; 3];
+        let v = vec![0.0; 3];
+        assert!(calculate(&p, &p, &p, &v, &[0, 1, 2], 0).is_none());
+        assert!(calculate(&[], &[], &[], &[], &[], 0).is_none());
+    }
+}
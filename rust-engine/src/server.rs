@@ -9,7 +9,10 @@ pub mod proto {
 use proto::technical_indicators_server::TechnicalIndicators;
 use proto::*;
 
-use crate::indicators::{adx, atr, bollinger, ema, macd, regime, rsi, stochastic, volume};
+use crate::indicators::{
+    adx, atr, bollinger, donchian, ema, ichimoku, macd, obv, pivots, regime, rsi, stochastic,
+    supertrend, volume, vwap,
+};
 
 pub struct IndicatorService;
 
@@ -33,6 +36,24 @@ fn extract_lows(candles: &[Candle]) -> Vec<f64> {
     candles.iter().map(|c| c.low).collect()
 }
 
+// extracts unix-second timestamps from candle data
+fn extract_timestamps(candles: &[Candle]) -> Vec<i64> {
+    candles.iter().map(|c| c.timestamp).collect()
+}
+
+// maps one set of pivot levels to its proto message
+fn pivot_levels(l: pivots::PivotLevels) -> PivotLevels {
+    PivotLevels {
+        pivot: l.pivot,
+        r1: l.r1,
+        r2: l.r2,
+        r3: l.r3,
+        s1: l.s1,
+        s2: l.s2,
+        s3: l.s3,
+    }
+}
+
 #[tonic::async_trait]
 impl TechnicalIndicators for IndicatorService {
     async fn calculate_rsi(
@@ -323,6 +344,7 @@ impl TechnicalIndicators for IndicatorService {
         let volumes = extract_volumes(&req.candles);
         let highs = extract_highs(&req.candles);
         let lows = extract_lows(&req.candles);
+        let timestamps = extract_timestamps(&req.candles);
 
         // defaults
         let rsi_period = if req.rsi_period > 0 {
@@ -370,6 +392,47 @@ impl TechnicalIndicators for IndicatorService {
         } else {
             2.0
         };
+        let tenkan = if req.ichimoku_tenkan > 0 {
+            req.ichimoku_tenkan as usize
+        } else {
+            9
+        };
+        let kijun = if req.ichimoku_kijun > 0 {
+            req.ichimoku_kijun as usize
+        } else {
+            26
+        };
+        let senkou = if req.ichimoku_senkou > 0 {
+            req.ichimoku_senkou as usize
+        } else {
+            52
+        };
+        let st_period = if req.supertrend_period > 0 {
+            req.supertrend_period as usize
+        } else {
+            10
+        };
+        let st_multiplier = if req.supertrend_multiplier > 0.0 {
+            req.supertrend_multiplier
+        } else {
+            3.0
+        };
+        let donchian_period = if req.donchian_period > 0 {
+            req.donchian_period as usize
+        } else {
+            20
+        };
+        let mut ema_periods: Vec<usize> = req
+            .ema_periods
+            .iter()
+            .filter(|&&p| p > 0)
+            .map(|&p| p as usize)
+            .collect();
+        if ema_periods.is_empty() {
+            ema_periods = vec![12, 26, 50, 200];
+        }
+        ema_periods.sort_unstable();
+        ema_periods.dedup();
 
         // compute each indicator, use None if insufficient data
         let rsi_result = rsi::calculate(&closes, rsi_period);
@@ -513,6 +576,92 @@ impl TechnicalIndicators for IndicatorService {
             description: r.description,
         });
 
+        // structure and volume-flow indicators are informational and don't vote
+        let vwap_resp = vwap::calculate(
+            &highs,
+            &lows,
+            &closes,
+            &volumes,
+            &timestamps,
+            req.vwap_anchor,
+        )
+        .map(|r| VwapResponse {
+            session: r.session,
+            session_upper: r.session_upper,
+            session_lower: r.session_lower,
+            anchored: r.anchored,
+            anchor_timestamp: r.anchor_timestamp,
+            signal: r.signal,
+            session_series: r.session_series,
+        });
+
+        let ichimoku_resp =
+            ichimoku::calculate(&highs, &lows, &closes, tenkan, kijun, senkou).map(|r| {
+                IchimokuResponse {
+                    tenkan: r.tenkan,
+                    kijun: r.kijun,
+                    senkou_a: r.senkou_a,
+                    senkou_b: r.senkou_b,
+                    chikou: r.chikou,
+                    signal: r.signal,
+                    tk_cross: r.tk_cross,
+                    future_cloud: r.future_cloud,
+                }
+            });
+
+        let obv_resp = obv::calculate(&closes, &volumes, vol_lookback).map(|r| ObvResponse {
+            value: r.value,
+            slope: r.slope,
+            signal: r.signal,
+            divergence: r.divergence,
+            series: r.series,
+        });
+
+        let supertrend_resp =
+            supertrend::calculate(&highs, &lows, &closes, st_period, st_multiplier).map(|r| {
+                SupertrendResponse {
+                    value: r.value,
+                    direction: r.direction,
+                    flipped: r.flipped,
+                    series: r.series,
+                }
+            });
+
+        let donchian_resp = donchian::calculate(&highs, &lows, &closes, donchian_period).map(|r| {
+            DonchianResponse {
+                upper: r.upper,
+                middle: r.middle,
+                lower: r.lower,
+                width_percent: r.width_percent,
+                signal: r.signal,
+            }
+        });
+
+        let pivots_resp =
+            pivots::calculate(&highs, &lows, &closes, &timestamps).map(|r| PivotResponse {
+                classic: Some(pivot_levels(r.classic)),
+                fibonacci: Some(pivot_levels(r.fibonacci)),
+                session_timestamp: r.session_timestamp,
+                position: r.position,
+            });
+
+        // multi-period emas, skipping periods longer than the window
+        let last_close = *closes.last().unwrap_or(&0.0);
+        let ema_values: Vec<(usize, f64)> = ema_periods
+            .iter()
+            .filter(|&&p| closes.len() >= p)
+            .filter_map(|&p| ema::latest(&closes, p).map(|v| (p, v)))
+            .collect();
+        let ema_alignment = ema::alignment(&ema_values);
+        let emas = ema_values
+            .iter()
+            .map(|&(period, value)| EmaLevel {
+                period: period as i32,
+                value,
+                trend: ema::trend(last_close, value).to_string(),
+            })
+            .collect();
+
         let overall_signal = determine_overall_signal(bullish, bearish);
 
         Ok(Response::new(AnalyzeAllResponse {
@@ -528,6 +677,14 @@ impl TechnicalIndicators for IndicatorService {
             adx: adx_resp,
             stochastic: stoch_resp,
             regime: regime_resp,
+            vwap: vwap_resp,
+            ichimoku: ichimoku_resp,
+            obv: obv_resp,
+            supertrend: supertrend_resp,
+            donchian: donchian_resp,
+            pivots: pivots_resp,
+            emas,
+            ema_alignment,
         }))
     }
 }
@@ -670,6 +827,7 @@ mod tests {
             ema_period: 21,
             volume_lookback: 20,
             volume_threshold: 2.0,
+            ..Default::default()
         });
         let resp = service.analyze_all(req).await.unwrap();
         let inner = resp.into_inner();
@@ -698,12 +856,40 @@ mod tests {
             ema_period: 0,
             volume_lookback: 0,
             volume_threshold: 0.0,
+            ..Default::default()
         });
         let resp = service.analyze_all(req).await.unwrap();
         let inner = resp.into_inner();
         assert!(inner.rsi.is_some());
     }
 
+    #[tokio::test]
+    async fn test_analyze_all_structure_indicators() {
+        let service = IndicatorService;
+        let prices: Vec<f64> = (0..100).map(|i| 100.0 + (i as f64) * 0.3).collect();
+        let volumes = vec![1000.0; 100];
+        // 4h candles so the window spans several utc days
+        let mut candles = make_candles(&prices, &volumes);
+        for (i, c) in candles.iter_mut().enumerate() {
+            c.timestamp = i as i64 * 14_400;
+        }
+        let req = Request::new(AnalyzeAllRequest {
+            candles,
+            ..Default::default()
+        });
+        let inner = service.analyze_all(req).await.unwrap().into_inner();
+        assert!(inner.vwap.is_some());
+        assert!(inner.ichimoku.is_some());
+        assert!(inner.obv.is_some());
+        assert_eq!(inner.supertrend.unwrap().direction, "UP");
+        assert!(inner.donchian.is_some());
+        assert!(inner.pivots.unwrap().classic.is_some());
+        // 200 is longer than the window and is skipped
+        let periods: Vec<i32> = inner.emas.iter().map(|e| e.period).collect();
+        assert_eq!(periods, vec![12, 26, 50]);
+        assert_eq!(inner.ema_alignment, "BULLISH");
+    }
+
     #[test]
     fn test_overall_signal_strong_buy() {
         assert_eq!(determine_overall_signal(4, 0), "STRONG_BUY");